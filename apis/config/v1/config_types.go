@@ -0,0 +1,165 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConfigSpec defines the desired state of Config. The v1 schema is
+// structurally identical to v1alpha1 but carries the validation and
+// defaulting the alpha version silently skipped: process names are
+// checked, sync entries must be fully qualified, and traces must name
+// both a user and a kind.
+type ConfigSpec struct {
+	// Important: Run "make" to regenerate code after modifying this file
+
+	// Configuration for syncing k8s objects
+	Sync Sync `json:"sync,omitempty"`
+
+	// Configuration for validation
+	Validation Validation `json:"validation,omitempty"`
+
+	// Configuration for namespace exclusion
+	Match []MatchEntry `json:"match,omitempty"`
+
+	// Configuration for readiness tracker
+	Readiness ReadinessSpec `json:"readiness,omitempty"`
+}
+
+type Validation struct {
+	// List of requests to trace. Both "user" and "kind" must be specified
+	Traces []Trace `json:"traces,omitempty"`
+}
+
+type Trace struct {
+	// Only trace requests from the specified user
+	User string `json:"user,omitempty"`
+	// Only trace requests of the following GroupVersionKind
+	Kind GVK `json:"kind,omitempty"`
+	// Also dump the state of OPA with the trace. Set to `All` to dump everything.
+	// +kubebuilder:validation:Enum="";All
+	Dump string `json:"dump,omitempty"`
+}
+
+type Sync struct {
+	// If non-empty, only entries on this list will be replicated into OPA
+	SyncOnly []SyncOnlyEntry `json:"syncOnly,omitempty"`
+}
+
+type SyncOnlyEntry struct {
+	Group string `json:"group,omitempty"`
+	// +kubebuilder:validation:MinLength=1
+	Version string `json:"version"`
+	// +kubebuilder:validation:MinLength=1
+	Kind string `json:"kind"`
+}
+
+type MatchEntry struct {
+	ExcludedNamespaces []string `json:"excludedNamespaces,omitempty"`
+	// ExcludedNamespaceSelector excludes namespaces whose labels match the
+	// selector, in addition to any namespaces listed by name
+	ExcludedNamespaceSelector *metav1.LabelSelector `json:"excludedNamespaceSelector,omitempty"`
+	// Processes the exclusion applies to. Defaults to ["*"], every
+	// process.
+	// +kubebuilder:validation:items:Enum=audit;sync;webhook;mutation-webhook;*
+	Processes []string `json:"processes,omitempty"`
+}
+
+type ReadinessSpec struct {
+	StatsEnabled bool `json:"statsEnabled,omitempty"`
+}
+
+// ConfigStatus defines the observed state of Config
+type ConfigStatus struct {
+	// Important: Run "make" to regenerate code after modifying this file
+}
+
+type GVK struct {
+	Group   string `json:"group,omitempty"`
+	Version string `json:"version,omitempty"`
+	Kind    string `json:"kind,omitempty"`
+}
+
+// +kubebuilder:resource:scope=Namespaced
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+
+// Config is the Schema for the configs API
+type Config struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ConfigSpec   `json:"spec,omitempty"`
+	Status ConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ConfigList contains a list of Config
+type ConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Config `json:"items"`
+}
+
+// validProcesses are the process names a match entry may target.
+var validProcesses = map[string]bool{"audit": true, "sync": true, "webhook": true, "mutation-webhook": true, "*": true}
+
+// Default fills in the defaults the schema documents: match entries
+// without processes apply to every process.
+func (c *Config) Default() {
+	for i := range c.Spec.Match {
+		if len(c.Spec.Match[i].Processes) == 0 {
+			c.Spec.Match[i].Processes = []string{"*"}
+		}
+	}
+}
+
+// Validate rejects the misconfigurations the alpha schema accepted
+// silently.
+func (c *Config) Validate() error {
+	for _, entry := range c.Spec.Sync.SyncOnly {
+		if entry.Version == "" || entry.Kind == "" {
+			return fmt.Errorf("syncOnly entries must set version and kind")
+		}
+	}
+	for i, entry := range c.Spec.Match {
+		if len(entry.ExcludedNamespaces) == 0 && entry.ExcludedNamespaceSelector == nil {
+			return fmt.Errorf("match entry %d excludes nothing: set excludedNamespaces or excludedNamespaceSelector", i)
+		}
+		for _, p := range entry.Processes {
+			if !validProcesses[p] {
+				return fmt.Errorf("process %q is not one of audit, sync, webhook, mutation-webhook, *", p)
+			}
+		}
+	}
+	for _, trace := range c.Spec.Validation.Traces {
+		if trace.User == "" || trace.Kind.Kind == "" {
+			return fmt.Errorf("traces must name both a user and a kind")
+		}
+		if trace.Dump != "" && trace.Dump != "All" {
+			return fmt.Errorf("trace dump must be unset or \"All\", got %q", trace.Dump)
+		}
+	}
+	return nil
+}
+
+func init() {
+	SchemeBuilder.Register(&Config{}, &ConfigList{})
+}
@@ -0,0 +1,98 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	configv1 "github.com/open-policy-agent/gatekeeper/apis/config/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// The schemas are structurally identical, so conversion is a field-wise
+// copy; v1 defaulting is applied on the way up so alpha objects read
+// back with the documented defaults filled in.
+
+// ConvertTo converts this Config to the v1 hub version.
+func (src *Config) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*configv1.Config)
+	if !ok {
+		return fmt.Errorf("unexpected conversion hub type %T", dstRaw)
+	}
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = specToV1(src.Spec)
+	dst.Default()
+	return nil
+}
+
+// ConvertFrom converts from the v1 hub version to this Config.
+func (dst *Config) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*configv1.Config)
+	if !ok {
+		return fmt.Errorf("unexpected conversion hub type %T", srcRaw)
+	}
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = specFromV1(src.Spec)
+	return nil
+}
+
+func specToV1(in ConfigSpec) configv1.ConfigSpec {
+	out := configv1.ConfigSpec{
+		Readiness: configv1.ReadinessSpec{StatsEnabled: in.Readiness.StatsEnabled},
+	}
+	for _, entry := range in.Sync.SyncOnly {
+		out.Sync.SyncOnly = append(out.Sync.SyncOnly, configv1.SyncOnlyEntry{Group: entry.Group, Version: entry.Version, Kind: entry.Kind})
+	}
+	for _, trace := range in.Validation.Traces {
+		out.Validation.Traces = append(out.Validation.Traces, configv1.Trace{
+			User: trace.User,
+			Kind: configv1.GVK{Group: trace.Kind.Group, Version: trace.Kind.Version, Kind: trace.Kind.Kind},
+			Dump: trace.Dump,
+		})
+	}
+	for _, match := range in.Match {
+		out.Match = append(out.Match, configv1.MatchEntry{
+			ExcludedNamespaces:        append([]string{}, match.ExcludedNamespaces...),
+			ExcludedNamespaceSelector: match.ExcludedNamespaceSelector.DeepCopy(),
+			Processes:                 append([]string{}, match.Processes...),
+		})
+	}
+	return out
+}
+
+func specFromV1(in configv1.ConfigSpec) ConfigSpec {
+	out := ConfigSpec{
+		Readiness: ReadinessSpec{StatsEnabled: in.Readiness.StatsEnabled},
+	}
+	for _, entry := range in.Sync.SyncOnly {
+		out.Sync.SyncOnly = append(out.Sync.SyncOnly, SyncOnlyEntry{Group: entry.Group, Version: entry.Version, Kind: entry.Kind})
+	}
+	for _, trace := range in.Validation.Traces {
+		out.Validation.Traces = append(out.Validation.Traces, Trace{
+			User: trace.User,
+			Kind: GVK{Group: trace.Kind.Group, Version: trace.Kind.Version, Kind: trace.Kind.Kind},
+			Dump: trace.Dump,
+		})
+	}
+	for _, match := range in.Match {
+		out.Match = append(out.Match, MatchEntry{
+			ExcludedNamespaces:        append([]string{}, match.ExcludedNamespaces...),
+			ExcludedNamespaceSelector: match.ExcludedNamespaceSelector.DeepCopy(),
+			Processes:                 append([]string{}, match.Processes...),
+		})
+	}
+	return out
+}
@@ -63,7 +63,10 @@ type SyncOnlyEntry struct {
 
 type MatchEntry struct {
 	ExcludedNamespaces []string `json:"excludedNamespaces,omitempty"`
-	Processes          []string `json:"processes,omitempty"`
+	// ExcludedNamespaceSelector excludes namespaces whose labels match the
+	// selector, in addition to any namespaces listed by name
+	ExcludedNamespaceSelector *metav1.LabelSelector `json:"excludedNamespaceSelector,omitempty"`
+	Processes                 []string              `json:"processes,omitempty"`
 }
 
 type ReadinessSpec struct {
@@ -73,6 +76,25 @@ type ReadinessSpec struct {
 // ConfigStatus defines the observed state of Config
 type ConfigStatus struct {
 	// Important: Run "make" to regenerate code after modifying this file
+
+	// Ready is true once the readiness tracker has seen every template,
+	// constraint, and syncOnly entry complete its initial ingestion.
+	// Only written when --readiness-sync-status is set.
+	Ready bool `json:"ready,omitempty"`
+	// ConstraintsSynced is true once every constraint of every ingested
+	// template kind has been loaded.
+	ConstraintsSynced bool `json:"constraintsSynced,omitempty"`
+	// SyncStatus reports, per syncOnly entry, whether the initial list
+	// of that kind has been replicated.
+	SyncStatus []SyncGVKStatus `json:"syncStatus,omitempty"`
+}
+
+// SyncGVKStatus is one syncOnly entry's initial sync state.
+type SyncGVKStatus struct {
+	Group   string `json:"group,omitempty"`
+	Version string `json:"version,omitempty"`
+	Kind    string `json:"kind,omitempty"`
+	Synced  bool   `json:"synced"`
 }
 
 type GVK struct {
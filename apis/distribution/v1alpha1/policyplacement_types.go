@@ -0,0 +1,118 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PolicyRef identifies a template or constraint on the hub to
+// distribute.
+type PolicyRef struct {
+	// APIVersion of the referenced object. Defaults to
+	// templates.gatekeeper.sh/v1beta1 for ConstraintTemplates and
+	// constraints.gatekeeper.sh/v1beta1 for everything else.
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// Kind of the referenced object: ConstraintTemplate or a constraint
+	// kind.
+	Kind string `json:"kind"`
+
+	// Name of the referenced object.
+	Name string `json:"name"`
+}
+
+// ClusterOverride adjusts distributed constraints for one spoke, so the
+// same placement can, for example, audit in production while denying in
+// staging.
+type ClusterOverride struct {
+	// Cluster is the name of the SpokeCluster the override applies to.
+	Cluster string `json:"cluster"`
+
+	// EnforcementAction replaces spec.enforcementAction on every
+	// constraint distributed to the cluster.
+	EnforcementAction string `json:"enforcementAction,omitempty"`
+}
+
+// PolicyPlacementSpec defines the desired state of PolicyPlacement: a
+// set of hub policies and the registered spokes they roll out to.
+type PolicyPlacementSpec struct {
+	// ClusterSelector selects registered SpokeClusters by label. When
+	// unset, the placement targets every registered spoke.
+	ClusterSelector *metav1.LabelSelector `json:"clusterSelector,omitempty"`
+
+	// Policies references the templates and constraints on the hub to
+	// distribute.
+	Policies []PolicyRef `json:"policies,omitempty"`
+
+	// Overrides adjusts the distributed constraints per cluster.
+	Overrides []ClusterOverride `json:"overrides,omitempty"`
+}
+
+// ClusterPlacementStatus reports the rollout state of the placement on
+// one spoke.
+type ClusterPlacementStatus struct {
+	// Cluster is the name of the SpokeCluster.
+	Cluster string `json:"cluster"`
+
+	// Synced is true when every referenced policy was applied to the
+	// cluster during the last sync.
+	Synced bool `json:"synced"`
+
+	// SyncedObjects is the number of policy objects applied to the
+	// cluster.
+	SyncedObjects int64 `json:"syncedObjects,omitempty"`
+
+	// LastSyncTime is when the cluster was last successfully synced.
+	LastSyncTime metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// LastError records why the most recent sync of this cluster failed,
+	// if it did.
+	LastError string `json:"lastError,omitempty"`
+}
+
+// PolicyPlacementStatus aggregates per-cluster rollout status back to
+// the hub.
+type PolicyPlacementStatus struct {
+	// Clusters holds one entry per targeted spoke.
+	Clusters []ClusterPlacementStatus `json:"clusters,omitempty"`
+}
+
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// PolicyPlacement is the Schema for the policyplacements API
+type PolicyPlacement struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PolicyPlacementSpec   `json:"spec,omitempty"`
+	Status PolicyPlacementStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PolicyPlacementList contains a list of PolicyPlacement
+type PolicyPlacementList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PolicyPlacement `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PolicyPlacement{}, &PolicyPlacementList{})
+}
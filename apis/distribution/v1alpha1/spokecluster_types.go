@@ -0,0 +1,53 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SpokeClusterSpec defines the desired state of SpokeCluster. A
+// SpokeCluster registers a managed cluster with the hub; PolicyPlacements
+// select registered spokes by the labels on this object.
+type SpokeClusterSpec struct {
+	// KubeconfigSecretRef names a Secret in Gatekeeper's namespace whose
+	// "kubeconfig" key holds credentials for the spoke's API server.
+	KubeconfigSecretRef string `json:"kubeconfigSecretRef"`
+}
+
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:object:root=true
+
+// SpokeCluster is the Schema for the spokeclusters API
+type SpokeCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SpokeClusterSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SpokeClusterList contains a list of SpokeCluster
+type SpokeClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SpokeCluster `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SpokeCluster{}, &SpokeClusterList{})
+}
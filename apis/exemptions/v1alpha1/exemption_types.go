@@ -0,0 +1,116 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ExemptionSpec defines the desired state of Exemption. An Exemption
+// grants a scoped, time-limited bypass for specific constraints and
+// resources, replacing ad-hoc namespace exclusions. Exemptions carry
+// approver metadata so every bypass is attributable, and expire
+// automatically once expiresAt has passed.
+type ExemptionSpec struct {
+	// Constraints lists the constraints bypassed by this exemption.
+	// An empty list exempts the matched resources from all constraints.
+	Constraints []ConstraintReference `json:"constraints,omitempty"`
+
+	// Match limits the resources the exemption applies to.
+	Match ExemptionMatch `json:"match,omitempty"`
+
+	// ExpiresAt is the time at which this exemption stops applying.
+	// Required; open-ended exemptions are not supported.
+	ExpiresAt metav1.Time `json:"expiresAt"`
+
+	// AllowAnnotations changes the exemption from a direct bypass into an
+	// authorization: resources covered by Match may request their own
+	// exemption via the exemptions.gatekeeper.sh/exempt annotation, and
+	// the annotation is only honored while an authorizing exemption is
+	// active.
+	AllowAnnotations bool `json:"allowAnnotations,omitempty"`
+
+	// Approver identifies who approved the exemption.
+	Approver string `json:"approver,omitempty"`
+
+	// Reason records why the exemption was granted.
+	Reason string `json:"reason,omitempty"`
+}
+
+// ConstraintReference identifies a constraint by kind and optionally name.
+type ConstraintReference struct {
+	Kind string `json:"kind"`
+	// Name of the constraint. An empty name matches every constraint of
+	// the given kind.
+	Name string `json:"name,omitempty"`
+}
+
+// ExemptionMatch selects the resources and requesters an exemption
+// applies to. An empty field matches everything. When any of Users,
+// Groups, or ServiceAccounts is set, the exemption only applies to
+// requests from a listed requester; the three fields are alternatives,
+// so matching any one of them is sufficient.
+type ExemptionMatch struct {
+	// Namespaces the exemption applies to.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Kinds of resources the exemption applies to.
+	Kinds []string `json:"kinds,omitempty"`
+	// Names of resources the exemption applies to.
+	Names []string `json:"names,omitempty"`
+	// Users whose requests the exemption applies to, by authenticated
+	// username.
+	Users []string `json:"users,omitempty"`
+	// Groups whose members' requests the exemption applies to.
+	Groups []string `json:"groups,omitempty"`
+	// ServiceAccounts whose requests the exemption applies to, as
+	// namespace/name.
+	ServiceAccounts []string `json:"serviceAccounts,omitempty"`
+}
+
+// ExemptionStatus defines the observed state of Exemption
+type ExemptionStatus struct {
+	// ByPod contains no entries; status is computed on read.
+}
+
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:object:root=true
+
+// Exemption is the Schema for the exemptions API
+type Exemption struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ExemptionSpec   `json:"spec,omitempty"`
+	Status ExemptionStatus `json:"status,omitempty"`
+}
+
+// Expired returns true once the exemption's expiry time has passed.
+func (e *Exemption) Expired(now metav1.Time) bool {
+	return e.Spec.ExpiresAt.Before(&now)
+}
+
+// +kubebuilder:object:root=true
+
+// ExemptionList contains a list of Exemption
+type ExemptionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Exemption `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Exemption{}, &ExemptionList{})
+}
@@ -0,0 +1,109 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ExpansionTemplateSpec declares how a workload-generating resource
+// expands into the resource it ultimately creates, so policy written
+// against the generated kind (typically Pods) can be enforced at the
+// point where users actually create resources. Built-in workload kinds
+// and custom CRDs (e.g. Argo Rollouts, Knative Services) are declared
+// the same way: a path to the embedded template and the GVK it
+// produces.
+type ExpansionTemplateSpec struct {
+	// ApplyTo lists the resource kinds this template expands.
+	ApplyTo []ApplyTo `json:"applyTo,omitempty"`
+
+	// TemplateSource is the dot-separated path to the embedded resource
+	// template within the parent object, e.g. "spec.template" for
+	// Deployments and Argo Rollouts, "spec.jobTemplate.spec.template"
+	// for CronJobs.
+	TemplateSource string `json:"templateSource,omitempty"`
+
+	// GeneratedGVK is the group, version, and kind of the resource the
+	// parent generates from the template. Not used when Transform is
+	// set; the transform emits complete resources.
+	GeneratedGVK GeneratedGVK `json:"generatedGVK,omitempty"`
+
+	// Transform generates the resultant resources with a Rego program
+	// instead of extracting an embedded template, for parents whose
+	// relationship to their workloads is not a simple field path.
+	// Mutually exclusive with TemplateSource.
+	Transform *Transform `json:"transform,omitempty"`
+
+	// EnforcementAction overrides the enforcement action of constraints
+	// violated by expanded resources. When empty, each constraint's own
+	// enforcement action applies.
+	EnforcementAction string `json:"enforcementAction,omitempty"`
+}
+
+// ApplyTo selects the parent resource kinds a template expands. All
+// three fields must match; an entry is expected per served version.
+type ApplyTo struct {
+	Groups   []string `json:"groups,omitempty"`
+	Kinds    []string `json:"kinds,omitempty"`
+	Versions []string `json:"versions,omitempty"`
+}
+
+// Transform declares a Rego program that produces the resultant
+// resources for a parent. The program must use `package expansion` and
+// define a rule `resultants` evaluating to a list of complete objects
+// (apiVersion and kind included); `input` is the parent object. Name
+// and namespace default from the parent when the transform omits them.
+type Transform struct {
+	// Rego is the transform program.
+	Rego string `json:"rego,omitempty"`
+}
+
+// GeneratedGVK identifies the kind of resource generated from the
+// embedded template.
+type GeneratedGVK struct {
+	Group   string `json:"group,omitempty"`
+	Version string `json:"version,omitempty"`
+	Kind    string `json:"kind,omitempty"`
+}
+
+// ExpansionTemplateStatus defines the observed state of ExpansionTemplate
+type ExpansionTemplateStatus struct {
+}
+
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:object:root=true
+
+// ExpansionTemplate is the Schema for the expansiontemplates API
+type ExpansionTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ExpansionTemplateSpec   `json:"spec,omitempty"`
+	Status ExpansionTemplateStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ExpansionTemplateList contains a list of ExpansionTemplate
+type ExpansionTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ExpansionTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ExpansionTemplate{}, &ExpansionTemplateList{})
+}
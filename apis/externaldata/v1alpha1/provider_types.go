@@ -0,0 +1,109 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Protocol selects how Gatekeeper talks to an external data provider.
+type Protocol string
+
+const (
+	// ProtocolHTTP sends provider requests as HTTP POSTs with JSON
+	// bodies. It is the default.
+	ProtocolHTTP Protocol = "HTTP"
+
+	// ProtocolGRPC sends provider requests over a gRPC unary call,
+	// avoiding per-request connection setup and JSON framing overhead
+	// for high-throughput providers.
+	ProtocolGRPC Protocol = "GRPC"
+)
+
+// ProviderSpec defines how to reach an external data provider and how
+// long to wait for it.
+type ProviderSpec struct {
+	// URL is the provider endpoint. For HTTP providers this is the full
+	// request URL; for gRPC providers it is the host:port to dial,
+	// optionally prefixed with a grpc:// or grpcs:// scheme.
+	URL string `json:"url,omitempty"`
+
+	// Protocol is HTTP (the default) or GRPC.
+	Protocol Protocol `json:"protocol,omitempty"`
+
+	// Timeout is how long to wait for the provider, in seconds.
+	// Defaults to 3.
+	Timeout int `json:"timeout,omitempty"`
+
+	// CABundle is a base64-encoded PEM bundle used to verify the
+	// provider's serving certificate. When empty, HTTP providers use
+	// plain HTTP and gRPC providers dial without transport security.
+	CABundle string `json:"caBundle,omitempty"`
+
+	// Auth configures how Gatekeeper authenticates to the provider.
+	// When empty, requests carry no client credentials.
+	Auth *ProviderAuth `json:"auth,omitempty"`
+}
+
+// ProviderAuth selects the client credentials presented to a provider.
+// Credentials live in Secrets and are read on every query, so rotating
+// a Secret takes effect without restarting Gatekeeper.
+type ProviderAuth struct {
+	// ClientCertSecretRef names a kubernetes.io/tls Secret whose
+	// tls.crt and tls.key are presented as the client certificate
+	// (mTLS). Requires caBundle.
+	ClientCertSecretRef *SecretRef `json:"clientCertSecretRef,omitempty"`
+
+	// BearerTokenSecretRef names a Secret whose token key is sent as a
+	// Bearer token with every request.
+	BearerTokenSecretRef *SecretRef `json:"bearerTokenSecretRef,omitempty"`
+}
+
+// SecretRef points at a Secret. Namespace defaults to the namespace
+// Gatekeeper runs in.
+type SecretRef struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ProviderStatus defines the observed state of Provider
+type ProviderStatus struct {
+}
+
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:object:root=true
+
+// Provider is the Schema for the providers API
+type Provider struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ProviderSpec   `json:"spec,omitempty"`
+	Status ProviderStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ProviderList contains a list of Provider
+type ProviderList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Provider `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Provider{}, &ProviderList{})
+}
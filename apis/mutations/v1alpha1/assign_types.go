@@ -34,6 +34,13 @@ type AssignSpec struct {
 	Match      Match      `json:"match,omitempty"`
 	Location   string     `json:"location,omitempty"`
 	Parameters Parameters `json:"parameters,omitempty"`
+
+	// Order determines when this mutation applies relative to others;
+	// lower orders apply first. Mutators sharing an order apply in a
+	// stable order derived from their identity. Defaults to 0. Use this
+	// when a mutation reads a field another mutation sets, so the result
+	// does not depend on iteration until the mutators stabilize.
+	Order int `json:"order,omitempty"`
 }
 
 // ApplyTo determines what GVKs items the mutation should apply to.
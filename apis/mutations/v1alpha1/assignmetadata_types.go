@@ -28,6 +28,10 @@ type AssignMetadataSpec struct {
 	Match      Match              `json:"match,omitempty"`
 	Location   string             `json:"location,omitempty"`
 	Parameters MetadataParameters `json:"parameters,omitempty"`
+
+	// Order determines when this mutation applies relative to others;
+	// lower orders apply first. Defaults to 0.
+	Order int `json:"order,omitempty"`
 }
 
 type MetadataParameters struct {
@@ -0,0 +1,83 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// JSONPatchSpec defines the desired state of JSONPatch
+type JSONPatchSpec struct {
+	ApplyTo    []ApplyTo           `json:"applyTo,omitempty"`
+	Match      Match               `json:"match,omitempty"`
+	Parameters JSONPatchParameters `json:"parameters,omitempty"`
+
+	// Order determines when this mutation applies relative to others;
+	// lower orders apply first. Defaults to 0.
+	Order int `json:"order,omitempty"`
+}
+
+type JSONPatchParameters struct {
+	// Operations is the list of RFC 6902 operations applied, in order,
+	// to matching objects. Operations that the location-based mutators
+	// cannot express — removing a list element at an index, copying one
+	// field to another, testing before replacing — are all available.
+	Operations []PatchOperation `json:"operations,omitempty"`
+}
+
+// PatchOperation is one RFC 6902 operation.
+type PatchOperation struct {
+	// Op is one of add, remove, replace, move, copy, or test.
+	Op string `json:"op"`
+	// Path is an RFC 6901 JSON pointer to the field the operation
+	// applies to.
+	Path string `json:"path"`
+	// From is the source pointer for move and copy operations.
+	From string `json:"from,omitempty"`
+	// Value holds the value for add, replace, and test operations.
+	// +kubebuilder:validation:XPreserveUnknownFields
+	Value runtime.RawExtension `json:"value,omitempty"`
+}
+
+// JSONPatchStatus defines the observed state of JSONPatch
+type JSONPatchStatus struct {
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope="Cluster"
+
+// JSONPatch is the Schema for the jsonpatch API
+type JSONPatch struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   JSONPatchSpec   `json:"spec,omitempty"`
+	Status JSONPatchStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// JSONPatchList contains a list of JSONPatch
+type JSONPatchList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []JSONPatch `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&JSONPatch{}, &JSONPatchList{})
+}
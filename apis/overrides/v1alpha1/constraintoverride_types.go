@@ -0,0 +1,84 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConstraintOverrideSpec defines the desired state of ConstraintOverride.
+// A ConstraintOverride lets namespace owners downgrade specific
+// constraints from deny to warn for their own namespace. Overrides are
+// only honored for constraints whose authors delegated that decision via
+// the policies.gatekeeper.sh/allow-overrides annotation.
+type ConstraintOverrideSpec struct {
+	// Constraints lists the constraints downgraded by this override.
+	Constraints []ConstraintReference `json:"constraints"`
+
+	// Reason records why the override was requested.
+	Reason string `json:"reason,omitempty"`
+}
+
+// ConstraintReference identifies a constraint by kind and optionally name.
+type ConstraintReference struct {
+	Kind string `json:"kind"`
+	// Name of the constraint. An empty name matches every constraint of
+	// the given kind.
+	Name string `json:"name,omitempty"`
+}
+
+// ConstraintOverrideStatus defines the observed state of ConstraintOverride
+type ConstraintOverrideStatus struct {
+	// Honored overrides are surfaced on the constraint's status instead.
+}
+
+// +kubebuilder:resource:scope=Namespaced
+// +kubebuilder:object:root=true
+
+// ConstraintOverride is the Schema for the constraintoverrides API
+type ConstraintOverride struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ConstraintOverrideSpec   `json:"spec,omitempty"`
+	Status ConstraintOverrideStatus `json:"status,omitempty"`
+}
+
+// Matches returns true if the override covers the given constraint.
+func (o *ConstraintOverride) Matches(kind, name string) bool {
+	for _, ref := range o.Spec.Constraints {
+		if ref.Kind != kind {
+			continue
+		}
+		if ref.Name == "" || ref.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// +kubebuilder:object:root=true
+
+// ConstraintOverrideList contains a list of ConstraintOverride
+type ConstraintOverrideList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ConstraintOverride `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ConstraintOverride{}, &ConstraintOverrideList{})
+}
@@ -0,0 +1,93 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GatekeeperConfigSpec defines the desired state of GatekeeperConfig:
+// runtime knobs otherwise spread across command-line flags. Unset fields
+// leave the corresponding flag untouched. Knobs that are safe to change
+// live take effect within one reconcile; the rest are reported as
+// requiring a restart on the status.
+type GatekeeperConfigSpec struct {
+	// AuditIntervalSeconds is how often audit runs; 0 disables audit.
+	// Takes effect at the start of the next audit cycle.
+	AuditIntervalSeconds *int64 `json:"auditIntervalSeconds,omitempty"`
+
+	// ConstraintViolationsLimit caps the violations recorded per
+	// constraint status.
+	ConstraintViolationsLimit *int64 `json:"constraintViolationsLimit,omitempty"`
+
+	// ExemptNamespaces are namespaces allowed to set the
+	// admission.gatekeeper.sh/ignore label. Entries are additive to the
+	// --exempt-namespace flags; removing an entry requires a restart.
+	ExemptNamespaces []string `json:"exemptNamespaces,omitempty"`
+
+	// LogDenies logs detailed info on each deny.
+	LogDenies *bool `json:"logDenies,omitempty"`
+
+	// EmitAdmissionEvents emits Kubernetes events for each admission
+	// violation.
+	EmitAdmissionEvents *bool `json:"emitAdmissionEvents,omitempty"`
+
+	// LogLevel is the minimum log level: DEBUG, INFO, WARNING, or ERROR.
+	// The logger is built at startup, so changing it requires a restart.
+	LogLevel string `json:"logLevel,omitempty"`
+}
+
+// GatekeeperConfigStatus defines the observed state of GatekeeperConfig.
+type GatekeeperConfigStatus struct {
+	// ObservedGeneration is the generation last reconciled.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// LastAppliedTime is when the spec was last successfully applied.
+	LastAppliedTime metav1.Time `json:"lastAppliedTime,omitempty"`
+
+	// RestartRequired lists the settings that differ from the running
+	// process and only take effect after a restart.
+	RestartRequired []string `json:"restartRequired,omitempty"`
+
+	// LastError records why the most recent reconcile failed, if it did.
+	LastError string `json:"lastError,omitempty"`
+}
+
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// GatekeeperConfig is the Schema for the gatekeeperconfigs API
+type GatekeeperConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GatekeeperConfigSpec   `json:"spec,omitempty"`
+	Status GatekeeperConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// GatekeeperConfigList contains a list of GatekeeperConfig
+type GatekeeperConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GatekeeperConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GatekeeperConfig{}, &GatekeeperConfigList{})
+}
@@ -0,0 +1,100 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PolicySourceSpec defines the desired state of PolicySource. A
+// PolicySource points at a git repository from which constraint
+// templates, constraints, and mutators are synced and pruned, giving a
+// native GitOps path without external tooling.
+type PolicySourceSpec struct {
+	// Repository is the git URL to sync policies from. Exactly one of
+	// Repository and Image must be set.
+	Repository string `json:"repository,omitempty"`
+
+	// Image is a digest-pinned OCI artifact reference
+	// (registry/repo@sha256:…) holding the policy bundle as a tarball of
+	// manifests. Digest pinning makes the bundle immutable, so the same
+	// artifact can be promoted across environments.
+	Image string `json:"image,omitempty"`
+
+	// Ref is the branch, tag, or commit to check out. Only used with
+	// Repository; defaults to the repository's default branch.
+	Ref string `json:"ref,omitempty"`
+
+	// Path is the directory within the repository holding the policy
+	// manifests. Defaults to the repository root.
+	Path string `json:"path,omitempty"`
+
+	// IntervalSeconds is how often the source is re-synced. Defaults to
+	// 300.
+	IntervalSeconds int64 `json:"intervalSeconds,omitempty"`
+
+	// SecretRef names a Secret in Gatekeeper's namespace providing git
+	// credentials: either a "token" key or "username" and "password"
+	// keys. Unset for public repositories.
+	SecretRef string `json:"secretRef,omitempty"`
+
+	// RequireSignature requires each manifest to carry a detached
+	// signature (<file>.sig) that verifies against the
+	// --bundle-trust-roots; unsigned or tampered manifests fail the sync.
+	RequireSignature bool `json:"requireSignature,omitempty"`
+}
+
+// PolicySourceStatus defines the observed state of PolicySource.
+type PolicySourceStatus struct {
+	// ObservedRevision is the commit last applied from the source.
+	ObservedRevision string `json:"observedRevision,omitempty"`
+
+	// LastSyncTime is when the source was last successfully applied.
+	LastSyncTime metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// SyncedObjects is the number of policy objects currently managed
+	// from this source.
+	SyncedObjects int64 `json:"syncedObjects,omitempty"`
+
+	// LastError records why the most recent sync failed, if it did.
+	LastError string `json:"lastError,omitempty"`
+}
+
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// PolicySource is the Schema for the policysources API
+type PolicySource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PolicySourceSpec   `json:"spec,omitempty"`
+	Status PolicySourceStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PolicySourceList contains a list of PolicySource
+type PolicySourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PolicySource `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PolicySource{}, &PolicySourceList{})
+}
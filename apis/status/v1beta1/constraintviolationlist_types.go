@@ -0,0 +1,102 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ViolationChunkLabel carries the chunk's index, so stale chunks can be
+// pruned by label after the violation count shrinks.
+const ViolationChunkLabel = "internal.gatekeeper.sh/violation-chunk"
+
+// AuditViolation is one violation recorded by audit.
+type AuditViolation struct {
+	Kind              string `json:"kind"`
+	Name              string `json:"name"`
+	Namespace         string `json:"namespace,omitempty"`
+	Message           string `json:"message"`
+	EnforcementAction string `json:"enforcementAction"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced
+
+// ConstraintViolationList stores one chunk of a constraint's audit
+// violations. Violations on the constraint's status are capped to keep
+// the constraint within etcd's object size limit; chunking the full
+// list across dedicated resources removes that cap. Chunks are written
+// by audit each cycle and are paged through with
+// `policyctl violations`.
+type ConstraintViolationList struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	ConstraintKind string `json:"constraintKind,omitempty"`
+	ConstraintName string `json:"constraintName,omitempty"`
+	// ChunkIndex is this chunk's zero-based position in the list.
+	ChunkIndex int `json:"chunkIndex"`
+	// TotalChunks is how many chunks the audit cycle wrote, so readers
+	// can tell whether the set is complete.
+	TotalChunks int `json:"totalChunks"`
+	// AuditTimestamp is the audit run that produced this chunk.
+	AuditTimestamp string           `json:"auditTimestamp,omitempty"`
+	Violations     []AuditViolation `json:"violations,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ConstraintViolationListList contains a list of ConstraintViolationList
+type ConstraintViolationListList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ConstraintViolationList `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ConstraintViolationList{}, &ConstraintViolationListList{})
+}
+
+// NewViolationListChunk returns a chunk initialized with the naming and
+// labels the audit pruner and the pager rely on.
+func NewViolationListChunk(constraint *unstructured.Unstructured, index, total int, timestamp string, violations []AuditViolation) (*ConstraintViolationList, error) {
+	obj := &ConstraintViolationList{
+		ConstraintKind: constraint.GetKind(),
+		ConstraintName: constraint.GetName(),
+		ChunkIndex:     index,
+		TotalChunks:    total,
+		AuditTimestamp: timestamp,
+		Violations:     violations,
+	}
+	// the chunk index cannot begin or end with a dash, so it packs safely
+	name, err := dashPacker(strings.ToLower(constraint.GetKind()), constraint.GetName(), strconv.Itoa(index))
+	if err != nil {
+		return nil, err
+	}
+	obj.SetName(name)
+	obj.SetNamespace(util.GetNamespace())
+	obj.SetLabels(map[string]string{
+		ConstraintNameLabel: constraint.GetName(),
+		ConstraintKindLabel: constraint.GetKind(),
+		ViolationChunkLabel: strconv.Itoa(index),
+	})
+	return obj, nil
+}
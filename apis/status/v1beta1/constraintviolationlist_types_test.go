@@ -0,0 +1,41 @@
+package v1beta1
+
+import (
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestNewViolationListChunk(t *testing.T) {
+	g := NewGomegaWithT(t)
+	podNS := "a-gk-namespace"
+	os.Setenv("POD_NAMESPACE", podNS)
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	cstr := &unstructured.Unstructured{}
+	cstr.SetGroupVersionKind(schema.GroupVersionKind{Group: ConstraintsGroup, Version: "v1beta1", Kind: "AConstraintKind"})
+	cstr.SetName("a-constraint")
+
+	violations := []AuditViolation{
+		{Kind: "Pod", Name: "nginx", Namespace: "default", Message: "missing labels", EnforcementAction: "deny"},
+	}
+	chunk, err := NewViolationListChunk(cstr, 2, 5, "2021-01-01T00:00:00Z", violations)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(chunk.GetName()).To(Equal("aconstraintkind-a--constraint-2"))
+	g.Expect(chunk.GetNamespace()).To(Equal(podNS))
+	g.Expect(chunk.GetLabels()).To(Equal(map[string]string{
+		ConstraintNameLabel: "a-constraint",
+		ConstraintKindLabel: "AConstraintKind",
+		ViolationChunkLabel: "2",
+	}))
+	g.Expect(chunk.ConstraintKind).To(Equal("AConstraintKind"))
+	g.Expect(chunk.ConstraintName).To(Equal("a-constraint"))
+	g.Expect(chunk.ChunkIndex).To(Equal(2))
+	g.Expect(chunk.TotalChunks).To(Equal(5))
+	g.Expect(chunk.AuditTimestamp).To(Equal("2021-01-01T00:00:00Z"))
+	g.Expect(chunk.Violations).To(Equal(violations))
+}
@@ -0,0 +1,82 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// NamespacedConstraintSpec defines the desired state of
+// NamespacedConstraint. A NamespacedConstraint is a namespaced wrapper
+// around a constraint: Gatekeeper projects it into a cluster-scoped
+// constraint whose match is confined to the wrapper's own namespace, so
+// tenant admins can author constraints without cluster-wide RBAC.
+type NamespacedConstraintSpec struct {
+	// ConstraintKind is the constraint kind to instantiate, i.e. the
+	// kind declared by a ConstraintTemplate. The template must be marked
+	// tenant-delegable by a cluster admin for the projection to happen.
+	ConstraintKind string `json:"constraintKind"`
+
+	// EnforcementAction is passed through to the projected constraint.
+	EnforcementAction string `json:"enforcementAction,omitempty"`
+
+	// Match is passed through to the projected constraint, except that
+	// its namespace selection is always overridden to the wrapper's own
+	// namespace.
+	Match runtime.RawExtension `json:"match,omitempty"`
+
+	// Parameters are passed through to the projected constraint
+	// unchanged.
+	Parameters runtime.RawExtension `json:"parameters,omitempty"`
+}
+
+// NamespacedConstraintStatus defines the observed state of
+// NamespacedConstraint.
+type NamespacedConstraintStatus struct {
+	// ProjectedName is the name of the cluster-scoped constraint this
+	// wrapper currently projects to.
+	ProjectedName string `json:"projectedName,omitempty"`
+
+	// LastError records why the most recent projection failed, if it
+	// did.
+	LastError string `json:"lastError,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// NamespacedConstraint is the Schema for the namespacedconstraints API
+type NamespacedConstraint struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NamespacedConstraintSpec   `json:"spec,omitempty"`
+	Status NamespacedConstraintStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NamespacedConstraintList contains a list of NamespacedConstraint
+type NamespacedConstraintList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NamespacedConstraint `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NamespacedConstraint{}, &NamespacedConstraintList{})
+}
@@ -0,0 +1,375 @@
+// The gator command evaluates objects against Gatekeeper policies
+// outside the cluster, giving CI pipelines the same gate as admission.
+// The test subcommand loads constraint templates and constraints from
+// local directories or from an OCI policy bundle, and/or a plain Rego
+// policy directory following Conftest conventions, runs a directory of
+// objects through them, and exits non-zero when any object is denied.
+// Results can be emitted as text, JSON, or SARIF; the SARIF form maps
+// each violation to the offending manifest file and line, so violations
+// can be uploaded to GitHub code scanning and similar tooling.
+//
+// The whatif subcommand instead evaluates a proposed local policy set
+// against live cluster resources, comparing its violations with those of
+// the policies currently installed in the cluster, so the blast radius
+// of a policy change can be assessed before applying it.
+//
+// Usage:
+//
+//	gator test -objects dir [-templates dir -constraints dir] [-image ref] [-policy dir] [-output json|sarif] [-coverage [-coverage-format html|json]]
+//	gator whatif -templates dir [-constraints dir] [-kubeconfig path] [-output json]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/gator"
+	"github.com/open-policy-agent/gatekeeper/pkg/loadtest"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	var failed bool
+	var err error
+	switch os.Args[1] {
+	case "test":
+		flags := flag.NewFlagSet("test", flag.ExitOnError)
+		var (
+			objectsDir     = flags.String("objects", "", "directory of object YAML files to evaluate")
+			templatesDir   = flags.String("templates", "", "directory of constraint template YAML files to load")
+			constraintsDir = flags.String("constraints", "", "directory of constraint YAML files to load")
+			image          = flags.String("image", "", "OCI artifact containing constraint templates and constraints, e.g. ghcr.io/org/policies:v1")
+			policyDir      = flags.String("policy", "", "directory of plain Rego policies following Conftest conventions")
+			output         = flags.String("output", "", "output format; 'json' for machine-readable results, 'sarif' for SARIF 2.1.0")
+			coverage       = flags.Bool("coverage", false, "report which rules and lines of each constraint template the objects exercised")
+			coverageFormat = flags.String("coverage-format", "json", "coverage report format: json or html")
+		)
+		if err := flags.Parse(os.Args[2:]); err != nil {
+			os.Exit(2)
+		}
+		failed, err = run(*objectsDir, *templatesDir, *constraintsDir, *image, *policyDir, *output, *coverage, *coverageFormat)
+	case "whatif":
+		flags := flag.NewFlagSet("whatif", flag.ExitOnError)
+		var (
+			templatesDir   = flags.String("templates", "", "directory of proposed constraint template YAML files")
+			constraintsDir = flags.String("constraints", "", "directory of proposed constraint YAML files")
+			kubeconfig     = flags.String("kubeconfig", "", "path to the kubeconfig file; defaults to the standard loading rules")
+			output         = flags.String("output", "", "output format; 'json' for machine-readable results")
+		)
+		if err := flags.Parse(os.Args[2:]); err != nil {
+			os.Exit(2)
+		}
+		failed, err = runWhatIf(*templatesDir, *constraintsDir, *kubeconfig, *output)
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gator: %v\n", err)
+		os.Exit(2)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: gator test -objects dir [-templates dir -constraints dir] [-image ref] [-policy dir] [-output json|sarif] [-coverage]")
+	fmt.Fprintln(os.Stderr, "       gator whatif -templates dir [-constraints dir] [-kubeconfig path] [-output json]")
+	os.Exit(2)
+}
+
+// objectResult collects one object's findings across both policy
+// flavors.
+type objectResult struct {
+	Name       string                `json:"name"`
+	Kind       string                `json:"kind"`
+	Violations []string              `json:"violations,omitempty"`
+	Warnings   []string              `json:"warnings,omitempty"`
+	Rego       []gator.RegoViolation `json:"rego,omitempty"`
+}
+
+func run(objectsDir, templatesDir, constraintsDir, image, policyDir, output string, coverage bool, coverageFormat string) (bool, error) {
+	if objectsDir == "" {
+		return false, fmt.Errorf("-objects is required")
+	}
+	if templatesDir == "" && image == "" && policyDir == "" {
+		return false, fmt.Errorf("at least one of -templates, -image, and -policy is required")
+	}
+	if coverage && coverageFormat != "json" && coverageFormat != "html" {
+		return false, fmt.Errorf("unsupported -coverage-format %q; use json or html", coverageFormat)
+	}
+	if output != "" && output != "json" && output != "sarif" {
+		return false, fmt.Errorf("unsupported -output %q; use json or sarif", output)
+	}
+	// a SARIF document must be the sole stdout content to stay uploadable
+	if coverage && output == "sarif" {
+		return false, fmt.Errorf("-coverage cannot be combined with -output sarif")
+	}
+	ctx := context.Background()
+
+	if image != "" {
+		bundle, err := gator.PullImage(ctx, image)
+		if err != nil {
+			return false, fmt.Errorf("pulling %s: %w", image, err)
+		}
+		// the bundle supplies whichever directories were not given
+		// explicitly, so a local template under review can be combined
+		// with the published constraints
+		if templatesDir == "" {
+			templatesDir = bundle.TemplatesDir
+		}
+		if constraintsDir == "" {
+			constraintsDir = bundle.ConstraintsDir
+		}
+	}
+
+	objects, err := gator.LoadObjectsWithSource(objectsDir)
+	if err != nil {
+		return false, fmt.Errorf("loading objects: %w", err)
+	}
+	if len(objects) == 0 {
+		return false, fmt.Errorf("no objects found in %s", objectsDir)
+	}
+
+	client, err := loadtest.NewClient()
+	if err != nil {
+		return false, fmt.Errorf("initializing policy client: %w", err)
+	}
+	if templatesDir != "" {
+		if _, err := loadtest.LoadTemplates(ctx, client, templatesDir); err != nil {
+			return false, fmt.Errorf("loading templates: %w", err)
+		}
+	}
+	if constraintsDir != "" {
+		if _, err := loadtest.LoadConstraints(ctx, client, constraintsDir); err != nil {
+			return false, fmt.Errorf("loading constraints: %w", err)
+		}
+	}
+	var policies *gator.RegoPolicies
+	if policyDir != "" {
+		policies, err = gator.LoadRegoPolicies(policyDir)
+		if err != nil {
+			return false, err
+		}
+	}
+	var coverageSet *gator.CoverageSet
+	if coverage {
+		if templatesDir == "" {
+			return false, fmt.Errorf("-coverage requires constraint templates via -templates or -image")
+		}
+		coverageSet, err = gator.LoadCoverageSet(templatesDir, constraintsDir)
+		if err != nil {
+			return false, fmt.Errorf("loading templates for coverage: %w", err)
+		}
+	}
+
+	failed := false
+	var results []objectResult
+	var sarif []gator.SarifFinding
+	for _, src := range objects {
+		obj := src.Object
+		result := objectResult{Name: obj.GetName(), Kind: obj.GetKind()}
+		if templatesDir != "" {
+			resp, err := client.Review(ctx, target.AugmentedUnstructured{Object: *obj})
+			if err != nil {
+				return false, fmt.Errorf("reviewing %s: %w", obj.GetName(), err)
+			}
+			for _, r := range resp.Results() {
+				level := gator.SarifLevelWarning
+				if r.EnforcementAction == "deny" {
+					result.Violations = append(result.Violations, r.Msg)
+					failed = true
+					level = gator.SarifLevelError
+				} else {
+					result.Warnings = append(result.Warnings, r.Msg)
+				}
+				sarif = append(sarif, gator.SarifFinding{
+					RuleID:  r.Constraint.GetKind() + "/" + r.Constraint.GetName(),
+					Level:   level,
+					Message: r.Msg,
+					Path:    src.Path,
+					Line:    src.Line,
+				})
+			}
+		}
+		if coverageSet != nil {
+			if err := coverageSet.EvalObject(ctx, obj.Object); err != nil {
+				return false, err
+			}
+		}
+		if policies != nil {
+			findings, err := policies.Eval(ctx, obj.Object)
+			if err != nil {
+				return false, fmt.Errorf("evaluating %s: %w", obj.GetName(), err)
+			}
+			result.Rego = findings
+			for _, finding := range findings {
+				level := gator.SarifLevelError
+				if finding.Warning() {
+					level = gator.SarifLevelWarning
+				} else {
+					failed = true
+				}
+				sarif = append(sarif, gator.SarifFinding{
+					RuleID:  finding.Package + "." + finding.Rule,
+					Level:   level,
+					Message: finding.Message,
+					Path:    src.Path,
+					Line:    src.Line,
+				})
+			}
+		}
+		results = append(results, result)
+	}
+
+	if output == "sarif" {
+		return failed, gator.WriteSarif(os.Stdout, sarif)
+	}
+	if output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return false, err
+		}
+		if coverageSet != nil {
+			return failed, emitCoverage(coverageSet, coverageFormat)
+		}
+		return failed, nil
+	}
+	for _, result := range results {
+		for _, msg := range result.Violations {
+			fmt.Printf("FAIL %s %s: %s\n", result.Kind, result.Name, msg)
+		}
+		for _, msg := range result.Warnings {
+			fmt.Printf("WARN %s %s: %s\n", result.Kind, result.Name, msg)
+		}
+		for _, finding := range result.Rego {
+			status := "FAIL"
+			if finding.Warning() {
+				status = "WARN"
+			}
+			fmt.Printf("%s %s %s: %s (%s.%s)\n", status, result.Kind, result.Name, finding.Message, finding.Package, finding.Rule)
+		}
+	}
+	fmt.Printf("%d objects evaluated\n", len(results))
+	if coverageSet != nil {
+		return failed, emitCoverage(coverageSet, coverageFormat)
+	}
+	return failed, nil
+}
+
+// runWhatIf evaluates the cluster's resources against two policy sets —
+// the templates and constraints installed in the cluster, and the
+// proposed ones from local directories — and prints the violations the
+// proposal would introduce or resolve. The local directories are treated
+// as the complete proposed policy set. Returns failed=true when the
+// proposal would introduce violations.
+func runWhatIf(templatesDir, constraintsDir, kubeconfig, output string) (bool, error) {
+	if templatesDir == "" {
+		return false, fmt.Errorf("-templates is required")
+	}
+	ctx := context.Background()
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		loadingRules.ExplicitPath = kubeconfig
+	}
+	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return false, fmt.Errorf("loading kubeconfig: %w", err)
+	}
+
+	liveClient, err := loadtest.NewClient()
+	if err != nil {
+		return false, fmt.Errorf("initializing live policy client: %w", err)
+	}
+	liveConstraints, err := gator.LoadClusterPolicies(ctx, cfg, liveClient)
+	if err != nil {
+		return false, err
+	}
+
+	proposedClient, err := loadtest.NewClient()
+	if err != nil {
+		return false, fmt.Errorf("initializing proposed policy client: %w", err)
+	}
+	if _, err := loadtest.LoadTemplates(ctx, proposedClient, templatesDir); err != nil {
+		return false, fmt.Errorf("loading proposed templates: %w", err)
+	}
+	var proposedConstraints []*unstructured.Unstructured
+	if constraintsDir != "" {
+		if _, err := loadtest.LoadConstraints(ctx, proposedClient, constraintsDir); err != nil {
+			return false, fmt.Errorf("loading proposed constraints: %w", err)
+		}
+		proposedConstraints, err = loadtest.LoadObjects(constraintsDir)
+		if err != nil {
+			return false, fmt.Errorf("loading proposed constraints: %w", err)
+		}
+	}
+
+	// fetch resources for the kinds either policy set matches, so both
+	// removals and additions are assessed over the same inputs
+	kinds, unmatched := gator.MatchedKinds(append(liveConstraints, proposedConstraints...))
+	for _, name := range unmatched {
+		fmt.Fprintf(os.Stderr, "warning: constraint %s has no concrete kinds matcher; its impact is not assessed\n", name)
+	}
+	objects, skipped, err := gator.FetchResources(ctx, cfg, kinds)
+	if err != nil {
+		return false, err
+	}
+	for _, note := range skipped {
+		fmt.Fprintf(os.Stderr, "warning: skipping kind %s\n", note)
+	}
+
+	liveViolations, err := gator.ReviewAll(ctx, liveClient, objects)
+	if err != nil {
+		return false, err
+	}
+	proposedViolations, err := gator.ReviewAll(ctx, proposedClient, objects)
+	if err != nil {
+		return false, err
+	}
+	delta := gator.DiffViolations(liveViolations, proposedViolations)
+
+	if output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(delta); err != nil {
+			return false, err
+		}
+		return len(delta.Introduced) > 0, nil
+	}
+	for _, v := range delta.Introduced {
+		fmt.Printf("INTRODUCED %s/%s %s %s: %s\n", v.ConstraintKind, v.ConstraintName, v.ResourceKind, resourceRef(v), v.Message)
+	}
+	for _, v := range delta.Resolved {
+		fmt.Printf("RESOLVED %s/%s %s %s: %s\n", v.ConstraintKind, v.ConstraintName, v.ResourceKind, resourceRef(v), v.Message)
+	}
+	fmt.Printf("%d resources evaluated: %d violations introduced, %d resolved, %d unchanged\n",
+		len(objects), len(delta.Introduced), len(delta.Resolved), delta.Unchanged)
+	return len(delta.Introduced) > 0, nil
+}
+
+func resourceRef(v gator.ImpactViolation) string {
+	if v.ResourceNamespace == "" {
+		return v.ResourceName
+	}
+	return v.ResourceNamespace + "/" + v.ResourceName
+}
+
+// emitCoverage writes the coverage report to stdout after the results.
+func emitCoverage(set *gator.CoverageSet, format string) error {
+	if format == "html" {
+		return set.WriteHTML(os.Stdout)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(set.Report())
+}
@@ -0,0 +1,234 @@
+// The policyctl command backs up and restores a cluster's policy state
+// and diagnoses a running installation. The backup subcommand exports
+// templates, constraints, mutators, exemptions, and the Config into a
+// versioned archive; the restore subcommand applies such an archive to
+// the current cluster, for disaster recovery and cluster migration.
+// The doctor subcommand inspects the installation — webhook
+// configuration and serving certs, template compile errors, missing
+// sync data, stale statuses, expired exemptions — and prints actionable
+// findings. The cluster is selected the usual way: --kubeconfig,
+// $KUBECONFIG, or in-cluster credentials.
+//
+// Usage:
+//
+// The violations subcommand pages through the chunked
+// ConstraintViolationList resources audit writes when
+// --emit-violation-lists is set, which hold the full violation list
+// beyond the cap on the constraint's status.
+//
+// Usage:
+//
+//	policyctl backup -file archive.json [-include-status]
+//	policyctl restore -file archive.json [-force]
+//	policyctl doctor [-namespace gatekeeper-system]
+//	policyctl violations -kind K8sRequiredLabels -name must-have-owner [-chunk n]
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	statusv1beta1 "github.com/open-policy-agent/gatekeeper/apis/status/v1beta1"
+	"github.com/open-policy-agent/gatekeeper/pkg/backup"
+	"github.com/open-policy-agent/gatekeeper/pkg/doctor"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+func main() {
+	if len(os.Args) < 2 || (os.Args[1] != "backup" && os.Args[1] != "restore" && os.Args[1] != "doctor" && os.Args[1] != "violations") {
+		fmt.Fprintln(os.Stderr, "usage: policyctl backup -file archive.json [-include-status] | policyctl restore -file archive.json [-force] | policyctl doctor [-namespace gatekeeper-system] | policyctl violations -kind kind -name name [-chunk n]")
+		os.Exit(2)
+	}
+	subcommand := os.Args[1]
+	flags := flag.NewFlagSet(subcommand, flag.ExitOnError)
+
+	if subcommand == "violations" {
+		var (
+			kind      = flags.String("kind", "", "kind of the constraint to show violations for")
+			name      = flags.String("name", "", "name of the constraint to show violations for")
+			chunk     = flags.Int("chunk", -1, "print only the given chunk; by default all chunks are printed in order")
+			namespace = flags.String("namespace", "gatekeeper-system", "namespace Gatekeeper is installed in")
+		)
+		if err := flags.Parse(os.Args[2:]); err != nil {
+			os.Exit(2)
+		}
+		if *kind == "" || *name == "" {
+			fmt.Fprintln(os.Stderr, "policyctl: -kind and -name are required")
+			os.Exit(2)
+		}
+		if err := runViolations(*kind, *name, *namespace, *chunk); err != nil {
+			fmt.Fprintf(os.Stderr, "policyctl: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if subcommand == "doctor" {
+		namespace := flags.String("namespace", "gatekeeper-system", "namespace Gatekeeper is installed in")
+		if err := flags.Parse(os.Args[2:]); err != nil {
+			os.Exit(2)
+		}
+		if err := runDoctor(*namespace); err != nil {
+			fmt.Fprintf(os.Stderr, "policyctl: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	var (
+		file          = flags.String("file", "", "path of the archive to write (backup) or read (restore)")
+		includeStatus = flags.Bool("include-status", false, "backup: keep object status in the archive")
+		force         = flags.Bool("force", false, "restore: overwrite policy objects that already exist")
+	)
+	if err := flags.Parse(os.Args[2:]); err != nil {
+		os.Exit(2)
+	}
+	if *file == "" {
+		fmt.Fprintln(os.Stderr, "policyctl: -file is required")
+		os.Exit(2)
+	}
+
+	if err := run(subcommand, *file, *includeStatus, *force); err != nil {
+		fmt.Fprintf(os.Stderr, "policyctl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runViolations pages through the constraint's chunked violation lists
+// in chunk order.
+func runViolations(kind, name, namespace string, chunk int) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return fmt.Errorf("loading cluster credentials: %w", err)
+	}
+	sch := runtime.NewScheme()
+	if err := statusv1beta1.AddToScheme(sch); err != nil {
+		return err
+	}
+	c, err := client.New(cfg, client.Options{Scheme: sch})
+	if err != nil {
+		return fmt.Errorf("building cluster client: %w", err)
+	}
+
+	chunks := &statusv1beta1.ConstraintViolationListList{}
+	if err := c.List(context.Background(), chunks,
+		client.InNamespace(namespace),
+		client.MatchingLabels{
+			statusv1beta1.ConstraintKindLabel: kind,
+			statusv1beta1.ConstraintNameLabel: name,
+		}); err != nil {
+		return fmt.Errorf("listing violation chunks: %w", err)
+	}
+	if len(chunks.Items) == 0 {
+		return fmt.Errorf("no violation lists found for %s %s; audit writes them when --emit-violation-lists is set", kind, name)
+	}
+	sort.Slice(chunks.Items, func(i, j int) bool {
+		return chunks.Items[i].ChunkIndex < chunks.Items[j].ChunkIndex
+	})
+
+	printed := 0
+	for i := range chunks.Items {
+		item := &chunks.Items[i]
+		if chunk >= 0 && item.ChunkIndex != chunk {
+			continue
+		}
+		fmt.Printf("chunk %d/%d audited at %s\n", item.ChunkIndex+1, item.TotalChunks, item.AuditTimestamp)
+		for _, v := range item.Violations {
+			ref := v.Name
+			if v.Namespace != "" {
+				ref = v.Namespace + "/" + v.Name
+			}
+			fmt.Printf("%s %s %s: %s\n", v.EnforcementAction, v.Kind, ref, v.Message)
+		}
+		printed++
+	}
+	if printed == 0 {
+		return fmt.Errorf("chunk %d not found; %s %s has %d chunk(s)", chunk, kind, name, len(chunks.Items))
+	}
+	return nil
+}
+
+// runDoctor checks the installation and prints findings, one per line.
+// It exits nonzero (via an error) when any error-severity finding is
+// present so it can gate CI and upgrade pipelines.
+func runDoctor(namespace string) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return fmt.Errorf("loading cluster credentials: %w", err)
+	}
+	c, err := client.New(cfg, client.Options{})
+	if err != nil {
+		return fmt.Errorf("building cluster client: %w", err)
+	}
+
+	findings, err := doctor.Run(context.Background(), c, namespace)
+	if err != nil {
+		return err
+	}
+	if len(findings) == 0 {
+		fmt.Println("no problems found")
+		return nil
+	}
+	errors := 0
+	for _, f := range findings {
+		fmt.Printf("%s: %s\n", f.Severity, f.Message)
+		if f.Severity == doctor.SeverityError {
+			errors++
+		}
+	}
+	if errors > 0 {
+		return fmt.Errorf("%d error(s) found", errors)
+	}
+	return nil
+}
+
+func run(subcommand, file string, includeStatus, force bool) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return fmt.Errorf("loading cluster credentials: %w", err)
+	}
+	c, err := client.New(cfg, client.Options{})
+	if err != nil {
+		return fmt.Errorf("building cluster client: %w", err)
+	}
+	ctx := context.Background()
+
+	if subcommand == "backup" {
+		archive, err := backup.Export(ctx, c, includeStatus)
+		if err != nil {
+			return err
+		}
+		f, err := os.Create(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := backup.Write(archive, f); err != nil {
+			return err
+		}
+		fmt.Printf("exported %d policy objects to %s\n", len(archive.Objects), file)
+		return nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	archive, err := backup.Read(f)
+	if err != nil {
+		return err
+	}
+	applied, err := backup.Restore(ctx, c, archive, force, time.Minute)
+	if err != nil {
+		return fmt.Errorf("applied %d objects before failing: %w", applied, err)
+	}
+	fmt.Printf("restored %d policy objects from archive taken %s\n", applied, archive.CreatedAt.Format(time.RFC3339))
+	return nil
+}
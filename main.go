@@ -16,6 +16,8 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"crypto/tls"
 	"flag"
 	"fmt"
 	"net/http"
@@ -29,19 +31,61 @@ import (
 	"github.com/open-policy-agent/frameworks/constraint/pkg/client/drivers/local"
 	api "github.com/open-policy-agent/gatekeeper/apis"
 	configv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/config/v1alpha1"
+	exemptionsv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/exemptions/v1alpha1"
 	mutationsv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/mutations/v1alpha1"
+	overridesv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/overrides/v1alpha1"
 	statusv1beta1 "github.com/open-policy-agent/gatekeeper/apis/status/v1beta1"
+	"github.com/open-policy-agent/gatekeeper/pkg/aggregator"
 	"github.com/open-policy-agent/gatekeeper/pkg/audit"
+	"github.com/open-policy-agent/gatekeeper/pkg/auditexport"
+	"github.com/open-policy-agent/gatekeeper/pkg/auditshard"
+	"github.com/open-policy-agent/gatekeeper/pkg/bootstrap"
+	"github.com/open-policy-agent/gatekeeper/pkg/bundlesig"
+	"github.com/open-policy-agent/gatekeeper/pkg/canary"
+	"github.com/open-policy-agent/gatekeeper/pkg/celengine"
+	"github.com/open-policy-agent/gatekeeper/pkg/certmanager"
+	"github.com/open-policy-agent/gatekeeper/pkg/cloudauth"
 	"github.com/open-policy-agent/gatekeeper/pkg/controller"
 	"github.com/open-policy-agent/gatekeeper/pkg/controller/config/process"
+	"github.com/open-policy-agent/gatekeeper/pkg/databudget"
+	"github.com/open-policy-agent/gatekeeper/pkg/decisionapi"
+	"github.com/open-policy-agent/gatekeeper/pkg/distribution"
+	"github.com/open-policy-agent/gatekeeper/pkg/drift"
+	"github.com/open-policy-agent/gatekeeper/pkg/extauthz"
+	"github.com/open-policy-agent/gatekeeper/pkg/externalcert"
+	"github.com/open-policy-agent/gatekeeper/pkg/externaldata"
+	"github.com/open-policy-agent/gatekeeper/pkg/fips"
+	"github.com/open-policy-agent/gatekeeper/pkg/grpcapi"
+	"github.com/open-policy-agent/gatekeeper/pkg/httpguard"
+	"github.com/open-policy-agent/gatekeeper/pkg/lazycompile"
+	"github.com/open-policy-agent/gatekeeper/pkg/loglevel"
+	"github.com/open-policy-agent/gatekeeper/pkg/matchindex"
 	"github.com/open-policy-agent/gatekeeper/pkg/metrics"
 	"github.com/open-policy-agent/gatekeeper/pkg/mutation"
+	"github.com/open-policy-agent/gatekeeper/pkg/opashard"
 	"github.com/open-policy-agent/gatekeeper/pkg/operations"
+	"github.com/open-policy-agent/gatekeeper/pkg/policyreport"
+	"github.com/open-policy-agent/gatekeeper/pkg/policysource"
+	"github.com/open-policy-agent/gatekeeper/pkg/policyswap"
+	"github.com/open-policy-agent/gatekeeper/pkg/rbacscope"
 	"github.com/open-policy-agent/gatekeeper/pkg/readiness"
+	"github.com/open-policy-agent/gatekeeper/pkg/settings"
+	"github.com/open-policy-agent/gatekeeper/pkg/sharding"
+	"github.com/open-policy-agent/gatekeeper/pkg/simulation"
+	"github.com/open-policy-agent/gatekeeper/pkg/spiffe"
+	"github.com/open-policy-agent/gatekeeper/pkg/statusbatch"
+	"github.com/open-policy-agent/gatekeeper/pkg/statusshard"
+	"github.com/open-policy-agent/gatekeeper/pkg/syncenc"
 	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	"github.com/open-policy-agent/gatekeeper/pkg/tenancy"
+	"github.com/open-policy-agent/gatekeeper/pkg/tfplan"
+	"github.com/open-policy-agent/gatekeeper/pkg/tlsopts"
+	"github.com/open-policy-agent/gatekeeper/pkg/tracing"
 	"github.com/open-policy-agent/gatekeeper/pkg/upgrade"
 	"github.com/open-policy-agent/gatekeeper/pkg/util"
+	"github.com/open-policy-agent/gatekeeper/pkg/vapgen"
 	"github.com/open-policy-agent/gatekeeper/pkg/version"
+	"github.com/open-policy-agent/gatekeeper/pkg/wasmengine"
 	"github.com/open-policy-agent/gatekeeper/pkg/watch"
 	"github.com/open-policy-agent/gatekeeper/pkg/webhook"
 	"github.com/open-policy-agent/gatekeeper/third_party/sigs.k8s.io/controller-runtime/pkg/dynamiccache"
@@ -65,6 +109,12 @@ var webhooks = []rotator.WebhookInfo{
 		Name: webhook.VwhName,
 		Type: rotator.Validating,
 	},
+	{
+		// the Config CRD serves v1 and v1alpha1 through a conversion
+		// webhook; the rotator keeps its caBundle injected
+		Name: "configs.config.gatekeeper.sh",
+		Type: rotator.CRDConversion,
+	},
 }
 
 const (
@@ -98,6 +148,12 @@ var (
 	disableCertRotation = flag.Bool("disable-cert-rotation", false, "disable automatic generation and rotation of webhook TLS certificates/keys")
 	enableProfile       = flag.Bool("enable-pprof", false, "enable pprof profiling")
 	profilePort         = flag.Int("pprof-port", 6060, "port for pprof profiling. defaulted to 6060 if unspecified")
+
+	enableLeaderElection        = flag.Bool("enable-leader-election", false, "enable leader election so only one instance reconciles when running multiple replicas. Leave disabled for single-replica audit/controller deployments")
+	leaderElectionID            = flag.String("leader-election-id", "gatekeeper.sh", "the name of the resource that leader election will use for holding the leader lock")
+	leaderElectionLeaseDuration = flag.Duration("leader-election-lease-duration", 15*time.Second, "the duration that non-leader candidates will wait to force acquire leadership")
+	leaderElectionRenewDeadline = flag.Duration("leader-election-renew-deadline", 10*time.Second, "the duration that the acting leader will retry refreshing leadership before giving up. Must be less than the lease duration")
+	leaderElectionRetryPeriod   = flag.Duration("leader-election-retry-period", 2*time.Second, "the duration leader election clients should wait between lock acquisition attempts. Must be less than the renew deadline")
 )
 
 func init() {
@@ -106,6 +162,8 @@ func init() {
 	_ = api.AddToScheme(scheme)
 
 	_ = configv1alpha1.AddToScheme(scheme)
+	_ = exemptionsv1alpha1.AddToScheme(scheme)
+	_ = overridesv1alpha1.AddToScheme(scheme)
 	_ = statusv1beta1.AddToScheme(scheme)
 	_ = mutationsv1alpha1.AddToScheme(scheme)
 	// +kubebuilder:scaffold:scheme
@@ -119,6 +177,64 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := statusshard.Validate(); err != nil {
+		setupLog.Error(err, "invalid status sharding flags")
+		os.Exit(1)
+	}
+
+	if err := tlsopts.Validate(); err != nil {
+		setupLog.Error(err, "invalid TLS flags")
+		os.Exit(1)
+	}
+
+	if err := webhook.ValidateClientCertFlags(); err != nil {
+		setupLog.Error(err, "invalid client certificate authentication flags")
+		os.Exit(1)
+	}
+
+	if err := spiffe.Validate(); err != nil {
+		setupLog.Error(err, "invalid SPIFFE flags")
+		os.Exit(1)
+	}
+
+	if err := rbacscope.Validate(); err != nil {
+		setupLog.Error(err, "invalid RBAC minimization flags")
+		os.Exit(1)
+	}
+
+	if err := bundlesig.Validate(); err != nil {
+		setupLog.Error(err, "invalid bundle signing flags")
+		os.Exit(1)
+	}
+
+	if err := cloudauth.Validate(); err != nil {
+		setupLog.Error(err, "invalid cloud auth flags")
+		os.Exit(1)
+	}
+
+	if err := auditexport.Validate(); err != nil {
+		setupLog.Error(err, "invalid audit export flags")
+		os.Exit(1)
+	}
+
+	if err := httpguard.Validate(); err != nil {
+		setupLog.Error(err, "invalid endpoint protection flags")
+		os.Exit(1)
+	}
+
+	if err := tracing.Validate(); err != nil {
+		setupLog.Error(err, "invalid tracing flags")
+		os.Exit(1)
+	}
+
+	if fips.Enabled() {
+		if err := fips.SelfTest(); err != nil {
+			setupLog.Error(err, "FIPS crypto self-test failed")
+			os.Exit(1)
+		}
+		setupLog.Info("FIPS mode enabled", "cryptoModule", fips.ModuleStatus())
+	}
+
 	if *enableProfile {
 		setupLog.Info("Starting profiling on port %s", *profilePort)
 		go func() {
@@ -132,7 +248,7 @@ func main() {
 		eCfg := zap.NewDevelopmentEncoderConfig()
 		eCfg.LevelKey = *logLevelKey
 		eCfg.EncodeLevel = encoder
-		ctrl.SetLogger(crzap.New(crzap.UseDevMode(true), crzap.Encoder(zapcore.NewConsoleEncoder(eCfg))))
+		ctrl.SetLogger(crzap.New(crzap.UseDevMode(true), crzap.Encoder(zapcore.NewConsoleEncoder(eCfg)), crzap.Level(loglevel.Enabler(zap.DebugLevel))))
 	case "WARNING", "ERROR":
 		setLoggerForProduction(encoder)
 	case "INFO":
@@ -141,21 +257,37 @@ func main() {
 		eCfg := zap.NewProductionEncoderConfig()
 		eCfg.LevelKey = *logLevelKey
 		eCfg.EncodeLevel = encoder
-		ctrl.SetLogger(crzap.New(crzap.UseDevMode(false), crzap.Encoder(zapcore.NewJSONEncoder(eCfg))))
+		ctrl.SetLogger(crzap.New(crzap.UseDevMode(false), crzap.Encoder(zapcore.NewJSONEncoder(eCfg)), crzap.Level(loglevel.Enabler(zap.InfoLevel))))
+	}
+	if *enableLeaderElection {
+		if *leaderElectionRenewDeadline >= *leaderElectionLeaseDuration {
+			setupLog.Error(fmt.Errorf("--leader-election-renew-deadline (%s) must be less than --leader-election-lease-duration (%s)", *leaderElectionRenewDeadline, *leaderElectionLeaseDuration), "invalid leader election flags")
+			os.Exit(1)
+		}
+		if *leaderElectionRetryPeriod >= *leaderElectionRenewDeadline {
+			setupLog.Error(fmt.Errorf("--leader-election-retry-period (%s) must be less than --leader-election-renew-deadline (%s)", *leaderElectionRetryPeriod, *leaderElectionRenewDeadline), "invalid leader election flags")
+			os.Exit(1)
+		}
 	}
+
 	config := ctrl.GetConfigOrDie()
 	config.UserAgent = version.GetUserAgent()
 
 	webhooks = webhook.AppendMutationWebhookIfEnabled(webhooks)
 
 	mgr, err := ctrl.NewManager(config, ctrl.Options{
-		NewCache:               dynamiccache.New,
-		Scheme:                 scheme,
-		MetricsBindAddress:     *metricsAddr,
-		LeaderElection:         false,
-		Port:                   *port,
-		CertDir:                *certDir,
-		HealthProbeBindAddress: *healthAddr,
+		NewCache:                dynamiccache.New,
+		Scheme:                  scheme,
+		MetricsBindAddress:      *metricsAddr,
+		LeaderElection:          *enableLeaderElection,
+		LeaderElectionID:        *leaderElectionID,
+		LeaderElectionNamespace: util.GetNamespace(),
+		LeaseDuration:           leaderElectionLeaseDuration,
+		RenewDeadline:           leaderElectionRenewDeadline,
+		RetryPeriod:             leaderElectionRetryPeriod,
+		Port:                    *port,
+		CertDir:                 *certDir,
+		HealthProbeBindAddress:  *healthAddr,
 		MapperProvider: func(c *rest.Config) (meta.RESTMapper, error) {
 			return apiutil.NewDynamicRESTMapper(c)
 		},
@@ -165,9 +297,48 @@ func main() {
 		os.Exit(1)
 	}
 
+	if (tlsopts.Enabled() || webhook.ClientCNRestricted() || fips.Enabled()) && operations.IsAssigned(operations.Webhook) {
+		setupLog.Info("hardening webhook server TLS configuration")
+		configureTLS := tlsopts.ApplyTo
+		if fips.Enabled() {
+			configureTLS = func(cfg *tls.Config) {
+				tlsopts.ApplyTo(cfg)
+				fips.ApplyTo(cfg)
+			}
+		}
+		if err := webhook.UseHardenedServer(mgr, *port, *certDir, configureTLS); err != nil {
+			setupLog.Error(err, "unable to set up hardened webhook server")
+			os.Exit(1)
+		}
+	}
+
 	// Make sure certs are generated and valid if cert rotation is enabled.
 	setupFinished := make(chan struct{})
-	if !*disableCertRotation && operations.IsAssigned(operations.Webhook) {
+	if certmanager.Enabled() && operations.IsAssigned(operations.Webhook) {
+		setupLog.Info("consuming certs from cert-manager")
+		if err := certmanager.AddInjector(mgr, &certmanager.Injector{
+			SecretKey: types.NamespacedName{
+				Namespace: util.GetNamespace(),
+				Name:      secretName,
+			},
+			CertDir:  *certDir,
+			Webhooks: webhooks,
+			IsReady:  setupFinished,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up cert-manager CA bundle injection")
+			os.Exit(1)
+		}
+	} else if externalcert.Enabled() && operations.IsAssigned(operations.Webhook) {
+		setupLog.Info("using externally provisioned certs")
+		if err := externalcert.AddWatcher(mgr, &externalcert.Watcher{
+			CertDir:  *certDir,
+			Webhooks: webhooks,
+			IsReady:  setupFinished,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up external cert watching")
+			os.Exit(1)
+		}
+	} else if !*disableCertRotation && operations.IsAssigned(operations.Webhook) {
 		setupLog.Info("setting up cert rotation")
 		if err := rotator.AddRotator(mgr, &rotator.CertRotator{
 			SecretKey: types.NamespacedName{
@@ -205,6 +376,48 @@ func main() {
 		setupLog.Error(err, "unable to create health check")
 		os.Exit(1)
 	}
+	if fips.Enabled() {
+		if err := mgr.AddHealthzCheck("fips", fips.Check); err != nil {
+			setupLog.Error(err, "unable to create FIPS health check")
+			os.Exit(1)
+		}
+	}
+	if rbacscope.Enabled() {
+		if err := rbacscope.AddAdvisor(mgr); err != nil {
+			setupLog.Error(err, "unable to set up RBAC minimization")
+			os.Exit(1)
+		}
+	}
+	if vapgen.Enabled() {
+		if err := vapgen.AddGenerator(mgr); err != nil {
+			setupLog.Error(err, "unable to set up admission policy generation")
+			os.Exit(1)
+		}
+	}
+	if policysource.Enabled() {
+		if err := policysource.AddSyncer(mgr, util.GetNamespace()); err != nil {
+			setupLog.Error(err, "unable to set up policy source syncing")
+			os.Exit(1)
+		}
+	}
+	if distribution.Enabled() {
+		if err := distribution.AddDistributor(mgr, util.GetNamespace()); err != nil {
+			setupLog.Error(err, "unable to set up policy distribution")
+			os.Exit(1)
+		}
+	}
+	if settings.Enabled() {
+		if err := settings.AddReconciler(mgr); err != nil {
+			setupLog.Error(err, "unable to set up GatekeeperConfig reconciliation")
+			os.Exit(1)
+		}
+	}
+	if tenancy.Enabled() {
+		if err := tenancy.AddProjector(mgr); err != nil {
+			setupLog.Error(err, "unable to set up namespaced constraint projection")
+			os.Exit(1)
+		}
+	}
 	// Setup controllers asynchronously, they will block for certificate generation if needed.
 	go setupControllers(mgr, sw, tracker, setupFinished)
 
@@ -230,16 +443,96 @@ func setupControllers(mgr ctrl.Manager, sw *watch.ControllerSwitch, tracker *rea
 	// Block until the setup (certificate generation) finishes.
 	<-setupFinished
 
+	// external data registers a Rego builtin, so it must be set up
+	// before any OPA client compiles a template
+	if externaldata.Enabled() {
+		externaldata.Setup(mgr.GetClient())
+	}
+
 	// initialize OPA
-	driver := local.New(local.Tracing(false))
-	backend, err := opa.NewBackend(opa.Driver(driver))
-	if err != nil {
-		setupLog.Error(err, "unable to set up OPA backend")
-		os.Exit(1)
+	newOpaClient := func() (*opa.Client, error) {
+		driver := local.New(local.Tracing(false))
+		backend, err := opa.NewBackend(opa.Driver(driver))
+		if err != nil {
+			return nil, err
+		}
+		return backend.NewClient(opa.Targets(&target.K8sValidationTarget{}))
 	}
-	client, err := backend.NewClient(opa.Targets(&target.K8sValidationTarget{}))
-	if err != nil {
-		setupLog.Error(err, "unable to set up OPA client")
+	var client opashard.Client
+	if opashard.Enabled() {
+		set, err := opashard.NewSet(opashard.Count(), newOpaClient)
+		if err != nil {
+			setupLog.Error(err, "unable to set up sharded OPA clients")
+			os.Exit(1)
+		}
+		client = set
+	} else {
+		single, err := newOpaClient()
+		if err != nil {
+			setupLog.Error(err, "unable to set up OPA client")
+		}
+		client = single
+	}
+
+	if syncenc.Enabled() {
+		encClient, err := syncenc.New(client)
+		if err != nil {
+			setupLog.Error(err, "unable to set up sync store encryption")
+			os.Exit(1)
+		}
+		client = encClient
+	}
+
+	if lazycompile.Enabled() {
+		lazyClient := lazycompile.New(client)
+		client = lazyClient
+		if lazycompile.WarmupEnabled() {
+			if err := mgr.Add(lazycompile.NewWarmer(lazyClient)); err != nil {
+				setupLog.Error(err, "unable to register template warm-up")
+				os.Exit(1)
+			}
+		}
+	}
+
+	if databudget.Enabled() {
+		budgeted := databudget.New(client)
+		client = budgeted
+		if err := mgr.Add(databudget.NewRefetcher(budgeted, mgr.GetClient())); err != nil {
+			setupLog.Error(err, "unable to register data refetcher")
+			os.Exit(1)
+		}
+	}
+
+	if matchindex.Enabled() {
+		client = matchindex.New(client)
+	}
+
+	if celengine.Enabled() {
+		client = celengine.New(client, mgr.GetClient())
+	}
+
+	if wasmengine.Enabled() {
+		client = wasmengine.New(client, mgr.GetClient())
+	}
+
+	if canary.Enabled() {
+		client = canary.New(client)
+	}
+
+	if policyswap.Enabled() {
+		swapClient := policyswap.New(client)
+		client = swapClient
+		if err := mgr.Add(policyswap.NewSwapper(swapClient)); err != nil {
+			setupLog.Error(err, "unable to register policy swapper")
+			os.Exit(1)
+		}
+	}
+
+	if bootstrap.Enabled() {
+		if err := bootstrap.Preload(context.Background(), client, bootstrap.Dir()); err != nil {
+			setupLog.Error(err, "unable to preload bootstrap policies")
+			os.Exit(1)
+		}
 	}
 
 	mutationCache := mutation.NewSystem()
@@ -288,6 +581,10 @@ func setupControllers(mgr ctrl.Manager, sw *watch.ControllerSwitch, tracker *rea
 	}
 	if operations.IsAssigned(operations.Audit) {
 		setupLog.Info("setting up audit")
+		if err := auditshard.Validate(); err != nil {
+			setupLog.Error(err, "invalid audit sharding configuration")
+			os.Exit(1)
+		}
 		if err := audit.AddToManager(mgr, client, processExcluder); err != nil {
 			setupLog.Error(err, "unable to register audit with the manager")
 			os.Exit(1)
@@ -305,6 +602,118 @@ func setupControllers(mgr ctrl.Manager, sw *watch.ControllerSwitch, tracker *rea
 		setupLog.Error(err, "unable to register metrics with the manager")
 		os.Exit(1)
 	}
+
+	if drift.Enabled() {
+		setupLog.Info("setting up policy drift detection")
+		driftManager, err := drift.New(mgr.GetClient())
+		if err != nil {
+			setupLog.Error(err, "unable to create drift detection manager")
+			os.Exit(1)
+		}
+		if err := mgr.Add(driftManager); err != nil {
+			setupLog.Error(err, "unable to register drift detection with the manager")
+			os.Exit(1)
+		}
+	}
+
+	if policyreport.Enabled() {
+		setupLog.Info("setting up effective policy report API")
+		if err := mgr.Add(policyreport.NewServer(mgr.GetClient(), mgr.GetConfig())); err != nil {
+			setupLog.Error(err, "unable to register policy report server with the manager")
+			os.Exit(1)
+		}
+	}
+
+	if aggregator.Enabled() {
+		setupLog.Info("setting up violation aggregation")
+		if err := mgr.Add(aggregator.NewServer()); err != nil {
+			setupLog.Error(err, "unable to register violation aggregator with the manager")
+			os.Exit(1)
+		}
+	}
+
+	if loglevel.Enabled() {
+		setupLog.Info("setting up runtime log level endpoint")
+		if err := mgr.Add(loglevel.NewServer()); err != nil {
+			setupLog.Error(err, "unable to register log level server with the manager")
+			os.Exit(1)
+		}
+	}
+
+	if tracing.Enabled() {
+		setupLog.Info("setting up OTLP trace exporter")
+		if err := mgr.Add(tracing.Setup()); err != nil {
+			setupLog.Error(err, "unable to register trace exporter with the manager")
+			os.Exit(1)
+		}
+	}
+
+	if sharding.Enabled() {
+		setupLog.Info("setting up webhook sharding")
+		shardManager, err := sharding.New(mgr.GetClient(), webhook.VwhName)
+		if err != nil {
+			setupLog.Error(err, "unable to create webhook sharding manager")
+			os.Exit(1)
+		}
+		if err := mgr.Add(shardManager); err != nil {
+			setupLog.Error(err, "unable to register webhook sharding with the manager")
+			os.Exit(1)
+		}
+	}
+
+	if simulation.Enabled() {
+		setupLog.Info("setting up simulation API")
+		simulator := simulation.New(client, mutationCache, mgr.GetClient())
+		if err := mgr.Add(simulation.NewServer(simulator)); err != nil {
+			setupLog.Error(err, "unable to register simulation server with the manager")
+			os.Exit(1)
+		}
+	}
+
+	if tfplan.Enabled() {
+		setupLog.Info("setting up Terraform plan validation API")
+		simulator := simulation.New(client, mutationCache, mgr.GetClient())
+		if err := mgr.Add(tfplan.NewServer(simulator)); err != nil {
+			setupLog.Error(err, "unable to register Terraform plan server with the manager")
+			os.Exit(1)
+		}
+	}
+
+	if decisionapi.Enabled() {
+		setupLog.Info("setting up policy decision API")
+		// Documents from non-Kubernetes callers reference namespaces that
+		// may not exist in the cluster, so no namespace reader is wired.
+		simulator := simulation.New(client, mutationCache, nil)
+		if err := mgr.Add(decisionapi.NewServer(simulator)); err != nil {
+			setupLog.Error(err, "unable to register decision server with the manager")
+			os.Exit(1)
+		}
+	}
+
+	if grpcapi.Enabled() {
+		setupLog.Info("setting up gRPC decision API")
+		simulator := simulation.New(client, mutationCache, mgr.GetClient())
+		if err := mgr.Add(grpcapi.NewServer(simulator)); err != nil {
+			setupLog.Error(err, "unable to register gRPC decision server with the manager")
+			os.Exit(1)
+		}
+	}
+
+	if extauthz.Enabled() {
+		setupLog.Info("setting up ext_authz API")
+		if err := mgr.Add(extauthz.NewServer(client)); err != nil {
+			setupLog.Error(err, "unable to register ext_authz server with the manager")
+			os.Exit(1)
+		}
+	}
+
+	if statusbatch.Enabled() {
+		setupLog.Info("setting up batched status updates")
+		if err := mgr.Add(statusbatch.New(mgr.GetClient())); err != nil {
+			setupLog.Error(err, "unable to register status batcher with the manager")
+			os.Exit(1)
+		}
+	}
 }
 
 func setLoggerForProduction(encoder zapcore.LevelEncoder) {
@@ -314,7 +723,7 @@ func setLoggerForProduction(encoder zapcore.LevelEncoder) {
 	encCfg.LevelKey = *logLevelKey
 	encCfg.EncodeLevel = encoder
 	enc := zapcore.NewJSONEncoder(encCfg)
-	lvl := zap.NewAtomicLevelAt(zap.WarnLevel)
+	lvl := loglevel.Enabler(zap.WarnLevel)
 	opts = append(opts, zap.AddStacktrace(zap.ErrorLevel),
 		zap.WrapCore(func(core zapcore.Core) zapcore.Core {
 			return zapcore.NewSamplerWithOptions(core, time.Second, 100, 100)
@@ -0,0 +1,126 @@
+// Package aggregator implements a fleet-level violation aggregator.
+// It collects violation exports pushed by gatekeeper audit pods running
+// in many clusters, dedupes them by fingerprint and serves a combined
+// view over HTTP so that organizations running gatekeeper across dozens
+// of clusters can reason about policy posture in one place.
+package aggregator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Violation is a single constraint violation reported by a member cluster.
+// The identity fields mirror the violations recorded on constraint status
+// by the audit process, with the addition of the reporting cluster.
+type Violation struct {
+	Cluster           string `json:"cluster"`
+	ConstraintKind    string `json:"constraintKind"`
+	ConstraintName    string `json:"constraintName"`
+	Kind              string `json:"kind"`
+	Name              string `json:"name"`
+	Namespace         string `json:"namespace,omitempty"`
+	Message           string `json:"message"`
+	EnforcementAction string `json:"enforcementAction"`
+}
+
+// Fingerprint returns a stable identifier for the violation, used to
+// dedupe repeated exports of the same finding across audit runs.
+func (v *Violation) Fingerprint() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s/%s/%s/%s/%s/%s/%s",
+		v.Cluster, v.ConstraintKind, v.ConstraintName, v.Kind, v.Namespace, v.Name, v.Message)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// AggregatedViolation is a deduped violation annotated with bookkeeping
+// maintained by the aggregator.
+type AggregatedViolation struct {
+	Violation   `json:",inline"`
+	Fingerprint string    `json:"fingerprint"`
+	FirstSeen   time.Time `json:"firstSeen"`
+	LastSeen    time.Time `json:"lastSeen"`
+	Count       int64     `json:"count"`
+}
+
+// Aggregator stores deduped violations from many clusters.
+// All methods are safe for concurrent use.
+type Aggregator struct {
+	mux        sync.RWMutex
+	violations map[string]*AggregatedViolation
+	now        func() time.Time
+}
+
+// New returns an empty Aggregator.
+func New() *Aggregator {
+	return &Aggregator{
+		violations: make(map[string]*AggregatedViolation),
+		now:        time.Now,
+	}
+}
+
+// Add records a batch of violations, deduping by fingerprint. Violations
+// already known to the aggregator have their lastSeen time and count
+// updated instead of being stored again.
+func (a *Aggregator) Add(violations ...Violation) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	now := a.now()
+	for i := range violations {
+		fp := violations[i].Fingerprint()
+		if existing, ok := a.violations[fp]; ok {
+			existing.LastSeen = now
+			existing.Count++
+			continue
+		}
+		a.violations[fp] = &AggregatedViolation{
+			Violation:   violations[i],
+			Fingerprint: fp,
+			FirstSeen:   now,
+			LastSeen:    now,
+			Count:       1,
+		}
+	}
+}
+
+// List returns the current set of deduped violations, optionally filtered
+// by cluster and/or constraint name. Results are sorted by fingerprint so
+// the combined view is stable between calls.
+func (a *Aggregator) List(cluster, constraint string) []AggregatedViolation {
+	a.mux.RLock()
+	defer a.mux.RUnlock()
+	var out []AggregatedViolation
+	for _, v := range a.violations {
+		if cluster != "" && v.Cluster != cluster {
+			continue
+		}
+		if constraint != "" && v.ConstraintName != constraint {
+			continue
+		}
+		out = append(out, *v)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Fingerprint < out[j].Fingerprint })
+	return out
+}
+
+// Prune drops violations that have not been re-reported within the
+// provided retention window and returns the number removed. Clusters are
+// expected to export on every audit run, so stale entries indicate the
+// violation was fixed or the cluster stopped reporting.
+func (a *Aggregator) Prune(retention time.Duration) int {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	cutoff := a.now().Add(-retention)
+	removed := 0
+	for fp, v := range a.violations {
+		if v.LastSeen.Before(cutoff) {
+			delete(a.violations, fp)
+			removed++
+		}
+	}
+	return removed
+}
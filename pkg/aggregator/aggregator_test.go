@@ -0,0 +1,76 @@
+package aggregator
+
+import (
+	"testing"
+	"time"
+)
+
+func makeViolation(cluster, constraint, name string) Violation {
+	return Violation{
+		Cluster:           cluster,
+		ConstraintKind:    "K8sRequiredLabels",
+		ConstraintName:    constraint,
+		Kind:              "Pod",
+		Name:              name,
+		Namespace:         "default",
+		Message:           "you must provide labels",
+		EnforcementAction: "deny",
+	}
+}
+
+func TestAddDedupes(t *testing.T) {
+	a := New()
+	v := makeViolation("cluster-a", "require-labels", "pod-1")
+	a.Add(v)
+	a.Add(v)
+	out := a.List("", "")
+	if len(out) != 1 {
+		t.Fatalf("expected 1 deduped violation, got %d", len(out))
+	}
+	if out[0].Count != 2 {
+		t.Errorf("expected count of 2, got %d", out[0].Count)
+	}
+}
+
+func TestFingerprintDistinguishesClusters(t *testing.T) {
+	a := New()
+	a.Add(makeViolation("cluster-a", "require-labels", "pod-1"))
+	a.Add(makeViolation("cluster-b", "require-labels", "pod-1"))
+	if got := len(a.List("", "")); got != 2 {
+		t.Fatalf("expected violations from separate clusters to be distinct, got %d", got)
+	}
+	if got := len(a.List("cluster-a", "")); got != 1 {
+		t.Errorf("expected 1 violation for cluster-a, got %d", got)
+	}
+}
+
+func TestListFiltersByConstraint(t *testing.T) {
+	a := New()
+	a.Add(makeViolation("cluster-a", "require-labels", "pod-1"))
+	a.Add(makeViolation("cluster-a", "require-limits", "pod-1"))
+	out := a.List("", "require-limits")
+	if len(out) != 1 {
+		t.Fatalf("expected 1 violation for require-limits, got %d", len(out))
+	}
+	if out[0].ConstraintName != "require-limits" {
+		t.Errorf("unexpected constraint: %s", out[0].ConstraintName)
+	}
+}
+
+func TestPruneRemovesStaleViolations(t *testing.T) {
+	a := New()
+	now := time.Now()
+	a.now = func() time.Time { return now }
+	a.Add(makeViolation("cluster-a", "require-labels", "pod-1"))
+
+	a.now = func() time.Time { return now.Add(2 * time.Hour) }
+	a.Add(makeViolation("cluster-a", "require-labels", "pod-2"))
+
+	if removed := a.Prune(time.Hour); removed != 1 {
+		t.Fatalf("expected 1 pruned violation, got %d", removed)
+	}
+	out := a.List("", "")
+	if len(out) != 1 || out[0].Name != "pod-2" {
+		t.Errorf("expected only pod-2 to survive pruning, got %v", out)
+	}
+}
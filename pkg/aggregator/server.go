@@ -0,0 +1,99 @@
+package aggregator
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"time"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/httpguard"
+	"github.com/open-policy-agent/gatekeeper/pkg/logging"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("aggregator").WithValues(logging.Process, "aggregator")
+
+const defaultRetention = 24 * time.Hour
+
+var (
+	enableViolationAggregation = flag.Bool("enable-violation-aggregation", false, "(alpha) enable the fleet-level violation aggregation endpoint")
+	aggregatorAddr             = flag.String("aggregator-addr", ":8891", "The address the violation aggregation endpoint binds to.")
+	aggregatorRetention        = flag.Duration("aggregator-retention", defaultRetention, "how long an aggregated violation is retained after it was last reported")
+)
+
+// Enabled returns true when the aggregation endpoint was requested via flag.
+func Enabled() bool {
+	return *enableViolationAggregation
+}
+
+// Server serves the combined violation view and accepts pushed exports.
+// It implements manager.Runnable so it can be registered with the
+// controller manager like the other optional servers.
+type Server struct {
+	aggregator *Aggregator
+	addr       string
+	retention  time.Duration
+}
+
+// NewServer returns a Server configured from the aggregator flags.
+func NewServer() *Server {
+	return &Server{
+		aggregator: New(),
+		addr:       *aggregatorAddr,
+		retention:  *aggregatorRetention,
+	}
+}
+
+// Start implements manager.Runnable.
+func (s *Server) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.Handle("/v1/violations", httpguard.Wrap(http.HandlerFunc(s.handleViolations)))
+	srv := &http.Server{Addr: s.addr, Handler: mux}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	ticker := time.NewTicker(s.retention / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if removed := s.aggregator.Prune(s.retention); removed > 0 {
+				log.Info("pruned stale violations", "count", removed)
+			}
+		case err := <-errCh:
+			return err
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return srv.Shutdown(shutdownCtx)
+		}
+	}
+}
+
+// handleViolations accepts pushed violation batches on POST and serves
+// the combined, deduped view on GET. GET supports `cluster` and
+// `constraint` query parameters to scope the response.
+func (s *Server) handleViolations(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodPost:
+		var violations []Violation
+		if err := json.NewDecoder(req.Body).Decode(&violations); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.aggregator.Add(violations...)
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodGet:
+		out := s.aggregator.List(req.URL.Query().Get("cluster"), req.URL.Query().Get("constraint"))
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(out); err != nil {
+			log.Error(err, "unable to encode aggregated violations")
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
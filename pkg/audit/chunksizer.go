@@ -0,0 +1,60 @@
+package audit
+
+import (
+	"flag"
+	"runtime"
+)
+
+const (
+	// pressureChunkSize is the chunk size adopted when memory pressure
+	// forces chunking on an otherwise unchunked audit.
+	pressureChunkSize = 500
+	// minChunkSize is the floor adaptive sizing will not shrink below.
+	minChunkSize = 50
+)
+
+var auditMemoryLimit = flag.Uint64("audit-memory-limit-bytes", 0, "(alpha) heap size at which audit list chunk sizes start shrinking to avoid OOM kills. Chunks shrink as usage approaches the limit and recover when pressure subsides. defaulted to 0 (disabled) if unspecified")
+
+// chunkSizer decides how many objects each audit List call requests,
+// shrinking the chunk size as heap usage approaches the configured
+// memory limit.
+type chunkSizer struct {
+	base  uint64 // configured chunk size; 0 means unchunked
+	limit uint64 // heap bytes considered full pressure; 0 disables
+}
+
+func newChunkSizer() *chunkSizer {
+	return &chunkSizer{base: *auditChunkSize, limit: *auditMemoryLimit}
+}
+
+// size returns the limit for the next List call. Pressure is sampled
+// per call so long-running audits recover their full chunk size once
+// memory is freed.
+func (s *chunkSizer) size() int64 {
+	if s.limit == 0 {
+		return int64(s.base)
+	}
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return int64(s.sizeForUsage(ms.HeapAlloc))
+}
+
+func (s *chunkSizer) sizeForUsage(usage uint64) uint64 {
+	// Under 75% of the limit, use the configured size as-is.
+	if usage < s.limit/4*3 {
+		return s.base
+	}
+	base := s.base
+	if base == 0 {
+		base = pressureChunkSize
+	}
+	// Above 90%, quarter the chunk size; above 75%, halve it.
+	size := base / 2
+	if usage >= s.limit/10*9 {
+		size = base / 4
+	}
+	if size < minChunkSize {
+		size = minChunkSize
+	}
+	return size
+}
@@ -0,0 +1,35 @@
+package audit
+
+import "testing"
+
+func TestChunkSizerDisabled(t *testing.T) {
+	s := &chunkSizer{base: 500, limit: 0}
+	if got := s.size(); got != 500 {
+		t.Errorf("expected configured chunk size with no memory limit, got %d", got)
+	}
+}
+
+func TestChunkSizerSizeForUsage(t *testing.T) {
+	tests := []struct {
+		name  string
+		base  uint64
+		limit uint64
+		usage uint64
+		want  uint64
+	}{
+		{name: "no pressure keeps base", base: 1000, limit: 1 << 30, usage: 1 << 20, want: 1000},
+		{name: "above 75 percent halves", base: 1000, limit: 1000, usage: 800, want: 500},
+		{name: "above 90 percent quarters", base: 1000, limit: 1000, usage: 950, want: 250},
+		{name: "floor is enforced", base: 100, limit: 1000, usage: 950, want: minChunkSize},
+		{name: "unchunked audits adopt chunking under pressure", base: 0, limit: 1000, usage: 800, want: pressureChunkSize / 2},
+		{name: "unchunked audits stay unchunked without pressure", base: 0, limit: 1000, usage: 100, want: 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &chunkSizer{base: tc.base, limit: tc.limit}
+			if got := s.sizeForUsage(tc.usage); got != tc.want {
+				t.Errorf("sizeForUsage(%d) = %d, want %d", tc.usage, got, tc.want)
+			}
+		})
+	}
+}
@@ -15,13 +15,13 @@ package audit
 import (
 	"context"
 
-	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
 	"github.com/open-policy-agent/gatekeeper/pkg/controller/config/process"
+	"github.com/open-policy-agent/gatekeeper/pkg/opashard"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
 // AddToManager adds audit manager to the Manager
-func AddToManager(m manager.Manager, opa *opa.Client, processExcluder *process.Excluder) error {
+func AddToManager(m manager.Manager, opa opashard.Client, processExcluder *process.Excluder) error {
 	if *auditInterval == 0 {
 		log.Info("auditing is disabled")
 		return nil
@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"flag"
+	"fmt"
+	"hash/fnv"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -11,14 +14,26 @@ import (
 	"github.com/go-logr/logr"
 	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
 	constraintTypes "github.com/open-policy-agent/frameworks/constraint/pkg/types"
+	overridesv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/overrides/v1alpha1"
+	"github.com/open-policy-agent/gatekeeper/pkg/auditdelta"
+	"github.com/open-policy-agent/gatekeeper/pkg/auditexport"
+	"github.com/open-policy-agent/gatekeeper/pkg/auditshard"
+	"github.com/open-policy-agent/gatekeeper/pkg/canary"
+	"github.com/open-policy-agent/gatekeeper/pkg/clusteridentity"
 	"github.com/open-policy-agent/gatekeeper/pkg/controller/config/process"
 	"github.com/open-policy-agent/gatekeeper/pkg/logging"
+	"github.com/open-policy-agent/gatekeeper/pkg/opashard"
+	"github.com/open-policy-agent/gatekeeper/pkg/override"
+	"github.com/open-policy-agent/gatekeeper/pkg/pubsub"
+	"github.com/open-policy-agent/gatekeeper/pkg/statusbatch"
 	"github.com/open-policy-agent/gatekeeper/pkg/target"
 	"github.com/open-policy-agent/gatekeeper/pkg/util"
+	"github.com/open-policy-agent/gatekeeper/pkg/wgpolicy"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -58,7 +73,7 @@ var (
 // Manager allows us to audit resources periodically
 type Manager struct {
 	client          client.Client
-	opa             *opa.Client
+	opa             opashard.Client
 	stopper         chan struct{}
 	stopped         chan struct{}
 	mgr             manager.Manager
@@ -69,6 +84,14 @@ type Manager struct {
 	processExcluder *process.Excluder
 	eventRecorder   record.EventRecorder
 	gkNamespace     string
+	clusterIdentity *clusteridentity.Provider
+	chunker         *chunkSizer
+	schedule        *auditSchedule
+
+	// incremental audit state: the last cycle's results per object,
+	// and a fingerprint of the policy set that produced them
+	incrState       map[string][]*constraintTypes.Result
+	incrFingerprint string
 }
 
 type auditResult struct {
@@ -116,7 +139,7 @@ func (c *nsCache) Get(ctx context.Context, client client.Client, namespace strin
 }
 
 // New creates a new manager for audit
-func New(ctx context.Context, mgr manager.Manager, opa *opa.Client, processExcluder *process.Excluder) (*Manager, error) {
+func New(ctx context.Context, mgr manager.Manager, opa opashard.Client, processExcluder *process.Excluder) (*Manager, error) {
 	reporter, err := newStatsReporter()
 	if err != nil {
 		log.Error(err, "StatsReporter could not start")
@@ -139,6 +162,9 @@ func New(ctx context.Context, mgr manager.Manager, opa *opa.Client, processExclu
 		processExcluder: processExcluder,
 		eventRecorder:   recorder,
 		gkNamespace:     util.GetNamespace(),
+		clusterIdentity: clusteridentity.New(mgr.GetClient(), util.GetNamespace()),
+		chunker:         newChunkSizer(),
+		schedule:        newAuditSchedule(),
 	}
 	return am, nil
 }
@@ -193,7 +219,19 @@ func (am *Manager) audit(ctx context.Context) error {
 		totalViolationsPerEnforcementAction[action] = 0
 	}
 
-	if *auditFromCache {
+	if auditdelta.Enabled() {
+		am.log.Info("Auditing incrementally from sync events")
+		res, err = am.incrementalResults(ctx, constraintsGVKs)
+		if err != nil {
+			return err
+		}
+		am.log.Info("Incremental audit results", "violations", len(res))
+
+		err := am.addAuditResponsesToUpdateLists(updateLists, res, totalViolationsPerConstraint, totalViolationsPerEnforcementAction, timestamp)
+		if err != nil {
+			return err
+		}
+	} else if *auditFromCache {
 		am.log.Info("Auditing from cache")
 		resp, err = am.opa.Audit(ctx)
 		if err != nil {
@@ -226,12 +264,108 @@ func (am *Manager) audit(ctx context.Context) error {
 		}
 	}
 
+	perConstraint := make(map[constraintTags]int64)
+	for link := range updateLists {
+		ar := updateLists[link][0]
+		perConstraint[constraintTags{
+			kind:              ar.constraint.GetKind(),
+			name:              ar.constraint.GetName(),
+			enforcementAction: ar.enforcementAction,
+		}] = totalViolationsPerConstraint[link]
+	}
+	if err := am.reporter.reportConstraintViolations(perConstraint); err != nil {
+		am.log.Error(err, "failed to report per-constraint violations")
+	}
+
 	// update constraints for each kind
 	am.writeAuditResults(ctx, constraintsGVKs, updateLists, timestamp, totalViolationsPerConstraint)
 
+	if pubsub.Enabled() {
+		if err := am.exportViolations(ctx, updateLists, timestamp); err != nil {
+			am.log.Error(err, "failed to export violations")
+		}
+	}
+
+	if wgpolicy.Enabled() {
+		if err := wgpolicy.Sync(ctx, am.client, am.policyReportEntries(updateLists), timestamp); err != nil {
+			am.log.Error(err, "failed to write policy reports")
+		}
+	}
+
+	if auditexport.Enabled() {
+		if err := auditexport.Export(ctx, timestamp, am.storageViolations(updateLists)); err != nil {
+			am.log.Error(err, "failed to export audit report to object storage")
+		}
+	}
+
 	return nil
 }
 
+// storageViolations flattens this run's violations for the object
+// storage export. Unlike constraint status this list is not capped, so
+// the export is the complete record of the run.
+func (am *Manager) storageViolations(updateLists map[util.KindVersionResource][]auditResult) []auditexport.Violation {
+	var violations []auditexport.Violation
+	for _, results := range updateLists {
+		for _, ar := range results {
+			violations = append(violations, auditexport.Violation{
+				ConstraintKind:    ar.cgvk.Kind,
+				ConstraintName:    ar.cname,
+				EnforcementAction: ar.enforcementAction,
+				ResourceKind:      ar.rkind,
+				ResourceNamespace: ar.rnamespace,
+				ResourceName:      ar.rname,
+				Message:           ar.message,
+			})
+		}
+	}
+	return violations
+}
+
+// policyReportEntries flattens this run's violations for PolicyReport
+// output.
+func (am *Manager) policyReportEntries(updateLists map[util.KindVersionResource][]auditResult) []wgpolicy.Entry {
+	var entries []wgpolicy.Entry
+	for _, results := range updateLists {
+		for _, ar := range results {
+			entries = append(entries, wgpolicy.Entry{
+				ConstraintKind:    ar.cgvk.Kind,
+				ConstraintName:    ar.cname,
+				ResourceKind:      ar.rkind,
+				ResourceNamespace: ar.rnamespace,
+				ResourceName:      ar.rname,
+				Message:           ar.message,
+				EnforcementAction: ar.enforcementAction,
+			})
+		}
+	}
+	return entries
+}
+
+// exportViolations publishes this run's violations to the configured
+// message broker.
+func (am *Manager) exportViolations(ctx context.Context, updateLists map[util.KindVersionResource][]auditResult, timestamp string) error {
+	var violations []pubsub.Violation
+	for _, results := range updateLists {
+		for _, ar := range results {
+			violations = append(violations, pubsub.Violation{
+				ConstraintKind:    ar.cgvk.Kind,
+				ConstraintName:    ar.cname,
+				ResourceKind:      ar.rkind,
+				ResourceNamespace: ar.rnamespace,
+				ResourceName:      ar.rname,
+				Message:           ar.message,
+				EnforcementAction: ar.enforcementAction,
+				AuditTimestamp:    timestamp,
+			})
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return pubsub.Export(ctx, am.client, am.gkNamespace, violations)
+}
+
 // Audits server resources via the discovery client, as an alternative to opa.Client.Audit()
 func (am *Manager) auditResources(
 	ctx context.Context,
@@ -342,12 +476,13 @@ func (am *Manager) auditResources(
 			}
 
 			objList := &unstructured.UnstructuredList{}
-			opts := &client.ListOptions{
-				Limit: int64(*auditChunkSize),
-			}
+			opts := &client.ListOptions{}
 			resourceVersion := ""
 
 			for {
+				// re-sampled per chunk so long audits shrink under memory
+				// pressure and recover once it subsides
+				opts.Limit = am.chunker.size()
 				objList.SetGroupVersionKind(schema.GroupVersionKind{
 					Group:   gv.Group,
 					Version: gv.Version,
@@ -362,8 +497,12 @@ func (am *Manager) auditResources(
 				}
 
 				for _, obj := range objList.Items {
+					// other audit shards review their own share
+					if auditshard.Enabled() && !auditshard.Mine(obj.GetNamespace(), obj.GetName()) {
+						continue
+					}
 					objNamespace := obj.GetNamespace()
-					isExcludedNamespace, err := am.skipExcludedNamespace(&obj)
+					isExcludedNamespace, err := am.skipExcludedNamespace(ctx, nsCache, &obj)
 					if err != nil {
 						log.Error(err, "error while excluding namespaces")
 					}
@@ -411,6 +550,104 @@ func (am *Manager) auditResources(
 	return nil
 }
 
+// incrementalResults returns the full violation picture while only
+// re-evaluating objects that changed since the last cycle. The state is
+// rebuilt from the OPA cache whenever the policy set changes, since a
+// template or constraint edit invalidates results for unchanged objects
+// too.
+func (am *Manager) incrementalResults(ctx context.Context, constraintsGVKs []schema.GroupVersionKind) ([]*constraintTypes.Result, error) {
+	fingerprint, err := am.policyFingerprint(ctx, constraintsGVKs)
+	if err != nil {
+		return nil, err
+	}
+	if am.incrState == nil || fingerprint != am.incrFingerprint {
+		am.log.Info("policy set changed, rebuilding incremental audit state from cache")
+		resp, err := am.opa.Audit(ctx)
+		if err != nil {
+			return nil, err
+		}
+		state := make(map[string][]*constraintTypes.Result)
+		for _, r := range resp.Results() {
+			resource, ok := r.Resource.(*unstructured.Unstructured)
+			if !ok {
+				continue
+			}
+			key := auditdelta.Key(resource)
+			state[key] = append(state[key], r)
+		}
+		am.incrState = state
+		am.incrFingerprint = fingerprint
+		// pending changes are already reflected in the rebuilt state
+		auditdelta.Drain()
+	} else {
+		nsCache := newNSCache()
+		for _, change := range auditdelta.Drain() {
+			delete(am.incrState, change.Key())
+			if change.Deleted {
+				continue
+			}
+			obj := change.Object
+			isExcludedNamespace, err := am.skipExcludedNamespace(ctx, nsCache, obj)
+			if err != nil {
+				am.log.Error(err, "error while excluding namespaces")
+			}
+			if isExcludedNamespace {
+				continue
+			}
+			ns := corev1.Namespace{}
+			if obj.GetNamespace() != "" {
+				ns, err = nsCache.Get(ctx, am.client, obj.GetNamespace())
+				if err != nil {
+					am.log.Error(err, "Unable to look up object namespace", "key", change.Key())
+					continue
+				}
+			}
+			resp, err := am.opa.Review(ctx, target.AugmentedUnstructured{Object: *obj, Namespace: &ns})
+			if err != nil {
+				am.log.Error(err, "Unable to review changed object", "key", change.Key())
+				continue
+			}
+			if results := resp.Results(); len(results) > 0 {
+				am.incrState[change.Key()] = results
+			}
+		}
+	}
+
+	var out []*constraintTypes.Result
+	for _, results := range am.incrState {
+		out = append(out, results...)
+	}
+	return out, nil
+}
+
+// policyFingerprint hashes the identity and generation of every
+// template and constraint, so incremental audit can tell when cached
+// results are stale. Generations are used rather than resource versions
+// because audit's own status writes bump the latter every cycle.
+func (am *Manager) policyFingerprint(ctx context.Context, constraintsGVKs []schema.GroupVersionKind) (string, error) {
+	var entries []string
+	listGVKs := append([]schema.GroupVersionKind{
+		{Group: "templates.gatekeeper.sh", Version: "v1beta1", Kind: "ConstraintTemplateList"},
+	}, constraintsGVKs...)
+	for _, gvk := range listGVKs {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk)
+		if err := am.client.List(ctx, list); err != nil {
+			return "", err
+		}
+		for i := range list.Items {
+			item := &list.Items[i]
+			entries = append(entries, fmt.Sprintf("%s/%s:%d", item.GetUID(), item.GetName(), item.GetGeneration()))
+		}
+	}
+	sort.Strings(entries)
+	h := fnv.New64a()
+	for _, entry := range entries {
+		_, _ = h.Write([]byte(entry))
+	}
+	return strconv.FormatUint(h.Sum64(), 16), nil
+}
+
 func (am *Manager) auditManagerLoop(ctx context.Context) {
 	ticker := time.NewTicker(time.Duration(*auditInterval) * time.Second)
 	defer ticker.Stop()
@@ -472,24 +709,80 @@ func (am *Manager) addAuditResponsesToUpdateLists(
 	totalViolationsPerConstraint map[util.KindVersionResource]int64,
 	totalViolationsPerEnforcementAction map[util.EnforcementAction]int64,
 	timestamp string) error {
+	var clusterLabels map[string]string
+	if am.clusterIdentity != nil {
+		clusterLabels = am.clusterIdentity.Labels(am.ctx)
+	}
 	for _, r := range res {
+		// when auditing from cache, results cover the full object space;
+		// keep only this shard's share
+		if auditshard.Enabled() {
+			if resource, ok := r.Resource.(*unstructured.Unstructured); ok && !auditshard.Mine(resource.GetNamespace(), resource.GetName()) {
+				continue
+			}
+		}
+		// constraints scoped to other cluster classes are not active here
+		if matched, err := util.ConstraintMatchesCluster(r.Constraint.Object, clusterLabels); err == nil && !matched {
+			continue
+		}
+		// canary shadow constraints exist only inside the policy engine;
+		// there is no cluster resource whose status could hold their
+		// violations
+		if canary.Enabled() && canary.IsCanaryConstraint(r.Constraint) {
+			continue
+		}
+		// constraints on a longer per-constraint audit interval keep the
+		// results of their last refresh this run
+		if !am.schedule.Due(r.Constraint, time.Now()) {
+			continue
+		}
 		key := util.GetUniqueKey(*r.Constraint)
 		totalViolationsPerConstraint[key]++
 		name := r.Constraint.GetName()
 		namespace := r.Constraint.GetNamespace()
 		apiVersion := r.Constraint.GetAPIVersion()
 		gvk := r.Constraint.GroupVersionKind()
-		enforcementAction := r.EnforcementAction
 		message := r.Msg
 		resource, ok := r.Resource.(*unstructured.Unstructured)
 		if !ok {
 			return errors.Errorf("could not cast resource as reviewResource: %v", r.Resource)
 		}
+		enforcementAction := r.EnforcementAction
+		// constraints may scope a different enforcement action to the
+		// audit enforcement point
+		if scoped, err := util.GetEnforcementActionForPoint(r.Constraint.Object, util.AuditEnforcementPoint); err == nil {
+			enforcementAction = string(scoped)
+		}
+		// namespace-scoped actions win over the global action and the
+		// audit-point override
+		if nsAction, found, err := util.GetEnforcementActionForNamespace(r.Constraint.Object, resource.GetNamespace()); err == nil && found {
+			enforcementAction = string(nsAction)
+		}
+		if enforcementAction == string(util.Deny) {
+			if relaxed, err := util.InMaintenanceWindow(r.Constraint.Object, time.Now()); err == nil && relaxed {
+				am.log.Info("enforcement relaxed by maintenance window",
+					logging.ConstraintName, r.Constraint.GetName(),
+					logging.ConstraintKind, r.Constraint.GetKind(),
+				)
+				enforcementAction = string(util.Dryrun)
+			}
+		}
+		// skip results from constraints whose field selectors do not
+		// match the resource
+		if matched, err := util.FieldSelectorsMatch(r.Constraint.Object, resource.Object); err == nil && !matched {
+			continue
+		}
+		// skip results outside the constraint's audit scope
+		if matched, err := util.AuditMatchesNamespace(r.Constraint.Object, resource.GetNamespace()); err == nil && !matched {
+			continue
+		}
 		rname := resource.GetName()
 		rkind := resource.GetKind()
 		rnamespace := resource.GetNamespace()
-		// append audit results only if it is below violations limit
-		if uint(len(updateLists[key])) < *constraintViolationsLimit {
+		// append audit results only if it is below violations limit; the
+		// chunked violation lists need every result, so the cap does not
+		// apply when they are enabled
+		if uint(len(updateLists[key])) < *constraintViolationsLimit || violationListsEnabled() {
 			result := auditResult{
 				cgvk:              gvk,
 				capiversion:       apiVersion,
@@ -529,26 +822,59 @@ func (am *Manager) writeAuditResults(ctx context.Context, constraintsGVKs []sche
 		}
 	}
 
+	// collect active overrides once so honored downgrades can be surfaced
+	// on each constraint's status
+	var overrides []overridesv1alpha1.ConstraintOverride
+	if override.Enabled() {
+		overrideList := &overridesv1alpha1.ConstraintOverrideList{}
+		if err := am.client.List(ctx, overrideList); err != nil {
+			// the ConstraintOverride CRD may not be installed
+			if !meta.IsNoMatchError(err) {
+				am.log.Error(err, "unable to list constraint overrides")
+			}
+		} else {
+			overrides = overrideList.Items
+		}
+	}
+
 	am.ucloop = &updateConstraintLoop{
-		client:  am.client,
-		stop:    make(chan struct{}),
-		stopped: make(chan struct{}),
-		ul:      updateLists,
-		ts:      timestamp,
-		tv:      totalViolations,
-		log:     am.log,
+		client:    am.client,
+		stop:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+		ul:        updateLists,
+		ts:        timestamp,
+		tv:        totalViolations,
+		log:       am.log,
+		overrides: overrides,
+		schedule:  am.schedule,
 	}
 
 	go am.ucloop.update(ctx, constraintsGVKs)
 }
 
-func (am *Manager) skipExcludedNamespace(obj *unstructured.Unstructured) (bool, error) {
+func (am *Manager) skipExcludedNamespace(ctx context.Context, cache *nsCache, obj *unstructured.Unstructured) (bool, error) {
 	isNamespaceExcluded, err := am.processExcluder.IsNamespaceExcluded(process.Audit, obj)
 	if err != nil {
 		return false, err
 	}
+	if isNamespaceExcluded {
+		return true, nil
+	}
+
+	// name-based exclusion did not apply; for namespaced objects, also
+	// evaluate any label-based exclusion against the owning namespace.
+	// IsNamespaceExcluded already checks labels on Namespace objects
+	// themselves.
+	objNamespace := obj.GetNamespace()
+	if objNamespace == "" || !am.processExcluder.HasNamespaceSelectors(process.Audit) {
+		return false, nil
+	}
+	ns, err := cache.Get(ctx, am.client, objNamespace)
+	if err != nil {
+		return false, err
+	}
 
-	return isNamespaceExcluded, err
+	return am.processExcluder.IsNamespaceExcludedByLabels(process.Audit, ns.Labels), nil
 }
 
 func (ucloop *updateConstraintLoop) updateConstraintStatus(ctx context.Context, instance *unstructured.Unstructured, auditResults []auditResult, timestamp string, totalViolations int64) error {
@@ -582,6 +908,34 @@ func (ucloop *updateConstraintLoop) updateConstraintStatus(ctx context.Context,
 	if err != nil {
 		return err
 	}
+	// fold this shard's results in with the other shards' before writing
+	if auditshard.Enabled() {
+		mergedViolations, mergedTotal, err := auditshard.Merge(instance, violations, totalViolations, timestamp, *constraintViolationsLimit)
+		if err != nil {
+			return err
+		}
+		violations = mergedViolations
+		totalViolations = mergedTotal
+	}
+	// surface honored overrides so delegated downgrades are visible on
+	// the constraint
+	var statusOverrides []interface{}
+	for i := range ucloop.overrides {
+		o := &ucloop.overrides[i]
+		if !o.Matches(instance.GetKind(), constraintName) || !override.ConstraintAllowsOverride(instance, o.GetNamespace()) {
+			continue
+		}
+		statusOverrides = append(statusOverrides, map[string]interface{}{
+			"namespace":         o.GetNamespace(),
+			"override":          o.GetName(),
+			"enforcementAction": "warn",
+		})
+	}
+	if len(statusOverrides) == 0 {
+		unstructured.RemoveNestedField(instance.Object, "status", "overrides")
+	} else if err := unstructured.SetNestedSlice(instance.Object, statusOverrides, "status", "overrides"); err != nil {
+		return err
+	}
 	// update constraint status auditTimestamp
 	if err = unstructured.SetNestedField(instance.Object, timestamp, "status", "auditTimestamp"); err != nil {
 		return err
@@ -600,21 +954,32 @@ func (ucloop *updateConstraintLoop) updateConstraintStatus(ctx context.Context,
 			unstructured.RemoveNestedField(instance.Object, "status", "violations")
 			ucloop.log.Info("removed status violations", "constraintName", constraintName)
 		}
-		err = ucloop.client.Status().Update(ctx, instance)
-		if err != nil {
-			return err
+		if !statusbatch.Enqueue(instance) {
+			err = ucloop.client.Status().Update(ctx, instance)
+			if err != nil {
+				return err
+			}
 		}
 	} else {
 		if err := unstructured.SetNestedSlice(instance.Object, violations, "status", "violations"); err != nil {
 			return err
 		}
 		ucloop.log.Info("constraint status update", "object", instance)
-		err = ucloop.client.Status().Update(ctx, instance)
-		if err != nil {
-			return err
+		if !statusbatch.Enqueue(instance) {
+			err = ucloop.client.Status().Update(ctx, instance)
+			if err != nil {
+				return err
+			}
 		}
 		ucloop.log.Info("updated constraint status violations", "constraintName", constraintName, "count", len(violations))
 	}
+	if violationListsEnabled() {
+		if err := ucloop.writeViolationLists(ctx, instance, auditResults); err != nil {
+			// chunk writes are best-effort relative to the status update,
+			// which has already landed
+			ucloop.log.Error(err, "could not write violation list chunks", "constraintName", constraintName)
+		}
+	}
 	return nil
 }
 
@@ -630,14 +995,16 @@ func truncateString(str string, size int) string {
 }
 
 type updateConstraintLoop struct {
-	uc      map[util.KindVersionResource]unstructured.Unstructured
-	client  client.Client
-	stop    chan struct{}
-	stopped chan struct{}
-	ul      map[util.KindVersionResource][]auditResult
-	ts      string
-	tv      map[util.KindVersionResource]int64
-	log     logr.Logger
+	uc        map[util.KindVersionResource]unstructured.Unstructured
+	client    client.Client
+	stop      chan struct{}
+	stopped   chan struct{}
+	ul        map[util.KindVersionResource][]auditResult
+	ts        string
+	tv        map[util.KindVersionResource]int64
+	log       logr.Logger
+	overrides []overridesv1alpha1.ConstraintOverride
+	schedule  *auditSchedule
 }
 
 func (ucloop *updateConstraintLoop) update(ctx context.Context, constraintsGVKs []schema.GroupVersionKind) {
@@ -704,6 +1071,12 @@ func (ucloop *updateConstraintLoop) update(ctx context.Context, constraintsGVKs
 					}
 				}
 				latestItemKey := util.GetUniqueKey(latestItem)
+				// constraints on a per-constraint interval that are not
+				// yet due keep the status of their last refresh
+				if !ucloop.schedule.Due(&latestItem, time.Now()) {
+					delete(ucloop.uc, key)
+					continue
+				}
 				totalViolations := ucloop.tv[latestItemKey]
 				if constraintAuditResults, ok := ucloop.ul[latestItemKey]; !ok {
 					err := ucloop.updateConstraintStatus(ctx, &latestItem, emptyAuditResults, ucloop.ts, totalViolations)
@@ -719,6 +1092,7 @@ func (ucloop *updateConstraintLoop) update(ctx context.Context, constraintsGVKs
 						continue
 					}
 				}
+				ucloop.schedule.Mark(&latestItem, time.Now())
 				delete(ucloop.uc, key)
 			}
 		}
@@ -770,9 +1144,12 @@ func logConstraint(l logr.Logger, constraint *unstructured.Unstructured, enforce
 func logViolation(l logr.Logger,
 	constraint *unstructured.Unstructured,
 	enforcementAction string, resourceGroupVersionKind schema.GroupVersionKind, rnamespace, rname, message string) {
+	owner, contact := util.GetConstraintOwner(constraint)
 	l.Info(
 		message,
 		logging.EventType, "violation_audited",
+		logging.ConstraintOwner, owner,
+		logging.ConstraintContact, contact,
 		logging.ConstraintGroup, constraint.GroupVersionKind().Group,
 		logging.ConstraintAPIVersion, constraint.GroupVersionKind().Version,
 		logging.ConstraintKind, constraint.GetKind(),
@@ -790,10 +1167,13 @@ func logViolation(l logr.Logger,
 func emitEvent(constraint *unstructured.Unstructured,
 	timestamp, enforcementAction string, resourceGroupVersionKind schema.GroupVersionKind, rnamespace, rname, message, gkNamespace string,
 	eventRecorder record.EventRecorder) {
+	owner, contact := util.GetConstraintOwner(constraint)
 	annotations := map[string]string{
 		"process":                    "audit",
 		"auditTimestamp":             timestamp,
 		logging.EventType:            "violation_audited",
+		logging.ConstraintOwner:      owner,
+		logging.ConstraintContact:    contact,
 		logging.ConstraintGroup:      constraint.GroupVersionKind().Group,
 		logging.ConstraintAPIVersion: constraint.GroupVersionKind().Version,
 		logging.ConstraintKind:       constraint.GetKind(),
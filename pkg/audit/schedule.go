@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/util"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// auditSchedule tracks when each constraint's results were last
+// refreshed, honoring per-constraint spec.audit.interval overrides.
+// Constraints that are not yet due keep the results of their last
+// refresh instead of being re-reported every global audit cycle. Access
+// is guarded because results collection for the next run can overlap the
+// previous run's status-writing loop.
+type auditSchedule struct {
+	mux  sync.Mutex
+	last map[util.KindVersionResource]time.Time
+}
+
+func newAuditSchedule() *auditSchedule {
+	return &auditSchedule{last: make(map[util.KindVersionResource]time.Time)}
+}
+
+// Due returns true when the constraint's results should be refreshed
+// this run. Constraints without an interval override are always due, as
+// are ones whose override does not parse: a broken override must not
+// silently stop auditing.
+func (s *auditSchedule) Due(constraint *unstructured.Unstructured, now time.Time) bool {
+	interval, found, err := util.GetAuditInterval(constraint.Object)
+	if err != nil || !found {
+		return true
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	last, audited := s.last[util.GetUniqueKey(*constraint)]
+	return !audited || now.Sub(last) >= interval
+}
+
+// Mark records that the constraint's results were refreshed.
+func (s *auditSchedule) Mark(constraint *unstructured.Unstructured, now time.Time) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.last[util.GetUniqueKey(*constraint)] = now
+}
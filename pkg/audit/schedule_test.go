@@ -0,0 +1,53 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func scheduledConstraint(name, interval string) *unstructured.Unstructured {
+	constraint := &unstructured.Unstructured{}
+	constraint.SetAPIVersion("constraints.gatekeeper.sh/v1beta1")
+	constraint.SetKind("K8sRequiredLabels")
+	constraint.SetName(name)
+	if interval != "" {
+		_ = unstructured.SetNestedField(constraint.Object, interval, "spec", "audit", "interval")
+	}
+	return constraint
+}
+
+func TestAuditScheduleDue(t *testing.T) {
+	schedule := newAuditSchedule()
+	now := time.Now()
+
+	hourly := scheduledConstraint("hourly", "1h")
+	if !schedule.Due(hourly, now) {
+		t.Errorf("a never-audited constraint should be due")
+	}
+	schedule.Mark(hourly, now)
+	if schedule.Due(hourly, now.Add(time.Minute)) {
+		t.Errorf("a constraint audited within its interval should not be due")
+	}
+	if !schedule.Due(hourly, now.Add(time.Hour)) {
+		t.Errorf("a constraint should come due once its interval elapses")
+	}
+}
+
+func TestAuditScheduleNoOverride(t *testing.T) {
+	schedule := newAuditSchedule()
+	now := time.Now()
+
+	plain := scheduledConstraint("plain", "")
+	schedule.Mark(plain, now)
+	if !schedule.Due(plain, now) {
+		t.Errorf("constraints without an interval override are always due")
+	}
+
+	broken := scheduledConstraint("broken", "often")
+	schedule.Mark(broken, now)
+	if !schedule.Due(broken, now) {
+		t.Errorf("a broken override must not stop auditing")
+	}
+}
@@ -12,17 +12,21 @@ import (
 )
 
 const (
-	violationsMetricName    = "violations"
-	auditDurationMetricName = "audit_duration_seconds"
-	lastRunTimeMetricName   = "audit_last_run_time"
+	violationsMetricName           = "violations"
+	constraintViolationsMetricName = "constraint_violations"
+	auditDurationMetricName        = "audit_duration_seconds"
+	lastRunTimeMetricName          = "audit_last_run_time"
 )
 
 var (
-	violationsM    = stats.Int64(violationsMetricName, "Total number of audited violations", stats.UnitDimensionless)
-	auditDurationM = stats.Float64(auditDurationMetricName, "Latency of audit operation in seconds", stats.UnitSeconds)
-	lastRunTimeM   = stats.Float64(lastRunTimeMetricName, "Timestamp of last audit run time", stats.UnitSeconds)
+	violationsM           = stats.Int64(violationsMetricName, "Total number of audited violations", stats.UnitDimensionless)
+	constraintViolationsM = stats.Int64(constraintViolationsMetricName, "Current number of audited violations per constraint", stats.UnitDimensionless)
+	auditDurationM        = stats.Float64(auditDurationMetricName, "Latency of audit operation in seconds", stats.UnitSeconds)
+	lastRunTimeM          = stats.Float64(lastRunTimeMetricName, "Timestamp of last audit run time", stats.UnitSeconds)
 
 	enforcementActionKey = tag.MustNewKey("enforcement_action")
+	kindKey              = tag.MustNewKey("kind")
+	nameKey              = tag.MustNewKey("name")
 )
 
 func init() {
@@ -39,6 +43,12 @@ func register() error {
 			Aggregation: view.LastValue(),
 			TagKeys:     []tag.Key{enforcementActionKey},
 		},
+		{
+			Name:        constraintViolationsMetricName,
+			Measure:     constraintViolationsM,
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{kindKey, nameKey, enforcementActionKey},
+		},
 		{
 			Name:        auditDurationMetricName,
 			Measure:     auditDurationM,
@@ -65,6 +75,48 @@ func (r *reporter) reportTotalViolations(enforcementAction util.EnforcementActio
 	return r.report(ctx, violationsM.M(v))
 }
 
+// constraintTags identifies one constraint's gauge series.
+type constraintTags struct {
+	kind              string
+	name              string
+	enforcementAction string
+}
+
+// reportConstraintViolations sets the per-constraint violation gauge
+// for the cycle. Constraints that had violations last cycle but none in
+// this one are reported as zero, so resolved constraints do not keep
+// stale values on the metrics endpoint.
+func (r *reporter) reportConstraintViolations(counts map[constraintTags]int64) error {
+	for tags, count := range counts {
+		if err := r.reportConstraintViolation(tags, count); err != nil {
+			return err
+		}
+	}
+	for tags := range r.lastConstraints {
+		if _, ok := counts[tags]; ok {
+			continue
+		}
+		if err := r.reportConstraintViolation(tags, 0); err != nil {
+			return err
+		}
+	}
+	r.lastConstraints = counts
+	return nil
+}
+
+func (r *reporter) reportConstraintViolation(tags constraintTags, count int64) error {
+	ctx, err := tag.New(
+		r.ctx,
+		tag.Insert(kindKey, tags.kind),
+		tag.Insert(nameKey, tags.name),
+		tag.Insert(enforcementActionKey, tags.enforcementAction))
+	if err != nil {
+		return err
+	}
+
+	return r.report(ctx, constraintViolationsM.M(count))
+}
+
 func (r *reporter) reportLatency(d time.Duration) error {
 	ctx, err := tag.New(r.ctx)
 	if err != nil {
@@ -93,6 +145,10 @@ func newStatsReporter() (*reporter, error) {
 
 type reporter struct {
 	ctx context.Context
+
+	// lastConstraints holds the series reported in the previous cycle,
+	// so series for resolved constraints can be zeroed.
+	lastConstraints map[constraintTags]int64
 }
 
 func (r *reporter) report(ctx context.Context, m stats.Measurement) error {
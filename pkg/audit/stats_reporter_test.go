@@ -112,3 +112,47 @@ func TestLastRestartCheck(t *testing.T) {
 		t.Errorf("Metric: %v - Expected %v, got %v", lastRunTimeMetricName, expectedTs, value.Value)
 	}
 }
+
+func TestReportConstraintViolations(t *testing.T) {
+	r, err := newStatsReporter()
+	if err != nil {
+		t.Errorf("newStatsReporter() error %v", err)
+	}
+	noisy := constraintTags{kind: "K8sRequiredLabels", name: "must-have-owner", enforcementAction: "deny"}
+	err = r.reportConstraintViolations(map[constraintTags]int64{noisy: 7})
+	if err != nil {
+		t.Errorf("reportConstraintViolations error %v", err)
+	}
+	row := checkData(t, constraintViolationsMetricName, 1)
+	value, ok := row.Data.(*view.LastValueData)
+	if !ok {
+		t.Error("reportConstraintViolations should have aggregation LastValue()")
+	}
+	if int64(value.Value) != 7 {
+		t.Errorf("Metric: %v - Expected 7, got %v", constraintViolationsMetricName, value.Value)
+	}
+	expectedTags := map[string]string{
+		"kind":               "K8sRequiredLabels",
+		"name":               "must-have-owner",
+		"enforcement_action": "deny",
+	}
+	for _, tag := range row.Tags {
+		if tag.Value != expectedTags[tag.Key.Name()] {
+			t.Errorf("reportConstraintViolations tags does not match for %v", tag.Key.Name())
+		}
+	}
+
+	// a constraint absent from the next cycle is zeroed, not left stale
+	err = r.reportConstraintViolations(map[constraintTags]int64{})
+	if err != nil {
+		t.Errorf("reportConstraintViolations error %v", err)
+	}
+	row = checkData(t, constraintViolationsMetricName, 1)
+	value, ok = row.Data.(*view.LastValueData)
+	if !ok {
+		t.Error("reportConstraintViolations should have aggregation LastValue()")
+	}
+	if int64(value.Value) != 0 {
+		t.Errorf("Metric: %v - Expected 0 after the constraint resolved, got %v", constraintViolationsMetricName, value.Value)
+	}
+}
@@ -0,0 +1,106 @@
+package audit
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	statusv1beta1 "github.com/open-policy-agent/gatekeeper/apis/status/v1beta1"
+	"github.com/open-policy-agent/gatekeeper/pkg/util"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const defaultViolationListChunkSize = 500
+
+var (
+	emitViolationLists     = flag.Bool("emit-violation-lists", false, "(alpha) store the full audit violation list in chunked ConstraintViolationList resources, lifting the per-constraint status cap")
+	violationListChunkSize = flag.Uint("violation-list-chunk-size", defaultViolationListChunkSize, "number of violations stored per ConstraintViolationList chunk. defaulted to 500 if unspecified")
+)
+
+func violationListsEnabled() bool {
+	return *emitViolationLists
+}
+
+// writeViolationLists stores the constraint's full violation list in
+// chunked ConstraintViolationList resources, then prunes chunks left
+// over from cycles that produced more of them. The constraint's status
+// keeps its capped copy; these resources carry the overflow.
+func (ucloop *updateConstraintLoop) writeViolationLists(ctx context.Context, constraint *unstructured.Unstructured, auditResults []auditResult) error {
+	chunkSize := int(*violationListChunkSize)
+	if chunkSize <= 0 {
+		chunkSize = defaultViolationListChunkSize
+	}
+
+	var violations []statusv1beta1.AuditViolation
+	for _, ar := range auditResults {
+		violations = append(violations, statusv1beta1.AuditViolation{
+			Kind:              ar.rkind,
+			Name:              ar.rname,
+			Namespace:         ar.rnamespace,
+			Message:           ar.message,
+			EnforcementAction: ar.enforcementAction,
+		})
+	}
+	total := (len(violations) + chunkSize - 1) / chunkSize
+
+	for i := 0; i < total; i++ {
+		end := (i + 1) * chunkSize
+		if end > len(violations) {
+			end = len(violations)
+		}
+		chunk, err := statusv1beta1.NewViolationListChunk(constraint, i, total, ucloop.ts, violations[i*chunkSize:end])
+		if err != nil {
+			return err
+		}
+		if err := ucloop.upsertViolationChunk(ctx, chunk); err != nil {
+			return err
+		}
+	}
+	return ucloop.pruneViolationChunks(ctx, constraint, total)
+}
+
+func (ucloop *updateConstraintLoop) upsertViolationChunk(ctx context.Context, chunk *statusv1beta1.ConstraintViolationList) error {
+	existing := &statusv1beta1.ConstraintViolationList{}
+	err := ucloop.client.Get(ctx, client.ObjectKey{Namespace: chunk.GetNamespace(), Name: chunk.GetName()}, existing)
+	switch {
+	case apierrors.IsNotFound(err):
+		return ucloop.client.Create(ctx, chunk)
+	case err != nil:
+		return err
+	default:
+		chunk.SetResourceVersion(existing.GetResourceVersion())
+		return ucloop.client.Update(ctx, chunk)
+	}
+}
+
+// pruneViolationChunks deletes the constraint's chunks at or past the
+// count the current cycle wrote, so a shrinking violation list does not
+// leave stale tails behind.
+func (ucloop *updateConstraintLoop) pruneViolationChunks(ctx context.Context, constraint *unstructured.Unstructured, total int) error {
+	existing := &statusv1beta1.ConstraintViolationListList{}
+	if err := ucloop.client.List(ctx, existing,
+		client.InNamespace(util.GetNamespace()),
+		client.MatchingLabels{
+			statusv1beta1.ConstraintNameLabel: constraint.GetName(),
+			statusv1beta1.ConstraintKindLabel: constraint.GetKind(),
+		}); err != nil {
+		return err
+	}
+	for i := range existing.Items {
+		chunk := &existing.Items[i]
+		index, err := strconv.Atoi(chunk.GetLabels()[statusv1beta1.ViolationChunkLabel])
+		if err != nil {
+			return fmt.Errorf("chunk %s has a malformed index label: %v", chunk.GetName(), err)
+		}
+		if index < total {
+			continue
+		}
+		if err := ucloop.client.Delete(ctx, chunk); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package auditdelta
+
+import (
+	"flag"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func testObject(namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("Pod")
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+func TestRecordCoalesces(t *testing.T) {
+	if err := flag.Set("audit-incremental", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = flag.Set("audit-incremental", "false")
+		Drain()
+	}()
+
+	Record(testObject("prod", "web"))
+	Record(testObject("prod", "web"))
+	Record(testObject("prod", "api"))
+	if got := Pending(); got != 2 {
+		t.Errorf("Pending() = %d, want 2; changes to one object should coalesce", got)
+	}
+
+	// a deletion supersedes earlier updates to the same object
+	RecordDeletion(testObject("prod", "api"))
+	changes := Drain()
+	if len(changes) != 2 {
+		t.Fatalf("Drain() returned %d changes, want 2", len(changes))
+	}
+	for _, c := range changes {
+		if c.Object.GetName() == "api" && !c.Deleted {
+			t.Error("the deletion should supersede the earlier update")
+		}
+		if c.Object.GetName() == "web" && c.Deleted {
+			t.Error("the update should not be marked deleted")
+		}
+	}
+
+	if got := Pending(); got != 0 {
+		t.Errorf("Pending() = %d after Drain, want 0", got)
+	}
+}
+
+func TestRecordDisabled(t *testing.T) {
+	Record(testObject("prod", "web"))
+	if got := Pending(); got != 0 {
+		t.Errorf("Pending() = %d, want 0 when incremental audit is disabled", got)
+	}
+}
+
+func TestKey(t *testing.T) {
+	namespaced := Key(testObject("prod", "web"))
+	clusterScoped := Key(testObject("", "web"))
+	if namespaced == clusterScoped {
+		t.Error("keys should distinguish namespaces")
+	}
+}
@@ -0,0 +1,354 @@
+// Package auditexport persists full audit violation reports to object
+// storage. Constraint status caps the violations it records, so large
+// clusters silently lose findings; the exporter writes every violation
+// of an audit run as a timestamped JSON or CSV object to S3, GCS, or
+// Azure Blob Storage, authenticating through the cluster's workload
+// identity integration via the cloudauth package.
+package auditexport
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/cloudauth"
+	"github.com/open-policy-agent/gatekeeper/pkg/logging"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("audit-export").WithValues(logging.Process, "audit")
+
+var (
+	enableAuditExport = flag.Bool("enable-audit-export", false, "(alpha) persist full audit violation reports to object storage")
+	auditExportURL    = flag.String("audit-export-url", "", "destination for audit reports: s3://bucket[/prefix], gs://bucket[/prefix], or azblob://account/container[/prefix]")
+	auditExportFormat = flag.String("audit-export-format", "json", "format audit reports are written in: 'json' or 'csv'")
+	auditExportRegion = flag.String("audit-export-region", "us-east-1", "region of the S3 bucket audit reports are written to")
+)
+
+// Endpoints, overridable in tests.
+var (
+	s3BaseURL    = "https://%s.s3.%s.amazonaws.com"
+	gcsUploadURL = "https://storage.googleapis.com/upload/storage/v1/b/%s/o"
+	azureBaseURL = "https://%s.blob.core.windows.net"
+)
+
+// Enabled returns true when audit export was requested via flag.
+func Enabled() bool {
+	return *enableAuditExport
+}
+
+// Validate checks the audit export flags. Called from main.
+func Validate() error {
+	if !Enabled() {
+		return nil
+	}
+	if *auditExportFormat != "json" && *auditExportFormat != "csv" {
+		return fmt.Errorf("--audit-export-format must be 'json' or 'csv', got %q", *auditExportFormat)
+	}
+	_, err := parseTarget(*auditExportURL)
+	return err
+}
+
+// Violation is one audit finding as persisted to object storage.
+type Violation struct {
+	ConstraintKind    string `json:"constraintKind"`
+	ConstraintName    string `json:"constraintName"`
+	EnforcementAction string `json:"enforcementAction"`
+	ResourceKind      string `json:"resourceKind"`
+	ResourceNamespace string `json:"resourceNamespace,omitempty"`
+	ResourceName      string `json:"resourceName"`
+	Message           string `json:"message"`
+}
+
+// report is the JSON document written for one audit run.
+type report struct {
+	AuditTimestamp string      `json:"auditTimestamp"`
+	ViolationCount int         `json:"violationCount"`
+	Violations     []Violation `json:"violations"`
+}
+
+// uploader writes one object to a storage backend.
+type uploader interface {
+	put(ctx context.Context, object string, data []byte, contentType string) error
+}
+
+// target is the parsed destination.
+type target struct {
+	scheme string
+	// bucket is the S3/GCS bucket or the Azure storage account.
+	bucket string
+	// container is only used for Azure.
+	container string
+	prefix    string
+}
+
+// parseTarget splits an export URL into its components.
+func parseTarget(raw string) (*target, error) {
+	if raw == "" {
+		return nil, fmt.Errorf("--audit-export-url is required when audit export is enabled")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parsing --audit-export-url: %w", err)
+	}
+	path := strings.Trim(u.Path, "/")
+	t := &target{scheme: u.Scheme, bucket: u.Host}
+	switch u.Scheme {
+	case "s3", "gs":
+		if t.bucket == "" {
+			return nil, fmt.Errorf("%s URL %q has no bucket", u.Scheme, raw)
+		}
+		t.prefix = path
+	case "azblob":
+		parts := strings.SplitN(path, "/", 2)
+		if t.bucket == "" || parts[0] == "" {
+			return nil, fmt.Errorf("azblob URL %q must be azblob://account/container[/prefix]", raw)
+		}
+		t.container = parts[0]
+		if len(parts) == 2 {
+			t.prefix = parts[1]
+		}
+	default:
+		return nil, fmt.Errorf("unsupported audit export scheme %q; use s3, gs, or azblob", u.Scheme)
+	}
+	return t, nil
+}
+
+var (
+	exporterOnce sync.Once
+	exporter     uploader
+	exporterErr  error
+	exportTarget *target
+)
+
+// Export writes the violations of one audit run as a timestamped object.
+// The sink is built lazily from flags on first use.
+func Export(ctx context.Context, timestamp string, violations []Violation) error {
+	exporterOnce.Do(func() {
+		exportTarget, exporterErr = parseTarget(*auditExportURL)
+		if exporterErr != nil {
+			return
+		}
+		var source cloudauth.TokenSource
+		source, exporterErr = cloudauth.NewSource()
+		if exporterErr != nil {
+			return
+		}
+		switch exportTarget.scheme {
+		case "s3":
+			exporter = &s3Uploader{bucket: exportTarget.bucket, region: *auditExportRegion, source: source}
+		case "gs":
+			exporter = &gcsUploader{bucket: exportTarget.bucket, source: source}
+		case "azblob":
+			exporter = &azureUploader{account: exportTarget.bucket, container: exportTarget.container, source: source}
+		}
+	})
+	if exporterErr != nil {
+		return exporterErr
+	}
+
+	data, contentType, err := encode(timestamp, violations, *auditExportFormat)
+	if err != nil {
+		return err
+	}
+	object := objectName(exportTarget.prefix, timestamp, *auditExportFormat)
+	if err := exporter.put(ctx, object, data, contentType); err != nil {
+		return fmt.Errorf("uploading audit report %s: %w", object, err)
+	}
+	log.Info("audit report exported", "object", object, "violations", len(violations))
+	return nil
+}
+
+// objectName builds the timestamped object key for a report.
+func objectName(prefix, timestamp, format string) string {
+	stamp := strings.NewReplacer(":", "", "-", "").Replace(timestamp)
+	name := fmt.Sprintf("audit-%s.%s", stamp, format)
+	if prefix != "" {
+		return prefix + "/" + name
+	}
+	return name
+}
+
+// encode renders the report in the configured format.
+func encode(timestamp string, violations []Violation, format string) ([]byte, string, error) {
+	if format == "csv" {
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		records := [][]string{{"constraint_kind", "constraint_name", "enforcement_action", "resource_kind", "resource_namespace", "resource_name", "message"}}
+		for _, v := range violations {
+			records = append(records, []string{v.ConstraintKind, v.ConstraintName, v.EnforcementAction, v.ResourceKind, v.ResourceNamespace, v.ResourceName, v.Message})
+		}
+		if err := w.WriteAll(records); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "text/csv", nil
+	}
+	data, err := json.Marshal(report{AuditTimestamp: timestamp, ViolationCount: len(violations), Violations: violations})
+	if err != nil {
+		return nil, "", err
+	}
+	return data, "application/json", nil
+}
+
+// gcsUploader writes via the GCS JSON API with a bearer token.
+type gcsUploader struct {
+	bucket string
+	source cloudauth.TokenSource
+}
+
+func (g *gcsUploader) put(ctx context.Context, object string, data []byte, contentType string) error {
+	token, err := g.source.Token(ctx)
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf(gcsUploadURL, g.bucket) + "?uploadType=media&name=" + url.QueryEscape(object)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.Value)
+	req.Header.Set("Content-Type", contentType)
+	return doUpload(req)
+}
+
+// azureUploader writes block blobs with a bearer token.
+type azureUploader struct {
+	account   string
+	container string
+	source    cloudauth.TokenSource
+}
+
+func (a *azureUploader) put(ctx context.Context, object string, data []byte, contentType string) error {
+	token, err := a.source.Token(ctx)
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf(azureBaseURL, a.account) + "/" + a.container + "/" + object
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.Value)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	req.Header.Set("x-ms-version", "2020-04-08")
+	return doUpload(req)
+}
+
+// s3Uploader writes objects with SigV4 request signing using the STS
+// credentials cloudauth carries for AWS.
+type s3Uploader struct {
+	bucket string
+	region string
+	source cloudauth.TokenSource
+}
+
+func (s *s3Uploader) put(ctx context.Context, object string, data []byte, contentType string) error {
+	token, err := s.source.Token(ctx)
+	if err != nil {
+		return err
+	}
+	creds := cloudauth.AWSCredentials{}
+	if err := json.Unmarshal([]byte(token.Value), &creds); err != nil {
+		return fmt.Errorf("decoding AWS credentials: %w", err)
+	}
+	endpoint := fmt.Sprintf(s3BaseURL, s.bucket, s.region) + "/" + object
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	signV4(req, creds, s.region, data, time.Now().UTC())
+	return doUpload(req)
+}
+
+// signV4 signs the request with AWS Signature Version 4 for the s3
+// service.
+func signV4(req *http.Request, creds cloudauth.AWSCredentials, region string, payload []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+	payloadHash := sha256.Sum256(payload)
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", hex.EncodeToString(payloadHash[:]))
+	if creds.SessionToken != "" {
+		req.Header.Set("x-amz-security-token", creds.SessionToken)
+	}
+
+	signedHeaders := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	if creds.SessionToken != "" {
+		signedHeaders = append(signedHeaders, "x-amz-security-token")
+	}
+	var canonicalHeaders strings.Builder
+	for _, h := range signedHeaders {
+		value := req.Header.Get(h)
+		if h == "host" {
+			value = req.Host
+			if value == "" {
+				value = req.URL.Host
+			}
+		}
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", h, strings.TrimSpace(value))
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := strings.Join([]string{dateStamp, region, "s3", "aws4_request"}, "/")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), dateStamp)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, strings.Join(signedHeaders, ";"), signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// doUpload executes the request and surfaces non-2xx responses as
+// errors.
+func doUpload(req *http.Request) error {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("storage returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package auditexport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/cloudauth"
+)
+
+type fakeSource struct {
+	value string
+}
+
+func (f *fakeSource) Token(ctx context.Context) (cloudauth.Token, error) {
+	return cloudauth.Token{Value: f.value, Expiry: time.Now().Add(time.Hour)}, nil
+}
+
+func sampleViolations() []Violation {
+	return []Violation{
+		{
+			ConstraintKind:    "K8sRequiredLabels",
+			ConstraintName:    "must-have-owner",
+			EnforcementAction: "deny",
+			ResourceKind:      "Pod",
+			ResourceNamespace: "shipping",
+			ResourceName:      "frontend",
+			Message:           "missing owner label",
+		},
+	}
+}
+
+func TestParseTarget(t *testing.T) {
+	tc := []struct {
+		url                               string
+		scheme, bucket, container, prefix string
+		wantErr                           bool
+	}{
+		{url: "s3://my-bucket/audit", scheme: "s3", bucket: "my-bucket", prefix: "audit"},
+		{url: "s3://my-bucket", scheme: "s3", bucket: "my-bucket"},
+		{url: "gs://my-bucket/a/b", scheme: "gs", bucket: "my-bucket", prefix: "a/b"},
+		{url: "azblob://account/container/reports", scheme: "azblob", bucket: "account", container: "container", prefix: "reports"},
+		{url: "azblob://account/container", scheme: "azblob", bucket: "account", container: "container"},
+		{url: "azblob://account", wantErr: true},
+		{url: "file:///tmp/audit", wantErr: true},
+		{url: "", wantErr: true},
+	}
+	for _, tt := range tc {
+		got, err := parseTarget(tt.url)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseTarget(%q): expected error, got none", tt.url)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseTarget(%q): unexpected error: %v", tt.url, err)
+			continue
+		}
+		if got.scheme != tt.scheme || got.bucket != tt.bucket || got.container != tt.container || got.prefix != tt.prefix {
+			t.Errorf("parseTarget(%q) = %+v", tt.url, got)
+		}
+	}
+}
+
+func TestObjectName(t *testing.T) {
+	name := objectName("reports", "2021-02-03T04:05:06Z", "json")
+	if name != "reports/audit-20210203T040506Z.json" {
+		t.Errorf("unexpected object name %q", name)
+	}
+	name = objectName("", "2021-02-03T04:05:06Z", "csv")
+	if name != "audit-20210203T040506Z.csv" {
+		t.Errorf("unexpected object name %q", name)
+	}
+}
+
+func TestEncodeJSON(t *testing.T) {
+	data, contentType, err := encode("2021-02-03T04:05:06Z", sampleViolations(), "json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if contentType != "application/json" {
+		t.Errorf("content type = %q", contentType)
+	}
+	decoded := report{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("report is not valid JSON: %v", err)
+	}
+	if decoded.ViolationCount != 1 || len(decoded.Violations) != 1 {
+		t.Errorf("unexpected report: %+v", decoded)
+	}
+}
+
+func TestEncodeCSV(t *testing.T) {
+	data, contentType, err := encode("2021-02-03T04:05:06Z", sampleViolations(), "csv")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if contentType != "text/csv" {
+		t.Errorf("content type = %q", contentType)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected header and one record, got %d lines", len(lines))
+	}
+	if !strings.HasPrefix(lines[0], "constraint_kind,") {
+		t.Errorf("unexpected header %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "must-have-owner") {
+		t.Errorf("unexpected record %q", lines[1])
+	}
+}
+
+func TestGCSUpload(t *testing.T) {
+	var gotAuth, gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotQuery = r.URL.RawQuery
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	oldURL := gcsUploadURL
+	gcsUploadURL = server.URL + "/upload/storage/v1/b/%s/o"
+	defer func() { gcsUploadURL = oldURL }()
+
+	up := &gcsUploader{bucket: "my-bucket", source: &fakeSource{value: "gcs-token"}}
+	if err := up.put(context.Background(), "audit-1.json", []byte("{}"), "application/json"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotAuth != "Bearer gcs-token" {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+	if !strings.Contains(gotQuery, "name=audit-1.json") {
+		t.Errorf("query = %q", gotQuery)
+	}
+}
+
+func TestAzureUpload(t *testing.T) {
+	var gotBlobType, gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBlobType = r.Header.Get("x-ms-blob-type")
+		gotPath = r.URL.Path
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	oldURL := azureBaseURL
+	azureBaseURL = server.URL + "/%s-suffix"
+	defer func() { azureBaseURL = oldURL }()
+
+	up := &azureUploader{account: "account", container: "container", source: &fakeSource{value: "az-token"}}
+	if err := up.put(context.Background(), "audit-1.json", []byte("{}"), "application/json"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotBlobType != "BlockBlob" {
+		t.Errorf("x-ms-blob-type = %q", gotBlobType)
+	}
+	if gotPath != "/account-suffix/container/audit-1.json" {
+		t.Errorf("path = %q", gotPath)
+	}
+}
+
+func TestS3Upload(t *testing.T) {
+	var gotAuth, gotToken, gotSHA string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotToken = r.Header.Get("x-amz-security-token")
+		gotSHA = r.Header.Get("x-amz-content-sha256")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	oldURL := s3BaseURL
+	s3BaseURL = server.URL + "/%s.%s"
+	defer func() { s3BaseURL = oldURL }()
+
+	creds, _ := json.Marshal(cloudauth.AWSCredentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "secret",
+		SessionToken:    "session",
+	})
+	up := &s3Uploader{bucket: "my-bucket", region: "us-east-1", source: &fakeSource{value: string(creds)}}
+	if err := up.put(context.Background(), "audit-1.json", []byte("{}"), "application/json"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(gotAuth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+	if !strings.Contains(gotAuth, "SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-security-token") {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+	if gotToken != "session" || gotSHA == "" {
+		t.Errorf("security token = %q, content sha = %q", gotToken, gotSHA)
+	}
+}
+
+func TestSignV4Deterministic(t *testing.T) {
+	creds := cloudauth.AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"}
+	now := time.Date(2021, 2, 3, 4, 5, 6, 0, time.UTC)
+	sign := func() string {
+		req, _ := http.NewRequest(http.MethodPut, "https://my-bucket.s3.us-east-1.amazonaws.com/audit.json", nil)
+		signV4(req, creds, "us-east-1", []byte("{}"), now)
+		return req.Header.Get("Authorization")
+	}
+	if sign() != sign() {
+		t.Errorf("signature should be deterministic for identical input")
+	}
+
+	req, _ := http.NewRequest(http.MethodPut, "https://my-bucket.s3.us-east-1.amazonaws.com/audit.json", nil)
+	signV4(req, cloudauth.AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "other"}, "us-east-1", []byte("{}"), now)
+	if req.Header.Get("Authorization") == sign() {
+		t.Errorf("different secrets should produce different signatures")
+	}
+}
@@ -0,0 +1,167 @@
+// Package auditshard splits the audit workload across multiple audit
+// pods. Objects are assigned to shards by hashing their namespace and
+// name, so even a single enormous kind divides evenly; each replica
+// audits only its share and merges its results into constraint status,
+// preserving the violations other shards reported. Shard membership is
+// static: every replica is configured with the shard count and either
+// an explicit index or, for StatefulSet deployments, the trailing
+// ordinal of its pod name.
+package auditshard
+
+import (
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/util"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var (
+	shardCount = flag.Int("audit-shard-count", 1, "(alpha) number of audit pods splitting the audit workload. Each pod audits the objects hashing to its shard. Defaults to 1 (no sharding)")
+	shardIndex = flag.Int("audit-shard-index", -1, "(alpha) index of this audit pod in [0, audit-shard-count). Defaults to the trailing ordinal of the pod name, for StatefulSet deployments")
+)
+
+// Enabled returns true when audit sharding was requested via flags.
+func Enabled() bool {
+	return *shardCount > 1
+}
+
+// Count returns the configured number of audit shards.
+func Count() int {
+	return *shardCount
+}
+
+var (
+	resolveOnce   sync.Once
+	resolvedIndex int
+	resolveErr    error
+)
+
+// Index returns this pod's shard index, from the flag or from the
+// pod name's trailing ordinal.
+func Index() (int, error) {
+	resolveOnce.Do(func() {
+		resolvedIndex, resolveErr = resolveIndex(*shardIndex, util.GetPodName())
+	})
+	return resolvedIndex, resolveErr
+}
+
+func resolveIndex(flagIndex int, podName string) (int, error) {
+	if flagIndex >= 0 {
+		return flagIndex, nil
+	}
+	dash := strings.LastIndex(podName, "-")
+	if dash < 0 || dash == len(podName)-1 {
+		return 0, fmt.Errorf("cannot derive a shard index from pod name %q; set --audit-shard-index or run audit as a StatefulSet", podName)
+	}
+	ordinal, err := strconv.Atoi(podName[dash+1:])
+	if err != nil {
+		return 0, fmt.Errorf("cannot derive a shard index from pod name %q; set --audit-shard-index or run audit as a StatefulSet", podName)
+	}
+	return ordinal, nil
+}
+
+// Validate checks the sharding flags resolve to a usable assignment.
+func Validate() error {
+	if !Enabled() {
+		return nil
+	}
+	index, err := Index()
+	if err != nil {
+		return err
+	}
+	if index >= *shardCount {
+		return fmt.Errorf("audit shard index %d is out of range for %d shards", index, *shardCount)
+	}
+	return nil
+}
+
+// Mine reports whether the object identified by namespace and name is
+// assigned to this pod's shard.
+func Mine(namespace, name string) bool {
+	index, err := Index()
+	if err != nil {
+		// Validate rejects this at startup; audit everything rather
+		// than silently nothing if it is somehow reached
+		return true
+	}
+	return assigned(*shardCount, index, namespace, name)
+}
+
+func assigned(count, index int, namespace, name string) bool {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(namespace + "/" + name))
+	return int(h.Sum32())%count == index
+}
+
+// Merge folds this shard's audit results into a constraint's status
+// alongside the other shards'. Violations reported by other shards are
+// preserved, per-shard totals are kept under status.auditShards, and
+// the returned violation list and total cover all shards. Entries from
+// shards beyond the current count are dropped, so scale-downs converge.
+func Merge(instance *unstructured.Unstructured, own []interface{}, ownTotal int64, timestamp string, limit uint) ([]interface{}, int64, error) {
+	index, err := Index()
+	if err != nil {
+		return nil, 0, err
+	}
+	return merge(instance, *shardCount, index, own, ownTotal, timestamp, limit)
+}
+
+func merge(instance *unstructured.Unstructured, count, index int, own []interface{}, ownTotal int64, timestamp string, limit uint) ([]interface{}, int64, error) {
+	existing, _, err := unstructured.NestedSlice(instance.Object, "status", "violations")
+	if err != nil {
+		return nil, 0, err
+	}
+	merged := make([]interface{}, 0, len(own)+len(existing))
+	for _, entry := range existing {
+		violation, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		namespace, _, _ := unstructured.NestedString(violation, "namespace")
+		name, _, _ := unstructured.NestedString(violation, "name")
+		// this shard's previous entries are superseded by own
+		if assigned(count, index, namespace, name) {
+			continue
+		}
+		merged = append(merged, entry)
+	}
+	merged = append(merged, own...)
+	if uint(len(merged)) > limit {
+		merged = merged[:limit]
+	}
+
+	shards, _, err := unstructured.NestedMap(instance.Object, "status", "auditShards")
+	if err != nil {
+		return nil, 0, err
+	}
+	if shards == nil {
+		shards = map[string]interface{}{}
+	}
+	shards[strconv.Itoa(index)] = map[string]interface{}{
+		"total":          ownTotal,
+		"auditTimestamp": timestamp,
+	}
+	total := int64(0)
+	for key, entry := range shards {
+		i, err := strconv.Atoi(key)
+		if err != nil || i >= count {
+			delete(shards, key)
+			continue
+		}
+		shard, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		t, _, _ := unstructured.NestedInt64(shard, "total")
+		total += t
+	}
+	if err := unstructured.SetNestedMap(instance.Object, shards, "status", "auditShards"); err != nil {
+		return nil, 0, err
+	}
+	return merged, total, nil
+}
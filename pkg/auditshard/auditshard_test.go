@@ -0,0 +1,146 @@
+package auditshard
+
+import (
+	"strconv"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestResolveIndex(t *testing.T) {
+	tc := []struct {
+		name      string
+		flagIndex int
+		podName   string
+		want      int
+		err       bool
+	}{
+		{name: "explicit flag wins", flagIndex: 3, podName: "gatekeeper-audit-1", want: 3},
+		{name: "statefulset ordinal", flagIndex: -1, podName: "gatekeeper-audit-2", want: 2},
+		{name: "no ordinal", flagIndex: -1, podName: "gatekeeper-audit-abcde", err: true},
+		{name: "empty pod name", flagIndex: -1, podName: "", err: true},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveIndex(tt.flagIndex, tt.podName)
+			if tt.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssignedPartitions(t *testing.T) {
+	// every object lands on exactly one of the shards
+	objects := [][2]string{
+		{"prod", "web"}, {"prod", "api"}, {"", "cluster-scoped"}, {"kube-system", "coredns"},
+	}
+	const count = 3
+	for _, obj := range objects {
+		owners := 0
+		for index := 0; index < count; index++ {
+			if assigned(count, index, obj[0], obj[1]) {
+				owners++
+			}
+		}
+		if owners != 1 {
+			t.Errorf("%s/%s is assigned to %d shards, want exactly 1", obj[0], obj[1], owners)
+		}
+	}
+}
+
+func violation(namespace, name string) interface{} {
+	return map[string]interface{}{
+		"kind":      "Pod",
+		"namespace": namespace,
+		"name":      name,
+		"message":   "denied",
+	}
+}
+
+// shardOf returns which of count shards owns the object, for building
+// deterministic fixtures.
+func shardOf(count int, namespace, name string) int {
+	for index := 0; index < count; index++ {
+		if assigned(count, index, namespace, name) {
+			return index
+		}
+	}
+	return -1
+}
+
+func TestMerge(t *testing.T) {
+	const count = 2
+	// pick fixture names by their actual shard so the test is
+	// hash-stable: two owned by shard 0, one by shard 1
+	var shard0, shard1 []string
+	for i := 0; len(shard0) < 2 || len(shard1) < 1; i++ {
+		name := "pod-" + strconv.Itoa(i)
+		if shardOf(count, "prod", name) == 0 {
+			shard0 = append(shard0, name)
+		} else {
+			shard1 = append(shard1, name)
+		}
+	}
+	mineName, supersededName, theirsName := shard0[0], shard0[1], shard1[0]
+
+	constraint := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	// previous audit wrote one violation from each shard, and shard 1
+	// recorded its total
+	_ = unstructured.SetNestedSlice(constraint.Object, []interface{}{
+		violation("prod", supersededName), violation("prod", theirsName),
+	}, "status", "violations")
+	_ = unstructured.SetNestedMap(constraint.Object, map[string]interface{}{
+		"1": map[string]interface{}{"total": int64(4), "auditTimestamp": "earlier"},
+		"7": map[string]interface{}{"total": int64(9), "auditTimestamp": "stale"}, // from a scaled-down deployment
+	}, "status", "auditShards")
+
+	own := []interface{}{violation("prod", mineName)}
+	merged, total, err := merge(constraint, count, 0, own, 1, "now", 20)
+	if err != nil {
+		t.Fatalf("merge: %s", err)
+	}
+	if total != 5 {
+		t.Errorf("total = %d, want 5 (own 1 + shard 1's 4, stale shard dropped)", total)
+	}
+	if len(merged) != 2 {
+		t.Fatalf("merged = %v, want the other shard's violation plus our own", merged)
+	}
+	names := map[string]bool{}
+	for _, v := range merged {
+		name, _, _ := unstructured.NestedString(v.(map[string]interface{}), "name")
+		names[name] = true
+	}
+	if !names[theirsName] || !names[mineName] || names[supersededName] {
+		t.Errorf("merged names = %v; our stale entry should be replaced and theirs kept", names)
+	}
+
+	shards, _, _ := unstructured.NestedMap(constraint.Object, "status", "auditShards")
+	if _, found := shards["7"]; found {
+		t.Error("entries from scaled-down shards should be pruned")
+	}
+	if _, found := shards["0"]; !found {
+		t.Error("our shard's entry should be recorded")
+	}
+}
+
+func TestMergeHonorsLimit(t *testing.T) {
+	constraint := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	own := []interface{}{violation("prod", "a"), violation("prod", "b"), violation("prod", "c")}
+	merged, _, err := merge(constraint, 2, shardOf(2, "prod", "a"), own, 3, "now", 2)
+	if err != nil {
+		t.Fatalf("merge: %s", err)
+	}
+	if len(merged) != 2 {
+		t.Errorf("merged %d violations, want the limit of 2", len(merged))
+	}
+}
@@ -0,0 +1,200 @@
+// Package backup exports the cluster's policy state — templates,
+// constraints, mutators, exemptions, and the Config — into a versioned
+// archive and restores such archives into a cluster, for disaster
+// recovery and cluster migration. Cluster-local metadata is stripped on
+// export so an archive taken from one cluster applies cleanly to
+// another; restore applies templates before constraints and retries
+// objects whose CRDs are still registering.
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Version identifies the archive format; restore refuses archives
+// written by a newer, unknown format.
+const Version = "backup.gatekeeper.sh/v1"
+
+// Archive is the serialized policy state of a cluster.
+type Archive struct {
+	// Version is the archive format version.
+	Version string `json:"version"`
+
+	// CreatedAt is when the archive was exported.
+	CreatedAt metav1.Time `json:"createdAt"`
+
+	// Objects holds the exported policy objects.
+	Objects []*unstructured.Unstructured `json:"objects"`
+}
+
+// staticListKinds are the policy lists exported besides constraints,
+// whose kinds are discovered from the installed templates.
+var staticListKinds = []schema.GroupVersionKind{
+	{Group: "templates.gatekeeper.sh", Version: "v1beta1", Kind: "ConstraintTemplateList"},
+	{Group: "mutations.gatekeeper.sh", Version: "v1alpha1", Kind: "AssignList"},
+	{Group: "mutations.gatekeeper.sh", Version: "v1alpha1", Kind: "AssignMetadataList"},
+	{Group: "exemptions.gatekeeper.sh", Version: "v1alpha1", Kind: "ExemptionList"},
+	{Group: "config.gatekeeper.sh", Version: "v1alpha1", Kind: "ConfigList"},
+}
+
+// Export collects the cluster's policy objects into an archive. Status
+// is stripped unless includeStatus is set.
+func Export(ctx context.Context, c client.Client, includeStatus bool) (*Archive, error) {
+	archive := &Archive{Version: Version, CreatedAt: metav1.Now()}
+	listKinds := append([]schema.GroupVersionKind{}, staticListKinds...)
+
+	templates := &unstructured.UnstructuredList{}
+	templates.SetGroupVersionKind(staticListKinds[0])
+	if err := c.List(ctx, templates); err != nil && !meta.IsNoMatchError(err) {
+		return nil, fmt.Errorf("listing constraint templates: %w", err)
+	}
+	for i := range templates.Items {
+		kind, found, err := unstructured.NestedString(templates.Items[i].Object, "spec", "crd", "spec", "names", "kind")
+		if err != nil || !found || kind == "" {
+			continue
+		}
+		listKinds = append(listKinds, schema.GroupVersionKind{Group: "constraints.gatekeeper.sh", Version: "v1beta1", Kind: kind + "List"})
+	}
+
+	for _, gvk := range listKinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk)
+		if err := c.List(ctx, list); err != nil {
+			// optional CRDs (mutation, exemptions) may not be installed
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, fmt.Errorf("listing %s: %w", gvk.Kind, err)
+		}
+		for i := range list.Items {
+			archive.Objects = append(archive.Objects, CleanForBackup(&list.Items[i], includeStatus))
+		}
+	}
+	return archive, nil
+}
+
+// CleanForBackup strips cluster-local metadata from an object so the
+// archive applies cleanly to another cluster.
+func CleanForBackup(obj *unstructured.Unstructured, includeStatus bool) *unstructured.Unstructured {
+	out := obj.DeepCopy()
+	unstructured.RemoveNestedField(out.Object, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(out.Object, "metadata", "uid")
+	unstructured.RemoveNestedField(out.Object, "metadata", "generation")
+	unstructured.RemoveNestedField(out.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(out.Object, "metadata", "selfLink")
+	unstructured.RemoveNestedField(out.Object, "metadata", "managedFields")
+	if !includeStatus {
+		unstructured.RemoveNestedField(out.Object, "status")
+	}
+	return out
+}
+
+// Write serializes the archive.
+func Write(archive *Archive, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(archive)
+}
+
+// Read deserializes an archive, rejecting unknown format versions.
+func Read(r io.Reader) (*Archive, error) {
+	archive := &Archive{}
+	if err := json.NewDecoder(r).Decode(archive); err != nil {
+		return nil, fmt.Errorf("parsing archive: %w", err)
+	}
+	if archive.Version != Version {
+		return nil, fmt.Errorf("archive version %q is not supported by this build (want %s)", archive.Version, Version)
+	}
+	return archive, nil
+}
+
+// restoreRank orders objects so dependencies exist before their
+// dependents: templates register the constraint CRDs, so they go first;
+// the Config goes last so syncing starts against restored policy.
+func restoreRank(obj *unstructured.Unstructured) int {
+	switch obj.GroupVersionKind().Group {
+	case "templates.gatekeeper.sh":
+		return 0
+	case "config.gatekeeper.sh":
+		return 2
+	}
+	return 1
+}
+
+// SortForRestore orders the archive's objects for application.
+func SortForRestore(objects []*unstructured.Unstructured) []*unstructured.Unstructured {
+	out := append([]*unstructured.Unstructured{}, objects...)
+	sort.SliceStable(out, func(i, j int) bool { return restoreRank(out[i]) < restoreRank(out[j]) })
+	return out
+}
+
+// Restore applies the archive's objects to the cluster, returning how
+// many were applied. Existing objects are only overwritten when force is
+// set. Objects whose CRDs are not yet registered — constraints of a
+// template created moments earlier — are retried until the deadline.
+func Restore(ctx context.Context, c client.Client, archive *Archive, force bool, deadline time.Duration) (int, error) {
+	pending := SortForRestore(archive.Objects)
+	applied := 0
+	stop := time.Now().Add(deadline)
+	for {
+		var retry []*unstructured.Unstructured
+		var lastErr error
+		for _, obj := range pending {
+			err := applyObject(ctx, c, obj, force)
+			switch {
+			case err == nil:
+				applied++
+			case meta.IsNoMatchError(err):
+				retry = append(retry, obj)
+				lastErr = err
+			default:
+				return applied, fmt.Errorf("restoring %s %s: %w", obj.GetKind(), obj.GetName(), err)
+			}
+		}
+		if len(retry) == 0 {
+			return applied, nil
+		}
+		if time.Now().After(stop) {
+			return applied, fmt.Errorf("restoring %s %s: %w", retry[0].GetKind(), retry[0].GetName(), lastErr)
+		}
+		pending = retry
+		select {
+		case <-time.After(2 * time.Second):
+		case <-ctx.Done():
+			return applied, ctx.Err()
+		}
+	}
+}
+
+// applyObject creates one object, updating it when force is set and it
+// already exists.
+func applyObject(ctx context.Context, c client.Client, obj *unstructured.Unstructured, force bool) error {
+	err := c.Create(ctx, obj.DeepCopy())
+	if !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	if !force {
+		return nil
+	}
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(obj.GroupVersionKind())
+	key := client.ObjectKey{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	if err := c.Get(ctx, key, existing); err != nil {
+		return err
+	}
+	updated := obj.DeepCopy()
+	updated.SetResourceVersion(existing.GetResourceVersion())
+	return c.Update(ctx, updated)
+}
@@ -0,0 +1,94 @@
+package backup
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func makeObject(apiVersion, kind, name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": name},
+	}}
+}
+
+func TestCleanForBackup(t *testing.T) {
+	obj := makeObject("templates.gatekeeper.sh/v1beta1", "ConstraintTemplate", "k8srequiredlabels")
+	obj.SetResourceVersion("42")
+	obj.SetUID("abc")
+	obj.SetGeneration(3)
+	obj.Object["status"] = map[string]interface{}{"created": true}
+	obj.SetLabels(map[string]string{"team": "platform"})
+
+	cleaned := CleanForBackup(obj, false)
+	if cleaned.GetResourceVersion() != "" || cleaned.GetUID() != "" || cleaned.GetGeneration() != 0 {
+		t.Errorf("cluster-local metadata should be stripped, got %v", cleaned.Object["metadata"])
+	}
+	if _, found := cleaned.Object["status"]; found {
+		t.Errorf("status should be stripped by default")
+	}
+	if cleaned.GetLabels()["team"] != "platform" {
+		t.Errorf("labels should be preserved, got %v", cleaned.GetLabels())
+	}
+	if obj.GetResourceVersion() != "42" {
+		t.Errorf("cleaning must not mutate the original object")
+	}
+
+	withStatus := CleanForBackup(obj, true)
+	if _, found := withStatus.Object["status"]; !found {
+		t.Errorf("status should be kept when requested")
+	}
+}
+
+func TestWriteReadRoundTrip(t *testing.T) {
+	archive := &Archive{Version: Version, Objects: []*unstructured.Unstructured{
+		makeObject("templates.gatekeeper.sh/v1beta1", "ConstraintTemplate", "k8srequiredlabels"),
+		makeObject("constraints.gatekeeper.sh/v1beta1", "K8sRequiredLabels", "require-team"),
+	}}
+	buf := &bytes.Buffer{}
+	if err := Write(archive, buf); err != nil {
+		t.Fatalf("writing archive: %s", err)
+	}
+	parsed, err := Read(buf)
+	if err != nil {
+		t.Fatalf("reading archive: %s", err)
+	}
+	if len(parsed.Objects) != 2 {
+		t.Fatalf("expected 2 objects, got %d", len(parsed.Objects))
+	}
+	if parsed.Objects[1].GetKind() != "K8sRequiredLabels" || parsed.Objects[1].GetName() != "require-team" {
+		t.Errorf("objects should round-trip, got %v", parsed.Objects[1])
+	}
+}
+
+func TestReadRejectsUnknownVersions(t *testing.T) {
+	if _, err := Read(strings.NewReader(`{"version": "backup.gatekeeper.sh/v99", "objects": []}`)); err == nil {
+		t.Errorf("unknown archive versions should be rejected")
+	}
+	if _, err := Read(strings.NewReader(`not json`)); err == nil {
+		t.Errorf("malformed archives should be rejected")
+	}
+}
+
+func TestSortForRestore(t *testing.T) {
+	objects := []*unstructured.Unstructured{
+		makeObject("config.gatekeeper.sh/v1alpha1", "Config", "config"),
+		makeObject("constraints.gatekeeper.sh/v1beta1", "K8sRequiredLabels", "require-team"),
+		makeObject("templates.gatekeeper.sh/v1beta1", "ConstraintTemplate", "k8srequiredlabels"),
+		makeObject("mutations.gatekeeper.sh/v1alpha1", "Assign", "set-image-policy"),
+	}
+	sorted := SortForRestore(objects)
+	if sorted[0].GetKind() != "ConstraintTemplate" {
+		t.Errorf("templates should restore first, got %s", sorted[0].GetKind())
+	}
+	if sorted[len(sorted)-1].GetKind() != "Config" {
+		t.Errorf("the Config should restore last, got %s", sorted[len(sorted)-1].GetKind())
+	}
+	if objects[0].GetKind() != "Config" {
+		t.Errorf("sorting must not reorder the input slice")
+	}
+}
@@ -0,0 +1,142 @@
+// Package bootstrap preloads constraint templates and constraints from
+// a mounted directory into the policy client before the manager starts
+// serving. On freshly provisioned clusters the webhook can then enforce
+// policy from its first request, without waiting for the template and
+// constraint CRs to be created and reconciled. Bootstrap policies are a
+// floor, not a replacement: the same objects can be applied as CRs
+// later and reconcile over the preloaded copies.
+package bootstrap
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/open-policy-agent/frameworks/constraint/pkg/core/templates"
+	"github.com/open-policy-agent/gatekeeper/apis"
+	"github.com/open-policy-agent/gatekeeper/pkg/opashard"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+)
+
+var log = logf.Log.WithName("bootstrap")
+
+var policyDir = flag.String("bootstrap-policy-dir", "", "(alpha) directory of constraint template and constraint manifests to preload into the policy engine at startup, e.g. a mounted ConfigMap")
+
+var (
+	runtimeScheme = k8sruntime.NewScheme()
+	codecs        = serializer.NewCodecFactory(runtimeScheme)
+	deserializer  = codecs.UniversalDeserializer()
+)
+
+func init() {
+	_ = apis.AddToScheme(runtimeScheme)
+}
+
+// Enabled returns true if a bootstrap policy directory was configured.
+func Enabled() bool {
+	return *policyDir != ""
+}
+
+// Dir returns the configured bootstrap policy directory.
+func Dir() string {
+	return *policyDir
+}
+
+// Preload reads every manifest under dir and adds the templates and
+// constraints it finds to the client, templates first so constraints
+// always land on a registered kind. Any unreadable or non-policy
+// document is an error: a cluster relying on bootstrap policy for
+// day-zero protection should fail loudly rather than start partially
+// enforced.
+func Preload(ctx context.Context, client opashard.Client, dir string) error {
+	templateDocs, constraintDocs, err := readPolicies(dir)
+	if err != nil {
+		return err
+	}
+	for _, doc := range templateDocs {
+		obj, _, err := deserializer.Decode(doc, nil, nil)
+		if err != nil {
+			return err
+		}
+		unversioned := &templates.ConstraintTemplate{}
+		if err := runtimeScheme.Convert(obj, unversioned, nil); err != nil {
+			return err
+		}
+		if _, err := client.AddTemplate(ctx, unversioned); err != nil {
+			return fmt.Errorf("adding bootstrap template %s: %w", unversioned.GetName(), err)
+		}
+	}
+	for _, doc := range constraintDocs {
+		js, err := yaml.YAMLToJSON(doc)
+		if err != nil {
+			return err
+		}
+		cstr := &unstructured.Unstructured{}
+		if err := cstr.UnmarshalJSON(js); err != nil {
+			return err
+		}
+		if _, err := client.AddConstraint(ctx, cstr); err != nil {
+			return fmt.Errorf("adding bootstrap constraint %s %s: %w", cstr.GetKind(), cstr.GetName(), err)
+		}
+	}
+	log.Info("bootstrap policies loaded", "templates", len(templateDocs), "constraints", len(constraintDocs))
+	return nil
+}
+
+// readPolicies collects the YAML documents under dir, split into
+// templates and constraints. Hidden entries are skipped so ConfigMap
+// volume bookkeeping (..data and friends) does not trip parsing.
+func readPolicies(dir string) (templateDocs, constraintDocs [][]byte, err error) {
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() && path != dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		ext := filepath.Ext(info.Name())
+		if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+			return nil
+		}
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, doc := range strings.Split(string(raw), "\n---") {
+			if strings.TrimSpace(doc) == "" {
+				continue
+			}
+			obj := &unstructured.Unstructured{}
+			if err := yaml.Unmarshal([]byte(doc), &obj.Object); err != nil {
+				return fmt.Errorf("parsing %s: %w", path, err)
+			}
+			if len(obj.Object) == 0 {
+				continue
+			}
+			switch obj.GroupVersionKind().Group {
+			case "templates.gatekeeper.sh":
+				templateDocs = append(templateDocs, []byte(doc))
+			case "constraints.gatekeeper.sh":
+				constraintDocs = append(constraintDocs, []byte(doc))
+			default:
+				return fmt.Errorf("%s: %s/%s is not a template or constraint", path, obj.GetAPIVersion(), obj.GetKind())
+			}
+		}
+		return nil
+	})
+	return templateDocs, constraintDocs, err
+}
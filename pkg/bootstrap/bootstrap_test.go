@@ -0,0 +1,91 @@
+package bootstrap
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/loadtest"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+)
+
+const denyAllManifest = `
+apiVersion: templates.gatekeeper.sh/v1beta1
+kind: ConstraintTemplate
+metadata:
+  name: denyall
+spec:
+  crd:
+    spec:
+      names:
+        kind: DenyAll
+  targets:
+    - target: admission.k8s.gatekeeper.sh
+      rego: |
+        package denyall
+
+        violation[{"msg": msg}] {
+          msg := "denied by bootstrap"
+        }
+---
+apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: DenyAll
+metadata:
+  name: deny-everything
+`
+
+func TestPreloadEnforcesFromStartup(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "policies.yaml"), []byte(denyAllManifest), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := loadtest.NewClient()
+	if err != nil {
+		t.Fatalf("could not initialize OPA: %s", err)
+	}
+	if err := Preload(context.Background(), client, dir); err != nil {
+		t.Fatalf("preload failed: %s", err)
+	}
+
+	obj := loadtest.SyntheticObjects(1)[0]
+	resp, err := client.Review(context.Background(), target.AugmentedUnstructured{Object: *obj})
+	if err != nil {
+		t.Fatalf("review failed: %s", err)
+	}
+	if len(resp.Results()) != 1 {
+		t.Fatalf("expected the preloaded constraint to fire, got %d results", len(resp.Results()))
+	}
+}
+
+func TestPreloadRejectsForeignObjects(t *testing.T) {
+	dir := t.TempDir()
+	manifest := "apiVersion: v1\nkind: Pod\nmetadata:\n  name: not-policy\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "pod.yaml"), []byte(manifest), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := loadtest.NewClient()
+	if err != nil {
+		t.Fatalf("could not initialize OPA: %s", err)
+	}
+	if err := Preload(context.Background(), client, dir); err == nil {
+		t.Fatal("expected an error for a non-policy manifest")
+	}
+}
+
+func TestReadPoliciesSkipsHiddenEntries(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, ".hidden.yaml"), []byte("apiVersion: v1\nkind: Pod"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	templateDocs, constraintDocs, err := readPolicies(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(templateDocs) != 0 || len(constraintDocs) != 0 {
+		t.Fatalf("expected hidden files to be skipped, got %d templates and %d constraints", len(templateDocs), len(constraintDocs))
+	}
+}
@@ -0,0 +1,93 @@
+// Package breakglass implements an emergency, time-bounded global bypass
+// of constraint enforcement. It exists for incident response when policy
+// evaluation itself is the outage: operators create a break-glass
+// ConfigMap in the gatekeeper namespace and every deny is downgraded to
+// dryrun cluster-wide until the bypass expires or the ConfigMap is
+// removed. Activation is deliberately loud — every bypassed request is
+// logged and counted.
+package breakglass
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("break-glass").WithValues(logging.Process, "break_glass")
+
+// ConfigMapName is the name of the ConfigMap whose presence activates
+// the break-glass bypass. An optional `until` key holds an RFC3339
+// timestamp bounding the bypass; the bypass never outlives
+// --break-glass-max-duration regardless.
+const ConfigMapName = "gatekeeper-break-glass"
+
+const defaultMaxDuration = 30 * time.Minute
+
+var (
+	enableBreakGlass      = flag.Bool("enable-break-glass", false, "(alpha) allow a break-glass ConfigMap to temporarily downgrade all denies to dryrun cluster-wide")
+	breakGlassMaxDuration = flag.Duration("break-glass-max-duration", defaultMaxDuration, "upper bound on how long a break-glass bypass can stay active before auto-reverting")
+)
+
+// Enabled returns true when the break-glass mechanism was requested via
+// flag.
+func Enabled() bool {
+	return *enableBreakGlass
+}
+
+// Checker evaluates whether the break-glass bypass is currently active.
+type Checker struct {
+	client      client.Client
+	namespace   string
+	maxDuration time.Duration
+}
+
+// NewChecker returns a Checker watching for the break-glass ConfigMap in
+// the given namespace.
+func NewChecker(c client.Client, namespace string) *Checker {
+	return &Checker{
+		client:      c,
+		namespace:   namespace,
+		maxDuration: *breakGlassMaxDuration,
+	}
+}
+
+// Active returns true while the break-glass bypass is in effect. The
+// bypass auto-reverts once the declared `until` timestamp or the maximum
+// duration has passed, whichever is earlier.
+func (c *Checker) Active(ctx context.Context) bool {
+	cm := &corev1.ConfigMap{}
+	if err := c.client.Get(ctx, types.NamespacedName{Namespace: c.namespace, Name: ConfigMapName}, cm); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			log.Error(err, "unable to check for break-glass ConfigMap")
+		}
+		reportActive(false)
+		return false
+	}
+	active := c.activeAt(cm, time.Now())
+	reportActive(active)
+	return active
+}
+
+func (c *Checker) activeAt(cm *corev1.ConfigMap, now time.Time) bool {
+	expiresAt := cm.CreationTimestamp.Add(c.maxDuration)
+	if raw, ok := cm.Data["until"]; ok {
+		until, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			log.Error(err, "break-glass ConfigMap has malformed until timestamp, using maximum duration", "until", raw)
+		} else if until.Before(expiresAt) {
+			expiresAt = until
+		}
+	}
+	if !now.Before(expiresAt) {
+		log.Info("break-glass bypass has expired and is no longer honored", "expired_at", expiresAt.String())
+		return false
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package breakglass
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func makeConfigMap(created time.Time, until string) *corev1.ConfigMap {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              ConfigMapName,
+			CreationTimestamp: metav1.NewTime(created),
+		},
+	}
+	if until != "" {
+		cm.Data = map[string]string{"until": until}
+	}
+	return cm
+}
+
+func TestActiveAt(t *testing.T) {
+	now := time.Now()
+	checker := &Checker{maxDuration: 30 * time.Minute}
+
+	tc := []struct {
+		name     string
+		cm       *corev1.ConfigMap
+		at       time.Time
+		expected bool
+	}{
+		{
+			name:     "freshly created is active",
+			cm:       makeConfigMap(now, ""),
+			at:       now.Add(time.Minute),
+			expected: true,
+		},
+		{
+			name:     "auto-reverts after maximum duration",
+			cm:       makeConfigMap(now, ""),
+			at:       now.Add(31 * time.Minute),
+			expected: false,
+		},
+		{
+			name:     "until bound honored when earlier",
+			cm:       makeConfigMap(now, now.Add(5*time.Minute).Format(time.RFC3339)),
+			at:       now.Add(10 * time.Minute),
+			expected: false,
+		},
+		{
+			name:     "until bound cannot extend past maximum duration",
+			cm:       makeConfigMap(now, now.Add(24*time.Hour).Format(time.RFC3339)),
+			at:       now.Add(31 * time.Minute),
+			expected: false,
+		},
+		{
+			name:     "malformed until falls back to maximum duration",
+			cm:       makeConfigMap(now, "soon"),
+			at:       now.Add(time.Minute),
+			expected: true,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checker.activeAt(tt.cm, tt.at); got != tt.expected {
+				t.Errorf("expected active=%v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
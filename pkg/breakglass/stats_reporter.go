@@ -0,0 +1,59 @@
+package breakglass
+
+import (
+	"context"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/metrics"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+)
+
+const (
+	activeMetricName   = "break_glass_active"
+	bypassesMetricName = "break_glass_bypasses"
+)
+
+var (
+	activeM   = stats.Int64(activeMetricName, "Whether the break-glass bypass is currently active", stats.UnitDimensionless)
+	bypassesM = stats.Int64(bypassesMetricName, "Total number of requests admitted due to the break-glass bypass", stats.UnitDimensionless)
+)
+
+func init() {
+	if err := register(); err != nil {
+		panic(err)
+	}
+}
+
+func register() error {
+	views := []*view.View{
+		{
+			Name:        activeMetricName,
+			Measure:     activeM,
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        bypassesMetricName,
+			Measure:     bypassesM,
+			Aggregation: view.Count(),
+		},
+	}
+	return view.Register(views...)
+}
+
+func reportActive(active bool) {
+	val := int64(0)
+	if active {
+		val = 1
+	}
+	if err := metrics.Record(context.Background(), activeM.M(val)); err != nil {
+		log.Error(err, "failed to report break-glass state")
+	}
+}
+
+// ReportBypass records a request that was admitted only because the
+// break-glass bypass is active.
+func ReportBypass() {
+	if err := metrics.Record(context.Background(), bypassesM.M(1)); err != nil {
+		log.Error(err, "failed to report break-glass bypass")
+	}
+}
@@ -0,0 +1,165 @@
+package bundlesig
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// writeTrustRoot marshals a public key as PEM into dir and points the
+// trust roots flag at it.
+func writeTrustRoot(t *testing.T, dir string, key interface{}) {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatalf("marshaling public key: %s", err)
+	}
+	raw := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	if err := ioutil.WriteFile(filepath.Join(dir, "root.pub"), raw, 0600); err != nil {
+		t.Fatalf("writing trust root: %s", err)
+	}
+}
+
+func setFlags(t *testing.T, dir string) {
+	t.Helper()
+	oldRequire, oldRoots := *requireSignedBundles, *bundleTrustRoots
+	t.Cleanup(func() {
+		*requireSignedBundles = oldRequire
+		*bundleTrustRoots = oldRoots
+	})
+	*requireSignedBundles = true
+	*bundleTrustRoots = dir
+}
+
+func signECDSA(t *testing.T, key *ecdsa.PrivateKey, content []byte) []byte {
+	t.Helper()
+	digest := sha256.Sum256(content)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatalf("signing bundle: %s", err)
+	}
+	return []byte(base64.StdEncoding.EncodeToString(sig))
+}
+
+func TestVerifyECDSA(t *testing.T) {
+	dir := t.TempDir()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %s", err)
+	}
+	writeTrustRoot(t, dir, &key.PublicKey)
+	setFlags(t, dir)
+
+	v, err := NewVerifier()
+	if err != nil {
+		t.Fatalf("loading trust roots: %s", err)
+	}
+	content := []byte("package foo\nviolation[{\"msg\": \"denied\"}] { true }\n")
+	if err := v.Verify(content, signECDSA(t, key, content)); err != nil {
+		t.Errorf("valid signature should verify: %s", err)
+	}
+	if err := v.Verify(append(content, '\n'), signECDSA(t, key, content)); err == nil {
+		t.Errorf("tampered content should not verify")
+	}
+}
+
+func TestVerifyEd25519(t *testing.T) {
+	dir := t.TempDir()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %s", err)
+	}
+	writeTrustRoot(t, dir, pub)
+	setFlags(t, dir)
+
+	v, err := NewVerifier()
+	if err != nil {
+		t.Fatalf("loading trust roots: %s", err)
+	}
+	content := []byte("bundle contents")
+	sig := []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, content)))
+	if err := v.Verify(content, sig); err != nil {
+		t.Errorf("valid signature should verify: %s", err)
+	}
+}
+
+func TestVerifyRejectsUntrustedKey(t *testing.T) {
+	dir := t.TempDir()
+	trusted, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %s", err)
+	}
+	writeTrustRoot(t, dir, &trusted.PublicKey)
+	setFlags(t, dir)
+
+	v, err := NewVerifier()
+	if err != nil {
+		t.Fatalf("loading trust roots: %s", err)
+	}
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %s", err)
+	}
+	content := []byte("bundle contents")
+	if err := v.Verify(content, signECDSA(t, other, content)); err == nil {
+		t.Errorf("signatures from untrusted keys should not verify")
+	}
+}
+
+func TestVerifyPath(t *testing.T) {
+	dir := t.TempDir()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %s", err)
+	}
+	writeTrustRoot(t, dir, &key.PublicKey)
+	setFlags(t, dir)
+
+	v, err := NewVerifier()
+	if err != nil {
+		t.Fatalf("loading trust roots: %s", err)
+	}
+	content := []byte("bundle contents")
+	bundle := filepath.Join(dir, "bundle.tar.gz")
+	if err := ioutil.WriteFile(bundle, content, 0600); err != nil {
+		t.Fatalf("writing bundle: %s", err)
+	}
+	if err := v.VerifyPath(bundle); err == nil {
+		t.Errorf("unsigned bundles should be refused")
+	}
+	if err := ioutil.WriteFile(bundle+sigSuffix, signECDSA(t, key, content), 0600); err != nil {
+		t.Fatalf("writing signature: %s", err)
+	}
+	if err := v.VerifyPath(bundle); err != nil {
+		t.Errorf("signed bundle should verify: %s", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	setFlags(t, dir)
+	*bundleTrustRoots = ""
+	if err := Validate(); err == nil {
+		t.Errorf("missing trust roots should be rejected")
+	}
+	*bundleTrustRoots = dir
+	if err := Validate(); err == nil {
+		t.Errorf("empty trust roots should be rejected")
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %s", err)
+	}
+	writeTrustRoot(t, dir, &key.PublicKey)
+	if err := Validate(); err != nil {
+		t.Errorf("valid trust roots should pass: %s", err)
+	}
+}
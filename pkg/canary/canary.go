@@ -0,0 +1,269 @@
+// Package canary rolls out a new version of a constraint template's
+// Rego to part of the cluster before it is enforced everywhere. The
+// candidate Rego travels as an annotation on the template, and the
+// decorated client registers it side by side with the stable version
+// under a shadow kind, mirroring the template's constraints onto it.
+// In the namespaces named by the rollout the candidate's verdict is
+// the one enforced; everywhere else candidate denials surface as
+// admission warnings so operators can watch the new version disagree
+// before flipping the whole cluster.
+package canary
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/open-policy-agent/frameworks/constraint/pkg/core/templates"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/types"
+	"github.com/open-policy-agent/gatekeeper/pkg/opashard"
+	"github.com/open-policy-agent/gatekeeper/pkg/util"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("template-canary")
+
+const (
+	// RegoAnnotation carries the candidate Rego for the template's target.
+	RegoAnnotation = "canary.gatekeeper.sh/rego"
+	// NamespacesAnnotation lists the namespaces where the candidate is
+	// enforced instead of the stable version. Entries may use a trailing
+	// * wildcard.
+	NamespacesAnnotation = "canary.gatekeeper.sh/namespaces"
+
+	kindSuffix = "Canary"
+	nameSuffix = "-canary"
+)
+
+var enableTemplateCanary = flag.Bool("enable-template-canary", false, "(alpha) roll out annotated candidate template Rego to canary namespaces before enforcing it cluster-wide")
+
+// Enabled returns true when template canary rollouts were requested via
+// flags.
+func Enabled() bool {
+	return *enableTemplateCanary
+}
+
+// rollout is one template's active canary.
+type rollout struct {
+	kind       string
+	canaryKind string
+	namespaces []string
+}
+
+// registry tracks active rollouts so the webhook can classify review
+// results without a reference to the decorated client.
+var registry = struct {
+	mux     sync.RWMutex
+	byKind  map[string]*rollout // stable kind -> rollout
+	shadows map[string]*rollout // canary kind -> rollout
+}{
+	byKind:  make(map[string]*rollout),
+	shadows: make(map[string]*rollout),
+}
+
+func register(r *rollout) {
+	registry.mux.Lock()
+	defer registry.mux.Unlock()
+	registry.byKind[r.kind] = r
+	registry.shadows[r.canaryKind] = r
+}
+
+func deregister(kind string) {
+	registry.mux.Lock()
+	defer registry.mux.Unlock()
+	if r, ok := registry.byKind[kind]; ok {
+		delete(registry.shadows, r.canaryKind)
+		delete(registry.byKind, kind)
+	}
+}
+
+func rolloutFor(kind string) *rollout {
+	registry.mux.RLock()
+	defer registry.mux.RUnlock()
+	return registry.byKind[kind]
+}
+
+// IsCanaryConstraint reports whether the constraint is a mirrored copy
+// attached to a canary template. Such copies exist only inside the
+// policy engine and have no cluster resource whose status could record
+// violations, so audit skips them.
+func IsCanaryConstraint(constraint *unstructured.Unstructured) bool {
+	registry.mux.RLock()
+	defer registry.mux.RUnlock()
+	_, ok := registry.shadows[constraint.GetKind()]
+	return ok
+}
+
+// Client wraps an OPA client and maintains a shadow copy of each
+// canaried template and its constraints. It implements opashard.Client.
+type Client struct {
+	opashard.Client
+}
+
+var _ opashard.Client = &Client{}
+
+// New wraps inner with template canary rollouts.
+func New(inner opashard.Client) *Client {
+	return &Client{Client: inner}
+}
+
+// AddTemplate registers the stable template and, when the template
+// carries canary annotations, a shadow copy compiled from the candidate
+// Rego. Constraints mirrored onto the shadow arrive through
+// AddConstraint as the controllers replay them after the template
+// change.
+func (c *Client) AddTemplate(ctx context.Context, templ *templates.ConstraintTemplate) (*types.Responses, error) {
+	kind := templ.Spec.CRD.Spec.Names.Kind
+	resp, err := c.Client.AddTemplate(ctx, templ)
+	if err != nil {
+		return resp, err
+	}
+	rego := templ.GetAnnotations()[RegoAnnotation]
+	if rego == "" {
+		// an ended rollout tears its shadow down
+		if r := rolloutFor(kind); r != nil {
+			deregister(kind)
+			if _, err := c.Client.RemoveTemplate(ctx, canaryTemplate(templ, "")); err != nil {
+				log.Error(err, "unable to remove retired canary template", "kind", kind)
+			}
+		}
+		return resp, nil
+	}
+	namespaces := splitList(templ.GetAnnotations()[NamespacesAnnotation])
+	if len(namespaces) == 0 {
+		return resp, fmt.Errorf("template %s sets %s but no %s", templ.GetName(), RegoAnnotation, NamespacesAnnotation)
+	}
+	shadow := canaryTemplate(templ, rego)
+	if _, err := c.Client.AddTemplate(ctx, shadow); err != nil {
+		return resp, fmt.Errorf("compiling canary Rego for template %s: %w", templ.GetName(), err)
+	}
+	register(&rollout{kind: kind, canaryKind: kind + kindSuffix, namespaces: namespaces})
+	log.Info("canary rollout active", "kind", kind, "namespaces", strings.Join(namespaces, ","))
+	return resp, nil
+}
+
+// RemoveTemplate tears the shadow down along with the stable template.
+func (c *Client) RemoveTemplate(ctx context.Context, templ *templates.ConstraintTemplate) (*types.Responses, error) {
+	kind := templ.Spec.CRD.Spec.Names.Kind
+	if r := rolloutFor(kind); r != nil {
+		deregister(kind)
+		if _, err := c.Client.RemoveTemplate(ctx, canaryTemplate(templ, "")); err != nil {
+			log.Error(err, "unable to remove canary template", "kind", kind)
+		}
+	}
+	return c.Client.RemoveTemplate(ctx, templ)
+}
+
+// AddConstraint mirrors constraints of canaried kinds onto the shadow
+// kind so the candidate evaluates the same policy set as the stable
+// version.
+func (c *Client) AddConstraint(ctx context.Context, constraint *unstructured.Unstructured) (*types.Responses, error) {
+	resp, err := c.Client.AddConstraint(ctx, constraint)
+	if err != nil {
+		return resp, err
+	}
+	if rolloutFor(constraint.GetKind()) != nil {
+		if _, err := c.Client.AddConstraint(ctx, mirrorConstraint(constraint)); err != nil {
+			return resp, fmt.Errorf("mirroring constraint %s onto canary: %w", constraint.GetName(), err)
+		}
+	}
+	return resp, nil
+}
+
+// RemoveConstraint removes the mirrored copy along with the constraint.
+func (c *Client) RemoveConstraint(ctx context.Context, constraint *unstructured.Unstructured) (*types.Responses, error) {
+	if rolloutFor(constraint.GetKind()) != nil {
+		if _, err := c.Client.RemoveConstraint(ctx, mirrorConstraint(constraint)); err != nil {
+			log.Error(err, "unable to remove mirrored canary constraint", "name", constraint.GetName())
+		}
+	}
+	return c.Client.RemoveConstraint(ctx, constraint)
+}
+
+// canaryTemplate builds the shadow template for a canaried template.
+// When rego is empty the copy is only used as a handle for removal.
+func canaryTemplate(templ *templates.ConstraintTemplate, rego string) *templates.ConstraintTemplate {
+	shadow := templ.DeepCopy()
+	shadow.Spec.CRD.Spec.Names.Kind = templ.Spec.CRD.Spec.Names.Kind + kindSuffix
+	// the framework requires a template's name to be the lowercased kind
+	shadow.SetName(strings.ToLower(shadow.Spec.CRD.Spec.Names.Kind))
+	delete(shadow.Annotations, RegoAnnotation)
+	delete(shadow.Annotations, NamespacesAnnotation)
+	if rego != "" {
+		for i := range shadow.Spec.Targets {
+			shadow.Spec.Targets[i].Rego = rego
+		}
+	}
+	return shadow
+}
+
+// mirrorConstraint builds the shadow-kind copy of a constraint.
+func mirrorConstraint(constraint *unstructured.Unstructured) *unstructured.Unstructured {
+	mirror := constraint.DeepCopy()
+	mirror.SetKind(constraint.GetKind() + kindSuffix)
+	mirror.SetName(constraint.GetName() + nameSuffix)
+	return mirror
+}
+
+// Apply classifies review results for the requesting namespace. Inside
+// a rollout's canary namespaces the candidate's results replace the
+// stable version's; everywhere else candidate denials are returned as
+// warnings and the stable version stays authoritative.
+func Apply(res []*types.Result, namespace string) ([]*types.Result, []string) {
+	var out []*types.Result
+	var warnings []string
+	for _, r := range res {
+		if r.Constraint == nil {
+			out = append(out, r)
+			continue
+		}
+		registry.mux.RLock()
+		shadow := registry.shadows[r.Constraint.GetKind()]
+		stable := registry.byKind[r.Constraint.GetKind()]
+		registry.mux.RUnlock()
+		switch {
+		case shadow != nil:
+			if namespaceMatches(shadow.namespaces, namespace) {
+				out = append(out, r)
+			} else if r.EnforcementAction == string(util.Deny) {
+				warnings = append(warnings, fmt.Sprintf(
+					"canary %s %s would have denied this request: %s",
+					r.Constraint.GetKind(), r.Constraint.GetName(), r.Msg))
+			}
+		case stable != nil && namespaceMatches(stable.namespaces, namespace):
+			// the candidate's verdict replaces the stable one here
+		default:
+			out = append(out, r)
+		}
+	}
+	return out, warnings
+}
+
+// namespaceMatches mirrors the matching used for namespace-scoped
+// enforcement actions: exact, trailing-* prefix, or "*".
+func namespaceMatches(scopes []string, namespace string) bool {
+	for _, scope := range scopes {
+		if scope == "*" || scope == namespace {
+			return true
+		}
+		if strings.HasSuffix(scope, "*") && strings.HasPrefix(namespace, strings.TrimSuffix(scope, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitList splits a comma-separated annotation value, dropping empty
+// entries.
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			out = append(out, trimmed)
+		}
+	}
+	return out
+}
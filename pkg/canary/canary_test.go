@@ -0,0 +1,184 @@
+package canary
+
+import (
+	"context"
+	"testing"
+
+	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/client/drivers/local"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/core/templates"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/types"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const stableRego = `
+package neverdeny
+
+violation[{"msg": msg}] {
+  input.review.object.metadata.name == "never"
+  msg := "stable"
+}
+`
+
+const candidateRego = `
+package neverdeny
+
+violation[{"msg": msg}] {
+  msg := "candidate denies everything"
+}
+`
+
+func makeOpaClient() (*opa.Client, error) {
+	t := &target.K8sValidationTarget{}
+	driver := local.New(local.Tracing(false))
+	backend, err := opa.NewBackend(opa.Driver(driver))
+	if err != nil {
+		return nil, err
+	}
+	return backend.NewClient(opa.Targets(t))
+}
+
+func makeCanaryClient(t *testing.T) *Client {
+	inner, err := makeOpaClient()
+	if err != nil {
+		t.Fatalf("could not initialize OPA: %s", err)
+	}
+	return New(inner)
+}
+
+func canariedTemplate(namespaces string) *templates.ConstraintTemplate {
+	templ := &templates.ConstraintTemplate{}
+	templ.SetName("neverdeny")
+	templ.SetAnnotations(map[string]string{
+		RegoAnnotation:       candidateRego,
+		NamespacesAnnotation: namespaces,
+	})
+	templ.Spec.CRD.Spec.Names.Kind = "NeverDeny"
+	templ.Spec.Targets = []templates.Target{{
+		Target: (&target.K8sValidationTarget{}).GetName(),
+		Rego:   stableRego,
+	}}
+	return templ
+}
+
+func makeConstraint(name string) *unstructured.Unstructured {
+	cstr := &unstructured.Unstructured{}
+	cstr.SetAPIVersion("constraints.gatekeeper.sh/v1beta1")
+	cstr.SetKind("NeverDeny")
+	cstr.SetName(name)
+	return cstr
+}
+
+func makePod(name, namespace string) *unstructured.Unstructured {
+	pod := &unstructured.Unstructured{}
+	pod.SetAPIVersion("v1")
+	pod.SetKind("Pod")
+	pod.SetName(name)
+	pod.SetNamespace(namespace)
+	return pod
+}
+
+func TestShadowEvaluatesCandidateRego(t *testing.T) {
+	t.Cleanup(func() { deregister("NeverDeny") })
+	c := makeCanaryClient(t)
+	ctx := context.Background()
+	if _, err := c.AddTemplate(ctx, canariedTemplate("canary-*")); err != nil {
+		t.Fatalf("could not add canaried template: %s", err)
+	}
+	if _, err := c.AddConstraint(ctx, makeConstraint("no-pods")); err != nil {
+		t.Fatalf("could not add constraint: %s", err)
+	}
+
+	resp, err := c.Review(ctx, target.AugmentedUnstructured{Object: *makePod("busybox", "prod")})
+	if err != nil {
+		t.Fatalf("review failed: %s", err)
+	}
+	res := resp.Results()
+	if len(res) != 1 {
+		t.Fatalf("expected 1 result from the shadow, got %d", len(res))
+	}
+	if res[0].Constraint.GetKind() != "NeverDenyCanary" {
+		t.Errorf("result came from %s, want the shadow kind", res[0].Constraint.GetKind())
+	}
+
+	// outside the canary namespaces the candidate denial becomes a warning
+	kept, warnings := Apply(res, "prod")
+	if len(kept) != 0 || len(warnings) != 1 {
+		t.Errorf("Apply(prod) = %d results, %d warnings; want 0 and 1", len(kept), len(warnings))
+	}
+
+	// inside them the candidate's verdict is enforced
+	kept, warnings = Apply(res, "canary-1")
+	if len(kept) != 1 || len(warnings) != 0 {
+		t.Errorf("Apply(canary-1) = %d results, %d warnings; want 1 and 0", len(kept), len(warnings))
+	}
+}
+
+func TestStableResultDroppedInCanaryNamespace(t *testing.T) {
+	t.Cleanup(func() { deregister("NeverDeny") })
+	register(&rollout{kind: "NeverDeny", canaryKind: "NeverDenyCanary", namespaces: []string{"canary-1"}})
+
+	stable := makeConstraint("no-pods")
+	res := []*types.Result{{Constraint: stable, EnforcementAction: "deny", Msg: "stable denies"}}
+
+	kept, warnings := Apply(res, "canary-1")
+	if len(kept) != 0 || len(warnings) != 0 {
+		t.Errorf("stable result should be replaced by the candidate in a canary namespace, got %d results, %d warnings", len(kept), len(warnings))
+	}
+	kept, _ = Apply(res, "prod")
+	if len(kept) != 1 {
+		t.Errorf("stable result should stay authoritative outside canary namespaces, got %d results", len(kept))
+	}
+}
+
+func TestRolloutTeardown(t *testing.T) {
+	t.Cleanup(func() { deregister("NeverDeny") })
+	c := makeCanaryClient(t)
+	ctx := context.Background()
+	if _, err := c.AddTemplate(ctx, canariedTemplate("canary-*")); err != nil {
+		t.Fatalf("could not add canaried template: %s", err)
+	}
+	if rolloutFor("NeverDeny") == nil {
+		t.Fatalf("rollout should be registered")
+	}
+
+	// the same template without annotations ends the rollout
+	plain := canariedTemplate("")
+	plain.SetAnnotations(nil)
+	if _, err := c.AddTemplate(ctx, plain); err != nil {
+		t.Fatalf("could not add plain template: %s", err)
+	}
+	if rolloutFor("NeverDeny") != nil {
+		t.Errorf("rollout should be deregistered after the annotations are removed")
+	}
+}
+
+func TestMissingNamespacesAnnotation(t *testing.T) {
+	t.Cleanup(func() { deregister("NeverDeny") })
+	c := makeCanaryClient(t)
+	templ := canariedTemplate("")
+	templ.Annotations[NamespacesAnnotation] = ""
+	if _, err := c.AddTemplate(context.Background(), templ); err == nil {
+		t.Errorf("expected an error for a canary without namespaces")
+	}
+}
+
+func TestNamespaceMatches(t *testing.T) {
+	tc := []struct {
+		scopes    []string
+		namespace string
+		want      bool
+	}{
+		{scopes: []string{"canary-1"}, namespace: "canary-1", want: true},
+		{scopes: []string{"canary-*"}, namespace: "canary-2", want: true},
+		{scopes: []string{"*"}, namespace: "anything", want: true},
+		{scopes: []string{"canary-*"}, namespace: "prod", want: false},
+		{scopes: nil, namespace: "prod", want: false},
+	}
+	for _, tt := range tc {
+		if got := namespaceMatches(tt.scopes, tt.namespace); got != tt.want {
+			t.Errorf("namespaceMatches(%v, %q) = %v, want %v", tt.scopes, tt.namespace, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+// Package celengine evaluates constraint templates written in CEL
+// alongside the Rego engine. A template opts in by declaring
+// targets[].code entries with engine K8sNativeValidation, the shape
+// ValidatingAdmissionPolicy users already know, so CEL policies carry
+// over without a Rego rewrite. The engine is a self-contained evaluator
+// for a documented CEL subset (see eval.go); templates using
+// expressions outside the subset are rejected when they are written.
+// It wraps the OPA client as a decorator, so both the webhook and
+// audit — which review objects through the same client — evaluate
+// whichever engine a template declares.
+package celengine
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"sync"
+
+	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/core/templates"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/types"
+	"github.com/open-policy-agent/gatekeeper/pkg/enginereview"
+	"github.com/open-policy-agent/gatekeeper/pkg/opashard"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	"github.com/open-policy-agent/gatekeeper/pkg/wasmengine"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var enableCEL = flag.Bool("experimental-enable-k8s-native-validation", false, "(alpha) evaluate constraint template code targets declaring engine K8sNativeValidation with the built-in CEL engine")
+
+// Engine is the engine name a template's code entry declares to be
+// evaluated here, matching ValidatingAdmissionPolicy terminology.
+const Engine = "K8sNativeValidation"
+
+// Enabled returns true if CEL template evaluation was requested via
+// flags.
+func Enabled() bool {
+	return *enableCEL
+}
+
+// Validation is one CEL expression and the message reported when it
+// evaluates to false.
+type Validation struct {
+	Expression string `json:"expression"`
+	Message    string `json:"message,omitempty"`
+}
+
+// Client wraps an OPA client and additionally evaluates CEL templates
+// on Review. Templates and constraints are read through the manager's
+// cached client, so no second watch pipeline is needed. It implements
+// opashard.Client.
+type Client struct {
+	opashard.Client
+	reader client.Reader
+
+	mux      sync.Mutex
+	programs map[string]*Program     // compiled expressions by source
+	kinds    map[string][]Validation // validations by constraint kind
+}
+
+var _ opashard.Client = &Client{}
+
+// New wraps inner with CEL template evaluation.
+func New(inner opashard.Client, reader client.Reader) *Client {
+	return &Client{Client: inner, reader: reader, programs: map[string]*Program{}, kinds: map[string][]Validation{}}
+}
+
+// AddTemplate indexes the template's validations by its constraint kind,
+// so reviews evaluate against the index instead of listing templates.
+// Rego templates pass through to the wrapped engine; CEL-only templates
+// have no Rego for it to compile, so they are acknowledged here and
+// served entirely by this engine.
+func (c *Client) AddTemplate(ctx context.Context, templ *templates.ConstraintTemplate) (*types.Responses, error) {
+	kind := templ.Spec.CRD.Spec.Names.Kind
+	validations := c.templateValidations(ctx, templ.GetName())
+	c.mux.Lock()
+	if len(validations) > 0 {
+		c.kinds[kind] = validations
+	} else {
+		delete(c.kinds, kind)
+	}
+	c.mux.Unlock()
+	if len(validations) > 0 && !hasRego(templ) {
+		return types.NewResponses(), nil
+	}
+	return c.Client.AddTemplate(ctx, templ)
+}
+
+// RemoveTemplate drops the template from the index and tolerates the
+// wrapped engine not knowing CEL-only templates it was never handed.
+func (c *Client) RemoveTemplate(ctx context.Context, templ *templates.ConstraintTemplate) (*types.Responses, error) {
+	c.mux.Lock()
+	delete(c.kinds, templ.Spec.CRD.Spec.Names.Kind)
+	c.mux.Unlock()
+	resp, err := c.Client.RemoveTemplate(ctx, templ)
+	if err != nil && !hasRego(templ) {
+		return types.NewResponses(), nil
+	}
+	return resp, err
+}
+
+func hasRego(templ *templates.ConstraintTemplate) bool {
+	for _, t := range templ.Spec.Targets {
+		if t.Rego != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// templateValidations fetches the named cluster template and returns
+// the CEL validations it declares, if any.
+func (c *Client) templateValidations(ctx context.Context, name string) []Validation {
+	t := &unstructured.Unstructured{}
+	t.SetGroupVersionKind(schema.GroupVersionKind{Group: "templates.gatekeeper.sh", Version: "v1beta1", Kind: "ConstraintTemplate"})
+	if err := c.reader.Get(ctx, k8stypes.NamespacedName{Name: name}, t); err != nil {
+		return nil
+	}
+	return Validations(t)
+}
+
+// Review evaluates the wrapped engine first, then every matching CEL
+// constraint, and merges the results.
+func (c *Client) Review(ctx context.Context, obj interface{}, opts ...opa.QueryOpt) (*types.Responses, error) {
+	resp, err := c.Client.Review(ctx, obj, opts...)
+	if err != nil {
+		return resp, err
+	}
+	review, ok := enginereview.Extract(obj)
+	if !ok {
+		return resp, nil
+	}
+	results, err := c.reviewCEL(ctx, review)
+	if err != nil {
+		return resp, err
+	}
+	if len(results) == 0 {
+		return resp, nil
+	}
+
+	targetName := (&target.K8sValidationTarget{}).GetName()
+	if resp.ByTarget == nil {
+		resp.ByTarget = map[string]*types.Response{}
+	}
+	if resp.ByTarget[targetName] == nil {
+		resp.ByTarget[targetName] = &types.Response{Target: targetName}
+	}
+	resp.ByTarget[targetName].Results = append(resp.ByTarget[targetName].Results, results...)
+	return resp, nil
+}
+
+// reviewCEL evaluates every CEL constraint matching the review. CEL
+// kinds come from the index AddTemplate maintains, so only constraints
+// of kinds that actually declare validations are listed.
+func (c *Client) reviewCEL(ctx context.Context, rev *enginereview.Review) ([]*types.Result, error) {
+	c.mux.Lock()
+	kinds := make([]string, 0, len(c.kinds))
+	validations := make(map[string][]Validation, len(c.kinds))
+	for kind, v := range c.kinds {
+		kinds = append(kinds, kind)
+		validations[kind] = v
+	}
+	c.mux.Unlock()
+	sort.Strings(kinds)
+
+	var results []*types.Result
+	for _, kind := range kinds {
+		constraints := &unstructured.UnstructuredList{}
+		constraints.SetGroupVersionKind(schema.GroupVersionKind{Group: "constraints.gatekeeper.sh", Version: "v1beta1", Kind: kind + "List"})
+		if err := c.reader.List(ctx, constraints); err != nil {
+			// the constraint CRD registers asynchronously after its template
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, fmt.Errorf("listing %s constraints: %w", kind, err)
+		}
+		for j := range constraints.Items {
+			constraint := &constraints.Items[j]
+			matched, err := enginereview.Matches(constraint, rev)
+			if err != nil {
+				// mirror the Rego matcher's autoreject: an unevaluable
+				// selector fails loudly instead of silently changing the
+				// constraint's scope
+				results = append(results, enginereview.MatchFailure(constraint, err))
+				continue
+			}
+			if !matched {
+				continue
+			}
+			results = append(results, c.evaluate(constraint, validations[kind], rev)...)
+		}
+	}
+	return results, nil
+}
+
+// evaluate runs each validation for one constraint. A false expression
+// is a violation; an expression that errors at runtime is reported as a
+// violation too, matching ValidatingAdmissionPolicy's Fail policy.
+func (c *Client) evaluate(constraint *unstructured.Unstructured, validations []Validation, rev *enginereview.Review) []*types.Result {
+	action := enginereview.EnforcementAction(constraint)
+	params, _, _ := unstructured.NestedMap(constraint.Object, "spec", "parameters")
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	vars := map[string]interface{}{
+		"object":    rev.Object,
+		"oldObject": rev.OldObject,
+		"request":   rev.Request,
+		"params":    params,
+	}
+
+	var results []*types.Result
+	for _, v := range validations {
+		program, err := c.program(v.Expression)
+		if err != nil {
+			results = append(results, &types.Result{
+				Msg:               fmt.Sprintf("constraint %s: %v", constraint.GetName(), err),
+				Constraint:        constraint,
+				EnforcementAction: action,
+			})
+			continue
+		}
+		out, err := program.Eval(vars)
+		if err != nil {
+			results = append(results, &types.Result{
+				Msg:               fmt.Sprintf("constraint %s: evaluating %q: %v", constraint.GetName(), v.Expression, err),
+				Constraint:        constraint,
+				EnforcementAction: action,
+			})
+			continue
+		}
+		if passed, ok := out.(bool); ok && passed {
+			continue
+		}
+		msg := v.Message
+		if msg == "" {
+			msg = fmt.Sprintf("failed expression: %s", v.Expression)
+		}
+		results = append(results, &types.Result{
+			Msg:               msg,
+			Constraint:        constraint,
+			EnforcementAction: action,
+		})
+	}
+	return results
+}
+
+// program returns the compiled form of an expression, compiling and
+// caching it on first use.
+func (c *Client) program(expression string) (*Program, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if p, ok := c.programs[expression]; ok {
+		return p, nil
+	}
+	p, err := Compile(expression)
+	if err != nil {
+		return nil, err
+	}
+	c.programs[expression] = p
+	return p, nil
+}
+
+// Validations extracts the CEL validations a template declares for this
+// engine, across all its targets.
+func Validations(t *unstructured.Unstructured) []Validation {
+	var out []Validation
+	targets, _, _ := unstructured.NestedSlice(t.Object, "spec", "targets")
+	for _, tgt := range targets {
+		targetMap, ok := tgt.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		code, _, _ := unstructured.NestedSlice(targetMap, "code")
+		for _, entry := range code {
+			entryMap, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			engine, _, _ := unstructured.NestedString(entryMap, "engine")
+			if engine != Engine {
+				continue
+			}
+			validations, _, _ := unstructured.NestedSlice(entryMap, "source", "validations")
+			for _, v := range validations {
+				vMap, ok := v.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				expression, _, _ := unstructured.NestedString(vMap, "expression")
+				message, _, _ := unstructured.NestedString(vMap, "message")
+				if expression != "" {
+					out = append(out, Validation{Expression: expression, Message: message})
+				}
+			}
+		}
+	}
+	return out
+}
+
+// ValidateTemplate rejects templates whose code targets declare an
+// unknown engine or expressions the CEL engine cannot compile, so
+// mistakes surface at write time instead of admitting everything.
+func ValidateTemplate(t *unstructured.Unstructured) error {
+	targets, _, _ := unstructured.NestedSlice(t.Object, "spec", "targets")
+	for _, tgt := range targets {
+		targetMap, ok := tgt.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		code, _, _ := unstructured.NestedSlice(targetMap, "code")
+		for _, entry := range code {
+			entryMap, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			engine, _, _ := unstructured.NestedString(entryMap, "engine")
+			if engine != Engine {
+				// the wasm engine validates its own code entries
+				if engine == wasmengine.Engine {
+					continue
+				}
+				return fmt.Errorf("unsupported code engine %q; only %s and %s are supported", engine, Engine, wasmengine.Engine)
+			}
+		}
+	}
+	for _, v := range Validations(t) {
+		if _, err := Compile(v.Expression); err != nil {
+			return fmt.Errorf("compiling CEL expression %q: %w", v.Expression, err)
+		}
+	}
+	return nil
+}
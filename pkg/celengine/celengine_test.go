@@ -0,0 +1,132 @@
+package celengine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/enginereview"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+const celTemplateManifest = `
+apiVersion: templates.gatekeeper.sh/v1beta1
+kind: ConstraintTemplate
+metadata:
+  name: k8smaxreplicas
+spec:
+  crd:
+    spec:
+      names:
+        kind: K8sMaxReplicas
+  targets:
+    - target: admission.k8s.gatekeeper.sh
+      code:
+        - engine: K8sNativeValidation
+          source:
+            validations:
+              - expression: "object.spec.replicas <= params.max"
+                message: "too many replicas"
+`
+
+func celTemplate(t *testing.T) *unstructured.Unstructured {
+	t.Helper()
+	obj := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal([]byte(celTemplateManifest), &obj.Object); err != nil {
+		t.Fatal(err)
+	}
+	return obj
+}
+
+func TestValidations(t *testing.T) {
+	validations := Validations(celTemplate(t))
+	if len(validations) != 1 {
+		t.Fatalf("expected one validation, got %v", validations)
+	}
+	if validations[0].Expression != "object.spec.replicas <= params.max" || validations[0].Message != "too many replicas" {
+		t.Errorf("unexpected validation: %+v", validations[0])
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	if err := ValidateTemplate(celTemplate(t)); err != nil {
+		t.Errorf("a valid template should validate, got %s", err)
+	}
+
+	bad := celTemplate(t)
+	targets, _, _ := unstructured.NestedSlice(bad.Object, "spec", "targets")
+	target := targets[0].(map[string]interface{})
+	code, _, _ := unstructured.NestedSlice(target, "code")
+	entry := code[0].(map[string]interface{})
+	_ = unstructured.SetNestedSlice(entry, []interface{}{
+		map[string]interface{}{"expression": "object.spec.replicas.asQuantity()"},
+	}, "source", "validations")
+	code[0] = entry
+	_ = unstructured.SetNestedSlice(target, code, "code")
+	targets[0] = target
+	_ = unstructured.SetNestedSlice(bad.Object, targets, "spec", "targets")
+	if err := ValidateTemplate(bad); err == nil || !strings.Contains(err.Error(), "compiling CEL expression") {
+		t.Errorf("expected a compile error for an unsupported expression, got %v", err)
+	}
+
+	_ = unstructured.SetNestedField(entry, "Rego", "engine")
+	code[0] = entry
+	_ = unstructured.SetNestedSlice(target, code, "code")
+	targets[0] = target
+	_ = unstructured.SetNestedSlice(bad.Object, targets, "spec", "targets")
+	if err := ValidateTemplate(bad); err == nil || !strings.Contains(err.Error(), "unsupported code engine") {
+		t.Errorf("expected an unsupported engine error, got %v", err)
+	}
+}
+
+func maxReplicasConstraint(t *testing.T, max int64) *unstructured.Unstructured {
+	t.Helper()
+	c := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	c.SetAPIVersion("constraints.gatekeeper.sh/v1beta1")
+	c.SetKind("K8sMaxReplicas")
+	c.SetName("replica-limit")
+	_ = unstructured.SetNestedSlice(c.Object, []interface{}{
+		map[string]interface{}{"apiGroups": []interface{}{"apps"}, "kinds": []interface{}{"Deployment"}},
+	}, "spec", "match", "kinds")
+	_ = unstructured.SetNestedField(c.Object, max, "spec", "parameters", "max")
+	return c
+}
+
+func TestEvaluate(t *testing.T) {
+	client := New(nil, nil)
+	constraint := maxReplicasConstraint(t, 2)
+	validations := Validations(celTemplate(t))
+	rev := &enginereview.Review{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{"replicas": int64(3)},
+		},
+		Request:   map[string]interface{}{"operation": "CREATE"},
+		GVK:       schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+		Namespace: "prod",
+	}
+
+	results := client.evaluate(constraint, validations, rev)
+	if len(results) != 1 {
+		t.Fatalf("expected one violation, got %v", results)
+	}
+	if results[0].Msg != "too many replicas" || results[0].EnforcementAction != "deny" {
+		t.Errorf("unexpected result: %+v", results[0])
+	}
+	if results[0].Constraint.GetName() != "replica-limit" {
+		t.Errorf("result should carry the constraint, got %v", results[0].Constraint)
+	}
+
+	// under the limit there is no violation
+	rev.Object["spec"].(map[string]interface{})["replicas"] = int64(1)
+	if results := client.evaluate(constraint, validations, rev); len(results) != 0 {
+		t.Errorf("expected no violations, got %v", results)
+	}
+
+	// a runtime error is a violation, matching VAP's Fail policy
+	delete(rev.Object["spec"].(map[string]interface{}), "replicas")
+	results = client.evaluate(constraint, validations, rev)
+	if len(results) != 1 || !strings.Contains(results[0].Msg, "evaluating") {
+		t.Errorf("expected an evaluation error violation, got %v", results)
+	}
+}
@@ -0,0 +1,1074 @@
+package celengine
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// This file implements the expression language the engine evaluates: a
+// subset of CEL covering what admission validations actually use.
+// Supported: literals (int, double, string, bool, null, lists, maps),
+// field selection and indexing, the operators || && ! == != < <= > >=
+// in + - * / % and ?:, the functions size() string() int() double() and
+// the has() macro, the string methods startsWith endsWith contains and
+// matches (RE2), and the comprehension macros all exists map filter
+// over lists. Anything else fails to compile, so unsupported templates
+// are rejected when they are written rather than misbehaving at
+// admission time. Evaluation follows CEL semantics where the subset
+// overlaps it; notably, selecting a missing field is an error unless
+// guarded by has().
+
+// Program is a compiled expression ready for repeated evaluation.
+type Program struct {
+	source string
+	root   node
+}
+
+// Compile parses an expression, returning an error describing the first
+// construct outside the supported subset.
+func Compile(source string) (*Program, error) {
+	p := &parser{lexer: lexer{input: source}}
+	p.next()
+	root, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at offset %d", p.tok.text, p.tok.offset)
+	}
+	return &Program{source: source, root: root}, nil
+}
+
+// Eval evaluates the program against the given variables. Values are
+// the usual JSON shapes: bool, int64, float64, string, nil,
+// []interface{}, and map[string]interface{}.
+func (p *Program) Eval(vars map[string]interface{}) (interface{}, error) {
+	return eval(p.root, vars)
+}
+
+// Source returns the expression the program was compiled from.
+func (p *Program) Source() string {
+	return p.source
+}
+
+// --- lexer ---
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenInt
+	tokenDouble
+	tokenString
+	tokenOp
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	offset int
+}
+
+type lexer struct {
+	input string
+	pos   int
+}
+
+func (l *lexer) lex() (token, error) {
+	for l.pos < len(l.input) && isSpace(l.input[l.pos]) {
+		l.pos++
+	}
+	start := l.pos
+	if l.pos >= len(l.input) {
+		return token{kind: tokenEOF, offset: start}, nil
+	}
+	c := l.input[l.pos]
+	switch {
+	case isIdentStart(c):
+		for l.pos < len(l.input) && isIdentPart(l.input[l.pos]) {
+			l.pos++
+		}
+		return token{kind: tokenIdent, text: l.input[start:l.pos], offset: start}, nil
+	case c >= '0' && c <= '9':
+		kind := tokenInt
+		for l.pos < len(l.input) && (isDigit(l.input[l.pos]) || l.input[l.pos] == '.' || l.input[l.pos] == 'e' || l.input[l.pos] == 'E') {
+			if l.input[l.pos] == '.' || l.input[l.pos] == 'e' || l.input[l.pos] == 'E' {
+				kind = tokenDouble
+			}
+			l.pos++
+		}
+		return token{kind: kind, text: l.input[start:l.pos], offset: start}, nil
+	case c == '\'' || c == '"':
+		quote := c
+		l.pos++
+		var sb strings.Builder
+		for l.pos < len(l.input) && l.input[l.pos] != quote {
+			ch := l.input[l.pos]
+			if ch == '\\' && l.pos+1 < len(l.input) {
+				l.pos++
+				switch l.input[l.pos] {
+				case 'n':
+					ch = '\n'
+				case 't':
+					ch = '\t'
+				case '\\', '\'', '"':
+					ch = l.input[l.pos]
+				default:
+					return token{}, fmt.Errorf("unsupported escape \\%c at offset %d", l.input[l.pos], l.pos)
+				}
+			}
+			sb.WriteByte(ch)
+			l.pos++
+		}
+		if l.pos >= len(l.input) {
+			return token{}, fmt.Errorf("unterminated string at offset %d", start)
+		}
+		l.pos++
+		return token{kind: tokenString, text: sb.String(), offset: start}, nil
+	}
+	for _, op := range []string{"||", "&&", "==", "!=", "<=", ">="} {
+		if strings.HasPrefix(l.input[l.pos:], op) {
+			l.pos += 2
+			return token{kind: tokenOp, text: op, offset: start}, nil
+		}
+	}
+	if strings.ContainsRune("!<>+-*/%?:.,[](){}", rune(c)) {
+		l.pos++
+		return token{kind: tokenOp, text: string(c), offset: start}, nil
+	}
+	return token{}, fmt.Errorf("unexpected character %q at offset %d", c, start)
+}
+
+func isSpace(c byte) bool      { return c == ' ' || c == '\t' || c == '\n' || c == '\r' }
+func isDigit(c byte) bool      { return c >= '0' && c <= '9' }
+func isIdentStart(c byte) bool { return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+func isIdentPart(c byte) bool  { return isIdentStart(c) || isDigit(c) }
+
+// --- AST ---
+
+type node interface{}
+
+type literalNode struct{ value interface{} }
+type identNode struct{ name string }
+type selectNode struct {
+	operand node
+	field   string
+}
+type indexNode struct{ operand, index node }
+type listNode struct{ elements []node }
+type mapNode struct{ keys, values []node }
+type unaryNode struct {
+	op      string
+	operand node
+}
+type binaryNode struct {
+	op          string
+	left, right node
+}
+type ternaryNode struct{ cond, then, els node }
+type callNode struct {
+	target node // nil for global functions
+	name   string
+	args   []node
+}
+
+// comprehensionNode is a macro like list.all(x, pred).
+type comprehensionNode struct {
+	op      string // all, exists, map, filter
+	operand node
+	varName string
+	body    node
+}
+
+// hasNode guards selection of the given path.
+type hasNode struct{ operand node }
+
+// --- parser ---
+
+type parser struct {
+	lexer lexer
+	tok   token
+	err   error
+}
+
+func (p *parser) next() {
+	if p.err != nil {
+		return
+	}
+	p.tok, p.err = p.lexer.lex()
+}
+
+func (p *parser) expectOp(text string) error {
+	if p.err != nil {
+		return p.err
+	}
+	if p.tok.kind != tokenOp || p.tok.text != text {
+		return fmt.Errorf("expected %q at offset %d, got %q", text, p.tok.offset, p.tok.text)
+	}
+	p.next()
+	return p.err
+}
+
+func (p *parser) parseExpr() (node, error) {
+	cond, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokenOp && p.tok.text == "?" {
+		p.next()
+		then, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectOp(":"); err != nil {
+			return nil, err
+		}
+		els, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		return &ternaryNode{cond: cond, then: then, els: els}, nil
+	}
+	return cond, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokenOp && p.tok.text == "||" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseRelation()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokenOp && p.tok.text == "&&" {
+		p.next()
+		right, err := p.parseRelation()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseRelation() (node, error) {
+	left, err := p.parseAddition()
+	if err != nil {
+		return nil, err
+	}
+	isRel := p.tok.kind == tokenOp && (p.tok.text == "==" || p.tok.text == "!=" || p.tok.text == "<" || p.tok.text == "<=" || p.tok.text == ">" || p.tok.text == ">=")
+	isIn := p.tok.kind == tokenIdent && p.tok.text == "in"
+	if !isRel && !isIn {
+		return left, nil
+	}
+	op := p.tok.text
+	p.next()
+	right, err := p.parseAddition()
+	if err != nil {
+		return nil, err
+	}
+	return &binaryNode{op: op, left: left, right: right}, nil
+}
+
+func (p *parser) parseAddition() (node, error) {
+	left, err := p.parseMultiplication()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokenOp && (p.tok.text == "+" || p.tok.text == "-") {
+		op := p.tok.text
+		p.next()
+		right, err := p.parseMultiplication()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseMultiplication() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokenOp && (p.tok.text == "*" || p.tok.text == "/" || p.tok.text == "%") {
+		op := p.tok.text
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.tok.kind == tokenOp && (p.tok.text == "!" || p.tok.text == "-") {
+		op := p.tok.text
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	operand, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.tok.kind == tokenOp && p.tok.text == ".":
+			p.next()
+			if p.tok.kind != tokenIdent {
+				return nil, fmt.Errorf("expected a field or method name at offset %d", p.tok.offset)
+			}
+			name := p.tok.text
+			p.next()
+			if p.tok.kind == tokenOp && p.tok.text == "(" {
+				operand, err = p.parseMethod(operand, name)
+				if err != nil {
+					return nil, err
+				}
+			} else {
+				operand = &selectNode{operand: operand, field: name}
+			}
+		case p.tok.kind == tokenOp && p.tok.text == "[":
+			p.next()
+			index, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOp("]"); err != nil {
+				return nil, err
+			}
+			operand = &indexNode{operand: operand, index: index}
+		default:
+			return operand, nil
+		}
+	}
+}
+
+// parseMethod parses a method call whose opening paren is current.
+func (p *parser) parseMethod(operand node, name string) (node, error) {
+	p.next() // consume "("
+	switch name {
+	case "all", "exists", "map", "filter":
+		if p.tok.kind != tokenIdent {
+			return nil, fmt.Errorf("%s() needs an iteration variable at offset %d", name, p.tok.offset)
+		}
+		varName := p.tok.text
+		p.next()
+		if err := p.expectOp(","); err != nil {
+			return nil, err
+		}
+		body, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+		return &comprehensionNode{op: name, operand: operand, varName: varName, body: body}, nil
+	case "startsWith", "endsWith", "contains", "matches":
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+		return &callNode{target: operand, name: name, args: []node{arg}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported method %q; the CEL engine supports startsWith, endsWith, contains, matches, all, exists, map, and filter", name)
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	switch p.tok.kind {
+	case tokenInt:
+		v, err := strconv.ParseInt(p.tok.text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", p.tok.text)
+		}
+		p.next()
+		return &literalNode{value: v}, nil
+	case tokenDouble:
+		v, err := strconv.ParseFloat(p.tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", p.tok.text)
+		}
+		p.next()
+		return &literalNode{value: v}, nil
+	case tokenString:
+		v := p.tok.text
+		p.next()
+		return &literalNode{value: v}, nil
+	case tokenIdent:
+		name := p.tok.text
+		p.next()
+		switch name {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null":
+			return &literalNode{value: nil}, nil
+		}
+		if p.tok.kind == tokenOp && p.tok.text == "(" {
+			return p.parseFunction(name)
+		}
+		return &identNode{name: name}, nil
+	case tokenOp:
+		switch p.tok.text {
+		case "(":
+			p.next()
+			inner, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOp(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		case "[":
+			p.next()
+			var elements []node
+			for !(p.tok.kind == tokenOp && p.tok.text == "]") {
+				element, err := p.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				elements = append(elements, element)
+				if p.tok.kind == tokenOp && p.tok.text == "," {
+					p.next()
+				}
+			}
+			p.next()
+			return &listNode{elements: elements}, nil
+		case "{":
+			p.next()
+			var keys, values []node
+			for !(p.tok.kind == tokenOp && p.tok.text == "}") {
+				key, err := p.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expectOp(":"); err != nil {
+					return nil, err
+				}
+				value, err := p.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				keys = append(keys, key)
+				values = append(values, value)
+				if p.tok.kind == tokenOp && p.tok.text == "," {
+					p.next()
+				}
+			}
+			p.next()
+			return &mapNode{keys: keys, values: values}, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected %q at offset %d", p.tok.text, p.tok.offset)
+}
+
+// parseFunction parses a global function call whose opening paren is
+// current.
+func (p *parser) parseFunction(name string) (node, error) {
+	p.next() // consume "("
+	switch name {
+	case "has":
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+		if _, ok := arg.(*selectNode); !ok {
+			return nil, fmt.Errorf("has() requires a field selection argument")
+		}
+		return &hasNode{operand: arg}, nil
+	case "size", "string", "int", "double":
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+		return &callNode{name: name, args: []node{arg}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported function %q; the CEL engine supports has, size, string, int, and double", name)
+	}
+}
+
+// --- evaluation ---
+
+func eval(n node, vars map[string]interface{}) (interface{}, error) {
+	switch n := n.(type) {
+	case *literalNode:
+		return n.value, nil
+	case *identNode:
+		v, ok := vars[n.name]
+		if !ok {
+			return nil, fmt.Errorf("undeclared variable %q", n.name)
+		}
+		return v, nil
+	case *selectNode:
+		operand, err := eval(n.operand, vars)
+		if err != nil {
+			return nil, err
+		}
+		m, ok := operand.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cannot select field %q from %s", n.field, typeName(operand))
+		}
+		v, ok := m[n.field]
+		if !ok {
+			return nil, fmt.Errorf("no such field %q", n.field)
+		}
+		return v, nil
+	case *indexNode:
+		return evalIndex(n, vars)
+	case *listNode:
+		out := make([]interface{}, 0, len(n.elements))
+		for _, e := range n.elements {
+			v, err := eval(e, vars)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+		return out, nil
+	case *mapNode:
+		out := make(map[string]interface{}, len(n.keys))
+		for i := range n.keys {
+			k, err := eval(n.keys[i], vars)
+			if err != nil {
+				return nil, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				return nil, fmt.Errorf("map keys must be strings, got %s", typeName(k))
+			}
+			v, err := eval(n.values[i], vars)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = v
+		}
+		return out, nil
+	case *unaryNode:
+		return evalUnary(n, vars)
+	case *binaryNode:
+		return evalBinary(n, vars)
+	case *ternaryNode:
+		cond, err := evalBool(n.cond, vars)
+		if err != nil {
+			return nil, err
+		}
+		if cond {
+			return eval(n.then, vars)
+		}
+		return eval(n.els, vars)
+	case *callNode:
+		return evalCall(n, vars)
+	case *comprehensionNode:
+		return evalComprehension(n, vars)
+	case *hasNode:
+		sel := n.operand.(*selectNode)
+		operand, err := eval(sel.operand, vars)
+		if err != nil {
+			return false, nil
+		}
+		m, ok := operand.(map[string]interface{})
+		if !ok {
+			return false, nil
+		}
+		_, ok = m[sel.field]
+		return ok, nil
+	}
+	return nil, fmt.Errorf("internal: unknown node %T", n)
+}
+
+func evalBool(n node, vars map[string]interface{}) (bool, error) {
+	v, err := eval(n, vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expected bool, got %s", typeName(v))
+	}
+	return b, nil
+}
+
+func evalIndex(n *indexNode, vars map[string]interface{}) (interface{}, error) {
+	operand, err := eval(n.operand, vars)
+	if err != nil {
+		return nil, err
+	}
+	index, err := eval(n.index, vars)
+	if err != nil {
+		return nil, err
+	}
+	switch operand := operand.(type) {
+	case []interface{}:
+		i, ok := toInt(index)
+		if !ok {
+			return nil, fmt.Errorf("list index must be an int, got %s", typeName(index))
+		}
+		if i < 0 || i >= int64(len(operand)) {
+			return nil, fmt.Errorf("index %d out of range", i)
+		}
+		return operand[i], nil
+	case map[string]interface{}:
+		key, ok := index.(string)
+		if !ok {
+			return nil, fmt.Errorf("map key must be a string, got %s", typeName(index))
+		}
+		v, ok := operand[key]
+		if !ok {
+			return nil, fmt.Errorf("no such key %q", key)
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("cannot index %s", typeName(operand))
+}
+
+func evalUnary(n *unaryNode, vars map[string]interface{}) (interface{}, error) {
+	v, err := eval(n.operand, vars)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "!":
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("cannot negate %s", typeName(v))
+		}
+		return !b, nil
+	case "-":
+		switch v := v.(type) {
+		case int64:
+			return -v, nil
+		case float64:
+			return -v, nil
+		}
+		return nil, fmt.Errorf("cannot negate %s", typeName(v))
+	}
+	return nil, fmt.Errorf("internal: unknown unary %q", n.op)
+}
+
+func evalBinary(n *binaryNode, vars map[string]interface{}) (interface{}, error) {
+	// logical operators short-circuit
+	if n.op == "||" || n.op == "&&" {
+		left, err := evalBool(n.left, vars)
+		if err != nil {
+			return nil, err
+		}
+		if (n.op == "||" && left) || (n.op == "&&" && !left) {
+			return left, nil
+		}
+		return evalBool(n.right, vars)
+	}
+
+	left, err := eval(n.left, vars)
+	if err != nil {
+		return nil, err
+	}
+	right, err := eval(n.right, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "in":
+		list, ok := right.([]interface{})
+		if !ok {
+			if m, ok := right.(map[string]interface{}); ok {
+				key, ok := left.(string)
+				if !ok {
+					return nil, fmt.Errorf("map membership needs a string key, got %s", typeName(left))
+				}
+				_, found := m[key]
+				return found, nil
+			}
+			return nil, fmt.Errorf("in needs a list or map, got %s", typeName(right))
+		}
+		for _, item := range list {
+			if equal(left, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "<", "<=", ">", ">=":
+		return compare(n.op, left, right)
+	case "+":
+		if ls, ok := left.(string); ok {
+			rs, ok := right.(string)
+			if !ok {
+				return nil, fmt.Errorf("cannot add string and %s", typeName(right))
+			}
+			return ls + rs, nil
+		}
+		if ll, ok := left.([]interface{}); ok {
+			rl, ok := right.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("cannot add list and %s", typeName(right))
+			}
+			return append(append([]interface{}{}, ll...), rl...), nil
+		}
+		return arith(n.op, left, right)
+	case "-", "*", "/", "%":
+		return arith(n.op, left, right)
+	}
+	return nil, fmt.Errorf("internal: unknown operator %q", n.op)
+}
+
+func evalCall(n *callNode, vars map[string]interface{}) (interface{}, error) {
+	if n.target == nil {
+		arg, err := eval(n.args[0], vars)
+		if err != nil {
+			return nil, err
+		}
+		switch n.name {
+		case "size":
+			switch arg := arg.(type) {
+			case string:
+				return int64(len(arg)), nil
+			case []interface{}:
+				return int64(len(arg)), nil
+			case map[string]interface{}:
+				return int64(len(arg)), nil
+			}
+			return nil, fmt.Errorf("size() cannot measure %s", typeName(arg))
+		case "string":
+			switch arg := arg.(type) {
+			case string:
+				return arg, nil
+			case int64:
+				return strconv.FormatInt(arg, 10), nil
+			case float64:
+				return strconv.FormatFloat(arg, 'g', -1, 64), nil
+			case bool:
+				return strconv.FormatBool(arg), nil
+			}
+			return nil, fmt.Errorf("string() cannot convert %s", typeName(arg))
+		case "int":
+			switch arg := arg.(type) {
+			case int64:
+				return arg, nil
+			case float64:
+				return int64(arg), nil
+			case string:
+				v, err := strconv.ParseInt(arg, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("int() cannot convert %q", arg)
+				}
+				return v, nil
+			}
+			return nil, fmt.Errorf("int() cannot convert %s", typeName(arg))
+		case "double":
+			switch arg := arg.(type) {
+			case float64:
+				return arg, nil
+			case int64:
+				return float64(arg), nil
+			case string:
+				v, err := strconv.ParseFloat(arg, 64)
+				if err != nil {
+					return nil, fmt.Errorf("double() cannot convert %q", arg)
+				}
+				return v, nil
+			}
+			return nil, fmt.Errorf("double() cannot convert %s", typeName(arg))
+		}
+		return nil, fmt.Errorf("internal: unknown function %q", n.name)
+	}
+
+	target, err := eval(n.target, vars)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := target.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s() needs a string receiver, got %s", n.name, typeName(target))
+	}
+	arg, err := eval(n.args[0], vars)
+	if err != nil {
+		return nil, err
+	}
+	argStr, ok := arg.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s() needs a string argument, got %s", n.name, typeName(arg))
+	}
+	switch n.name {
+	case "startsWith":
+		return strings.HasPrefix(s, argStr), nil
+	case "endsWith":
+		return strings.HasSuffix(s, argStr), nil
+	case "contains":
+		return strings.Contains(s, argStr), nil
+	case "matches":
+		re, err := regexp.Compile(argStr)
+		if err != nil {
+			return nil, fmt.Errorf("matches(): %v", err)
+		}
+		return re.MatchString(s), nil
+	}
+	return nil, fmt.Errorf("internal: unknown method %q", n.name)
+}
+
+func evalComprehension(n *comprehensionNode, vars map[string]interface{}) (interface{}, error) {
+	operand, err := eval(n.operand, vars)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := operand.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s() needs a list, got %s", n.op, typeName(operand))
+	}
+
+	scoped := make(map[string]interface{}, len(vars)+1)
+	for k, v := range vars {
+		scoped[k] = v
+	}
+	var mapped []interface{}
+	for _, item := range list {
+		scoped[n.varName] = item
+		switch n.op {
+		case "all":
+			ok, err := evalBool(n.body, scoped)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return false, nil
+			}
+		case "exists":
+			ok, err := evalBool(n.body, scoped)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				return true, nil
+			}
+		case "map":
+			v, err := eval(n.body, scoped)
+			if err != nil {
+				return nil, err
+			}
+			mapped = append(mapped, v)
+		case "filter":
+			ok, err := evalBool(n.body, scoped)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				mapped = append(mapped, item)
+			}
+		}
+	}
+	switch n.op {
+	case "all":
+		return true, nil
+	case "exists":
+		return false, nil
+	default:
+		if mapped == nil {
+			mapped = []interface{}{}
+		}
+		return mapped, nil
+	}
+}
+
+// equal compares values with numeric promotion, so 2 == 2.0 as in CEL.
+func equal(a, b interface{}) bool {
+	if af, aok := toFloat(a); aok {
+		bf, bok := toFloat(b)
+		return bok && af == bf
+	}
+	switch a := a.(type) {
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, v := range a {
+			bv, found := b[k]
+			if !found || !equal(v, bv) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+func compare(op string, left, right interface{}) (interface{}, error) {
+	if ls, ok := left.(string); ok {
+		rs, ok := right.(string)
+		if !ok {
+			return nil, fmt.Errorf("cannot compare string and %s", typeName(right))
+		}
+		return applyOrder(op, strings.Compare(ls, rs)), nil
+	}
+	lf, lok := toFloat(left)
+	rf, rok := toFloat(right)
+	if !lok || !rok {
+		return nil, fmt.Errorf("cannot compare %s and %s", typeName(left), typeName(right))
+	}
+	switch {
+	case lf < rf:
+		return applyOrder(op, -1), nil
+	case lf > rf:
+		return applyOrder(op, 1), nil
+	default:
+		return applyOrder(op, 0), nil
+	}
+}
+
+func applyOrder(op string, cmp int) bool {
+	switch op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+func arith(op string, left, right interface{}) (interface{}, error) {
+	li, lok := left.(int64)
+	ri, rok := right.(int64)
+	if lok && rok {
+		switch op {
+		case "+":
+			return li + ri, nil
+		case "-":
+			return li - ri, nil
+		case "*":
+			return li * ri, nil
+		case "/":
+			if ri == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			return li / ri, nil
+		case "%":
+			if ri == 0 {
+				return nil, fmt.Errorf("modulo by zero")
+			}
+			return li % ri, nil
+		}
+	}
+	lf, lok := toFloat(left)
+	rf, rok := toFloat(right)
+	if !lok || !rok {
+		return nil, fmt.Errorf("cannot apply %q to %s and %s", op, typeName(left), typeName(right))
+	}
+	switch op {
+	case "+":
+		return lf + rf, nil
+	case "-":
+		return lf - rf, nil
+	case "*":
+		return lf * rf, nil
+	case "/":
+		if rf == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return lf / rf, nil
+	case "%":
+		return math.Mod(lf, rf), nil
+	}
+	return nil, fmt.Errorf("internal: unknown operator %q", op)
+}
+
+// toInt converts the JSON number shapes to int64.
+func toInt(v interface{}) (int64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return v, true
+	case float64:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case int64:
+		return "int"
+	case float64:
+		return "double"
+	case string:
+		return "string"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "map"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,116 @@
+package celengine
+
+import (
+	"reflect"
+	"testing"
+)
+
+func podVars() map[string]interface{} {
+	return map[string]interface{}{
+		"object": map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":   "web",
+				"labels": map[string]interface{}{"app": "web", "team": "platform"},
+			},
+			"spec": map[string]interface{}{
+				"replicas": int64(3),
+				"containers": []interface{}{
+					map[string]interface{}{"name": "main", "image": "registry.internal/web:v1"},
+					map[string]interface{}{"name": "sidecar", "image": "registry.internal/proxy:v2"},
+				},
+			},
+		},
+		"oldObject": nil,
+		"params":    map[string]interface{}{"maxReplicas": int64(5)},
+		"request":   map[string]interface{}{"operation": "CREATE"},
+	}
+}
+
+func TestEval(t *testing.T) {
+	tc := []struct {
+		expr string
+		want interface{}
+	}{
+		{`object.spec.replicas <= params.maxReplicas`, true},
+		{`object.spec.replicas > 5`, false},
+		{`object.metadata.name == 'web'`, true},
+		{`object.metadata.labels.app in ['web', 'api']`, true},
+		{`'team' in object.metadata.labels`, true},
+		{`has(object.spec.nodeSelector)`, false},
+		{`has(object.spec.containers)`, true},
+		{`size(object.spec.containers)`, int64(2)},
+		{`object.spec.containers.all(c, c.image.startsWith('registry.internal/'))`, true},
+		{`object.spec.containers.exists(c, c.name == 'injected')`, false},
+		{`object.spec.containers.map(c, c.name)`, []interface{}{"main", "sidecar"}},
+		{`object.spec.containers.filter(c, c.name == 'main').size() == 1`, nil}, // method form of size is unsupported
+		{`size(object.spec.containers.filter(c, c.name == 'main')) == 1`, true},
+		{`request.operation == 'CREATE' && object.spec.replicas != 0`, true},
+		{`!has(object.spec.hostNetwork) || object.spec.hostNetwork == false`, true},
+		{`object.metadata.name.matches('^[a-z]+$')`, true},
+		{`'replicas: ' + string(object.spec.replicas)`, "replicas: 3"},
+		{`object.spec.replicas >= 2 ? 'scaled' : 'single'`, "scaled"},
+		{`(2 + 3) * 2 == 10`, true},
+		{`2 == 2.0`, true},
+		{`10 % 3`, int64(1)},
+	}
+	for _, tt := range tc {
+		t.Run(tt.expr, func(t *testing.T) {
+			p, err := Compile(tt.expr)
+			if tt.want == nil {
+				if err == nil {
+					t.Fatal("expected a compile error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("compile: %s", err)
+			}
+			got, err := p.Eval(podVars())
+			if err != nil {
+				t.Fatalf("eval: %s", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v (%T), want %v", got, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileRejectsUnsupported(t *testing.T) {
+	for _, expr := range []string{
+		`object.spec.duration(`,
+		`timestamp('2020-01-01T00:00:00Z') < now`,
+		`object.spec.containers.exists_one(c, true)`,
+		`object..name`,
+		`"unterminated`,
+	} {
+		if _, err := Compile(expr); err == nil {
+			t.Errorf("expected %q to fail to compile", expr)
+		}
+	}
+}
+
+func TestEvalMissingFieldIsError(t *testing.T) {
+	p, err := Compile(`object.spec.hostNetwork == true`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Eval(podVars()); err == nil {
+		t.Fatal("expected an error selecting a missing field without has()")
+	}
+}
+
+func TestEvalShortCircuit(t *testing.T) {
+	// the right side would error, but the left side decides
+	p, err := Compile(`has(object.spec.replicas) && object.spec.replicas < 5 || object.spec.missing == 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := p.Eval(podVars())
+	if err != nil {
+		t.Fatalf("eval: %s", err)
+	}
+	if got != true {
+		t.Errorf("got %v, want true", got)
+	}
+}
@@ -0,0 +1,213 @@
+// Package certmanager consumes webhook serving certs managed by
+// cert-manager instead of the built-in rotator. cert-manager owns the
+// webhook cert Secret; this package only watches it and injects its CA
+// bundle into the webhook configurations, so organizations that
+// standardize on cert-manager for all TLS can disable Gatekeeper's own
+// certificate generation entirely.
+package certmanager
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/open-policy-agent/cert-controller/pkg/rotator"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+var log = logf.Log.WithName("cert-manager-certs")
+
+// syncPeriod is how often the injector re-reads the Secret; cert-manager
+// CA rotations are picked up within one period.
+const syncPeriod = 10 * time.Second
+
+var certManagerCerts = flag.Bool("enable-cert-manager-certs", false, "(alpha) consume webhook serving certs from cert-manager: watch the cert Secret and inject its CA bundle into the webhook configurations instead of generating certificates with the built-in rotator")
+
+// Enabled returns true if cert-manager managed certs were requested via
+// flags.
+func Enabled() bool {
+	return *certManagerCerts
+}
+
+// Injector watches the cert-manager managed webhook cert Secret and
+// keeps the CA bundle in the webhook configurations in sync with it. It
+// implements manager.Runnable.
+type Injector struct {
+	// SecretKey locates the Secret cert-manager writes the serving
+	// certificate into.
+	SecretKey types.NamespacedName
+	// CertDir is where the Secret is mounted; the webhook server cannot
+	// serve until the cert files appear there.
+	CertDir string
+	// Webhooks are the webhook configurations to inject the CA bundle
+	// into.
+	Webhooks []rotator.WebhookInfo
+	// IsReady is closed once the certs are mounted and the CA bundle has
+	// been injected.
+	IsReady chan struct{}
+
+	client    client.Client
+	readyOnce sync.Once
+}
+
+// AddInjector registers the injector with the manager using a direct
+// (uncached) client, so the Secret does not need to be watchable through
+// the manager's cache.
+func AddInjector(mgr manager.Manager, inj *Injector) error {
+	c, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return err
+	}
+	inj.client = c
+	return mgr.Add(inj)
+}
+
+// Start implements manager.Runnable. It blocks until ctx is cancelled,
+// re-syncing the CA bundle every syncPeriod.
+func (i *Injector) Start(ctx context.Context) error {
+	ticker := time.NewTicker(syncPeriod)
+	defer ticker.Stop()
+	for {
+		if err := i.sync(ctx); err != nil {
+			log.Error(err, "unable to sync CA bundle from cert-manager secret", "secret", i.SecretKey)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// sync reads the Secret, injects its CA bundle into every webhook
+// configuration, and signals readiness once the certs are mounted.
+func (i *Injector) sync(ctx context.Context) error {
+	secret := &corev1.Secret{}
+	if err := i.client.Get(ctx, i.SecretKey, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("waiting for cert-manager to create the webhook cert secret", "secret", i.SecretKey)
+			return nil
+		}
+		return err
+	}
+	ca, err := caBundleFromSecret(secret)
+	if err != nil {
+		return err
+	}
+
+	for _, wh := range i.Webhooks {
+		if err := i.injectWebhook(ctx, wh, ca); err != nil {
+			return fmt.Errorf("injecting CA bundle into %s: %w", wh.Name, err)
+		}
+	}
+
+	if !i.certsMounted() {
+		log.Info("waiting for cert-manager certs to be mounted", "certDir", i.CertDir)
+		return nil
+	}
+	i.readyOnce.Do(func() {
+		log.Info("cert-manager certs are ready", "certDir", i.CertDir)
+		if i.IsReady != nil {
+			close(i.IsReady)
+		}
+	})
+	return nil
+}
+
+// injectWebhook updates one webhook configuration if its CA bundle is
+// out of date.
+func (i *Injector) injectWebhook(ctx context.Context, wh rotator.WebhookInfo, ca []byte) error {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(webhookGVK(wh.Type))
+	if err := i.client.Get(ctx, types.NamespacedName{Name: wh.Name}, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("waiting for webhook configuration to exist", "webhook", wh.Name)
+			return nil
+		}
+		return err
+	}
+	changed, err := InjectCABundle(obj, ca)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+	log.Info("injecting CA bundle from cert-manager secret", "webhook", wh.Name)
+	return i.client.Update(ctx, obj)
+}
+
+func (i *Injector) certsMounted() bool {
+	_, err := os.Stat(filepath.Join(i.CertDir, "tls.crt"))
+	return err == nil
+}
+
+// caBundleFromSecret extracts the CA bundle cert-manager wrote into the
+// Secret, falling back to the serving cert for self-signed issuers that
+// do not populate ca.crt.
+func caBundleFromSecret(secret *corev1.Secret) ([]byte, error) {
+	if ca := secret.Data["ca.crt"]; len(ca) > 0 {
+		return ca, nil
+	}
+	if crt := secret.Data["tls.crt"]; len(crt) > 0 {
+		return crt, nil
+	}
+	return nil, errors.New("secret has neither ca.crt nor tls.crt")
+}
+
+// InjectCABundle sets clientConfig.caBundle on every webhook in the
+// configuration, reporting whether anything changed.
+func InjectCABundle(obj *unstructured.Unstructured, ca []byte) (bool, error) {
+	encoded := base64.StdEncoding.EncodeToString(ca)
+	webhooks, found, err := unstructured.NestedSlice(obj.Object, "webhooks")
+	if err != nil {
+		return false, err
+	}
+	if !found {
+		return false, errors.New("webhook configuration has no webhooks")
+	}
+	changed := false
+	for _, w := range webhooks {
+		hook, ok := w.(map[string]interface{})
+		if !ok {
+			return false, errors.New("webhook entry is not an object")
+		}
+		current, _, err := unstructured.NestedString(hook, "clientConfig", "caBundle")
+		if err != nil {
+			return false, err
+		}
+		if current == encoded {
+			continue
+		}
+		if err := unstructured.SetNestedField(hook, encoded, "clientConfig", "caBundle"); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	if changed {
+		if err := unstructured.SetNestedSlice(obj.Object, webhooks, "webhooks"); err != nil {
+			return false, err
+		}
+	}
+	return changed, nil
+}
+
+func webhookGVK(t rotator.WebhookType) schema.GroupVersionKind {
+	if t == rotator.Mutating {
+		return schema.GroupVersionKind{Group: "admissionregistration.k8s.io", Version: "v1", Kind: "MutatingWebhookConfiguration"}
+	}
+	return schema.GroupVersionKind{Group: "admissionregistration.k8s.io", Version: "v1", Kind: "ValidatingWebhookConfiguration"}
+}
@@ -0,0 +1,92 @@
+package certmanager
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func makeWebhookConfig(caBundle string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("admissionregistration.k8s.io/v1")
+	obj.SetKind("ValidatingWebhookConfiguration")
+	obj.SetName("gatekeeper-validating-webhook-configuration")
+	hook := map[string]interface{}{
+		"name":         "validation.gatekeeper.sh",
+		"clientConfig": map[string]interface{}{},
+	}
+	if caBundle != "" {
+		hook["clientConfig"].(map[string]interface{})["caBundle"] = caBundle
+	}
+	_ = unstructured.SetNestedSlice(obj.Object, []interface{}{hook}, "webhooks")
+	return obj
+}
+
+func TestCABundleFromSecret(t *testing.T) {
+	secret := &corev1.Secret{Data: map[string][]byte{
+		"ca.crt":  []byte("the-ca"),
+		"tls.crt": []byte("the-cert"),
+	}}
+	ca, err := caBundleFromSecret(secret)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !bytes.Equal(ca, []byte("the-ca")) {
+		t.Errorf("expected ca.crt to win, got %q", ca)
+	}
+
+	delete(secret.Data, "ca.crt")
+	ca, err = caBundleFromSecret(secret)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !bytes.Equal(ca, []byte("the-cert")) {
+		t.Errorf("expected fallback to tls.crt, got %q", ca)
+	}
+
+	delete(secret.Data, "tls.crt")
+	if _, err := caBundleFromSecret(secret); err == nil {
+		t.Errorf("expected an error for an empty secret")
+	}
+}
+
+func TestInjectCABundle(t *testing.T) {
+	obj := makeWebhookConfig("")
+	changed, err := InjectCABundle(obj, []byte("the-ca"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !changed {
+		t.Errorf("expected injection into an empty clientConfig to report a change")
+	}
+	webhooks, _, _ := unstructured.NestedSlice(obj.Object, "webhooks")
+	got, _, _ := unstructured.NestedString(webhooks[0].(map[string]interface{}), "clientConfig", "caBundle")
+	want := base64.StdEncoding.EncodeToString([]byte("the-ca"))
+	if got != want {
+		t.Errorf("caBundle = %q, want %q", got, want)
+	}
+}
+
+func TestInjectCABundleIdempotent(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte("the-ca"))
+	obj := makeWebhookConfig(encoded)
+	changed, err := InjectCABundle(obj, []byte("the-ca"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if changed {
+		t.Errorf("injecting an up-to-date CA bundle should not report a change")
+	}
+}
+
+func TestInjectCABundleNoWebhooks(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("admissionregistration.k8s.io/v1")
+	obj.SetKind("ValidatingWebhookConfiguration")
+	if _, err := InjectCABundle(obj, []byte("the-ca")); err == nil {
+		t.Errorf("expected an error for a configuration without webhooks")
+	}
+}
@@ -0,0 +1,164 @@
+// Package changelog records every create, update, and delete of policy
+// objects — constraint templates, constraints, mutators, the sync
+// Config, exemptions, and overrides — as they pass through the
+// validating webhook. Each entry captures who made the change, when,
+// the operation, and a summary of which fields changed, and is appended
+// to a JSON-lines file that compliance reviews can export. Entries are
+// also emitted to the structured log so the trail survives even without
+// a configured file.
+package changelog
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("policy-change-log")
+
+// trackedGroups are the API groups whose writes are recorded.
+var trackedGroups = map[string]bool{
+	"templates.gatekeeper.sh":   true,
+	"constraints.gatekeeper.sh": true,
+	"mutations.gatekeeper.sh":   true,
+	"config.gatekeeper.sh":      true,
+	"exemptions.gatekeeper.sh":  true,
+	"overrides.gatekeeper.sh":   true,
+}
+
+var (
+	enablePolicyChangeLog = flag.Bool("enable-policy-change-log", false, "(alpha) record every create/update/delete of policy objects (templates, constraints, mutators, Config, exemptions) with who, when, and a field-level diff summary")
+	policyChangeLogPath   = flag.String("policy-change-log-path", "", "file to append policy change log entries to, one JSON object per line; if unset, entries only go to the structured log")
+)
+
+// Enabled returns true if the policy change log was requested via flags.
+func Enabled() bool {
+	return *enablePolicyChangeLog
+}
+
+// Entry is one recorded policy change.
+type Entry struct {
+	Timestamp     time.Time `json:"timestamp"`
+	Username      string    `json:"username"`
+	Groups        []string  `json:"groups,omitempty"`
+	Operation     string    `json:"operation"`
+	Group         string    `json:"group"`
+	Kind          string    `json:"kind"`
+	Namespace     string    `json:"namespace,omitempty"`
+	Name          string    `json:"name"`
+	ChangedFields []string  `json:"changedFields,omitempty"`
+}
+
+// Recorder appends change entries to the configured sink.
+type Recorder struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewRecorder opens the configured log file, if any.
+func NewRecorder() (*Recorder, error) {
+	r := &Recorder{}
+	if *policyChangeLogPath != "" {
+		f, err := os.OpenFile(*policyChangeLogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		r.file = f
+	}
+	return r, nil
+}
+
+// Tracked reports whether the request targets a policy object whose
+// changes are recorded.
+func Tracked(req *admissionv1.AdmissionRequest) bool {
+	return trackedGroups[req.Kind.Group]
+}
+
+// Record captures one policy change from an admission request. The
+// entry reflects the write as submitted to the API server's admission
+// chain; denials are visible in the webhook's own decision logs.
+func (r *Recorder) Record(req *admissionv1.AdmissionRequest) {
+	entry := Entry{
+		Timestamp:     time.Now().UTC(),
+		Username:      req.UserInfo.Username,
+		Groups:        req.UserInfo.Groups,
+		Operation:     string(req.Operation),
+		Group:         req.Kind.Group,
+		Kind:          req.Kind.Kind,
+		Namespace:     req.Namespace,
+		Name:          req.Name,
+		ChangedFields: changedFields(req.OldObject.Raw, req.Object.Raw),
+	}
+	log.Info("policy change recorded",
+		"username", entry.Username,
+		"operation", entry.Operation,
+		"group", entry.Group,
+		"kind", entry.Kind,
+		"name", entry.Name,
+		"changedFields", entry.ChangedFields,
+	)
+	if r.file == nil {
+		return
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		log.Error(err, "unable to serialize policy change entry")
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.file.Write(append(line, '\n')); err != nil {
+		log.Error(err, "unable to append to policy change log")
+	}
+}
+
+// changedFields summarizes which top-level spec fields (plus labels and
+// annotations) differ between the old and new object. Creates and
+// deletes, which have only one side, report no diff.
+func changedFields(oldRaw, newRaw []byte) []string {
+	if len(oldRaw) == 0 || len(newRaw) == 0 {
+		return nil
+	}
+	var oldObj, newObj map[string]interface{}
+	if err := json.Unmarshal(oldRaw, &oldObj); err != nil {
+		return nil
+	}
+	if err := json.Unmarshal(newRaw, &newObj); err != nil {
+		return nil
+	}
+
+	changed := map[string]bool{}
+	oldSpec, _ := oldObj["spec"].(map[string]interface{})
+	newSpec, _ := newObj["spec"].(map[string]interface{})
+	for field := range oldSpec {
+		if !reflect.DeepEqual(oldSpec[field], newSpec[field]) {
+			changed["spec."+field] = true
+		}
+	}
+	for field := range newSpec {
+		if _, found := oldSpec[field]; !found {
+			changed["spec."+field] = true
+		}
+	}
+	oldMeta, _ := oldObj["metadata"].(map[string]interface{})
+	newMeta, _ := newObj["metadata"].(map[string]interface{})
+	for _, field := range []string{"labels", "annotations"} {
+		if !reflect.DeepEqual(oldMeta[field], newMeta[field]) {
+			changed["metadata."+field] = true
+		}
+	}
+
+	fields := make([]string, 0, len(changed))
+	for field := range changed {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
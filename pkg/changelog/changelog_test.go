@@ -0,0 +1,87 @@
+package changelog
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestTracked(t *testing.T) {
+	req := &admissionv1.AdmissionRequest{
+		Kind: metav1.GroupVersionKind{Group: "constraints.gatekeeper.sh", Kind: "K8sRequiredLabels"},
+	}
+	if !Tracked(req) {
+		t.Errorf("constraint writes should be tracked")
+	}
+	req.Kind.Group = "apps"
+	if Tracked(req) {
+		t.Errorf("ordinary resources should not be tracked")
+	}
+}
+
+func TestChangedFields(t *testing.T) {
+	oldRaw := []byte(`{"metadata": {"labels": {"a": "1"}}, "spec": {"match": {"kinds": []}, "parameters": {"labels": ["owner"]}}}`)
+	newRaw := []byte(`{"metadata": {"labels": {"a": "2"}}, "spec": {"match": {"kinds": []}, "parameters": {"labels": ["team"]}, "enforcementAction": "dryrun"}}`)
+	got := changedFields(oldRaw, newRaw)
+	want := []string{"metadata.labels", "spec.enforcementAction", "spec.parameters"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("changedFields = %v, want %v", got, want)
+	}
+	if fields := changedFields(nil, newRaw); fields != nil {
+		t.Errorf("creates should report no diff, got %v", fields)
+	}
+}
+
+func TestRecordAppendsToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changes.log")
+	oldPath := *policyChangeLogPath
+	t.Cleanup(func() { *policyChangeLogPath = oldPath })
+	*policyChangeLogPath = path
+
+	r, err := NewRecorder()
+	if err != nil {
+		t.Fatalf("opening recorder: %s", err)
+	}
+	req := &admissionv1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Group: "constraints.gatekeeper.sh", Version: "v1beta1", Kind: "K8sRequiredLabels"},
+		Name:      "require-owner",
+		Operation: admissionv1.Update,
+		UserInfo:  authenticationv1.UserInfo{Username: "admin@example.com"},
+		OldObject: runtime.RawExtension{Raw: []byte(`{"spec": {"enforcementAction": "deny"}}`)},
+		Object:    runtime.RawExtension{Raw: []byte(`{"spec": {"enforcementAction": "dryrun"}}`)},
+	}
+	r.Record(req)
+	r.Record(req)
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("opening change log: %s", err)
+	}
+	defer f.Close()
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("parsing change log line: %s", err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[0].Username != "admin@example.com" || entries[0].Operation != "UPDATE" {
+		t.Errorf("entry = %+v, want the request's user and operation", entries[0])
+	}
+	if !reflect.DeepEqual(entries[0].ChangedFields, []string{"spec.enforcementAction"}) {
+		t.Errorf("changed fields = %v, want [spec.enforcementAction]", entries[0].ChangedFields)
+	}
+}
@@ -0,0 +1,290 @@
+// Package cloudauth obtains short-lived credentials for cloud-hosted
+// external data providers and export sinks using the cluster's workload
+// identity integration, so no long-lived secrets need to be mounted.
+// Three token flows are supported: AWS IRSA (web identity federation via
+// STS), GCP Workload Identity (the GKE metadata server), and Azure
+// Workload Identity (federated token exchange). The active flow is
+// detected from the environment the platform's webhook injects, or
+// forced with --cloud-auth-provider.
+package cloudauth
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Platform environment variables injected by the workload identity
+// webhooks.
+const (
+	awsRoleARNEnv   = "AWS_ROLE_ARN"
+	awsTokenFileEnv = "AWS_WEB_IDENTITY_TOKEN_FILE"
+
+	azureClientIDEnv  = "AZURE_CLIENT_ID"
+	azureTenantIDEnv  = "AZURE_TENANT_ID"
+	azureTokenFileEnv = "AZURE_FEDERATED_TOKEN_FILE"
+)
+
+// expirySkew is subtracted from token lifetimes so callers never present
+// a credential that expires mid-request.
+const expirySkew = 5 * time.Minute
+
+// Endpoints, overridable in tests.
+var (
+	stsEndpoint      = "https://sts.amazonaws.com/"
+	gcpTokenEndpoint = "http://169.254.169.254/computeMetadata/v1/instance/service-accounts/default/token"
+	azureAuthority   = "https://login.microsoftonline.com"
+)
+
+var cloudAuthProvider = flag.String("cloud-auth-provider", "", "(alpha) workload identity flow for authenticating to cloud-hosted providers and export sinks: 'aws', 'gcp', or 'azure'; auto-detected from the environment if unset")
+
+// Token is a short-lived credential.
+type Token struct {
+	// Value is the bearer token or, for AWS, the JSON-encoded STS
+	// credentials.
+	Value  string
+	Expiry time.Time
+}
+
+// Expired reports whether the token needs refreshing.
+func (t Token) Expired() bool {
+	return time.Now().After(t.Expiry.Add(-expirySkew))
+}
+
+// TokenSource produces credentials for one platform.
+type TokenSource interface {
+	// Token returns a credential, refreshing it if needed.
+	Token(ctx context.Context) (Token, error)
+}
+
+// Validate rejects unknown providers at startup.
+func Validate() error {
+	switch *cloudAuthProvider {
+	case "", "aws", "gcp", "azure":
+		return nil
+	default:
+		return fmt.Errorf("invalid --cloud-auth-provider %q; must be 'aws', 'gcp', or 'azure'", *cloudAuthProvider)
+	}
+}
+
+// NewSource returns the token source for the configured or detected
+// platform, or nil if no workload identity integration is present.
+func NewSource() (TokenSource, error) {
+	provider := *cloudAuthProvider
+	if provider == "" {
+		provider = detect()
+	}
+	switch provider {
+	case "aws":
+		if os.Getenv(awsRoleARNEnv) == "" || os.Getenv(awsTokenFileEnv) == "" {
+			return nil, fmt.Errorf("AWS IRSA requires %s and %s in the environment", awsRoleARNEnv, awsTokenFileEnv)
+		}
+		return newCaching(&awsSource{roleARN: os.Getenv(awsRoleARNEnv), tokenFile: os.Getenv(awsTokenFileEnv)}), nil
+	case "gcp":
+		return newCaching(&gcpSource{}), nil
+	case "azure":
+		for _, env := range []string{azureClientIDEnv, azureTenantIDEnv, azureTokenFileEnv} {
+			if os.Getenv(env) == "" {
+				return nil, fmt.Errorf("Azure Workload Identity requires %s in the environment", env)
+			}
+		}
+		return newCaching(&azureSource{
+			clientID:  os.Getenv(azureClientIDEnv),
+			tenantID:  os.Getenv(azureTenantIDEnv),
+			tokenFile: os.Getenv(azureTokenFileEnv),
+		}), nil
+	case "":
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown cloud auth provider %q", provider)
+}
+
+// detect infers the platform from the injected environment.
+func detect() string {
+	if os.Getenv(awsRoleARNEnv) != "" && os.Getenv(awsTokenFileEnv) != "" {
+		return "aws"
+	}
+	if os.Getenv(azureClientIDEnv) != "" && os.Getenv(azureTokenFileEnv) != "" {
+		return "azure"
+	}
+	return ""
+}
+
+// caching wraps a source so the underlying flow only runs when the
+// cached token approaches expiry.
+type caching struct {
+	inner TokenSource
+
+	mu    sync.Mutex
+	token Token
+}
+
+func newCaching(inner TokenSource) TokenSource {
+	return &caching{inner: inner}
+}
+
+func (c *caching) Token(ctx context.Context) (Token, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token.Value != "" && !c.token.Expired() {
+		return c.token, nil
+	}
+	token, err := c.inner.Token(ctx)
+	if err != nil {
+		return Token{}, err
+	}
+	c.token = token
+	return token, nil
+}
+
+// awsSource exchanges the projected service account token for STS
+// credentials via AssumeRoleWithWebIdentity.
+type awsSource struct {
+	roleARN   string
+	tokenFile string
+}
+
+// AWSCredentials is the JSON form of the STS credentials carried in
+// Token.Value for AWS.
+type AWSCredentials struct {
+	AccessKeyID     string `json:"accessKeyId"`
+	SecretAccessKey string `json:"secretAccessKey"`
+	SessionToken    string `json:"sessionToken"`
+}
+
+type stsResponse struct {
+	Credentials struct {
+		AccessKeyID     string    `xml:"AccessKeyId"`
+		SecretAccessKey string    `xml:"SecretAccessKey"`
+		SessionToken    string    `xml:"SessionToken"`
+		Expiration      time.Time `xml:"Expiration"`
+	} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+}
+
+func (s *awsSource) Token(ctx context.Context) (Token, error) {
+	webToken, err := ioutil.ReadFile(s.tokenFile)
+	if err != nil {
+		return Token{}, fmt.Errorf("reading web identity token: %w", err)
+	}
+	params := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {s.roleARN},
+		"RoleSessionName":  {"gatekeeper"},
+		"WebIdentityToken": {strings.TrimSpace(string(webToken))},
+	}
+	body, err := postForm(ctx, stsEndpoint, params)
+	if err != nil {
+		return Token{}, fmt.Errorf("assuming role with web identity: %w", err)
+	}
+	resp := &stsResponse{}
+	if err := xml.Unmarshal(body, resp); err != nil {
+		return Token{}, fmt.Errorf("parsing STS response: %w", err)
+	}
+	creds, err := json.Marshal(AWSCredentials{
+		AccessKeyID:     resp.Credentials.AccessKeyID,
+		SecretAccessKey: resp.Credentials.SecretAccessKey,
+		SessionToken:    resp.Credentials.SessionToken,
+	})
+	if err != nil {
+		return Token{}, err
+	}
+	return Token{Value: string(creds), Expiry: resp.Credentials.Expiration}, nil
+}
+
+// gcpSource fetches an access token from the metadata server, which the
+// GKE Workload Identity integration scopes to the bound service account.
+type gcpSource struct{}
+
+type gcpTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+func (s *gcpSource) Token(ctx context.Context) (Token, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gcpTokenEndpoint, nil)
+	if err != nil {
+		return Token{}, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	body, err := do(req)
+	if err != nil {
+		return Token{}, fmt.Errorf("fetching metadata server token: %w", err)
+	}
+	resp := &gcpTokenResponse{}
+	if err := json.Unmarshal(body, resp); err != nil {
+		return Token{}, fmt.Errorf("parsing metadata server token: %w", err)
+	}
+	return Token{Value: resp.AccessToken, Expiry: time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)}, nil
+}
+
+// azureSource exchanges the projected federated token for an AAD access
+// token via the client assertion flow.
+type azureSource struct {
+	clientID  string
+	tenantID  string
+	tokenFile string
+}
+
+type azureTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+func (s *azureSource) Token(ctx context.Context) (Token, error) {
+	assertion, err := ioutil.ReadFile(s.tokenFile)
+	if err != nil {
+		return Token{}, fmt.Errorf("reading federated token: %w", err)
+	}
+	params := url.Values{
+		"grant_type":            {"client_credentials"},
+		"client_id":             {s.clientID},
+		"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+		"client_assertion":      {strings.TrimSpace(string(assertion))},
+		"scope":                 {"https://management.azure.com/.default"},
+	}
+	endpoint := fmt.Sprintf("%s/%s/oauth2/v2.0/token", azureAuthority, s.tenantID)
+	body, err := postForm(ctx, endpoint, params)
+	if err != nil {
+		return Token{}, fmt.Errorf("exchanging federated token: %w", err)
+	}
+	resp := &azureTokenResponse{}
+	if err := json.Unmarshal(body, resp); err != nil {
+		return Token{}, fmt.Errorf("parsing token response: %w", err)
+	}
+	return Token{Value: resp.AccessToken, Expiry: time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)}, nil
+}
+
+func postForm(ctx context.Context, endpoint string, params url.Values) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return do(req)
+}
+
+func do(req *http.Request) ([]byte, error) {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %d: %s", req.URL.Host, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
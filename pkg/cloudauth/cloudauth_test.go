@@ -0,0 +1,149 @@
+package cloudauth
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTokenFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "token")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("writing token file: %s", err)
+	}
+	return path
+}
+
+func TestAWSSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parsing form: %s", err)
+		}
+		if r.Form.Get("WebIdentityToken") != "projected-token" {
+			t.Errorf("WebIdentityToken = %q, want the projected token", r.Form.Get("WebIdentityToken"))
+		}
+		if r.Form.Get("RoleArn") != "arn:aws:iam::123456789012:role/gatekeeper" {
+			t.Errorf("RoleArn = %q", r.Form.Get("RoleArn"))
+		}
+		w.Write([]byte(`<AssumeRoleWithWebIdentityResponse>
+  <AssumeRoleWithWebIdentityResult>
+    <Credentials>
+      <AccessKeyId>AKIDEXAMPLE</AccessKeyId>
+      <SecretAccessKey>secret</SecretAccessKey>
+      <SessionToken>session</SessionToken>
+      <Expiration>2030-01-01T00:00:00Z</Expiration>
+    </Credentials>
+  </AssumeRoleWithWebIdentityResult>
+</AssumeRoleWithWebIdentityResponse>`))
+	}))
+	defer srv.Close()
+	defer func(old string) { stsEndpoint = old }(stsEndpoint)
+	stsEndpoint = srv.URL
+
+	source := &awsSource{
+		roleARN:   "arn:aws:iam::123456789012:role/gatekeeper",
+		tokenFile: writeTokenFile(t, "projected-token\n"),
+	}
+	token, err := source.Token(context.Background())
+	if err != nil {
+		t.Fatalf("fetching token: %s", err)
+	}
+	creds := &AWSCredentials{}
+	if err := json.Unmarshal([]byte(token.Value), creds); err != nil {
+		t.Fatalf("parsing credentials: %s", err)
+	}
+	if creds.AccessKeyID != "AKIDEXAMPLE" || creds.SessionToken != "session" {
+		t.Errorf("credentials = %+v, want the STS response", creds)
+	}
+	if token.Expired() {
+		t.Errorf("token expiring in 2030 should not be expired")
+	}
+}
+
+func TestGCPSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			t.Errorf("requests must carry the Metadata-Flavor header")
+		}
+		w.Write([]byte(`{"access_token": "gcp-token", "expires_in": 3600}`))
+	}))
+	defer srv.Close()
+	defer func(old string) { gcpTokenEndpoint = old }(gcpTokenEndpoint)
+	gcpTokenEndpoint = srv.URL
+
+	token, err := (&gcpSource{}).Token(context.Background())
+	if err != nil {
+		t.Fatalf("fetching token: %s", err)
+	}
+	if token.Value != "gcp-token" {
+		t.Errorf("token = %q, want gcp-token", token.Value)
+	}
+}
+
+func TestAzureSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parsing form: %s", err)
+		}
+		if r.Form.Get("client_assertion") != "federated-token" {
+			t.Errorf("client_assertion = %q, want the federated token", r.Form.Get("client_assertion"))
+		}
+		w.Write([]byte(`{"access_token": "azure-token", "expires_in": 3600}`))
+	}))
+	defer srv.Close()
+	defer func(old string) { azureAuthority = old }(azureAuthority)
+	azureAuthority = srv.URL
+
+	source := &azureSource{
+		clientID:  "client-id",
+		tenantID:  "tenant-id",
+		tokenFile: writeTokenFile(t, "federated-token"),
+	}
+	token, err := source.Token(context.Background())
+	if err != nil {
+		t.Fatalf("fetching token: %s", err)
+	}
+	if token.Value != "azure-token" {
+		t.Errorf("token = %q, want azure-token", token.Value)
+	}
+}
+
+func TestCachingRefreshesOnlyWhenExpired(t *testing.T) {
+	calls := 0
+	source := newCaching(stubSource(func() (Token, error) {
+		calls++
+		return Token{Value: "token", Expiry: time.Now().Add(time.Hour)}, nil
+	}))
+	for i := 0; i < 3; i++ {
+		if _, err := source.Token(context.Background()); err != nil {
+			t.Fatalf("fetching token: %s", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("unexpired tokens should be served from cache, got %d fetches", calls)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	defer func(old string) { *cloudAuthProvider = old }(*cloudAuthProvider)
+	for _, provider := range []string{"", "aws", "gcp", "azure"} {
+		*cloudAuthProvider = provider
+		if err := Validate(); err != nil {
+			t.Errorf("provider %q should be valid: %s", provider, err)
+		}
+	}
+	*cloudAuthProvider = "ibm"
+	if err := Validate(); err == nil {
+		t.Errorf("unknown providers should be rejected")
+	}
+}
+
+type stubSource func() (Token, error)
+
+func (s stubSource) Token(context.Context) (Token, error) { return s() }
@@ -0,0 +1,75 @@
+// Package clusteridentity exposes the identity labels of the cluster
+// Gatekeeper runs on. Operators describe the cluster class (environment,
+// region, tier, ...) in a ConfigMap in the gatekeeper namespace; a single
+// GitOps-managed policy bundle can then scope constraints to cluster
+// classes via spec.clusterSelector without per-cluster templating.
+package clusteridentity
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("cluster-identity").WithValues(logging.Process, "cluster_identity")
+
+// ConfigMapName is the name of the ConfigMap whose data holds the
+// cluster identity labels.
+const ConfigMapName = "gatekeeper-cluster-identity"
+
+// refreshInterval bounds how long cached identity labels are served
+// before the ConfigMap is re-read.
+const refreshInterval = time.Minute
+
+// Provider serves the cluster identity labels, caching them briefly so
+// hot paths like admission do not hit the API server per request.
+type Provider struct {
+	client    client.Client
+	namespace string
+
+	mux       sync.Mutex
+	labels    map[string]string
+	expiresAt time.Time
+}
+
+// New returns a Provider reading the cluster identity ConfigMap from the
+// given namespace.
+func New(c client.Client, namespace string) *Provider {
+	return &Provider{
+		client:    c,
+		namespace: namespace,
+	}
+}
+
+// Labels returns the cluster identity labels. A missing ConfigMap yields
+// an empty set, so constraints with cluster selectors simply do not
+// activate.
+func (p *Provider) Labels(ctx context.Context) map[string]string {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	now := time.Now()
+	if now.Before(p.expiresAt) {
+		return p.labels
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := p.client.Get(ctx, types.NamespacedName{Namespace: p.namespace, Name: ConfigMapName}, cm); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			log.Error(err, "unable to read cluster identity ConfigMap, serving stale labels")
+			return p.labels
+		}
+		p.labels = nil
+	} else {
+		p.labels = cm.Data
+	}
+	p.expiresAt = now.Add(refreshInterval)
+	return p.labels
+}
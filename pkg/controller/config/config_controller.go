@@ -19,13 +19,13 @@ import (
 	"context"
 	"fmt"
 
-	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
 	configv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/config/v1alpha1"
 	"github.com/open-policy-agent/gatekeeper/pkg/controller/config/process"
 	syncc "github.com/open-policy-agent/gatekeeper/pkg/controller/sync"
 	"github.com/open-policy-agent/gatekeeper/pkg/keys"
 	"github.com/open-policy-agent/gatekeeper/pkg/metrics"
 	"github.com/open-policy-agent/gatekeeper/pkg/mutation"
+	"github.com/open-policy-agent/gatekeeper/pkg/opashard"
 	"github.com/open-policy-agent/gatekeeper/pkg/readiness"
 	"github.com/open-policy-agent/gatekeeper/pkg/target"
 	"github.com/open-policy-agent/gatekeeper/pkg/watch"
@@ -33,6 +33,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -54,7 +55,7 @@ const (
 var log = logf.Log.WithName("controller").WithValues("kind", "Config")
 
 type Adder struct {
-	Opa              *opa.Client
+	Opa              opashard.Client
 	WatchManager     *watch.Manager
 	ControllerSwitch *watch.ControllerSwitch
 	Tracker          *readiness.Tracker
@@ -75,7 +76,7 @@ func (a *Adder) Add(mgr manager.Manager) error {
 	return add(mgr, r)
 }
 
-func (a *Adder) InjectOpa(o *opa.Client) {
+func (a *Adder) InjectOpa(o opashard.Client) {
 	a.Opa = o
 }
 
@@ -124,7 +125,15 @@ func newReconciler(mgr manager.Manager, opa syncc.OpaDataClient, wm *watch.Manag
 	if err != nil {
 		return nil, err
 	}
+	var disc discoveryLister
+	if wildcardSyncEnabled() {
+		disc, err = discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+		if err != nil {
+			return nil, fmt.Errorf("building discovery client for wildcard sync: %w", err)
+		}
+	}
 	return &ReconcileConfig{
+		discovery:        disc,
 		reader:           mgr.GetCache(),
 		writer:           mgr.GetClient(),
 		statusClient:     mgr.GetClient(),
@@ -166,6 +175,7 @@ type ReconcileConfig struct {
 
 	scheme           *runtime.Scheme
 	opa              syncc.OpaDataClient
+	discovery        discoveryLister
 	syncMetricsCache *syncc.MetricsCache
 	cs               *watch.ControllerSwitch
 	watcher          *watch.Registrar
@@ -226,10 +236,29 @@ func (r *ReconcileConfig) Reconcile(ctx context.Context, request reconcile.Reque
 	newSyncOnly := watch.NewSet()
 	newExcluder := process.New()
 	var statsEnabled bool
+	// wildcard entries are re-expanded against discovery on a timer so
+	// kinds added by new CRDs start syncing without a Config update
+	result := reconcile.Result{}
 	// If the config is being deleted the user is saying they don't want to
 	// sync anything
 	if exists && instance.GetDeletionTimestamp().IsZero() {
 		for _, entry := range instance.Spec.Sync.SyncOnly {
+			if isWildcardEntry(entry) {
+				if !wildcardSyncEnabled() {
+					log.Info("ignoring wildcard syncOnly entry; enable with --enable-wildcard-sync",
+						"group", entry.Group, "version", entry.Version, "kind", entry.Kind)
+					continue
+				}
+				gvks, err := expandWildcardEntry(r.discovery, entry)
+				if err != nil {
+					return reconcile.Result{}, fmt.Errorf("expanding wildcard syncOnly entry: %w", err)
+				}
+				for _, gvk := range gvks {
+					newSyncOnly.Add(gvk)
+				}
+				result.RequeueAfter = *wildcardSyncRefresh
+				continue
+			}
 			gvk := schema.GroupVersionKind{Group: entry.Group, Version: entry.Version, Kind: entry.Kind}
 			newSyncOnly.Add(gvk)
 		}
@@ -255,7 +284,7 @@ func (r *ReconcileConfig) Reconcile(ctx context.Context, request reconcile.Reque
 	if r.watched.Equals(newSyncOnly) && r.processExcluder.Equals(newExcluder) {
 		// ...unless we have pending wipe / replay operations from a previous reconcile.
 		if !(r.needsWipe || r.needsReplay != nil) {
-			return reconcile.Result{}, nil
+			return result, nil
 		}
 
 		// If we reach here, the watch set hasn't changed since last reconcile, but we
@@ -300,7 +329,7 @@ func (r *ReconcileConfig) Reconcile(ctx context.Context, request reconcile.Reque
 		return reconcile.Result{}, fmt.Errorf("replaying data: %w", err)
 	}
 
-	return reconcile.Result{}, nil
+	return result, nil
 }
 
 func (r *ReconcileConfig) wipeCacheIfNeeded(ctx context.Context) error {
@@ -7,9 +7,14 @@ import (
 	configv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/config/v1alpha1"
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+var log = logf.Log.WithName("process-excluder")
+
 type Process string
 
 const (
@@ -20,9 +25,18 @@ const (
 	Star     = Process("*")
 )
 
+// namespaceSelector pairs a configured excludedNamespaceSelector with its
+// compiled form. The raw selector is retained so Equals can compare
+// excluders without depending on selector internals.
+type namespaceSelector struct {
+	raw      *metav1.LabelSelector
+	selector labels.Selector
+}
+
 type Excluder struct {
 	mux                sync.RWMutex
 	excludedNamespaces map[Process]map[string]bool
+	excludedSelectors  map[Process][]namespaceSelector
 }
 
 var allProcesses = []Process{
@@ -33,6 +47,7 @@ var allProcesses = []Process{
 
 var processExcluder = &Excluder{
 	excludedNamespaces: make(map[Process]map[string]bool),
+	excludedSelectors:  make(map[Process][]namespaceSelector),
 }
 
 func Get() *Excluder {
@@ -42,6 +57,7 @@ func Get() *Excluder {
 func New() *Excluder {
 	return &Excluder{
 		excludedNamespaces: make(map[Process]map[string]bool),
+		excludedSelectors:  make(map[Process][]namespaceSelector),
 	}
 }
 
@@ -68,6 +84,26 @@ func (s *Excluder) Add(entry []configv1alpha1.MatchEntry) {
 				}
 			}
 		}
+
+		if matchEntry.ExcludedNamespaceSelector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(matchEntry.ExcludedNamespaceSelector)
+		if err != nil {
+			log.Error(err, "invalid excludedNamespaceSelector, entry will not exclude any namespaces by label")
+			continue
+		}
+		nss := namespaceSelector{raw: matchEntry.ExcludedNamespaceSelector, selector: selector}
+		for _, op := range matchEntry.Processes {
+			// adding excluded namespace selector to all processes for "*"
+			if Process(op) == Star {
+				for _, o := range allProcesses {
+					s.excludedSelectors[o] = append(s.excludedSelectors[o], nss)
+				}
+			} else {
+				s.excludedSelectors[Process(op)] = append(s.excludedSelectors[Process(op)], nss)
+			}
+		}
 	}
 }
 
@@ -75,12 +111,24 @@ func (s *Excluder) Replace(new *Excluder) {
 	s.mux.Lock()
 	defer s.mux.Unlock()
 	s.excludedNamespaces = new.excludedNamespaces
+	s.excludedSelectors = new.excludedSelectors
 }
 
 func (s *Excluder) Equals(new *Excluder) bool {
 	s.mux.RLock()
 	defer s.mux.RUnlock()
-	return reflect.DeepEqual(s.excludedNamespaces, new.excludedNamespaces)
+	return reflect.DeepEqual(s.excludedNamespaces, new.excludedNamespaces) &&
+		reflect.DeepEqual(s.rawSelectors(), new.rawSelectors())
+}
+
+func (s *Excluder) rawSelectors() map[Process][]*metav1.LabelSelector {
+	raw := make(map[Process][]*metav1.LabelSelector)
+	for p, selectors := range s.excludedSelectors {
+		for _, nss := range selectors {
+			raw[p] = append(raw[p], nss.raw)
+		}
+	}
+	return raw
 }
 
 func (s *Excluder) IsNamespaceExcluded(process Process, obj runtime.Object) (bool, error) {
@@ -93,8 +141,37 @@ func (s *Excluder) IsNamespaceExcluded(process Process, obj runtime.Object) (boo
 	}
 
 	if obj.GetObjectKind().GroupVersionKind().Kind == "Namespace" && obj.GetObjectKind().GroupVersionKind().Group == "" {
-		return s.excludedNamespaces[process][meta.GetName()], nil
+		if s.excludedNamespaces[process][meta.GetName()] {
+			return true, nil
+		}
+		return s.labelsMatchSelectors(process, meta.GetLabels()), nil
 	}
 
 	return s.excludedNamespaces[process][meta.GetNamespace()], nil
 }
+
+// HasNamespaceSelectors reports whether any excludedNamespaceSelectors are
+// configured for the process. Callers use this to avoid fetching namespace
+// labels when no selector could match.
+func (s *Excluder) HasNamespaceSelectors(process Process) bool {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	return len(s.excludedSelectors[process]) > 0
+}
+
+// IsNamespaceExcludedByLabels returns true if the given namespace labels
+// match an excludedNamespaceSelector configured for the process.
+func (s *Excluder) IsNamespaceExcludedByLabels(process Process, nsLabels map[string]string) bool {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	return s.labelsMatchSelectors(process, nsLabels)
+}
+
+func (s *Excluder) labelsMatchSelectors(process Process, nsLabels map[string]string) bool {
+	for _, nss := range s.excludedSelectors[process] {
+		if nss.selector.Matches(labels.Set(nsLabels)) {
+			return true
+		}
+	}
+	return false
+}
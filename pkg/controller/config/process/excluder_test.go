@@ -0,0 +1,94 @@
+package process
+
+import (
+	"testing"
+
+	configv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/config/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newExcluderWithSelector(processes []string, matchLabels map[string]string) *Excluder {
+	excluder := New()
+	excluder.Add([]configv1alpha1.MatchEntry{
+		{
+			ExcludedNamespaceSelector: &metav1.LabelSelector{MatchLabels: matchLabels},
+			Processes:                 processes,
+		},
+	})
+	return excluder
+}
+
+func makeNamespace(name string, labels map[string]string) *unstructured.Unstructured {
+	ns := &unstructured.Unstructured{}
+	ns.SetAPIVersion("v1")
+	ns.SetKind("Namespace")
+	ns.SetName(name)
+	ns.SetLabels(labels)
+	return ns
+}
+
+func TestIsNamespaceExcludedBySelector(t *testing.T) {
+	excluder := newExcluderWithSelector([]string{"webhook"}, map[string]string{"admission.gatekeeper.sh/ignore": "true"})
+
+	excluded, err := excluder.IsNamespaceExcluded(Webhook, makeNamespace("preview-1", map[string]string{"admission.gatekeeper.sh/ignore": "true"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !excluded {
+		t.Errorf("namespace with matching labels should be excluded")
+	}
+
+	excluded, err = excluder.IsNamespaceExcluded(Webhook, makeNamespace("prod", nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if excluded {
+		t.Errorf("namespace without matching labels should not be excluded")
+	}
+
+	excluded, err = excluder.IsNamespaceExcluded(Audit, makeNamespace("preview-1", map[string]string{"admission.gatekeeper.sh/ignore": "true"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if excluded {
+		t.Errorf("selector should only apply to the configured process")
+	}
+}
+
+func TestIsNamespaceExcludedByLabels(t *testing.T) {
+	excluder := newExcluderWithSelector([]string{"*"}, map[string]string{"team": "ci"})
+
+	if !excluder.IsNamespaceExcludedByLabels(Audit, map[string]string{"team": "ci"}) {
+		t.Errorf("matching labels should be excluded for all processes with \"*\"")
+	}
+	if excluder.IsNamespaceExcludedByLabels(Audit, map[string]string{"team": "platform"}) {
+		t.Errorf("non-matching labels should not be excluded")
+	}
+}
+
+func TestHasNamespaceSelectors(t *testing.T) {
+	excluder := newExcluderWithSelector([]string{"webhook"}, map[string]string{"team": "ci"})
+	if !excluder.HasNamespaceSelectors(Webhook) {
+		t.Errorf("expected namespace selectors for webhook process")
+	}
+	if excluder.HasNamespaceSelectors(Sync) {
+		t.Errorf("expected no namespace selectors for sync process")
+	}
+	if New().HasNamespaceSelectors(Webhook) {
+		t.Errorf("expected no namespace selectors on an empty excluder")
+	}
+}
+
+func TestEqualsWithSelectors(t *testing.T) {
+	a := newExcluderWithSelector([]string{"webhook"}, map[string]string{"team": "ci"})
+	b := newExcluderWithSelector([]string{"webhook"}, map[string]string{"team": "ci"})
+	if !a.Equals(b) {
+		t.Errorf("excluders with identical selectors should be equal")
+	}
+
+	c := newExcluderWithSelector([]string{"webhook"}, map[string]string{"team": "platform"})
+	if a.Equals(c) {
+		t.Errorf("excluders with different selectors should not be equal")
+	}
+}
@@ -0,0 +1,130 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	configv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/config/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	enableWildcardSync  = flag.Bool("enable-wildcard-sync", false, "(alpha) allow syncOnly entries to use * for group, version, or kind, expanded against API discovery")
+	wildcardSyncRefresh = flag.Duration("wildcard-sync-refresh", time.Minute, "how often wildcard syncOnly entries are re-expanded against API discovery, so newly installed CRDs start syncing without a Config update")
+)
+
+// wildcardSyncEnabled returns true when wildcard syncOnly entries were
+// requested via flags.
+func wildcardSyncEnabled() bool {
+	return *enableWildcardSync
+}
+
+// discoveryLister is the subset of the discovery client wildcard
+// expansion needs.
+type discoveryLister interface {
+	ServerPreferredResources() ([]*metav1.APIResourceList, error)
+	ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error)
+}
+
+// isWildcardEntry reports whether any field of the entry is the *
+// wildcard.
+func isWildcardEntry(entry configv1alpha1.SyncOnlyEntry) bool {
+	return entry.Group == "*" || entry.Version == "*" || entry.Kind == "*"
+}
+
+// expandWildcardEntry resolves a wildcard syncOnly entry into the
+// concrete GVKs the cluster currently serves. An entry pinned to an
+// exact version is expanded against that group/version; otherwise each
+// group's preferred version is used so a kind is not synced at several
+// versions at once. Resources that cannot be listed and watched are
+// skipped.
+func expandWildcardEntry(disc discoveryLister, entry configv1alpha1.SyncOnlyEntry) ([]schema.GroupVersionKind, error) {
+	var lists []*metav1.APIResourceList
+	if entry.Version != "*" && entry.Group != "*" {
+		groupVersion := entry.Version
+		if entry.Group != "" {
+			groupVersion = entry.Group + "/" + entry.Version
+		}
+		list, err := disc.ServerResourcesForGroupVersion(groupVersion)
+		if err != nil {
+			return nil, fmt.Errorf("discovering resources in %s: %w", groupVersion, err)
+		}
+		lists = append(lists, list)
+	} else {
+		preferred, err := disc.ServerPreferredResources()
+		if err != nil {
+			return nil, fmt.Errorf("discovering preferred resources: %w", err)
+		}
+		lists = preferred
+	}
+
+	var out []schema.GroupVersionKind
+	for _, list := range lists {
+		if list == nil {
+			continue
+		}
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		if !fieldMatches(entry.Group, gv.Group) || !fieldMatches(entry.Version, gv.Version) {
+			continue
+		}
+		for _, resource := range list.APIResources {
+			// subresources such as pods/status are not syncable objects
+			if strings.Contains(resource.Name, "/") {
+				continue
+			}
+			if !fieldMatches(entry.Kind, resource.Kind) {
+				continue
+			}
+			if !hasVerbs(resource, "list", "watch") {
+				continue
+			}
+			out = append(out, schema.GroupVersionKind{Group: gv.Group, Version: gv.Version, Kind: resource.Kind})
+		}
+	}
+	return out, nil
+}
+
+// fieldMatches matches an entry field against a discovered value; * is
+// the wildcard.
+func fieldMatches(pattern, value string) bool {
+	return pattern == "*" || pattern == value
+}
+
+// hasVerbs reports whether the resource supports all of the given
+// verbs.
+func hasVerbs(resource metav1.APIResource, verbs ...string) bool {
+	for _, verb := range verbs {
+		found := false
+		for _, supported := range resource.Verbs {
+			if supported == verb {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
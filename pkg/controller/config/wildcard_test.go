@@ -0,0 +1,134 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	configv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/config/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type fakeDiscovery struct {
+	lists []*metav1.APIResourceList
+}
+
+func (f *fakeDiscovery) ServerPreferredResources() ([]*metav1.APIResourceList, error) {
+	return f.lists, nil
+}
+
+func (f *fakeDiscovery) ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error) {
+	for _, list := range f.lists {
+		if list.GroupVersion == groupVersion {
+			return list, nil
+		}
+	}
+	return nil, fmt.Errorf("no resources for %s", groupVersion)
+}
+
+func watchable(kind, name string) metav1.APIResource {
+	return metav1.APIResource{Kind: kind, Name: name, Verbs: metav1.Verbs{"get", "list", "watch"}}
+}
+
+func discoveryFixture() *fakeDiscovery {
+	return &fakeDiscovery{lists: []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{
+			watchable("Pod", "pods"),
+			watchable("Service", "services"),
+			{Kind: "Binding", Name: "bindings", Verbs: metav1.Verbs{"create"}},
+			{Kind: "Pod", Name: "pods/status", Verbs: metav1.Verbs{"get", "list", "watch"}},
+		}},
+		{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{
+			watchable("Deployment", "deployments"),
+			watchable("StatefulSet", "statefulsets"),
+		}},
+	}}
+}
+
+func TestIsWildcardEntry(t *testing.T) {
+	if isWildcardEntry(configv1alpha1.SyncOnlyEntry{Group: "apps", Version: "v1", Kind: "Deployment"}) {
+		t.Errorf("concrete entry should not be treated as a wildcard")
+	}
+	for _, entry := range []configv1alpha1.SyncOnlyEntry{
+		{Group: "*", Version: "v1", Kind: "Deployment"},
+		{Group: "apps", Version: "*", Kind: "Deployment"},
+		{Group: "apps", Version: "v1", Kind: "*"},
+	} {
+		if !isWildcardEntry(entry) {
+			t.Errorf("entry %+v should be treated as a wildcard", entry)
+		}
+	}
+}
+
+func TestExpandWildcardEntry(t *testing.T) {
+	disc := discoveryFixture()
+	tc := []struct {
+		name  string
+		entry configv1alpha1.SyncOnlyEntry
+		want  []schema.GroupVersionKind
+	}{
+		{
+			name:  "all kinds in a group",
+			entry: configv1alpha1.SyncOnlyEntry{Group: "apps", Version: "*", Kind: "*"},
+			want: []schema.GroupVersionKind{
+				{Group: "apps", Version: "v1", Kind: "Deployment"},
+				{Group: "apps", Version: "v1", Kind: "StatefulSet"},
+			},
+		},
+		{
+			name:  "kind across all groups",
+			entry: configv1alpha1.SyncOnlyEntry{Group: "*", Version: "*", Kind: "Pod"},
+			want:  []schema.GroupVersionKind{{Version: "v1", Kind: "Pod"}},
+		},
+		{
+			name:  "pinned version with wildcard kind",
+			entry: configv1alpha1.SyncOnlyEntry{Group: "", Version: "v1", Kind: "*"},
+			want: []schema.GroupVersionKind{
+				{Version: "v1", Kind: "Pod"},
+				{Version: "v1", Kind: "Service"},
+			},
+		},
+	}
+	for _, tt := range tc {
+		got, err := expandWildcardEntry(disc, tt.entry)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestExpandWildcardEntrySkipsUnwatchable(t *testing.T) {
+	got, err := expandWildcardEntry(discoveryFixture(), configv1alpha1.SyncOnlyEntry{Group: "", Version: "v1", Kind: "Binding"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("resources without list and watch verbs should be skipped, got %v", got)
+	}
+}
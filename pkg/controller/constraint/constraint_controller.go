@@ -29,8 +29,10 @@ import (
 	"github.com/open-policy-agent/gatekeeper/pkg/logging"
 	"github.com/open-policy-agent/gatekeeper/pkg/metrics"
 	"github.com/open-policy-agent/gatekeeper/pkg/mutation"
+	"github.com/open-policy-agent/gatekeeper/pkg/opashard"
 	"github.com/open-policy-agent/gatekeeper/pkg/readiness"
 	"github.com/open-policy-agent/gatekeeper/pkg/util"
+	"github.com/open-policy-agent/gatekeeper/pkg/verdictcache"
 	"github.com/open-policy-agent/gatekeeper/pkg/watch"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -57,7 +59,7 @@ const (
 )
 
 type Adder struct {
-	Opa              *opa.Client
+	Opa              opashard.Client
 	ConstraintsCache *ConstraintsCache
 	WatchManager     *watch.Manager
 	ControllerSwitch *watch.ControllerSwitch
@@ -67,7 +69,7 @@ type Adder struct {
 	ProcessExcluder  *process.Excluder
 }
 
-func (a *Adder) InjectOpa(o *opa.Client) {
+func (a *Adder) InjectOpa(o opashard.Client) {
 	a.Opa = o
 }
 
@@ -114,7 +116,7 @@ type tags struct {
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(
 	mgr manager.Manager,
-	opa *opa.Client,
+	opa opashard.Client,
 	cs *watch.ControllerSwitch,
 	reporter StatsReporter,
 	constraintsCache *ConstraintsCache,
@@ -178,7 +180,7 @@ type ReconcileConstraint struct {
 
 	cs               *watch.ControllerSwitch
 	scheme           *runtime.Scheme
-	opa              *opa.Client
+	opa              opashard.Client
 	log              logr.Logger
 	reporter         StatsReporter
 	constraintsCache *ConstraintsCache
@@ -295,6 +297,7 @@ func (r *ReconcileConstraint) Reconcile(ctx context.Context, request reconcile.R
 				return reconcile.Result{}, err
 			}
 		}
+		verdictcache.InvalidateAll()
 		logRemoval(r.log, instance, enforcementAction)
 
 		// cancel expectations
@@ -391,6 +394,7 @@ func (r *ReconcileConstraint) cacheConstraint(instance *unstructured.Unstructure
 		t.TryCancelExpect(obj)
 		return err
 	}
+	verdictcache.InvalidateAll()
 
 	// Track for readiness
 	t.Observe(instance)
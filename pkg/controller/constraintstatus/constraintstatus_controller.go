@@ -22,9 +22,11 @@ import (
 	"sort"
 
 	"github.com/go-logr/logr"
-	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
 	"github.com/open-policy-agent/gatekeeper/apis/status/v1beta1"
 	"github.com/open-policy-agent/gatekeeper/pkg/logging"
+	"github.com/open-policy-agent/gatekeeper/pkg/opashard"
+	"github.com/open-policy-agent/gatekeeper/pkg/statusbatch"
+	"github.com/open-policy-agent/gatekeeper/pkg/statusshard"
 	"github.com/open-policy-agent/gatekeeper/pkg/util"
 	"github.com/open-policy-agent/gatekeeper/pkg/watch"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -46,7 +48,7 @@ var (
 )
 
 type Adder struct {
-	Opa              *opa.Client
+	Opa              opashard.Client
 	WatchManager     *watch.Manager
 	ControllerSwitch *watch.ControllerSwitch
 	Events           <-chan event.GenericEvent
@@ -167,6 +169,11 @@ func (r *ReconcileConstraintStatus) Reconcile(ctx context.Context, request recon
 		return reconcile.Result{}, nil
 	}
 
+	// With status sharding, leave constraints owned by other status pods alone.
+	if !statusshard.Mine(unpackedRequest.Name) {
+		return reconcile.Result{}, nil
+	}
+
 	instance := &unstructured.Unstructured{}
 	instance.SetGroupVersionKind(gvk)
 	if err := r.reader.Get(ctx, unpackedRequest.NamespacedName, instance); err != nil {
@@ -218,8 +225,10 @@ func (r *ReconcileConstraintStatus) Reconcile(ctx context.Context, request recon
 		return reconcile.Result{}, err
 	}
 
-	if err = r.statusClient.Status().Update(context.Background(), instance); err != nil {
-		return reconcile.Result{Requeue: true}, nil
+	if !statusbatch.Enqueue(instance) {
+		if err = r.statusClient.Status().Update(context.Background(), instance); err != nil {
+			return reconcile.Result{Requeue: true}, nil
+		}
 	}
 
 	return reconcile.Result{}, nil
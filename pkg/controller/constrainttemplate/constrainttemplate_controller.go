@@ -17,12 +17,12 @@ package constrainttemplate
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"reflect"
 	"time"
 
 	"github.com/open-policy-agent/frameworks/constraint/pkg/apis/templates/v1beta1"
-	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
 	"github.com/open-policy-agent/frameworks/constraint/pkg/core/templates"
 	statusv1beta1 "github.com/open-policy-agent/gatekeeper/apis/status/v1beta1"
 	"github.com/open-policy-agent/gatekeeper/pkg/controller/constraint"
@@ -31,9 +31,11 @@ import (
 	"github.com/open-policy-agent/gatekeeper/pkg/logging"
 	"github.com/open-policy-agent/gatekeeper/pkg/metrics"
 	"github.com/open-policy-agent/gatekeeper/pkg/mutation"
+	"github.com/open-policy-agent/gatekeeper/pkg/opashard"
 	"github.com/open-policy-agent/gatekeeper/pkg/operations"
 	"github.com/open-policy-agent/gatekeeper/pkg/readiness"
 	"github.com/open-policy-agent/gatekeeper/pkg/util"
+	"github.com/open-policy-agent/gatekeeper/pkg/verdictcache"
 	"github.com/open-policy-agent/gatekeeper/pkg/watch"
 	"github.com/open-policy-agent/opa/ast"
 	errorpkg "github.com/pkg/errors"
@@ -59,6 +61,8 @@ const (
 	ctrlName      = "constrainttemplate-controller"
 )
 
+var maxCompileWorkers = flag.Int("max-template-compile-workers", 1, "(alpha) number of constraint templates compiled concurrently. Values above 1 let clusters with many templates become ready faster at the cost of additional CPU during startup")
+
 var log = logf.Log.WithName("controller").WithValues("kind", "ConstraintTemplate", logging.Process, "constraint_template_controller")
 
 var gvkConstraintTemplate = schema.GroupVersionKind{
@@ -68,7 +72,7 @@ var gvkConstraintTemplate = schema.GroupVersionKind{
 }
 
 type Adder struct {
-	Opa              *opa.Client
+	Opa              opashard.Client
 	WatchManager     *watch.Manager
 	ControllerSwitch *watch.ControllerSwitch
 	Tracker          *readiness.Tracker
@@ -87,7 +91,7 @@ func (a *Adder) Add(mgr manager.Manager) error {
 	return add(mgr, r)
 }
 
-func (a *Adder) InjectOpa(o *opa.Client) {
+func (a *Adder) InjectOpa(o opashard.Client) {
 	a.Opa = o
 }
 
@@ -113,7 +117,7 @@ func (a *Adder) InjectMutationCache(mutationCache *mutation.System) {}
 // cstrEvents is the channel from which constraint controller will receive the events
 // regEvents is the channel registered by Registrar to put the events in
 // cstrEvents and regEvents point to same event channel except for testing
-func newReconciler(mgr manager.Manager, opa *opa.Client, wm *watch.Manager, cs *watch.ControllerSwitch, tracker *readiness.Tracker, cstrEvents <-chan event.GenericEvent, regEvents chan<- event.GenericEvent, getPod func() (*corev1.Pod, error)) (*ReconcileConstraintTemplate, error) {
+func newReconciler(mgr manager.Manager, opa opashard.Client, wm *watch.Manager, cs *watch.ControllerSwitch, tracker *readiness.Tracker, cstrEvents <-chan event.GenericEvent, regEvents chan<- event.GenericEvent, getPod func() (*corev1.Pod, error)) (*ReconcileConstraintTemplate, error) {
 	// constraintsCache contains total number of constraints and shared mutex
 	constraintsCache := constraint.NewConstraintsCache()
 
@@ -187,8 +191,13 @@ func newReconciler(mgr manager.Manager, opa *opa.Client, wm *watch.Manager, cs *
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
 func add(mgr manager.Manager, r reconcile.Reconciler) error {
-	// Create a new controller
-	c, err := controller.New(ctrlName, mgr, controller.Options{Reconciler: r})
+	workers := *maxCompileWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	// Create a new controller. Template compilation is independent per
+	// template, so reconciles can fan out across a bounded worker pool.
+	c, err := controller.New(ctrlName, mgr, controller.Options{Reconciler: r, MaxConcurrentReconciles: workers})
 	if err != nil {
 		return err
 	}
@@ -231,7 +240,7 @@ type ReconcileConstraintTemplate struct {
 	scheme        *runtime.Scheme
 	watcher       *watch.Registrar
 	statusWatcher *watch.Registrar
-	opa           *opa.Client
+	opa           opashard.Client
 	cs            *watch.ControllerSwitch
 	metrics       *reporter
 	tracker       *readiness.Tracker
@@ -422,6 +431,7 @@ func (r *ReconcileConstraintTemplate) handleUpdate(
 		r.tracker.CancelTemplate(unversionedCT) // Don't track templates that failed compilation
 		return reconcile.Result{}, err
 	}
+	verdictcache.InvalidateAll()
 
 	if err := r.metrics.reportIngestDuration(metrics.ActiveStatus, time.Since(beginCompile)); err != nil {
 		log.Error(err, "failed to report constraint template ingestion duration")
@@ -485,6 +495,7 @@ func (r *ReconcileConstraintTemplate) handleDelete(
 	if _, err := r.opa.RemoveTemplate(context.Background(), ct); err != nil {
 		return reconcile.Result{}, err
 	}
+	verdictcache.InvalidateAll()
 	return reconcile.Result{}, nil
 }
 
@@ -23,9 +23,10 @@ import (
 
 	"github.com/go-logr/logr"
 	constrainttemplatev1beta1 "github.com/open-policy-agent/frameworks/constraint/pkg/apis/templates/v1beta1"
-	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
 	"github.com/open-policy-agent/gatekeeper/apis/status/v1beta1"
 	"github.com/open-policy-agent/gatekeeper/pkg/logging"
+	"github.com/open-policy-agent/gatekeeper/pkg/opashard"
+	"github.com/open-policy-agent/gatekeeper/pkg/statusshard"
 	"github.com/open-policy-agent/gatekeeper/pkg/util"
 	"github.com/open-policy-agent/gatekeeper/pkg/watch"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -46,7 +47,7 @@ var (
 )
 
 type Adder struct {
-	Opa              *opa.Client
+	Opa              opashard.Client
 	WatchManager     *watch.Manager
 	ControllerSwitch *watch.ControllerSwitch
 }
@@ -140,6 +141,12 @@ func (r *ReconcileConstraintStatus) Reconcile(ctx context.Context, request recon
 			return reconcile.Result{}, nil
 		}
 	}
+
+	// With status sharding, leave templates owned by other status pods alone.
+	if !statusshard.Mine(request.Name) {
+		return reconcile.Result{}, nil
+	}
+
 	template := &unstructured.Unstructured{}
 	gv := constrainttemplatev1beta1.SchemeGroupVersion
 	template.SetGroupVersionKind(gv.WithKind("ConstraintTemplate"))
@@ -22,11 +22,13 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/open-policy-agent/gatekeeper/pkg/auditdelta"
 	"github.com/open-policy-agent/gatekeeper/pkg/controller/config/process"
 	"github.com/open-policy-agent/gatekeeper/pkg/logging"
 	"github.com/open-policy-agent/gatekeeper/pkg/metrics"
 	"github.com/open-policy-agent/gatekeeper/pkg/readiness"
 	"github.com/open-policy-agent/gatekeeper/pkg/util"
+	"github.com/open-policy-agent/gatekeeper/pkg/verdictcache"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -173,6 +175,8 @@ func (r *ReconcileSync) Reconcile(ctx context.Context, request reconcile.Request
 			if _, err := r.opa.RemoveData(context.Background(), instance); err != nil {
 				return reconcile.Result{}, err
 			}
+			verdictcache.InvalidateAll()
+			auditdelta.RecordDeletion(instance)
 
 			// cancel expectations
 			t := r.tracker.ForData(instance.GroupVersionKind())
@@ -203,6 +207,8 @@ func (r *ReconcileSync) Reconcile(ctx context.Context, request reconcile.Request
 		if _, err := r.opa.RemoveData(context.Background(), instance); err != nil {
 			return reconcile.Result{}, err
 		}
+		verdictcache.InvalidateAll()
+		auditdelta.RecordDeletion(instance)
 
 		// cancel expectations
 		t := r.tracker.ForData(instance.GroupVersionKind())
@@ -230,6 +236,8 @@ func (r *ReconcileSync) Reconcile(ctx context.Context, request reconcile.Request
 
 		return reconcile.Result{}, err
 	}
+	verdictcache.InvalidateAll()
+	auditdelta.Record(instance)
 	r.tracker.ForData(gvk).Observe(instance)
 	log.V(1).Info("[readiness] observed data", "gvk", gvk, "namespace", instance.GetNamespace(), "name", instance.GetName())
 
@@ -0,0 +1,290 @@
+// Package databudget bounds the memory used by replicated policy data.
+// Synced resources are tracked with an approximate serialized size and
+// evicted least-recently-updated first once a configured budget is
+// exceeded, so Gatekeeper degrades gracefully instead of OOM-ing when
+// inventory grows unexpectedly. Evicted resources are refetched in the
+// background once the budget allows, and evictions/misses are exported
+// as metrics.
+package databudget
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"flag"
+	"sync"
+	"time"
+
+	"github.com/open-policy-agent/frameworks/constraint/pkg/types"
+	"github.com/open-policy-agent/gatekeeper/pkg/opashard"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrltypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("data-budget")
+
+const refetchPeriod = time.Minute
+
+var memoryBudget = flag.Int64("data-cache-memory-budget-bytes", 0, "(alpha) approximate upper bound in bytes for replicated policy data. Least-recently-updated resources are evicted once the budget is exceeded. 0 disables the budget")
+
+// Enabled returns true when a data memory budget was requested via
+// flags.
+func Enabled() bool {
+	return *memoryBudget > 0
+}
+
+// dataKey identifies a synced resource.
+type dataKey struct {
+	apiVersion string
+	kind       string
+	namespace  string
+	name       string
+}
+
+// entry tracks a synced resource's approximate size. skeleton keeps just
+// enough of the object to remove it from OPA and refetch it later.
+type entry struct {
+	key      dataKey
+	size     int64
+	skeleton *unstructured.Unstructured
+	element  *list.Element
+}
+
+// Client wraps an OPA client and enforces the data memory budget. It
+// implements opashard.Client.
+type Client struct {
+	opashard.Client
+
+	budget  int64
+	mux     sync.Mutex
+	total   int64
+	entries map[dataKey]*entry
+	order   *list.List // front = most recently updated
+	evicted map[dataKey]*unstructured.Unstructured
+}
+
+var _ opashard.Client = &Client{}
+
+// New wraps inner with the budget configured via flags.
+func New(inner opashard.Client) *Client {
+	return &Client{
+		Client:  inner,
+		budget:  *memoryBudget,
+		entries: make(map[dataKey]*entry),
+		order:   list.New(),
+		evicted: make(map[dataKey]*unstructured.Unstructured),
+	}
+}
+
+func keyFor(obj *unstructured.Unstructured) dataKey {
+	return dataKey{
+		apiVersion: obj.GetAPIVersion(),
+		kind:       obj.GetKind(),
+		namespace:  obj.GetNamespace(),
+		name:       obj.GetName(),
+	}
+}
+
+func skeletonFor(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	skeleton := &unstructured.Unstructured{}
+	skeleton.SetAPIVersion(obj.GetAPIVersion())
+	skeleton.SetKind(obj.GetKind())
+	skeleton.SetNamespace(obj.GetNamespace())
+	skeleton.SetName(obj.GetName())
+	return skeleton
+}
+
+// sizeOf approximates the memory held by a resource as the length of its
+// JSON serialization.
+func sizeOf(obj *unstructured.Unstructured) int64 {
+	b, err := json.Marshal(obj.Object)
+	if err != nil {
+		return 0
+	}
+	return int64(len(b))
+}
+
+// AddData tracks the resource against the budget and evicts the
+// least-recently-updated resources when it is exceeded.
+func (c *Client) AddData(ctx context.Context, data interface{}) (*types.Responses, error) {
+	obj := asUnstructured(data)
+	if obj == nil {
+		if _, ok := data.(target.WipeData); ok {
+			c.reset()
+		} else if _, ok := data.(*target.WipeData); ok {
+			c.reset()
+		}
+		return c.Client.AddData(ctx, data)
+	}
+
+	resp, err := c.Client.AddData(ctx, data)
+	if err != nil {
+		return resp, err
+	}
+
+	key := keyFor(obj)
+	size := sizeOf(obj)
+	c.mux.Lock()
+	if _, wasEvicted := c.evicted[key]; wasEvicted {
+		delete(c.evicted, key)
+		reportMiss()
+	}
+	if e, ok := c.entries[key]; ok {
+		c.total += size - e.size
+		e.size = size
+		e.skeleton = skeletonFor(obj)
+		c.order.MoveToFront(e.element)
+	} else {
+		e := &entry{key: key, size: size, skeleton: skeletonFor(obj)}
+		e.element = c.order.PushFront(e)
+		c.entries[key] = e
+		c.total += size
+	}
+	overflow := c.drainOverflowLocked()
+	c.mux.Unlock()
+
+	for _, skeleton := range overflow {
+		if _, err := c.Client.RemoveData(ctx, skeleton); err != nil {
+			log.Error(err, "unable to evict data", "kind", skeleton.GetKind(), "name", skeleton.GetName())
+			continue
+		}
+		reportEviction()
+		log.Info("evicted data over memory budget", "kind", skeleton.GetKind(), "namespace", skeleton.GetNamespace(), "name", skeleton.GetName())
+	}
+	return resp, nil
+}
+
+// RemoveData drops the resource from the budget accounting.
+func (c *Client) RemoveData(ctx context.Context, data interface{}) (*types.Responses, error) {
+	if obj := asUnstructured(data); obj != nil {
+		key := keyFor(obj)
+		c.mux.Lock()
+		if e, ok := c.entries[key]; ok {
+			c.order.Remove(e.element)
+			c.total -= e.size
+			delete(c.entries, key)
+		}
+		delete(c.evicted, key)
+		c.mux.Unlock()
+	} else if _, ok := data.(target.WipeData); ok {
+		c.reset()
+	} else if _, ok := data.(*target.WipeData); ok {
+		c.reset()
+	}
+	return c.Client.RemoveData(ctx, data)
+}
+
+// drainOverflowLocked pops least-recently-updated entries until the
+// budget is respected, returning their skeletons for removal. Callers
+// must hold the mutex.
+func (c *Client) drainOverflowLocked() []*unstructured.Unstructured {
+	var overflow []*unstructured.Unstructured
+	for c.total > c.budget && c.order.Len() > 1 {
+		back := c.order.Back()
+		e := back.Value.(*entry)
+		c.order.Remove(back)
+		c.total -= e.size
+		delete(c.entries, e.key)
+		c.evicted[e.key] = e.skeleton
+		overflow = append(overflow, e.skeleton)
+	}
+	return overflow
+}
+
+func (c *Client) reset() {
+	c.mux.Lock()
+	c.total = 0
+	c.entries = make(map[dataKey]*entry)
+	c.order = list.New()
+	c.evicted = make(map[dataKey]*unstructured.Unstructured)
+	c.mux.Unlock()
+}
+
+// evictedSkeletons returns a snapshot of the evicted resources.
+func (c *Client) evictedSkeletons() []*unstructured.Unstructured {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	out := make([]*unstructured.Unstructured, 0, len(c.evicted))
+	for _, skeleton := range c.evicted {
+		out = append(out, skeleton)
+	}
+	return out
+}
+
+func (c *Client) overBudget() bool {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.total >= c.budget
+}
+
+func asUnstructured(data interface{}) *unstructured.Unstructured {
+	switch obj := data.(type) {
+	case *unstructured.Unstructured:
+		return obj
+	case unstructured.Unstructured:
+		return &obj
+	}
+	return nil
+}
+
+// Refetcher re-adds evicted resources from the API server once the
+// budget allows. It implements manager.Runnable.
+type Refetcher struct {
+	client *Client
+	reader client.Reader
+}
+
+// NewRefetcher returns a Refetcher for the given budgeted client.
+func NewRefetcher(c *Client, reader client.Reader) *Refetcher {
+	return &Refetcher{client: c, reader: reader}
+}
+
+// Start implements manager.Runnable.
+func (r *Refetcher) Start(ctx context.Context) error {
+	ticker := time.NewTicker(refetchPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.refetch(ctx)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (r *Refetcher) refetch(ctx context.Context) {
+	for _, skeleton := range r.client.evictedSkeletons() {
+		if r.client.overBudget() {
+			return
+		}
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(skeleton.GetAPIVersion())
+		obj.SetKind(skeleton.GetKind())
+		err := r.reader.Get(ctx, ctrltypes.NamespacedName{Namespace: skeleton.GetNamespace(), Name: skeleton.GetName()}, obj)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				r.client.forget(skeleton)
+				continue
+			}
+			log.Error(err, "unable to refetch evicted data", "kind", skeleton.GetKind(), "name", skeleton.GetName())
+			continue
+		}
+		if _, err := r.client.AddData(ctx, obj); err != nil {
+			log.Error(err, "unable to re-add refetched data", "kind", obj.GetKind(), "name", obj.GetName())
+			continue
+		}
+		log.Info("refetched evicted data", "kind", obj.GetKind(), "namespace", obj.GetNamespace(), "name", obj.GetName())
+	}
+}
+
+// forget drops an evicted resource that no longer exists upstream.
+func (c *Client) forget(skeleton *unstructured.Unstructured) {
+	c.mux.Lock()
+	delete(c.evicted, keyFor(skeleton))
+	c.mux.Unlock()
+}
@@ -0,0 +1,127 @@
+package databudget
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/client/drivers/local"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func makeOpaClient() (*opa.Client, error) {
+	t := &target.K8sValidationTarget{}
+	driver := local.New(local.Tracing(false))
+	backend, err := opa.NewBackend(opa.Driver(driver))
+	if err != nil {
+		return nil, err
+	}
+	return backend.NewClient(opa.Targets(t))
+}
+
+func makeBudgetedClient(t *testing.T, budget int64) *Client {
+	inner, err := makeOpaClient()
+	if err != nil {
+		t.Fatalf("could not initialize OPA: %s", err)
+	}
+	c := New(inner)
+	c.budget = budget
+	return c
+}
+
+func makeConfigMap(name, payload string) *unstructured.Unstructured {
+	cm := &unstructured.Unstructured{}
+	cm.SetAPIVersion("v1")
+	cm.SetKind("ConfigMap")
+	cm.SetNamespace("default")
+	cm.SetName(name)
+	if err := unstructured.SetNestedField(cm.Object, payload, "data", "payload"); err != nil {
+		panic(err)
+	}
+	return cm
+}
+
+func TestAddDataTracksSize(t *testing.T) {
+	c := makeBudgetedClient(t, 1<<20)
+	if _, err := c.AddData(context.Background(), makeConfigMap("a", "x")); err != nil {
+		t.Fatalf("could not add data: %s", err)
+	}
+	if len(c.entries) != 1 {
+		t.Fatalf("expected 1 tracked entry, got %d", len(c.entries))
+	}
+	if c.total <= 0 {
+		t.Errorf("expected positive total size, got %d", c.total)
+	}
+}
+
+func TestAddDataEvictsOverBudget(t *testing.T) {
+	c := makeBudgetedClient(t, 400)
+	big := strings.Repeat("x", 300)
+	if _, err := c.AddData(context.Background(), makeConfigMap("first", big)); err != nil {
+		t.Fatalf("could not add data: %s", err)
+	}
+	if _, err := c.AddData(context.Background(), makeConfigMap("second", big)); err != nil {
+		t.Fatalf("could not add data: %s", err)
+	}
+	if len(c.evicted) != 1 {
+		t.Fatalf("expected 1 eviction, got %d", len(c.evicted))
+	}
+	if _, ok := c.entries[dataKey{apiVersion: "v1", kind: "ConfigMap", namespace: "default", name: "second"}]; !ok {
+		t.Errorf("most recently updated entry should survive eviction")
+	}
+	dump, err := c.Dump(context.Background())
+	if err != nil {
+		t.Fatalf("dump failed: %s", err)
+	}
+	if strings.Contains(dump, `"first"`) {
+		t.Errorf("evicted resource should have been removed from OPA")
+	}
+	if !strings.Contains(dump, `"second"`) {
+		t.Errorf("retained resource should still be in OPA")
+	}
+}
+
+func TestReaddClearsEvicted(t *testing.T) {
+	c := makeBudgetedClient(t, 400)
+	big := strings.Repeat("x", 300)
+	if _, err := c.AddData(context.Background(), makeConfigMap("first", big)); err != nil {
+		t.Fatalf("could not add data: %s", err)
+	}
+	if _, err := c.AddData(context.Background(), makeConfigMap("second", big)); err != nil {
+		t.Fatalf("could not add data: %s", err)
+	}
+	if _, err := c.AddData(context.Background(), makeConfigMap("first", "small")); err != nil {
+		t.Fatalf("could not re-add data: %s", err)
+	}
+	if _, ok := c.evicted[dataKey{apiVersion: "v1", kind: "ConfigMap", namespace: "default", name: "first"}]; ok {
+		t.Errorf("re-added resource should no longer be marked evicted")
+	}
+}
+
+func TestRemoveDataUpdatesAccounting(t *testing.T) {
+	c := makeBudgetedClient(t, 1<<20)
+	if _, err := c.AddData(context.Background(), makeConfigMap("a", "x")); err != nil {
+		t.Fatalf("could not add data: %s", err)
+	}
+	if _, err := c.RemoveData(context.Background(), makeConfigMap("a", "x")); err != nil {
+		t.Fatalf("could not remove data: %s", err)
+	}
+	if len(c.entries) != 0 || c.total != 0 {
+		t.Errorf("accounting should be empty after removal, got %d entries and total %d", len(c.entries), c.total)
+	}
+}
+
+func TestWipeDataResetsAccounting(t *testing.T) {
+	c := makeBudgetedClient(t, 1<<20)
+	if _, err := c.AddData(context.Background(), makeConfigMap("a", "x")); err != nil {
+		t.Fatalf("could not add data: %s", err)
+	}
+	if _, err := c.RemoveData(context.Background(), target.WipeData{}); err != nil {
+		t.Fatalf("could not wipe data: %s", err)
+	}
+	if len(c.entries) != 0 || c.total != 0 {
+		t.Errorf("accounting should be empty after wipe, got %d entries and total %d", len(c.entries), c.total)
+	}
+}
@@ -0,0 +1,62 @@
+package databudget
+
+import (
+	"context"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/metrics"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+)
+
+const (
+	evictionsMetricName = "data_cache_evictions_total"
+	missesMetricName    = "data_cache_misses_total"
+)
+
+var (
+	evictionsM = stats.Int64(
+		evictionsMetricName,
+		"Total number of resources evicted from the policy data cache over the memory budget",
+		stats.UnitDimensionless)
+
+	missesM = stats.Int64(
+		missesMetricName,
+		"Total number of times an evicted resource had to be re-added to the policy data cache",
+		stats.UnitDimensionless)
+)
+
+func init() {
+	if err := register(); err != nil {
+		panic(err)
+	}
+}
+
+func reportEviction() {
+	if err := metrics.Record(context.Background(), evictionsM.M(1)); err != nil {
+		log.Error(err, "unable to record data cache eviction")
+	}
+}
+
+func reportMiss() {
+	if err := metrics.Record(context.Background(), missesM.M(1)); err != nil {
+		log.Error(err, "unable to record data cache miss")
+	}
+}
+
+func register() error {
+	views := []*view.View{
+		{
+			Name:        evictionsMetricName,
+			Description: evictionsM.Description(),
+			Measure:     evictionsM,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        missesMetricName,
+			Description: missesM.Description(),
+			Measure:     missesM,
+			Aggregation: view.Sum(),
+		},
+	}
+	return view.Register(views...)
+}
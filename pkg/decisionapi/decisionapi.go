@@ -0,0 +1,152 @@
+// Package decisionapi exposes policy decisions to non-Kubernetes
+// callers. CI systems and internal services post an arbitrary JSON
+// document along with a declared kind; the document is wrapped into a
+// review object as that kind and evaluated against the cluster's loaded
+// constraints, returning the violations admission would raise. Combined
+// with --endpoint-auth-token-file the endpoint is safe to expose beyond
+// the cluster.
+package decisionapi
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"time"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/httpguard"
+	"github.com/open-policy-agent/gatekeeper/pkg/logging"
+	"github.com/open-policy-agent/gatekeeper/pkg/simulation"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("decision-api").WithValues(logging.Process, "decision_api")
+
+var (
+	enableDecisionAPI = flag.Bool("enable-decision-api", false, "(alpha) enable the HTTP policy decision endpoint for non-Kubernetes callers")
+	decisionAddr      = flag.String("decision-addr", ":8892", "The address the policy decision endpoint binds to.")
+)
+
+// Enabled returns true when the decision endpoint was requested via
+// flag.
+func Enabled() bool {
+	return *enableDecisionAPI
+}
+
+// Request is a decision request: an arbitrary JSON document plus the
+// kind context it should be reviewed as.
+type Request struct {
+	// Kind the document is evaluated as; constraints matching this kind
+	// apply. Required.
+	Kind string `json:"kind"`
+	// APIVersion the document is evaluated under. Defaults to v1.
+	APIVersion string `json:"apiVersion,omitempty"`
+	// Name identifies the document in violation messages. Defaults to
+	// "document".
+	Name string `json:"name,omitempty"`
+	// Namespace to evaluate the document in, if any.
+	Namespace string `json:"namespace,omitempty"`
+	// Document is the JSON body under review.
+	Document map[string]interface{} `json:"document"`
+}
+
+// Response reports the violations the document would raise.
+type Response struct {
+	Violations []simulation.Violation `json:"violations"`
+	// Denied is true when at least one violated constraint carries the
+	// deny enforcement action.
+	Denied bool `json:"denied"`
+}
+
+// Server serves decision requests. It implements manager.Runnable so it
+// can be registered with the controller manager like the other optional
+// servers.
+type Server struct {
+	simulator *simulation.Simulator
+	addr      string
+}
+
+// NewServer returns a Server configured from the decision flags.
+func NewServer(simulator *simulation.Simulator) *Server {
+	return &Server{simulator: simulator, addr: *decisionAddr}
+}
+
+// Start implements manager.Runnable.
+func (s *Server) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.Handle("/v1/decide", httpguard.Wrap(http.HandlerFunc(s.handleDecide)))
+	srv := &http.Server{Addr: s.addr, Handler: mux}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+// handleDecide wraps the document into a review object and evaluates
+// it.
+func (s *Server) handleDecide(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	decReq := &Request{}
+	if err := json.NewDecoder(req.Body).Decode(decReq); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if decReq.Kind == "" {
+		http.Error(w, "decision request must declare a kind", http.StatusBadRequest)
+		return
+	}
+	if decReq.Document == nil {
+		http.Error(w, "decision request must include a document", http.StatusBadRequest)
+		return
+	}
+
+	obj := wrap(decReq)
+	result, err := s.simulator.Simulate(req.Context(), &simulation.Request{Object: obj})
+	if err != nil {
+		log.Error(err, "unable to evaluate document", "kind", decReq.Kind, "name", obj.GetName())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(&Response{Violations: result.Violations, Denied: result.Denied}); err != nil {
+		log.Error(err, "unable to encode decision result")
+	}
+}
+
+// wrap builds the review object for a request, filling in the declared
+// kind context without clobbering fields the document already carries.
+func wrap(req *Request) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: req.Document}
+	obj.SetKind(req.Kind)
+	apiVersion := req.APIVersion
+	if apiVersion == "" {
+		apiVersion = "v1"
+	}
+	obj.SetAPIVersion(apiVersion)
+	if obj.GetName() == "" {
+		name := req.Name
+		if name == "" {
+			name = "document"
+		}
+		obj.SetName(name)
+	}
+	if req.Namespace != "" {
+		obj.SetNamespace(req.Namespace)
+	}
+	return obj
+}
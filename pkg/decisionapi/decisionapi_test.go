@@ -0,0 +1,86 @@
+package decisionapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/client/drivers/local"
+	"github.com/open-policy-agent/gatekeeper/pkg/mutation"
+	"github.com/open-policy-agent/gatekeeper/pkg/simulation"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+)
+
+func makeOpaClient() (*opa.Client, error) {
+	t := &target.K8sValidationTarget{}
+	driver := local.New(local.Tracing(false))
+	backend, err := opa.NewBackend(opa.Driver(driver))
+	if err != nil {
+		return nil, err
+	}
+	return backend.NewClient(opa.Targets(t))
+}
+
+func makeServer(t *testing.T) *Server {
+	t.Helper()
+	opaClient, err := makeOpaClient()
+	if err != nil {
+		t.Fatalf("could not initialize OPA: %s", err)
+	}
+	return &Server{simulator: simulation.New(opaClient, mutation.NewSystem(), nil)}
+}
+
+func post(t *testing.T, server *Server, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	server.handleDecide(rec, httptest.NewRequest(http.MethodPost, "/v1/decide", strings.NewReader(body)))
+	return rec
+}
+
+func TestHandleDecide(t *testing.T) {
+	server := makeServer(t)
+	rec := post(t, server, `{"kind": "BuildConfig", "document": {"spec": {"image": "nginx"}}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	response := &Response{}
+	if err := json.Unmarshal(rec.Body.Bytes(), response); err != nil {
+		t.Fatalf("parsing response: %s", err)
+	}
+	if response.Denied || len(response.Violations) != 0 {
+		t.Errorf("document with no constraints should be clean, got %+v", response)
+	}
+}
+
+func TestHandleDecideRejectsBadRequests(t *testing.T) {
+	server := makeServer(t)
+	if rec := post(t, server, `{"document": {"a": 1}}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("requests without a kind should be rejected, got %d", rec.Code)
+	}
+	if rec := post(t, server, `{"kind": "BuildConfig"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("requests without a document should be rejected, got %d", rec.Code)
+	}
+	if rec := post(t, server, `not json`); rec.Code != http.StatusBadRequest {
+		t.Errorf("malformed requests should be rejected, got %d", rec.Code)
+	}
+	rec := httptest.NewRecorder()
+	server.handleDecide(rec, httptest.NewRequest(http.MethodGet, "/v1/decide", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET should be rejected, got %d", rec.Code)
+	}
+}
+
+func TestWrapDefaults(t *testing.T) {
+	obj := wrap(&Request{Kind: "BuildConfig", Document: map[string]interface{}{"spec": map[string]interface{}{}}})
+	if obj.GetAPIVersion() != "v1" || obj.GetName() != "document" {
+		t.Errorf("wrap should default apiVersion and name, got %q/%q", obj.GetAPIVersion(), obj.GetName())
+	}
+	obj = wrap(&Request{Kind: "BuildConfig", Name: "pipeline-42", Namespace: "ci", APIVersion: "ci.example.com/v1",
+		Document: map[string]interface{}{}})
+	if obj.GetAPIVersion() != "ci.example.com/v1" || obj.GetName() != "pipeline-42" || obj.GetNamespace() != "ci" {
+		t.Errorf("wrap should honor declared context, got %v", obj)
+	}
+}
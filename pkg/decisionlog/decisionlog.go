@@ -0,0 +1,167 @@
+// Package decisionlog writes one structured JSON record per admission
+// review to a dedicated sink, separate from operational logs. Each
+// record carries the request UID so a decision can be correlated with
+// API server audit logs, and enough context — user, object, constraints
+// evaluated, outcome, latency — that auditors can replay why a request
+// was admitted or denied.
+package decisionlog
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	rtypes "github.com/open-policy-agent/frameworks/constraint/pkg/types"
+	"github.com/open-policy-agent/gatekeeper/pkg/logging"
+	admissionv1 "k8s.io/api/admission/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("decisionlog").WithValues(logging.Process, "decisionlog")
+
+var (
+	enableDecisionLogs = flag.Bool("enable-decision-logs", false, "(alpha) write a structured JSON record for every admission decision")
+	decisionLogPath    = flag.String("decision-log-path", "", "file the decision log is appended to; defaults to stdout so a sidecar can ship it")
+)
+
+// Enabled returns true when decision logging was requested via flag.
+func Enabled() bool {
+	return *enableDecisionLogs
+}
+
+// ConstraintDecision records one constraint's contribution to a decision.
+type ConstraintDecision struct {
+	Kind              string `json:"kind"`
+	Name              string `json:"name"`
+	EnforcementAction string `json:"enforcementAction"`
+	Message           string `json:"message,omitempty"`
+}
+
+// Record is the wire format of one decision log entry.
+type Record struct {
+	RequestUID  string               `json:"requestUID"`
+	Timestamp   string               `json:"timestamp"`
+	Username    string               `json:"username"`
+	Group       string               `json:"group,omitempty"`
+	Version     string               `json:"version"`
+	Kind        string               `json:"kind"`
+	Namespace   string               `json:"namespace,omitempty"`
+	Name        string               `json:"name,omitempty"`
+	Operation   string               `json:"operation"`
+	Decision    string               `json:"decision"`
+	Constraints []ConstraintDecision `json:"constraints,omitempty"`
+	Messages    []string             `json:"messages,omitempty"`
+	LatencyMS   int64                `json:"latencyMS"`
+}
+
+// New seeds a record from the admission request. The decision defaults
+// to "unknown" so a code path that forgets to mark its outcome is
+// visible in the log rather than silently recorded as an allow.
+func New(req *admissionv1.AdmissionRequest) *Record {
+	return &Record{
+		RequestUID: string(req.UID),
+		Timestamp:  time.Now().UTC().Format(time.RFC3339),
+		Username:   req.UserInfo.Username,
+		Group:      req.Kind.Group,
+		Version:    req.Kind.Version,
+		Kind:       req.Kind.Kind,
+		Namespace:  req.Namespace,
+		Name:       req.Name,
+		Operation:  string(req.Operation),
+		Decision:   "unknown",
+	}
+}
+
+// All mutators are no-ops on a nil receiver so call sites in the webhook
+// do not need to guard on whether decision logging is enabled.
+
+// Allow marks the request as admitted.
+func (r *Record) Allow() {
+	if r == nil {
+		return
+	}
+	r.Decision = "allow"
+}
+
+// Deny marks the request as rejected with the given messages.
+func (r *Record) Deny(msgs ...string) {
+	if r == nil {
+		return
+	}
+	r.Decision = "deny"
+	r.Messages = append(r.Messages, msgs...)
+}
+
+// Error marks the request as failed rather than decided.
+func (r *Record) Error(msg string) {
+	if r == nil {
+		return
+	}
+	r.Decision = "error"
+	r.Messages = append(r.Messages, msg)
+}
+
+// Evaluated records the constraints that produced results for this
+// request after exemptions and overrides were applied.
+func (r *Record) Evaluated(res []*rtypes.Result) {
+	if r == nil {
+		return
+	}
+	for _, result := range res {
+		cd := ConstraintDecision{
+			EnforcementAction: result.EnforcementAction,
+			Message:           result.Msg,
+		}
+		if result.Constraint != nil {
+			cd.Kind = result.Constraint.GetKind()
+			cd.Name = result.Constraint.GetName()
+		}
+		r.Constraints = append(r.Constraints, cd)
+	}
+}
+
+// Emit writes the record to the decision log sink. A sink failure is
+// reported to the operational log but never affects the admission
+// response.
+func (r *Record) Emit(latency time.Duration) {
+	if r == nil {
+		return
+	}
+	r.LatencyMS = latency.Milliseconds()
+	if err := write(r); err != nil {
+		log.Error(err, "unable to write decision log record", "requestUID", r.RequestUID)
+	}
+}
+
+var (
+	sinkOnce sync.Once
+	sinkMux  sync.Mutex
+	sink     io.Writer
+	sinkErr  error
+)
+
+// write appends the record as one JSON line, serializing writers so
+// concurrent admission requests do not interleave records.
+func write(r *Record) error {
+	sinkOnce.Do(func() {
+		if *decisionLogPath == "" {
+			sink = os.Stdout
+			return
+		}
+		f, err := os.OpenFile(*decisionLogPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			sinkErr = err
+			return
+		}
+		sink = f
+	})
+	if sinkErr != nil {
+		return sinkErr
+	}
+	sinkMux.Lock()
+	defer sinkMux.Unlock()
+	return json.NewEncoder(sink).Encode(r)
+}
@@ -0,0 +1,72 @@
+package decisionlog
+
+import (
+	"encoding/json"
+	"testing"
+
+	rtypes "github.com/open-policy-agent/frameworks/constraint/pkg/types"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func sampleRequest() *admissionv1.AdmissionRequest {
+	return &admissionv1.AdmissionRequest{
+		UID:       types.UID("abc-123"),
+		Kind:      metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+		Namespace: "shipping",
+		Name:      "frontend",
+		Operation: admissionv1.Create,
+		UserInfo:  authenticationv1.UserInfo{Username: "jane"},
+	}
+}
+
+func TestRecordLifecycle(t *testing.T) {
+	r := New(sampleRequest())
+	if r.Decision != "unknown" {
+		t.Errorf("new record decision = %q, want unknown", r.Decision)
+	}
+	if r.RequestUID != "abc-123" || r.Username != "jane" || r.Kind != "Deployment" {
+		t.Errorf("record did not capture request identity: %+v", r)
+	}
+
+	constraint := &unstructured.Unstructured{}
+	constraint.SetKind("K8sRequiredLabels")
+	constraint.SetName("must-have-owner")
+	r.Evaluated([]*rtypes.Result{
+		{Constraint: constraint, EnforcementAction: "deny", Msg: "missing owner label"},
+	})
+	r.Deny("missing owner label")
+
+	if r.Decision != "deny" {
+		t.Errorf("decision = %q, want deny", r.Decision)
+	}
+	if len(r.Constraints) != 1 || r.Constraints[0].Name != "must-have-owner" {
+		t.Errorf("unexpected constraints: %+v", r.Constraints)
+	}
+
+	out, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("marshaling record: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(out, &decoded); err != nil {
+		t.Fatalf("record is not valid JSON: %v", err)
+	}
+	if decoded["requestUID"] != "abc-123" {
+		t.Errorf("serialized record lost the request UID: %s", out)
+	}
+}
+
+func TestRecordNilReceiver(t *testing.T) {
+	// call sites in the webhook run unguarded, so a nil record must be
+	// safe to use
+	var r *Record
+	r.Allow()
+	r.Deny("msg")
+	r.Error("msg")
+	r.Evaluated(nil)
+	r.Emit(0)
+}
@@ -0,0 +1,119 @@
+// Package degradation detects API server unavailability and signals it
+// without taking enforcement down. Admission is served from the
+// compiled policy and cached data already in memory, so an unreachable
+// API server does not stop the webhook from answering; what it does
+// stop is policy updates, sync watches, and status writes. The monitor
+// probes the API server on a cadence and, after a configurable grace
+// period of failures, reports the instance as degraded. The webhook
+// then attaches an explicit staleness warning to its responses so
+// callers know decisions may be based on last-known-good policy,
+// instead of the instance flapping readiness or silently failing open.
+package degradation
+
+import (
+	"context"
+	"flag"
+	"sync"
+	"time"
+
+	"github.com/open-policy-agent/frameworks/constraint/pkg/apis/templates/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("degradation-monitor")
+
+// probeTimeout bounds a single API server probe.
+const probeTimeout = 5 * time.Second
+
+var (
+	enableDegradedMode = flag.Bool("enable-degraded-mode", false, "(alpha) when the API server is unreachable, keep serving admission from the last-known-good policy and attach a staleness warning to responses")
+	probePeriod        = flag.Duration("degraded-probe-period", 10*time.Second, "how often to probe the API server for reachability when --enable-degraded-mode is set")
+	degradedAfter      = flag.Duration("degraded-after", 30*time.Second, "how long API server probes may fail before the instance reports itself degraded")
+)
+
+// Enabled returns true if degraded-mode signaling was requested via
+// flags.
+func Enabled() bool {
+	return *enableDegradedMode
+}
+
+// Lister lists resources from the API server. It is the probe's only
+// dependency, satisfied by the manager's API reader.
+type Lister interface {
+	List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error
+}
+
+// Monitor probes the API server and tracks whether the instance is
+// degraded. It implements manager.Runnable so it starts and stops with
+// the manager.
+type Monitor struct {
+	lister        Lister
+	probePeriod   time.Duration
+	degradedAfter time.Duration
+
+	mu          sync.RWMutex
+	lastSuccess time.Time
+	degraded    bool
+}
+
+// NewMonitor returns a Monitor configured from the degraded-mode flags.
+func NewMonitor(lister Lister) *Monitor {
+	return &Monitor{
+		lister:        lister,
+		probePeriod:   *probePeriod,
+		degradedAfter: *degradedAfter,
+		// count from startup so an instance that never reaches the API
+		// server still degrades after the grace period
+		lastSuccess: time.Now(),
+	}
+}
+
+// Start implements manager.Runnable.
+func (m *Monitor) Start(ctx context.Context) error {
+	m.probe(ctx)
+	ticker := time.NewTicker(m.probePeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			m.probe(ctx)
+		}
+	}
+}
+
+// probe performs one reachability check. The constraint template list
+// is used because every Gatekeeper deployment has RBAC for it and a
+// limit of one keeps the probe cheap.
+func (m *Monitor) probe(ctx context.Context) {
+	probeCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	list := &v1beta1.ConstraintTemplateList{}
+	err := m.lister.List(probeCtx, list, client.Limit(1))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		if !m.degraded && time.Since(m.lastSuccess) >= m.degradedAfter {
+			m.degraded = true
+			log.Info("entering degraded mode: API server unreachable, serving last-known-good policy",
+				"lastSuccess", m.lastSuccess, "error", err.Error())
+		}
+		return
+	}
+	if m.degraded {
+		log.Info("API server reachable again, leaving degraded mode")
+	}
+	m.degraded = false
+	m.lastSuccess = time.Now()
+}
+
+// Degraded reports whether the instance is degraded, and if so, when
+// the API server was last reachable.
+func (m *Monitor) Degraded() (time.Time, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lastSuccess, m.degraded
+}
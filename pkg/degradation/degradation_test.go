@@ -0,0 +1,62 @@
+package degradation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type fakeLister struct {
+	err error
+}
+
+func (f *fakeLister) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return f.err
+}
+
+func TestDegradesAfterGracePeriod(t *testing.T) {
+	lister := &fakeLister{err: errors.New("connection refused")}
+	m := &Monitor{
+		lister:        lister,
+		degradedAfter: 10 * time.Millisecond,
+		lastSuccess:   time.Now(),
+	}
+
+	m.probe(context.Background())
+	if _, degraded := m.Degraded(); degraded {
+		t.Fatal("should not degrade before the grace period has passed")
+	}
+
+	m.lastSuccess = time.Now().Add(-time.Minute)
+	m.probe(context.Background())
+	since, degraded := m.Degraded()
+	if !degraded {
+		t.Fatal("should degrade once probes have failed past the grace period")
+	}
+	if time.Since(since) < time.Minute {
+		t.Errorf("degraded-since should report the last successful probe, got %s", since)
+	}
+}
+
+func TestRecoversWhenProbesSucceed(t *testing.T) {
+	lister := &fakeLister{err: errors.New("connection refused")}
+	m := &Monitor{
+		lister:        lister,
+		degradedAfter: 0,
+		lastSuccess:   time.Now().Add(-time.Minute),
+	}
+
+	m.probe(context.Background())
+	if _, degraded := m.Degraded(); !degraded {
+		t.Fatal("expected the monitor to be degraded")
+	}
+
+	lister.err = nil
+	m.probe(context.Background())
+	if _, degraded := m.Degraded(); degraded {
+		t.Fatal("expected the monitor to recover after a successful probe")
+	}
+}
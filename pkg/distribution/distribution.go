@@ -0,0 +1,363 @@
+// Package distribution rolls out templates and constraints from a hub
+// cluster to registered spoke clusters. PolicyPlacements on the hub
+// select SpokeClusters by label and reference the hub policies to
+// distribute; each targeted spoke receives labeled copies, optionally
+// adjusted per cluster (for example auditing in production while denying
+// in staging), and previously distributed objects that fall out of a
+// placement are pruned. Rollout state is aggregated back onto each
+// placement's status, one entry per spoke.
+package distribution
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+
+	distributionv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/distribution/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+var log = logf.Log.WithName("distribution")
+
+// PlacementLabel records which PolicyPlacement a distributed object came
+// from, so pruning never touches a spoke's own policy.
+const PlacementLabel = "gatekeeper.sh/policy-placement"
+
+// kubeconfigKey is the Secret key holding a spoke's kubeconfig.
+const kubeconfigKey = "kubeconfig"
+
+// resyncPeriod is how often placements are reconciled against their
+// spokes.
+const resyncPeriod = time.Minute
+
+var enableDistribution = flag.Bool("enable-policy-distribution", false, "(alpha) act as a hub: distribute templates and constraints to the spoke clusters selected by PolicyPlacement resources")
+
+// Enabled returns true if hub-spoke policy distribution was requested
+// via flags.
+func Enabled() bool {
+	return *enableDistribution
+}
+
+// +kubebuilder:rbac:groups=distribution.gatekeeper.sh,resources=policyplacements;spokeclusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups=distribution.gatekeeper.sh,resources=policyplacements/status,verbs=get;update;patch
+
+// Distributor reconciles spoke clusters with the hub's placements. It
+// implements manager.Runnable.
+type Distributor struct {
+	client      client.Client
+	gkNamespace string
+	// newSpokeClient builds a client for a spoke from its kubeconfig;
+	// overridable in tests.
+	newSpokeClient func(kubeconfig []byte) (client.Client, error)
+}
+
+// AddDistributor registers the distributor with the manager.
+func AddDistributor(mgr manager.Manager, gkNamespace string) error {
+	return mgr.Add(&Distributor{
+		client:         mgr.GetClient(),
+		gkNamespace:    gkNamespace,
+		newSpokeClient: defaultSpokeClient,
+	})
+}
+
+func defaultSpokeClient(kubeconfig []byte) (client.Client, error) {
+	cfg, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	return client.New(cfg, client.Options{})
+}
+
+// Start implements manager.Runnable.
+func (d *Distributor) Start(ctx context.Context) error {
+	ticker := time.NewTicker(resyncPeriod)
+	defer ticker.Stop()
+	for {
+		d.syncAll(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// syncAll reconciles every placement against its selected spokes.
+func (d *Distributor) syncAll(ctx context.Context) {
+	placements := &distributionv1alpha1.PolicyPlacementList{}
+	if err := d.client.List(ctx, placements); err != nil {
+		log.Error(err, "unable to list policy placements")
+		return
+	}
+	spokes := &distributionv1alpha1.SpokeClusterList{}
+	if err := d.client.List(ctx, spokes); err != nil {
+		log.Error(err, "unable to list spoke clusters")
+		return
+	}
+	for i := range placements.Items {
+		placement := &placements.Items[i]
+		if err := d.syncPlacement(ctx, placement, spokes.Items); err != nil {
+			log.Error(err, "unable to sync policy placement", "placement", placement.GetName())
+		}
+	}
+}
+
+// syncPlacement distributes one placement's policies to its selected
+// spokes and records the per-cluster outcome on the placement status.
+func (d *Distributor) syncPlacement(ctx context.Context, placement *distributionv1alpha1.PolicyPlacement, spokes []distributionv1alpha1.SpokeCluster) error {
+	targets, err := MatchingClusters(placement, spokes)
+	if err != nil {
+		return err
+	}
+
+	objects, err := d.resolvePolicies(ctx, placement)
+	if err != nil {
+		return err
+	}
+
+	previous := map[string]distributionv1alpha1.ClusterPlacementStatus{}
+	for _, cs := range placement.Status.Clusters {
+		previous[cs.Cluster] = cs
+	}
+	var statuses []distributionv1alpha1.ClusterPlacementStatus
+	for i := range targets {
+		spoke := &targets[i]
+		status := distributionv1alpha1.ClusterPlacementStatus{Cluster: spoke.GetName()}
+		count, err := d.syncSpoke(ctx, placement, spoke, objects)
+		if err != nil {
+			log.Error(err, "unable to sync spoke", "placement", placement.GetName(), "cluster", spoke.GetName())
+			status.LastError = err.Error()
+			// keep the last successful sync time so operators can see how
+			// stale the spoke is
+			if prev, ok := previous[spoke.GetName()]; ok {
+				status.LastSyncTime = prev.LastSyncTime
+				status.SyncedObjects = prev.SyncedObjects
+			}
+		} else {
+			status.Synced = true
+			status.SyncedObjects = int64(count)
+			status.LastSyncTime = metav1.Now()
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Cluster < statuses[j].Cluster })
+	return d.updateStatus(ctx, placement, statuses)
+}
+
+// resolvePolicies fetches the hub objects a placement references.
+func (d *Distributor) resolvePolicies(ctx context.Context, placement *distributionv1alpha1.PolicyPlacement) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	for _, ref := range placement.Spec.Policies {
+		gvk, err := RefGroupVersionKind(ref)
+		if err != nil {
+			return nil, err
+		}
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		if err := d.client.Get(ctx, client.ObjectKey{Name: ref.Name}, obj); err != nil {
+			return nil, fmt.Errorf("resolving %s %s: %w", ref.Kind, ref.Name, err)
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+// syncSpoke applies the placement's objects to one spoke and prunes
+// distributed objects that fell out of the placement.
+func (d *Distributor) syncSpoke(ctx context.Context, placement *distributionv1alpha1.PolicyPlacement, spoke *distributionv1alpha1.SpokeCluster, objects []*unstructured.Unstructured) (int, error) {
+	spokeClient, err := d.spokeClientFor(ctx, spoke)
+	if err != nil {
+		return 0, err
+	}
+
+	want := map[objectKey]bool{}
+	constraintKinds := map[schema.GroupVersionKind]bool{}
+	for _, hubObj := range objects {
+		obj := PrepareForCluster(hubObj, placement, spoke.GetName())
+		if err := apply(ctx, spokeClient, placement.GetName(), obj); err != nil {
+			return 0, err
+		}
+		want[keyOf(obj)] = true
+		if gvk := obj.GroupVersionKind(); gvk.Group == constraintsGroup {
+			gvk.Kind += "List"
+			constraintKinds[gvk] = true
+		}
+	}
+	if err := prune(ctx, spokeClient, placement.GetName(), want, constraintKinds); err != nil {
+		return 0, err
+	}
+	return len(objects), nil
+}
+
+// spokeClientFor builds a client for the spoke from its registered
+// kubeconfig secret.
+func (d *Distributor) spokeClientFor(ctx context.Context, spoke *distributionv1alpha1.SpokeCluster) (client.Client, error) {
+	if spoke.Spec.KubeconfigSecretRef == "" {
+		return nil, fmt.Errorf("spoke cluster %s does not reference a kubeconfig secret", spoke.GetName())
+	}
+	secret := &corev1.Secret{}
+	if err := d.client.Get(ctx, types.NamespacedName{Namespace: d.gkNamespace, Name: spoke.Spec.KubeconfigSecretRef}, secret); err != nil {
+		return nil, fmt.Errorf("reading kubeconfig for spoke cluster %s: %w", spoke.GetName(), err)
+	}
+	kubeconfig, found := secret.Data[kubeconfigKey]
+	if !found {
+		return nil, fmt.Errorf("kubeconfig secret %s is missing the %q key", spoke.Spec.KubeconfigSecretRef, kubeconfigKey)
+	}
+	return d.newSpokeClient(kubeconfig)
+}
+
+// MatchingClusters returns the registered spokes a placement selects.
+func MatchingClusters(placement *distributionv1alpha1.PolicyPlacement, spokes []distributionv1alpha1.SpokeCluster) ([]distributionv1alpha1.SpokeCluster, error) {
+	if placement.Spec.ClusterSelector == nil {
+		return spokes, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(placement.Spec.ClusterSelector)
+	if err != nil {
+		return nil, fmt.Errorf("parsing cluster selector of placement %s: %w", placement.GetName(), err)
+	}
+	var out []distributionv1alpha1.SpokeCluster
+	for i := range spokes {
+		if selector.Matches(labels.Set(spokes[i].GetLabels())) {
+			out = append(out, spokes[i])
+		}
+	}
+	return out, nil
+}
+
+const (
+	templatesGroup   = "templates.gatekeeper.sh"
+	constraintsGroup = "constraints.gatekeeper.sh"
+)
+
+// RefGroupVersionKind resolves a policy reference to its GVK, defaulting
+// the apiVersion from the kind.
+func RefGroupVersionKind(ref distributionv1alpha1.PolicyRef) (schema.GroupVersionKind, error) {
+	if ref.Kind == "" || ref.Name == "" {
+		return schema.GroupVersionKind{}, fmt.Errorf("policy references must set kind and name")
+	}
+	if ref.APIVersion == "" {
+		group := constraintsGroup
+		if ref.Kind == "ConstraintTemplate" {
+			group = templatesGroup
+		}
+		return schema.GroupVersionKind{Group: group, Version: "v1beta1", Kind: ref.Kind}, nil
+	}
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return schema.GroupVersionKind{}, fmt.Errorf("parsing apiVersion of policy reference %s: %w", ref.Name, err)
+	}
+	return gv.WithKind(ref.Kind), nil
+}
+
+// PrepareForCluster copies a hub object for one spoke: cluster-local
+// metadata is stripped, the placement label is attached, and any
+// per-cluster override is applied.
+func PrepareForCluster(hubObj *unstructured.Unstructured, placement *distributionv1alpha1.PolicyPlacement, cluster string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(hubObj.GroupVersionKind())
+	obj.SetName(hubObj.GetName())
+	if spec, found, _ := unstructured.NestedMap(hubObj.Object, "spec"); found {
+		obj.Object["spec"] = spec
+	}
+	labels := map[string]string{}
+	for k, v := range hubObj.GetLabels() {
+		labels[k] = v
+	}
+	labels[PlacementLabel] = placement.GetName()
+	obj.SetLabels(labels)
+	obj.SetAnnotations(hubObj.GetAnnotations())
+
+	if obj.GroupVersionKind().Group == constraintsGroup {
+		for _, o := range placement.Spec.Overrides {
+			if o.Cluster != cluster || o.EnforcementAction == "" {
+				continue
+			}
+			if err := unstructured.SetNestedField(obj.Object, o.EnforcementAction, "spec", "enforcementAction"); err != nil {
+				log.Error(err, "unable to apply enforcement override", "placement", placement.GetName(), "cluster", cluster)
+			}
+		}
+	}
+	return obj
+}
+
+// objectKey identifies one distributed object.
+type objectKey struct {
+	gvk  string
+	name string
+}
+
+func keyOf(obj *unstructured.Unstructured) objectKey {
+	return objectKey{gvk: obj.GroupVersionKind().String(), name: obj.GetName()}
+}
+
+// apply creates or updates one distributed object on a spoke, refusing
+// to overwrite objects the placement does not own.
+func apply(ctx context.Context, spokeClient client.Client, placementName string, obj *unstructured.Unstructured) error {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(obj.GroupVersionKind())
+	err := spokeClient.Get(ctx, client.ObjectKey{Name: obj.GetName()}, existing)
+	switch {
+	case apierrors.IsNotFound(err):
+		return spokeClient.Create(ctx, obj)
+	case err != nil:
+		return err
+	}
+	if existing.GetLabels()[PlacementLabel] != placementName {
+		return fmt.Errorf("%s %s already exists on the cluster and is not managed by this placement; refusing to overwrite",
+			obj.GetKind(), obj.GetName())
+	}
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	return spokeClient.Update(ctx, obj)
+}
+
+// prune deletes objects previously distributed by this placement that
+// are no longer part of it. Constraints of kinds no longer referenced
+// vanish with their template's CRD.
+func prune(ctx context.Context, spokeClient client.Client, placementName string, want map[objectKey]bool, constraintKinds map[schema.GroupVersionKind]bool) error {
+	listKinds := []schema.GroupVersionKind{{Group: templatesGroup, Version: "v1beta1", Kind: "ConstraintTemplateList"}}
+	for gvk := range constraintKinds {
+		listKinds = append(listKinds, gvk)
+	}
+	for _, gvk := range listKinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk)
+		if err := spokeClient.List(ctx, list, client.MatchingLabels{PlacementLabel: placementName}); err != nil {
+			continue
+		}
+		for i := range list.Items {
+			if want[keyOf(&list.Items[i])] {
+				continue
+			}
+			log.Info("pruning distributed object removed from placement", "kind", list.Items[i].GetKind(), "name", list.Items[i].GetName())
+			if err := spokeClient.Delete(ctx, &list.Items[i]); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// updateStatus records the per-cluster rollout state on the placement.
+func (d *Distributor) updateStatus(ctx context.Context, placement *distributionv1alpha1.PolicyPlacement, statuses []distributionv1alpha1.ClusterPlacementStatus) error {
+	if equality.Semantic.DeepEqual(placement.Status.Clusters, statuses) {
+		return nil
+	}
+	placement.Status.Clusters = statuses
+	if err := d.client.Status().Update(ctx, placement); err != nil {
+		return fmt.Errorf("updating status of placement %s: %w", placement.GetName(), err)
+	}
+	return nil
+}
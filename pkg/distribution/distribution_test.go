@@ -0,0 +1,126 @@
+package distribution
+
+import (
+	"testing"
+
+	distributionv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/distribution/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func spoke(name string, labels map[string]string) distributionv1alpha1.SpokeCluster {
+	s := distributionv1alpha1.SpokeCluster{}
+	s.SetName(name)
+	s.SetLabels(labels)
+	return s
+}
+
+func TestMatchingClusters(t *testing.T) {
+	spokes := []distributionv1alpha1.SpokeCluster{
+		spoke("prod-east", map[string]string{"env": "prod"}),
+		spoke("prod-west", map[string]string{"env": "prod"}),
+		spoke("staging", map[string]string{"env": "staging"}),
+	}
+
+	placement := &distributionv1alpha1.PolicyPlacement{}
+	matched, err := MatchingClusters(placement, spokes)
+	if err != nil {
+		t.Fatalf("matching without a selector: %s", err)
+	}
+	if len(matched) != 3 {
+		t.Errorf("placements without a selector should target every spoke, got %d", len(matched))
+	}
+
+	placement.Spec.ClusterSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}
+	matched, err = MatchingClusters(placement, spokes)
+	if err != nil {
+		t.Fatalf("matching with a selector: %s", err)
+	}
+	if len(matched) != 2 {
+		t.Fatalf("selector should match the two prod spokes, got %d", len(matched))
+	}
+	for _, s := range matched {
+		if s.GetLabels()["env"] != "prod" {
+			t.Errorf("unexpected spoke %s matched", s.GetName())
+		}
+	}
+}
+
+func TestRefGroupVersionKind(t *testing.T) {
+	gvk, err := RefGroupVersionKind(distributionv1alpha1.PolicyRef{Kind: "ConstraintTemplate", Name: "k8srequiredlabels"})
+	if err != nil {
+		t.Fatalf("resolving template ref: %s", err)
+	}
+	if gvk.Group != "templates.gatekeeper.sh" || gvk.Version != "v1beta1" {
+		t.Errorf("template refs should default to the templates group, got %v", gvk)
+	}
+
+	gvk, err = RefGroupVersionKind(distributionv1alpha1.PolicyRef{Kind: "K8sRequiredLabels", Name: "require-team"})
+	if err != nil {
+		t.Fatalf("resolving constraint ref: %s", err)
+	}
+	if gvk.Group != "constraints.gatekeeper.sh" || gvk.Kind != "K8sRequiredLabels" {
+		t.Errorf("constraint refs should default to the constraints group, got %v", gvk)
+	}
+
+	gvk, err = RefGroupVersionKind(distributionv1alpha1.PolicyRef{APIVersion: "templates.gatekeeper.sh/v1alpha1", Kind: "ConstraintTemplate", Name: "x"})
+	if err != nil {
+		t.Fatalf("resolving explicit ref: %s", err)
+	}
+	if gvk.Version != "v1alpha1" {
+		t.Errorf("explicit apiVersion should be honored, got %v", gvk)
+	}
+
+	if _, err := RefGroupVersionKind(distributionv1alpha1.PolicyRef{Name: "unnamed"}); err == nil {
+		t.Errorf("refs without a kind should be rejected")
+	}
+}
+
+func TestPrepareForCluster(t *testing.T) {
+	hubObj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "constraints.gatekeeper.sh/v1beta1",
+		"kind":       "K8sRequiredLabels",
+		"metadata": map[string]interface{}{
+			"name":            "require-team",
+			"resourceVersion": "42",
+			"uid":             "abc",
+			"labels":          map[string]interface{}{"team": "platform"},
+		},
+		"spec": map[string]interface{}{"enforcementAction": "deny"},
+	}}
+	placement := &distributionv1alpha1.PolicyPlacement{
+		Spec: distributionv1alpha1.PolicyPlacementSpec{
+			Overrides: []distributionv1alpha1.ClusterOverride{
+				{Cluster: "prod-east", EnforcementAction: "dryrun"},
+			},
+		},
+	}
+	placement.SetName("baseline")
+
+	obj := PrepareForCluster(hubObj, placement, "prod-east")
+	if obj.GetResourceVersion() != "" || obj.GetUID() != "" {
+		t.Errorf("cluster-local metadata should be stripped, got %v", obj.Object["metadata"])
+	}
+	if obj.GetLabels()[PlacementLabel] != "baseline" {
+		t.Errorf("distributed objects should carry the placement label, got %v", obj.GetLabels())
+	}
+	if obj.GetLabels()["team"] != "platform" {
+		t.Errorf("hub labels should be preserved, got %v", obj.GetLabels())
+	}
+	action, _, _ := unstructured.NestedString(obj.Object, "spec", "enforcementAction")
+	if action != "dryrun" {
+		t.Errorf("override should replace the enforcement action for its cluster, got %q", action)
+	}
+
+	obj = PrepareForCluster(hubObj, placement, "prod-west")
+	action, _, _ = unstructured.NestedString(obj.Object, "spec", "enforcementAction")
+	if action != "deny" {
+		t.Errorf("other clusters should keep the hub enforcement action, got %q", action)
+	}
+
+	// the hub object must not pick up the override
+	action, _, _ = unstructured.NestedString(hubObj.Object, "spec", "enforcementAction")
+	if action != "deny" {
+		t.Errorf("preparing a copy must not mutate the hub object, got %q", action)
+	}
+}
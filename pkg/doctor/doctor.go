@@ -0,0 +1,282 @@
+// Package doctor inspects a running Gatekeeper installation and
+// reports the misconfigurations that account for most support triage:
+// a webhook caBundle that does not match the serving certificate, an
+// expired or expiring certificate, templates that failed to compile,
+// referential policies with no synced data to reference, statuses no
+// pod has reported, and exemptions that have quietly expired. Each
+// check reads cluster state that is already there; nothing is changed.
+package doctor
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Severity classifies a finding. Errors describe installations that are
+// not enforcing what their operators believe; warnings describe state
+// that will get there if left alone.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is one actionable problem found in the installation.
+type Finding struct {
+	Severity Severity
+	Message  string
+}
+
+// certExpiryWarning is how close to expiry the serving certificate may
+// get before doctor warns about it.
+const certExpiryWarning = 30 * 24 * time.Hour
+
+const (
+	vwhName    = "gatekeeper-validating-webhook-configuration"
+	secretName = "gatekeeper-webhook-server-cert"
+)
+
+// Run executes every check against the cluster and returns the
+// findings. namespace is where Gatekeeper is installed; it locates the
+// webhook serving cert secret. Optional pieces (mutation, exemptions)
+// whose CRDs are not installed are skipped, not reported.
+func Run(ctx context.Context, c client.Client, namespace string) ([]Finding, error) {
+	var findings []Finding
+
+	vwc := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+	err := c.Get(ctx, types.NamespacedName{Name: vwhName}, vwc)
+	switch {
+	case apierrors.IsNotFound(err):
+		findings = append(findings, Finding{SeverityError, fmt.Sprintf("ValidatingWebhookConfiguration %s not found; admission requests are not reaching Gatekeeper", vwhName)})
+	case err != nil:
+		return nil, fmt.Errorf("fetching webhook configuration: %w", err)
+	default:
+		findings = append(findings, checkWebhookConfiguration(vwc)...)
+		secret := &corev1.Secret{}
+		err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: secretName}, secret)
+		switch {
+		case apierrors.IsNotFound(err):
+			findings = append(findings, Finding{SeverityError, fmt.Sprintf("serving cert secret %s/%s not found; the webhook cannot terminate TLS", namespace, secretName)})
+		case err != nil:
+			return nil, fmt.Errorf("fetching serving cert secret: %w", err)
+		default:
+			findings = append(findings, checkServingCert(vwc, secret, time.Now())...)
+		}
+	}
+
+	templates, err := listOptional(ctx, c, schema.GroupVersionKind{Group: "templates.gatekeeper.sh", Version: "v1beta1", Kind: "ConstraintTemplateList"})
+	if err != nil {
+		return nil, fmt.Errorf("listing constraint templates: %w", err)
+	}
+	findings = append(findings, checkTemplates(templates)...)
+
+	configs, err := listOptional(ctx, c, schema.GroupVersionKind{Group: "config.gatekeeper.sh", Version: "v1alpha1", Kind: "ConfigList"})
+	if err != nil {
+		return nil, fmt.Errorf("listing configs: %w", err)
+	}
+	findings = append(findings, checkSyncData(templates, configs)...)
+
+	exemptions, err := listOptional(ctx, c, schema.GroupVersionKind{Group: "exemptions.gatekeeper.sh", Version: "v1alpha1", Kind: "ExemptionList"})
+	if err != nil {
+		return nil, fmt.Errorf("listing exemptions: %w", err)
+	}
+	findings = append(findings, checkExemptions(exemptions, time.Now())...)
+
+	return findings, nil
+}
+
+// listOptional lists a Gatekeeper kind, returning an empty slice when
+// its CRD is not installed.
+func listOptional(ctx context.Context, c client.Client, gvk schema.GroupVersionKind) ([]unstructured.Unstructured, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk)
+	if err := c.List(ctx, list); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// checkWebhookConfiguration flags webhook configurations that silently
+// fail to enforce: no webhooks registered, an empty caBundle, or a
+// failure policy that ignores webhook outages.
+func checkWebhookConfiguration(vwc *admissionregistrationv1.ValidatingWebhookConfiguration) []Finding {
+	var findings []Finding
+	if len(vwc.Webhooks) == 0 {
+		return append(findings, Finding{SeverityError, fmt.Sprintf("ValidatingWebhookConfiguration %s has no webhooks registered", vwc.GetName())})
+	}
+	for i := range vwc.Webhooks {
+		wh := &vwc.Webhooks[i]
+		if len(wh.ClientConfig.CABundle) == 0 {
+			findings = append(findings, Finding{SeverityError, fmt.Sprintf("webhook %s has an empty caBundle; the API server cannot trust the webhook and requests fail or are ignored", wh.Name)})
+		}
+		if wh.FailurePolicy != nil && *wh.FailurePolicy == admissionregistrationv1.Ignore {
+			findings = append(findings, Finding{SeverityWarning, fmt.Sprintf("webhook %s has failurePolicy Ignore; violations are admitted whenever the webhook is unavailable", wh.Name)})
+		}
+	}
+	return findings
+}
+
+// checkServingCert verifies the serving cert secret agrees with the
+// webhook configuration: the serving certificate parses, is not expired
+// or about to expire, and was issued by the CA the webhook advertises.
+func checkServingCert(vwc *admissionregistrationv1.ValidatingWebhookConfiguration, secret *corev1.Secret, now time.Time) []Finding {
+	var findings []Finding
+	cert, err := parseCert(secret.Data["tls.crt"])
+	if err != nil {
+		return append(findings, Finding{SeverityError, fmt.Sprintf("serving cert secret %s/%s: %v", secret.GetNamespace(), secret.GetName(), err)})
+	}
+	switch {
+	case now.After(cert.NotAfter):
+		findings = append(findings, Finding{SeverityError, fmt.Sprintf("serving certificate expired %s; the API server is rejecting the webhook's TLS handshake", cert.NotAfter.Format(time.RFC3339))})
+	case now.Add(certExpiryWarning).After(cert.NotAfter):
+		findings = append(findings, Finding{SeverityWarning, fmt.Sprintf("serving certificate expires %s; rotation should happen before then", cert.NotAfter.Format(time.RFC3339))})
+	}
+
+	caCert := secret.Data["ca.crt"]
+	for i := range vwc.Webhooks {
+		wh := &vwc.Webhooks[i]
+		if len(wh.ClientConfig.CABundle) == 0 || len(caCert) == 0 {
+			continue
+		}
+		if !verifiesAgainst(cert, wh.ClientConfig.CABundle) {
+			findings = append(findings, Finding{SeverityError, fmt.Sprintf("webhook %s caBundle does not verify the serving certificate; the cert was likely rotated without updating the webhook configuration", wh.Name)})
+		}
+	}
+	return findings
+}
+
+// parseCert decodes the first certificate in a PEM bundle.
+func parseCert(pemBytes []byte) (*x509.Certificate, error) {
+	if len(pemBytes) == 0 {
+		return nil, fmt.Errorf("tls.crt is missing or empty")
+	}
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, fmt.Errorf("tls.crt is not PEM-encoded")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing tls.crt: %w", err)
+	}
+	return cert, nil
+}
+
+// verifiesAgainst reports whether the certificate chains to a root in
+// the PEM bundle.
+func verifiesAgainst(cert *x509.Certificate, caBundle []byte) bool {
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caBundle) {
+		return false
+	}
+	_, err := cert.Verify(x509.VerifyOptions{Roots: roots, CurrentTime: cert.NotBefore.Add(time.Second)})
+	return err == nil
+}
+
+// checkTemplates flags templates whose status shows compile errors and
+// templates no pod has reported status for — the latter usually means
+// the controller is not running or cannot reach the template.
+func checkTemplates(templates []unstructured.Unstructured) []Finding {
+	var findings []Finding
+	for i := range templates {
+		t := &templates[i]
+		byPod, _, _ := unstructured.NestedSlice(t.Object, "status", "byPod")
+		if len(byPod) == 0 {
+			findings = append(findings, Finding{SeverityWarning, fmt.Sprintf("ConstraintTemplate %s has no status from any pod; constraints for it are not enforced", t.GetName())})
+			continue
+		}
+		for _, entry := range byPod {
+			pod, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			observed, _, _ := unstructured.NestedInt64(pod, "observedGeneration")
+			if observed != t.GetGeneration() {
+				id, _, _ := unstructured.NestedString(pod, "id")
+				findings = append(findings, Finding{SeverityWarning, fmt.Sprintf("ConstraintTemplate %s status from pod %s is stale (observed generation %d, current %d)", t.GetName(), id, observed, t.GetGeneration())})
+			}
+			errs, _, _ := unstructured.NestedSlice(pod, "errors")
+			for _, e := range errs {
+				errMap, ok := e.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				msg, _, _ := unstructured.NestedString(errMap, "message")
+				findings = append(findings, Finding{SeverityError, fmt.Sprintf("ConstraintTemplate %s failed to compile: %s", t.GetName(), msg)})
+			}
+		}
+	}
+	return findings
+}
+
+// checkSyncData flags referential templates — those whose Rego reads
+// data.inventory — when no Config syncs any data for them to reference.
+// Such templates compile fine and then allow everything.
+func checkSyncData(templates, configs []unstructured.Unstructured) []Finding {
+	var referential []string
+	for i := range templates {
+		t := &templates[i]
+		targets, _, _ := unstructured.NestedSlice(t.Object, "spec", "targets")
+		for _, target := range targets {
+			targetMap, ok := target.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			rego, _, _ := unstructured.NestedString(targetMap, "rego")
+			if strings.Contains(rego, "data.inventory") {
+				referential = append(referential, t.GetName())
+				break
+			}
+		}
+	}
+	if len(referential) == 0 {
+		return nil
+	}
+
+	for i := range configs {
+		syncOnly, _, _ := unstructured.NestedSlice(configs[i].Object, "spec", "sync", "syncOnly")
+		if len(syncOnly) > 0 {
+			return nil
+		}
+	}
+	return []Finding{{SeverityError, fmt.Sprintf("referential templates %s read data.inventory but no Config syncs any data; their constraints evaluate against an empty inventory", strings.Join(referential, ", "))}}
+}
+
+// checkExemptions flags exemptions that have expired — they read as
+// active in the cluster but no longer exempt anything — and exemptions
+// so broad they match everything.
+func checkExemptions(exemptions []unstructured.Unstructured, now time.Time) []Finding {
+	var findings []Finding
+	for i := range exemptions {
+		e := &exemptions[i]
+		expiresAt, found, _ := unstructured.NestedString(e.Object, "spec", "expiresAt")
+		if found {
+			if expiry, err := time.Parse(time.RFC3339, expiresAt); err == nil && now.After(expiry) {
+				findings = append(findings, Finding{SeverityWarning, fmt.Sprintf("Exemption %s expired %s and no longer exempts anything; delete or renew it", e.GetName(), expiry.Format(time.RFC3339))})
+				continue
+			}
+		}
+		constraints, _, _ := unstructured.NestedSlice(e.Object, "spec", "constraints")
+		match, _, _ := unstructured.NestedMap(e.Object, "spec", "match")
+		if len(constraints) == 0 && len(match) == 0 {
+			findings = append(findings, Finding{SeverityWarning, fmt.Sprintf("Exemption %s names no constraints and no match criteria; it exempts every resource from every constraint", e.GetName())})
+		}
+	}
+	return findings
+}
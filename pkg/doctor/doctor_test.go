@@ -0,0 +1,205 @@
+package doctor
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// selfSignedCert returns a PEM-encoded self-signed certificate valid
+// for the given duration; it serves as both CA bundle and serving cert
+// in tests.
+func selfSignedCert(t *testing.T, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "gatekeeper-webhook-service"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func hasFinding(findings []Finding, severity Severity, substr string) bool {
+	for _, f := range findings {
+		if f.Severity == severity && strings.Contains(f.Message, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCheckWebhookConfiguration(t *testing.T) {
+	ignore := admissionregistrationv1.Ignore
+	vwc := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: vwhName},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			{Name: "validation.gatekeeper.sh", FailurePolicy: &ignore},
+		},
+	}
+	findings := checkWebhookConfiguration(vwc)
+	if !hasFinding(findings, SeverityError, "empty caBundle") {
+		t.Errorf("expected an empty caBundle finding, got %v", findings)
+	}
+	if !hasFinding(findings, SeverityWarning, "failurePolicy Ignore") {
+		t.Errorf("expected a failurePolicy finding, got %v", findings)
+	}
+
+	vwc.Webhooks = nil
+	findings = checkWebhookConfiguration(vwc)
+	if !hasFinding(findings, SeverityError, "no webhooks registered") {
+		t.Errorf("expected a no-webhooks finding, got %v", findings)
+	}
+}
+
+func TestCheckServingCert(t *testing.T) {
+	certPEM := selfSignedCert(t, time.Now().Add(365*24*time.Hour))
+	otherCA := selfSignedCert(t, time.Now().Add(365*24*time.Hour))
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "gatekeeper-system", Name: secretName},
+		Data:       map[string][]byte{"tls.crt": certPEM, "ca.crt": certPEM},
+	}
+	vwc := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{{
+			Name:         "validation.gatekeeper.sh",
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{CABundle: certPEM},
+		}},
+	}
+
+	if findings := checkServingCert(vwc, secret, time.Now()); len(findings) != 0 {
+		t.Errorf("expected no findings for a healthy cert, got %v", findings)
+	}
+
+	// caBundle from a different CA cannot verify the serving cert
+	vwc.Webhooks[0].ClientConfig.CABundle = otherCA
+	if findings := checkServingCert(vwc, secret, time.Now()); !hasFinding(findings, SeverityError, "does not verify") {
+		t.Errorf("expected a caBundle mismatch finding, got %v", findings)
+	}
+	vwc.Webhooks[0].ClientConfig.CABundle = certPEM
+
+	// near-expiry warns, past-expiry errors
+	if findings := checkServingCert(vwc, secret, time.Now().Add(364*24*time.Hour)); !hasFinding(findings, SeverityWarning, "expires") {
+		t.Errorf("expected an expiring-soon finding, got %v", findings)
+	}
+	if findings := checkServingCert(vwc, secret, time.Now().Add(400*24*time.Hour)); !hasFinding(findings, SeverityError, "expired") {
+		t.Errorf("expected an expired finding, got %v", findings)
+	}
+
+	// a secret without tls.crt is itself the finding
+	secret.Data = nil
+	if findings := checkServingCert(vwc, secret, time.Now()); !hasFinding(findings, SeverityError, "tls.crt") {
+		t.Errorf("expected a missing tls.crt finding, got %v", findings)
+	}
+}
+
+func template(name string, generation int64, byPod []interface{}) unstructured.Unstructured {
+	t := unstructured.Unstructured{Object: map[string]interface{}{}}
+	t.SetName(name)
+	t.SetGeneration(generation)
+	if byPod != nil {
+		_ = unstructured.SetNestedSlice(t.Object, byPod, "status", "byPod")
+	}
+	return t
+}
+
+func TestCheckTemplates(t *testing.T) {
+	templates := []unstructured.Unstructured{
+		template("k8srequiredlabels", 2, []interface{}{
+			map[string]interface{}{"id": "pod-a", "observedGeneration": int64(2)},
+		}),
+		template("k8sbroken", 1, []interface{}{
+			map[string]interface{}{
+				"id": "pod-a", "observedGeneration": int64(1),
+				"errors": []interface{}{map[string]interface{}{"code": "rego_parse_error", "message": "unexpected eof"}},
+			},
+		}),
+		template("k8sstale", 3, []interface{}{
+			map[string]interface{}{"id": "pod-a", "observedGeneration": int64(1)},
+		}),
+		template("k8sunreported", 1, nil),
+	}
+	findings := checkTemplates(templates)
+	if !hasFinding(findings, SeverityError, "k8sbroken failed to compile: unexpected eof") {
+		t.Errorf("expected a compile error finding, got %v", findings)
+	}
+	if !hasFinding(findings, SeverityWarning, "k8sstale status from pod pod-a is stale") {
+		t.Errorf("expected a stale status finding, got %v", findings)
+	}
+	if !hasFinding(findings, SeverityWarning, "k8sunreported has no status from any pod") {
+		t.Errorf("expected a missing status finding, got %v", findings)
+	}
+	if hasFinding(findings, SeverityError, "k8srequiredlabels") || hasFinding(findings, SeverityWarning, "k8srequiredlabels") {
+		t.Errorf("the healthy template should produce no findings, got %v", findings)
+	}
+}
+
+func TestCheckSyncData(t *testing.T) {
+	referential := template("k8suniqueingress", 1, nil)
+	_ = unstructured.SetNestedSlice(referential.Object, []interface{}{
+		map[string]interface{}{"target": "admission.k8s.gatekeeper.sh", "rego": "violation[{\"msg\": msg}] { data.inventory.namespace[_] }"},
+	}, "spec", "targets")
+
+	findings := checkSyncData([]unstructured.Unstructured{referential}, nil)
+	if !hasFinding(findings, SeverityError, "no Config syncs any data") {
+		t.Errorf("expected a missing sync finding, got %v", findings)
+	}
+
+	config := unstructured.Unstructured{Object: map[string]interface{}{}}
+	config.SetName("config")
+	_ = unstructured.SetNestedSlice(config.Object, []interface{}{
+		map[string]interface{}{"group": "", "version": "v1", "kind": "Namespace"},
+	}, "spec", "sync", "syncOnly")
+	if findings := checkSyncData([]unstructured.Unstructured{referential}, []unstructured.Unstructured{config}); len(findings) != 0 {
+		t.Errorf("expected no findings when data is synced, got %v", findings)
+	}
+}
+
+func TestCheckExemptions(t *testing.T) {
+	now := time.Now()
+	expired := unstructured.Unstructured{Object: map[string]interface{}{}}
+	expired.SetName("old-migration")
+	_ = unstructured.SetNestedField(expired.Object, now.Add(-time.Hour).Format(time.RFC3339), "spec", "expiresAt")
+
+	unbounded := unstructured.Unstructured{Object: map[string]interface{}{}}
+	unbounded.SetName("exempt-all")
+	_ = unstructured.SetNestedField(unbounded.Object, now.Add(time.Hour).Format(time.RFC3339), "spec", "expiresAt")
+
+	scoped := unstructured.Unstructured{Object: map[string]interface{}{}}
+	scoped.SetName("kube-system")
+	_ = unstructured.SetNestedField(scoped.Object, now.Add(time.Hour).Format(time.RFC3339), "spec", "expiresAt")
+	_ = unstructured.SetNestedStringSlice(scoped.Object, []string{"kube-system"}, "spec", "match", "namespaces")
+
+	findings := checkExemptions([]unstructured.Unstructured{expired, unbounded, scoped}, now)
+	if !hasFinding(findings, SeverityWarning, "old-migration expired") {
+		t.Errorf("expected an expired exemption finding, got %v", findings)
+	}
+	if !hasFinding(findings, SeverityWarning, "exempt-all names no constraints and no match criteria") {
+		t.Errorf("expected an unbounded exemption finding, got %v", findings)
+	}
+	if hasFinding(findings, SeverityWarning, "kube-system") {
+		t.Errorf("the scoped exemption should produce no findings, got %v", findings)
+	}
+}
@@ -0,0 +1,144 @@
+// Package drift detects divergence between the policy objects installed
+// in the cluster and a declared desired bundle. It reports templates and
+// constraints that are missing, modified or extra relative to the bundle
+// via structured logs and metrics.
+package drift
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	templatesGroup   = "templates.gatekeeper.sh"
+	constraintsGroup = "constraints.gatekeeper.sh"
+)
+
+// Result is the outcome of comparing installed policy objects against the
+// desired bundle. Entries are of the form `<Kind>/<name>`.
+type Result struct {
+	// Missing objects are declared in the bundle but not installed.
+	Missing []string
+	// Modified objects are installed with a spec differing from the bundle.
+	Modified []string
+	// Extra objects are installed but not declared in the bundle. Only
+	// kinds that appear in the bundle are considered, since the bundle
+	// has no opinion about other kinds.
+	Extra []string
+}
+
+// Empty returns true when no drift was detected.
+func (r *Result) Empty() bool {
+	return len(r.Missing) == 0 && len(r.Modified) == 0 && len(r.Extra) == 0
+}
+
+// LoadBundle reads all YAML manifests under the given directory and
+// returns the constraint templates and constraints they declare. Other
+// objects in the bundle are ignored.
+func LoadBundle(dir string) ([]*unstructured.Unstructured, error) {
+	var out []*unstructured.Unstructured
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || (!strings.HasSuffix(path, ".yaml") && !strings.HasSuffix(path, ".yml")) {
+			return nil
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		objs, err := parseManifest(b)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %v", path, err)
+		}
+		out = append(out, objs...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func parseManifest(b []byte) ([]*unstructured.Unstructured, error) {
+	var out []*unstructured.Unstructured
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(b), 1024)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(obj); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		if obj.Object == nil {
+			continue
+		}
+		group := obj.GroupVersionKind().Group
+		if group != templatesGroup && group != constraintsGroup {
+			continue
+		}
+		out = append(out, obj)
+	}
+	return out, nil
+}
+
+// Diff compares the desired bundle against the installed objects.
+// Objects are keyed by kind and name; modification is judged on spec
+// only, so cluster-managed metadata and status do not register as drift.
+func Diff(desired, installed []*unstructured.Unstructured) *Result {
+	result := &Result{}
+	desiredByKey := make(map[string]*unstructured.Unstructured)
+	desiredKinds := make(map[string]bool)
+	for _, d := range desired {
+		desiredByKey[key(d)] = d
+		desiredKinds[d.GetKind()] = true
+	}
+	installedByKey := make(map[string]*unstructured.Unstructured)
+	for _, i := range installed {
+		installedByKey[key(i)] = i
+	}
+
+	for k, d := range desiredByKey {
+		current, ok := installedByKey[k]
+		if !ok {
+			result.Missing = append(result.Missing, k)
+			continue
+		}
+		if !apiequality.Semantic.DeepEqual(spec(d), spec(current)) {
+			result.Modified = append(result.Modified, k)
+		}
+	}
+	for k, i := range installedByKey {
+		if !desiredKinds[i.GetKind()] {
+			continue
+		}
+		if _, ok := desiredByKey[k]; !ok {
+			result.Extra = append(result.Extra, k)
+		}
+	}
+
+	sort.Strings(result.Missing)
+	sort.Strings(result.Modified)
+	sort.Strings(result.Extra)
+	return result
+}
+
+func key(obj *unstructured.Unstructured) string {
+	return obj.GetKind() + "/" + obj.GetName()
+}
+
+func spec(obj *unstructured.Unstructured) interface{} {
+	return obj.Object["spec"]
+}
@@ -0,0 +1,100 @@
+package drift
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func makeObject(kind, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	group := constraintsGroup
+	if kind == "ConstraintTemplate" {
+		group = templatesGroup
+	}
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": group + "/v1beta1",
+			"kind":       kind,
+			"metadata":   map[string]interface{}{"name": name},
+		},
+	}
+	if spec != nil {
+		obj.Object["spec"] = spec
+	}
+	return obj
+}
+
+func TestDiff(t *testing.T) {
+	desired := []*unstructured.Unstructured{
+		makeObject("ConstraintTemplate", "k8srequiredlabels", map[string]interface{}{"crd": "a"}),
+		makeObject("K8sRequiredLabels", "require-team", map[string]interface{}{"enforcementAction": "deny"}),
+		makeObject("K8sRequiredLabels", "require-owner", nil),
+	}
+	installed := []*unstructured.Unstructured{
+		makeObject("ConstraintTemplate", "k8srequiredlabels", map[string]interface{}{"crd": "b"}),
+		makeObject("K8sRequiredLabels", "require-team", map[string]interface{}{"enforcementAction": "deny"}),
+		makeObject("K8sRequiredLabels", "rogue-constraint", nil),
+	}
+
+	result := Diff(desired, installed)
+	if !reflect.DeepEqual(result.Missing, []string{"K8sRequiredLabels/require-owner"}) {
+		t.Errorf("unexpected missing set: %v", result.Missing)
+	}
+	if !reflect.DeepEqual(result.Modified, []string{"ConstraintTemplate/k8srequiredlabels"}) {
+		t.Errorf("unexpected modified set: %v", result.Modified)
+	}
+	if !reflect.DeepEqual(result.Extra, []string{"K8sRequiredLabels/rogue-constraint"}) {
+		t.Errorf("unexpected extra set: %v", result.Extra)
+	}
+}
+
+func TestDiffNoDrift(t *testing.T) {
+	objs := []*unstructured.Unstructured{
+		makeObject("K8sRequiredLabels", "require-team", map[string]interface{}{"enforcementAction": "deny"}),
+	}
+	if result := Diff(objs, objs); !result.Empty() {
+		t.Errorf("expected no drift, got %+v", result)
+	}
+}
+
+func TestLoadBundle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bundle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	manifest := `apiVersion: templates.gatekeeper.sh/v1beta1
+kind: ConstraintTemplate
+metadata:
+  name: k8srequiredlabels
+---
+apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sRequiredLabels
+metadata:
+  name: require-team
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unrelated
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "bundle.yaml"), []byte(manifest), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	objs, err := LoadBundle(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(objs) != 2 {
+		t.Fatalf("expected 2 policy objects, got %d", len(objs))
+	}
+	if objs[0].GetKind() != "ConstraintTemplate" || objs[1].GetKind() != "K8sRequiredLabels" {
+		t.Errorf("unexpected kinds: %s, %s", objs[0].GetKind(), objs[1].GetKind())
+	}
+}
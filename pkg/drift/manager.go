@@ -0,0 +1,118 @@
+package drift
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/logging"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("drift").WithValues(logging.Process, "drift_detection")
+
+const defaultDriftInterval = 300
+
+var (
+	policyBundlePath = flag.String("policy-bundle-path", "", "(alpha) path to a directory containing the desired policy bundle. When set, installed templates and constraints are periodically compared against the bundle and drift is reported")
+	driftInterval    = flag.Uint("drift-detection-interval", defaultDriftInterval, "interval in seconds between drift detection runs. defaulted to 300 secs if unspecified")
+)
+
+// Enabled returns true when drift detection was requested via flag.
+func Enabled() bool {
+	return *policyBundlePath != ""
+}
+
+// Manager periodically compares installed policy objects against the
+// desired bundle. It implements manager.Runnable.
+type Manager struct {
+	client   client.Client
+	reporter *reporter
+	path     string
+	interval time.Duration
+}
+
+// New returns a drift detection manager configured from flags.
+func New(c client.Client) (*Manager, error) {
+	reporter, err := newStatsReporter()
+	if err != nil {
+		return nil, err
+	}
+	return &Manager{
+		client:   c,
+		reporter: reporter,
+		path:     *policyBundlePath,
+		interval: time.Duration(*driftInterval) * time.Second,
+	}, nil
+}
+
+// Start implements manager.Runnable.
+func (m *Manager) Start(ctx context.Context) error {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		if err := m.detect(ctx); err != nil {
+			log.Error(err, "drift detection run failed")
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (m *Manager) detect(ctx context.Context) error {
+	desired, err := LoadBundle(m.path)
+	if err != nil {
+		return err
+	}
+	installed, err := m.listInstalled(ctx, desired)
+	if err != nil {
+		return err
+	}
+	result := Diff(desired, installed)
+	if err := m.reporter.reportDrift(result); err != nil {
+		log.Error(err, "failed to report drift metrics")
+	}
+	if result.Empty() {
+		log.V(logging.DebugLevel).Info("no drift detected", "bundle", m.path)
+		return nil
+	}
+	log.Info("policy drift detected",
+		"bundle", m.path,
+		"missing", result.Missing,
+		"modified", result.Modified,
+		"extra", result.Extra,
+	)
+	return nil
+}
+
+// listInstalled lists the installed counterparts of every kind declared
+// in the bundle.
+func (m *Manager) listInstalled(ctx context.Context, desired []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	seen := make(map[schema.GroupVersionKind]bool)
+	var out []*unstructured.Unstructured
+	for _, d := range desired {
+		gvk := d.GroupVersionKind()
+		if seen[gvk] {
+			continue
+		}
+		seen[gvk] = true
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := m.client.List(ctx, list); err != nil {
+			// the kind is not installed at all; its objects will register
+			// as missing
+			log.V(logging.DebugLevel).Info("unable to list installed objects", "gvk", gvk.String(), "error", err.Error())
+			continue
+		}
+		for i := range list.Items {
+			out = append(out, &list.Items[i])
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,66 @@
+package drift
+
+import (
+	"context"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/metrics"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+const driftMetricName = "policy_drift"
+
+var (
+	driftM = stats.Int64(driftMetricName, "Number of policy objects drifted from the desired bundle", stats.UnitDimensionless)
+
+	driftTypeKey = tag.MustNewKey("drift_type")
+)
+
+func init() {
+	if err := register(); err != nil {
+		panic(err)
+	}
+}
+
+func register() error {
+	views := []*view.View{
+		{
+			Name:        driftMetricName,
+			Measure:     driftM,
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{driftTypeKey},
+		},
+	}
+	return view.Register(views...)
+}
+
+// newStatsReporter creates a reporter for drift metrics
+func newStatsReporter() (*reporter, error) {
+	ctx, err := tag.New(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return &reporter{ctx: ctx}, nil
+}
+
+type reporter struct {
+	ctx context.Context
+}
+
+func (r *reporter) reportDrift(result *Result) error {
+	for driftType, count := range map[string]int{
+		"missing":  len(result.Missing),
+		"modified": len(result.Modified),
+		"extra":    len(result.Extra),
+	} {
+		ctx, err := tag.New(r.ctx, tag.Insert(driftTypeKey, driftType))
+		if err != nil {
+			return err
+		}
+		if err := metrics.Record(ctx, driftM.M(int64(count))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+// Package enginereview carries the review plumbing shared by the
+// auxiliary template engines (CEL and wasm): extracting the reviewed
+// object from the shapes the webhook and audit pass to Review, and
+// applying a constraint's match criteria with the Rego target matcher's
+// semantics. Constraints served by those engines never reach the Rego
+// matcher, so the criteria must be enforced here in full.
+package enginereview
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/open-policy-agent/frameworks/constraint/pkg/types"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Review is the admission state handed to an engine.
+type Review struct {
+	Object    map[string]interface{}
+	OldObject map[string]interface{}
+	Request   map[string]interface{}
+	GVK       schema.GroupVersionKind
+	Namespace string
+	// NamespaceLabels are the labels of the object's namespace when the
+	// caller provided it; NamespaceCached distinguishes an unlabeled
+	// namespace from an unknown one, which matters for namespaceSelector.
+	NamespaceLabels map[string]string
+	NamespaceCached bool
+}
+
+// Extract pulls the reviewed object from the shapes the webhook and
+// audit pass to Review.
+func Extract(obj interface{}) (*Review, bool) {
+	switch obj := obj.(type) {
+	case target.AugmentedUnstructured:
+		rev := &Review{
+			Object:    obj.Object.Object,
+			Request:   map[string]interface{}{"operation": ""},
+			GVK:       obj.Object.GroupVersionKind(),
+			Namespace: obj.Object.GetNamespace(),
+		}
+		if obj.Namespace != nil {
+			rev.NamespaceLabels = obj.Namespace.Labels
+			rev.NamespaceCached = true
+		}
+		return rev, true
+	case *target.AugmentedUnstructured:
+		return Extract(*obj)
+	case target.AugmentedReview:
+		rev, ok := requestReview(obj.AdmissionRequest)
+		if ok && obj.Namespace != nil {
+			rev.NamespaceLabels = obj.Namespace.Labels
+			rev.NamespaceCached = true
+		}
+		return rev, ok
+	case *target.AugmentedReview:
+		return Extract(*obj)
+	case admissionv1.AdmissionRequest:
+		return requestReview(&obj)
+	case *admissionv1.AdmissionRequest:
+		return requestReview(obj)
+	}
+	return nil, false
+}
+
+func requestReview(req *admissionv1.AdmissionRequest) (*Review, bool) {
+	if req == nil {
+		return nil, false
+	}
+	out := &Review{
+		Request: map[string]interface{}{
+			"operation": string(req.Operation),
+			"userInfo":  map[string]interface{}{"username": req.UserInfo.Username},
+		},
+		GVK:       schema.GroupVersionKind{Group: req.Kind.Group, Version: req.Kind.Version, Kind: req.Kind.Kind},
+		Namespace: req.Namespace,
+	}
+	if len(req.Object.Raw) > 0 {
+		if err := json.Unmarshal(req.Object.Raw, &out.Object); err != nil {
+			return nil, false
+		}
+	}
+	if len(req.OldObject.Raw) > 0 {
+		if err := json.Unmarshal(req.OldObject.Raw, &out.OldObject); err != nil {
+			return nil, false
+		}
+	}
+	if out.Object == nil {
+		out.Object = out.OldObject
+	}
+	if out.Object == nil {
+		return nil, false
+	}
+	return out, true
+}
+
+// EnforcementAction returns the constraint's enforcement action,
+// defaulting to deny.
+func EnforcementAction(constraint *unstructured.Unstructured) string {
+	action, _, _ := unstructured.NestedString(constraint.Object, "spec", "enforcementAction")
+	if action == "" {
+		action = "deny"
+	}
+	return action
+}
+
+// MatchFailure reports a constraint whose match criteria could not be
+// evaluated, so an unevaluable selector fails loudly instead of silently
+// changing the constraint's scope.
+func MatchFailure(constraint *unstructured.Unstructured, err error) *types.Result {
+	return &types.Result{
+		Msg:               fmt.Sprintf("constraint %s: %v", constraint.GetName(), err),
+		Constraint:        constraint,
+		EnforcementAction: EnforcementAction(constraint),
+	}
+}
+
+// Matches applies the constraint's full match criteria — kinds, scope,
+// namespaces, excludedNamespaces, labelSelector, and namespaceSelector —
+// mirroring the Rego target's matcher so engine constraints fire exactly
+// where their Rego counterparts would. It returns an error when a
+// criterion cannot be evaluated, such as a namespaceSelector against an
+// uncached namespace, mirroring the Rego matcher's autoreject.
+func Matches(constraint *unstructured.Unstructured, rev *Review) (bool, error) {
+	match, _, _ := unstructured.NestedMap(constraint.Object, "spec", "match")
+	if match == nil {
+		return true, nil
+	}
+	if kinds, found, _ := unstructured.NestedSlice(match, "kinds"); found && !matchesKinds(kinds, rev.GVK) {
+		return false, nil
+	}
+	if !matchesScope(match, rev) {
+		return false, nil
+	}
+	isNS := rev.GVK.Group == "" && rev.GVK.Kind == "Namespace"
+	// cluster-scoped objects other than namespaces always match the
+	// namespace criteria
+	if isNS || rev.Namespace != "" {
+		nsName := rev.Namespace
+		if isNS {
+			nsName, _, _ = unstructured.NestedString(rev.Object, "metadata", "name")
+		}
+		if namespaces, found, _ := unstructured.NestedStringSlice(match, "namespaces"); found && !containsMatch(namespaces, nsName) {
+			return false, nil
+		}
+		if excluded, _, _ := unstructured.NestedStringSlice(match, "excludedNamespaces"); containsMatch(excluded, nsName) {
+			return false, nil
+		}
+		if selector, found, _ := unstructured.NestedMap(match, "namespaceSelector"); found {
+			if ok, err := matchesNamespaceSelector(selector, rev, isNS); err != nil || !ok {
+				return false, err
+			}
+		}
+	}
+	if selector, found, _ := unstructured.NestedMap(match, "labelSelector"); found {
+		compiled, err := compileSelector(selector)
+		if err != nil {
+			return false, err
+		}
+		return anyLabelSelectorMatch(compiled, rev), nil
+	}
+	return true, nil
+}
+
+func matchesKinds(kinds []interface{}, gvk schema.GroupVersionKind) bool {
+	for _, k := range kinds {
+		kMap, ok := k.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		groups, _, _ := unstructured.NestedStringSlice(kMap, "apiGroups")
+		names, _, _ := unstructured.NestedStringSlice(kMap, "kinds")
+		if (len(groups) == 0 || containsMatch(groups, gvk.Group)) && (len(names) == 0 || containsMatch(names, gvk.Kind)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesScope applies the match's scope criterion: "*" matches
+// everything, "Namespaced" namespaced objects, "Cluster" cluster-scoped
+// ones.
+func matchesScope(match map[string]interface{}, rev *Review) bool {
+	scope, _, _ := unstructured.NestedString(match, "scope")
+	switch scope {
+	case "", "*":
+		return true
+	case "Namespaced":
+		return rev.Namespace != ""
+	case "Cluster":
+		return rev.Namespace == ""
+	}
+	return false
+}
+
+// matchesNamespaceSelector applies a namespaceSelector to the object's
+// namespace labels, or to the object's own labels when the object is
+// itself a namespace. A namespaced object whose namespace is not cached
+// cannot be evaluated and is an error.
+func matchesNamespaceSelector(selector map[string]interface{}, rev *Review, isNS bool) (bool, error) {
+	compiled, err := compileSelector(selector)
+	if err != nil {
+		return false, err
+	}
+	if isNS {
+		return anyLabelSelectorMatch(compiled, rev), nil
+	}
+	if !rev.NamespaceCached {
+		return false, fmt.Errorf("namespace %s is not cached; cannot evaluate namespaceSelector", rev.Namespace)
+	}
+	return compiled.Matches(labels.Set(rev.NamespaceLabels)), nil
+}
+
+// compileSelector turns an unstructured LabelSelector into a compiled
+// one.
+func compileSelector(selector map[string]interface{}) (labels.Selector, error) {
+	parsed := &metav1.LabelSelector{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(selector, parsed); err != nil {
+		return nil, fmt.Errorf("parsing label selector: %v", err)
+	}
+	compiled, err := metav1.LabelSelectorAsSelector(parsed)
+	if err != nil {
+		return nil, fmt.Errorf("parsing label selector: %v", err)
+	}
+	return compiled, nil
+}
+
+// anyLabelSelectorMatch matches the selector against the labels of the
+// object and, on updates and deletes, the old object; either matching
+// suffices, as in the Rego matcher.
+func anyLabelSelectorMatch(selector labels.Selector, rev *Review) bool {
+	sets := make([]labels.Set, 0, 2)
+	if rev.Object != nil {
+		sets = append(sets, objectLabels(rev.Object))
+	}
+	if rev.OldObject != nil {
+		sets = append(sets, objectLabels(rev.OldObject))
+	}
+	if len(sets) == 0 {
+		sets = append(sets, labels.Set{})
+	}
+	for _, s := range sets {
+		if selector.Matches(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func objectLabels(obj map[string]interface{}) labels.Set {
+	l, _, _ := unstructured.NestedStringMap(obj, "metadata", "labels")
+	return labels.Set(l)
+}
+
+// containsMatch reports whether the list contains s, honoring the "*"
+// wildcard and the "ns-*" prefix form used in match criteria.
+func containsMatch(list []string, s string) bool {
+	for _, item := range list {
+		if item == "*" || item == s {
+			return true
+		}
+		if strings.HasSuffix(item, "*") && strings.HasPrefix(s, strings.TrimSuffix(item, "*")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package enginereview
+
+import (
+	"testing"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func podGVK() schema.GroupVersionKind {
+	return schema.GroupVersionKind{Version: "v1", Kind: "Pod"}
+}
+
+func matchConstraint(match map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"match": match},
+	}}
+}
+
+func mustMatch(t *testing.T, constraint *unstructured.Unstructured, rev *Review, want bool, desc string) {
+	t.Helper()
+	got, err := Matches(constraint, rev)
+	if err != nil {
+		t.Fatalf("%s: unexpected error: %s", desc, err)
+	}
+	if got != want {
+		t.Errorf("%s: Matches() = %v, want %v", desc, got, want)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	pod := unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]interface{}{"name": "busybox", "namespace": "default"},
+	}}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "default",
+		Labels: map[string]string{"env": "prod"},
+	}}
+	rev, ok := Extract(target.AugmentedUnstructured{Object: pod, Namespace: ns})
+	if !ok {
+		t.Fatalf("an augmented unstructured should extract")
+	}
+	if rev.GVK != podGVK() || rev.Namespace != "default" {
+		t.Errorf("unexpected review: %+v", rev)
+	}
+	if !rev.NamespaceCached || rev.NamespaceLabels["env"] != "prod" {
+		t.Errorf("the provided namespace should be carried over, got %+v", rev)
+	}
+
+	req := &admissionv1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+		Namespace: "default",
+		Operation: admissionv1.Create,
+		Object:    runtime.RawExtension{Raw: []byte(`{"metadata":{"name":"busybox"}}`)},
+	}
+	rev, ok = Extract(target.AugmentedReview{AdmissionRequest: req, Namespace: ns})
+	if !ok {
+		t.Fatalf("an augmented review should extract")
+	}
+	if rev.Request["operation"] != "CREATE" || !rev.NamespaceCached {
+		t.Errorf("unexpected review: %+v", rev)
+	}
+
+	if _, ok := Extract("not a review"); ok {
+		t.Errorf("unrecognized shapes should not extract")
+	}
+}
+
+func TestMatches(t *testing.T) {
+	constraint := matchConstraint(map[string]interface{}{
+		"kinds": []interface{}{
+			map[string]interface{}{
+				"apiGroups": []interface{}{""},
+				"kinds":     []interface{}{"Pod"},
+			},
+		},
+		"excludedNamespaces": []interface{}{"kube-*"},
+	})
+	mustMatch(t, constraint, &Review{GVK: podGVK(), Namespace: "default"}, true, "a Pod in default")
+	mustMatch(t, constraint, &Review{GVK: podGVK(), Namespace: "kube-system"}, false, "a Pod in an excluded namespace")
+	service := &Review{GVK: podGVK(), Namespace: "default"}
+	service.GVK.Kind = "Service"
+	mustMatch(t, constraint, service, false, "a Service against a Pod-only constraint")
+}
+
+func TestMatchesScope(t *testing.T) {
+	namespaced := matchConstraint(map[string]interface{}{"scope": "Namespaced"})
+	cluster := matchConstraint(map[string]interface{}{"scope": "Cluster"})
+	pod := &Review{GVK: podGVK(), Namespace: "default"}
+	node := &Review{GVK: schema.GroupVersionKind{Version: "v1", Kind: "Node"}}
+	mustMatch(t, namespaced, pod, true, "a namespaced object against scope Namespaced")
+	mustMatch(t, namespaced, node, false, "a cluster object against scope Namespaced")
+	mustMatch(t, cluster, pod, false, "a namespaced object against scope Cluster")
+	mustMatch(t, cluster, node, true, "a cluster object against scope Cluster")
+}
+
+func TestMatchesLabelSelector(t *testing.T) {
+	constraint := matchConstraint(map[string]interface{}{
+		"labelSelector": map[string]interface{}{
+			"matchLabels": map[string]interface{}{"team": "ci"},
+		},
+	})
+	labeled := &Review{GVK: podGVK(), Namespace: "default", Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"labels": map[string]interface{}{"team": "ci"}},
+	}}
+	unlabeled := &Review{GVK: podGVK(), Namespace: "default", Object: map[string]interface{}{
+		"metadata": map[string]interface{}{},
+	}}
+	mustMatch(t, constraint, labeled, true, "an object carrying the selected label")
+	mustMatch(t, constraint, unlabeled, false, "an object without the selected label")
+
+	// on updates the old object's labels match too
+	updated := &Review{GVK: podGVK(), Namespace: "default",
+		Object:    unlabeled.Object,
+		OldObject: labeled.Object,
+	}
+	mustMatch(t, constraint, updated, true, "an update whose old object carried the label")
+}
+
+func TestMatchesNamespaceSelector(t *testing.T) {
+	constraint := matchConstraint(map[string]interface{}{
+		"namespaceSelector": map[string]interface{}{
+			"matchLabels": map[string]interface{}{"env": "prod"},
+		},
+	})
+	prod := &Review{GVK: podGVK(), Namespace: "payments", NamespaceLabels: map[string]string{"env": "prod"}, NamespaceCached: true}
+	dev := &Review{GVK: podGVK(), Namespace: "sandbox", NamespaceLabels: map[string]string{"env": "dev"}, NamespaceCached: true}
+	mustMatch(t, constraint, prod, true, "a Pod in a selected namespace")
+	mustMatch(t, constraint, dev, false, "a Pod in an unselected namespace")
+
+	// cluster-scoped objects always match namespace criteria
+	node := &Review{GVK: schema.GroupVersionKind{Version: "v1", Kind: "Node"}}
+	mustMatch(t, constraint, node, true, "a cluster object against a namespaceSelector")
+
+	// a namespace under review matches against its own labels
+	ns := &Review{GVK: schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "payments", "labels": map[string]interface{}{"env": "prod"}},
+	}}
+	mustMatch(t, constraint, ns, true, "a namespace carrying the selected label")
+
+	// an uncached namespace cannot be evaluated and fails loudly
+	uncached := &Review{GVK: podGVK(), Namespace: "payments"}
+	if _, err := Matches(constraint, uncached); err == nil {
+		t.Errorf("an uncached namespace should be an error, not a silent match")
+	}
+}
+
+func TestEnforcementAction(t *testing.T) {
+	warn := matchConstraint(nil)
+	_ = unstructured.SetNestedField(warn.Object, "warn", "spec", "enforcementAction")
+	if got := EnforcementAction(warn); got != "warn" {
+		t.Errorf("EnforcementAction() = %q, want warn", got)
+	}
+	if got := EnforcementAction(matchConstraint(nil)); got != "deny" {
+		t.Errorf("EnforcementAction() = %q, want the deny default", got)
+	}
+}
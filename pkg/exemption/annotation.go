@@ -0,0 +1,63 @@
+package exemption
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// AnnotationKey is the annotation through which a resource requests its
+// own exemption. The annotation is honored only while an Exemption with
+// allowAnnotations covering the resource is active.
+const AnnotationKey = "exemptions.gatekeeper.sh/exempt"
+
+// AnnotationExemption is the structured payload of the exemption
+// annotation.
+type AnnotationExemption struct {
+	// Constraints lists the constraint names the resource requests
+	// exemption from. Required; annotations cannot request blanket
+	// exemption.
+	Constraints []string `json:"constraints"`
+	// Justification records why the exemption was requested.
+	Justification string `json:"justification,omitempty"`
+	// Expiry is an RFC3339 timestamp after which the annotation is
+	// ignored. Required.
+	Expiry string `json:"expiry"`
+}
+
+// ParseAnnotation extracts the exemption annotation from an object, if
+// present. A malformed annotation is an error so that it is surfaced
+// rather than silently unenforced.
+func ParseAnnotation(obj *unstructured.Unstructured) (*AnnotationExemption, error) {
+	raw, ok := obj.GetAnnotations()[AnnotationKey]
+	if !ok {
+		return nil, nil
+	}
+	out := &AnnotationExemption{}
+	if err := json.Unmarshal([]byte(raw), out); err != nil {
+		return nil, fmt.Errorf("malformed %s annotation: %v", AnnotationKey, err)
+	}
+	if len(out.Constraints) == 0 {
+		return nil, fmt.Errorf("%s annotation must list the constraints it requests exemption from", AnnotationKey)
+	}
+	if out.Expiry == "" {
+		return nil, fmt.Errorf("%s annotation must declare an expiry", AnnotationKey)
+	}
+	if _, err := time.Parse(time.RFC3339, out.Expiry); err != nil {
+		return nil, fmt.Errorf("%s annotation expiry %q is not a valid RFC3339 timestamp: %v", AnnotationKey, out.Expiry, err)
+	}
+	return out, nil
+}
+
+// Covers returns true when the annotation requests exemption from the
+// given constraint and has not expired.
+func (a *AnnotationExemption) Covers(constraintName string, now metav1.Time) bool {
+	expiry, err := time.Parse(time.RFC3339, a.Expiry)
+	if err != nil || !now.Time.Before(expiry) {
+		return false
+	}
+	return contains(a.Constraints, constraintName)
+}
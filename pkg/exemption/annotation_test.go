@@ -0,0 +1,70 @@
+package exemption
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func makeAnnotated(raw string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name": "busybox",
+			"annotations": map[string]interface{}{
+				AnnotationKey: raw,
+			},
+		},
+	}}
+}
+
+func TestParseAnnotation(t *testing.T) {
+	parsed, err := ParseAnnotation(makeAnnotated(`{"constraints":["require-team"],"justification":"migration","expiry":"2030-01-01T00:00:00Z"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed == nil || len(parsed.Constraints) != 1 || parsed.Justification != "migration" {
+		t.Errorf("unexpected parse result: %+v", parsed)
+	}
+
+	parsed, err = ParseAnnotation(&unstructured.Unstructured{Object: map[string]interface{}{}})
+	if err != nil || parsed != nil {
+		t.Errorf("object without annotation should parse to nil, got %+v, %v", parsed, err)
+	}
+
+	if _, err := ParseAnnotation(makeAnnotated(`not-json`)); err == nil {
+		t.Errorf("expected error on malformed annotation")
+	}
+	if _, err := ParseAnnotation(makeAnnotated(`{"constraints":[],"expiry":"2030-01-01T00:00:00Z"}`)); err == nil {
+		t.Errorf("expected error when no constraints are listed")
+	}
+	if _, err := ParseAnnotation(makeAnnotated(`{"constraints":["c"]}`)); err == nil {
+		t.Errorf("expected error when no expiry is declared")
+	}
+	if _, err := ParseAnnotation(makeAnnotated(`{"constraints":["c"],"expiry":"tomorrow"}`)); err == nil {
+		t.Errorf("expected error on malformed expiry")
+	}
+}
+
+func TestAnnotationCovers(t *testing.T) {
+	now := metav1.Now()
+	active := &AnnotationExemption{
+		Constraints: []string{"require-team"},
+		Expiry:      now.Add(time.Hour).Format(time.RFC3339),
+	}
+	if !active.Covers("require-team", now) {
+		t.Errorf("annotation should cover a listed constraint before expiry")
+	}
+	if active.Covers("other-constraint", now) {
+		t.Errorf("annotation should not cover an unlisted constraint")
+	}
+
+	expired := &AnnotationExemption{
+		Constraints: []string{"require-team"},
+		Expiry:      now.Add(-time.Hour).Format(time.RFC3339),
+	}
+	if expired.Covers("require-team", now) {
+		t.Errorf("expired annotation should not cover anything")
+	}
+}
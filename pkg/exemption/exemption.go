@@ -0,0 +1,129 @@
+// Package exemption evaluates Exemption resources, which grant scoped,
+// time-limited bypasses for specific constraints and resources.
+package exemption
+
+import (
+	"fmt"
+	"strings"
+
+	exemptionsv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/exemptions/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Target identifies the resource and constraint a violation pertains
+// to, and the requester behind it, so exemptions can be evaluated
+// against it.
+type Target struct {
+	ConstraintKind string
+	ConstraintName string
+	Kind           string
+	Namespace      string
+	Name           string
+	Username       string
+	Groups         []string
+}
+
+// FindExemption returns the first active exemption directly covering the
+// target, or nil when none applies. Expired exemptions never apply, and
+// exemptions that merely authorize annotations do not bypass directly.
+func FindExemption(exemptions []exemptionsv1alpha1.Exemption, target Target, now metav1.Time) *exemptionsv1alpha1.Exemption {
+	for i := range exemptions {
+		if exemptions[i].Spec.AllowAnnotations {
+			continue
+		}
+		if covers(&exemptions[i], target, now) {
+			return &exemptions[i]
+		}
+	}
+	return nil
+}
+
+// FindAnnotationAuthorizer returns the first active exemption that
+// authorizes annotation-based exemption for the target, or nil when
+// annotation exemptions are not permitted for it.
+func FindAnnotationAuthorizer(exemptions []exemptionsv1alpha1.Exemption, target Target, now metav1.Time) *exemptionsv1alpha1.Exemption {
+	for i := range exemptions {
+		if !exemptions[i].Spec.AllowAnnotations {
+			continue
+		}
+		if covers(&exemptions[i], target, now) {
+			return &exemptions[i]
+		}
+	}
+	return nil
+}
+
+func covers(exemption *exemptionsv1alpha1.Exemption, target Target, now metav1.Time) bool {
+	if exemption.Expired(now) {
+		return false
+	}
+	if !matchesConstraint(exemption.Spec.Constraints, target) {
+		return false
+	}
+	match := exemption.Spec.Match
+	if len(match.Namespaces) > 0 && !contains(match.Namespaces, target.Namespace) {
+		return false
+	}
+	if len(match.Kinds) > 0 && !contains(match.Kinds, target.Kind) {
+		return false
+	}
+	if len(match.Names) > 0 && !contains(match.Names, target.Name) {
+		return false
+	}
+	if !matchesSubject(match, target) {
+		return false
+	}
+	return true
+}
+
+// matchesSubject reports whether the requester is covered by the
+// match's user, group, and service account lists. The lists are
+// alternatives: matching any one entry is enough. When all three are
+// empty the exemption is not subject-scoped.
+func matchesSubject(match exemptionsv1alpha1.ExemptionMatch, target Target) bool {
+	if len(match.Users) == 0 && len(match.Groups) == 0 && len(match.ServiceAccounts) == 0 {
+		return true
+	}
+	if contains(match.Users, target.Username) {
+		return true
+	}
+	for _, group := range target.Groups {
+		if contains(match.Groups, group) {
+			return true
+		}
+	}
+	for _, sa := range match.ServiceAccounts {
+		parts := strings.SplitN(sa, "/", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if target.Username == fmt.Sprintf("system:serviceaccount:%s:%s", parts[0], parts[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesConstraint(refs []exemptionsv1alpha1.ConstraintReference, target Target) bool {
+	if len(refs) == 0 {
+		return true
+	}
+	for _, ref := range refs {
+		if ref.Kind != target.ConstraintKind {
+			continue
+		}
+		if ref.Name == "" || ref.Name == target.ConstraintName {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package exemption
+
+import (
+	"testing"
+	"time"
+
+	exemptionsv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/exemptions/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func makeExemption(name string, spec exemptionsv1alpha1.ExemptionSpec) exemptionsv1alpha1.Exemption {
+	return exemptionsv1alpha1.Exemption{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       spec,
+	}
+}
+
+func TestFindExemption(t *testing.T) {
+	now := metav1.Now()
+	future := metav1.NewTime(now.Add(time.Hour))
+	past := metav1.NewTime(now.Add(-time.Hour))
+
+	target := Target{
+		ConstraintKind: "K8sRequiredLabels",
+		ConstraintName: "require-team",
+		Kind:           "Pod",
+		Namespace:      "team-a",
+		Name:           "busybox",
+	}
+
+	tc := []struct {
+		name     string
+		spec     exemptionsv1alpha1.ExemptionSpec
+		expected bool
+	}{
+		{
+			name:     "matches everything until expiry",
+			spec:     exemptionsv1alpha1.ExemptionSpec{ExpiresAt: future},
+			expected: true,
+		},
+		{
+			name:     "expired exemption never applies",
+			spec:     exemptionsv1alpha1.ExemptionSpec{ExpiresAt: past},
+			expected: false,
+		},
+		{
+			name: "scoped to matching constraint kind",
+			spec: exemptionsv1alpha1.ExemptionSpec{
+				ExpiresAt:   future,
+				Constraints: []exemptionsv1alpha1.ConstraintReference{{Kind: "K8sRequiredLabels"}},
+			},
+			expected: true,
+		},
+		{
+			name: "scoped to different constraint name",
+			spec: exemptionsv1alpha1.ExemptionSpec{
+				ExpiresAt:   future,
+				Constraints: []exemptionsv1alpha1.ConstraintReference{{Kind: "K8sRequiredLabels", Name: "other"}},
+			},
+			expected: false,
+		},
+		{
+			name: "scoped to matching namespace and kind",
+			spec: exemptionsv1alpha1.ExemptionSpec{
+				ExpiresAt: future,
+				Match: exemptionsv1alpha1.ExemptionMatch{
+					Namespaces: []string{"team-a"},
+					Kinds:      []string{"Pod"},
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "scoped to different resource name",
+			spec: exemptionsv1alpha1.ExemptionSpec{
+				ExpiresAt: future,
+				Match:     exemptionsv1alpha1.ExemptionMatch{Names: []string{"nginx"}},
+			},
+			expected: false,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindExemption([]exemptionsv1alpha1.Exemption{makeExemption("e", tt.spec)}, target, now)
+			if (got != nil) != tt.expected {
+				t.Errorf("expected match=%v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMatchesSubject(t *testing.T) {
+	now := metav1.Now()
+	future := metav1.NewTime(now.Add(time.Hour))
+
+	target := Target{
+		ConstraintKind: "K8sRequiredLabels",
+		ConstraintName: "require-team",
+		Kind:           "Pod",
+		Namespace:      "team-a",
+		Name:           "busybox",
+		Username:       "system:serviceaccount:ci:deployer",
+		Groups:         []string{"system:serviceaccounts", "system:authenticated"},
+	}
+
+	tc := []struct {
+		name     string
+		match    exemptionsv1alpha1.ExemptionMatch
+		expected bool
+	}{
+		{
+			name:     "no subject scoping applies to everyone",
+			match:    exemptionsv1alpha1.ExemptionMatch{},
+			expected: true,
+		},
+		{
+			name:     "matching username",
+			match:    exemptionsv1alpha1.ExemptionMatch{Users: []string{"system:serviceaccount:ci:deployer"}},
+			expected: true,
+		},
+		{
+			name:     "non-matching username",
+			match:    exemptionsv1alpha1.ExemptionMatch{Users: []string{"alice@example.com"}},
+			expected: false,
+		},
+		{
+			name:     "matching group",
+			match:    exemptionsv1alpha1.ExemptionMatch{Groups: []string{"system:serviceaccounts"}},
+			expected: true,
+		},
+		{
+			name:     "matching service account by namespace/name",
+			match:    exemptionsv1alpha1.ExemptionMatch{ServiceAccounts: []string{"ci/deployer"}},
+			expected: true,
+		},
+		{
+			name:     "service account in a different namespace",
+			match:    exemptionsv1alpha1.ExemptionMatch{ServiceAccounts: []string{"prod/deployer"}},
+			expected: false,
+		},
+		{
+			name: "any matching subject field is sufficient",
+			match: exemptionsv1alpha1.ExemptionMatch{
+				Users:  []string{"alice@example.com"},
+				Groups: []string{"system:authenticated"},
+			},
+			expected: true,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			exemptions := []exemptionsv1alpha1.Exemption{
+				makeExemption("subject-scoped", exemptionsv1alpha1.ExemptionSpec{ExpiresAt: future, Match: tt.match}),
+			}
+			got := FindExemption(exemptions, target, now) != nil
+			if got != tt.expected {
+				t.Errorf("expected match=%v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package exemption
+
+import (
+	"context"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/metrics"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+const exemptionsMetricName = "exemptions_honored"
+
+var (
+	exemptionsM = stats.Int64(exemptionsMetricName, "Total number of honored exemptions", stats.UnitDimensionless)
+
+	sourceKey = tag.MustNewKey("source")
+)
+
+// exemption sources recorded on the honored-exemption metric
+const (
+	SourceCRD        = "exemption"
+	SourceAnnotation = "annotation"
+	SourceUserInfo   = "userinfo"
+)
+
+func init() {
+	if err := register(); err != nil {
+		panic(err)
+	}
+}
+
+func register() error {
+	views := []*view.View{
+		{
+			Name:        exemptionsMetricName,
+			Measure:     exemptionsM,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{sourceKey},
+		},
+	}
+	return view.Register(views...)
+}
+
+// ReportHonored records that an exemption from the given source was
+// honored.
+func ReportHonored(source string) error {
+	ctx, err := tag.New(context.Background(), tag.Insert(sourceKey, source))
+	if err != nil {
+		return err
+	}
+	return metrics.Record(ctx, exemptionsM.M(1))
+}
@@ -0,0 +1,154 @@
+package expansion
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultRuntimeClassAnnotation is a namespace annotation declaring the
+// runtime class Pods in that namespace get by default. Kubernetes has
+// no namespace-scoped runtime class default of its own, so operators
+// whose admission chain injects one can declare it here for expansion
+// to mirror.
+const DefaultRuntimeClassAnnotation = "expansion.gatekeeper.sh/default-runtime-class"
+
+// defaultServiceAccountName is what the API server assigns Pods that do
+// not name a service account.
+const defaultServiceAccountName = "default"
+
+// NamespaceDefaulter fills in the namespace-level defaults the API
+// server and its admission chain would apply to a real Pod — service
+// account, image pull secrets, runtime class, and LimitRange-derived
+// resources — so policy results for expanded Pods match what would be
+// reviewed at Pod creation. Defaulting is best-effort: anything that
+// cannot be resolved leaves the expanded Pod as-is.
+type NamespaceDefaulter struct {
+	client client.Client
+}
+
+// NewNamespaceDefaulter returns a defaulter reading namespace state
+// through the provided client.
+func NewNamespaceDefaulter(c client.Client) *NamespaceDefaulter {
+	return &NamespaceDefaulter{client: c}
+}
+
+// ApplyTo defaults the expanded resource in place. Only v1 Pods carry
+// namespace defaults; other generated kinds pass through untouched.
+func (d *NamespaceDefaulter) ApplyTo(ctx context.Context, resultant *unstructured.Unstructured) error {
+	if resultant.GetAPIVersion() != "v1" || resultant.GetKind() != "Pod" || resultant.GetNamespace() == "" {
+		return nil
+	}
+	namespace := resultant.GetNamespace()
+
+	applyServiceAccountName(resultant)
+
+	saName, _, _ := unstructured.NestedString(resultant.Object, "spec", "serviceAccountName")
+	sa := &corev1.ServiceAccount{}
+	err := d.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: saName}, sa)
+	switch {
+	case apierrors.IsNotFound(err):
+		// the service account may not exist yet; skip its defaults
+	case err != nil:
+		return fmt.Errorf("fetching service account %s/%s: %w", namespace, saName, err)
+	default:
+		applyImagePullSecrets(resultant, sa)
+	}
+
+	ns := &corev1.Namespace{}
+	if err := d.client.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return fmt.Errorf("fetching namespace %s: %w", namespace, err)
+	}
+	applyRuntimeClassDefault(resultant, ns.GetAnnotations())
+
+	limitRanges := &corev1.LimitRangeList{}
+	if err := d.client.List(ctx, limitRanges, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("listing limit ranges in %s: %w", namespace, err)
+	}
+	for _, lr := range limitRanges.Items {
+		applyLimitRangeDefaults(resultant, lr.Spec.Limits)
+	}
+	return nil
+}
+
+// applyServiceAccountName sets the default service account on Pods that
+// do not name one, matching API server behavior.
+func applyServiceAccountName(pod *unstructured.Unstructured) {
+	name, _, _ := unstructured.NestedString(pod.Object, "spec", "serviceAccountName")
+	if name != "" {
+		return
+	}
+	_ = unstructured.SetNestedField(pod.Object, defaultServiceAccountName, "spec", "serviceAccountName")
+}
+
+// applyImagePullSecrets copies the service account's image pull secrets
+// onto Pods that declare none, matching the service account admission
+// controller.
+func applyImagePullSecrets(pod *unstructured.Unstructured, sa *corev1.ServiceAccount) {
+	existing, _, _ := unstructured.NestedSlice(pod.Object, "spec", "imagePullSecrets")
+	if len(existing) > 0 || len(sa.ImagePullSecrets) == 0 {
+		return
+	}
+	secrets := make([]interface{}, 0, len(sa.ImagePullSecrets))
+	for _, ref := range sa.ImagePullSecrets {
+		secrets = append(secrets, map[string]interface{}{"name": ref.Name})
+	}
+	_ = unstructured.SetNestedSlice(pod.Object, secrets, "spec", "imagePullSecrets")
+}
+
+// applyRuntimeClassDefault sets the namespace's declared default
+// runtime class on Pods that do not name one.
+func applyRuntimeClassDefault(pod *unstructured.Unstructured, nsAnnotations map[string]string) {
+	runtimeClass := nsAnnotations[DefaultRuntimeClassAnnotation]
+	if runtimeClass == "" {
+		return
+	}
+	existing, _, _ := unstructured.NestedString(pod.Object, "spec", "runtimeClassName")
+	if existing != "" {
+		return
+	}
+	_ = unstructured.SetNestedField(pod.Object, runtimeClass, "spec", "runtimeClassName")
+}
+
+// applyLimitRangeDefaults fills container resource requests and limits
+// from the LimitRange's container defaults, matching the LimitRanger
+// admission controller.
+func applyLimitRangeDefaults(pod *unstructured.Unstructured, limits []corev1.LimitRangeItem) {
+	for _, item := range limits {
+		if item.Type != corev1.LimitTypeContainer {
+			continue
+		}
+		for _, field := range []string{"containers", "initContainers"} {
+			containers, found, _ := unstructured.NestedSlice(pod.Object, "spec", field)
+			if !found {
+				continue
+			}
+			for i, c := range containers {
+				container, ok := c.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				applyContainerDefaults(container, "limits", item.Default)
+				applyContainerDefaults(container, "requests", item.DefaultRequest)
+				containers[i] = container
+			}
+			_ = unstructured.SetNestedSlice(pod.Object, containers, "spec", field)
+		}
+	}
+}
+
+func applyContainerDefaults(container map[string]interface{}, section string, defaults corev1.ResourceList) {
+	for resourceName, quantity := range defaults {
+		key := string(resourceName)
+		existing, _, _ := unstructured.NestedString(container, "resources", section, key)
+		if existing != "" {
+			continue
+		}
+		_ = unstructured.SetNestedField(container, quantity.String(), "resources", section, key)
+	}
+}
@@ -0,0 +1,108 @@
+package expansion
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func minimalPod(t *testing.T) *unstructured.Unstructured {
+	t.Helper()
+	pod := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "main", "image": "nginx"},
+			},
+		},
+	}}
+	pod.SetAPIVersion("v1")
+	pod.SetKind("Pod")
+	pod.SetName("expanded")
+	pod.SetNamespace("prod")
+	return pod
+}
+
+func TestApplyServiceAccountName(t *testing.T) {
+	pod := minimalPod(t)
+	applyServiceAccountName(pod)
+	name, _, _ := unstructured.NestedString(pod.Object, "spec", "serviceAccountName")
+	if name != "default" {
+		t.Errorf("expected the default service account, got %q", name)
+	}
+
+	_ = unstructured.SetNestedField(pod.Object, "builder", "spec", "serviceAccountName")
+	applyServiceAccountName(pod)
+	name, _, _ = unstructured.NestedString(pod.Object, "spec", "serviceAccountName")
+	if name != "builder" {
+		t.Errorf("an explicit service account should be kept, got %q", name)
+	}
+}
+
+func TestApplyImagePullSecrets(t *testing.T) {
+	pod := minimalPod(t)
+	sa := &corev1.ServiceAccount{
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry-creds"}},
+	}
+	applyImagePullSecrets(pod, sa)
+	secrets, _, _ := unstructured.NestedSlice(pod.Object, "spec", "imagePullSecrets")
+	if len(secrets) != 1 {
+		t.Fatalf("expected one pull secret, got %v", secrets)
+	}
+	if name, _, _ := unstructured.NestedString(secrets[0].(map[string]interface{}), "name"); name != "registry-creds" {
+		t.Errorf("unexpected pull secret: %v", secrets[0])
+	}
+
+	// pods with their own pull secrets are left alone
+	_ = unstructured.SetNestedSlice(pod.Object, []interface{}{map[string]interface{}{"name": "own"}}, "spec", "imagePullSecrets")
+	applyImagePullSecrets(pod, sa)
+	secrets, _, _ = unstructured.NestedSlice(pod.Object, "spec", "imagePullSecrets")
+	if len(secrets) != 1 || secrets[0].(map[string]interface{})["name"] != "own" {
+		t.Errorf("explicit pull secrets should be kept, got %v", secrets)
+	}
+}
+
+func TestApplyRuntimeClassDefault(t *testing.T) {
+	pod := minimalPod(t)
+	applyRuntimeClassDefault(pod, map[string]string{DefaultRuntimeClassAnnotation: "gvisor"})
+	rc, _, _ := unstructured.NestedString(pod.Object, "spec", "runtimeClassName")
+	if rc != "gvisor" {
+		t.Errorf("expected the annotated runtime class, got %q", rc)
+	}
+
+	applyRuntimeClassDefault(pod, map[string]string{DefaultRuntimeClassAnnotation: "kata"})
+	rc, _, _ = unstructured.NestedString(pod.Object, "spec", "runtimeClassName")
+	if rc != "gvisor" {
+		t.Errorf("an already-set runtime class should be kept, got %q", rc)
+	}
+}
+
+func TestApplyLimitRangeDefaults(t *testing.T) {
+	pod := minimalPod(t)
+	limits := []corev1.LimitRangeItem{{
+		Type:           corev1.LimitTypeContainer,
+		Default:        corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+		DefaultRequest: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+	}}
+	applyLimitRangeDefaults(pod, limits)
+
+	containers, _, _ := unstructured.NestedSlice(pod.Object, "spec", "containers")
+	container := containers[0].(map[string]interface{})
+	if mem, _, _ := unstructured.NestedString(container, "resources", "limits", "memory"); mem != "512Mi" {
+		t.Errorf("expected the default memory limit, got %q", mem)
+	}
+	if cpu, _, _ := unstructured.NestedString(container, "resources", "requests", "cpu"); cpu != "100m" {
+		t.Errorf("expected the default cpu request, got %q", cpu)
+	}
+
+	// explicit resources win over LimitRange defaults
+	_ = unstructured.SetNestedField(container, "1Gi", "resources", "limits", "memory")
+	containers[0] = container
+	_ = unstructured.SetNestedSlice(pod.Object, containers, "spec", "containers")
+	applyLimitRangeDefaults(pod, limits)
+	containers, _, _ = unstructured.NestedSlice(pod.Object, "spec", "containers")
+	if mem, _, _ := unstructured.NestedString(containers[0].(map[string]interface{}), "resources", "limits", "memory"); mem != "1Gi" {
+		t.Errorf("explicit limits should be kept, got %q", mem)
+	}
+}
@@ -0,0 +1,108 @@
+// Package expansion evaluates policy against the resources a workload
+// generator implies, at the point where users actually create
+// resources. An ExpansionTemplate declares where a parent kind embeds
+// its resource template (e.g. spec.template on Deployments or Argo
+// Rollouts) and what kind that template generates; when a matching
+// parent is admitted, the webhook fabricates the resultant resource and
+// reviews it, so pod-level constraints reject a bad Deployment instead
+// of the Pods that would appear later. Expanded resources exist only
+// for the review; nothing is written to the cluster.
+package expansion
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	expansionv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/expansion/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var enableExpansion = flag.Bool("enable-generator-resource-expansion", false, "(alpha) review the resources implied by workload generators, as declared by ExpansionTemplates, when the generators are admitted")
+
+// Enabled returns true if generator resource expansion was requested
+// via flags.
+func Enabled() bool {
+	return *enableExpansion
+}
+
+// Matches reports whether the template applies to parents of the given
+// GVK.
+func Matches(t *expansionv1alpha1.ExpansionTemplate, gvk schema.GroupVersionKind) bool {
+	for _, applyTo := range t.Spec.ApplyTo {
+		if contains(applyTo.Groups, gvk.Group) && contains(applyTo.Versions, gvk.Version) && contains(applyTo.Kinds, gvk.Kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// TemplatesFor returns the templates in list that apply to parents of
+// the given GVK.
+func TemplatesFor(list []expansionv1alpha1.ExpansionTemplate, gvk schema.GroupVersionKind) []*expansionv1alpha1.ExpansionTemplate {
+	var out []*expansionv1alpha1.ExpansionTemplate
+	for i := range list {
+		if Matches(&list[i], gvk) {
+			out = append(out, &list[i])
+		}
+	}
+	return out
+}
+
+// Expand fabricates the resource the parent would generate according to
+// the template. The embedded template's metadata is preserved where
+// present; the name and namespace are derived from the parent so the
+// result is well-formed for review.
+func Expand(t *expansionv1alpha1.ExpansionTemplate, parent *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	path := strings.Split(t.Spec.TemplateSource, ".")
+	source, found, err := unstructured.NestedMap(parent.Object, path...)
+	if err != nil {
+		return nil, fmt.Errorf("extracting %s from %s %s: %w", t.Spec.TemplateSource, parent.GetKind(), parent.GetName(), err)
+	}
+	if !found {
+		return nil, fmt.Errorf("%s %s has no %s to expand", parent.GetKind(), parent.GetName(), t.Spec.TemplateSource)
+	}
+
+	resultant := &unstructured.Unstructured{Object: source}
+	resultant.SetAPIVersion(schema.GroupVersion{Group: t.Spec.GeneratedGVK.Group, Version: t.Spec.GeneratedGVK.Version}.String())
+	resultant.SetKind(t.Spec.GeneratedGVK.Kind)
+	resultant.SetName(parent.GetName() + "-" + strings.ToLower(t.Spec.GeneratedGVK.Kind))
+	resultant.SetNamespace(parent.GetNamespace())
+	return resultant, nil
+}
+
+// ValidateTemplate checks an ExpansionTemplate for the mistakes that
+// would otherwise only surface as failed expansions at admission time.
+func ValidateTemplate(t *expansionv1alpha1.ExpansionTemplate) error {
+	if len(t.Spec.ApplyTo) == 0 {
+		return fmt.Errorf("ExpansionTemplate %s must specify applyTo", t.GetName())
+	}
+	for _, applyTo := range t.Spec.ApplyTo {
+		if len(applyTo.Kinds) == 0 || len(applyTo.Versions) == 0 {
+			return fmt.Errorf("ExpansionTemplate %s applyTo entries must specify kinds and versions", t.GetName())
+		}
+	}
+	if t.Spec.Transform != nil {
+		if t.Spec.TemplateSource != "" {
+			return fmt.Errorf("ExpansionTemplate %s must specify templateSource or transform, not both", t.GetName())
+		}
+		return validateTransform(t)
+	}
+	if t.Spec.TemplateSource == "" {
+		return fmt.Errorf("ExpansionTemplate %s must specify templateSource or transform", t.GetName())
+	}
+	if t.Spec.GeneratedGVK.Version == "" || t.Spec.GeneratedGVK.Kind == "" {
+		return fmt.Errorf("ExpansionTemplate %s must specify the version and kind of the generated resource", t.GetName())
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,247 @@
+package expansion
+
+import (
+	"context"
+	"testing"
+
+	expansionv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/expansion/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+func rolloutTemplate() *expansionv1alpha1.ExpansionTemplate {
+	return &expansionv1alpha1.ExpansionTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "expand-rollouts"},
+		Spec: expansionv1alpha1.ExpansionTemplateSpec{
+			ApplyTo: []expansionv1alpha1.ApplyTo{{
+				Groups:   []string{"argoproj.io"},
+				Versions: []string{"v1alpha1"},
+				Kinds:    []string{"Rollout"},
+			}},
+			TemplateSource: "spec.template",
+			GeneratedGVK: expansionv1alpha1.GeneratedGVK{
+				Version: "v1",
+				Kind:    "Pod",
+			},
+		},
+	}
+}
+
+func rolloutParent(t *testing.T) *unstructured.Unstructured {
+	t.Helper()
+	manifest := `
+apiVersion: argoproj.io/v1alpha1
+kind: Rollout
+metadata:
+  name: canary
+  namespace: prod
+spec:
+  template:
+    metadata:
+      labels:
+        app: canary
+    spec:
+      containers:
+        - name: main
+          image: nginx
+`
+	obj := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal([]byte(manifest), &obj.Object); err != nil {
+		t.Fatal(err)
+	}
+	return obj
+}
+
+func TestMatches(t *testing.T) {
+	tmpl := rolloutTemplate()
+	if !Matches(tmpl, schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Rollout"}) {
+		t.Error("expected the template to match its declared GVK")
+	}
+	if Matches(tmpl, schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}) {
+		t.Error("the template should not match undeclared GVKs")
+	}
+}
+
+func TestExpandProducesReviewableResource(t *testing.T) {
+	parent := rolloutParent(t)
+	resultant, err := Expand(rolloutTemplate(), parent)
+	if err != nil {
+		t.Fatalf("expand failed: %s", err)
+	}
+	if resultant.GetKind() != "Pod" || resultant.GetAPIVersion() != "v1" {
+		t.Errorf("expected a v1 Pod, got %s %s", resultant.GetAPIVersion(), resultant.GetKind())
+	}
+	if resultant.GetName() != "canary-pod" || resultant.GetNamespace() != "prod" {
+		t.Errorf("expected name and namespace derived from the parent, got %s/%s", resultant.GetNamespace(), resultant.GetName())
+	}
+	if resultant.GetLabels()["app"] != "canary" {
+		t.Error("expected the template metadata to carry over")
+	}
+	containers, found, err := unstructured.NestedSlice(resultant.Object, "spec", "containers")
+	if err != nil || !found || len(containers) != 1 {
+		t.Errorf("expected the template spec to carry over, got %v (found=%v, err=%v)", containers, found, err)
+	}
+	// the parent must be left untouched
+	if parent.GetKind() != "Rollout" {
+		t.Error("expansion mutated the parent object")
+	}
+}
+
+func TestExpandMissingSource(t *testing.T) {
+	tmpl := rolloutTemplate()
+	tmpl.Spec.TemplateSource = "spec.jobTemplate.spec.template"
+	if _, err := Expand(tmpl, rolloutParent(t)); err == nil {
+		t.Fatal("expected an error when the template source is absent")
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	tc := []struct {
+		name   string
+		mutate func(*expansionv1alpha1.ExpansionTemplate)
+		err    bool
+	}{
+		{name: "valid", mutate: func(t *expansionv1alpha1.ExpansionTemplate) {}},
+		{name: "missing applyTo", mutate: func(t *expansionv1alpha1.ExpansionTemplate) { t.Spec.ApplyTo = nil }, err: true},
+		{name: "applyTo without versions", mutate: func(t *expansionv1alpha1.ExpansionTemplate) { t.Spec.ApplyTo[0].Versions = nil }, err: true},
+		{name: "missing templateSource", mutate: func(t *expansionv1alpha1.ExpansionTemplate) { t.Spec.TemplateSource = "" }, err: true},
+		{name: "missing generated kind", mutate: func(t *expansionv1alpha1.ExpansionTemplate) { t.Spec.GeneratedGVK.Kind = "" }, err: true},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := rolloutTemplate()
+			tt.mutate(tmpl)
+			err := ValidateTemplate(tmpl)
+			if tt.err && err == nil {
+				t.Error("expected a validation error")
+			}
+			if !tt.err && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func transformTemplate() *expansionv1alpha1.ExpansionTemplate {
+	return &expansionv1alpha1.ExpansionTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "expand-fanout"},
+		Spec: expansionv1alpha1.ExpansionTemplateSpec{
+			ApplyTo: []expansionv1alpha1.ApplyTo{{
+				Groups:   []string{"example.com"},
+				Versions: []string{"v1"},
+				Kinds:    []string{"Fanout"},
+			}},
+			Transform: &expansionv1alpha1.Transform{
+				Rego: `
+package expansion
+
+resultants[pod] {
+	replica := input.spec.replicas[_]
+	pod := {
+		"apiVersion": "v1",
+		"kind": "Pod",
+		"metadata": {"name": replica.name},
+		"spec": replica.spec,
+	}
+}
+`,
+			},
+		},
+	}
+}
+
+func fanoutParent(t *testing.T) *unstructured.Unstructured {
+	t.Helper()
+	manifest := `
+apiVersion: example.com/v1
+kind: Fanout
+metadata:
+  name: fan
+  namespace: prod
+spec:
+  replicas:
+    - name: fan-a
+      spec:
+        containers:
+          - name: main
+            image: nginx
+    - name: fan-b
+      spec:
+        containers:
+          - name: main
+            image: redis
+`
+	obj := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal([]byte(manifest), &obj.Object); err != nil {
+		t.Fatal(err)
+	}
+	return obj
+}
+
+func TestExpandAllTransform(t *testing.T) {
+	resultants, err := ExpandAll(context.Background(), transformTemplate(), fanoutParent(t))
+	if err != nil {
+		t.Fatalf("transform expansion failed: %s", err)
+	}
+	if len(resultants) != 2 {
+		t.Fatalf("expected two resultants, got %d", len(resultants))
+	}
+	names := map[string]bool{}
+	for _, r := range resultants {
+		if r.GetKind() != "Pod" || r.GetAPIVersion() != "v1" {
+			t.Errorf("expected a v1 Pod, got %s %s", r.GetAPIVersion(), r.GetKind())
+		}
+		if r.GetNamespace() != "prod" {
+			t.Errorf("expected the namespace to default from the parent, got %q", r.GetNamespace())
+		}
+		names[r.GetName()] = true
+	}
+	if !names["fan-a"] || !names["fan-b"] {
+		t.Errorf("unexpected resultant names %v", names)
+	}
+}
+
+func TestExpandAllClassicTemplate(t *testing.T) {
+	resultants, err := ExpandAll(context.Background(), rolloutTemplate(), rolloutParent(t))
+	if err != nil {
+		t.Fatalf("expansion failed: %s", err)
+	}
+	if len(resultants) != 1 || resultants[0].GetName() != "canary-pod" {
+		t.Errorf("expected the single embedded-template resultant, got %v", resultants)
+	}
+}
+
+func TestTransformResultantMissingKind(t *testing.T) {
+	tmpl := transformTemplate()
+	tmpl.Spec.Transform.Rego = `
+package expansion
+
+resultants[pod] {
+	replica := input.spec.replicas[_]
+	pod := {"metadata": {"name": replica.name}}
+}
+`
+	if _, err := ExpandAll(context.Background(), tmpl, fanoutParent(t)); err == nil {
+		t.Fatal("expected an error for resultants without apiVersion and kind")
+	}
+}
+
+func TestValidateTransformTemplate(t *testing.T) {
+	tmpl := transformTemplate()
+	if err := ValidateTemplate(tmpl); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	tmpl.Spec.TemplateSource = "spec.template"
+	if err := ValidateTemplate(tmpl); err == nil {
+		t.Error("expected an error when both templateSource and transform are set")
+	}
+
+	tmpl = transformTemplate()
+	tmpl.Spec.Transform.Rego = "not rego"
+	if err := ValidateTemplate(tmpl); err == nil {
+		t.Error("expected an error for a transform that does not compile")
+	}
+}
@@ -0,0 +1,110 @@
+package expansion
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	expansionv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/expansion/v1alpha1"
+	"github.com/open-policy-agent/opa/rego"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// transformQuery is the rule a transform program must define: a list of
+// complete resultant objects, evaluated with the parent as input.
+const transformQuery = "data.expansion.resultants"
+
+// preparedTransforms caches compiled transform programs by source so a
+// template's Rego is not recompiled on every admission review.
+var preparedTransforms = struct {
+	mux     sync.Mutex
+	queries map[string]rego.PreparedEvalQuery
+}{queries: make(map[string]rego.PreparedEvalQuery)}
+
+func prepareTransform(ctx context.Context, source string) (rego.PreparedEvalQuery, error) {
+	preparedTransforms.mux.Lock()
+	defer preparedTransforms.mux.Unlock()
+	if q, ok := preparedTransforms.queries[source]; ok {
+		return q, nil
+	}
+	q, err := rego.New(
+		rego.Query(transformQuery),
+		rego.Module("transform.rego", source),
+	).PrepareForEval(ctx)
+	if err != nil {
+		return rego.PreparedEvalQuery{}, err
+	}
+	preparedTransforms.queries[source] = q
+	return q, nil
+}
+
+// ExpandAll produces the resources the parent implies according to the
+// template: the single embedded-template extraction for templateSource
+// templates, or whatever the declared transform emits.
+func ExpandAll(ctx context.Context, t *expansionv1alpha1.ExpansionTemplate, parent *unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	if t.Spec.Transform != nil && t.Spec.Transform.Rego != "" {
+		return expandTransform(ctx, t, parent)
+	}
+	resultant, err := Expand(t, parent)
+	if err != nil {
+		return nil, err
+	}
+	return []*unstructured.Unstructured{resultant}, nil
+}
+
+// expandTransform evaluates the template's transform against the parent
+// and returns the emitted resources.
+func expandTransform(ctx context.Context, t *expansionv1alpha1.ExpansionTemplate, parent *unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	query, err := prepareTransform(ctx, t.Spec.Transform.Rego)
+	if err != nil {
+		return nil, fmt.Errorf("compiling transform of ExpansionTemplate %s: %w", t.GetName(), err)
+	}
+	rs, err := query.Eval(ctx, rego.EvalInput(parent.Object))
+	if err != nil {
+		return nil, fmt.Errorf("evaluating transform of ExpansionTemplate %s: %w", t.GetName(), err)
+	}
+	if len(rs) == 0 || len(rs[0].Expressions) == 0 {
+		return nil, fmt.Errorf("transform of ExpansionTemplate %s produced no resultants", t.GetName())
+	}
+	emitted, ok := rs[0].Expressions[0].Value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("transform of ExpansionTemplate %s must emit a list of objects, got %T", t.GetName(), rs[0].Expressions[0].Value)
+	}
+
+	var out []*unstructured.Unstructured
+	for i, item := range emitted {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("transform of ExpansionTemplate %s emitted a non-object resultant at index %d", t.GetName(), i)
+		}
+		resultant := &unstructured.Unstructured{Object: obj}
+		if resultant.GetAPIVersion() == "" || resultant.GetKind() == "" {
+			return nil, fmt.Errorf("transform of ExpansionTemplate %s emitted a resultant without apiVersion and kind at index %d", t.GetName(), i)
+		}
+		// name and namespace default from the parent so the result is
+		// well-formed for review
+		if resultant.GetName() == "" {
+			resultant.SetName(fmt.Sprintf("%s-%d", parent.GetName(), i))
+		}
+		if resultant.GetNamespace() == "" {
+			resultant.SetNamespace(parent.GetNamespace())
+		}
+		out = append(out, resultant)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("transform of ExpansionTemplate %s emitted no resultants", t.GetName())
+	}
+	return out, nil
+}
+
+// validateTransform compiles the transform so a broken program is
+// rejected when the template is written rather than at admission time.
+func validateTransform(t *expansionv1alpha1.ExpansionTemplate) error {
+	if t.Spec.Transform.Rego == "" {
+		return fmt.Errorf("ExpansionTemplate %s transform must specify rego", t.GetName())
+	}
+	if _, err := prepareTransform(context.Background(), t.Spec.Transform.Rego); err != nil {
+		return fmt.Errorf("ExpansionTemplate %s transform does not compile: %w", t.GetName(), err)
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+// Package extauthz serves the Envoy external authorization check API
+// backed by the constraint framework, so service meshes can enforce the
+// same organizational policies Gatekeeper enforces at admission. Each
+// Check call is wrapped into a review object carrying the request's
+// method, path, headers, and caller identity, and evaluated against
+// constraints matching the CheckRequest kind; a matching deny constraint
+// turns into a PERMISSION_DENIED response. Messages travel in the
+// protobuf wire format the filter sends, read and written directly (see
+// proto.go), so no generated Envoy protobuf code is required.
+package extauthz
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/logging"
+	"github.com/open-policy-agent/gatekeeper/pkg/opashard"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	"google.golang.org/grpc"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("ext-authz").WithValues(logging.Process, "ext_authz")
+
+// ServiceName is the fully qualified gRPC service name Envoy's
+// ext_authz filter dials.
+const ServiceName = "envoy.service.auth.v3.Authorization"
+
+// Review objects are evaluated as this GVK; constraints targeting
+// service traffic match kind CheckRequest in the extauthz group.
+const (
+	checkAPIVersion = "extauthz.gatekeeper.sh/v1alpha1"
+	checkKind       = "CheckRequest"
+)
+
+// grpc codes returned in the check response status.
+const (
+	codeOK               = 0
+	codePermissionDenied = 7
+)
+
+var (
+	enableExtAuthz = flag.Bool("enable-ext-authz", false, "(alpha) serve the Envoy external authorization check API backed by constraints")
+	extAuthzAddr   = flag.String("ext-authz-addr", ":9191", "The address the ext_authz service binds to.")
+)
+
+// Enabled returns true when the ext_authz service was requested via
+// flag.
+func Enabled() bool {
+	return *enableExtAuthz
+}
+
+// CheckRequest mirrors the subset of the Envoy check message constraints
+// evaluate: who is calling, and what HTTP request they made.
+type CheckRequest struct {
+	Attributes Attributes `json:"attributes"`
+}
+
+// Attributes carries the source identity and the request under check.
+type Attributes struct {
+	Source  Peer        `json:"source,omitempty"`
+	Request HTTPRequest `json:"request,omitempty"`
+}
+
+// Peer identifies the calling workload.
+type Peer struct {
+	// Principal is the authenticated identity of the caller, e.g. a
+	// SPIFFE ID when the mesh uses mTLS.
+	Principal string `json:"principal,omitempty"`
+}
+
+// HTTPRequest wraps the HTTP attributes of the request.
+type HTTPRequest struct {
+	HTTP HTTPAttributes `json:"http,omitempty"`
+}
+
+// HTTPAttributes is the request as the proxy saw it.
+type HTTPAttributes struct {
+	Method  string            `json:"method,omitempty"`
+	Path    string            `json:"path,omitempty"`
+	Host    string            `json:"host,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// CheckResponse reports the authorization decision as a gRPC status:
+// code 0 allows the request, PERMISSION_DENIED blocks it with the deny
+// messages as the status message.
+type CheckResponse struct {
+	Status ResponseStatus `json:"status"`
+}
+
+// ResponseStatus is the google.rpc.Status portion of the response.
+type ResponseStatus struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// Server serves check requests. It implements manager.Runnable so it
+// can be registered with the controller manager like the other optional
+// servers.
+type Server struct {
+	opa  opashard.Client
+	addr string
+}
+
+// NewServer returns a Server configured from the ext_authz flags.
+func NewServer(opa opashard.Client) *Server {
+	return &Server{opa: opa, addr: *extAuthzAddr}
+}
+
+// serviceDesc describes the authorization service without generated
+// code.
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*authorizationHandler)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Check", Handler: checkHandler},
+	},
+}
+
+// authorizationHandler is the interface the service desc dispatches to.
+type authorizationHandler interface {
+	Check(ctx context.Context, req *CheckRequest) (*CheckResponse, error)
+}
+
+func checkHandler(srv interface{}, ctx context.Context, decode func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	req := &CheckRequest{}
+	if err := decode(req); err != nil {
+		return nil, err
+	}
+	return srv.(authorizationHandler).Check(ctx, req)
+}
+
+// Check evaluates one request against loaded constraints. As with
+// admission, only the deny enforcement action blocks; evaluation errors
+// allow the request so a policy bug cannot take down mesh traffic.
+func (s *Server) Check(ctx context.Context, req *CheckRequest) (*CheckResponse, error) {
+	obj, err := wrapCheckRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.opa.Review(ctx, target.AugmentedUnstructured{Object: *obj})
+	if err != nil {
+		log.Error(err, "error evaluating check request", "principal", req.Attributes.Source.Principal)
+		return &CheckResponse{Status: ResponseStatus{Code: codeOK}}, nil
+	}
+	var msgs []string
+	for _, r := range resp.Results() {
+		if r.EnforcementAction != "deny" {
+			continue
+		}
+		msgs = append(msgs, fmt.Sprintf("[denied by %s] %s", r.Constraint.GetName(), r.Msg))
+	}
+	if len(msgs) > 0 {
+		log.Info("denied request",
+			logging.EventType, "violation",
+			"principal", req.Attributes.Source.Principal,
+			"method", req.Attributes.Request.HTTP.Method,
+			"path", req.Attributes.Request.HTTP.Path,
+			"denials", strings.Join(msgs, "\n"),
+		)
+		return &CheckResponse{Status: ResponseStatus{Code: codePermissionDenied, Message: strings.Join(msgs, "\n")}}, nil
+	}
+	return &CheckResponse{Status: ResponseStatus{Code: codeOK}}, nil
+}
+
+// wrapCheckRequest turns the check message into an unstructured object
+// constraints can match on; the attributes land under
+// review.object.spec. Check requests are unnamed, so the caller's
+// principal identifies the request in violation messages.
+func wrapCheckRequest(req *CheckRequest) (*unstructured.Unstructured, error) {
+	raw, err := json.Marshal(req.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	spec := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &spec); err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	obj.SetAPIVersion(checkAPIVersion)
+	obj.SetKind(checkKind)
+	name := req.Attributes.Source.Principal
+	if name == "" {
+		name = "anonymous"
+	}
+	obj.SetName(name)
+	return obj, nil
+}
+
+// Start implements manager.Runnable.
+func (s *Server) Start(ctx context.Context) error {
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", s.addr, err)
+	}
+	// Envoy sends the default proto content subtype; the codec is forced
+	// server-wide so it applies regardless of the subtype offered.
+	srv := grpc.NewServer(grpc.CustomCodec(protoCodec{}))
+	srv.RegisterService(&serviceDesc, s)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Serve(lis)
+	}()
+	log.Info("serving ext_authz API", "addr", s.addr)
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		srv.GracefulStop()
+		return nil
+	}
+}
@@ -0,0 +1,166 @@
+package extauthz
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ghodss/yaml"
+	templv1beta1 "github.com/open-policy-agent/frameworks/constraint/pkg/apis/templates/v1beta1"
+	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/client/drivers/local"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/core/templates"
+	"github.com/open-policy-agent/gatekeeper/apis"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	"google.golang.org/grpc"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	denyAdminPathTemplate = `
+apiVersion: templates.gatekeeper.sh/v1beta1
+kind: ConstraintTemplate
+metadata:
+  name: k8sdenyadminpaths
+spec:
+  crd:
+    spec:
+      names:
+        kind: K8sDenyAdminPaths
+  targets:
+    - target: admission.k8s.gatekeeper.sh
+      rego: |
+        package denyadminpaths
+
+        violation[{"msg": msg}] {
+          startswith(input.review.object.spec.request.http.path, "/admin")
+          msg := sprintf("principal %v may not call %v", [input.review.object.spec.source.principal, input.review.object.spec.request.http.path])
+        }
+`
+
+	denyAdminPathConstraint = `
+apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sDenyAdminPaths
+metadata:
+  name: deny-admin-paths
+spec:
+  match:
+    kinds:
+      - apiGroups: ["extauthz.gatekeeper.sh"]
+        kinds: ["CheckRequest"]
+`
+)
+
+func makeServer(t *testing.T) *Server {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := apis.AddToScheme(scheme); err != nil {
+		t.Fatalf("building scheme: %s", err)
+	}
+	driver := local.New(local.Tracing(false))
+	backend, err := opa.NewBackend(opa.Driver(driver))
+	if err != nil {
+		t.Fatalf("could not initialize OPA backend: %s", err)
+	}
+	opaClient, err := backend.NewClient(opa.Targets(&target.K8sValidationTarget{}))
+	if err != nil {
+		t.Fatalf("could not initialize OPA: %s", err)
+	}
+	cstr := &templv1beta1.ConstraintTemplate{}
+	if err := yaml.Unmarshal([]byte(denyAdminPathTemplate), cstr); err != nil {
+		t.Fatalf("could not parse template: %s", err)
+	}
+	unversioned := &templates.ConstraintTemplate{}
+	if err := scheme.Convert(cstr, unversioned, nil); err != nil {
+		t.Fatalf("could not convert template: %s", err)
+	}
+	if _, err := opaClient.AddTemplate(context.Background(), unversioned); err != nil {
+		t.Fatalf("could not add template: %s", err)
+	}
+	b, err := yaml.YAMLToJSON([]byte(denyAdminPathConstraint))
+	if err != nil {
+		t.Fatalf("could not parse constraint: %s", err)
+	}
+	u := &unstructured.Unstructured{}
+	if err := u.UnmarshalJSON(b); err != nil {
+		t.Fatalf("could not decode constraint: %s", err)
+	}
+	if _, err := opaClient.AddConstraint(context.Background(), u); err != nil {
+		t.Fatalf("could not add constraint: %s", err)
+	}
+	return &Server{opa: opaClient}
+}
+
+func TestCheckOverGRPC(t *testing.T) {
+	server := makeServer(t)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %s", err)
+	}
+	srv := grpc.NewServer(grpc.CustomCodec(protoCodec{}))
+	srv.RegisterService(&serviceDesc, server)
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, lis.Addr().String(), grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		t.Fatalf("dialing: %s", err)
+	}
+	defer conn.Close()
+
+	resp := &CheckResponse{}
+	req := &CheckRequest{Attributes: Attributes{
+		Source:  Peer{Principal: "spiffe://cluster.local/ns/ci/sa/runner"},
+		Request: HTTPRequest{HTTP: HTTPAttributes{Method: "GET", Path: "/admin/users"}},
+	}}
+	if err := conn.Invoke(ctx, "/"+ServiceName+"/Check", req, resp, grpc.CallCustomCodec(protoCodec{})); err != nil {
+		t.Fatalf("invoking Check: %s", err)
+	}
+	if resp.Status.Code != codePermissionDenied {
+		t.Errorf("admin path should be denied, got %+v", resp.Status)
+	}
+	if !strings.Contains(resp.Status.Message, "may not call /admin/users") {
+		t.Errorf("status message should carry the violation, got %q", resp.Status.Message)
+	}
+
+	req.Attributes.Request.HTTP.Path = "/healthz"
+	if err := conn.Invoke(ctx, "/"+ServiceName+"/Check", req, resp, grpc.CallCustomCodec(protoCodec{})); err != nil {
+		t.Fatalf("invoking Check: %s", err)
+	}
+	if resp.Status.Code != codeOK {
+		t.Errorf("non-matching request should be allowed, got %+v", resp.Status)
+	}
+}
+
+func TestWrapCheckRequest(t *testing.T) {
+	obj, err := wrapCheckRequest(&CheckRequest{Attributes: Attributes{
+		Source:  Peer{Principal: "spiffe://cluster.local/ns/ci/sa/runner"},
+		Request: HTTPRequest{HTTP: HTTPAttributes{Method: "POST", Path: "/v1/jobs", Headers: map[string]string{"x-team": "ci"}}},
+	}})
+	if err != nil {
+		t.Fatalf("wrapping request: %s", err)
+	}
+	if obj.GetAPIVersion() != checkAPIVersion || obj.GetKind() != checkKind {
+		t.Errorf("review object should carry the CheckRequest GVK, got %s/%s", obj.GetAPIVersion(), obj.GetKind())
+	}
+	if obj.GetName() != "spiffe://cluster.local/ns/ci/sa/runner" {
+		t.Errorf("review object should be named for the principal, got %q", obj.GetName())
+	}
+	path, _, err := unstructured.NestedString(obj.Object, "spec", "request", "http", "path")
+	if err != nil || path != "/v1/jobs" {
+		t.Errorf("request attributes should land under spec, got %q (%v)", path, err)
+	}
+	obj, err = wrapCheckRequest(&CheckRequest{})
+	if err != nil {
+		t.Fatalf("wrapping request: %s", err)
+	}
+	if obj.GetName() != "anonymous" {
+		t.Errorf("unauthenticated callers should fall back to anonymous, got %q", obj.GetName())
+	}
+}
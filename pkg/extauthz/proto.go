@@ -0,0 +1,269 @@
+package extauthz
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// Envoy's ext_authz filter sends protobuf-encoded CheckRequests with the
+// default proto content subtype; it has no JSON transport. Rather than
+// vendor the generated Envoy API packages for the handful of fields the
+// server uses, this file reads and writes those fields directly in the
+// protobuf wire format. Field numbers come from
+// envoy/service/auth/v3/external_auth.proto and attribute_context.proto.
+const (
+	// CheckRequest
+	fieldAttributes = 1
+	// AttributeContext
+	fieldSource  = 1
+	fieldRequest = 4
+	// AttributeContext.Peer
+	fieldPrincipal = 4
+	// AttributeContext.Request
+	fieldHTTP = 2
+	// AttributeContext.HttpRequest
+	fieldMethod  = 2
+	fieldHeaders = 3
+	fieldPath    = 4
+	fieldHost    = 5
+	// map<string, string> entries
+	fieldMapKey   = 1
+	fieldMapValue = 2
+	// CheckResponse
+	fieldStatus = 1
+	// google.rpc.Status
+	fieldCode    = 1
+	fieldMessage = 2
+)
+
+// protobuf wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// protoCodec carries check requests and responses in the protobuf wire
+// format. It implements grpc.Codec; the server installs it via
+// grpc.CustomCodec so only the ext_authz service is affected.
+type protoCodec struct{}
+
+func (protoCodec) Marshal(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case *CheckRequest:
+		return marshalCheckRequest(v), nil
+	case *CheckResponse:
+		return marshalCheckResponse(v), nil
+	}
+	return nil, fmt.Errorf("ext_authz proto codec cannot marshal %T", v)
+}
+
+func (protoCodec) Unmarshal(data []byte, v interface{}) error {
+	switch v := v.(type) {
+	case *CheckRequest:
+		return unmarshalCheckRequest(data, v)
+	case *CheckResponse:
+		return unmarshalCheckResponse(data, v)
+	}
+	return fmt.Errorf("ext_authz proto codec cannot unmarshal %T", v)
+}
+
+func (protoCodec) String() string { return "proto" }
+
+// fields iterates a message's fields. Bytes fields hand fn their
+// payload, varint fields their value; fixed-width and unknown fields are
+// skipped.
+func fields(data []byte, fn func(num int, payload []byte, varint uint64) error) error {
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 {
+			return fmt.Errorf("malformed field tag")
+		}
+		data = data[n:]
+		num, wire := int(tag>>3), int(tag&0x7)
+		switch wire {
+		case wireVarint:
+			value, n := binary.Uvarint(data)
+			if n <= 0 {
+				return fmt.Errorf("malformed varint in field %d", num)
+			}
+			data = data[n:]
+			if err := fn(num, nil, value); err != nil {
+				return err
+			}
+		case wireBytes:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < length {
+				return fmt.Errorf("malformed length in field %d", num)
+			}
+			payload := data[n : n+int(length)]
+			data = data[n+int(length):]
+			if err := fn(num, payload, 0); err != nil {
+				return err
+			}
+		case wireFixed64:
+			if len(data) < 8 {
+				return fmt.Errorf("truncated fixed64 in field %d", num)
+			}
+			data = data[8:]
+		case wireFixed32:
+			if len(data) < 4 {
+				return fmt.Errorf("truncated fixed32 in field %d", num)
+			}
+			data = data[4:]
+		default:
+			return fmt.Errorf("unsupported wire type %d in field %d", wire, num)
+		}
+	}
+	return nil
+}
+
+func unmarshalCheckRequest(data []byte, req *CheckRequest) error {
+	*req = CheckRequest{}
+	return fields(data, func(num int, payload []byte, _ uint64) error {
+		if num != fieldAttributes || payload == nil {
+			return nil
+		}
+		return unmarshalAttributes(payload, &req.Attributes)
+	})
+}
+
+func unmarshalAttributes(data []byte, attrs *Attributes) error {
+	return fields(data, func(num int, payload []byte, _ uint64) error {
+		if payload == nil {
+			return nil
+		}
+		switch num {
+		case fieldSource:
+			return fields(payload, func(num int, payload []byte, _ uint64) error {
+				if num == fieldPrincipal && payload != nil {
+					attrs.Source.Principal = string(payload)
+				}
+				return nil
+			})
+		case fieldRequest:
+			return fields(payload, func(num int, payload []byte, _ uint64) error {
+				if num != fieldHTTP || payload == nil {
+					return nil
+				}
+				return unmarshalHTTP(payload, &attrs.Request.HTTP)
+			})
+		}
+		return nil
+	})
+}
+
+func unmarshalHTTP(data []byte, http *HTTPAttributes) error {
+	return fields(data, func(num int, payload []byte, _ uint64) error {
+		if payload == nil {
+			return nil
+		}
+		switch num {
+		case fieldMethod:
+			http.Method = string(payload)
+		case fieldPath:
+			http.Path = string(payload)
+		case fieldHost:
+			http.Host = string(payload)
+		case fieldHeaders:
+			var key, value string
+			err := fields(payload, func(num int, payload []byte, _ uint64) error {
+				switch num {
+				case fieldMapKey:
+					key = string(payload)
+				case fieldMapValue:
+					value = string(payload)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if http.Headers == nil {
+				http.Headers = map[string]string{}
+			}
+			http.Headers[key] = value
+		}
+		return nil
+	})
+}
+
+func unmarshalCheckResponse(data []byte, resp *CheckResponse) error {
+	*resp = CheckResponse{}
+	return fields(data, func(num int, payload []byte, _ uint64) error {
+		if num != fieldStatus || payload == nil {
+			return nil
+		}
+		return fields(payload, func(num int, payload []byte, varint uint64) error {
+			switch num {
+			case fieldCode:
+				resp.Status.Code = int32(varint)
+			case fieldMessage:
+				resp.Status.Message = string(payload)
+			}
+			return nil
+		})
+	})
+}
+
+func marshalCheckRequest(req *CheckRequest) []byte {
+	var http []byte
+	http = appendString(http, fieldMethod, req.Attributes.Request.HTTP.Method)
+	keys := make([]string, 0, len(req.Attributes.Request.HTTP.Headers))
+	for k := range req.Attributes.Request.HTTP.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		var entry []byte
+		entry = appendString(entry, fieldMapKey, k)
+		entry = appendString(entry, fieldMapValue, req.Attributes.Request.HTTP.Headers[k])
+		http = appendBytes(http, fieldHeaders, entry)
+	}
+	http = appendString(http, fieldPath, req.Attributes.Request.HTTP.Path)
+	http = appendString(http, fieldHost, req.Attributes.Request.HTTP.Host)
+
+	var attrs []byte
+	if req.Attributes.Source.Principal != "" {
+		attrs = appendBytes(attrs, fieldSource, appendString(nil, fieldPrincipal, req.Attributes.Source.Principal))
+	}
+	attrs = appendBytes(attrs, fieldRequest, appendBytes(nil, fieldHTTP, http))
+	return appendBytes(nil, fieldAttributes, attrs)
+}
+
+func marshalCheckResponse(resp *CheckResponse) []byte {
+	var status []byte
+	if resp.Status.Code != 0 {
+		status = appendVarint(status, fieldCode, uint64(resp.Status.Code))
+	}
+	status = appendString(status, fieldMessage, resp.Status.Message)
+	return appendBytes(nil, fieldStatus, status)
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
+
+func appendVarint(b []byte, num int, value uint64) []byte {
+	b = appendUvarint(b, uint64(num)<<3|wireVarint)
+	return appendUvarint(b, value)
+}
+
+func appendBytes(b []byte, num int, payload []byte) []byte {
+	b = appendUvarint(b, uint64(num)<<3|wireBytes)
+	b = appendUvarint(b, uint64(len(payload)))
+	return append(b, payload...)
+}
+
+// appendString appends a string field, omitting it when empty as proto3
+// does.
+func appendString(b []byte, num int, s string) []byte {
+	if s == "" {
+		return b
+	}
+	return appendBytes(b, num, []byte(s))
+}
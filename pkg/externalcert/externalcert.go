@@ -0,0 +1,207 @@
+// Package externalcert supports webhook serving certs provisioned
+// outside Gatekeeper and mounted as files (vault-agent, cloud secret
+// CSI drivers, hand-rolled PKI). The manager's webhook server already
+// hot-reloads the serving cert from disk on rotation; this package adds
+// the two missing pieces: patching the CA bundle from the mounted files
+// into the webhook configurations, and a health check that flags
+// certificates approaching expiry before admission starts failing.
+package externalcert
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/open-policy-agent/cert-controller/pkg/rotator"
+	"github.com/open-policy-agent/gatekeeper/pkg/certmanager"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+var log = logf.Log.WithName("external-certs")
+
+// syncPeriod is how often the watcher re-reads the mounted cert files;
+// rotations are re-injected within one period.
+const syncPeriod = 10 * time.Second
+
+var (
+	externalCerts     = flag.Bool("enable-external-certs", false, "(alpha) use externally provisioned webhook certs mounted under --cert-dir: patch the mounted CA bundle into the webhook configurations on rotation and expose a cert expiry health check, instead of running the built-in rotator")
+	certExpiryWarning = flag.Duration("cert-expiry-warning", 240*time.Hour, "(alpha) how far before the serving cert expires the cert-expiry health check starts failing; only used with --enable-external-certs")
+)
+
+// Enabled returns true if externally provisioned certs were requested
+// via flags.
+func Enabled() bool {
+	return *externalCerts
+}
+
+// Watcher keeps the webhook configurations' CA bundle in sync with the
+// mounted cert files and tracks the serving cert's expiry. It implements
+// manager.Runnable.
+type Watcher struct {
+	// CertDir is where the externally provisioned certs are mounted.
+	CertDir string
+	// Webhooks are the webhook configurations to inject the CA bundle
+	// into.
+	Webhooks []rotator.WebhookInfo
+	// IsReady is closed once the certs are present and the CA bundle has
+	// been injected.
+	IsReady chan struct{}
+
+	client    client.Client
+	readyOnce sync.Once
+
+	mu       sync.Mutex
+	notAfter time.Time
+}
+
+// AddWatcher registers the watcher with the manager using a direct
+// (uncached) client and wires its expiry check into the health endpoint.
+func AddWatcher(mgr manager.Manager, w *Watcher) error {
+	c, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return err
+	}
+	w.client = c
+	if err := mgr.AddHealthzCheck("cert-expiry", w.CheckExpiry); err != nil {
+		return err
+	}
+	return mgr.Add(w)
+}
+
+// Start implements manager.Runnable. It blocks until ctx is cancelled,
+// re-reading the mounted files every syncPeriod.
+func (w *Watcher) Start(ctx context.Context) error {
+	ticker := time.NewTicker(syncPeriod)
+	defer ticker.Stop()
+	for {
+		if err := w.sync(ctx); err != nil {
+			log.Error(err, "unable to sync externally provisioned certs", "certDir", w.CertDir)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// sync re-reads the mounted files, records the serving cert's expiry,
+// and injects the CA bundle into every webhook configuration.
+func (w *Watcher) sync(ctx context.Context) error {
+	crt, err := ioutil.ReadFile(filepath.Join(w.CertDir, "tls.crt"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			log.Info("waiting for externally provisioned certs to be mounted", "certDir", w.CertDir)
+			return nil
+		}
+		return err
+	}
+	notAfter, err := certNotAfter(crt)
+	if err != nil {
+		return fmt.Errorf("parsing mounted serving cert: %w", err)
+	}
+	w.mu.Lock()
+	w.notAfter = notAfter
+	w.mu.Unlock()
+
+	ca, err := ioutil.ReadFile(filepath.Join(w.CertDir, "ca.crt"))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		// No separate CA bundle; self-signed setups serve the cert as its
+		// own trust anchor.
+		ca = crt
+	}
+	for _, wh := range w.Webhooks {
+		if err := w.injectWebhook(ctx, wh, ca); err != nil {
+			return fmt.Errorf("injecting CA bundle into %s: %w", wh.Name, err)
+		}
+	}
+
+	w.readyOnce.Do(func() {
+		log.Info("externally provisioned certs are ready", "certDir", w.CertDir, "notAfter", notAfter)
+		if w.IsReady != nil {
+			close(w.IsReady)
+		}
+	})
+	return nil
+}
+
+// injectWebhook updates one webhook configuration if its CA bundle is
+// out of date.
+func (w *Watcher) injectWebhook(ctx context.Context, wh rotator.WebhookInfo, ca []byte) error {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(webhookGVK(wh.Type))
+	if err := w.client.Get(ctx, types.NamespacedName{Name: wh.Name}, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("waiting for webhook configuration to exist", "webhook", wh.Name)
+			return nil
+		}
+		return err
+	}
+	changed, err := certmanager.InjectCABundle(obj, ca)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+	log.Info("injecting CA bundle from mounted certs", "webhook", wh.Name)
+	return w.client.Update(ctx, obj)
+}
+
+// CheckExpiry is a healthz.Checker that fails when the serving cert is
+// missing, unparseable, or within the expiry warning window.
+func (w *Watcher) CheckExpiry(_ *http.Request) error {
+	w.mu.Lock()
+	notAfter := w.notAfter
+	w.mu.Unlock()
+	if notAfter.IsZero() {
+		return errors.New("serving cert has not been read yet")
+	}
+	remaining := time.Until(notAfter)
+	if remaining <= 0 {
+		return fmt.Errorf("serving cert expired at %s", notAfter)
+	}
+	if remaining < *certExpiryWarning {
+		return fmt.Errorf("serving cert expires at %s, within the %s warning window", notAfter, *certExpiryWarning)
+	}
+	return nil
+}
+
+// certNotAfter returns the expiry of the leaf certificate in a PEM
+// bundle.
+func certNotAfter(pemBytes []byte) (time.Time, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return time.Time{}, errors.New("no PEM data found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return cert.NotAfter, nil
+}
+
+func webhookGVK(t rotator.WebhookType) schema.GroupVersionKind {
+	if t == rotator.Mutating {
+		return schema.GroupVersionKind{Group: "admissionregistration.k8s.io", Version: "v1", Kind: "MutatingWebhookConfiguration"}
+	}
+	return schema.GroupVersionKind{Group: "admissionregistration.k8s.io", Version: "v1", Kind: "ValidatingWebhookConfiguration"}
+}
@@ -0,0 +1,68 @@
+package externalcert
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func selfSignedPEM(t *testing.T, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating key: %s", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "gatekeeper-webhook-service.gatekeeper-system.svc"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("creating certificate: %s", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestCertNotAfter(t *testing.T) {
+	want := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+	got, err := certNotAfter(selfSignedPEM(t, want))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !got.Equal(want.UTC()) {
+		t.Errorf("NotAfter = %s, want %s", got, want.UTC())
+	}
+
+	if _, err := certNotAfter([]byte("not a cert")); err == nil {
+		t.Errorf("expected an error for non-PEM input")
+	}
+}
+
+func TestCheckExpiry(t *testing.T) {
+	w := &Watcher{}
+	if err := w.CheckExpiry(nil); err == nil {
+		t.Errorf("expected failure before the cert has been read")
+	}
+
+	w.notAfter = time.Now().Add(-time.Hour)
+	if err := w.CheckExpiry(nil); err == nil {
+		t.Errorf("expected failure for an expired cert")
+	}
+
+	w.notAfter = time.Now().Add(*certExpiryWarning / 2)
+	if err := w.CheckExpiry(nil); err == nil {
+		t.Errorf("expected failure inside the expiry warning window")
+	}
+
+	w.notAfter = time.Now().Add(*certExpiryWarning * 2)
+	if err := w.CheckExpiry(nil); err != nil {
+		t.Errorf("expected a healthy cert to pass: %s", err)
+	}
+}
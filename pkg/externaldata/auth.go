@@ -0,0 +1,91 @@
+package externaldata
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+
+	externaldatav1alpha1 "github.com/open-policy-agent/gatekeeper/apis/externaldata/v1alpha1"
+	"github.com/open-policy-agent/gatekeeper/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+// bearerTokenKey is the Secret data key a bearer token lives under.
+const bearerTokenKey = "token"
+
+// clientAuth is the resolved client credentials for one query.
+type clientAuth struct {
+	cert   *tls.Certificate
+	bearer string
+}
+
+// resolveAuth reads the Secrets the provider's auth references. It runs
+// on every query, so rotating a Secret takes effect on the next lookup
+// without restarting Gatekeeper; the reads are served from the
+// manager's cache, not the API server.
+func resolveAuth(ctx context.Context, provider *externaldatav1alpha1.Provider) (*clientAuth, error) {
+	if provider.Spec.Auth == nil {
+		return nil, nil
+	}
+	mux.Lock()
+	r := reader
+	mux.Unlock()
+	if r == nil {
+		return nil, fmt.Errorf("provider %s declares auth but external data is not set up", provider.GetName())
+	}
+
+	auth := &clientAuth{}
+	if ref := provider.Spec.Auth.ClientCertSecretRef; ref != nil {
+		secret, err := getSecret(ctx, ref)
+		if err != nil {
+			return nil, fmt.Errorf("provider %s client certificate: %w", provider.GetName(), err)
+		}
+		cert, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+		if err != nil {
+			return nil, fmt.Errorf("provider %s client certificate secret %s: %v", provider.GetName(), ref.Name, err)
+		}
+		auth.cert = &cert
+	}
+	if ref := provider.Spec.Auth.BearerTokenSecretRef; ref != nil {
+		secret, err := getSecret(ctx, ref)
+		if err != nil {
+			return nil, fmt.Errorf("provider %s bearer token: %w", provider.GetName(), err)
+		}
+		token := secret.Data[bearerTokenKey]
+		if len(token) == 0 {
+			return nil, fmt.Errorf("provider %s bearer token secret %s has no %s key", provider.GetName(), ref.Name, bearerTokenKey)
+		}
+		auth.bearer = string(token)
+	}
+	return auth, nil
+}
+
+func getSecret(ctx context.Context, ref *externaldatav1alpha1.SecretRef) (*corev1.Secret, error) {
+	mux.Lock()
+	r := reader
+	mux.Unlock()
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = util.GetNamespace()
+	}
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, k8stypes.NamespacedName{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+		return nil, fmt.Errorf("fetching secret %s/%s: %w", namespace, ref.Name, err)
+	}
+	return secret, nil
+}
+
+// tlsConfigFor builds the client TLS configuration from the provider's
+// CA bundle and any resolved client certificate.
+func tlsConfigFor(caBundle string, auth *clientAuth) (*tls.Config, error) {
+	pool, err := certPool(caBundle)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{RootCAs: pool}
+	if auth != nil && auth.cert != nil {
+		cfg.Certificates = []tls.Certificate{*auth.cert}
+	}
+	return cfg, nil
+}
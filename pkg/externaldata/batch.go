@@ -0,0 +1,93 @@
+package externaldata
+
+import (
+	"context"
+	"sync"
+)
+
+// Batcher coalesces external data lookups made during a single admission
+// review. Each template's external_data call typically asks for the same
+// keys — image names, hostnames — so without coalescing one review fans
+// out into one provider request per template. The batcher keeps a
+// review-scoped cache of resolved keys per provider: the first call
+// sends one batch request and later calls only query the keys the
+// review has not seen yet, so the common case is a single provider
+// round trip per review.
+type Batcher struct {
+	mu        sync.Mutex
+	providers map[string]*providerBatch
+}
+
+// providerBatch holds one provider's resolved keys for the review. Its
+// own lock serializes queries to the provider so concurrent callers
+// ride on each other's results instead of issuing duplicate requests.
+type providerBatch struct {
+	mu       sync.Mutex
+	resolved map[string]Item
+}
+
+// NewBatcher returns a batcher scoped to one admission review.
+func NewBatcher() *Batcher {
+	return &Batcher{providers: make(map[string]*providerBatch)}
+}
+
+type batcherKey struct{}
+
+// WithBatcher attaches the batcher to the context the review is
+// evaluated under, so the external_data builtin can find it.
+func WithBatcher(ctx context.Context, b *Batcher) context.Context {
+	return context.WithValue(ctx, batcherKey{}, b)
+}
+
+func batcherFrom(ctx context.Context) *Batcher {
+	b, _ := ctx.Value(batcherKey{}).(*Batcher)
+	return b
+}
+
+// Query answers the keys from the review's cache where possible and
+// sends the rest to the provider as one batch. Keys the provider does
+// not answer are returned as per-key errors rather than dropped, so
+// templates can tell a missing result from a missing lookup.
+func (b *Batcher) Query(ctx context.Context, providerName string, keys []string) ([]Item, error) {
+	pb := b.batchFor(providerName)
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	var missing []string
+	for _, key := range keys {
+		if _, ok := pb.resolved[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		items, err := QueryByName(ctx, providerName, missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			pb.resolved[item.Key] = item
+		}
+		for _, key := range missing {
+			if _, ok := pb.resolved[key]; !ok {
+				pb.resolved[key] = Item{Key: key, Error: "provider returned no result for key"}
+			}
+		}
+	}
+
+	out := make([]Item, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, pb.resolved[key])
+	}
+	return out, nil
+}
+
+func (b *Batcher) batchFor(providerName string) *providerBatch {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	pb, ok := b.providers[providerName]
+	if !ok {
+		pb = &providerBatch{resolved: make(map[string]Item)}
+		b.providers[providerName] = pb
+	}
+	return pb
+}
@@ -0,0 +1,114 @@
+package externaldata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	externaldatav1alpha1 "github.com/open-policy-agent/gatekeeper/apis/externaldata/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// providerReader serves one Provider, standing in for the manager's
+// cache.
+type providerReader struct {
+	provider *externaldatav1alpha1.Provider
+}
+
+func (f *providerReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	p, ok := obj.(*externaldatav1alpha1.Provider)
+	if !ok || f.provider == nil || key.Name != f.provider.Name {
+		return fmt.Errorf("not found: %v", key)
+	}
+	f.provider.DeepCopyInto(p)
+	return nil
+}
+
+func (f *providerReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return nil
+}
+
+func TestBatcherCoalescesRepeatedKeys(t *testing.T) {
+	requests := 0
+	var gotKeys [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		req := &Request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		gotKeys = append(gotKeys, req.Request.Keys)
+		resp := &Response{}
+		for _, key := range req.Request.Keys {
+			resp.Response.Items = append(resp.Response.Items, Item{Key: key, Value: "v-" + key})
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Errorf("encoding response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	Setup(&providerReader{provider: provider("test-provider", externaldatav1alpha1.ProviderSpec{URL: server.URL})})
+	defer Setup(nil)
+
+	ctx := context.Background()
+	b := NewBatcher()
+	items, err := b.Query(ctx, "test-provider", []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 2 || items[0].Value != "v-a" || items[1].Value != "v-b" {
+		t.Fatalf("unexpected items: %+v", items)
+	}
+
+	// a second template asking for the same keys must not reach the
+	// provider again
+	if _, err := b.Query(ctx, "test-provider", []string{"b", "a"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests != 1 {
+		t.Fatalf("provider saw %d requests, want 1", requests)
+	}
+
+	// a partially overlapping call only sends the unseen key
+	items, err = b.Query(ctx, "test-provider", []string{"a", "c"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests != 2 {
+		t.Fatalf("provider saw %d requests, want 2", requests)
+	}
+	if len(gotKeys[1]) != 1 || gotKeys[1][0] != "c" {
+		t.Fatalf("second request asked for keys %v, want [c]", gotKeys[1])
+	}
+	if items[0].Value != "v-a" || items[1].Value != "v-c" {
+		t.Fatalf("unexpected items: %+v", items)
+	}
+}
+
+func TestBatcherUnansweredKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := &Response{Response: ResponseBody{Items: []Item{{Key: "a", Value: "valid"}}}}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Errorf("encoding response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	Setup(&providerReader{provider: provider("test-provider", externaldatav1alpha1.ProviderSpec{URL: server.URL})})
+	defer Setup(nil)
+
+	items, err := NewBatcher().Query(context.Background(), "test-provider", []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if items[0].Key != "a" || items[0].Error != "" {
+		t.Fatalf("unexpected first item: %+v", items[0])
+	}
+	if items[1].Key != "b" || items[1].Error == "" {
+		t.Fatalf("unanswered key should carry a per-key error, got %+v", items[1])
+	}
+}
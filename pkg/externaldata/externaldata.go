@@ -0,0 +1,264 @@
+// Package externaldata lets templates consult out-of-cluster data
+// sources at evaluation time. A Provider resource names an endpoint and
+// transport; Rego reaches it through the external_data builtin, which
+// sends the requested keys as a batch and returns the provider's
+// responses and per-key errors. Providers speak either HTTP+JSON or
+// gRPC — the latter skips per-request connection setup and HTTP
+// framing for high-throughput providers; messages travel as JSON via
+// the same registered codec the gRPC decision service uses, so no
+// generated protobuf code is required on either side.
+package externaldata
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/url"
+	"sync"
+
+	externaldatav1alpha1 "github.com/open-policy-agent/gatekeeper/apis/externaldata/v1alpha1"
+	"github.com/open-policy-agent/gatekeeper/pkg/logging"
+	"github.com/open-policy-agent/gatekeeper/pkg/tracing"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("external-data").WithValues(logging.Process, "external_data")
+
+var enableExternalData = flag.Bool("enable-external-data", false, "(alpha) allow templates to consult external data providers declared by Provider resources via the external_data builtin")
+
+// Enabled returns true if external data support was requested via
+// flags.
+func Enabled() bool {
+	return *enableExternalData
+}
+
+// +kubebuilder:rbac:groups=externaldata.gatekeeper.sh,resources=providers,verbs=get;list;watch
+
+// defaultTimeoutSeconds applies when a Provider does not set one.
+const defaultTimeoutSeconds = 3
+
+// Request is the batch of keys sent to a provider.
+type Request struct {
+	APIVersion string      `json:"apiVersion"`
+	Kind       string      `json:"kind"`
+	Request    RequestBody `json:"request"`
+}
+
+// RequestBody carries the keys to look up.
+type RequestBody struct {
+	Keys []string `json:"keys"`
+}
+
+// Response is what a provider returns.
+type Response struct {
+	APIVersion string       `json:"apiVersion,omitempty"`
+	Kind       string       `json:"kind,omitempty"`
+	Response   ResponseBody `json:"response"`
+}
+
+// ResponseBody carries per-key results and any provider-wide error.
+type ResponseBody struct {
+	Items       []Item `json:"items,omitempty"`
+	SystemError string `json:"systemError,omitempty"`
+}
+
+// Item is one key's result.
+type Item struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value,omitempty"`
+	Error string      `json:"error,omitempty"`
+}
+
+// NewRequest builds the request envelope for a batch of keys.
+func NewRequest(keys []string) *Request {
+	return &Request{
+		APIVersion: externaldatav1alpha1.GroupVersion.String(),
+		Kind:       "ProviderRequest",
+		Request:    RequestBody{Keys: keys},
+	}
+}
+
+var (
+	mux    sync.Mutex
+	reader client.Reader
+)
+
+// Setup stores the reader the builtin resolves Provider resources with
+// and registers the external_data builtin. It must run before the OPA
+// client compiles any templates.
+func Setup(r client.Reader) {
+	mux.Lock()
+	reader = r
+	mux.Unlock()
+	registerOnce.Do(registerBuiltin)
+}
+
+var registerOnce sync.Once
+
+func registerBuiltin() {
+	rego.RegisterBuiltin1(
+		&rego.Function{
+			Name:    "external_data",
+			Decl:    types.NewFunction(types.Args(types.A), types.A),
+			Memoize: true,
+		},
+		func(bctx rego.BuiltinContext, op *ast.Term) (*ast.Term, error) {
+			return builtinImpl(bctx.Context, op)
+		},
+	)
+}
+
+// builtinImpl resolves external_data({"provider": ..., "keys": [...]}).
+// Transport and provider errors are returned in the result's
+// system_error field rather than failing evaluation, so templates
+// decide whether missing data is a violation.
+func builtinImpl(ctx context.Context, op *ast.Term) (*ast.Term, error) {
+	raw, err := ast.JSON(op.Value)
+	if err != nil {
+		return nil, err
+	}
+	input, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("external_data expects an object with provider and keys")
+	}
+	providerName, _ := input["provider"].(string)
+	if providerName == "" {
+		return nil, fmt.Errorf("external_data requires a provider name")
+	}
+	var keys []string
+	if rawKeys, ok := input["keys"].([]interface{}); ok {
+		for _, k := range rawKeys {
+			if s, ok := k.(string); ok {
+				keys = append(keys, s)
+			}
+		}
+	}
+
+	result := map[string]interface{}{
+		"responses":    []interface{}{},
+		"errors":       []interface{}{},
+		"system_error": "",
+	}
+	var items []Item
+	if b := batcherFrom(ctx); b != nil {
+		items, err = b.Query(ctx, providerName, keys)
+	} else {
+		items, err = QueryByName(ctx, providerName, keys)
+	}
+	if err != nil {
+		log.Error(err, "external data query failed", "provider", providerName)
+		result["system_error"] = err.Error()
+	} else {
+		var responses, errors []interface{}
+		for _, item := range items {
+			if item.Error != "" {
+				errors = append(errors, []interface{}{item.Key, item.Error})
+				continue
+			}
+			responses = append(responses, []interface{}{item.Key, item.Value})
+		}
+		if responses != nil {
+			result["responses"] = responses
+		}
+		if errors != nil {
+			result["errors"] = errors
+		}
+	}
+
+	value, err := ast.InterfaceToValue(result)
+	if err != nil {
+		return nil, err
+	}
+	return ast.NewTerm(value), nil
+}
+
+// QueryByName resolves the named Provider and queries it for the keys.
+func QueryByName(ctx context.Context, name string, keys []string) ([]Item, error) {
+	mux.Lock()
+	r := reader
+	mux.Unlock()
+	if r == nil {
+		return nil, fmt.Errorf("external data is not set up")
+	}
+	provider := &externaldatav1alpha1.Provider{}
+	if err := r.Get(ctx, k8stypes.NamespacedName{Name: name}, provider); err != nil {
+		return nil, fmt.Errorf("fetching provider %s: %w", name, err)
+	}
+	return Query(ctx, provider, keys)
+}
+
+// Query sends the keys to the provider over its declared transport.
+func Query(ctx context.Context, provider *externaldatav1alpha1.Provider, keys []string) ([]Item, error) {
+	ctx, span := tracing.StartSpan(ctx, "externaldata.query",
+		tracing.Attribute("provider", provider.GetName()),
+		tracing.Attribute("protocol", string(provider.Spec.Protocol)),
+	)
+	defer span.End()
+	timeout := provider.Spec.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeoutSeconds
+	}
+	auth, err := resolveAuth(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+	switch provider.Spec.Protocol {
+	case externaldatav1alpha1.ProtocolGRPC:
+		return queryGRPC(ctx, provider, keys, timeout, auth)
+	case externaldatav1alpha1.ProtocolHTTP, "":
+		return queryHTTP(ctx, provider, keys, timeout, auth)
+	default:
+		return nil, fmt.Errorf("provider %s declares unsupported protocol %q", provider.GetName(), provider.Spec.Protocol)
+	}
+}
+
+// ValidateProvider checks a Provider for the mistakes that would
+// otherwise only surface as failed lookups at evaluation time.
+func ValidateProvider(provider *externaldatav1alpha1.Provider) error {
+	if provider.Spec.URL == "" {
+		return fmt.Errorf("Provider %s must specify url", provider.GetName())
+	}
+	switch provider.Spec.Protocol {
+	case externaldatav1alpha1.ProtocolHTTP, "":
+		u, err := url.Parse(provider.Spec.URL)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("Provider %s url must be an http or https URL", provider.GetName())
+		}
+		if u.Scheme == "https" && provider.Spec.CABundle == "" {
+			return fmt.Errorf("Provider %s uses https and must specify caBundle", provider.GetName())
+		}
+	case externaldatav1alpha1.ProtocolGRPC:
+		if _, _, err := grpcTarget(provider.Spec.URL); err != nil {
+			return fmt.Errorf("Provider %s: %v", provider.GetName(), err)
+		}
+	default:
+		return fmt.Errorf("Provider %s protocol must be HTTP or GRPC, got %q", provider.GetName(), provider.Spec.Protocol)
+	}
+	if provider.Spec.Timeout < 0 {
+		return fmt.Errorf("Provider %s timeout must not be negative", provider.GetName())
+	}
+	if provider.Spec.CABundle != "" {
+		if _, err := certPool(provider.Spec.CABundle); err != nil {
+			return fmt.Errorf("Provider %s: %v", provider.GetName(), err)
+		}
+	}
+	if auth := provider.Spec.Auth; auth != nil {
+		if auth.ClientCertSecretRef == nil && auth.BearerTokenSecretRef == nil {
+			return fmt.Errorf("Provider %s auth must specify clientCertSecretRef or bearerTokenSecretRef", provider.GetName())
+		}
+		for _, ref := range []*externaldatav1alpha1.SecretRef{auth.ClientCertSecretRef, auth.BearerTokenSecretRef} {
+			if ref != nil && ref.Name == "" {
+				return fmt.Errorf("Provider %s auth secret references must specify name", provider.GetName())
+			}
+		}
+		if auth.ClientCertSecretRef != nil && provider.Spec.CABundle == "" {
+			return fmt.Errorf("Provider %s presents a client certificate and must specify caBundle", provider.GetName())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+package externaldata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	externaldatav1alpha1 "github.com/open-policy-agent/gatekeeper/apis/externaldata/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func provider(name string, spec externaldatav1alpha1.ProviderSpec) *externaldatav1alpha1.Provider {
+	return &externaldatav1alpha1.Provider{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       spec,
+	}
+}
+
+func TestValidateProvider(t *testing.T) {
+	tc := []struct {
+		name    string
+		spec    externaldatav1alpha1.ProviderSpec
+		wantErr bool
+	}{
+		{
+			name: "valid http",
+			spec: externaldatav1alpha1.ProviderSpec{URL: "http://provider.example:8080/validate"},
+		},
+		{
+			name:    "missing url",
+			spec:    externaldatav1alpha1.ProviderSpec{},
+			wantErr: true,
+		},
+		{
+			name:    "http url without scheme",
+			spec:    externaldatav1alpha1.ProviderSpec{URL: "provider.example:8080"},
+			wantErr: true,
+		},
+		{
+			name:    "https without caBundle",
+			spec:    externaldatav1alpha1.ProviderSpec{URL: "https://provider.example"},
+			wantErr: true,
+		},
+		{
+			name: "valid grpc",
+			spec: externaldatav1alpha1.ProviderSpec{URL: "provider.example:9090", Protocol: externaldatav1alpha1.ProtocolGRPC},
+		},
+		{
+			name: "grpc with scheme",
+			spec: externaldatav1alpha1.ProviderSpec{URL: "grpc://provider.example:9090", Protocol: externaldatav1alpha1.ProtocolGRPC},
+		},
+		{
+			name:    "grpc with http scheme",
+			spec:    externaldatav1alpha1.ProviderSpec{URL: "http://provider.example:9090", Protocol: externaldatav1alpha1.ProtocolGRPC},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported protocol",
+			spec:    externaldatav1alpha1.ProviderSpec{URL: "http://provider.example", Protocol: "TCP"},
+			wantErr: true,
+		},
+		{
+			name:    "negative timeout",
+			spec:    externaldatav1alpha1.ProviderSpec{URL: "http://provider.example", Timeout: -1},
+			wantErr: true,
+		},
+		{
+			name:    "caBundle not base64",
+			spec:    externaldatav1alpha1.ProviderSpec{URL: "http://provider.example", CABundle: "not base64!"},
+			wantErr: true,
+		},
+		{
+			name: "valid bearer token auth",
+			spec: externaldatav1alpha1.ProviderSpec{
+				URL:  "http://provider.example",
+				Auth: &externaldatav1alpha1.ProviderAuth{BearerTokenSecretRef: &externaldatav1alpha1.SecretRef{Name: "provider-token"}},
+			},
+		},
+		{
+			name: "empty auth",
+			spec: externaldatav1alpha1.ProviderSpec{
+				URL:  "http://provider.example",
+				Auth: &externaldatav1alpha1.ProviderAuth{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "auth secret ref without name",
+			spec: externaldatav1alpha1.ProviderSpec{
+				URL:  "http://provider.example",
+				Auth: &externaldatav1alpha1.ProviderAuth{BearerTokenSecretRef: &externaldatav1alpha1.SecretRef{Namespace: "gatekeeper-system"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "client cert without caBundle",
+			spec: externaldatav1alpha1.ProviderSpec{
+				URL:  "http://provider.example",
+				Auth: &externaldatav1alpha1.ProviderAuth{ClientCertSecretRef: &externaldatav1alpha1.SecretRef{Name: "provider-client-cert"}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateProvider(provider("test-provider", tt.spec))
+			if tt.wantErr && err == nil {
+				t.Errorf("expected error, got none")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestGRPCTarget(t *testing.T) {
+	tc := []struct {
+		raw        string
+		want       string
+		wantSecure bool
+		wantErr    bool
+	}{
+		{raw: "provider.example:9090", want: "provider.example:9090"},
+		{raw: "grpc://provider.example:9090", want: "provider.example:9090"},
+		{raw: "grpcs://provider.example:9090", want: "provider.example:9090", wantSecure: true},
+		{raw: "http://provider.example:9090", wantErr: true},
+		{raw: "", wantErr: true},
+	}
+	for _, tt := range tc {
+		target, secure, err := grpcTarget(tt.raw)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("grpcTarget(%q): expected error, got none", tt.raw)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("grpcTarget(%q): unexpected error: %v", tt.raw, err)
+			continue
+		}
+		if target != tt.want || secure != tt.wantSecure {
+			t.Errorf("grpcTarget(%q) = %q, %v; want %q, %v", tt.raw, target, secure, tt.want, tt.wantSecure)
+		}
+	}
+}
+
+func TestQueryHTTP(t *testing.T) {
+	var gotKeys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &Request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		gotKeys = req.Request.Keys
+		resp := &Response{
+			Response: ResponseBody{
+				Items: []Item{
+					{Key: "a", Value: "valid"},
+					{Key: "b", Error: "not found"},
+				},
+			},
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Errorf("encoding response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	p := provider("test-provider", externaldatav1alpha1.ProviderSpec{URL: server.URL})
+	items, err := Query(context.Background(), p, []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(gotKeys) != 2 || gotKeys[0] != "a" || gotKeys[1] != "b" {
+		t.Errorf("provider received keys %v, want [a b]", gotKeys)
+	}
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2", len(items))
+	}
+	if items[0].Key != "a" || items[0].Value != "valid" {
+		t.Errorf("unexpected first item: %+v", items[0])
+	}
+	if items[1].Key != "b" || items[1].Error != "not found" {
+		t.Errorf("unexpected second item: %+v", items[1])
+	}
+}
+
+func TestQueryHTTPSystemError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := &Response{Response: ResponseBody{SystemError: "backend unavailable"}}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Errorf("encoding response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	p := provider("test-provider", externaldatav1alpha1.ProviderSpec{URL: server.URL})
+	if _, err := Query(context.Background(), p, []string{"a"}); err == nil {
+		t.Errorf("expected error for system error response, got none")
+	}
+}
+
+func TestNewRequest(t *testing.T) {
+	req := NewRequest([]string{"a"})
+	if req.APIVersion != "externaldata.gatekeeper.sh/v1alpha1" {
+		t.Errorf("unexpected apiVersion %q", req.APIVersion)
+	}
+	if req.Kind != "ProviderRequest" {
+		t.Errorf("unexpected kind %q", req.Kind)
+	}
+	if len(req.Request.Keys) != 1 || req.Request.Keys[0] != "a" {
+		t.Errorf("unexpected keys %v", req.Request.Keys)
+	}
+}
+
+// fakeReader serves one Secret, standing in for the manager's cache.
+type fakeReader struct {
+	secret *corev1.Secret
+}
+
+func (f *fakeReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok || f.secret == nil || key.Name != f.secret.Name || key.Namespace != f.secret.Namespace {
+		return fmt.Errorf("not found: %v", key)
+	}
+	f.secret.DeepCopyInto(secret)
+	return nil
+}
+
+func (f *fakeReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return nil
+}
+
+func TestQueryHTTPBearerToken(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		if err := json.NewEncoder(w).Encode(&Response{}); err != nil {
+			t.Errorf("encoding response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	tokenSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "provider-token", Namespace: "gatekeeper-system"},
+		Data:       map[string][]byte{"token": []byte("s3cr3t")},
+	}
+	Setup(&fakeReader{secret: tokenSecret})
+	defer Setup(nil)
+
+	p := provider("test-provider", externaldatav1alpha1.ProviderSpec{
+		URL: server.URL,
+		Auth: &externaldatav1alpha1.ProviderAuth{
+			BearerTokenSecretRef: &externaldatav1alpha1.SecretRef{Name: "provider-token"},
+		},
+	})
+	if _, err := Query(context.Background(), p, []string{"a"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotAuth != "Bearer s3cr3t" {
+		t.Errorf("Authorization = %q, want Bearer s3cr3t", gotAuth)
+	}
+
+	// a rotated Secret takes effect on the next query
+	tokenSecret.Data["token"] = []byte("rotated")
+	if _, err := Query(context.Background(), p, []string{"a"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotAuth != "Bearer rotated" {
+		t.Errorf("Authorization after rotation = %q, want Bearer rotated", gotAuth)
+	}
+}
+
+func TestQueryHTTPMissingTokenSecret(t *testing.T) {
+	Setup(&fakeReader{})
+	defer Setup(nil)
+	p := provider("test-provider", externaldatav1alpha1.ProviderSpec{
+		URL: "http://provider.example",
+		Auth: &externaldatav1alpha1.ProviderAuth{
+			BearerTokenSecretRef: &externaldatav1alpha1.SecretRef{Name: "absent"},
+		},
+	})
+	if _, err := Query(context.Background(), p, []string{"a"}); err == nil {
+		t.Error("expected an error when the token secret is missing")
+	}
+}
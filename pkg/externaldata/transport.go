@@ -0,0 +1,173 @@
+package externaldata
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	externaldatav1alpha1 "github.com/open-policy-agent/gatekeeper/apis/externaldata/v1alpha1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/encoding"
+)
+
+// ServiceName is the fully qualified gRPC service a gRPC provider must
+// implement; the single Query method takes a Request and returns a
+// Response, as JSON via the "json" content subtype.
+const ServiceName = "externaldata.gatekeeper.sh.v1alpha1.Provider"
+
+// jsonCodec carries request and response messages as JSON, matching
+// the decision service's codec.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                               { return "json" }
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// queryHTTP POSTs the keys to the provider as JSON.
+func queryHTTP(ctx context.Context, provider *externaldatav1alpha1.Provider, keys []string, timeoutSeconds int, auth *clientAuth) ([]Item, error) {
+	body, err := json.Marshal(NewRequest(keys))
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutSeconds)*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, provider.Spec.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if auth != nil && auth.bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+auth.bearer)
+	}
+
+	httpClient := &http.Client{}
+	if provider.Spec.CABundle != "" {
+		cfg, err := tlsConfigFor(provider.Spec.CABundle, auth)
+		if err != nil {
+			return nil, err
+		}
+		httpClient.Transport = &http.Transport{TLSClientConfig: cfg}
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("querying provider %s: %w", provider.GetName(), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("provider %s returned status %d", provider.GetName(), resp.StatusCode)
+	}
+	raw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return decodeResponse(provider.GetName(), raw)
+}
+
+// queryGRPC sends the keys as a unary call to the provider's Query
+// method.
+func queryGRPC(ctx context.Context, provider *externaldatav1alpha1.Provider, keys []string, timeoutSeconds int, auth *clientAuth) ([]Item, error) {
+	target, secure, err := grpcTarget(provider.Spec.URL)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutSeconds)*time.Second)
+	defer cancel()
+
+	opts := []grpc.DialOption{grpc.WithBlock()}
+	switch {
+	case provider.Spec.CABundle != "":
+		cfg, err := tlsConfigFor(provider.Spec.CABundle, auth)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(cfg)))
+	case secure:
+		return nil, fmt.Errorf("provider %s uses grpcs:// and must specify caBundle", provider.GetName())
+	default:
+		opts = append(opts, grpc.WithInsecure())
+	}
+	if auth != nil && auth.bearer != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerCreds(auth.bearer)))
+	}
+
+	conn, err := grpc.DialContext(ctx, target, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("dialing provider %s: %w", provider.GetName(), err)
+	}
+	defer conn.Close()
+
+	out := &Response{}
+	err = conn.Invoke(ctx, "/"+ServiceName+"/Query", NewRequest(keys), out, grpc.CallContentSubtype("json"))
+	if err != nil {
+		return nil, fmt.Errorf("querying provider %s: %w", provider.GetName(), err)
+	}
+	if out.Response.SystemError != "" {
+		return nil, fmt.Errorf("provider %s: %s", provider.GetName(), out.Response.SystemError)
+	}
+	return out.Response.Items, nil
+}
+
+// bearerCreds attaches a bearer token to every call. Transport
+// security is enforced separately via the caBundle rules, so tokens
+// over plaintext connections remain possible for in-cluster providers.
+type bearerCreds string
+
+func (b bearerCreds) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(b)}, nil
+}
+
+func (b bearerCreds) RequireTransportSecurity() bool { return false }
+
+// grpcTarget strips the optional grpc:// or grpcs:// scheme and
+// reports whether transport security was requested.
+func grpcTarget(raw string) (string, bool, error) {
+	switch {
+	case strings.HasPrefix(raw, "grpcs://"):
+		return strings.TrimPrefix(raw, "grpcs://"), true, nil
+	case strings.HasPrefix(raw, "grpc://"):
+		return strings.TrimPrefix(raw, "grpc://"), false, nil
+	case strings.Contains(raw, "://"):
+		return "", false, fmt.Errorf("gRPC provider url must be host:port, grpc://host:port, or grpcs://host:port, got %q", raw)
+	case raw == "":
+		return "", false, fmt.Errorf("gRPC provider url must not be empty")
+	default:
+		return raw, false, nil
+	}
+}
+
+func decodeResponse(providerName string, raw []byte) ([]Item, error) {
+	out := &Response{}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return nil, fmt.Errorf("decoding response from provider %s: %w", providerName, err)
+	}
+	if out.Response.SystemError != "" {
+		return nil, fmt.Errorf("provider %s: %s", providerName, out.Response.SystemError)
+	}
+	return out.Response.Items, nil
+}
+
+// certPool decodes a base64 PEM bundle into a pool.
+func certPool(caBundle string) (*x509.CertPool, error) {
+	pem, err := base64.StdEncoding.DecodeString(caBundle)
+	if err != nil {
+		return nil, fmt.Errorf("caBundle is not valid base64: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("caBundle contains no certificates")
+	}
+	return pool, nil
+}
@@ -0,0 +1,59 @@
+package fips
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+func TestSelfTest(t *testing.T) {
+	if err := SelfTest(); err != nil {
+		t.Errorf("self-test failed: %s", err)
+	}
+}
+
+func TestApplyToDefaults(t *testing.T) {
+	cfg := &tls.Config{}
+	ApplyTo(cfg)
+	if cfg.MinVersion != tls.VersionTLS12 {
+		t.Errorf("MinVersion = %#x, want %#x", cfg.MinVersion, tls.VersionTLS12)
+	}
+	if len(cfg.CipherSuites) != len(approvedCiphers) {
+		t.Errorf("expected the full approved cipher set, got %v", cfg.CipherSuites)
+	}
+	for _, c := range cfg.CurvePreferences {
+		if c == tls.X25519 {
+			t.Errorf("X25519 must not be offered in FIPS mode")
+		}
+	}
+}
+
+func TestApplyToIntersectsConfiguredSuites(t *testing.T) {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS10,
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_RSA_WITH_RC4_128_SHA,
+		},
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP384},
+	}
+	ApplyTo(cfg)
+	if cfg.MinVersion != tls.VersionTLS12 {
+		t.Errorf("TLS 1.0 must be raised to 1.2, got %#x", cfg.MinVersion)
+	}
+	if len(cfg.CipherSuites) != 1 || cfg.CipherSuites[0] != tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384 {
+		t.Errorf("unapproved suites should be dropped, got %v", cfg.CipherSuites)
+	}
+	if len(cfg.CurvePreferences) != 1 || cfg.CurvePreferences[0] != tls.CurveP384 {
+		t.Errorf("unapproved curves should be dropped, got %v", cfg.CurvePreferences)
+	}
+}
+
+func TestApplyToFallsBackWhenNothingApproved(t *testing.T) {
+	cfg := &tls.Config{
+		CipherSuites: []uint16{tls.TLS_RSA_WITH_RC4_128_SHA},
+	}
+	ApplyTo(cfg)
+	if len(cfg.CipherSuites) != len(approvedCiphers) {
+		t.Errorf("an all-unapproved configuration should fall back to the approved set, got %v", cfg.CipherSuites)
+	}
+}
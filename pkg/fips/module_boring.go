@@ -0,0 +1,11 @@
+//go:build boringcrypto
+// +build boringcrypto
+
+package fips
+
+// Builds compiled with the boringcrypto toolchain route the primitives
+// used here through the FIPS 140-2 validated BoringCrypto module.
+const (
+	moduleAvailable = true
+	moduleName      = "BoringCrypto"
+)
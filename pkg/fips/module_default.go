@@ -0,0 +1,11 @@
+//go:build !boringcrypto
+// +build !boringcrypto
+
+package fips
+
+// Standard builds enforce the approved algorithm set with Go's library
+// crypto; no validated module is linked.
+const (
+	moduleAvailable = false
+	moduleName      = "Go standard library crypto"
+)
@@ -0,0 +1,136 @@
+// Package gator evaluates objects against policies outside the cluster.
+// Besides constraint templates and constraints it understands plain Rego
+// policy directories following Conftest conventions — deny, violation,
+// and warn rules in any package — easing migration for teams with
+// existing Conftest policy repos.
+package gator
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+)
+
+// ruleNames are the Conftest rule conventions, in the order results are
+// reported.
+var ruleNames = []string{"deny", "violation", "warn"}
+
+// RegoViolation is one finding from a plain Rego policy.
+type RegoViolation struct {
+	// Package is the Rego package the rule lives in.
+	Package string `json:"package"`
+	// Rule is the rule name that produced the finding: deny, violation,
+	// or warn.
+	Rule string `json:"rule"`
+	// Message is the finding's message.
+	Message string `json:"message"`
+}
+
+// Warning reports whether the finding came from a warn rule, which does
+// not fail the evaluation.
+func (v *RegoViolation) Warning() bool {
+	return v.Rule == "warn"
+}
+
+// RegoPolicies is a compiled set of Conftest-convention policies.
+type RegoPolicies struct {
+	compiler *ast.Compiler
+	packages []string
+}
+
+// LoadRegoPolicies compiles every .rego file under dir, skipping tests.
+func LoadRegoPolicies(dir string) (*RegoPolicies, error) {
+	modules := map[string]string{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".rego") || strings.HasSuffix(path, "_test.rego") {
+			return nil
+		}
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		modules[path] = string(raw)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("no policies found in %s", dir)
+	}
+	compiler, err := ast.CompileModules(modules)
+	if err != nil {
+		return nil, fmt.Errorf("compiling policies: %w", err)
+	}
+
+	seen := map[string]bool{}
+	var packages []string
+	for _, module := range compiler.Modules {
+		path := strings.TrimPrefix(module.Package.Path.String(), "data.")
+		if !seen[path] {
+			seen[path] = true
+			packages = append(packages, path)
+		}
+	}
+	sort.Strings(packages)
+	return &RegoPolicies{compiler: compiler, packages: packages}, nil
+}
+
+// Eval runs the object through every deny, violation, and warn rule in
+// the policy set.
+func (p *RegoPolicies) Eval(ctx context.Context, input interface{}) ([]RegoViolation, error) {
+	var violations []RegoViolation
+	for _, pkg := range p.packages {
+		for _, rule := range ruleNames {
+			query := fmt.Sprintf("data.%s.%s", pkg, rule)
+			results, err := rego.New(
+				rego.Query(query),
+				rego.Compiler(p.compiler),
+				rego.Input(input),
+			).Eval(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("evaluating %s: %w", query, err)
+			}
+			for _, result := range results {
+				for _, expr := range result.Expressions {
+					for _, message := range messagesOf(expr.Value) {
+						violations = append(violations, RegoViolation{Package: pkg, Rule: rule, Message: message})
+					}
+				}
+			}
+		}
+	}
+	return violations, nil
+}
+
+// messagesOf extracts finding messages from a rule's value. Conftest
+// rules produce either a set of strings or a set of objects with a msg
+// field.
+func messagesOf(value interface{}) []string {
+	entries, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+	var messages []string
+	for _, entry := range entries {
+		switch v := entry.(type) {
+		case string:
+			messages = append(messages, v)
+		case map[string]interface{}:
+			if msg, ok := v["msg"].(string); ok {
+				messages = append(messages, msg)
+			}
+		}
+	}
+	return messages
+}
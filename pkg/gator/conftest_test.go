@@ -0,0 +1,110 @@
+package gator
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+const denyPolicy = `package main
+
+deny[msg] {
+	input.kind == "Deployment"
+	not input.spec.replicas
+	msg := "deployments must set replicas"
+}
+
+warn[msg] {
+	not input.metadata.labels.owner
+	msg := "resources should carry an owner label"
+}
+`
+
+const violationPolicy = `package k8s.labels
+
+violation[{"msg": msg}] {
+	not input.metadata.labels.team
+	msg := "resources must carry a team label"
+}
+`
+
+func writePolicies(t *testing.T, policies map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range policies {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("writing policy: %s", err)
+		}
+	}
+	return dir
+}
+
+func TestEvalConftestConventions(t *testing.T) {
+	dir := writePolicies(t, map[string]string{
+		"main.rego":   denyPolicy,
+		"labels.rego": violationPolicy,
+	})
+	policies, err := LoadRegoPolicies(dir)
+	if err != nil {
+		t.Fatalf("loading policies: %s", err)
+	}
+
+	input := map[string]interface{}{
+		"kind":     "Deployment",
+		"metadata": map[string]interface{}{"name": "nginx"},
+		"spec":     map[string]interface{}{},
+	}
+	violations, err := policies.Eval(context.Background(), input)
+	if err != nil {
+		t.Fatalf("evaluating: %s", err)
+	}
+	byRule := map[string]int{}
+	warnings := 0
+	for _, v := range violations {
+		byRule[v.Rule]++
+		if v.Warning() {
+			warnings++
+		}
+	}
+	if byRule["deny"] != 1 || byRule["violation"] != 1 || byRule["warn"] != 1 {
+		t.Errorf("violations by rule = %v, want one each of deny, violation, warn", byRule)
+	}
+	if warnings != 1 {
+		t.Errorf("warnings = %d, want 1", warnings)
+	}
+}
+
+func TestEvalCleanObject(t *testing.T) {
+	dir := writePolicies(t, map[string]string{"main.rego": denyPolicy})
+	policies, err := LoadRegoPolicies(dir)
+	if err != nil {
+		t.Fatalf("loading policies: %s", err)
+	}
+	input := map[string]interface{}{
+		"kind": "Deployment",
+		"metadata": map[string]interface{}{
+			"name":   "nginx",
+			"labels": map[string]interface{}{"owner": "team-a"},
+		},
+		"spec": map[string]interface{}{"replicas": 3},
+	}
+	violations, err := policies.Eval(context.Background(), input)
+	if err != nil {
+		t.Fatalf("evaluating: %s", err)
+	}
+	if len(violations) != 0 {
+		t.Errorf("violations = %v, want none", violations)
+	}
+}
+
+func TestLoadRegoPoliciesErrors(t *testing.T) {
+	if _, err := LoadRegoPolicies(t.TempDir()); err == nil {
+		t.Errorf("empty directories should be rejected")
+	}
+	dir := writePolicies(t, map[string]string{"broken.rego": "package broken\ndeny[msg] {"})
+	if _, err := LoadRegoPolicies(dir); err == nil {
+		t.Errorf("uncompilable policies should be rejected")
+	}
+}
@@ -0,0 +1,299 @@
+package gator
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/topdown"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// TemplateCoverage reports which rules and lines of one constraint
+// template's Rego the suite exercised. A rule counts as covered when at
+// least one evaluation of it succeeded; a line counts as covered when
+// the expression on it was reached at all, so a violation body that is
+// entered but never fires shows covered lines under a missed rule.
+type TemplateCoverage struct {
+	Template     string   `json:"template"`
+	CoveredRules []string `json:"coveredRules,omitempty"`
+	MissedRules  []string `json:"missedRules,omitempty"`
+	CoveredLines []int    `json:"coveredLines,omitempty"`
+	MissedLines  []int    `json:"missedLines,omitempty"`
+	Percent      float64  `json:"percent"`
+}
+
+// CoverageSet evaluates constraint template Rego directly, with a
+// tracer attached, against the same objects the policy client reviews.
+// The framework client offers no hook for tracing, so coverage runs are
+// a parallel evaluation rather than instrumentation of the real one.
+type CoverageSet struct {
+	compiler *ast.Compiler
+	sources  map[string]string        // template name -> rego source
+	packages map[string]string        // template name -> package path
+	params   map[string][]interface{} // template kind -> parameter sets from constraints
+	kinds    map[string]string        // template name -> CRD kind
+	tracer   *coverageTracer
+}
+
+// coverageTracer records which source locations evaluation touched.
+type coverageTracer struct {
+	ruleHits map[string]map[int]bool // file -> rule head lines that exited true
+	lineHits map[string]map[int]bool // file -> expression lines reached
+}
+
+func (t *coverageTracer) Enabled() bool { return true }
+
+func (t *coverageTracer) Config() topdown.TraceConfig { return topdown.TraceConfig{} }
+
+func (t *coverageTracer) TraceEvent(evt topdown.Event) {
+	switch evt.Op {
+	case topdown.ExitOp:
+		if rule, ok := evt.Node.(*ast.Rule); ok && rule.Head.Location != nil {
+			mark(t.ruleHits, rule.Head.Location.File, rule.Head.Location.Row)
+		}
+	case topdown.EvalOp:
+		if expr, ok := evt.Node.(*ast.Expr); ok && expr.Location != nil {
+			mark(t.lineHits, expr.Location.File, expr.Location.Row)
+		}
+	}
+}
+
+func mark(hits map[string]map[int]bool, file string, row int) {
+	if hits[file] == nil {
+		hits[file] = map[int]bool{}
+	}
+	hits[file][row] = true
+}
+
+// LoadCoverageSet compiles the Rego of every constraint template under
+// templatesDir. When constraintsDir is non-empty, each constraint's
+// spec.parameters becomes one parameter set for its template, so
+// coverage reflects the branches the configured constraints can reach.
+func LoadCoverageSet(templatesDir, constraintsDir string) (*CoverageSet, error) {
+	set := &CoverageSet{
+		sources:  map[string]string{},
+		packages: map[string]string{},
+		params:   map[string][]interface{}{},
+		kinds:    map[string]string{},
+		tracer: &coverageTracer{
+			ruleHits: map[string]map[int]bool{},
+			lineHits: map[string]map[int]bool{},
+		},
+	}
+	err := eachYAMLFile(templatesDir, func(obj *unstructured.Unstructured) error {
+		if obj.GetKind() != "ConstraintTemplate" {
+			return nil
+		}
+		kind, _, _ := unstructured.NestedString(obj.Object, "spec", "crd", "spec", "names", "kind")
+		targets, _, _ := unstructured.NestedSlice(obj.Object, "spec", "targets")
+		for _, t := range targets {
+			target, ok := t.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if regoSrc, ok := target["rego"].(string); ok && regoSrc != "" {
+				set.sources[obj.GetName()] = regoSrc
+				set.kinds[obj.GetName()] = kind
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(set.sources) == 0 {
+		return nil, fmt.Errorf("no constraint templates found in %s", templatesDir)
+	}
+	set.compiler, err = ast.CompileModules(set.sources)
+	if err != nil {
+		return nil, fmt.Errorf("compiling template rego: %w", err)
+	}
+	for name, module := range set.compiler.Modules {
+		set.packages[name] = strings.TrimPrefix(module.Package.Path.String(), "data.")
+	}
+
+	if constraintsDir != "" {
+		err := eachYAMLFile(constraintsDir, func(obj *unstructured.Unstructured) error {
+			params, found, _ := unstructured.NestedFieldNoCopy(obj.Object, "spec", "parameters")
+			if !found {
+				params = map[string]interface{}{}
+			}
+			set.params[obj.GetKind()] = append(set.params[obj.GetKind()], params)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+// EvalObject runs the object through every template's violation rule,
+// once per configured parameter set, recording coverage. Results are
+// discarded; the policy client remains the source of truth for
+// verdicts.
+func (s *CoverageSet) EvalObject(ctx context.Context, object map[string]interface{}) error {
+	for name, pkg := range s.packages {
+		paramSets := s.params[s.kinds[name]]
+		if len(paramSets) == 0 {
+			paramSets = []interface{}{map[string]interface{}{}}
+		}
+		for _, params := range paramSets {
+			input := map[string]interface{}{
+				"review":     map[string]interface{}{"object": object},
+				"parameters": params,
+			}
+			_, err := rego.New(
+				rego.Query(fmt.Sprintf("data.%s.violation", pkg)),
+				rego.Compiler(s.compiler),
+				rego.Input(input),
+				rego.QueryTracer(s.tracer),
+			).Eval(ctx)
+			if err != nil {
+				return fmt.Errorf("evaluating coverage for %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Report summarizes coverage per template, sorted by template name.
+func (s *CoverageSet) Report() []TemplateCoverage {
+	var out []TemplateCoverage
+	for name, module := range s.compiler.Modules {
+		cov := TemplateCoverage{Template: name}
+		ruleLines := map[string][]int{} // rule name -> head lines of its definitions
+		lines := map[int]bool{}         // statable lines
+		ast.WalkRules(module, func(rule *ast.Rule) bool {
+			if rule.Head.Location == nil {
+				return false
+			}
+			ruleName := rule.Head.Name.String()
+			ruleLines[ruleName] = append(ruleLines[ruleName], rule.Head.Location.Row)
+			lines[rule.Head.Location.Row] = true
+			ast.WalkExprs(rule.Body, func(expr *ast.Expr) bool {
+				if expr.Location != nil {
+					lines[expr.Location.Row] = true
+				}
+				return false
+			})
+			return false
+		})
+
+		for ruleName, heads := range ruleLines {
+			covered := false
+			for _, row := range heads {
+				if s.tracer.ruleHits[name][row] {
+					covered = true
+				}
+			}
+			if covered {
+				cov.CoveredRules = append(cov.CoveredRules, ruleName)
+			} else {
+				cov.MissedRules = append(cov.MissedRules, ruleName)
+			}
+		}
+		for row := range lines {
+			if s.tracer.lineHits[name][row] || s.tracer.ruleHits[name][row] {
+				cov.CoveredLines = append(cov.CoveredLines, row)
+			} else {
+				cov.MissedLines = append(cov.MissedLines, row)
+			}
+		}
+		sort.Strings(cov.CoveredRules)
+		sort.Strings(cov.MissedRules)
+		sort.Ints(cov.CoveredLines)
+		sort.Ints(cov.MissedLines)
+		if len(lines) > 0 {
+			cov.Percent = float64(len(cov.CoveredLines)) / float64(len(lines)) * 100
+		}
+		out = append(out, cov)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Template < out[j].Template })
+	return out
+}
+
+// WriteHTML renders each template's source with covered lines
+// highlighted, in the style of go tool cover's output.
+func (s *CoverageSet) WriteHTML(w io.Writer) error {
+	report := s.Report()
+	if _, err := fmt.Fprint(w, "<!DOCTYPE html>\n<html><head><title>gator coverage</title><style>\n"+
+		"body { background: #1e1e1e; color: #d4d4d4; font-family: monospace; }\n"+
+		".covered { color: #4ec94e; }\n"+
+		".missed { color: #e05252; }\n"+
+		"</style></head><body>\n"); err != nil {
+		return err
+	}
+	for _, cov := range report {
+		covered := map[int]bool{}
+		for _, row := range cov.CoveredLines {
+			covered[row] = true
+		}
+		missed := map[int]bool{}
+		for _, row := range cov.MissedLines {
+			missed[row] = true
+		}
+		if _, err := fmt.Fprintf(w, "<h2>%s (%.1f%%)</h2>\n<pre>", html.EscapeString(cov.Template), cov.Percent); err != nil {
+			return err
+		}
+		for i, line := range strings.Split(s.sources[cov.Template], "\n") {
+			row := i + 1
+			class := ""
+			switch {
+			case covered[row]:
+				class = "covered"
+			case missed[row]:
+				class = "missed"
+			}
+			if class != "" {
+				fmt.Fprintf(w, "<span class=\"%s\">%s</span>\n", class, html.EscapeString(line))
+			} else {
+				fmt.Fprintf(w, "%s\n", html.EscapeString(line))
+			}
+		}
+		if _, err := fmt.Fprint(w, "</pre>\n"); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprint(w, "</body></html>\n")
+	return err
+}
+
+// eachYAMLFile decodes every YAML or JSON file directly under dir into
+// an unstructured object.
+func eachYAMLFile(dir string, fn func(*unstructured.Unstructured) error) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		ext := filepath.Ext(f.Name())
+		if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return err
+		}
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal(data, &obj.Object); err != nil {
+			return fmt.Errorf("%s: %w", f.Name(), err)
+		}
+		if err := fn(obj); err != nil {
+			return fmt.Errorf("%s: %w", f.Name(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package gator
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const coverageTemplate = `
+apiVersion: templates.gatekeeper.sh/v1beta1
+kind: ConstraintTemplate
+metadata:
+  name: k8srequiredlabels
+spec:
+  crd:
+    spec:
+      names:
+        kind: K8sRequiredLabels
+  targets:
+    - target: admission.k8s.gatekeeper.sh
+      rego: |
+        package k8srequiredlabels
+
+        violation[{"msg": msg}] {
+          provided := {label | input.review.object.metadata.labels[label]}
+          required := {label | label := input.parameters.labels[_]}
+          missing := required - provided
+          count(missing) > 0
+          msg := sprintf("missing labels: %v", [missing])
+        }
+
+        unreached[msg] {
+          input.review.object.kind == "NeverSeen"
+          msg := "unreachable"
+        }
+`
+
+const coverageConstraint = `
+apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sRequiredLabels
+metadata:
+  name: must-have-owner
+spec:
+  parameters:
+    labels: ["owner"]
+`
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func coverageFixtures(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	templatesDir := filepath.Join(dir, "templates")
+	constraintsDir := filepath.Join(dir, "constraints")
+	for path, content := range map[string]string{
+		filepath.Join(templatesDir, "template.yaml"):     coverageTemplate,
+		filepath.Join(constraintsDir, "constraint.yaml"): coverageConstraint,
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return templatesDir, constraintsDir
+}
+
+func TestCoverageViolationExercised(t *testing.T) {
+	templatesDir, constraintsDir := coverageFixtures(t)
+	set, err := LoadCoverageSet(templatesDir, constraintsDir)
+	if err != nil {
+		t.Fatalf("loading coverage set: %v", err)
+	}
+	object := map[string]interface{}{
+		"kind":     "Pod",
+		"metadata": map[string]interface{}{"name": "unlabeled"},
+	}
+	if err := set.EvalObject(context.Background(), object); err != nil {
+		t.Fatalf("evaluating object: %v", err)
+	}
+
+	report := set.Report()
+	if len(report) != 1 {
+		t.Fatalf("expected one template in the report, got %d", len(report))
+	}
+	cov := report[0]
+	if cov.Template != "k8srequiredlabels" {
+		t.Errorf("template = %q", cov.Template)
+	}
+	if !contains(cov.CoveredRules, "violation") {
+		t.Errorf("expected the violation rule to be covered, got %v", cov.CoveredRules)
+	}
+	if !contains(cov.MissedRules, "unreached") {
+		t.Errorf("expected the unreached rule to be missed, got %v", cov.MissedRules)
+	}
+	if cov.Percent <= 0 || cov.Percent >= 100 {
+		t.Errorf("expected partial coverage, got %.1f%%", cov.Percent)
+	}
+}
+
+func TestCoverageNothingExercised(t *testing.T) {
+	templatesDir, _ := coverageFixtures(t)
+	// without constraints there are no parameter sets, so the violation
+	// body is entered but never fires
+	set, err := LoadCoverageSet(templatesDir, "")
+	if err != nil {
+		t.Fatalf("loading coverage set: %v", err)
+	}
+	object := map[string]interface{}{
+		"kind":     "Pod",
+		"metadata": map[string]interface{}{"name": "labeled", "labels": map[string]interface{}{"owner": "me"}},
+	}
+	if err := set.EvalObject(context.Background(), object); err != nil {
+		t.Fatalf("evaluating object: %v", err)
+	}
+	cov := set.Report()[0]
+	if contains(cov.CoveredRules, "violation") {
+		t.Errorf("violation should not count as covered when it never fires, got %v", cov.CoveredRules)
+	}
+	if len(cov.CoveredLines) == 0 {
+		t.Error("expected the entered body lines to count as covered")
+	}
+}
+
+func TestCoverageHTML(t *testing.T) {
+	templatesDir, constraintsDir := coverageFixtures(t)
+	set, err := LoadCoverageSet(templatesDir, constraintsDir)
+	if err != nil {
+		t.Fatalf("loading coverage set: %v", err)
+	}
+	object := map[string]interface{}{
+		"kind":     "Pod",
+		"metadata": map[string]interface{}{"name": "unlabeled"},
+	}
+	if err := set.EvalObject(context.Background(), object); err != nil {
+		t.Fatalf("evaluating object: %v", err)
+	}
+	var sb strings.Builder
+	if err := set.WriteHTML(&sb); err != nil {
+		t.Fatalf("rendering html: %v", err)
+	}
+	out := sb.String()
+	if !strings.Contains(out, "k8srequiredlabels") {
+		t.Error("expected the template name in the report")
+	}
+	if !strings.Contains(out, `<span class="covered">`) || !strings.Contains(out, `<span class="missed">`) {
+		t.Error("expected both covered and missed lines to be highlighted")
+	}
+}
@@ -0,0 +1,368 @@
+package gator
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Bundle is the policy content extracted from an OCI artifact, sorted
+// into the directory layout the loaders expect.
+type Bundle struct {
+	// TemplatesDir holds the ConstraintTemplates found in the bundle.
+	TemplatesDir string
+	// ConstraintsDir holds everything else with a constraints.gatekeeper.sh
+	// apiVersion.
+	ConstraintsDir string
+}
+
+// PullImage fetches an OCI artifact containing ConstraintTemplates and
+// Constraints — as pushed by oras or similar tooling — and extracts its
+// policy content so CI can test against the exact published bundle.
+// Layers are either tarballs, which are unpacked, or raw YAML/JSON
+// files. Only anonymous and token-based pulls are supported.
+func PullImage(ctx context.Context, ref string) (*Bundle, error) {
+	host, repository, reference, err := parseRef(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	reg := &registryClient{host: host, repository: repository, client: http.DefaultClient}
+	manifest, err := reg.manifest(ctx, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs [][]byte
+	for _, layer := range manifest.Layers {
+		blob, err := reg.blob(ctx, layer.Digest)
+		if err != nil {
+			return nil, err
+		}
+		layerDocs, err := extractLayer(layer.MediaType, blob)
+		if err != nil {
+			return nil, fmt.Errorf("extracting layer %s: %w", layer.Digest, err)
+		}
+		docs = append(docs, layerDocs...)
+	}
+
+	return sortDocs(docs)
+}
+
+// parseRef splits an image reference into host, repository, and tag or
+// digest. The registry host is required — a bare repository name has no
+// unambiguous default in a CI context.
+func parseRef(ref string) (host, repository, reference string, err error) {
+	if i := strings.Index(ref, "@"); i >= 0 {
+		reference = ref[i+1:]
+		ref = ref[:i]
+		if !strings.HasPrefix(reference, "sha256:") {
+			return "", "", "", fmt.Errorf("digest %q must be of the form sha256:...", reference)
+		}
+	}
+	slash := strings.Index(ref, "/")
+	if slash < 0 {
+		return "", "", "", fmt.Errorf("image reference %q must include a registry host", ref)
+	}
+	host = ref[:slash]
+	rest := ref[slash+1:]
+	if !strings.ContainsAny(host, ".:") && host != "localhost" {
+		return "", "", "", fmt.Errorf("image reference %q must include a registry host", ref)
+	}
+	if reference == "" {
+		if i := strings.LastIndex(rest, ":"); i >= 0 {
+			reference = rest[i+1:]
+			rest = rest[:i]
+		} else {
+			reference = "latest"
+		}
+	} else if i := strings.LastIndex(rest, ":"); i >= 0 {
+		// a tag alongside a digest is allowed; the digest wins
+		rest = rest[:i]
+	}
+	if rest == "" || reference == "" {
+		return "", "", "", fmt.Errorf("invalid image reference %q", ref)
+	}
+	return host, rest, reference, nil
+}
+
+type ociManifest struct {
+	MediaType string     `json:"mediaType"`
+	Layers    []ociLayer `json:"layers"`
+}
+
+type ociLayer struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+}
+
+// registryClient speaks the registry v2 pull protocol with anonymous
+// bearer-token auth, which covers public images on ghcr.io, Docker Hub,
+// and similar registries.
+type registryClient struct {
+	host       string
+	repository string
+	client     *http.Client
+	token      string
+}
+
+// scheme is https except for local registries, which lets tests and
+// local development use plain HTTP.
+func (r *registryClient) scheme() string {
+	if r.host == "localhost" || strings.HasPrefix(r.host, "localhost:") || strings.HasPrefix(r.host, "127.0.0.1") {
+		return "http"
+	}
+	return "https"
+}
+
+func (r *registryClient) manifest(ctx context.Context, reference string) (*ociManifest, error) {
+	url := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", r.scheme(), r.host, r.repository, reference)
+	body, err := r.get(ctx, url, "application/vnd.oci.image.manifest.v1+json, application/vnd.docker.distribution.manifest.v2+json")
+	if err != nil {
+		return nil, fmt.Errorf("fetching manifest for %s/%s:%s: %w", r.host, r.repository, reference, err)
+	}
+	// digest-pinned pulls can and must check the manifest against the
+	// pin; tag pulls have nothing to check against until the layers
+	if strings.HasPrefix(reference, "sha256:") {
+		if err := verifyDigest(body, reference); err != nil {
+			return nil, fmt.Errorf("manifest for %s/%s: %w", r.host, r.repository, err)
+		}
+	}
+	manifest := &ociManifest{}
+	if err := json.Unmarshal(body, manifest); err != nil {
+		return nil, fmt.Errorf("decoding manifest: %w", err)
+	}
+	if strings.Contains(manifest.MediaType, "image.index") || strings.Contains(manifest.MediaType, "manifest.list") {
+		return nil, fmt.Errorf("%s/%s:%s is a multi-platform index; reference a manifest digest instead", r.host, r.repository, reference)
+	}
+	if len(manifest.Layers) == 0 {
+		return nil, fmt.Errorf("manifest for %s/%s:%s has no layers", r.host, r.repository, reference)
+	}
+	return manifest, nil
+}
+
+func (r *registryClient) blob(ctx context.Context, digest string) ([]byte, error) {
+	url := fmt.Sprintf("%s://%s/v2/%s/blobs/%s", r.scheme(), r.host, r.repository, digest)
+	body, err := r.get(ctx, url, "")
+	if err != nil {
+		return nil, fmt.Errorf("fetching blob %s: %w", digest, err)
+	}
+	if err := verifyDigest(body, digest); err != nil {
+		return nil, fmt.Errorf("blob %s: %w", digest, err)
+	}
+	return body, nil
+}
+
+// verifyDigest checks content against its expected sha256 digest, so a
+// tampering registry cannot substitute policy content.
+func verifyDigest(content []byte, digest string) error {
+	sum := sha256.Sum256(content)
+	if got := "sha256:" + hex.EncodeToString(sum[:]); got != digest {
+		return fmt.Errorf("digest mismatch: got %s, want %s", got, digest)
+	}
+	return nil
+}
+
+// get performs a GET, answering a bearer-token challenge once.
+func (r *registryClient) get(ctx context.Context, url, accept string) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		if r.token != "" {
+			req.Header.Set("Authorization", "Bearer "+r.token)
+		}
+		resp, err := r.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			challenge := resp.Header.Get("WWW-Authenticate")
+			resp.Body.Close()
+			if err := r.authorize(ctx, challenge); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("registry returned status %d", resp.StatusCode)
+		}
+		return ioutil.ReadAll(resp.Body)
+	}
+}
+
+// authorize answers a Bearer challenge by fetching an anonymous pull
+// token.
+func (r *registryClient) authorize(ctx context.Context, challenge string) error {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return fmt.Errorf("registry requires unsupported authentication: %q", challenge)
+	}
+	params := map[string]string{}
+	for _, part := range strings.Split(strings.TrimPrefix(challenge, "Bearer "), ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) == 2 {
+			params[kv[0]] = strings.Trim(kv[1], `"`)
+		}
+	}
+	realm := params["realm"]
+	if realm == "" {
+		return fmt.Errorf("registry auth challenge has no realm: %q", challenge)
+	}
+	url := fmt.Sprintf("%s?service=%s&scope=repository:%s:pull", realm, params["service"], r.repository)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetching pull token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+	}
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return fmt.Errorf("decoding pull token: %w", err)
+	}
+	r.token = token.Token
+	if r.token == "" {
+		r.token = token.AccessToken
+	}
+	if r.token == "" {
+		return fmt.Errorf("token endpoint returned no token")
+	}
+	return nil
+}
+
+// extractLayer returns the YAML documents a layer carries, unpacking
+// tarballs and splitting multi-document files.
+func extractLayer(mediaType string, blob []byte) ([][]byte, error) {
+	if len(blob) >= 2 && blob[0] == 0x1f && blob[1] == 0x8b {
+		gz, err := gzip.NewReader(bytes.NewReader(blob))
+		if err != nil {
+			return nil, err
+		}
+		blob, err = ioutil.ReadAll(gz)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if strings.Contains(mediaType, "tar") || looksLikeTar(blob) {
+		return extractTar(blob)
+	}
+	return splitDocs(blob), nil
+}
+
+func looksLikeTar(blob []byte) bool {
+	// "ustar" at offset 257 marks a POSIX tar header
+	return len(blob) > 262 && string(blob[257:262]) == "ustar"
+}
+
+func extractTar(blob []byte) ([][]byte, error) {
+	var docs [][]byte
+	tr := tar.NewReader(bytes.NewReader(blob))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		ext := filepath.Ext(hdr.Name)
+		if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+			continue
+		}
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, splitDocs(content)...)
+	}
+	return docs, nil
+}
+
+// splitDocs splits multi-document YAML, dropping empty documents.
+func splitDocs(content []byte) [][]byte {
+	var docs [][]byte
+	for _, doc := range strings.Split(string(content), "\n---") {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+		docs = append(docs, []byte(doc))
+	}
+	return docs
+}
+
+// sortDocs writes templates and constraints into separate directories
+// so the existing loaders can consume them; documents of other kinds
+// are ignored.
+func sortDocs(docs [][]byte) (*Bundle, error) {
+	root, err := ioutil.TempDir("", "gator-bundle-")
+	if err != nil {
+		return nil, err
+	}
+	bundle := &Bundle{
+		TemplatesDir:   filepath.Join(root, "templates"),
+		ConstraintsDir: filepath.Join(root, "constraints"),
+	}
+	for _, dir := range []string{bundle.TemplatesDir, bundle.ConstraintsDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+	templates := 0
+	constraints := 0
+	for _, doc := range docs {
+		meta := struct {
+			APIVersion string `json:"apiVersion"`
+			Kind       string `json:"kind"`
+		}{}
+		if err := yaml.Unmarshal(doc, &meta); err != nil {
+			continue
+		}
+		var path string
+		switch {
+		case meta.Kind == "ConstraintTemplate" && strings.HasPrefix(meta.APIVersion, "templates.gatekeeper.sh/"):
+			templates++
+			path = filepath.Join(bundle.TemplatesDir, fmt.Sprintf("template-%d.yaml", templates))
+		case strings.HasPrefix(meta.APIVersion, "constraints.gatekeeper.sh/"):
+			constraints++
+			path = filepath.Join(bundle.ConstraintsDir, fmt.Sprintf("constraint-%d.yaml", constraints))
+		default:
+			continue
+		}
+		if err := ioutil.WriteFile(path, doc, 0644); err != nil {
+			return nil, err
+		}
+	}
+	if templates == 0 {
+		return nil, fmt.Errorf("bundle contains no constraint templates")
+	}
+	return bundle, nil
+}
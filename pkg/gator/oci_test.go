@@ -0,0 +1,206 @@
+package gator
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseRef(t *testing.T) {
+	tc := []struct {
+		ref                       string
+		host, repository, version string
+		wantErr                   bool
+	}{
+		{ref: "ghcr.io/org/policies:v1", host: "ghcr.io", repository: "org/policies", version: "v1"},
+		{ref: "ghcr.io/org/policies", host: "ghcr.io", repository: "org/policies", version: "latest"},
+		{ref: "localhost:5000/policies:v1", host: "localhost:5000", repository: "policies", version: "v1"},
+		{ref: "ghcr.io/org/policies@sha256:abc123", host: "ghcr.io", repository: "org/policies", version: "sha256:abc123"},
+		{ref: "ghcr.io/org/policies:v1@sha256:abc123", host: "ghcr.io", repository: "org/policies", version: "sha256:abc123"},
+		{ref: "policies:v1", wantErr: true},
+		{ref: "org/policies:v1", wantErr: true},
+		{ref: "ghcr.io/org/policies@abc123", wantErr: true},
+	}
+	for _, tt := range tc {
+		host, repository, version, err := parseRef(tt.ref)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseRef(%q): expected error, got none", tt.ref)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseRef(%q): unexpected error: %v", tt.ref, err)
+			continue
+		}
+		if host != tt.host || repository != tt.repository || version != tt.version {
+			t.Errorf("parseRef(%q) = %q, %q, %q; want %q, %q, %q", tt.ref, host, repository, version, tt.host, tt.repository, tt.version)
+		}
+	}
+}
+
+func TestSplitDocs(t *testing.T) {
+	content := []byte("apiVersion: v1\nkind: A\n---\napiVersion: v1\nkind: B\n---\n\n")
+	docs := splitDocs(content)
+	if len(docs) != 2 {
+		t.Fatalf("got %d docs, want 2", len(docs))
+	}
+}
+
+const testTemplate = `apiVersion: templates.gatekeeper.sh/v1beta1
+kind: ConstraintTemplate
+metadata:
+  name: k8srequiredlabels
+spec:
+  crd:
+    spec:
+      names:
+        kind: K8sRequiredLabels
+  targets:
+    - target: admission.k8s.gatekeeper.sh
+      rego: |
+        package k8srequiredlabels
+        violation[{"msg": msg}] {
+          not input.review.object.metadata.labels.owner
+          msg := "missing owner label"
+        }
+`
+
+const testConstraint = `apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sRequiredLabels
+metadata:
+  name: must-have-owner
+spec:
+  match:
+    kinds:
+      - apiGroups: [""]
+        kinds: ["Pod"]
+`
+
+func bundleTarball(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	files := map[string]string{
+		"policies/template.yaml":   testTemplate,
+		"policies/constraint.yaml": testConstraint,
+		"policies/README.md":       "ignored",
+	}
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("writing tar header: %v", err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("writing tar content: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("closing tar: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("closing gzip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func blobDigest(blob []byte) string {
+	sum := sha256.Sum256(blob)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func TestPullImage(t *testing.T) {
+	blob := bundleTarball(t)
+	digest := blobDigest(blob)
+	authorized := false
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.URL.RawQuery, "scope=repository:org/policies:pull") {
+			t.Errorf("unexpected token scope: %s", r.URL.RawQuery)
+		}
+		fmt.Fprintf(w, `{"token":"anonymous-token"}`)
+	})
+	mux.HandleFunc("/v2/org/policies/manifests/v1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer anonymous-token" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="http://%s/token",service="registry"`, r.Host))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		authorized = true
+		fmt.Fprintf(w, `{"mediaType":"application/vnd.oci.image.manifest.v1+json","layers":[{"mediaType":"application/vnd.oci.image.layer.v1.tar+gzip","digest":"%s"}]}`, digest)
+	})
+	mux.HandleFunc("/v2/org/policies/blobs/"+digest, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(blob)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	bundle, err := PullImage(context.Background(), host+"/org/policies:v1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(filepath.Dir(bundle.TemplatesDir))
+	if !authorized {
+		t.Errorf("pull did not complete the token handshake")
+	}
+
+	templates, err := ioutil.ReadDir(bundle.TemplatesDir)
+	if err != nil {
+		t.Fatalf("reading templates dir: %v", err)
+	}
+	if len(templates) != 1 {
+		t.Errorf("got %d templates, want 1", len(templates))
+	}
+	constraints, err := ioutil.ReadDir(bundle.ConstraintsDir)
+	if err != nil {
+		t.Fatalf("reading constraints dir: %v", err)
+	}
+	if len(constraints) != 1 {
+		t.Errorf("got %d constraints, want 1", len(constraints))
+	}
+}
+
+func TestPullImageRejectsTamperedContent(t *testing.T) {
+	blob := bundleTarball(t)
+	digest := blobDigest(blob)
+	manifest := fmt.Sprintf(`{"mediaType":"application/vnd.oci.image.manifest.v1+json","layers":[{"mediaType":"application/vnd.oci.image.layer.v1.tar+gzip","digest":"%s"}]}`, digest)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/org/policies/manifests/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, manifest)
+	})
+	mux.HandleFunc("/v2/org/policies/blobs/"+digest, func(w http.ResponseWriter, r *http.Request) {
+		// content that does not hash to the digest the manifest declares
+		w.Write(append(blob, "tampered"...))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	if _, err := PullImage(context.Background(), host+"/org/policies:v1"); err == nil || !strings.Contains(err.Error(), "digest mismatch") {
+		t.Errorf("a tampered layer should fail the pull, got %v", err)
+	}
+
+	// a digest-pinned pull checks the manifest itself too
+	if _, err := PullImage(context.Background(), host+"/org/policies@"+blobDigest([]byte("not the manifest"))); err == nil || !strings.Contains(err.Error(), "digest mismatch") {
+		t.Errorf("a manifest not matching its pin should fail the pull, got %v", err)
+	}
+}
+
+func TestSortDocsRequiresTemplates(t *testing.T) {
+	if _, err := sortDocs([][]byte{[]byte(testConstraint)}); err == nil {
+		t.Errorf("expected error for a bundle without templates")
+	}
+}
@@ -0,0 +1,191 @@
+package gator
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// Levels a SarifFinding may carry, per the SARIF 2.1.0 "level" property.
+const (
+	SarifLevelError   = "error"
+	SarifLevelWarning = "warning"
+)
+
+// SourcedObject pairs a parsed object with the manifest file and line it
+// came from, so findings can be mapped back to source in SARIF reports.
+type SourcedObject struct {
+	Object *unstructured.Unstructured
+	// Path is the manifest file the object was parsed from.
+	Path string
+	// Line is the 1-based line the object's definition starts on.
+	Line int
+}
+
+// LoadObjectsWithSource reads object YAML files from dir, like
+// loadtest.LoadObjects, but records each object's source location.
+func LoadObjectsWithSource(dir string) ([]SourcedObject, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var out []SourcedObject
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		ext := filepath.Ext(f.Name())
+		if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+			continue
+		}
+		path := filepath.Join(dir, f.Name())
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal(data, &obj.Object); err != nil {
+			return nil, err
+		}
+		out = append(out, SourcedObject{
+			Object: obj,
+			Path:   filepath.ToSlash(path),
+			Line:   objectStartLine(data),
+		})
+	}
+	return out, nil
+}
+
+// objectStartLine returns the 1-based line the object's definition
+// starts on, skipping leading blanks, comments, and document separators.
+func objectStartLine(data []byte) int {
+	for i, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "---" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		return i + 1
+	}
+	return 1
+}
+
+// SarifFinding is one violation destined for a SARIF report.
+type SarifFinding struct {
+	// RuleID identifies the policy that produced the finding: the
+	// constraint as Kind/name, or the Rego rule as package.rule.
+	RuleID string
+	// Level is SarifLevelError or SarifLevelWarning.
+	Level   string
+	Message string
+	// Path and Line locate the offending object's manifest.
+	Path string
+	Line int
+}
+
+// The subset of the SARIF 2.1.0 object model gator emits.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// WriteSarif renders the findings as a SARIF 2.1.0 document, suitable
+// for upload to GitHub code scanning and other SARIF-aware tooling.
+func WriteSarif(w io.Writer, findings []SarifFinding) error {
+	seen := map[string]bool{}
+	rules := []sarifRule{}
+	for _, f := range findings {
+		if seen[f.RuleID] {
+			continue
+		}
+		seen[f.RuleID] = true
+		rules = append(rules, sarifRule{ID: f.RuleID})
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+
+	results := make([]sarifResult, 0, len(findings))
+	for _, f := range findings {
+		result := sarifResult{
+			RuleID:  f.RuleID,
+			Level:   f.Level,
+			Message: sarifMessage{Text: f.Message},
+		}
+		if f.Path != "" {
+			loc := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: f.Path}}
+			if f.Line > 0 {
+				loc.Region = &sarifRegion{StartLine: f.Line}
+			}
+			result.Locations = []sarifLocation{{PhysicalLocation: loc}}
+		}
+		results = append(results, result)
+	}
+
+	doc := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "gator",
+				InformationURI: "https://github.com/open-policy-agent/gatekeeper",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
@@ -0,0 +1,130 @@
+package gator
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadObjectsWithSource(t *testing.T) {
+	dir := t.TempDir()
+	manifest := `# recorded from the cluster
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: source-pod
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "pod.yaml"), []byte(manifest), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	objects, err := LoadObjectsWithSource(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 1 {
+		t.Fatalf("expected 1 object, got %d", len(objects))
+	}
+	obj := objects[0]
+	if obj.Object.GetName() != "source-pod" {
+		t.Errorf("expected object source-pod, got %q", obj.Object.GetName())
+	}
+	if filepath.Base(obj.Path) != "pod.yaml" {
+		t.Errorf("expected path ending in pod.yaml, got %q", obj.Path)
+	}
+	// comments and the document separator precede the definition
+	if obj.Line != 3 {
+		t.Errorf("expected definition to start on line 3, got %d", obj.Line)
+	}
+}
+
+func TestObjectStartLine(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data string
+		want int
+	}{
+		{name: "no preamble", data: "kind: Pod\n", want: 1},
+		{name: "leading comment", data: "# hi\nkind: Pod\n", want: 2},
+		{name: "separator and blank", data: "---\n\nkind: Pod\n", want: 3},
+		{name: "empty", data: "", want: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := objectStartLine([]byte(tc.data)); got != tc.want {
+				t.Errorf("expected line %d, got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestWriteSarif(t *testing.T) {
+	findings := []SarifFinding{
+		{RuleID: "K8sRequiredLabels/must-have-owner", Level: SarifLevelError, Message: "missing label", Path: "objects/pod.yaml", Line: 4},
+		{RuleID: "K8sRequiredLabels/must-have-owner", Level: SarifLevelError, Message: "missing label", Path: "objects/deploy.yaml", Line: 2},
+		{RuleID: "main.warn", Level: SarifLevelWarning, Message: "advisory", Path: "objects/pod.yaml", Line: 4},
+	}
+	var buf bytes.Buffer
+	if err := WriteSarif(&buf, findings); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if doc.Version != "2.1.0" {
+		t.Errorf("expected version 2.1.0, got %q", doc.Version)
+	}
+	if len(doc.Runs) != 1 {
+		t.Fatalf("expected 1 run, got %d", len(doc.Runs))
+	}
+	run := doc.Runs[0]
+	if run.Tool.Driver.Name != "gator" {
+		t.Errorf("expected driver gator, got %q", run.Tool.Driver.Name)
+	}
+	// rules are deduplicated and sorted
+	if len(run.Tool.Driver.Rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(run.Tool.Driver.Rules))
+	}
+	if run.Tool.Driver.Rules[0].ID != "K8sRequiredLabels/must-have-owner" || run.Tool.Driver.Rules[1].ID != "main.warn" {
+		t.Errorf("unexpected rule order: %v", run.Tool.Driver.Rules)
+	}
+	if len(run.Results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(run.Results))
+	}
+	first := run.Results[0]
+	if first.Level != SarifLevelError || first.Message.Text != "missing label" {
+		t.Errorf("unexpected first result: %+v", first)
+	}
+	if len(first.Locations) != 1 {
+		t.Fatalf("expected 1 location, got %d", len(first.Locations))
+	}
+	loc := first.Locations[0].PhysicalLocation
+	if loc.ArtifactLocation.URI != "objects/pod.yaml" {
+		t.Errorf("expected uri objects/pod.yaml, got %q", loc.ArtifactLocation.URI)
+	}
+	if loc.Region == nil || loc.Region.StartLine != 4 {
+		t.Errorf("expected region startLine 4, got %+v", loc.Region)
+	}
+}
+
+func TestWriteSarifEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSarif(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	var doc sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	// an empty results array is still a valid, uploadable document
+	if len(doc.Runs) != 1 || len(doc.Runs[0].Results) != 0 {
+		t.Errorf("expected one run with no results, got %+v", doc.Runs)
+	}
+}
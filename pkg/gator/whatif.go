@@ -0,0 +1,317 @@
+package gator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	templatesv1beta1 "github.com/open-policy-agent/frameworks/constraint/pkg/apis/templates/v1beta1"
+	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/core/templates"
+	"github.com/open-policy-agent/gatekeeper/apis"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+var (
+	whatifScheme = k8sruntime.NewScheme()
+
+	templatesGVR = schema.GroupVersionResource{
+		Group: "templates.gatekeeper.sh", Version: "v1beta1", Resource: "constrainttemplates",
+	}
+	constraintsGV = schema.GroupVersion{Group: "constraints.gatekeeper.sh", Version: "v1beta1"}
+)
+
+func init() {
+	_ = apis.AddToScheme(whatifScheme)
+}
+
+// ImpactViolation is one violation found during a what-if run.
+type ImpactViolation struct {
+	ConstraintKind    string `json:"constraintKind"`
+	ConstraintName    string `json:"constraintName"`
+	ResourceKind      string `json:"resourceKind"`
+	ResourceNamespace string `json:"resourceNamespace,omitempty"`
+	ResourceName      string `json:"resourceName"`
+	Message           string `json:"message"`
+}
+
+// ImpactDelta is the difference in violations between the live policy
+// set and a proposed one, over the same resources.
+type ImpactDelta struct {
+	// Introduced are violations the proposed policies would add.
+	Introduced []ImpactViolation `json:"introduced,omitempty"`
+	// Resolved are current violations the proposed policies would
+	// no longer flag.
+	Resolved []ImpactViolation `json:"resolved,omitempty"`
+	// Unchanged counts violations present under both policy sets.
+	Unchanged int `json:"unchanged"`
+}
+
+// impactKey identifies a violation across runs; the message is excluded
+// so reworded templates do not show up as churn.
+type impactKey struct {
+	constraintKind, constraintName        string
+	resourceKind, resourceNS, resourceNam string
+}
+
+func (v ImpactViolation) key() impactKey {
+	return impactKey{
+		constraintKind: v.ConstraintKind,
+		constraintName: v.ConstraintName,
+		resourceKind:   v.ResourceKind,
+		resourceNS:     v.ResourceNamespace,
+		resourceNam:    v.ResourceName,
+	}
+}
+
+// DiffViolations computes the delta between the violations of the live
+// policy set and those of the proposed one.
+func DiffViolations(live, proposed []ImpactViolation) *ImpactDelta {
+	liveKeys := map[impactKey]bool{}
+	for _, v := range live {
+		liveKeys[v.key()] = true
+	}
+	proposedKeys := map[impactKey]bool{}
+	for _, v := range proposed {
+		proposedKeys[v.key()] = true
+	}
+
+	delta := &ImpactDelta{}
+	for _, v := range proposed {
+		if liveKeys[v.key()] {
+			delta.Unchanged++
+			continue
+		}
+		delta.Introduced = append(delta.Introduced, v)
+	}
+	for _, v := range live {
+		if !proposedKeys[v.key()] {
+			delta.Resolved = append(delta.Resolved, v)
+		}
+	}
+	sortViolations(delta.Introduced)
+	sortViolations(delta.Resolved)
+	return delta
+}
+
+func sortViolations(violations []ImpactViolation) {
+	sort.Slice(violations, func(i, j int) bool {
+		a, b := violations[i], violations[j]
+		if a.ConstraintName != b.ConstraintName {
+			return a.ConstraintName < b.ConstraintName
+		}
+		if a.ResourceNamespace != b.ResourceNamespace {
+			return a.ResourceNamespace < b.ResourceNamespace
+		}
+		return a.ResourceName < b.ResourceName
+	})
+}
+
+// ReviewAll runs every object through the policy client and collects
+// the violations, regardless of enforcement action: what-if analysis is
+// about what would be flagged, not what would be blocked.
+func ReviewAll(ctx context.Context, client *opa.Client, objects []*unstructured.Unstructured) ([]ImpactViolation, error) {
+	var out []ImpactViolation
+	for _, obj := range objects {
+		resp, err := client.Review(ctx, target.AugmentedUnstructured{Object: *obj})
+		if err != nil {
+			return nil, fmt.Errorf("reviewing %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		for _, r := range resp.Results() {
+			out = append(out, ImpactViolation{
+				ConstraintKind:    r.Constraint.GetKind(),
+				ConstraintName:    r.Constraint.GetName(),
+				ResourceKind:      obj.GetKind(),
+				ResourceNamespace: obj.GetNamespace(),
+				ResourceName:      obj.GetName(),
+				Message:           r.Msg,
+			})
+		}
+	}
+	return out, nil
+}
+
+// MatchedKinds collects the group/kind pairs the constraints match, so
+// the what-if run only fetches resources the policies can apply to.
+// Constraints without a kinds matcher are reported so the caller can
+// warn that their impact is not assessed.
+func MatchedKinds(constraints []*unstructured.Unstructured) (kinds []schema.GroupKind, unmatched []string) {
+	seen := map[schema.GroupKind]bool{}
+	for _, constraint := range constraints {
+		entries, found, _ := unstructured.NestedSlice(constraint.Object, "spec", "match", "kinds")
+		if !found || len(entries) == 0 {
+			unmatched = append(unmatched, constraint.GetName())
+			continue
+		}
+		for _, e := range entries {
+			entry, ok := e.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			groups := stringSlice(entry["apiGroups"])
+			if len(groups) == 0 {
+				groups = []string{""}
+			}
+			for _, kind := range stringSlice(entry["kinds"]) {
+				for _, group := range groups {
+					if kind == "*" || group == "*" {
+						unmatched = append(unmatched, constraint.GetName())
+						continue
+					}
+					gk := schema.GroupKind{Group: group, Kind: kind}
+					if !seen[gk] {
+						seen[gk] = true
+						kinds = append(kinds, gk)
+					}
+				}
+			}
+		}
+	}
+	sort.Slice(kinds, func(i, j int) bool {
+		return kinds[i].Group+"/"+kinds[i].Kind < kinds[j].Group+"/"+kinds[j].Kind
+	})
+	return kinds, unmatched
+}
+
+func stringSlice(raw interface{}) []string {
+	entries, ok := raw.([]interface{})
+	if !ok {
+		return nil
+	}
+	var out []string
+	for _, e := range entries {
+		if s, ok := e.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// FetchResources lists the cluster's resources of the given kinds via
+// the dynamic client, resolving kinds to list endpoints through
+// discovery. Kinds the cluster does not serve or cannot be listed are
+// skipped with a note rather than failing the run.
+func FetchResources(ctx context.Context, cfg *rest.Config, kinds []schema.GroupKind) ([]*unstructured.Unstructured, []string, error) {
+	disco, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("building discovery client: %w", err)
+	}
+	dyn, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("building dynamic client: %w", err)
+	}
+	lists, err := disco.ServerPreferredResources()
+	if err != nil {
+		return nil, nil, fmt.Errorf("discovering server resources: %w", err)
+	}
+
+	var objects []*unstructured.Unstructured
+	var skipped []string
+	for _, gk := range kinds {
+		gvr, found := resourceFor(lists, gk)
+		if !found {
+			skipped = append(skipped, gk.Kind)
+			continue
+		}
+		list, err := dyn.Resource(gvr).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s (%v)", gk.Kind, err))
+			continue
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	}
+	return objects, skipped, nil
+}
+
+func resourceFor(lists []*metav1.APIResourceList, gk schema.GroupKind) (schema.GroupVersionResource, bool) {
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil || gv.Group != gk.Group {
+			continue
+		}
+		for _, r := range list.APIResources {
+			if r.Kind != gk.Kind || strings.Contains(r.Name, "/") {
+				continue
+			}
+			if !hasListVerb(r.Verbs) {
+				continue
+			}
+			return gv.WithResource(r.Name), true
+		}
+	}
+	return schema.GroupVersionResource{}, false
+}
+
+func hasListVerb(verbs []string) bool {
+	for _, v := range verbs {
+		if v == "list" {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadClusterPolicies loads the cluster's installed constraint templates
+// and constraints into the client, forming the live policy baseline. It
+// returns the constraints so the caller can derive which resource kinds
+// to fetch.
+func LoadClusterPolicies(ctx context.Context, cfg *rest.Config, client *opa.Client) ([]*unstructured.Unstructured, error) {
+	dyn, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("building dynamic client: %w", err)
+	}
+	templateList, err := dyn.Resource(templatesGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing constraint templates: %w", err)
+	}
+
+	var kinds []string
+	for i := range templateList.Items {
+		item := &templateList.Items[i]
+		versioned := &templatesv1beta1.ConstraintTemplate{}
+		if err := k8sruntime.DefaultUnstructuredConverter.FromUnstructured(item.Object, versioned); err != nil {
+			return nil, fmt.Errorf("decoding template %s: %w", item.GetName(), err)
+		}
+		unversioned := &templates.ConstraintTemplate{}
+		if err := whatifScheme.Convert(versioned, unversioned, nil); err != nil {
+			return nil, fmt.Errorf("converting template %s: %w", item.GetName(), err)
+		}
+		if _, err := client.AddTemplate(ctx, unversioned); err != nil {
+			return nil, fmt.Errorf("adding template %s: %w", item.GetName(), err)
+		}
+		kinds = append(kinds, unversioned.Spec.CRD.Spec.Names.Kind)
+	}
+
+	var constraints []*unstructured.Unstructured
+	for _, kind := range kinds {
+		// the constraint resource name is the lowercased CRD kind, per
+		// the CRDs Gatekeeper generates from templates
+		gvr := constraintsGV.WithResource(strings.ToLower(kind))
+		list, err := dyn.Resource(gvr).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("listing %s constraints: %w", kind, err)
+		}
+		for i := range list.Items {
+			constraint := &list.Items[i]
+			if _, err := client.AddConstraint(ctx, constraint); err != nil {
+				return nil, fmt.Errorf("adding constraint %s: %w", constraint.GetName(), err)
+			}
+			constraints = append(constraints, constraint)
+		}
+	}
+	return constraints, nil
+}
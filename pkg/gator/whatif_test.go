@@ -0,0 +1,138 @@
+package gator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/loadtest"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+func impact(constraintName, resourceName string) ImpactViolation {
+	return ImpactViolation{
+		ConstraintKind:    "K8sRequiredLabels",
+		ConstraintName:    constraintName,
+		ResourceKind:      "Pod",
+		ResourceNamespace: "default",
+		ResourceName:      resourceName,
+		Message:           "missing labels",
+	}
+}
+
+func TestDiffViolations(t *testing.T) {
+	live := []ImpactViolation{impact("must-have-owner", "pod-a"), impact("must-have-owner", "pod-b")}
+	proposed := []ImpactViolation{impact("must-have-owner", "pod-b"), impact("must-have-team", "pod-a")}
+
+	delta := DiffViolations(live, proposed)
+	if len(delta.Introduced) != 1 || delta.Introduced[0].ConstraintName != "must-have-team" {
+		t.Fatalf("unexpected introduced: %+v", delta.Introduced)
+	}
+	if len(delta.Resolved) != 1 || delta.Resolved[0].ResourceName != "pod-a" {
+		t.Fatalf("unexpected resolved: %+v", delta.Resolved)
+	}
+	if delta.Unchanged != 1 {
+		t.Fatalf("got %d unchanged, want 1", delta.Unchanged)
+	}
+}
+
+func TestDiffViolationsIgnoresMessageChanges(t *testing.T) {
+	before := impact("must-have-owner", "pod-a")
+	after := before
+	after.Message = "reworded message"
+
+	delta := DiffViolations([]ImpactViolation{before}, []ImpactViolation{after})
+	if len(delta.Introduced) != 0 || len(delta.Resolved) != 0 || delta.Unchanged != 1 {
+		t.Fatalf("message-only change should be unchanged, got %+v", delta)
+	}
+}
+
+func TestMatchedKinds(t *testing.T) {
+	constraints := []*unstructured.Unstructured{
+		mustUnmarshal(t, `
+apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sRequiredLabels
+metadata:
+  name: must-have-owner
+spec:
+  match:
+    kinds:
+      - apiGroups: [""]
+        kinds: ["Pod", "Service"]
+      - apiGroups: ["apps"]
+        kinds: ["Deployment"]
+`),
+		mustUnmarshal(t, `
+apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sRequiredLabels
+metadata:
+  name: matches-everything
+spec: {}
+`),
+	}
+
+	kinds, unmatched := MatchedKinds(constraints)
+	if len(kinds) != 3 {
+		t.Fatalf("got %d kinds, want 3: %v", len(kinds), kinds)
+	}
+	if kinds[0].Kind != "Pod" || kinds[1].Kind != "Service" || kinds[2].Group != "apps" || kinds[2].Kind != "Deployment" {
+		t.Fatalf("unexpected kinds: %v", kinds)
+	}
+	if len(unmatched) != 1 || unmatched[0] != "matches-everything" {
+		t.Fatalf("unexpected unmatched constraints: %v", unmatched)
+	}
+}
+
+func TestReviewAll(t *testing.T) {
+	ctx := context.Background()
+	templatesDir, constraintsDir := coverageFixtures(t)
+
+	client, err := loadtest.NewClient()
+	if err != nil {
+		t.Fatalf("building client: %v", err)
+	}
+	if _, err := loadtest.LoadTemplates(ctx, client, templatesDir); err != nil {
+		t.Fatalf("loading templates: %v", err)
+	}
+	if _, err := loadtest.LoadConstraints(ctx, client, constraintsDir); err != nil {
+		t.Fatalf("loading constraints: %v", err)
+	}
+
+	unlabeled := mustUnmarshal(t, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: unlabeled
+  namespace: default
+`)
+	labeled := mustUnmarshal(t, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: labeled
+  namespace: default
+  labels:
+    owner: someone
+`)
+
+	violations, err := ReviewAll(ctx, client, []*unstructured.Unstructured{unlabeled, labeled})
+	if err != nil {
+		t.Fatalf("reviewing objects: %v", err)
+	}
+	if len(violations) != 1 {
+		t.Fatalf("got %d violations, want 1: %+v", len(violations), violations)
+	}
+	v := violations[0]
+	if v.ConstraintName != "must-have-owner" || v.ResourceName != "unlabeled" || v.ResourceNamespace != "default" {
+		t.Fatalf("unexpected violation: %+v", v)
+	}
+}
+
+func mustUnmarshal(t *testing.T, manifest string) *unstructured.Unstructured {
+	t.Helper()
+	obj := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal([]byte(manifest), &obj.Object); err != nil {
+		t.Fatalf("unmarshaling manifest: %v", err)
+	}
+	return obj
+}
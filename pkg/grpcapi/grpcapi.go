@@ -0,0 +1,128 @@
+// Package grpcapi exposes policy evaluation over gRPC, so internal
+// platforms and admission proxies can query Gatekeeper decisions with
+// lower overhead than the HTTP endpoints. The Review method accepts the
+// same request and result shapes as the simulation API; messages travel
+// as JSON via a registered codec, so no generated protobuf code is
+// required and any gRPC client can call the service with the "json"
+// content subtype.
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/logging"
+	"github.com/open-policy-agent/gatekeeper/pkg/simulation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("grpc-api").WithValues(logging.Process, "grpc_api")
+
+// ServiceName is the fully qualified gRPC service name.
+const ServiceName = "gatekeeper.v1alpha1.Decision"
+
+// CodecName is the content subtype clients must use:
+// grpc.CallContentSubtype(grpcapi.CodecName).
+const CodecName = "json"
+
+var (
+	enableGRPCAPI = flag.Bool("enable-grpc-api", false, "(alpha) expose policy evaluation as a gRPC service")
+	grpcAddr      = flag.String("grpc-addr", ":50051", "The address the gRPC decision service binds to.")
+)
+
+// Enabled returns true when the gRPC decision service was requested via
+// flag.
+func Enabled() bool {
+	return *enableGRPCAPI
+}
+
+// jsonCodec carries request and response messages as JSON.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return CodecName
+}
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// Server serves gRPC decision requests. It implements manager.Runnable
+// so it can be registered with the controller manager like the other
+// optional servers.
+type Server struct {
+	simulator *simulation.Simulator
+	addr      string
+}
+
+// NewServer returns a Server configured from the gRPC flags.
+func NewServer(simulator *simulation.Simulator) *Server {
+	return &Server{simulator: simulator, addr: *grpcAddr}
+}
+
+// serviceDesc describes the decision service without generated code.
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*decisionHandler)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Review", Handler: reviewHandler},
+	},
+}
+
+// decisionHandler is the interface the service desc dispatches to.
+type decisionHandler interface {
+	Review(ctx context.Context, req *simulation.Request) (*simulation.Result, error)
+}
+
+func reviewHandler(srv interface{}, ctx context.Context, decode func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	req := &simulation.Request{}
+	if err := decode(req); err != nil {
+		return nil, err
+	}
+	return srv.(decisionHandler).Review(ctx, req)
+}
+
+// Review evaluates one object, mirroring the simulation endpoint.
+func (s *Server) Review(ctx context.Context, req *simulation.Request) (*simulation.Result, error) {
+	if req.Object == nil {
+		return nil, fmt.Errorf("review request must include an object")
+	}
+	return s.simulator.Simulate(ctx, req)
+}
+
+// Start implements manager.Runnable.
+func (s *Server) Start(ctx context.Context) error {
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", s.addr, err)
+	}
+	srv := grpc.NewServer()
+	srv.RegisterService(&serviceDesc, s)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Serve(lis)
+	}()
+	log.Info("serving gRPC decision API", "addr", s.addr)
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		srv.GracefulStop()
+		return nil
+	}
+}
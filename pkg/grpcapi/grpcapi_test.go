@@ -0,0 +1,70 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/client/drivers/local"
+	"github.com/open-policy-agent/gatekeeper/pkg/mutation"
+	"github.com/open-policy-agent/gatekeeper/pkg/simulation"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	"google.golang.org/grpc"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func makeOpaClient() (*opa.Client, error) {
+	t := &target.K8sValidationTarget{}
+	driver := local.New(local.Tracing(false))
+	backend, err := opa.NewBackend(opa.Driver(driver))
+	if err != nil {
+		return nil, err
+	}
+	return backend.NewClient(opa.Targets(t))
+}
+
+func TestReviewOverGRPC(t *testing.T) {
+	opaClient, err := makeOpaClient()
+	if err != nil {
+		t.Fatalf("could not initialize OPA: %s", err)
+	}
+	server := &Server{simulator: simulation.New(opaClient, mutation.NewSystem(), nil)}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %s", err)
+	}
+	srv := grpc.NewServer()
+	srv.RegisterService(&serviceDesc, server)
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, lis.Addr().String(), grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		t.Fatalf("dialing: %s", err)
+	}
+	defer conn.Close()
+
+	pod := &unstructured.Unstructured{}
+	pod.SetAPIVersion("v1")
+	pod.SetKind("Pod")
+	pod.SetName("busybox")
+
+	result := &simulation.Result{}
+	err = conn.Invoke(ctx, "/"+ServiceName+"/Review", &simulation.Request{Object: pod}, result, grpc.CallContentSubtype(CodecName))
+	if err != nil {
+		t.Fatalf("invoking Review: %s", err)
+	}
+	if result.Denied {
+		t.Errorf("object with no constraints should not be denied")
+	}
+
+	err = conn.Invoke(ctx, "/"+ServiceName+"/Review", &simulation.Request{}, result, grpc.CallContentSubtype(CodecName))
+	if err == nil {
+		t.Errorf("requests without an object should be rejected")
+	}
+}
@@ -0,0 +1,105 @@
+package httpguard
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func setFlags(t *testing.T, tokenFile string, limit float64) {
+	t.Helper()
+	oldFile, oldLimit := *endpointTokenFile, *endpointRateLimit
+	t.Cleanup(func() {
+		*endpointTokenFile = oldFile
+		*endpointRateLimit = oldLimit
+	})
+	*endpointTokenFile = tokenFile
+	*endpointRateLimit = limit
+}
+
+func writeToken(t *testing.T, token string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "token")
+	if err := ioutil.WriteFile(path, []byte(token), 0600); err != nil {
+		t.Fatalf("writing token file: %s", err)
+	}
+	return path
+}
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func get(t *testing.T, h http.Handler, token string) int {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestWrapIsOpenWhenDisabled(t *testing.T) {
+	setFlags(t, "", 0)
+	if code := get(t, Wrap(okHandler()), ""); code != http.StatusOK {
+		t.Errorf("requests should pass through without protection configured, got %d", code)
+	}
+}
+
+func TestTokenAuth(t *testing.T) {
+	setFlags(t, writeToken(t, "s3cret\n"), 0)
+	g, err := newGuard()
+	if err != nil {
+		t.Fatalf("building guard: %s", err)
+	}
+	h := g.wrap(okHandler())
+	if code := get(t, h, "s3cret"); code != http.StatusOK {
+		t.Errorf("valid token should be accepted, got %d", code)
+	}
+	if code := get(t, h, "wrong"); code != http.StatusUnauthorized {
+		t.Errorf("invalid token should be rejected, got %d", code)
+	}
+	if code := get(t, h, ""); code != http.StatusUnauthorized {
+		t.Errorf("missing token should be rejected, got %d", code)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	setFlags(t, "", 1)
+	g, err := newGuard()
+	if err != nil {
+		t.Fatalf("building guard: %s", err)
+	}
+	h := g.wrap(okHandler())
+	limited := false
+	for i := 0; i < 10; i++ {
+		if get(t, h, "") == http.StatusTooManyRequests {
+			limited = true
+			break
+		}
+	}
+	if !limited {
+		t.Errorf("sustained traffic over the limit should be rejected")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	setFlags(t, "", -1)
+	if err := Validate(); err == nil {
+		t.Errorf("negative rate limits should be rejected")
+	}
+	setFlags(t, filepath.Join(t.TempDir(), "missing"), 0)
+	if err := Validate(); err == nil {
+		t.Errorf("unreadable token files should be rejected")
+	}
+	setFlags(t, writeToken(t, "s3cret"), 1)
+	if err := Validate(); err != nil {
+		t.Errorf("valid flags should pass: %s", err)
+	}
+}
@@ -0,0 +1,289 @@
+// Package lazycompile defers constraint template compilation until a
+// review arrives for a kind one of the template's constraints matches.
+// On clusters with very large policy libraries where most templates
+// target rare kinds, this cuts webhook startup time and memory: pending
+// templates and their constraints are held uncompiled and only pushed
+// into OPA on first use. An optional warm-up compiles the backlog in the
+// background after startup so steady-state latency is unaffected.
+package lazycompile
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"sync"
+
+	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/core/templates"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/types"
+	"github.com/open-policy-agent/gatekeeper/pkg/opashard"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("lazy-compile")
+
+var (
+	lazyCompilation = flag.Bool("lazy-template-compilation", false, "(alpha) defer compiling constraint templates until a review arrives for a kind their constraints match")
+	warmup          = flag.Bool("lazy-compilation-warmup", false, "(alpha) compile deferred templates in the background after startup. Only meaningful with --lazy-template-compilation")
+)
+
+// Enabled returns true when lazy template compilation was requested via
+// flags.
+func Enabled() bool {
+	return *lazyCompilation
+}
+
+// WarmupEnabled returns true when background warm-up was requested via
+// flags.
+func WarmupEnabled() bool {
+	return *warmup
+}
+
+// pendingTemplate is a template that has not been compiled yet, together
+// with the constraints that arrived for it in the meantime.
+type pendingTemplate struct {
+	template    *templates.ConstraintTemplate
+	constraints map[string]*unstructured.Unstructured
+}
+
+// matchesKind returns true when any of the pending constraints could
+// apply to the given resource kind. Constraints without kind matchers
+// match everything.
+func (p *pendingTemplate) matchesKind(kind string) bool {
+	for _, cstr := range p.constraints {
+		kinds, found, err := unstructured.NestedSlice(cstr.Object, "spec", "match", "kinds")
+		if err != nil || !found {
+			return true
+		}
+		for _, k := range kinds {
+			entry, ok := k.(map[string]interface{})
+			if !ok {
+				return true
+			}
+			names, found, err := unstructured.NestedStringSlice(entry, "kinds")
+			if err != nil || !found {
+				return true
+			}
+			for _, name := range names {
+				if name == kind || name == "*" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Client wraps an OPA client and holds templates back from compilation
+// until first use. It implements opashard.Client.
+type Client struct {
+	opashard.Client
+
+	mux     sync.Mutex
+	pending map[string]*pendingTemplate
+}
+
+var _ opashard.Client = &Client{}
+
+// New wraps inner with lazy template compilation.
+func New(inner opashard.Client) *Client {
+	return &Client{
+		Client:  inner,
+		pending: make(map[string]*pendingTemplate),
+	}
+}
+
+// AddTemplate queues the template instead of compiling it. CRD
+// generation still happens eagerly via CreateCRD, so constraint
+// validation and watches are unaffected.
+func (c *Client) AddTemplate(ctx context.Context, templ *templates.ConstraintTemplate) (*types.Responses, error) {
+	kind := templ.Spec.CRD.Spec.Names.Kind
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if p, ok := c.pending[kind]; ok {
+		p.template = templ.DeepCopy()
+		return types.NewResponses(), nil
+	}
+	c.pending[kind] = &pendingTemplate{
+		template:    templ.DeepCopy(),
+		constraints: make(map[string]*unstructured.Unstructured),
+	}
+	return types.NewResponses(), nil
+}
+
+func (c *Client) RemoveTemplate(ctx context.Context, templ *templates.ConstraintTemplate) (*types.Responses, error) {
+	kind := templ.Spec.CRD.Spec.Names.Kind
+	c.mux.Lock()
+	if _, ok := c.pending[kind]; ok {
+		delete(c.pending, kind)
+		c.mux.Unlock()
+		return types.NewResponses(), nil
+	}
+	c.mux.Unlock()
+	return c.Client.RemoveTemplate(ctx, templ)
+}
+
+func (c *Client) GetTemplate(ctx context.Context, templ *templates.ConstraintTemplate) (*templates.ConstraintTemplate, error) {
+	kind := templ.Spec.CRD.Spec.Names.Kind
+	c.mux.Lock()
+	if p, ok := c.pending[kind]; ok {
+		cpy := p.template.DeepCopy()
+		c.mux.Unlock()
+		return cpy, nil
+	}
+	c.mux.Unlock()
+	return c.Client.GetTemplate(ctx, templ)
+}
+
+func (c *Client) AddConstraint(ctx context.Context, constraint *unstructured.Unstructured) (*types.Responses, error) {
+	c.mux.Lock()
+	if p, ok := c.pending[constraint.GetKind()]; ok {
+		p.constraints[constraint.GetName()] = constraint.DeepCopy()
+		c.mux.Unlock()
+		return types.NewResponses(), nil
+	}
+	c.mux.Unlock()
+	return c.Client.AddConstraint(ctx, constraint)
+}
+
+func (c *Client) RemoveConstraint(ctx context.Context, constraint *unstructured.Unstructured) (*types.Responses, error) {
+	c.mux.Lock()
+	if p, ok := c.pending[constraint.GetKind()]; ok {
+		delete(p.constraints, constraint.GetName())
+		c.mux.Unlock()
+		return types.NewResponses(), nil
+	}
+	c.mux.Unlock()
+	return c.Client.RemoveConstraint(ctx, constraint)
+}
+
+func (c *Client) GetConstraint(ctx context.Context, constraint *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	c.mux.Lock()
+	if p, ok := c.pending[constraint.GetKind()]; ok {
+		if cstr, ok := p.constraints[constraint.GetName()]; ok {
+			cpy := cstr.DeepCopy()
+			c.mux.Unlock()
+			return cpy, nil
+		}
+	}
+	c.mux.Unlock()
+	return c.Client.GetConstraint(ctx, constraint)
+}
+
+// ValidateConstraint needs the compiled CRD, so it forces compilation of
+// the constraint's template.
+func (c *Client) ValidateConstraint(ctx context.Context, constraint *unstructured.Unstructured) error {
+	if err := c.compileKinds(ctx, func(kind string, p *pendingTemplate) bool {
+		return kind == constraint.GetKind()
+	}); err != nil {
+		return err
+	}
+	return c.Client.ValidateConstraint(ctx, constraint)
+}
+
+// Review compiles any pending templates whose constraints could match
+// the reviewed kind, then delegates.
+func (c *Client) Review(ctx context.Context, obj interface{}, opts ...opa.QueryOpt) (*types.Responses, error) {
+	kind := reviewKind(obj)
+	if err := c.compileKinds(ctx, func(_ string, p *pendingTemplate) bool {
+		return kind == "" || p.matchesKind(kind)
+	}); err != nil {
+		return nil, err
+	}
+	return c.Client.Review(ctx, obj, opts...)
+}
+
+// Audit evaluates all cached data, so everything pending is compiled
+// first.
+func (c *Client) Audit(ctx context.Context, opts ...opa.QueryOpt) (*types.Responses, error) {
+	if err := c.CompileAll(ctx); err != nil {
+		return nil, err
+	}
+	return c.Client.Audit(ctx, opts...)
+}
+
+// CompileAll compiles every pending template.
+func (c *Client) CompileAll(ctx context.Context) error {
+	return c.compileKinds(ctx, func(string, *pendingTemplate) bool { return true })
+}
+
+// compileKinds compiles pending templates selected by the predicate and
+// removes them from the backlog.
+func (c *Client) compileKinds(ctx context.Context, match func(string, *pendingTemplate) bool) error {
+	c.mux.Lock()
+	var selected []*pendingTemplate
+	for kind, p := range c.pending {
+		if !match(kind, p) {
+			continue
+		}
+		selected = append(selected, p)
+		delete(c.pending, kind)
+	}
+	c.mux.Unlock()
+
+	var errs []string
+	for _, p := range selected {
+		if _, err := c.Client.AddTemplate(ctx, p.template); err != nil {
+			errs = append(errs, fmt.Sprintf("compiling template %s: %v", p.template.GetName(), err))
+			continue
+		}
+		log.Info("lazily compiled template", "template", p.template.GetName(), "constraints", len(p.constraints))
+		for _, cstr := range p.constraints {
+			if _, err := c.Client.AddConstraint(ctx, cstr); err != nil {
+				errs = append(errs, fmt.Sprintf("adding constraint %s: %v", cstr.GetName(), err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// reviewKind extracts the resource kind from the review payloads used by
+// the webhook and audit. Unknown payloads compile everything.
+func reviewKind(obj interface{}) string {
+	switch data := obj.(type) {
+	case unstructured.Unstructured:
+		return data.GetKind()
+	case *unstructured.Unstructured:
+		return data.GetKind()
+	case target.AugmentedUnstructured:
+		return data.Object.GetKind()
+	case *target.AugmentedUnstructured:
+		return data.Object.GetKind()
+	case target.AugmentedReview:
+		return data.AdmissionRequest.Kind.Kind
+	case *target.AugmentedReview:
+		return data.AdmissionRequest.Kind.Kind
+	case admissionv1.AdmissionRequest:
+		return data.Kind.Kind
+	case *admissionv1.AdmissionRequest:
+		return data.Kind.Kind
+	}
+	return ""
+}
+
+// Warmer compiles the pending backlog in the background after startup.
+// It implements manager.Runnable.
+type Warmer struct {
+	client *Client
+}
+
+// NewWarmer returns a Warmer for the given lazy client.
+func NewWarmer(c *Client) *Warmer {
+	return &Warmer{client: c}
+}
+
+// Start implements manager.Runnable.
+func (w *Warmer) Start(ctx context.Context) error {
+	if err := w.client.CompileAll(ctx); err != nil {
+		log.Error(err, "template warm-up failed")
+	}
+	<-ctx.Done()
+	return nil
+}
@@ -0,0 +1,169 @@
+package lazycompile
+
+import (
+	"context"
+	"testing"
+
+	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/client/drivers/local"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/core/templates"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func makeOpaClient() (*opa.Client, error) {
+	t := &target.K8sValidationTarget{}
+	driver := local.New(local.Tracing(false))
+	backend, err := opa.NewBackend(opa.Driver(driver))
+	if err != nil {
+		return nil, err
+	}
+	return backend.NewClient(opa.Targets(t))
+}
+
+func makeLazyClient(t *testing.T) (*Client, *opa.Client) {
+	inner, err := makeOpaClient()
+	if err != nil {
+		t.Fatalf("could not initialize OPA: %s", err)
+	}
+	return New(inner), inner
+}
+
+func denyAllTemplate() *templates.ConstraintTemplate {
+	templ := &templates.ConstraintTemplate{}
+	templ.SetName("denyall")
+	templ.Spec.CRD.Spec.Names.Kind = "DenyAll"
+	templ.Spec.Targets = []templates.Target{{
+		Target: (&target.K8sValidationTarget{}).GetName(),
+		Rego: `
+package denyall
+
+violation[{"msg": msg}] {
+  msg := "denied by constraint"
+}
+`,
+	}}
+	return templ
+}
+
+func makeConstraint(name string, kinds ...string) *unstructured.Unstructured {
+	cstr := &unstructured.Unstructured{}
+	cstr.SetAPIVersion("constraints.gatekeeper.sh/v1beta1")
+	cstr.SetKind("DenyAll")
+	cstr.SetName(name)
+	if len(kinds) > 0 {
+		matchKinds := []interface{}{
+			map[string]interface{}{
+				"apiGroups": []interface{}{""},
+				"kinds":     toInterfaces(kinds),
+			},
+		}
+		if err := unstructured.SetNestedSlice(cstr.Object, matchKinds, "spec", "match", "kinds"); err != nil {
+			panic(err)
+		}
+	}
+	return cstr
+}
+
+func toInterfaces(ss []string) []interface{} {
+	out := make([]interface{}, len(ss))
+	for i, s := range ss {
+		out[i] = s
+	}
+	return out
+}
+
+func makePod(name string) *unstructured.Unstructured {
+	pod := &unstructured.Unstructured{}
+	pod.SetAPIVersion("v1")
+	pod.SetKind("Pod")
+	pod.SetName(name)
+	return pod
+}
+
+func TestAddTemplateDefersCompilation(t *testing.T) {
+	c, inner := makeLazyClient(t)
+	if _, err := c.AddTemplate(context.Background(), denyAllTemplate()); err != nil {
+		t.Fatalf("could not add template: %s", err)
+	}
+	if _, err := inner.GetTemplate(context.Background(), denyAllTemplate()); err == nil {
+		t.Errorf("template should not have been compiled into the inner client")
+	}
+	if _, err := c.GetTemplate(context.Background(), denyAllTemplate()); err != nil {
+		t.Errorf("pending template should be readable through the lazy client: %s", err)
+	}
+}
+
+func TestReviewCompilesMatchingTemplate(t *testing.T) {
+	c, inner := makeLazyClient(t)
+	if _, err := c.AddTemplate(context.Background(), denyAllTemplate()); err != nil {
+		t.Fatalf("could not add template: %s", err)
+	}
+	if _, err := c.AddConstraint(context.Background(), makeConstraint("pods-only", "Pod")); err != nil {
+		t.Fatalf("could not add constraint: %s", err)
+	}
+
+	resp, err := c.Review(context.Background(), target.AugmentedUnstructured{Object: *makePod("busybox")})
+	if err != nil {
+		t.Fatalf("review failed: %s", err)
+	}
+	if len(resp.Results()) != 1 {
+		t.Errorf("expected 1 violation after lazy compilation, got %d", len(resp.Results()))
+	}
+	if _, err := inner.GetTemplate(context.Background(), denyAllTemplate()); err != nil {
+		t.Errorf("template should have been compiled on first match: %s", err)
+	}
+}
+
+func TestReviewSkipsNonMatchingTemplate(t *testing.T) {
+	c, _ := makeLazyClient(t)
+	if _, err := c.AddTemplate(context.Background(), denyAllTemplate()); err != nil {
+		t.Fatalf("could not add template: %s", err)
+	}
+	if _, err := c.AddConstraint(context.Background(), makeConstraint("ingress-only", "Ingress")); err != nil {
+		t.Fatalf("could not add constraint: %s", err)
+	}
+
+	resp, err := c.Review(context.Background(), target.AugmentedUnstructured{Object: *makePod("busybox")})
+	if err != nil {
+		t.Fatalf("review failed: %s", err)
+	}
+	if len(resp.Results()) != 0 {
+		t.Errorf("expected no violations for non-matching kind, got %d", len(resp.Results()))
+	}
+	if len(c.pending) != 1 {
+		t.Errorf("non-matching template should remain pending")
+	}
+}
+
+func TestCompileAll(t *testing.T) {
+	c, inner := makeLazyClient(t)
+	if _, err := c.AddTemplate(context.Background(), denyAllTemplate()); err != nil {
+		t.Fatalf("could not add template: %s", err)
+	}
+	if _, err := c.AddConstraint(context.Background(), makeConstraint("ingress-only", "Ingress")); err != nil {
+		t.Fatalf("could not add constraint: %s", err)
+	}
+	if err := c.CompileAll(context.Background()); err != nil {
+		t.Fatalf("warm-up failed: %s", err)
+	}
+	if len(c.pending) != 0 {
+		t.Errorf("warm-up should drain the pending backlog")
+	}
+	if _, err := inner.GetTemplate(context.Background(), denyAllTemplate()); err != nil {
+		t.Errorf("template should have been compiled by warm-up: %s", err)
+	}
+}
+
+func TestRemovePendingTemplate(t *testing.T) {
+	c, _ := makeLazyClient(t)
+	if _, err := c.AddTemplate(context.Background(), denyAllTemplate()); err != nil {
+		t.Fatalf("could not add template: %s", err)
+	}
+	if _, err := c.RemoveTemplate(context.Background(), denyAllTemplate()); err != nil {
+		t.Fatalf("could not remove pending template: %s", err)
+	}
+	if len(c.pending) != 0 {
+		t.Errorf("removed template should not remain pending")
+	}
+}
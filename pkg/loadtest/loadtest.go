@@ -0,0 +1,267 @@
+// Package loadtest replays admission traffic against an embedded policy
+// set and reports latency percentiles and allocation counts. It backs
+// the loadtest command, which lets policy and Gatekeeper changes be
+// gated on performance regressions without a running cluster.
+package loadtest
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/client/drivers/local"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/core/templates"
+	"github.com/open-policy-agent/gatekeeper/apis"
+	"github.com/open-policy-agent/gatekeeper/pkg/opashard"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	runtimeScheme = k8sruntime.NewScheme()
+	codecs        = serializer.NewCodecFactory(runtimeScheme)
+	deserializer  = codecs.UniversalDeserializer()
+)
+
+func init() {
+	_ = apis.AddToScheme(runtimeScheme)
+}
+
+// Runner drives reviews against a policy client.
+type Runner struct {
+	Client      opashard.Client
+	Count       int
+	Concurrency int
+	Objects     []*unstructured.Unstructured
+}
+
+// Result summarizes one load test run.
+type Result struct {
+	Total           int
+	Violations      int
+	Duration        time.Duration
+	P50, P90, P99   time.Duration
+	Max             time.Duration
+	AllocsPerReview uint64
+	BytesPerReview  uint64
+}
+
+// String renders the result in a form suitable for perf gate logs.
+func (r Result) String() string {
+	return fmt.Sprintf(
+		"reviews=%d violations=%d duration=%s qps=%.0f p50=%s p90=%s p99=%s max=%s allocs/review=%d bytes/review=%d",
+		r.Total, r.Violations, r.Duration.Round(time.Millisecond),
+		float64(r.Total)/r.Duration.Seconds(),
+		r.P50, r.P90, r.P99, r.Max,
+		r.AllocsPerReview, r.BytesPerReview,
+	)
+}
+
+// Run replays the configured objects through the client and collects
+// latency and allocation statistics.
+func (r *Runner) Run(ctx context.Context) (Result, error) {
+	if r.Count <= 0 {
+		r.Count = 1000
+	}
+	if r.Concurrency <= 0 {
+		r.Concurrency = 1
+	}
+	if len(r.Objects) == 0 {
+		r.Objects = SyntheticObjects(10)
+	}
+
+	latencies := make([]time.Duration, r.Count)
+	violations := make([]int, r.Concurrency)
+	var firstErr error
+	var errMux sync.Mutex
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for w := 0; w < r.Concurrency; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := worker; i < r.Count; i += r.Concurrency {
+				obj := r.Objects[i%len(r.Objects)]
+				reviewStart := time.Now()
+				resp, err := r.Client.Review(ctx, target.AugmentedUnstructured{Object: *obj})
+				latencies[i] = time.Since(reviewStart)
+				if err != nil {
+					errMux.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					errMux.Unlock()
+					return
+				}
+				violations[worker] += len(resp.Results())
+			}
+		}(w)
+	}
+	wg.Wait()
+	duration := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	if firstErr != nil {
+		return Result{}, firstErr
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	res := Result{
+		Total:           r.Count,
+		Duration:        duration,
+		P50:             percentile(latencies, 50),
+		P90:             percentile(latencies, 90),
+		P99:             percentile(latencies, 99),
+		Max:             latencies[len(latencies)-1],
+		AllocsPerReview: (after.Mallocs - before.Mallocs) / uint64(r.Count),
+		BytesPerReview:  (after.TotalAlloc - before.TotalAlloc) / uint64(r.Count),
+	}
+	for _, v := range violations {
+		res.Violations += v
+	}
+	return res, nil
+}
+
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := len(sorted) * p / 100
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+// SyntheticObjects generates namespaced Pods with varying names and
+// labels for synthetic traffic.
+func SyntheticObjects(n int) []*unstructured.Unstructured {
+	out := make([]*unstructured.Unstructured, n)
+	for i := 0; i < n; i++ {
+		pod := &unstructured.Unstructured{}
+		pod.SetAPIVersion("v1")
+		pod.SetKind("Pod")
+		pod.SetName("loadtest-pod-" + strconv.Itoa(i))
+		pod.SetNamespace("loadtest")
+		pod.SetLabels(map[string]string{"app": "loadtest", "replica": strconv.Itoa(i)})
+		out[i] = pod
+	}
+	return out
+}
+
+// NewClient builds an embedded policy client in the same configuration
+// the webhook uses.
+func NewClient() (*opa.Client, error) {
+	driver := local.New(local.Tracing(false))
+	backend, err := opa.NewBackend(opa.Driver(driver))
+	if err != nil {
+		return nil, err
+	}
+	return backend.NewClient(opa.Targets(&target.K8sValidationTarget{}))
+}
+
+// LoadTemplates reads constraint template YAML files from dir and adds
+// them to the client.
+func LoadTemplates(ctx context.Context, client *opa.Client, dir string) (int, error) {
+	count := 0
+	err := eachYAML(dir, func(data []byte) error {
+		obj, _, err := deserializer.Decode(data, nil, nil)
+		if err != nil {
+			return err
+		}
+		unversioned := &templates.ConstraintTemplate{}
+		if err := runtimeScheme.Convert(obj, unversioned, nil); err != nil {
+			return err
+		}
+		if _, err := client.AddTemplate(ctx, unversioned); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// LoadConstraints reads constraint YAML files from dir and adds them to
+// the client.
+func LoadConstraints(ctx context.Context, client *opa.Client, dir string) (int, error) {
+	count := 0
+	err := eachYAML(dir, func(data []byte) error {
+		cstr, err := toUnstructured(data)
+		if err != nil {
+			return err
+		}
+		if _, err := client.AddConstraint(ctx, cstr); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// LoadObjects reads recorded objects from dir to use as replay traffic.
+func LoadObjects(dir string) ([]*unstructured.Unstructured, error) {
+	var out []*unstructured.Unstructured
+	err := eachYAML(dir, func(data []byte) error {
+		obj, err := toUnstructured(data)
+		if err != nil {
+			return err
+		}
+		out = append(out, obj)
+		return nil
+	})
+	return out, err
+}
+
+func toUnstructured(data []byte) (*unstructured.Unstructured, error) {
+	js, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(js); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func eachYAML(dir string, fn func(data []byte) error) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		ext := filepath.Ext(f.Name())
+		if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return err
+		}
+		if err := fn(data); err != nil {
+			return fmt.Errorf("%s: %w", f.Name(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package loadtest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/open-policy-agent/frameworks/constraint/pkg/core/templates"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func denyAllTemplate() *templates.ConstraintTemplate {
+	templ := &templates.ConstraintTemplate{}
+	templ.SetName("denyall")
+	templ.Spec.CRD.Spec.Names.Kind = "DenyAll"
+	templ.Spec.Targets = []templates.Target{{
+		Target: (&target.K8sValidationTarget{}).GetName(),
+		Rego: `
+package denyall
+
+violation[{"msg": msg}] {
+  msg := "denied by constraint"
+}
+`,
+	}}
+	return templ
+}
+
+func makeConstraint(name string) *unstructured.Unstructured {
+	cstr := &unstructured.Unstructured{}
+	cstr.SetAPIVersion("constraints.gatekeeper.sh/v1beta1")
+	cstr.SetKind("DenyAll")
+	cstr.SetName(name)
+	return cstr
+}
+
+func TestRunnerReportsLatencyAndViolations(t *testing.T) {
+	client, err := NewClient()
+	if err != nil {
+		t.Fatalf("could not initialize OPA: %s", err)
+	}
+	if _, err := client.AddTemplate(context.Background(), denyAllTemplate()); err != nil {
+		t.Fatalf("could not add template: %s", err)
+	}
+	if _, err := client.AddConstraint(context.Background(), makeConstraint("deny-everything")); err != nil {
+		t.Fatalf("could not add constraint: %s", err)
+	}
+
+	runner := &Runner{Client: client, Count: 40, Concurrency: 4}
+	result, err := runner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("run failed: %s", err)
+	}
+	if result.Total != 40 {
+		t.Errorf("expected 40 reviews, got %d", result.Total)
+	}
+	if result.Violations != 40 {
+		t.Errorf("expected a violation per review, got %d", result.Violations)
+	}
+	if result.P99 < result.P50 {
+		t.Errorf("p99 (%s) should not be below p50 (%s)", result.P99, result.P50)
+	}
+	if result.Max <= 0 {
+		t.Errorf("expected a positive max latency, got %s", result.Max)
+	}
+}
+
+func TestSyntheticObjects(t *testing.T) {
+	objs := SyntheticObjects(5)
+	if len(objs) != 5 {
+		t.Fatalf("expected 5 objects, got %d", len(objs))
+	}
+	for _, obj := range objs {
+		if obj.GetKind() != "Pod" || obj.GetNamespace() == "" {
+			t.Errorf("synthetic object %s should be a namespaced Pod", obj.GetName())
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := make([]time.Duration, 100)
+	for i := range sorted {
+		sorted[i] = time.Duration(i+1) * time.Millisecond
+	}
+	tests := []struct {
+		p    int
+		want time.Duration
+	}{
+		{p: 50, want: 51 * time.Millisecond},
+		{p: 99, want: 100 * time.Millisecond},
+		{p: 100, want: 100 * time.Millisecond},
+	}
+	for _, tc := range tests {
+		if got := percentile(sorted, tc.p); got != tc.want {
+			t.Errorf("percentile(%d) = %s, want %s", tc.p, got, tc.want)
+		}
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("percentile of empty slice should be 0, got %s", got)
+	}
+}
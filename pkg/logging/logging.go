@@ -11,6 +11,8 @@ const (
 	ConstraintAPIVersion = "constraint_api_version"
 	ConstraintStatus     = "constraint_status"
 	ConstraintAction     = "constraint_action"
+	ConstraintOwner      = "constraint_owner"
+	ConstraintContact    = "constraint_contact"
 	AuditID              = "audit_id"
 	ConstraintViolations = "constraint_violations"
 	ResourceGroup        = "resource_group"
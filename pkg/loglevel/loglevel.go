@@ -0,0 +1,123 @@
+// Package loglevel lets operators change the minimum log level of a
+// running gatekeeper pod without a restart. The logger is built around a
+// shared zap AtomicLevel, and a small HTTP endpoint flips that level at
+// runtime — so debug logging can be switched on while a misbehaving
+// webhook is actively rejecting requests, then switched back off.
+package loglevel
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/httpguard"
+	"github.com/open-policy-agent/gatekeeper/pkg/logging"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("loglevel").WithValues(logging.Process, "loglevel")
+
+var (
+	enableLogLevelEndpoint = flag.Bool("enable-log-level-endpoint", false, "(alpha) enable the runtime log level endpoint")
+	logLevelAddr           = flag.String("log-level-addr", ":8893", "The address the runtime log level endpoint binds to.")
+)
+
+// level is shared between the logger built at startup and the endpoint
+// that adjusts it afterwards.
+var level = zap.NewAtomicLevelAt(zapcore.InfoLevel)
+
+// Enabled returns true when the runtime log level endpoint was requested
+// via flag.
+func Enabled() bool {
+	return *enableLogLevelEndpoint
+}
+
+// Enabler seeds the shared level from the --log-level flag and returns
+// it for wiring into the logger options.
+func Enabler(initial zapcore.Level) zapcore.LevelEnabler {
+	level.SetLevel(initial)
+	return level
+}
+
+// parseLevel accepts both zap spellings (debug, warn) and the spellings
+// the --log-level flag documents (DEBUG, WARNING).
+func parseLevel(s string) (zapcore.Level, error) {
+	s = strings.ToLower(s)
+	if s == "warning" {
+		s = "warn"
+	}
+	var l zapcore.Level
+	err := l.UnmarshalText([]byte(s))
+	return l, err
+}
+
+// Server serves the current log level and accepts updates. It implements
+// manager.Runnable so it can be registered with the controller manager
+// like the other optional servers.
+type Server struct {
+	addr string
+}
+
+// NewServer returns a Server configured from the log level flags.
+func NewServer() *Server {
+	return &Server{addr: *logLevelAddr}
+}
+
+// Start implements manager.Runnable.
+func (s *Server) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.Handle("/v1/loglevel", httpguard.Wrap(http.HandlerFunc(handleLogLevel)))
+	srv := &http.Server{Addr: s.addr, Handler: mux}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+// levelBody is the endpoint's wire format in both directions.
+type levelBody struct {
+	Level string `json:"level"`
+}
+
+// handleLogLevel serves the current level on GET and adjusts it on PUT.
+func handleLogLevel(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(levelBody{Level: level.Level().String()}); err != nil {
+			log.Error(err, "unable to encode log level")
+		}
+	case http.MethodPut:
+		var body levelBody
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		parsed, err := parseLevel(body.Level)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		previous := level.Level()
+		level.SetLevel(parsed)
+		log.Info("log level changed", "previous", previous.String(), "current", parsed.String())
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
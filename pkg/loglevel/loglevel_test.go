@@ -0,0 +1,79 @@
+package loglevel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestParseLevel(t *testing.T) {
+	tc := []struct {
+		in      string
+		want    zapcore.Level
+		wantErr bool
+	}{
+		{in: "debug", want: zapcore.DebugLevel},
+		{in: "DEBUG", want: zapcore.DebugLevel},
+		{in: "info", want: zapcore.InfoLevel},
+		{in: "warn", want: zapcore.WarnLevel},
+		{in: "WARNING", want: zapcore.WarnLevel},
+		{in: "ERROR", want: zapcore.ErrorLevel},
+		{in: "verbose", wantErr: true},
+	}
+	for _, tt := range tc {
+		got, err := parseLevel(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseLevel(%q): expected error, got none", tt.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseLevel(%q): unexpected error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseLevel(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestHandleLogLevel(t *testing.T) {
+	Enabler(zapcore.InfoLevel)
+
+	w := httptest.NewRecorder()
+	handleLogLevel(w, httptest.NewRequest(http.MethodGet, "/v1/loglevel", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET returned status %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `"info"`) {
+		t.Errorf("GET returned %q, want the current level", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	handleLogLevel(w, httptest.NewRequest(http.MethodPut, "/v1/loglevel", strings.NewReader(`{"level":"DEBUG"}`)))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("PUT returned status %d: %s", w.Code, w.Body.String())
+	}
+	if got := level.Level(); got != zapcore.DebugLevel {
+		t.Errorf("level after PUT = %v, want debug", got)
+	}
+
+	w = httptest.NewRecorder()
+	handleLogLevel(w, httptest.NewRequest(http.MethodPut, "/v1/loglevel", strings.NewReader(`{"level":"verbose"}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("PUT with an invalid level returned status %d, want 400", w.Code)
+	}
+	if got := level.Level(); got != zapcore.DebugLevel {
+		t.Errorf("level after rejected PUT = %v, want debug unchanged", got)
+	}
+
+	w = httptest.NewRecorder()
+	handleLogLevel(w, httptest.NewRequest(http.MethodPost, "/v1/loglevel", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST returned status %d, want 405", w.Code)
+	}
+}
@@ -0,0 +1,231 @@
+// Package matchindex maintains an index from the API groups, kinds and
+// namespaces a constraint's match rules cover to the constraint itself.
+// Admission requests for objects no constraint can possibly match are
+// answered without entering the policy engine, so clusters with many
+// narrowly-scoped constraints stop paying for every constraint's match
+// rules on every request. The index deliberately over-approximates:
+// selectors it does not understand (label selectors, scope) are treated
+// as matching, so a request is only skipped when no constraint can
+// apply.
+package matchindex
+
+import (
+	"context"
+	"flag"
+	"strings"
+	"sync"
+
+	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/core/templates"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/types"
+	"github.com/open-policy-agent/gatekeeper/pkg/opashard"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var gvkMatchIndex = flag.Bool("gvk-match-index", false, "(alpha) index constraints by the groups, kinds and namespaces they match so admission requests no constraint can match skip policy evaluation")
+
+// Enabled returns true when the GVK match index was requested via flags.
+func Enabled() bool {
+	return *gvkMatchIndex
+}
+
+// entry captures the parts of one constraint's match rules the index
+// understands.
+type entry struct {
+	groups     map[string]bool // empty means all groups
+	kinds      map[string]bool // empty means all kinds
+	namespaces map[string]bool // empty means all namespaces
+	excluded   map[string]bool
+}
+
+// matches returns whether the constraint may apply to an object of the
+// given group, kind and namespace. Unknown selectors are assumed to
+// match.
+func (e *entry) matches(group, kind, namespace string) bool {
+	if len(e.groups) > 0 && !e.groups["*"] && !e.groups[group] {
+		return false
+	}
+	if len(e.kinds) > 0 && !e.kinds["*"] && !e.kinds[kind] {
+		return false
+	}
+	// Cluster-scoped objects are never excluded by namespace rules.
+	if namespace == "" {
+		return true
+	}
+	if len(e.namespaces) > 0 && !e.namespaces[namespace] {
+		return false
+	}
+	if e.excluded[namespace] {
+		return false
+	}
+	return true
+}
+
+// newEntry indexes a constraint's spec.match. A constraint without match
+// kinds matches every group and kind.
+func newEntry(u *unstructured.Unstructured) *entry {
+	e := &entry{
+		groups:     map[string]bool{},
+		kinds:      map[string]bool{},
+		namespaces: map[string]bool{},
+		excluded:   map[string]bool{},
+	}
+	matchKinds, found, err := unstructured.NestedSlice(u.Object, "spec", "match", "kinds")
+	if err == nil && found {
+		for _, mk := range matchKinds {
+			m, ok := mk.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			addStrings(e.groups, m["apiGroups"])
+			addStrings(e.kinds, m["kinds"])
+		}
+	}
+	if nss, found, err := unstructured.NestedStringSlice(u.Object, "spec", "match", "namespaces"); err == nil && found {
+		for _, ns := range nss {
+			e.namespaces[ns] = true
+		}
+	}
+	if nss, found, err := unstructured.NestedStringSlice(u.Object, "spec", "match", "excludedNamespaces"); err == nil && found {
+		for _, ns := range nss {
+			e.excluded[ns] = true
+		}
+	}
+	return e
+}
+
+func addStrings(set map[string]bool, val interface{}) {
+	items, ok := val.([]interface{})
+	if !ok {
+		return
+	}
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			set[s] = true
+		}
+	}
+}
+
+// Client wraps an OPA client with a constraint match index that
+// short-circuits reviews no constraint can match. It implements
+// opashard.Client.
+type Client struct {
+	opashard.Client
+
+	mux     sync.RWMutex
+	entries map[string]*entry // by constraint kind + "/" + name
+}
+
+var _ opashard.Client = &Client{}
+
+// New wraps inner with a constraint match index.
+func New(inner opashard.Client) *Client {
+	return &Client{
+		Client:  inner,
+		entries: make(map[string]*entry),
+	}
+}
+
+func constraintKey(u *unstructured.Unstructured) string {
+	return u.GetKind() + "/" + u.GetName()
+}
+
+// AddConstraint indexes the constraint's match rules.
+func (c *Client) AddConstraint(ctx context.Context, u *unstructured.Unstructured) (*types.Responses, error) {
+	resp, err := c.Client.AddConstraint(ctx, u)
+	if err != nil {
+		return resp, err
+	}
+	c.mux.Lock()
+	c.entries[constraintKey(u)] = newEntry(u)
+	c.mux.Unlock()
+	return resp, nil
+}
+
+// RemoveConstraint drops the constraint from the index.
+func (c *Client) RemoveConstraint(ctx context.Context, u *unstructured.Unstructured) (*types.Responses, error) {
+	resp, err := c.Client.RemoveConstraint(ctx, u)
+	if err != nil {
+		return resp, err
+	}
+	c.mux.Lock()
+	delete(c.entries, constraintKey(u))
+	c.mux.Unlock()
+	return resp, nil
+}
+
+// RemoveTemplate drops the index entries for the template's constraint
+// kind; the engine removes those constraints alongside the template.
+func (c *Client) RemoveTemplate(ctx context.Context, templ *templates.ConstraintTemplate) (*types.Responses, error) {
+	resp, err := c.Client.RemoveTemplate(ctx, templ)
+	if err != nil {
+		return resp, err
+	}
+	prefix := templ.Spec.CRD.Spec.Names.Kind + "/"
+	c.mux.Lock()
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+		}
+	}
+	c.mux.Unlock()
+	return resp, nil
+}
+
+// Review short-circuits when no indexed constraint can match the
+// reviewed object, and delegates otherwise. Review payloads the index
+// cannot classify are always delegated.
+func (c *Client) Review(ctx context.Context, obj interface{}, opts ...opa.QueryOpt) (*types.Responses, error) {
+	group, kind, namespace, ok := reviewSubject(obj)
+	if ok && !c.anyMatch(group, kind, namespace) {
+		return types.NewResponses(), nil
+	}
+	return c.Client.Review(ctx, obj, opts...)
+}
+
+// anyMatch returns whether any indexed constraint may apply.
+func (c *Client) anyMatch(group, kind, namespace string) bool {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	for _, e := range c.entries {
+		if e.matches(group, kind, namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// reviewSubject extracts the group, kind and namespace being reviewed.
+func reviewSubject(obj interface{}) (group, kind, namespace string, ok bool) {
+	switch data := obj.(type) {
+	case target.AugmentedReview:
+		return subjectFromReview(&data)
+	case *target.AugmentedReview:
+		return subjectFromReview(data)
+	case target.AugmentedUnstructured:
+		return subjectFromObject(&data.Object)
+	case *target.AugmentedUnstructured:
+		return subjectFromObject(&data.Object)
+	case unstructured.Unstructured:
+		return subjectFromObject(&data)
+	case *unstructured.Unstructured:
+		return subjectFromObject(data)
+	}
+	return "", "", "", false
+}
+
+func subjectFromReview(r *target.AugmentedReview) (string, string, string, bool) {
+	if r.AdmissionRequest == nil {
+		return "", "", "", false
+	}
+	return r.AdmissionRequest.Kind.Group, r.AdmissionRequest.Kind.Kind, r.AdmissionRequest.Namespace, true
+}
+
+func subjectFromObject(u *unstructured.Unstructured) (string, string, string, bool) {
+	gvk := u.GroupVersionKind()
+	if gvk.Kind == "" {
+		return "", "", "", false
+	}
+	return gvk.Group, gvk.Kind, u.GetNamespace(), true
+}
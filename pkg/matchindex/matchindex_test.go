@@ -0,0 +1,184 @@
+package matchindex
+
+import (
+	"context"
+	"testing"
+
+	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/client/drivers/local"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/core/templates"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func makeOpaClient() (*opa.Client, error) {
+	t := &target.K8sValidationTarget{}
+	driver := local.New(local.Tracing(false))
+	backend, err := opa.NewBackend(opa.Driver(driver))
+	if err != nil {
+		return nil, err
+	}
+	return backend.NewClient(opa.Targets(t))
+}
+
+func makeIndexedClient(t *testing.T) *Client {
+	inner, err := makeOpaClient()
+	if err != nil {
+		t.Fatalf("could not initialize OPA: %s", err)
+	}
+	return New(inner)
+}
+
+func denyAllTemplate() *templates.ConstraintTemplate {
+	templ := &templates.ConstraintTemplate{}
+	templ.SetName("denyall")
+	templ.Spec.CRD.Spec.Names.Kind = "DenyAll"
+	templ.Spec.Targets = []templates.Target{{
+		Target: (&target.K8sValidationTarget{}).GetName(),
+		Rego: `
+package denyall
+
+violation[{"msg": msg}] {
+  msg := "denied by constraint"
+}
+`,
+	}}
+	return templ
+}
+
+func makeConstraint(name string, kinds ...string) *unstructured.Unstructured {
+	cstr := &unstructured.Unstructured{}
+	cstr.SetAPIVersion("constraints.gatekeeper.sh/v1beta1")
+	cstr.SetKind("DenyAll")
+	cstr.SetName(name)
+	if len(kinds) > 0 {
+		matchKinds := []interface{}{
+			map[string]interface{}{
+				"apiGroups": []interface{}{""},
+				"kinds":     toInterfaces(kinds),
+			},
+		}
+		if err := unstructured.SetNestedSlice(cstr.Object, matchKinds, "spec", "match", "kinds"); err != nil {
+			panic(err)
+		}
+	}
+	return cstr
+}
+
+func toInterfaces(ss []string) []interface{} {
+	out := make([]interface{}, len(ss))
+	for i, s := range ss {
+		out[i] = s
+	}
+	return out
+}
+
+func makeObject(kind, namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+func addTemplateAndConstraint(t *testing.T, c *Client, cstr *unstructured.Unstructured) {
+	if _, err := c.AddTemplate(context.Background(), denyAllTemplate()); err != nil {
+		t.Fatalf("could not add template: %s", err)
+	}
+	if _, err := c.AddConstraint(context.Background(), cstr); err != nil {
+		t.Fatalf("could not add constraint: %s", err)
+	}
+}
+
+func TestReviewSkipsUnmatchedKind(t *testing.T) {
+	c := makeIndexedClient(t)
+	addTemplateAndConstraint(t, c, makeConstraint("pods-only", "Pod"))
+
+	resp, err := c.Review(context.Background(), target.AugmentedUnstructured{Object: *makeObject("Service", "default", "web")})
+	if err != nil {
+		t.Fatalf("review failed: %s", err)
+	}
+	if len(resp.Results()) != 0 {
+		t.Errorf("expected no violations for unmatched kind, got %d", len(resp.Results()))
+	}
+}
+
+func TestReviewEvaluatesMatchedKind(t *testing.T) {
+	c := makeIndexedClient(t)
+	addTemplateAndConstraint(t, c, makeConstraint("pods-only", "Pod"))
+
+	resp, err := c.Review(context.Background(), target.AugmentedUnstructured{Object: *makeObject("Pod", "default", "busybox")})
+	if err != nil {
+		t.Fatalf("review failed: %s", err)
+	}
+	if len(resp.Results()) != 1 {
+		t.Errorf("expected 1 violation for matched kind, got %d", len(resp.Results()))
+	}
+}
+
+func TestReviewEvaluatesWildcardConstraint(t *testing.T) {
+	c := makeIndexedClient(t)
+	addTemplateAndConstraint(t, c, makeConstraint("match-everything"))
+
+	resp, err := c.Review(context.Background(), target.AugmentedUnstructured{Object: *makeObject("Service", "default", "web")})
+	if err != nil {
+		t.Fatalf("review failed: %s", err)
+	}
+	if len(resp.Results()) != 1 {
+		t.Errorf("expected 1 violation from wildcard constraint, got %d", len(resp.Results()))
+	}
+}
+
+func TestEntryNamespaceRules(t *testing.T) {
+	cstr := makeConstraint("scoped", "Pod")
+	if err := unstructured.SetNestedStringSlice(cstr.Object, []string{"allowed"}, "spec", "match", "namespaces"); err != nil {
+		t.Fatal(err)
+	}
+	if err := unstructured.SetNestedStringSlice(cstr.Object, []string{"kube-system"}, "spec", "match", "excludedNamespaces"); err != nil {
+		t.Fatal(err)
+	}
+	e := newEntry(cstr)
+
+	tests := []struct {
+		name      string
+		namespace string
+		want      bool
+	}{
+		{name: "listed namespace matches", namespace: "allowed", want: true},
+		{name: "unlisted namespace does not match", namespace: "other", want: false},
+		{name: "cluster-scoped objects always match", namespace: "", want: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := e.matches("", "Pod", tc.namespace); got != tc.want {
+				t.Errorf("matches(%q) = %v, want %v", tc.namespace, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRemoveConstraintDropsIndexEntry(t *testing.T) {
+	c := makeIndexedClient(t)
+	cstr := makeConstraint("pods-only", "Pod")
+	addTemplateAndConstraint(t, c, cstr)
+
+	if _, err := c.RemoveConstraint(context.Background(), cstr); err != nil {
+		t.Fatalf("could not remove constraint: %s", err)
+	}
+	if len(c.entries) != 0 {
+		t.Errorf("removed constraint should not remain indexed")
+	}
+}
+
+func TestRemoveTemplateDropsKindEntries(t *testing.T) {
+	c := makeIndexedClient(t)
+	addTemplateAndConstraint(t, c, makeConstraint("pods-only", "Pod"))
+
+	if _, err := c.RemoveTemplate(context.Background(), denyAllTemplate()); err != nil {
+		t.Fatalf("could not remove template: %s", err)
+	}
+	if len(c.entries) != 0 {
+		t.Errorf("constraints of a removed template should not remain indexed")
+	}
+}
@@ -1,14 +1,22 @@
 package metrics
 
 import (
+	"crypto/tls"
+	"flag"
 	"fmt"
 	"net/http"
+	"path/filepath"
 
 	"contrib.go.opencensus.io/exporter/prometheus"
+	"github.com/open-policy-agent/gatekeeper/pkg/fips"
+	"github.com/open-policy-agent/gatekeeper/pkg/httpguard"
+	"github.com/open-policy-agent/gatekeeper/pkg/tlsopts"
 	"go.opencensus.io/stats/view"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+var metricsTLSCertDir = flag.String("metrics-tls-cert-dir", "", "directory containing tls.crt and tls.key for serving Prometheus metrics over TLS; metrics are served over plain HTTP when unset")
+
 var curPromSrv *http.Server
 
 var log = logf.Log.WithName("metrics")
@@ -25,7 +33,17 @@ func newPrometheusExporter() (view.Exporter, error) {
 	log.Info("Starting server for OpenCensus Prometheus exporter")
 	// Start the server for Prometheus scraping
 	srv := startNewPromSrv(e, *prometheusPort)
-	errCh <- srv.ListenAndServe()
+	if *metricsTLSCertDir != "" {
+		cfg := &tls.Config{}
+		tlsopts.ApplyTo(cfg)
+		if fips.Enabled() {
+			fips.ApplyTo(cfg)
+		}
+		srv.TLSConfig = cfg
+		errCh <- srv.ListenAndServeTLS(filepath.Join(*metricsTLSCertDir, "tls.crt"), filepath.Join(*metricsTLSCertDir, "tls.key"))
+	} else {
+		errCh <- srv.ListenAndServe()
+	}
 	err = <-errCh
 	if err != nil {
 		return nil, err
@@ -35,7 +53,7 @@ func newPrometheusExporter() (view.Exporter, error) {
 
 func startNewPromSrv(e *prometheus.Exporter, port int) *http.Server {
 	sm := http.NewServeMux()
-	sm.Handle("/metrics", e)
+	sm.Handle("/metrics", httpguard.Wrap(e))
 	curPromSrv = &http.Server{
 		Addr:    fmt.Sprintf(":%v", port),
 		Handler: sm,
@@ -80,6 +80,11 @@ func (m *AssignMutator) ID() types.ID {
 	return m.id
 }
 
+// Order returns the explicit order declared on the Assign resource.
+func (m *AssignMutator) Order() int {
+	return m.assign.Spec.Order
+}
+
 func (m *AssignMutator) SchemaBindings() []schema.Binding {
 	return m.bindings
 }
@@ -69,6 +69,12 @@ func (m *AssignMetadataMutator) ID() types.ID {
 	return m.id
 }
 
+// Order returns the explicit order declared on the AssignMetadata
+// resource.
+func (m *AssignMetadataMutator) Order() int {
+	return m.assignMetadata.Spec.Order
+}
+
 func (m *AssignMetadataMutator) Path() *parser.Path {
 	return m.path
 }
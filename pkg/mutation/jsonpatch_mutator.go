@@ -0,0 +1,221 @@
+package mutation
+
+import (
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/google/go-cmp/cmp"
+	mutationsv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/mutations/v1alpha1"
+	"github.com/open-policy-agent/gatekeeper/pkg/mutation/path/parser"
+	"github.com/open-policy-agent/gatekeeper/pkg/mutation/types"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// JSONPatchMutator is a mutator object built out of a JSONPatch
+// instance. Unlike Assign it applies raw RFC 6902 operations, so it can
+// express mutations the location-based mutators cannot — removing a
+// list element at an index, copying one field to another, or testing a
+// value before replacing it. It carries no schema bindings: JSON
+// pointers do not distinguish a list index from an object key the way
+// location paths do, so patches are exempt from schema conflict
+// detection and simply fail at apply time if the document shape does
+// not match.
+type JSONPatchMutator struct {
+	id    types.ID
+	jp    *mutationsv1alpha1.JSONPatch
+	patch jsonpatch.Patch
+}
+
+var _ types.Mutator = &JSONPatchMutator{}
+
+func (m *JSONPatchMutator) Matches(obj runtime.Object, ns *corev1.Namespace) bool {
+	if !m.appliesTo(obj) {
+		return false
+	}
+	matches, err := Matches(m.jp.Spec.Match, obj, ns)
+	if err != nil {
+		log.Error(err, "JSONPatchMutator.Matches failed", "jsonpatch", m.jp.Name)
+		return false
+	}
+	return matches
+}
+
+// appliesTo checks the object against spec.applyTo; patches carry no
+// schema bindings, so the GVK filter is applied here instead.
+func (m *JSONPatchMutator) appliesTo(obj runtime.Object) bool {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	for _, applyTo := range m.jp.Spec.ApplyTo {
+		if contains(applyTo.Groups, gvk.Group) && contains(applyTo.Kinds, gvk.Kind) && contains(applyTo.Versions, gvk.Version) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *JSONPatchMutator) Mutate(obj *unstructured.Unstructured) error {
+	doc, err := json.Marshal(obj.Object)
+	if err != nil {
+		return errors.Wrapf(err, "marshaling object for JSONPatch %s", m.jp.GetName())
+	}
+	patched, err := m.patch.Apply(doc)
+	if err != nil {
+		// a patch that does not apply — a remove of an already-absent
+		// path, a failed test — leaves the object unchanged instead of
+		// failing the request. This gives test its conditional meaning
+		// and keeps reapplication idempotent, which the mutation
+		// system's convergence loop requires.
+		log.V(1).Info("jsonpatch did not apply", "jsonpatch", m.jp.GetName(), "reason", err.Error())
+		return nil
+	}
+	result := map[string]interface{}{}
+	if err := json.Unmarshal(patched, &result); err != nil {
+		return errors.Wrapf(err, "unmarshaling result of JSONPatch %s", m.jp.GetName())
+	}
+	obj.Object = result
+	return nil
+}
+
+func (m *JSONPatchMutator) ID() types.ID {
+	return m.id
+}
+
+// Order returns the explicit order declared on the JSONPatch resource.
+func (m *JSONPatchMutator) Order() int {
+	return m.jp.Spec.Order
+}
+
+func (m *JSONPatchMutator) HasDiff(mutator types.Mutator) bool {
+	toCheck, ok := mutator.(*JSONPatchMutator)
+	if !ok { // different types, different
+		return true
+	}
+	if !cmp.Equal(toCheck.id, m.id) {
+		return true
+	}
+	// any difference in spec may be enough
+	if !cmp.Equal(toCheck.jp.Spec, m.jp.Spec) {
+		return true
+	}
+	return false
+}
+
+// Path returns nil; JSON pointers are not location paths and patches
+// do not participate in the mutation schema.
+func (m *JSONPatchMutator) Path() *parser.Path {
+	return nil
+}
+
+func (m *JSONPatchMutator) Value() (interface{}, error) {
+	return m.jp.Spec.Parameters.Operations, nil
+}
+
+func (m *JSONPatchMutator) DeepCopy() types.Mutator {
+	res := &JSONPatchMutator{
+		id: m.id,
+		jp: m.jp.DeepCopy(),
+	}
+	// the decoded patch is immutable once built
+	res.patch = m.patch
+	return res
+}
+
+// MutatorForJSONPatch returns a JSONPatchMutator built from the given
+// JSONPatch instance.
+func MutatorForJSONPatch(jp *mutationsv1alpha1.JSONPatch) (*JSONPatchMutator, error) {
+	id, err := types.MakeID(jp)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve id for jsonpatch type")
+	}
+	if err := validatePatchOperations(jp); err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(jp.Spec.Parameters.Operations)
+	if err != nil {
+		return nil, errors.Wrapf(err, "marshaling operations for JSONPatch %s", jp.GetName())
+	}
+	patch, err := jsonpatch.DecodePatch(raw)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid operations for JSONPatch %s", jp.GetName())
+	}
+	return &JSONPatchMutator{
+		id:    id,
+		jp:    jp.DeepCopy(),
+		patch: patch,
+	}, nil
+}
+
+var patchOps = map[string]bool{
+	"add":     true,
+	"remove":  true,
+	"replace": true,
+	"move":    true,
+	"copy":    true,
+	"test":    true,
+}
+
+// validatePatchOperations checks each operation for the structural
+// mistakes RFC 6902 rejects, plus the gatekeeper rule that mutators
+// other than AssignMetadata must leave metadata alone.
+func validatePatchOperations(jp *mutationsv1alpha1.JSONPatch) error {
+	if len(jp.Spec.Parameters.Operations) == 0 {
+		return fmt.Errorf("JSONPatch %s must declare at least one operation", jp.GetName())
+	}
+	for i, op := range jp.Spec.Parameters.Operations {
+		if !patchOps[op.Op] {
+			return fmt.Errorf("operation %d of JSONPatch %s has unsupported op %q", i, jp.GetName(), op.Op)
+		}
+		if err := validatePointer(op.Path); err != nil {
+			return fmt.Errorf("operation %d of JSONPatch %s: %v", i, jp.GetName(), err)
+		}
+		switch op.Op {
+		case "move", "copy":
+			if err := validatePointer(op.From); err != nil {
+				return fmt.Errorf("operation %d of JSONPatch %s: from: %v", i, jp.GetName(), err)
+			}
+		case "add", "replace", "test":
+			if len(op.Value.Raw) == 0 {
+				return fmt.Errorf("operation %d of JSONPatch %s requires a value", i, jp.GetName())
+			}
+		}
+		if pointsIntoMetadata(op.Path) || (op.Op == "move" && pointsIntoMetadata(op.From)) {
+			return fmt.Errorf("operation %d of JSONPatch %s: jsonpatch %s can't change metadata", i, jp.GetName(), jp.GetName())
+		}
+	}
+	return nil
+}
+
+func validatePointer(pointer string) error {
+	if pointer == "" {
+		return fmt.Errorf("path must not be empty")
+	}
+	if pointer[0] != '/' {
+		return fmt.Errorf("path %q must be a JSON pointer starting with /", pointer)
+	}
+	return nil
+}
+
+func pointsIntoMetadata(pointer string) bool {
+	return pointer == "/metadata" || len(pointer) > len("/metadata") && pointer[:len("/metadata/")] == "/metadata/"
+}
+
+// IsValidJSONPatch returns an error if the given jsonpatch object is
+// not semantically valid
+func IsValidJSONPatch(jp *mutationsv1alpha1.JSONPatch) error {
+	if _, err := MutatorForJSONPatch(jp); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package mutation
+
+import (
+	"encoding/json"
+	"testing"
+
+	mutationsv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/mutations/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func rawValue(v interface{}) runtime.RawExtension {
+	j, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return runtime.RawExtension{Raw: j}
+}
+
+func newJSONPatch(ops []mutationsv1alpha1.PatchOperation) *mutationsv1alpha1.JSONPatch {
+	jp := &mutationsv1alpha1.JSONPatch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "patch",
+		},
+	}
+	jp.Spec.ApplyTo = []mutationsv1alpha1.ApplyTo{
+		{Groups: []string{""}, Kinds: []string{"Pod"}, Versions: []string{"v1"}},
+	}
+	jp.Spec.Parameters.Operations = ops
+	return jp
+}
+
+func podObject() *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "main", "image": "nginx"},
+					map[string]interface{}{"name": "sidecar", "image": "envoy"},
+				},
+			},
+		},
+	}
+	obj.SetGroupVersionKind(schema.GroupVersionKind{Version: "v1", Kind: "Pod"})
+	return obj
+}
+
+func TestJSONPatchRemoveAtIndex(t *testing.T) {
+	jp := newJSONPatch([]mutationsv1alpha1.PatchOperation{
+		{Op: "remove", Path: "/spec/containers/1"},
+	})
+	m, err := MutatorForJSONPatch(jp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	obj := podObject()
+	if err := m.Mutate(obj); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	containers, _, err := unstructured.NestedSlice(obj.Object, "spec", "containers")
+	if err != nil {
+		t.Fatalf("reading containers: %v", err)
+	}
+	if len(containers) != 1 {
+		t.Fatalf("got %d containers, want 1", len(containers))
+	}
+
+	// reapplying must be a no-op, not an error, so the mutation system
+	// can converge
+	if err := m.Mutate(obj); err != nil {
+		t.Fatalf("reapplication should not error: %v", err)
+	}
+	containers, _, _ = unstructured.NestedSlice(obj.Object, "spec", "containers")
+	if len(containers) != 1 {
+		t.Fatalf("reapplication changed the object: %d containers", len(containers))
+	}
+}
+
+func TestJSONPatchCopyField(t *testing.T) {
+	jp := newJSONPatch([]mutationsv1alpha1.PatchOperation{
+		{Op: "copy", Path: "/spec/hostname", From: "/spec/containers/0/name"},
+	})
+	m, err := MutatorForJSONPatch(jp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	obj := podObject()
+	if err := m.Mutate(obj); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	hostname, _, err := unstructured.NestedString(obj.Object, "spec", "hostname")
+	if err != nil {
+		t.Fatalf("reading hostname: %v", err)
+	}
+	if hostname != "main" {
+		t.Errorf("got hostname %q, want main", hostname)
+	}
+}
+
+func TestJSONPatchFailedTestLeavesObjectUnchanged(t *testing.T) {
+	jp := newJSONPatch([]mutationsv1alpha1.PatchOperation{
+		{Op: "test", Path: "/spec/containers/0/image", Value: rawValue("busybox")},
+		{Op: "replace", Path: "/spec/containers/0/image", Value: rawValue("busybox:pinned")},
+	})
+	m, err := MutatorForJSONPatch(jp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	obj := podObject()
+	if err := m.Mutate(obj); err != nil {
+		t.Fatalf("failed test should not error: %v", err)
+	}
+	image, _, _ := unstructured.NestedString(obj.Object, "spec", "containers", "0")
+	_ = image
+	containers, _, _ := unstructured.NestedSlice(obj.Object, "spec", "containers")
+	first := containers[0].(map[string]interface{})
+	if first["image"] != "nginx" {
+		t.Errorf("object changed despite failed test: image %v", first["image"])
+	}
+}
+
+func TestJSONPatchMatchesApplyTo(t *testing.T) {
+	jp := newJSONPatch([]mutationsv1alpha1.PatchOperation{
+		{Op: "remove", Path: "/spec/containers/1"},
+	})
+	m, err := MutatorForJSONPatch(jp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pod := podObject()
+	if !m.Matches(pod, nil) {
+		t.Errorf("mutator should match a Pod")
+	}
+	svc := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	svc.SetAPIVersion("v1")
+	svc.SetKind("Service")
+	if m.Matches(svc, nil) {
+		t.Errorf("mutator should not match a Service")
+	}
+}
+
+func TestIsValidJSONPatch(t *testing.T) {
+	tc := []struct {
+		name    string
+		ops     []mutationsv1alpha1.PatchOperation
+		wantErr bool
+	}{
+		{
+			name: "valid add",
+			ops:  []mutationsv1alpha1.PatchOperation{{Op: "add", Path: "/spec/priority", Value: rawValue(10)}},
+		},
+		{
+			name:    "no operations",
+			ops:     nil,
+			wantErr: true,
+		},
+		{
+			name:    "unsupported op",
+			ops:     []mutationsv1alpha1.PatchOperation{{Op: "merge", Path: "/spec"}},
+			wantErr: true,
+		},
+		{
+			name:    "path without leading slash",
+			ops:     []mutationsv1alpha1.PatchOperation{{Op: "remove", Path: "spec/priority"}},
+			wantErr: true,
+		},
+		{
+			name:    "move without from",
+			ops:     []mutationsv1alpha1.PatchOperation{{Op: "move", Path: "/spec/priority"}},
+			wantErr: true,
+		},
+		{
+			name:    "add without value",
+			ops:     []mutationsv1alpha1.PatchOperation{{Op: "add", Path: "/spec/priority"}},
+			wantErr: true,
+		},
+		{
+			name:    "metadata is off limits",
+			ops:     []mutationsv1alpha1.PatchOperation{{Op: "add", Path: "/metadata/labels/owner", Value: rawValue("x")}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			err := IsValidJSONPatch(newJSONPatch(tt.ops))
+			if tt.wantErr && err == nil {
+				t.Errorf("expected error, got none")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -52,10 +52,18 @@ func (s *System) Upsert(m types.Mutator) error {
 		}
 	}
 
+	// An update may change the mutator's order, moving it in the list,
+	// so the old entry is removed before the new one is positioned.
+	if ok {
+		if err := s.removeFromList(current); err != nil {
+			return err
+		}
+	}
+
 	s.mutatorsMap[toAdd.ID()] = toAdd
 
 	i := sort.Search(len(s.orderedMutators), func(i int) bool {
-		return greaterOrEqual(s.orderedMutators[i].ID(), toAdd.ID())
+		return greaterOrEqual(s.orderedMutators[i], toAdd)
 	})
 
 	if i == len(s.orderedMutators) { // Adding to the bottom of the list
@@ -63,12 +71,6 @@ func (s *System) Upsert(m types.Mutator) error {
 		return nil
 	}
 
-	found := equal(s.orderedMutators[i].ID(), toAdd.ID())
-	if found {
-		s.orderedMutators[i] = toAdd
-		return nil
-	}
-
 	s.orderedMutators = append(s.orderedMutators, nil)
 	copy(s.orderedMutators[i+1:], s.orderedMutators[i:])
 	s.orderedMutators[i] = toAdd
@@ -111,27 +113,32 @@ func (s *System) Remove(id types.ID) error {
 	s.Lock()
 	defer s.Unlock()
 
-	if _, ok := s.mutatorsMap[id]; !ok {
+	current, ok := s.mutatorsMap[id]
+	if !ok {
 		return nil
 	}
 
 	s.schemaDB.Remove(id)
 
-	i := sort.Search(len(s.orderedMutators), func(i int) bool {
-		res := equal(s.orderedMutators[i].ID(), id)
-		if res {
-			return true
-		}
-		return greaterOrEqual(s.orderedMutators[i].ID(), id)
-	})
-
+	if err := s.removeFromList(current); err != nil {
+		return err
+	}
 	delete(s.mutatorsMap, id)
+	return nil
+}
 
-	found := equal(s.orderedMutators[i].ID(), id)
+// removeFromList drops the mutator from the ordered list. The search
+// uses the stored mutator rather than its ID alone, since the position
+// also depends on the mutator's order.
+func (s *System) removeFromList(m types.Mutator) error {
+	id := m.ID()
+	i := sort.Search(len(s.orderedMutators), func(i int) bool {
+		return greaterOrEqual(s.orderedMutators[i], m)
+	})
 
 	// The map is expected to be in sync with the list, so if we don't find it
 	// we return an error.
-	if !found {
+	if i == len(s.orderedMutators) || !equal(s.orderedMutators[i].ID(), id) {
 		return fmt.Errorf("Failed to find mutator with ID %v on sorted list", id)
 	}
 	copy(s.orderedMutators[i:], s.orderedMutators[i+1:])
@@ -145,7 +152,17 @@ func (s *System) Get(id types.ID) types.Mutator {
 	return s.mutatorsMap[id].DeepCopy()
 }
 
-func greaterOrEqual(id1, id2 types.ID) bool {
+// greaterOrEqual orders mutators by their explicit order first, with
+// identity as a stable tie-breaker.
+func greaterOrEqual(m1, m2 types.Mutator) bool {
+	o1, o2 := types.OrderOf(m1), types.OrderOf(m2)
+	if o1 != o2 {
+		return o1 > o2
+	}
+	return idGreaterOrEqual(m1.ID(), m2.ID())
+}
+
+func idGreaterOrEqual(id1, id2 types.ID) bool {
 	if id1.Group > id2.Group {
 		return true
 	}
@@ -18,6 +18,7 @@ import (
 // the cache.
 type MockMutator struct {
 	Mocked           types.ID
+	MockOrder        int    // explicit application order
 	RelevantField    string // relevant for comparison
 	NotRelevantField string // not relevant for comparison
 	Labels           map[string]string
@@ -59,6 +60,10 @@ func (m *MockMutator) ID() types.ID {
 	return m.Mocked
 }
 
+func (m *MockMutator) Order() int {
+	return m.MockOrder
+}
+
 func (m *MockMutator) Path() *parser.Path {
 	return nil
 }
@@ -78,6 +83,7 @@ func (m *MockMutator) HasDiff(mutator types.Mutator) bool {
 func (m *MockMutator) DeepCopy() types.Mutator {
 	res := &MockMutator{
 		Mocked:           m.Mocked,
+		MockOrder:        m.MockOrder,
 		RelevantField:    m.RelevantField,
 		NotRelevantField: m.NotRelevantField,
 		MutationCount:    m.MutationCount,
@@ -319,3 +325,84 @@ func TestMutation(t *testing.T) {
 		})
 	}
 }
+
+func TestExplicitOrder(t *testing.T) {
+	c := NewSystem()
+	// explicit orders reverse the identity-based sort
+	initial := []types.Mutator{
+		&MockMutator{Mocked: types.ID{Group: "aaa", Kind: "aaa", Namespace: "aaa", Name: "aaa"}, MockOrder: 10},
+		&MockMutator{Mocked: types.ID{Group: "bbb", Kind: "aaa", Namespace: "aaa", Name: "aaa"}, MockOrder: -10},
+		&MockMutator{Mocked: types.ID{Group: "ccc", Kind: "aaa", Namespace: "aaa", Name: "aaa"}},
+	}
+	for i, m := range initial {
+		if err := c.Upsert(m); err != nil {
+			t.Errorf("Failed inserting %dth object", i)
+		}
+	}
+	expected := []types.Mutator{
+		&MockMutator{Mocked: types.ID{Group: "bbb", Kind: "aaa", Namespace: "aaa", Name: "aaa"}, MockOrder: -10},
+		&MockMutator{Mocked: types.ID{Group: "ccc", Kind: "aaa", Namespace: "aaa", Name: "aaa"}},
+		&MockMutator{Mocked: types.ID{Group: "aaa", Kind: "aaa", Namespace: "aaa", Name: "aaa"}, MockOrder: 10},
+	}
+	if !cmp.Equal(c.orderedMutators, expected) {
+		t.Errorf("Cache content is not consistent: %s", cmp.Diff(c.orderedMutators, expected))
+	}
+
+	// changing a mutator's order on upsert moves it in the list
+	if err := c.Upsert(&MockMutator{Mocked: types.ID{Group: "bbb", Kind: "aaa", Namespace: "aaa", Name: "aaa"}, MockOrder: 20, RelevantField: "changed"}); err != nil {
+		t.Errorf("Failed upserting reordered mutator: %v", err)
+	}
+	expected = []types.Mutator{
+		&MockMutator{Mocked: types.ID{Group: "ccc", Kind: "aaa", Namespace: "aaa", Name: "aaa"}},
+		&MockMutator{Mocked: types.ID{Group: "aaa", Kind: "aaa", Namespace: "aaa", Name: "aaa"}, MockOrder: 10},
+		&MockMutator{Mocked: types.ID{Group: "bbb", Kind: "aaa", Namespace: "aaa", Name: "aaa"}, MockOrder: 20, RelevantField: "changed"},
+	}
+	if !cmp.Equal(c.orderedMutators, expected) {
+		t.Errorf("Cache content after reorder is not consistent: %s", cmp.Diff(c.orderedMutators, expected))
+	}
+
+	if err := c.Remove(types.ID{Group: "aaa", Kind: "aaa", Namespace: "aaa", Name: "aaa"}); err != nil {
+		t.Errorf("Failed removing ordered mutator: %v", err)
+	}
+	if len(c.orderedMutators) != 2 {
+		t.Errorf("Expected 2 mutators after remove, found %d", len(c.orderedMutators))
+	}
+}
+
+func TestExplicitOrderDeterminesWinner(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testpod",
+			Namespace: "foo",
+		},
+	}
+	converted, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pod)
+	if err != nil {
+		t.Fatal("Convert pod to unstructured failed")
+	}
+	toMutate := &unstructured.Unstructured{Object: converted}
+
+	// both mutators write the same label; the explicit orders make the
+	// identity-wise earlier mutator apply last, so its value wins
+	c := NewSystem()
+	mutators := []types.Mutator{
+		&MockMutator{Mocked: types.ID{Group: "aaa", Kind: "aaa", Namespace: "aaa", Name: "aaa"}, MockOrder: 1, Labels: map[string]string{"k": "first"}},
+		&MockMutator{Mocked: types.ID{Group: "bbb", Kind: "aaa", Namespace: "aaa", Name: "aaa"}, Labels: map[string]string{"k": "second"}},
+	}
+	for i, m := range mutators {
+		if err := c.Upsert(m); err != nil {
+			t.Errorf("Failed inserting %dth object", i)
+		}
+	}
+	if _, err := c.Mutate(toMutate, nil); err != nil {
+		t.Fatal("Mutate failed unexpectedly", err)
+	}
+
+	accessor, err := meta.Accessor(toMutate)
+	if err != nil {
+		t.Fatal("Failed to get unstruct accessor", err)
+	}
+	if got := accessor.GetLabels()["k"]; got != "first" {
+		t.Errorf("Expected the order-1 mutator to apply last and win, got label value %q", got)
+	}
+}
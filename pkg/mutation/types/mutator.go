@@ -36,6 +36,24 @@ type Mutator interface {
 	Path() *parser.Path
 }
 
+// MutatorWithOrder is implemented by mutators whose resources declare
+// an explicit application order.
+type MutatorWithOrder interface {
+	Mutator
+	// Order returns the mutator's explicit order. Lower orders apply
+	// first.
+	Order() int
+}
+
+// OrderOf returns the mutator's explicit order, or 0 for mutators that
+// do not declare one.
+func OrderOf(m Mutator) int {
+	if ordered, ok := m.(MutatorWithOrder); ok {
+		return ordered.Order()
+	}
+	return 0
+}
+
 // MakeID builds an ID object for the given object
 func MakeID(obj runtime.Object) (ID, error) {
 	meta, err := meta.Accessor(obj)
@@ -0,0 +1,231 @@
+// Package opashard fans constraint evaluation out over a pool of OPA
+// clients. Constraints are distributed across shards by name hash while
+// templates and synced data are replicated to every shard, so a single
+// admission review can evaluate independent constraints in parallel
+// instead of serially. With one shard the pool degenerates to the plain
+// client.
+package opashard
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+
+	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/core/templates"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/types"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var evaluationShards = flag.Int("constraint-evaluation-shards", 1, "(alpha) number of OPA clients to shard constraints over. Values above 1 evaluate a review's matching constraints in parallel, reducing tail latency when many constraints apply. Defaults to 1 (serial evaluation)")
+
+// Enabled returns true when parallel constraint evaluation was requested
+// via flags.
+func Enabled() bool {
+	return *evaluationShards > 1
+}
+
+// Count returns the configured number of evaluation shards.
+func Count() int {
+	return *evaluationShards
+}
+
+// Client is the subset of the constraint framework client the rest of
+// Gatekeeper uses. It is satisfied by both *opa.Client and a sharded
+// Set, so callers do not care whether evaluation is parallel.
+type Client interface {
+	AddData(ctx context.Context, data interface{}) (*types.Responses, error)
+	RemoveData(ctx context.Context, data interface{}) (*types.Responses, error)
+	CreateCRD(ctx context.Context, templ *templates.ConstraintTemplate) (*apiextensions.CustomResourceDefinition, error)
+	AddTemplate(ctx context.Context, templ *templates.ConstraintTemplate) (*types.Responses, error)
+	RemoveTemplate(ctx context.Context, templ *templates.ConstraintTemplate) (*types.Responses, error)
+	GetTemplate(ctx context.Context, templ *templates.ConstraintTemplate) (*templates.ConstraintTemplate, error)
+	AddConstraint(ctx context.Context, constraint *unstructured.Unstructured) (*types.Responses, error)
+	RemoveConstraint(ctx context.Context, constraint *unstructured.Unstructured) (*types.Responses, error)
+	GetConstraint(ctx context.Context, constraint *unstructured.Unstructured) (*unstructured.Unstructured, error)
+	ValidateConstraint(ctx context.Context, constraint *unstructured.Unstructured) error
+	Review(ctx context.Context, obj interface{}, opts ...opa.QueryOpt) (*types.Responses, error)
+	Audit(ctx context.Context, opts ...opa.QueryOpt) (*types.Responses, error)
+	Reset(ctx context.Context) error
+	Dump(ctx context.Context) (string, error)
+}
+
+var _ Client = &opa.Client{}
+var _ Client = &Set{}
+
+// Set shards constraints over multiple OPA clients and evaluates them
+// concurrently.
+type Set struct {
+	shards []*opa.Client
+}
+
+// NewSet builds a Set of count clients using the given constructor.
+func NewSet(count int, newClient func() (*opa.Client, error)) (*Set, error) {
+	if count < 1 {
+		return nil, fmt.Errorf("shard count must be at least 1, got %d", count)
+	}
+	shards := make([]*opa.Client, count)
+	for i := range shards {
+		c, err := newClient()
+		if err != nil {
+			return nil, err
+		}
+		shards[i] = c
+	}
+	return &Set{shards: shards}, nil
+}
+
+// shardFor picks the shard holding a constraint. Kind and name together
+// identify a constraint cluster-wide.
+func (s *Set) shardFor(constraint *unstructured.Unstructured) *opa.Client {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(constraint.GetKind()))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(constraint.GetName()))
+	return s.shards[int(h.Sum32()%uint32(len(s.shards)))]
+}
+
+// forAll runs fn against every shard and returns the first response with
+// any error encountered.
+func (s *Set) forAll(fn func(c *opa.Client) (*types.Responses, error)) (*types.Responses, error) {
+	var first *types.Responses
+	var errs []string
+	for _, c := range s.shards {
+		resp, err := fn(c)
+		if first == nil {
+			first = resp
+		}
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return first, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return first, nil
+}
+
+func (s *Set) AddData(ctx context.Context, data interface{}) (*types.Responses, error) {
+	return s.forAll(func(c *opa.Client) (*types.Responses, error) { return c.AddData(ctx, data) })
+}
+
+func (s *Set) RemoveData(ctx context.Context, data interface{}) (*types.Responses, error) {
+	return s.forAll(func(c *opa.Client) (*types.Responses, error) { return c.RemoveData(ctx, data) })
+}
+
+func (s *Set) CreateCRD(ctx context.Context, templ *templates.ConstraintTemplate) (*apiextensions.CustomResourceDefinition, error) {
+	return s.shards[0].CreateCRD(ctx, templ)
+}
+
+func (s *Set) AddTemplate(ctx context.Context, templ *templates.ConstraintTemplate) (*types.Responses, error) {
+	return s.forAll(func(c *opa.Client) (*types.Responses, error) { return c.AddTemplate(ctx, templ) })
+}
+
+func (s *Set) RemoveTemplate(ctx context.Context, templ *templates.ConstraintTemplate) (*types.Responses, error) {
+	return s.forAll(func(c *opa.Client) (*types.Responses, error) { return c.RemoveTemplate(ctx, templ) })
+}
+
+func (s *Set) GetTemplate(ctx context.Context, templ *templates.ConstraintTemplate) (*templates.ConstraintTemplate, error) {
+	return s.shards[0].GetTemplate(ctx, templ)
+}
+
+func (s *Set) AddConstraint(ctx context.Context, constraint *unstructured.Unstructured) (*types.Responses, error) {
+	return s.shardFor(constraint).AddConstraint(ctx, constraint)
+}
+
+func (s *Set) RemoveConstraint(ctx context.Context, constraint *unstructured.Unstructured) (*types.Responses, error) {
+	return s.shardFor(constraint).RemoveConstraint(ctx, constraint)
+}
+
+func (s *Set) GetConstraint(ctx context.Context, constraint *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return s.shardFor(constraint).GetConstraint(ctx, constraint)
+}
+
+func (s *Set) ValidateConstraint(ctx context.Context, constraint *unstructured.Unstructured) error {
+	return s.shardFor(constraint).ValidateConstraint(ctx, constraint)
+}
+
+// Review evaluates the object against every shard concurrently and
+// merges the responses.
+func (s *Set) Review(ctx context.Context, obj interface{}, opts ...opa.QueryOpt) (*types.Responses, error) {
+	return s.parallel(func(c *opa.Client) (*types.Responses, error) { return c.Review(ctx, obj, opts...) })
+}
+
+// Audit evaluates cached data against every shard concurrently and
+// merges the responses.
+func (s *Set) Audit(ctx context.Context, opts ...opa.QueryOpt) (*types.Responses, error) {
+	return s.parallel(func(c *opa.Client) (*types.Responses, error) { return c.Audit(ctx, opts...) })
+}
+
+func (s *Set) Reset(ctx context.Context) error {
+	for _, c := range s.shards {
+		if err := c.Reset(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Set) Dump(ctx context.Context) (string, error) {
+	var b strings.Builder
+	for i, c := range s.shards {
+		dump, err := c.Dump(ctx)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "Shard(%d):\n%s\n", i, dump)
+	}
+	return b.String(), nil
+}
+
+// parallel runs fn against every shard in its own goroutine and merges
+// the responses. The pool is bounded by the shard count.
+func (s *Set) parallel(fn func(c *opa.Client) (*types.Responses, error)) (*types.Responses, error) {
+	responses := make([]*types.Responses, len(s.shards))
+	errs := make([]error, len(s.shards))
+	var wg sync.WaitGroup
+	for i := range s.shards {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i], errs[i] = fn(s.shards[i])
+		}(i)
+	}
+	wg.Wait()
+
+	merged := types.NewResponses()
+	var errMsgs []string
+	for i := range s.shards {
+		if errs[i] != nil {
+			errMsgs = append(errMsgs, errs[i].Error())
+			continue
+		}
+		mergeResponses(merged, responses[i])
+	}
+	if len(errMsgs) > 0 {
+		return merged, fmt.Errorf("%s", strings.Join(errMsgs, "; "))
+	}
+	return merged, nil
+}
+
+func mergeResponses(into *types.Responses, from *types.Responses) {
+	if from == nil {
+		return
+	}
+	for target, resp := range from.ByTarget {
+		existing, ok := into.ByTarget[target]
+		if !ok {
+			copied := *resp
+			into.ByTarget[target] = &copied
+			continue
+		}
+		existing.Results = append(existing.Results, resp.Results...)
+	}
+	for target, handled := range from.Handled {
+		into.Handled[target] = into.Handled[target] || handled
+	}
+}
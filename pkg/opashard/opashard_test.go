@@ -0,0 +1,158 @@
+package opashard
+
+import (
+	"context"
+	"testing"
+
+	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/client/drivers/local"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/core/templates"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func makeOpaClient() (*opa.Client, error) {
+	t := &target.K8sValidationTarget{}
+	driver := local.New(local.Tracing(false))
+	backend, err := opa.NewBackend(opa.Driver(driver))
+	if err != nil {
+		return nil, err
+	}
+	return backend.NewClient(opa.Targets(t))
+}
+
+func makeSet(t *testing.T, count int) *Set {
+	s, err := NewSet(count, makeOpaClient)
+	if err != nil {
+		t.Fatalf("could not initialize shard set: %s", err)
+	}
+	return s
+}
+
+func denyAllTemplate() *templates.ConstraintTemplate {
+	templ := &templates.ConstraintTemplate{}
+	templ.SetName("denyall")
+	templ.Spec.CRD.Spec.Names.Kind = "DenyAll"
+	templ.Spec.Targets = []templates.Target{{
+		Target: (&target.K8sValidationTarget{}).GetName(),
+		Rego: `
+package denyall
+
+violation[{"msg": msg}] {
+  msg := "denied by constraint"
+}
+`,
+	}}
+	return templ
+}
+
+func makeConstraint(name string) *unstructured.Unstructured {
+	cstr := &unstructured.Unstructured{}
+	cstr.SetAPIVersion("constraints.gatekeeper.sh/v1beta1")
+	cstr.SetKind("DenyAll")
+	cstr.SetName(name)
+	return cstr
+}
+
+func makePod(name string) *unstructured.Unstructured {
+	pod := &unstructured.Unstructured{}
+	pod.SetAPIVersion("v1")
+	pod.SetKind("Pod")
+	pod.SetName(name)
+	return pod
+}
+
+func TestNewSetRejectsInvalidCount(t *testing.T) {
+	if _, err := NewSet(0, makeOpaClient); err == nil {
+		t.Errorf("expected error for zero shards")
+	}
+}
+
+func TestShardForDeterministic(t *testing.T) {
+	s := makeSet(t, 3)
+	if s.shardFor(makeConstraint("a")) != s.shardFor(makeConstraint("a")) {
+		t.Errorf("shard assignment must be deterministic")
+	}
+}
+
+func TestConstraintRouting(t *testing.T) {
+	s := makeSet(t, 2)
+	if _, err := s.AddTemplate(context.Background(), denyAllTemplate()); err != nil {
+		t.Fatalf("could not add template: %s", err)
+	}
+
+	names := []string{"a", "b", "c", "d", "e"}
+	for _, name := range names {
+		if _, err := s.AddConstraint(context.Background(), makeConstraint(name)); err != nil {
+			t.Fatalf("could not add constraint %q: %s", name, err)
+		}
+	}
+
+	// Every constraint must live on exactly the shard its hash selects.
+	for _, name := range names {
+		cstr := makeConstraint(name)
+		want := s.shardFor(cstr)
+		for _, shard := range s.shards {
+			_, err := shard.GetConstraint(context.Background(), cstr)
+			if shard == want && err != nil {
+				t.Errorf("constraint %q missing from its own shard: %s", name, err)
+			}
+			if shard != want && err == nil {
+				t.Errorf("constraint %q unexpectedly present on a foreign shard", name)
+			}
+		}
+	}
+}
+
+func TestReviewMergesShards(t *testing.T) {
+	s := makeSet(t, 3)
+	if _, err := s.AddTemplate(context.Background(), denyAllTemplate()); err != nil {
+		t.Fatalf("could not add template: %s", err)
+	}
+
+	names := []string{"a", "b", "c", "d", "e", "f"}
+	for _, name := range names {
+		if _, err := s.AddConstraint(context.Background(), makeConstraint(name)); err != nil {
+			t.Fatalf("could not add constraint %q: %s", name, err)
+		}
+	}
+
+	resp, err := s.Review(context.Background(), target.AugmentedUnstructured{Object: *makePod("busybox")})
+	if err != nil {
+		t.Fatalf("review failed: %s", err)
+	}
+	results := resp.Results()
+	if len(results) != len(names) {
+		t.Fatalf("expected %d violations, got %d", len(names), len(results))
+	}
+	seen := make(map[string]bool)
+	for _, r := range results {
+		seen[r.Constraint.GetName()] = true
+	}
+	for _, name := range names {
+		if !seen[name] {
+			t.Errorf("no violation reported for constraint %q", name)
+		}
+	}
+}
+
+func TestRemoveConstraint(t *testing.T) {
+	s := makeSet(t, 2)
+	if _, err := s.AddTemplate(context.Background(), denyAllTemplate()); err != nil {
+		t.Fatalf("could not add template: %s", err)
+	}
+	if _, err := s.AddConstraint(context.Background(), makeConstraint("a")); err != nil {
+		t.Fatalf("could not add constraint: %s", err)
+	}
+	if _, err := s.RemoveConstraint(context.Background(), makeConstraint("a")); err != nil {
+		t.Fatalf("could not remove constraint: %s", err)
+	}
+
+	resp, err := s.Review(context.Background(), target.AugmentedUnstructured{Object: *makePod("busybox")})
+	if err != nil {
+		t.Fatalf("review failed: %s", err)
+	}
+	if len(resp.Results()) != 0 {
+		t.Errorf("expected no violations after removal, got %d", len(resp.Results()))
+	}
+}
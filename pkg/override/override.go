@@ -0,0 +1,54 @@
+// Package override implements delegated per-team enforcement overrides.
+// A namespaced ConstraintOverride lets namespace owners downgrade
+// specific constraints from deny to warn for their namespace, but only
+// where the constraint's author delegated that decision via the
+// policies.gatekeeper.sh/allow-overrides annotation.
+package override
+
+import (
+	"flag"
+	"strings"
+
+	overridesv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/overrides/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// AllowOverridesAnnotation lists the namespaces whose ConstraintOverrides
+// the constraint honors, comma-separated. "*" honors overrides from
+// every namespace. Constraints without the annotation cannot be
+// overridden.
+const AllowOverridesAnnotation = "policies.gatekeeper.sh/allow-overrides"
+
+var enableConstraintOverrides = flag.Bool("enable-constraint-overrides", false, "(alpha) honor namespaced ConstraintOverride resources that downgrade allow-listed constraints from deny to warn")
+
+// Enabled returns true when constraint overrides were requested via flag.
+func Enabled() bool {
+	return *enableConstraintOverrides
+}
+
+// ConstraintAllowsOverride returns true if the constraint's author
+// delegated override decisions to the given namespace.
+func ConstraintAllowsOverride(constraint *unstructured.Unstructured, namespace string) bool {
+	allowed, ok := constraint.GetAnnotations()[AllowOverridesAnnotation]
+	if !ok {
+		return false
+	}
+	for _, ns := range strings.Split(allowed, ",") {
+		ns = strings.TrimSpace(ns)
+		if ns == "*" || ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// FindOverride returns the first override covering the given constraint,
+// or nil if none does.
+func FindOverride(items []overridesv1alpha1.ConstraintOverride, kind, name string) *overridesv1alpha1.ConstraintOverride {
+	for i := range items {
+		if items[i].Matches(kind, name) {
+			return &items[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package override
+
+import (
+	"testing"
+
+	overridesv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/overrides/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func makeConstraint(allowed string) *unstructured.Unstructured {
+	c := &unstructured.Unstructured{}
+	c.SetName("require-labels")
+	c.SetKind("K8sRequiredLabels")
+	if allowed != "" {
+		c.SetAnnotations(map[string]string{AllowOverridesAnnotation: allowed})
+	}
+	return c
+}
+
+func TestConstraintAllowsOverride(t *testing.T) {
+	tc := []struct {
+		name      string
+		allowed   string
+		namespace string
+		expected  bool
+	}{
+		{name: "no annotation", allowed: "", namespace: "team-a", expected: false},
+		{name: "wildcard", allowed: "*", namespace: "team-a", expected: true},
+		{name: "listed namespace", allowed: "team-a,team-b", namespace: "team-b", expected: true},
+		{name: "listed namespace with spaces", allowed: "team-a, team-b", namespace: "team-b", expected: true},
+		{name: "unlisted namespace", allowed: "team-a", namespace: "team-c", expected: false},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ConstraintAllowsOverride(makeConstraint(tt.allowed), tt.namespace)
+			if got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFindOverride(t *testing.T) {
+	items := []overridesv1alpha1.ConstraintOverride{
+		{
+			Spec: overridesv1alpha1.ConstraintOverrideSpec{
+				Constraints: []overridesv1alpha1.ConstraintReference{
+					{Kind: "K8sRequiredLabels", Name: "require-labels"},
+				},
+			},
+		},
+		{
+			Spec: overridesv1alpha1.ConstraintOverrideSpec{
+				Constraints: []overridesv1alpha1.ConstraintReference{
+					{Kind: "K8sAllowedRepos"},
+				},
+			},
+		},
+	}
+
+	if FindOverride(items, "K8sRequiredLabels", "require-labels") == nil {
+		t.Errorf("expected override matching kind and name")
+	}
+	if FindOverride(items, "K8sAllowedRepos", "any-name") == nil {
+		t.Errorf("override without a name should match every constraint of the kind")
+	}
+	if FindOverride(items, "K8sRequiredLabels", "other") != nil {
+		t.Errorf("expected no override for unmatched constraint name")
+	}
+}
@@ -0,0 +1,175 @@
+// Package policyreport computes the effective set of policies for a
+// namespace. It answers "what constraints and mutators apply to me" for
+// tenants, after evaluating namespace match rules and config-based
+// exemptions, without requiring them to reverse-engineer selectors.
+package policyreport
+
+import (
+	mutationsv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/mutations/v1alpha1"
+	"github.com/open-policy-agent/gatekeeper/pkg/controller/config/process"
+	"github.com/open-policy-agent/gatekeeper/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ConstraintEntry describes one constraint that applies to the namespace.
+type ConstraintEntry struct {
+	Kind              string `json:"kind"`
+	Name              string `json:"name"`
+	EnforcementAction string `json:"enforcementAction"`
+}
+
+// MutatorEntry describes one mutator that applies to the namespace.
+type MutatorEntry struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// Report is the effective policy view for a single namespace.
+type Report struct {
+	Namespace string `json:"namespace"`
+	// Excluded is true when the namespace is exempted from the webhook
+	// process via config, in which case no constraints are enforced at
+	// admission regardless of their match rules.
+	Excluded    bool              `json:"excluded"`
+	Constraints []ConstraintEntry `json:"constraints"`
+	Mutators    []MutatorEntry    `json:"mutators"`
+}
+
+// BuildReport evaluates the provided constraints and mutators against the
+// namespace and returns those that apply.
+func BuildReport(ns *corev1.Namespace, constraints []*unstructured.Unstructured, mutators []MutatorSource, excluder *process.Excluder) (*Report, error) {
+	report := &Report{Namespace: ns.Name}
+
+	if excluder != nil {
+		// the excluder distinguishes namespaces from namespaced objects by
+		// kind, which is not guaranteed to be populated on typed objects
+		withKind := ns.DeepCopy()
+		withKind.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"}
+		excluded, err := excluder.IsNamespaceExcluded(process.Webhook, withKind)
+		if err != nil {
+			return nil, err
+		}
+		report.Excluded = excluded
+	}
+
+	for _, constraint := range constraints {
+		applies, err := constraintMatchesNamespace(constraint, ns)
+		if err != nil {
+			return nil, err
+		}
+		if !applies {
+			continue
+		}
+		action, err := util.GetEnforcementAction(constraint.Object)
+		if err != nil {
+			return nil, err
+		}
+		report.Constraints = append(report.Constraints, ConstraintEntry{
+			Kind:              constraint.GetKind(),
+			Name:              constraint.GetName(),
+			EnforcementAction: string(action),
+		})
+	}
+
+	for _, m := range mutators {
+		applies, err := matchAppliesToNamespace(m.Match, ns)
+		if err != nil {
+			return nil, err
+		}
+		if applies {
+			report.Mutators = append(report.Mutators, MutatorEntry{Kind: m.Kind, Name: m.Name})
+		}
+	}
+
+	return report, nil
+}
+
+// MutatorSource is the subset of a mutator needed to compute namespace
+// applicability.
+type MutatorSource struct {
+	Kind  string
+	Name  string
+	Match mutationsv1alpha1.Match
+}
+
+// constraintMatchesNamespace evaluates the namespace-related portion of a
+// constraint's spec.match (namespaces, excludedNamespaces and
+// namespaceSelector) against the given namespace. Kind and label
+// selectors cannot be evaluated without a concrete object and are
+// intentionally ignored; a constraint that matches the namespace may
+// still not match every object in it.
+func constraintMatchesNamespace(constraint *unstructured.Unstructured, ns *corev1.Namespace) (bool, error) {
+	included, found, err := unstructured.NestedStringSlice(constraint.Object, "spec", "match", "namespaces")
+	if err != nil {
+		return false, err
+	}
+	if found && len(included) > 0 && !containsString(included, ns.Name) {
+		return false, nil
+	}
+
+	excluded, _, err := unstructured.NestedStringSlice(constraint.Object, "spec", "match", "excludedNamespaces")
+	if err != nil {
+		return false, err
+	}
+	if containsString(excluded, ns.Name) {
+		return false, nil
+	}
+
+	rawSelector, found, err := unstructured.NestedMap(constraint.Object, "spec", "match", "namespaceSelector")
+	if err != nil {
+		return false, err
+	}
+	if found {
+		selector, err := selectorFromUnstructured(rawSelector)
+		if err != nil {
+			return false, err
+		}
+		if !selector.Matches(labels.Set(ns.Labels)) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// matchAppliesToNamespace evaluates the namespace-related portion of a
+// mutator's match against the given namespace.
+func matchAppliesToNamespace(match mutationsv1alpha1.Match, ns *corev1.Namespace) (bool, error) {
+	if len(match.Namespaces) > 0 && !containsString(match.Namespaces, ns.Name) {
+		return false, nil
+	}
+	if containsString(match.ExcludedNamespaces, ns.Name) {
+		return false, nil
+	}
+	if match.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(match.NamespaceSelector)
+		if err != nil {
+			return false, err
+		}
+		if !selector.Matches(labels.Set(ns.Labels)) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func selectorFromUnstructured(raw map[string]interface{}) (labels.Selector, error) {
+	labelSelector := &metav1.LabelSelector{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, labelSelector); err != nil {
+		return nil, err
+	}
+	return metav1.LabelSelectorAsSelector(labelSelector)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
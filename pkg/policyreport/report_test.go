@@ -0,0 +1,137 @@
+package policyreport
+
+import (
+	"testing"
+
+	configv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/config/v1alpha1"
+	mutationsv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/mutations/v1alpha1"
+	"github.com/open-policy-agent/gatekeeper/pkg/controller/config/process"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func makeNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+	}
+}
+
+func makeConstraint(name string, match map[string]interface{}) *unstructured.Unstructured {
+	c := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "constraints.gatekeeper.sh/v1beta1",
+			"kind":       "K8sRequiredLabels",
+			"metadata":   map[string]interface{}{"name": name},
+			"spec":       map[string]interface{}{},
+		},
+	}
+	if match != nil {
+		c.Object["spec"].(map[string]interface{})["match"] = match
+	}
+	return c
+}
+
+func TestConstraintMatchesNamespace(t *testing.T) {
+	tc := []struct {
+		name     string
+		match    map[string]interface{}
+		ns       *corev1.Namespace
+		expected bool
+	}{
+		{
+			name:     "no match rules applies everywhere",
+			ns:       makeNamespace("default", nil),
+			expected: true,
+		},
+		{
+			name:     "namespace in included list",
+			match:    map[string]interface{}{"namespaces": []interface{}{"default", "kube-system"}},
+			ns:       makeNamespace("default", nil),
+			expected: true,
+		},
+		{
+			name:     "namespace not in included list",
+			match:    map[string]interface{}{"namespaces": []interface{}{"kube-system"}},
+			ns:       makeNamespace("default", nil),
+			expected: false,
+		},
+		{
+			name:     "namespace excluded",
+			match:    map[string]interface{}{"excludedNamespaces": []interface{}{"default"}},
+			ns:       makeNamespace("default", nil),
+			expected: false,
+		},
+		{
+			name: "namespace selector matches",
+			match: map[string]interface{}{
+				"namespaceSelector": map[string]interface{}{
+					"matchLabels": map[string]interface{}{"tier": "prod"},
+				},
+			},
+			ns:       makeNamespace("default", map[string]string{"tier": "prod"}),
+			expected: true,
+		},
+		{
+			name: "namespace selector does not match",
+			match: map[string]interface{}{
+				"namespaceSelector": map[string]interface{}{
+					"matchLabels": map[string]interface{}{"tier": "prod"},
+				},
+			},
+			ns:       makeNamespace("default", map[string]string{"tier": "dev"}),
+			expected: false,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := constraintMatchesNamespace(makeConstraint("c", tt.match), tt.ns)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	ns := makeNamespace("team-a", map[string]string{"tier": "prod"})
+	constraints := []*unstructured.Unstructured{
+		makeConstraint("applies-everywhere", nil),
+		makeConstraint("excludes-team-a", map[string]interface{}{"excludedNamespaces": []interface{}{"team-a"}}),
+	}
+	mutators := []MutatorSource{
+		{Kind: "Assign", Name: "set-image-policy", Match: mutationsv1alpha1.Match{Namespaces: []string{"team-a"}}},
+		{Kind: "Assign", Name: "other-ns-only", Match: mutationsv1alpha1.Match{Namespaces: []string{"team-b"}}},
+	}
+
+	report, err := BuildReport(ns, constraints, mutators, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Constraints) != 1 || report.Constraints[0].Name != "applies-everywhere" {
+		t.Errorf("unexpected constraints: %v", report.Constraints)
+	}
+	if len(report.Mutators) != 1 || report.Mutators[0].Name != "set-image-policy" {
+		t.Errorf("unexpected mutators: %v", report.Mutators)
+	}
+	if report.Excluded {
+		t.Errorf("namespace should not be excluded without an excluder")
+	}
+}
+
+func TestBuildReportExcludedNamespace(t *testing.T) {
+	excluder := process.New()
+	excluder.Add([]configv1alpha1.MatchEntry{
+		{ExcludedNamespaces: []string{"team-a"}, Processes: []string{"webhook"}},
+	})
+	report, err := BuildReport(makeNamespace("team-a", nil), nil, nil, excluder)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.Excluded {
+		t.Errorf("expected namespace to be reported as excluded")
+	}
+}
@@ -0,0 +1,175 @@
+package policyreport
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"time"
+
+	mutationsv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/mutations/v1alpha1"
+	"github.com/open-policy-agent/gatekeeper/pkg/controller/config/process"
+	"github.com/open-policy-agent/gatekeeper/pkg/httpguard"
+	"github.com/open-policy-agent/gatekeeper/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("policy-report").WithValues(logging.Process, "policy_report")
+
+const constraintsGV = "constraints.gatekeeper.sh/v1beta1"
+
+var (
+	enablePolicyReportAPI = flag.Bool("enable-policy-report-api", false, "(alpha) serve an HTTP API reporting the effective policies per namespace")
+	policyReportAddr      = flag.String("policy-report-addr", ":8889", "The address the effective policy report endpoint binds to.")
+)
+
+// Enabled returns true when the effective policy report API was requested
+// via flag.
+func Enabled() bool {
+	return *enablePolicyReportAPI
+}
+
+// Server serves effective policy reports over HTTP. It implements
+// manager.Runnable so it can be registered with the controller manager.
+type Server struct {
+	client client.Client
+	config *rest.Config
+	addr   string
+}
+
+// NewServer returns a Server configured from the policy report flags.
+func NewServer(c client.Client, cfg *rest.Config) *Server {
+	return &Server{client: c, config: cfg, addr: *policyReportAddr}
+}
+
+// Start implements manager.Runnable.
+func (s *Server) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.Handle("/v1/policies", httpguard.Wrap(http.HandlerFunc(s.handlePolicies)))
+	srv := &http.Server{Addr: s.addr, Handler: mux}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+// handlePolicies serves the effective policy report for the namespace
+// given by the `namespace` query parameter.
+func (s *Server) handlePolicies(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	nsName := req.URL.Query().Get("namespace")
+	if nsName == "" {
+		http.Error(w, "namespace query parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	ctx := req.Context()
+	ns := &corev1.Namespace{}
+	if err := s.client.Get(ctx, types.NamespacedName{Name: nsName}, ns); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	constraints, err := s.listConstraints(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	mutators, err := s.listMutators(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	report, err := BuildReport(ns, constraints, mutators, process.Get())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		log.Error(err, "unable to encode effective policy report")
+	}
+}
+
+// listConstraints enumerates the installed constraint kinds via discovery
+// and lists every constraint of each kind.
+func (s *Server) listConstraints(ctx context.Context) ([]*unstructured.Unstructured, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(s.config)
+	if err != nil {
+		return nil, err
+	}
+	rl, err := discoveryClient.ServerResourcesForGroupVersion(constraintsGV)
+	if err != nil {
+		// no constraint kinds have been installed yet
+		return nil, nil
+	}
+	gv, err := schema.ParseGroupVersion(constraintsGV)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*unstructured.Unstructured
+	for i := range rl.APIResources {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gv.WithKind(rl.APIResources[i].Kind + "List"))
+		if err := s.client.List(ctx, list); err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			out = append(out, &list.Items[i])
+		}
+	}
+	return out, nil
+}
+
+func (s *Server) listMutators(ctx context.Context) ([]MutatorSource, error) {
+	var out []MutatorSource
+
+	assigns := &mutationsv1alpha1.AssignList{}
+	if err := s.client.List(ctx, assigns); err != nil {
+		return nil, err
+	}
+	for i := range assigns.Items {
+		out = append(out, MutatorSource{
+			Kind:  assigns.Items[i].Kind,
+			Name:  assigns.Items[i].Name,
+			Match: assigns.Items[i].Spec.Match,
+		})
+	}
+
+	assignMetas := &mutationsv1alpha1.AssignMetadataList{}
+	if err := s.client.List(ctx, assignMetas); err != nil {
+		return nil, err
+	}
+	for i := range assignMetas.Items {
+		out = append(out, MutatorSource{
+			Kind:  assignMetas.Items[i].Kind,
+			Name:  assignMetas.Items[i].Name,
+			Match: assignMetas.Items[i].Spec.Match,
+		})
+	}
+
+	return out, nil
+}
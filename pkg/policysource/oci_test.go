@@ -0,0 +1,135 @@
+package policysource
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sourcesv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/sources/v1alpha1"
+)
+
+func TestParseImageRef(t *testing.T) {
+	registry, repository, digest, err := parseImageRef("registry.example.com/org/policies@sha256:abc123")
+	if err != nil {
+		t.Fatalf("parsing reference: %s", err)
+	}
+	if registry != "registry.example.com" || repository != "org/policies" || digest != "sha256:abc123" {
+		t.Errorf("parsed = %q, %q, %q", registry, repository, digest)
+	}
+	if _, _, _, err := parseImageRef("registry.example.com/org/policies:v1.2.3"); err == nil {
+		t.Errorf("tag references should be rejected")
+	}
+	if _, _, _, err := parseImageRef("policies@sha256:abc123"); err == nil {
+		t.Errorf("references without a registry host should be rejected")
+	}
+}
+
+func sha(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// makeLayer builds a gzipped tarball holding one file.
+func makeLayer(t *testing.T, name, content string) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatalf("writing tar header: %s", err)
+	}
+	if _, err := tw.Write([]byte(content)); err != nil {
+		t.Fatalf("writing tar content: %s", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("closing tar: %s", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("closing gzip: %s", err)
+	}
+	return buf.Bytes()
+}
+
+func TestFetchOCI(t *testing.T) {
+	layer := makeLayer(t, "templates/template.yaml", "apiVersion: templates.gatekeeper.sh/v1beta1\nkind: ConstraintTemplate\nmetadata:\n  name: k8srequiredlabels\n")
+	manifest := []byte(fmt.Sprintf(`{"layers": [{"mediaType": "application/vnd.oci.image.layer.v1.tar+gzip", "digest": %q}]}`, sha(layer)))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/v2/org/policies/manifests/"):
+			w.Write(manifest)
+		case r.URL.Path == "/v2/org/policies/blobs/"+sha(layer):
+			w.Write(layer)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	defer func(old string) { registryScheme = old }(registryScheme)
+	registryScheme = "http"
+
+	source := &sourcesv1alpha1.PolicySource{}
+	source.SetName("bundle")
+	source.Spec.Image = strings.TrimPrefix(srv.URL, "http://") + "/org/policies@" + sha(manifest)
+
+	dir := t.TempDir()
+	revision, err := (&Syncer{}).fetchOCI(context.Background(), source, dir)
+	if err != nil {
+		t.Fatalf("pulling bundle: %s", err)
+	}
+	if revision != sha(manifest) {
+		t.Errorf("revision = %q, want the manifest digest", revision)
+	}
+	content, err := ioutil.ReadFile(filepath.Join(dir, "templates", "template.yaml"))
+	if err != nil {
+		t.Fatalf("reading extracted manifest: %s", err)
+	}
+	if !strings.Contains(string(content), "k8srequiredlabels") {
+		t.Errorf("extracted content = %q", content)
+	}
+}
+
+func TestFetchOCIRejectsTamperedManifest(t *testing.T) {
+	manifest := []byte(`{"layers": []}`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(manifest)
+	}))
+	defer srv.Close()
+	defer func(old string) { registryScheme = old }(registryScheme)
+	registryScheme = "http"
+
+	source := &sourcesv1alpha1.PolicySource{}
+	source.SetName("bundle")
+	source.Spec.Image = strings.TrimPrefix(srv.URL, "http://") + "/org/policies@" + sha([]byte("something else"))
+
+	if _, err := (&Syncer{}).fetchOCI(context.Background(), source, t.TempDir()); err == nil {
+		t.Errorf("a manifest not matching the pinned digest should be rejected")
+	}
+}
+
+func TestExtractLayerRejectsEscapes(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	if err := tw.WriteHeader(&tar.Header{Name: "../escape.yaml", Mode: 0644, Size: 4, Typeflag: tar.TypeReg}); err != nil {
+		t.Fatalf("writing tar header: %s", err)
+	}
+	if _, err := tw.Write([]byte("oops")); err != nil {
+		t.Fatalf("writing tar content: %s", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("closing tar: %s", err)
+	}
+	if err := extractLayer(buf.Bytes(), t.TempDir()); err == nil {
+		t.Errorf("entries escaping the bundle directory should be rejected")
+	}
+}
@@ -0,0 +1,281 @@
+// Package policyswap applies a constraint template change and the
+// constraint updates that follow it to the policy engine as one atomic
+// unit. The change is staged off to the side while the controllers
+// replay the template's constraints; once the batch settles it is
+// swapped into the serving engine in a single critical section that
+// admission reviews cannot interleave with. Without this, a rollout
+// briefly serves a recompiled template with none (or only some) of its
+// constraints attached.
+package policyswap
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/core/templates"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/types"
+	"github.com/open-policy-agent/gatekeeper/pkg/opashard"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("policy-swap")
+
+const (
+	// settleDelay is how long a staged change must be quiet before it is
+	// swapped in; constraint re-adds for an updated template land well
+	// within it.
+	settleDelay = time.Second
+	// swapPeriod is how often the swapper looks for settled changes.
+	swapPeriod = 500 * time.Millisecond
+)
+
+var atomicSwaps = flag.Bool("atomic-policy-swaps", false, "(alpha) stage template changes and their constraints off to the side and swap them into the serving engine atomically, so admission never sees a partially updated policy set")
+
+// Enabled returns true when atomic policy swaps were requested via
+// flags.
+func Enabled() bool {
+	return *atomicSwaps
+}
+
+// stagedChange is one template's pending update, together with the
+// constraint changes that arrived while it was staged.
+type stagedChange struct {
+	template    *templates.ConstraintTemplate
+	constraints map[string]*unstructured.Unstructured
+	removed     map[string]*unstructured.Unstructured
+	lastChange  time.Time
+}
+
+// Client wraps an OPA client and batches each template change with its
+// constraints into an atomic swap. It implements opashard.Client.
+type Client struct {
+	opashard.Client
+
+	// gate serializes swaps against the serving path: reviews hold the
+	// read side, a swap holds the write side.
+	gate sync.RWMutex
+
+	mux    sync.Mutex
+	staged map[string]*stagedChange // by constraint kind
+}
+
+var _ opashard.Client = &Client{}
+
+// New wraps inner with atomic policy swaps.
+func New(inner opashard.Client) *Client {
+	return &Client{
+		Client: inner,
+		staged: make(map[string]*stagedChange),
+	}
+}
+
+// AddTemplate stages the template change instead of applying it
+// immediately; the swapper applies it together with the constraint
+// updates that follow.
+func (c *Client) AddTemplate(ctx context.Context, templ *templates.ConstraintTemplate) (*types.Responses, error) {
+	kind := templ.Spec.CRD.Spec.Names.Kind
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	s, ok := c.staged[kind]
+	if !ok {
+		s = &stagedChange{
+			constraints: make(map[string]*unstructured.Unstructured),
+			removed:     make(map[string]*unstructured.Unstructured),
+		}
+		c.staged[kind] = s
+	}
+	s.template = templ.DeepCopy()
+	s.lastChange = time.Now()
+	return types.NewResponses(), nil
+}
+
+// RemoveTemplate discards any staged change for the template's kind and
+// removes it from the serving engine.
+func (c *Client) RemoveTemplate(ctx context.Context, templ *templates.ConstraintTemplate) (*types.Responses, error) {
+	kind := templ.Spec.CRD.Spec.Names.Kind
+	c.mux.Lock()
+	delete(c.staged, kind)
+	c.mux.Unlock()
+	c.gate.Lock()
+	defer c.gate.Unlock()
+	return c.Client.RemoveTemplate(ctx, templ)
+}
+
+// GetTemplate serves the staged version when one exists so callers see
+// their own writes.
+func (c *Client) GetTemplate(ctx context.Context, templ *templates.ConstraintTemplate) (*templates.ConstraintTemplate, error) {
+	kind := templ.Spec.CRD.Spec.Names.Kind
+	c.mux.Lock()
+	if s, ok := c.staged[kind]; ok && s.template != nil {
+		cpy := s.template.DeepCopy()
+		c.mux.Unlock()
+		return cpy, nil
+	}
+	c.mux.Unlock()
+	return c.Client.GetTemplate(ctx, templ)
+}
+
+// AddConstraint joins a staged change for its kind when one exists, and
+// is applied directly otherwise.
+func (c *Client) AddConstraint(ctx context.Context, constraint *unstructured.Unstructured) (*types.Responses, error) {
+	c.mux.Lock()
+	if s, ok := c.staged[constraint.GetKind()]; ok {
+		s.constraints[constraint.GetName()] = constraint.DeepCopy()
+		delete(s.removed, constraint.GetName())
+		s.lastChange = time.Now()
+		c.mux.Unlock()
+		return types.NewResponses(), nil
+	}
+	c.mux.Unlock()
+	return c.Client.AddConstraint(ctx, constraint)
+}
+
+// RemoveConstraint joins a staged change for its kind when one exists.
+func (c *Client) RemoveConstraint(ctx context.Context, constraint *unstructured.Unstructured) (*types.Responses, error) {
+	c.mux.Lock()
+	if s, ok := c.staged[constraint.GetKind()]; ok {
+		delete(s.constraints, constraint.GetName())
+		s.removed[constraint.GetName()] = constraint.DeepCopy()
+		s.lastChange = time.Now()
+		c.mux.Unlock()
+		return types.NewResponses(), nil
+	}
+	c.mux.Unlock()
+	return c.Client.RemoveConstraint(ctx, constraint)
+}
+
+// GetConstraint serves staged constraints so callers see their own
+// writes.
+func (c *Client) GetConstraint(ctx context.Context, constraint *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	c.mux.Lock()
+	if s, ok := c.staged[constraint.GetKind()]; ok {
+		if cstr, ok := s.constraints[constraint.GetName()]; ok {
+			cpy := cstr.DeepCopy()
+			c.mux.Unlock()
+			return cpy, nil
+		}
+	}
+	c.mux.Unlock()
+	return c.Client.GetConstraint(ctx, constraint)
+}
+
+// ValidateConstraint swaps in the constraint's kind first so validation
+// runs against the staged template.
+func (c *Client) ValidateConstraint(ctx context.Context, constraint *unstructured.Unstructured) error {
+	if err := c.swapKinds(ctx, func(kind string, _ *stagedChange) bool {
+		return kind == constraint.GetKind()
+	}); err != nil {
+		return err
+	}
+	return c.Client.ValidateConstraint(ctx, constraint)
+}
+
+// Review holds the read side of the gate so it cannot observe a swap in
+// progress.
+func (c *Client) Review(ctx context.Context, obj interface{}, opts ...opa.QueryOpt) (*types.Responses, error) {
+	c.gate.RLock()
+	defer c.gate.RUnlock()
+	return c.Client.Review(ctx, obj, opts...)
+}
+
+// Audit swaps in everything staged so results reflect the latest policy
+// set, then evaluates behind the gate.
+func (c *Client) Audit(ctx context.Context, opts ...opa.QueryOpt) (*types.Responses, error) {
+	if err := c.SwapAll(ctx); err != nil {
+		return nil, err
+	}
+	c.gate.RLock()
+	defer c.gate.RUnlock()
+	return c.Client.Audit(ctx, opts...)
+}
+
+// SwapAll swaps every staged change into the serving engine.
+func (c *Client) SwapAll(ctx context.Context) error {
+	return c.swapKinds(ctx, func(string, *stagedChange) bool { return true })
+}
+
+// swapSettled swaps changes that have been quiet for settleDelay.
+func (c *Client) swapSettled(ctx context.Context) error {
+	cutoff := time.Now().Add(-settleDelay)
+	return c.swapKinds(ctx, func(_ string, s *stagedChange) bool {
+		return s.lastChange.Before(cutoff)
+	})
+}
+
+// swapKinds applies the selected staged changes in one critical section
+// that excludes reviews.
+func (c *Client) swapKinds(ctx context.Context, match func(string, *stagedChange) bool) error {
+	c.mux.Lock()
+	var selected []*stagedChange
+	for kind, s := range c.staged {
+		if !match(kind, s) {
+			continue
+		}
+		selected = append(selected, s)
+		delete(c.staged, kind)
+	}
+	c.mux.Unlock()
+	if len(selected) == 0 {
+		return nil
+	}
+
+	c.gate.Lock()
+	defer c.gate.Unlock()
+	var errs []string
+	for _, s := range selected {
+		if s.template != nil {
+			if _, err := c.Client.AddTemplate(ctx, s.template); err != nil {
+				errs = append(errs, fmt.Sprintf("swapping template %s: %v", s.template.GetName(), err))
+				continue
+			}
+			log.Info("atomically swapped template", "template", s.template.GetName(), "constraints", len(s.constraints))
+		}
+		for _, cstr := range s.constraints {
+			if _, err := c.Client.AddConstraint(ctx, cstr); err != nil {
+				errs = append(errs, fmt.Sprintf("adding constraint %s: %v", cstr.GetName(), err))
+			}
+		}
+		for _, cstr := range s.removed {
+			if _, err := c.Client.RemoveConstraint(ctx, cstr); err != nil {
+				errs = append(errs, fmt.Sprintf("removing constraint %s: %v", cstr.GetName(), err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Swapper periodically swaps settled changes into the serving engine.
+// It implements manager.Runnable.
+type Swapper struct {
+	client *Client
+}
+
+// NewSwapper returns a Swapper for the given client.
+func NewSwapper(c *Client) *Swapper {
+	return &Swapper{client: c}
+}
+
+// Start implements manager.Runnable.
+func (s *Swapper) Start(ctx context.Context) error {
+	ticker := time.NewTicker(swapPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.client.swapSettled(ctx); err != nil {
+				log.Error(err, "atomic policy swap failed")
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package policyswap
+
+import (
+	"context"
+	"testing"
+
+	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/client/drivers/local"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/core/templates"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func makeOpaClient() (*opa.Client, error) {
+	t := &target.K8sValidationTarget{}
+	driver := local.New(local.Tracing(false))
+	backend, err := opa.NewBackend(opa.Driver(driver))
+	if err != nil {
+		return nil, err
+	}
+	return backend.NewClient(opa.Targets(t))
+}
+
+func makeSwapClient(t *testing.T) (*Client, *opa.Client) {
+	inner, err := makeOpaClient()
+	if err != nil {
+		t.Fatalf("could not initialize OPA: %s", err)
+	}
+	return New(inner), inner
+}
+
+func denyAllTemplate() *templates.ConstraintTemplate {
+	templ := &templates.ConstraintTemplate{}
+	templ.SetName("denyall")
+	templ.Spec.CRD.Spec.Names.Kind = "DenyAll"
+	templ.Spec.Targets = []templates.Target{{
+		Target: (&target.K8sValidationTarget{}).GetName(),
+		Rego: `
+package denyall
+
+violation[{"msg": msg}] {
+  msg := "denied by constraint"
+}
+`,
+	}}
+	return templ
+}
+
+func makeConstraint(name string) *unstructured.Unstructured {
+	cstr := &unstructured.Unstructured{}
+	cstr.SetAPIVersion("constraints.gatekeeper.sh/v1beta1")
+	cstr.SetKind("DenyAll")
+	cstr.SetName(name)
+	return cstr
+}
+
+func makePod(name string) *unstructured.Unstructured {
+	pod := &unstructured.Unstructured{}
+	pod.SetAPIVersion("v1")
+	pod.SetKind("Pod")
+	pod.SetName(name)
+	return pod
+}
+
+func TestTemplateStagedUntilSwap(t *testing.T) {
+	c, inner := makeSwapClient(t)
+	if _, err := c.AddTemplate(context.Background(), denyAllTemplate()); err != nil {
+		t.Fatalf("could not stage template: %s", err)
+	}
+	if _, err := inner.GetTemplate(context.Background(), denyAllTemplate()); err == nil {
+		t.Errorf("staged template should not be in the serving engine yet")
+	}
+	if _, err := c.GetTemplate(context.Background(), denyAllTemplate()); err != nil {
+		t.Errorf("staged template should be readable through the client: %s", err)
+	}
+}
+
+func TestSwapAppliesTemplateWithConstraints(t *testing.T) {
+	c, inner := makeSwapClient(t)
+	if _, err := c.AddTemplate(context.Background(), denyAllTemplate()); err != nil {
+		t.Fatalf("could not stage template: %s", err)
+	}
+	if _, err := c.AddConstraint(context.Background(), makeConstraint("deny-everything")); err != nil {
+		t.Fatalf("could not stage constraint: %s", err)
+	}
+
+	if err := c.SwapAll(context.Background()); err != nil {
+		t.Fatalf("swap failed: %s", err)
+	}
+	if len(c.staged) != 0 {
+		t.Errorf("swap should drain the staged backlog")
+	}
+	if _, err := inner.GetTemplate(context.Background(), denyAllTemplate()); err != nil {
+		t.Errorf("template should be in the serving engine after swap: %s", err)
+	}
+
+	resp, err := c.Review(context.Background(), target.AugmentedUnstructured{Object: *makePod("busybox")})
+	if err != nil {
+		t.Fatalf("review failed: %s", err)
+	}
+	if len(resp.Results()) != 1 {
+		t.Errorf("expected 1 violation after swap, got %d", len(resp.Results()))
+	}
+}
+
+func TestRemoveConstraintWhileStaged(t *testing.T) {
+	c, _ := makeSwapClient(t)
+	if _, err := c.AddTemplate(context.Background(), denyAllTemplate()); err != nil {
+		t.Fatalf("could not stage template: %s", err)
+	}
+	if _, err := c.AddConstraint(context.Background(), makeConstraint("short-lived")); err != nil {
+		t.Fatalf("could not stage constraint: %s", err)
+	}
+	if _, err := c.RemoveConstraint(context.Background(), makeConstraint("short-lived")); err != nil {
+		t.Fatalf("could not remove staged constraint: %s", err)
+	}
+
+	if err := c.SwapAll(context.Background()); err != nil {
+		t.Fatalf("swap failed: %s", err)
+	}
+	resp, err := c.Review(context.Background(), target.AugmentedUnstructured{Object: *makePod("busybox")})
+	if err != nil {
+		t.Fatalf("review failed: %s", err)
+	}
+	if len(resp.Results()) != 0 {
+		t.Errorf("constraint removed while staged should not produce violations, got %d", len(resp.Results()))
+	}
+}
+
+func TestRemoveTemplateDiscardsStagedChange(t *testing.T) {
+	c, _ := makeSwapClient(t)
+	if _, err := c.AddTemplate(context.Background(), denyAllTemplate()); err != nil {
+		t.Fatalf("could not stage template: %s", err)
+	}
+	if _, err := c.RemoveTemplate(context.Background(), denyAllTemplate()); err != nil {
+		t.Fatalf("could not remove staged template: %s", err)
+	}
+	if len(c.staged) != 0 {
+		t.Errorf("removed template should not remain staged")
+	}
+}
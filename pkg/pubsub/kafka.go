@@ -0,0 +1,450 @@
+package pubsub
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+func init() {
+	Register("kafka", newKafkaDriver)
+}
+
+// kafkaDriver is a minimal produce-only Kafka client. It speaks just
+// enough of the wire protocol — Metadata v1 to find the partition
+// leader and Produce v3 with a v2 record batch — to publish messages
+// without pulling a Kafka client library into the tree. Messages go to
+// partition 0 with acks=1; ordering across partitions is not a concern
+// for an export stream consumed as an event log.
+type kafkaDriver struct {
+	brokers []string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	leader string
+}
+
+const kafkaClientID = "gatekeeper"
+
+func newKafkaDriver(config map[string]string) (Driver, error) {
+	raw := config["addresses"]
+	if raw == "" {
+		return nil, fmt.Errorf("kafka driver requires an addresses setting (comma-separated host:port)")
+	}
+	var brokers []string
+	for _, b := range strings.Split(raw, ",") {
+		if b = strings.TrimSpace(b); b != "" {
+			brokers = append(brokers, b)
+		}
+	}
+	if len(brokers) == 0 {
+		return nil, fmt.Errorf("kafka driver requires at least one broker address")
+	}
+	return &kafkaDriver{brokers: brokers}, nil
+}
+
+func (d *kafkaDriver) Publish(ctx context.Context, topic string, payload []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	err := d.produce(ctx, topic, payload)
+	if err != nil && d.conn != nil {
+		// drop the connection and retry once — the leader may have
+		// moved since it was last resolved
+		d.conn.Close()
+		d.conn = nil
+		d.leader = ""
+		err = d.produce(ctx, topic, payload)
+	}
+	return err
+}
+
+// produce resolves the partition leader if needed and sends one
+// message. The caller must hold d.mu.
+func (d *kafkaDriver) produce(ctx context.Context, topic string, payload []byte) error {
+	if d.conn == nil {
+		leader, err := d.findLeader(ctx, topic)
+		if err != nil {
+			return err
+		}
+		conn, err := kafkaDial(ctx, leader)
+		if err != nil {
+			return err
+		}
+		d.conn = conn
+		d.leader = leader
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = d.conn.SetDeadline(deadline)
+	} else {
+		_ = d.conn.SetDeadline(time.Now().Add(10 * time.Second))
+	}
+	req := encodeProduceRequest(topic, payload)
+	if err := kafkaSend(d.conn, req); err != nil {
+		return fmt.Errorf("sending produce request to %s: %w", d.leader, err)
+	}
+	resp, err := kafkaReceive(d.conn)
+	if err != nil {
+		return fmt.Errorf("reading produce response from %s: %w", d.leader, err)
+	}
+	if code := decodeProduceError(resp); code != 0 {
+		return fmt.Errorf("kafka broker %s rejected produce for topic %s: error code %d", d.leader, topic, code)
+	}
+	return nil
+}
+
+// findLeader asks any reachable broker where partition 0 of the topic
+// lives.
+func (d *kafkaDriver) findLeader(ctx context.Context, topic string) (string, error) {
+	var lastErr error
+	for _, broker := range d.brokers {
+		conn, err := kafkaDial(ctx, broker)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		leader, err := queryLeader(conn, topic)
+		conn.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return leader, nil
+	}
+	return "", fmt.Errorf("no kafka broker answered metadata for topic %s: %w", topic, lastErr)
+}
+
+func kafkaDial(ctx context.Context, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("dialing kafka broker %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else {
+		_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+	}
+	return conn, nil
+}
+
+func queryLeader(conn net.Conn, topic string) (string, error) {
+	if err := kafkaSend(conn, encodeMetadataRequest(topic)); err != nil {
+		return "", err
+	}
+	resp, err := kafkaReceive(conn)
+	if err != nil {
+		return "", err
+	}
+	return decodeLeader(resp, topic)
+}
+
+func (d *kafkaDriver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.conn == nil {
+		return nil
+	}
+	err := d.conn.Close()
+	d.conn = nil
+	d.leader = ""
+	return err
+}
+
+// kafkaSend frames and writes one request.
+func kafkaSend(conn net.Conn, payload []byte) error {
+	framed := make([]byte, 4+len(payload))
+	binary.BigEndian.PutUint32(framed, uint32(len(payload)))
+	copy(framed[4:], payload)
+	_, err := conn.Write(framed)
+	return err
+}
+
+// kafkaReceive reads one size-framed response.
+func kafkaReceive(conn net.Conn) ([]byte, error) {
+	var sizeBuf [4]byte
+	if _, err := io.ReadFull(conn, sizeBuf[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(sizeBuf[:])
+	if size > 1<<20 {
+		return nil, fmt.Errorf("kafka response of %d bytes exceeds sanity limit", size)
+	}
+	resp := make([]byte, size)
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// kafkaWriter builds big-endian request payloads.
+type kafkaWriter struct {
+	bytes.Buffer
+}
+
+func (w *kafkaWriter) int8(v int8) { w.WriteByte(byte(v)) }
+func (w *kafkaWriter) int16(v int16) {
+	var b [2]byte
+	binary.BigEndian.PutUint16(b[:], uint16(v))
+	w.Write(b[:])
+}
+func (w *kafkaWriter) int32(v int32) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(v))
+	w.Write(b[:])
+}
+func (w *kafkaWriter) int64(v int64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(v))
+	w.Write(b[:])
+}
+
+func (w *kafkaWriter) string(s string) {
+	w.int16(int16(len(s)))
+	w.WriteString(s)
+}
+
+func (w *kafkaWriter) nullString() { w.int16(-1) }
+
+func (w *kafkaWriter) bytes(b []byte) {
+	w.int32(int32(len(b)))
+	w.Write(b)
+}
+
+// varint writes a zigzag-encoded signed varint, as used inside record
+// batches.
+func (w *kafkaWriter) varint(v int64) {
+	u := uint64((v << 1) ^ (v >> 63))
+	for u >= 0x80 {
+		w.WriteByte(byte(u) | 0x80)
+		u >>= 7
+	}
+	w.WriteByte(byte(u))
+}
+
+// header writes the common request header.
+func (w *kafkaWriter) header(apiKey, apiVersion int16, correlationID int32) {
+	w.int16(apiKey)
+	w.int16(apiVersion)
+	w.int32(correlationID)
+	w.string(kafkaClientID)
+}
+
+const (
+	apiKeyProduce  = 0
+	apiKeyMetadata = 3
+)
+
+// encodeMetadataRequest builds a Metadata v1 request for one topic.
+func encodeMetadataRequest(topic string) []byte {
+	w := &kafkaWriter{}
+	w.header(apiKeyMetadata, 1, 1)
+	w.int32(1) // one topic
+	w.string(topic)
+	return w.Bytes()
+}
+
+// encodeProduceRequest builds a Produce v3 request carrying one record
+// for partition 0 with acks=1.
+func encodeProduceRequest(topic string, payload []byte) []byte {
+	batch := encodeRecordBatch(payload)
+	w := &kafkaWriter{}
+	w.header(apiKeyProduce, 3, 2)
+	w.nullString() // transactional id
+	w.int16(1)     // acks: leader only
+	w.int32(10000) // timeout ms
+	w.int32(1)     // one topic
+	w.string(topic)
+	w.int32(1) // one partition
+	w.int32(0) // partition index
+	w.bytes(batch)
+	return w.Bytes()
+}
+
+// encodeRecordBatch builds a v2 record batch holding one record.
+func encodeRecordBatch(payload []byte) []byte {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+
+	// the single record, varint-framed
+	rec := &kafkaWriter{}
+	rec.int8(0)    // attributes
+	rec.varint(0)  // timestamp delta
+	rec.varint(0)  // offset delta
+	rec.varint(-1) // no key
+	rec.varint(int64(len(payload)))
+	rec.Write(payload)
+	rec.varint(0) // no headers
+
+	// the batch body the CRC covers: attributes onward
+	body := &kafkaWriter{}
+	body.int16(0)   // attributes: no compression
+	body.int32(0)   // last offset delta
+	body.int64(now) // first timestamp
+	body.int64(now) // max timestamp
+	body.int64(-1)  // producer id
+	body.int16(-1)  // producer epoch
+	body.int32(-1)  // base sequence
+	body.int32(1)   // one record
+	body.varint(int64(rec.Len()))
+	body.Write(rec.Bytes())
+
+	crc := crc32.Checksum(body.Bytes(), crc32.MakeTable(crc32.Castagnoli))
+
+	batch := &kafkaWriter{}
+	batch.int64(0) // base offset
+	// batch length counts everything after this field
+	batch.int32(int32(4 + 1 + 4 + body.Len())) // leader epoch + magic + crc + body
+	batch.int32(-1)                            // partition leader epoch
+	batch.int8(2)                              // magic: v2
+	batch.int32(int32(crc))
+	batch.Write(body.Bytes())
+	return batch.Bytes()
+}
+
+// kafkaReader decodes big-endian response payloads, latching the first
+// error.
+type kafkaReader struct {
+	buf []byte
+	off int
+	err error
+}
+
+func (r *kafkaReader) remaining(n int) bool {
+	if r.err != nil {
+		return false
+	}
+	if r.off+n > len(r.buf) {
+		r.err = fmt.Errorf("kafka response truncated at offset %d", r.off)
+		return false
+	}
+	return true
+}
+
+func (r *kafkaReader) int8() int8 {
+	if !r.remaining(1) {
+		return 0
+	}
+	v := int8(r.buf[r.off])
+	r.off++
+	return v
+}
+
+func (r *kafkaReader) int16() int16 {
+	if !r.remaining(2) {
+		return 0
+	}
+	v := int16(binary.BigEndian.Uint16(r.buf[r.off:]))
+	r.off += 2
+	return v
+}
+
+func (r *kafkaReader) int32() int32 {
+	if !r.remaining(4) {
+		return 0
+	}
+	v := int32(binary.BigEndian.Uint32(r.buf[r.off:]))
+	r.off += 4
+	return v
+}
+
+func (r *kafkaReader) int64() int64 {
+	if !r.remaining(8) {
+		return 0
+	}
+	v := int64(binary.BigEndian.Uint64(r.buf[r.off:]))
+	r.off += 8
+	return v
+}
+
+func (r *kafkaReader) string() string {
+	n := r.int16()
+	if n < 0 {
+		return ""
+	}
+	if !r.remaining(int(n)) {
+		return ""
+	}
+	s := string(r.buf[r.off : r.off+int(n)])
+	r.off += int(n)
+	return s
+}
+
+func (r *kafkaReader) int32Array() {
+	n := r.int32()
+	for i := int32(0); i < n && r.err == nil; i++ {
+		r.int32()
+	}
+}
+
+// decodeLeader parses a Metadata v1 response and returns the address
+// of the leader for partition 0 of the topic.
+func decodeLeader(resp []byte, topic string) (string, error) {
+	r := &kafkaReader{buf: resp}
+	r.int32() // correlation id
+
+	brokers := map[int32]string{}
+	brokerCount := r.int32()
+	for i := int32(0); i < brokerCount && r.err == nil; i++ {
+		id := r.int32()
+		host := r.string()
+		port := r.int32()
+		r.string() // rack
+		brokers[id] = fmt.Sprintf("%s:%d", host, port)
+	}
+	r.int32() // controller id
+
+	topicCount := r.int32()
+	for i := int32(0); i < topicCount && r.err == nil; i++ {
+		topicErr := r.int16()
+		name := r.string()
+		r.int8() // is internal
+		partitionCount := r.int32()
+		var leaderID int32 = -1
+		for j := int32(0); j < partitionCount && r.err == nil; j++ {
+			r.int16() // partition error
+			partition := r.int32()
+			leader := r.int32()
+			r.int32Array() // replicas
+			r.int32Array() // isr
+			if partition == 0 {
+				leaderID = leader
+			}
+		}
+		if name != topic {
+			continue
+		}
+		if topicErr != 0 {
+			return "", fmt.Errorf("kafka metadata for topic %s returned error code %d", topic, topicErr)
+		}
+		if addr, ok := brokers[leaderID]; ok {
+			return addr, nil
+		}
+		return "", fmt.Errorf("kafka metadata for topic %s names unknown leader %d", topic, leaderID)
+	}
+	if r.err != nil {
+		return "", r.err
+	}
+	return "", fmt.Errorf("kafka metadata response does not mention topic %s", topic)
+}
+
+// decodeProduceError parses a Produce v3 response and returns the
+// error code for the first partition.
+func decodeProduceError(resp []byte) int16 {
+	r := &kafkaReader{buf: resp}
+	r.int32()  // correlation id
+	r.int32()  // topic count
+	r.string() // topic name
+	r.int32()  // partition count
+	r.int32()  // partition index
+	code := r.int16()
+	if r.err != nil {
+		return -1
+	}
+	return code
+}
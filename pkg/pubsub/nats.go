@@ -0,0 +1,108 @@
+package pubsub
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+func init() {
+	Register("nats", newNATSDriver)
+}
+
+// natsDriver speaks the NATS client protocol directly — the protocol
+// is a handful of text commands, which keeps the driver free of a
+// client library dependency. Only publishing is implemented.
+type natsDriver struct {
+	addr string
+
+	mu   sync.Mutex
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+func newNATSDriver(config map[string]string) (Driver, error) {
+	addr := config["addresses"]
+	if addr == "" {
+		return nil, fmt.Errorf("nats driver requires an addresses setting (host:port)")
+	}
+	if strings.Contains(addr, ",") {
+		// a single server is enough for publishing; clustering is the
+		// server's concern
+		addr = strings.SplitN(addr, ",", 2)[0]
+	}
+	return &natsDriver{addr: addr}, nil
+}
+
+// connect dials the server and completes the INFO/CONNECT handshake.
+// The caller must hold d.mu.
+func (d *natsDriver) connect(ctx context.Context) error {
+	if d.conn != nil {
+		return nil
+	}
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", d.addr)
+	if err != nil {
+		return fmt.Errorf("dialing nats server %s: %w", d.addr, err)
+	}
+	rd := bufio.NewReader(conn)
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else {
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	}
+	// the server greets with an INFO line
+	info, err := rd.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("reading nats INFO: %w", err)
+	}
+	if !strings.HasPrefix(info, "INFO ") {
+		conn.Close()
+		return fmt.Errorf("unexpected nats greeting %q", strings.TrimSpace(info))
+	}
+	if _, err := fmt.Fprintf(conn, "CONNECT {\"verbose\":false,\"pedantic\":false,\"name\":\"gatekeeper\"}\r\n"); err != nil {
+		conn.Close()
+		return fmt.Errorf("sending nats CONNECT: %w", err)
+	}
+	_ = conn.SetDeadline(time.Time{})
+	d.conn = conn
+	d.rd = rd
+	return nil
+}
+
+func (d *natsDriver) Publish(ctx context.Context, topic string, payload []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.connect(ctx); err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = d.conn.SetWriteDeadline(deadline)
+	}
+	_, err := fmt.Fprintf(d.conn, "PUB %s %d\r\n%s\r\n", topic, len(payload), payload)
+	if err != nil {
+		// drop the connection; the next publish redials
+		d.conn.Close()
+		d.conn = nil
+		d.rd = nil
+		return fmt.Errorf("publishing to nats subject %s: %w", topic, err)
+	}
+	return nil
+}
+
+func (d *natsDriver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.conn == nil {
+		return nil
+	}
+	err := d.conn.Close()
+	d.conn = nil
+	d.rd = nil
+	return err
+}
@@ -0,0 +1,193 @@
+// Package pubsub exports audit violations to an external message
+// broker so SIEM and reporting pipelines can consume them as a stream
+// instead of scraping constraint status. A driver interface hides the
+// broker protocol; Kafka and NATS drivers are built in and additional
+// ones can be registered. Connection settings live in a ConfigMap in
+// the gatekeeper namespace so operators can repoint the export without
+// redeploying.
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("pubsub").WithValues(logging.Process, "violation_export")
+
+var enableViolationExport = flag.Bool("enable-violation-export", false, "(alpha) publish audit violations to the message broker configured by the gatekeeper-export-config ConfigMap")
+
+// Enabled returns true if violation export was requested via flags.
+func Enabled() bool {
+	return *enableViolationExport
+}
+
+// configMapName is the ConfigMap the export settings are read from. It
+// must declare driver, topic, and addresses; remaining keys are passed
+// through to the driver.
+const configMapName = "gatekeeper-export-config"
+
+// Driver publishes payloads to one kind of broker.
+type Driver interface {
+	// Publish sends one message to the named topic or subject.
+	Publish(ctx context.Context, topic string, payload []byte) error
+	// Close releases the driver's connections.
+	Close() error
+}
+
+// Violation is the message published for each audit violation.
+type Violation struct {
+	ConstraintKind    string `json:"constraintKind"`
+	ConstraintName    string `json:"constraintName"`
+	ResourceKind      string `json:"resourceKind"`
+	ResourceNamespace string `json:"resourceNamespace,omitempty"`
+	ResourceName      string `json:"resourceName"`
+	Message           string `json:"message"`
+	EnforcementAction string `json:"enforcementAction"`
+	AuditTimestamp    string `json:"auditTimestamp"`
+}
+
+var (
+	driversMux sync.Mutex
+	drivers    = map[string]func(config map[string]string) (Driver, error){}
+)
+
+// Register makes a driver available under the given name. Built-in
+// drivers register at init; out-of-tree drivers may register before
+// the manager starts.
+func Register(name string, factory func(config map[string]string) (Driver, error)) {
+	driversMux.Lock()
+	defer driversMux.Unlock()
+	drivers[name] = factory
+}
+
+// Config is the parsed export configuration.
+type Config struct {
+	Driver string
+	Topic  string
+	// Settings carries every other ConfigMap key for the driver.
+	Settings map[string]string
+}
+
+// loadConfig reads the export ConfigMap from the gatekeeper namespace.
+func loadConfig(ctx context.Context, reader client.Reader, namespace string) (*Config, error) {
+	cm := &corev1.ConfigMap{}
+	if err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: configMapName}, cm); err != nil {
+		return nil, fmt.Errorf("fetching ConfigMap %s/%s: %w", namespace, configMapName, err)
+	}
+	return parseConfig(cm.Data)
+}
+
+func parseConfig(data map[string]string) (*Config, error) {
+	cfg := &Config{Settings: map[string]string{}}
+	for k, v := range data {
+		switch k {
+		case "driver":
+			cfg.Driver = v
+		case "topic":
+			cfg.Topic = v
+		default:
+			cfg.Settings[k] = v
+		}
+	}
+	if cfg.Driver == "" {
+		return nil, fmt.Errorf("export config must declare a driver")
+	}
+	if cfg.Topic == "" {
+		return nil, fmt.Errorf("export config must declare a topic")
+	}
+	return cfg, nil
+}
+
+// fingerprint is stable across map iteration order so a connection is
+// only rebuilt when the config actually changes.
+func (c *Config) fingerprint() string {
+	keys := make([]string, 0, len(c.Settings))
+	for k := range c.Settings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := []string{"driver=" + c.Driver, "topic=" + c.Topic}
+	for _, k := range keys {
+		parts = append(parts, k+"="+c.Settings[k])
+	}
+	return strings.Join(parts, "\n")
+}
+
+var (
+	activeMux         sync.Mutex
+	activeDriver      Driver
+	activeFingerprint string
+)
+
+// driverFor returns a connected driver for the config, reusing the
+// active one unless the config changed.
+func driverFor(cfg *Config) (Driver, error) {
+	activeMux.Lock()
+	defer activeMux.Unlock()
+	fp := cfg.fingerprint()
+	if activeDriver != nil && activeFingerprint == fp {
+		return activeDriver, nil
+	}
+	driversMux.Lock()
+	factory, ok := drivers[cfg.Driver]
+	driversMux.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown export driver %q", cfg.Driver)
+	}
+	d, err := factory(cfg.Settings)
+	if err != nil {
+		return nil, err
+	}
+	if activeDriver != nil {
+		if err := activeDriver.Close(); err != nil {
+			log.Error(err, "closing previous export driver")
+		}
+	}
+	activeDriver = d
+	activeFingerprint = fp
+	return d, nil
+}
+
+// Export publishes the violations to the configured broker. A failure
+// publishing one violation does not stop the rest; the first error is
+// returned along with how many publishes failed.
+func Export(ctx context.Context, reader client.Reader, namespace string, violations []Violation) error {
+	cfg, err := loadConfig(ctx, reader, namespace)
+	if err != nil {
+		return err
+	}
+	driver, err := driverFor(cfg)
+	if err != nil {
+		return err
+	}
+	var firstErr error
+	failed := 0
+	for i := range violations {
+		payload, err := json.Marshal(&violations[i])
+		if err != nil {
+			return err
+		}
+		if err := driver.Publish(ctx, cfg.Topic, payload); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+		}
+	}
+	if firstErr != nil {
+		return fmt.Errorf("publishing %d of %d violations failed: %w", failed, len(violations), firstErr)
+	}
+	log.Info("exported violations", "driver", cfg.Driver, "topic", cfg.Topic, "count", len(violations))
+	return nil
+}
@@ -0,0 +1,228 @@
+package pubsub
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := parseConfig(map[string]string{
+		"driver":    "kafka",
+		"topic":     "gatekeeper-violations",
+		"addresses": "broker-1:9092,broker-2:9092",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Driver != "kafka" || cfg.Topic != "gatekeeper-violations" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if cfg.Settings["addresses"] != "broker-1:9092,broker-2:9092" {
+		t.Errorf("addresses not passed through: %v", cfg.Settings)
+	}
+
+	if _, err := parseConfig(map[string]string{"topic": "t"}); err == nil {
+		t.Errorf("expected error when driver is missing")
+	}
+	if _, err := parseConfig(map[string]string{"driver": "kafka"}); err == nil {
+		t.Errorf("expected error when topic is missing")
+	}
+}
+
+func TestConfigFingerprint(t *testing.T) {
+	a := &Config{Driver: "nats", Topic: "t", Settings: map[string]string{"addresses": "x:4222", "extra": "1"}}
+	b := &Config{Driver: "nats", Topic: "t", Settings: map[string]string{"extra": "1", "addresses": "x:4222"}}
+	if a.fingerprint() != b.fingerprint() {
+		t.Errorf("fingerprint should not depend on map order")
+	}
+	c := &Config{Driver: "nats", Topic: "t2", Settings: map[string]string{"addresses": "x:4222", "extra": "1"}}
+	if a.fingerprint() == c.fingerprint() {
+		t.Errorf("fingerprint should change with the topic")
+	}
+}
+
+func TestUnknownDriver(t *testing.T) {
+	if _, err := driverFor(&Config{Driver: "carrier-pigeon", Topic: "t", Settings: map[string]string{}}); err == nil {
+		t.Errorf("expected error for unknown driver")
+	}
+}
+
+// fakeNATSServer accepts one connection, performs the handshake, and
+// reports published messages.
+func fakeNATSServer(t *testing.T) (string, chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	published := make(chan string, 10)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		fmt.Fprintf(conn, "INFO {\"server_id\":\"test\"}\r\n")
+		rd := bufio.NewReader(conn)
+		for {
+			line, err := rd.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "CONNECT") {
+				continue
+			}
+			if strings.HasPrefix(line, "PUB ") {
+				parts := strings.Fields(line)
+				var size int
+				fmt.Sscanf(parts[len(parts)-1], "%d", &size)
+				payload := make([]byte, size+2) // payload + trailing CRLF
+				if _, err := rd.Read(payload); err != nil {
+					return
+				}
+				published <- parts[1] + " " + strings.TrimSpace(string(payload))
+			}
+		}
+	}()
+	return ln.Addr().String(), published
+}
+
+func TestNATSPublish(t *testing.T) {
+	addr, published := fakeNATSServer(t)
+	d, err := newNATSDriver(map[string]string{"addresses": addr})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer d.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.Publish(ctx, "violations", []byte(`{"msg":"denied"}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case got := <-published:
+		want := `violations {"msg":"denied"}`
+		if got != want {
+			t.Errorf("server saw %q, want %q", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("server did not receive the message")
+	}
+}
+
+func TestNATSRequiresAddress(t *testing.T) {
+	if _, err := newNATSDriver(map[string]string{}); err == nil {
+		t.Errorf("expected error when addresses is missing")
+	}
+}
+
+func TestKafkaRequiresAddress(t *testing.T) {
+	if _, err := newKafkaDriver(map[string]string{}); err == nil {
+		t.Errorf("expected error when addresses is missing")
+	}
+	if _, err := newKafkaDriver(map[string]string{"addresses": " , "}); err == nil {
+		t.Errorf("expected error when addresses is empty")
+	}
+}
+
+func TestKafkaVarint(t *testing.T) {
+	tc := []struct {
+		in   int64
+		want []byte
+	}{
+		{0, []byte{0x00}},
+		{-1, []byte{0x01}},
+		{1, []byte{0x02}},
+		{63, []byte{0x7e}},
+		{64, []byte{0x80, 0x01}},
+	}
+	for _, tt := range tc {
+		w := &kafkaWriter{}
+		w.varint(tt.in)
+		got := w.Bytes()
+		if len(got) != len(tt.want) {
+			t.Errorf("varint(%d) = % x, want % x", tt.in, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("varint(%d) = % x, want % x", tt.in, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestDecodeLeader(t *testing.T) {
+	// build a Metadata v1 response naming broker 7 as the leader of
+	// partition 0
+	w := &kafkaWriter{}
+	w.int32(1) // correlation id
+	w.int32(1) // one broker
+	w.int32(7)
+	w.string("broker.example")
+	w.int32(9092)
+	w.nullString() // rack
+	w.int32(7)     // controller id
+	w.int32(1)     // one topic
+	w.int16(0)     // no error
+	w.string("violations")
+	w.int8(0)  // not internal
+	w.int32(1) // one partition
+	w.int16(0) // no error
+	w.int32(0) // partition 0
+	w.int32(7) // leader
+	w.int32(0) // replicas
+	w.int32(0) // isr
+
+	leader, err := decodeLeader(w.Bytes(), "violations")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if leader != "broker.example:9092" {
+		t.Errorf("got leader %q, want broker.example:9092", leader)
+	}
+
+	if _, err := decodeLeader(w.Bytes(), "other-topic"); err == nil {
+		t.Errorf("expected error for topic the response does not mention")
+	}
+	if _, err := decodeLeader([]byte{0x00}, "violations"); err == nil {
+		t.Errorf("expected error for truncated response")
+	}
+}
+
+func TestDecodeProduceError(t *testing.T) {
+	w := &kafkaWriter{}
+	w.int32(2) // correlation id
+	w.int32(1) // one topic
+	w.string("violations")
+	w.int32(1) // one partition
+	w.int32(0) // partition index
+	w.int16(0) // no error
+	w.int64(5) // base offset
+	w.int64(-1)
+	w.int32(0) // throttle
+
+	if code := decodeProduceError(w.Bytes()); code != 0 {
+		t.Errorf("got error code %d, want 0", code)
+	}
+
+	w2 := &kafkaWriter{}
+	w2.int32(2)
+	w2.int32(1)
+	w2.string("violations")
+	w2.int32(1)
+	w2.int32(0)
+	w2.int16(6) // NOT_LEADER_FOR_PARTITION
+	if code := decodeProduceError(w2.Bytes()); code != 6 {
+		t.Errorf("got error code %d, want 6", code)
+	}
+}
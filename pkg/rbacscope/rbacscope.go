@@ -0,0 +1,227 @@
+// Package rbacscope computes the minimal set of list/watch permissions
+// Gatekeeper actually needs for its current sync configuration, instead
+// of assuming cluster-wide read on everything. Depending on the mode it
+// either reports the narrowed ClusterRole so operators can apply it out
+// of band, or generates it in the cluster directly; the deployment's
+// RoleBinding can then point at the narrowed role.
+package rbacscope
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+
+	configv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/config/v1alpha1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/yaml"
+)
+
+var log = logf.Log.WithName("rbac-scope")
+
+// RoleName is the ClusterRole the advisor reports or generates.
+const RoleName = "gatekeeper-minimal-sync-reader"
+
+// syncPeriod is how often the advisor recomputes; sync config changes
+// are picked up within one period.
+const syncPeriod = time.Minute
+
+var minimalRBAC = flag.String("minimal-rbac", "", "(alpha) compute the minimal list/watch permissions required by the current sync config; 'report' logs the narrowed ClusterRole manifest, 'generate' creates or updates it in the cluster as "+RoleName)
+
+// Enabled returns true if RBAC minimization was requested via flags.
+func Enabled() bool {
+	return *minimalRBAC != ""
+}
+
+// Validate rejects unknown modes at startup.
+func Validate() error {
+	switch *minimalRBAC {
+	case "", "report", "generate":
+		return nil
+	default:
+		return fmt.Errorf("invalid --minimal-rbac mode %q; must be 'report' or 'generate'", *minimalRBAC)
+	}
+}
+
+// Advisor recomputes the narrowed ClusterRole as the sync config
+// changes. It implements manager.Runnable.
+type Advisor struct {
+	client client.Client
+	mapper meta.RESTMapper
+	mode   string
+
+	lastReported string
+}
+
+// AddAdvisor registers the advisor with the manager.
+func AddAdvisor(mgr manager.Manager) error {
+	return mgr.Add(&Advisor{
+		client: mgr.GetClient(),
+		mapper: mgr.GetRESTMapper(),
+		mode:   *minimalRBAC,
+	})
+}
+
+// Start implements manager.Runnable. It blocks until ctx is cancelled,
+// recomputing every syncPeriod.
+func (a *Advisor) Start(ctx context.Context) error {
+	ticker := time.NewTicker(syncPeriod)
+	defer ticker.Stop()
+	for {
+		if err := a.sync(ctx); err != nil {
+			log.Error(err, "unable to compute minimal RBAC")
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// sync recomputes the narrowed role and reports or applies it.
+func (a *Advisor) sync(ctx context.Context) error {
+	configs := &configv1alpha1.ConfigList{}
+	if err := a.client.List(ctx, configs); err != nil {
+		return fmt.Errorf("listing sync configs: %w", err)
+	}
+	var entries []configv1alpha1.SyncOnlyEntry
+	for _, cfg := range configs.Items {
+		entries = append(entries, cfg.Spec.Sync.SyncOnly...)
+	}
+
+	rules, err := rulesFor(entries, a.mapper)
+	if err != nil {
+		return err
+	}
+	role := buildRole(rules)
+
+	switch a.mode {
+	case "report":
+		manifest, err := yaml.Marshal(role)
+		if err != nil {
+			return err
+		}
+		// Only log when the computed role changes, so the report is easy
+		// to find instead of drowning in repeats.
+		if string(manifest) == a.lastReported {
+			return nil
+		}
+		a.lastReported = string(manifest)
+		log.Info("minimal sync RBAC computed", "clusterRole", string(manifest))
+		return nil
+	case "generate":
+		return a.apply(ctx, role)
+	}
+	return nil
+}
+
+// apply creates or updates the narrowed ClusterRole.
+func (a *Advisor) apply(ctx context.Context, role *rbacv1.ClusterRole) error {
+	existing := &rbacv1.ClusterRole{}
+	err := a.client.Get(ctx, types.NamespacedName{Name: RoleName}, existing)
+	switch {
+	case apierrors.IsNotFound(err):
+		log.Info("creating minimal sync ClusterRole", "name", RoleName)
+		return a.client.Create(ctx, role)
+	case err != nil:
+		return err
+	}
+	if equalRules(existing.Rules, role.Rules) {
+		return nil
+	}
+	existing.Rules = role.Rules
+	log.Info("updating minimal sync ClusterRole", "name", RoleName)
+	return a.client.Update(ctx, existing)
+}
+
+// rulesFor maps the synced GVKs to list/watch policy rules, grouped by
+// API group. Namespaces are always included; they back namespace
+// selectors and exclusion regardless of sync config.
+func rulesFor(entries []configv1alpha1.SyncOnlyEntry, mapper meta.RESTMapper) ([]rbacv1.PolicyRule, error) {
+	resources := map[string]map[string]bool{
+		"": {"namespaces": true},
+	}
+	for _, entry := range entries {
+		mapping, err := mapper.RESTMapping(schema.GroupKind{Group: entry.Group, Kind: entry.Kind}, entry.Version)
+		if err != nil {
+			return nil, fmt.Errorf("resolving resource for %s/%s %s: %w", entry.Group, entry.Version, entry.Kind, err)
+		}
+		group := mapping.Resource.Group
+		if resources[group] == nil {
+			resources[group] = make(map[string]bool)
+		}
+		resources[group][mapping.Resource.Resource] = true
+	}
+
+	groups := make([]string, 0, len(resources))
+	for group := range resources {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	rules := make([]rbacv1.PolicyRule, 0, len(groups))
+	for _, group := range groups {
+		names := make([]string, 0, len(resources[group]))
+		for resource := range resources[group] {
+			names = append(names, resource)
+		}
+		sort.Strings(names)
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{group},
+			Resources: names,
+			Verbs:     []string{"list", "watch"},
+		})
+	}
+	return rules, nil
+}
+
+// buildRole wraps the rules in the advisor's ClusterRole.
+func buildRole(rules []rbacv1.PolicyRule) *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: rbacv1.SchemeGroupVersion.String(),
+			Kind:       "ClusterRole",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   RoleName,
+			Labels: map[string]string{"gatekeeper.sh/system": "yes"},
+		},
+		Rules: rules,
+	}
+}
+
+func equalRules(a, b []rbacv1.PolicyRule) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !equalStrings(a[i].APIGroups, b[i].APIGroups) ||
+			!equalStrings(a[i].Resources, b[i].Resources) ||
+			!equalStrings(a[i].Verbs, b[i].Verbs) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package rbacscope
+
+import (
+	"reflect"
+	"testing"
+
+	configv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/config/v1alpha1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func makeMapper() meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Service"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"}, meta.RESTScopeNamespace)
+	return mapper
+}
+
+func TestRulesForEmptySyncConfig(t *testing.T) {
+	rules, err := rulesFor(nil, makeMapper())
+	if err != nil {
+		t.Fatalf("computing rules: %s", err)
+	}
+	want := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"list", "watch"}},
+	}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("rules = %v, want namespaces only", rules)
+	}
+}
+
+func TestRulesForGroupsAndSorts(t *testing.T) {
+	entries := []configv1alpha1.SyncOnlyEntry{
+		{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"},
+		{Version: "v1", Kind: "Service"},
+		{Group: "apps", Version: "v1", Kind: "Deployment"},
+		{Version: "v1", Kind: "Pod"},
+		// Duplicates must collapse into a single resource.
+		{Version: "v1", Kind: "Pod"},
+	}
+	rules, err := rulesFor(entries, makeMapper())
+	if err != nil {
+		t.Fatalf("computing rules: %s", err)
+	}
+	want := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"namespaces", "pods", "services"}, Verbs: []string{"list", "watch"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"list", "watch"}},
+		{APIGroups: []string{"networking.k8s.io"}, Resources: []string{"ingresses"}, Verbs: []string{"list", "watch"}},
+	}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("rules = %v, want %v", rules, want)
+	}
+}
+
+func TestRulesForUnknownKind(t *testing.T) {
+	entries := []configv1alpha1.SyncOnlyEntry{
+		{Group: "example.com", Version: "v1", Kind: "Widget"},
+	}
+	if _, err := rulesFor(entries, makeMapper()); err == nil {
+		t.Errorf("unresolvable kinds should surface an error")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	defer func(old string) { *minimalRBAC = old }(*minimalRBAC)
+	for _, mode := range []string{"", "report", "generate"} {
+		*minimalRBAC = mode
+		if err := Validate(); err != nil {
+			t.Errorf("mode %q should be valid: %s", mode, err)
+		}
+	}
+	*minimalRBAC = "apply"
+	if err := Validate(); err == nil {
+		t.Errorf("unknown modes should be rejected")
+	}
+}
+
+func TestBuildRole(t *testing.T) {
+	rules, err := rulesFor(nil, makeMapper())
+	if err != nil {
+		t.Fatalf("computing rules: %s", err)
+	}
+	role := buildRole(rules)
+	if role.Name != RoleName {
+		t.Errorf("role name = %q, want %q", role.Name, RoleName)
+	}
+	if !equalRules(role.Rules, rules) {
+		t.Errorf("role rules should match the computed rules")
+	}
+}
@@ -0,0 +1,229 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package readiness
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// category names one class of readiness expectations. Categories can be
+// skipped or given a timeout independently, and the diagnostics
+// endpoint reports outstanding expectations per category.
+type category string
+
+const (
+	categoryTemplates   category = "templates"
+	categoryConstraints category = "constraints"
+	categoryData        category = "data"
+	categoryMutation    category = "mutation"
+)
+
+var (
+	readinessSkip            = flag.String("readiness-skip", "", "comma-separated readiness categories (templates, constraints, data, mutation) to not wait on before reporting Ready")
+	templatesTimeout         = flag.Duration("readiness-templates-timeout", 0, "how long to wait on constraint template ingestion before treating it as satisfied for readiness. Zero waits indefinitely")
+	constraintsTimeout       = flag.Duration("readiness-constraints-timeout", 0, "how long to wait on constraint ingestion before treating it as satisfied for readiness. Zero waits indefinitely")
+	dataTimeout              = flag.Duration("readiness-data-timeout", 0, "how long to wait on synced data ingestion before treating it as satisfied for readiness. Zero waits indefinitely")
+	mutationTimeout          = flag.Duration("readiness-mutation-timeout", 0, "how long to wait on mutator ingestion before treating it as satisfied for readiness. Zero waits indefinitely")
+	readinessDiagnosticsAddr = flag.String("readiness-diagnostics-addr", "", "if set, serve a verbose readiness report on this address at /debug/readiness, listing the exact objects the tracker is still waiting on")
+)
+
+// parseSkippedCategories validates and parses --readiness-skip.
+func parseSkippedCategories() (map[category]bool, error) {
+	out := make(map[category]bool)
+	for _, entry := range strings.Split(*readinessSkip, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		switch cat := category(entry); cat {
+		case categoryTemplates, categoryConstraints, categoryData, categoryMutation:
+			out[cat] = true
+		default:
+			return nil, fmt.Errorf("unknown readiness category %q in --readiness-skip", entry)
+		}
+	}
+	return out, nil
+}
+
+func timeoutFor(cat category) time.Duration {
+	switch cat {
+	case categoryTemplates:
+		return *templatesTimeout
+	case categoryConstraints:
+		return *constraintsTimeout
+	case categoryData:
+		return *dataTimeout
+	case categoryMutation:
+		return *mutationTimeout
+	}
+	return 0
+}
+
+// overridden reports whether the category should be treated as
+// satisfied regardless of outstanding expectations, either because it
+// was skipped or because its timeout expired. Timed-out categories are
+// logged once so a pod that went Ready on a timeout is explainable.
+func (t *Tracker) overridden(cat category) bool {
+	if t.skipped[cat] {
+		return true
+	}
+	timeout := timeoutFor(cat)
+	if timeout <= 0 {
+		return false
+	}
+	t.mu.RLock()
+	started := t.startedAt
+	t.mu.RUnlock()
+	if started.IsZero() || time.Since(started) < timeout {
+		return false
+	}
+	t.mu.Lock()
+	if !t.timeoutWarned[cat] {
+		t.timeoutWarned[cat] = true
+		log.Info("readiness timeout expired; treating category as satisfied", "category", cat, "timeout", timeout)
+	}
+	t.mu.Unlock()
+	return true
+}
+
+// Diagnostics is the verbose readiness report served by the diagnostics
+// endpoint.
+type Diagnostics struct {
+	Satisfied  bool             `json:"satisfied"`
+	Categories []CategoryStatus `json:"categories"`
+}
+
+// CategoryStatus reports one readiness category and the objects it is
+// still waiting on.
+type CategoryStatus struct {
+	Category   string   `json:"category"`
+	Satisfied  bool     `json:"satisfied"`
+	Overridden bool     `json:"overridden,omitempty"`
+	WaitingFor []string `json:"waitingFor,omitempty"`
+}
+
+// Diagnostics reports, per category, whether the tracker is satisfied
+// and which objects it is still waiting on.
+func (t *Tracker) Diagnostics(ctx context.Context) Diagnostics {
+	d := Diagnostics{Satisfied: t.Satisfied(ctx)}
+
+	if t.mutationEnabled {
+		d.Categories = append(d.Categories, CategoryStatus{
+			Category:   string(categoryMutation),
+			Satisfied:  t.assignMetadata.Satisfied(),
+			Overridden: t.overridden(categoryMutation),
+			WaitingFor: waitingFor(t.assignMetadata),
+		})
+	}
+
+	d.Categories = append(d.Categories, CategoryStatus{
+		Category:   string(categoryTemplates),
+		Satisfied:  t.templates.Satisfied(),
+		Overridden: t.overridden(categoryTemplates),
+		WaitingFor: waitingFor(t.templates),
+	})
+
+	constraints := CategoryStatus{
+		Category:   string(categoryConstraints),
+		Satisfied:  true,
+		Overridden: t.overridden(categoryConstraints),
+	}
+	for _, gvk := range t.templates.kinds() {
+		es := t.constraints.Get(gvk)
+		if !es.Satisfied() {
+			constraints.Satisfied = false
+		}
+		if ot, ok := es.(*objectTracker); ok {
+			constraints.WaitingFor = append(constraints.WaitingFor, waitingFor(ot)...)
+		}
+	}
+	d.Categories = append(d.Categories, constraints)
+
+	data := CategoryStatus{
+		Category:   string(categoryData),
+		Satisfied:  t.config.Satisfied(),
+		Overridden: t.overridden(categoryData),
+		WaitingFor: waitingFor(t.config),
+	}
+	for _, gvk := range t.config.kinds() {
+		es := t.data.Get(gvk)
+		if !es.Satisfied() {
+			data.Satisfied = false
+		}
+		if ot, ok := es.(*objectTracker); ok {
+			data.WaitingFor = append(data.WaitingFor, waitingFor(ot)...)
+		}
+	}
+	d.Categories = append(d.Categories, data)
+
+	return d
+}
+
+func waitingFor(ot *objectTracker) []string {
+	unsat := ot.unsatisfied()
+	out := make([]string, 0, len(unsat))
+	for _, k := range unsat {
+		out = append(out, k.String())
+	}
+	return out
+}
+
+// diagnosticsServer serves the verbose readiness report. It implements
+// manager.Runnable so it starts and stops with the manager.
+type diagnosticsServer struct {
+	tracker *Tracker
+	addr    string
+}
+
+// Start implements manager.Runnable.
+func (s *diagnosticsServer) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/readiness", s.handleDiagnostics)
+	srv := &http.Server{Addr: s.addr, Handler: mux}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+func (s *diagnosticsServer) handleDiagnostics(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s.tracker.Diagnostics(req.Context())); err != nil {
+		log.Error(err, "writing readiness diagnostics")
+	}
+}
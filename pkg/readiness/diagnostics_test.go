@@ -0,0 +1,97 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package readiness
+
+import (
+	"context"
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestParseSkippedCategories(t *testing.T) {
+	defer func() {
+		_ = flag.Set("readiness-skip", "")
+	}()
+
+	if err := flag.Set("readiness-skip", "data, mutation"); err != nil {
+		t.Fatal(err)
+	}
+	skipped, err := parseSkippedCategories()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !skipped[categoryData] || !skipped[categoryMutation] {
+		t.Errorf("expected data and mutation to be skipped, got %v", skipped)
+	}
+	if skipped[categoryTemplates] || skipped[categoryConstraints] {
+		t.Errorf("unexpected categories skipped: %v", skipped)
+	}
+
+	if err := flag.Set("readiness-skip", "templates,bogus"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseSkippedCategories(); err == nil {
+		t.Error("expected an error for an unknown category")
+	}
+}
+
+func TestSatisfiedHonorsSkippedCategories(t *testing.T) {
+	tracker := NewTracker(nil, false)
+	tracker.templates.Expect(makeCT("waiting"))
+
+	if tracker.Satisfied(context.Background()) {
+		t.Fatal("tracker with outstanding template expectations should not be satisfied")
+	}
+
+	tracker = NewTracker(nil, false)
+	tracker.templates.Expect(makeCT("waiting"))
+	tracker.skipped = map[category]bool{
+		categoryTemplates:   true,
+		categoryConstraints: true,
+		categoryData:        true,
+	}
+	if !tracker.Satisfied(context.Background()) {
+		t.Fatal("tracker should be satisfied when all categories are skipped")
+	}
+}
+
+func TestDiagnosticsListsOutstandingObjects(t *testing.T) {
+	tracker := NewTracker(nil, false)
+	ct := makeCT("waiting")
+	ct.Spec.CRD.Spec.Names.Kind = "Waiting"
+	tracker.templates.Expect(ct)
+
+	d := tracker.Diagnostics(context.Background())
+	if d.Satisfied {
+		t.Fatal("diagnostics should report unsatisfied")
+	}
+	var templatesStatus *CategoryStatus
+	for i := range d.Categories {
+		if d.Categories[i].Category == string(categoryTemplates) {
+			templatesStatus = &d.Categories[i]
+		}
+	}
+	if templatesStatus == nil {
+		t.Fatal("expected a templates category in diagnostics")
+	}
+	if templatesStatus.Satisfied {
+		t.Error("templates category should be unsatisfied")
+	}
+	if len(templatesStatus.WaitingFor) != 1 || !strings.Contains(templatesStatus.WaitingFor[0], "waiting") {
+		t.Errorf("expected the outstanding template to be listed, got %v", templatesStatus.WaitingFor)
+	}
+}
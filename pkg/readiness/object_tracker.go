@@ -324,6 +324,17 @@ func (t *objectTracker) Satisfied() bool {
 	return t.allSatisfied
 }
 
+// progress returns how many expectations have been satisfied out of the
+// total seen so far. Once the circuit-breaker has tripped the tracking
+// memory is freed, so a fully satisfied tracker reports 0/0.
+func (t *objectTracker) progress() (satisfied, expected int) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	satisfied = len(t.satisfied)
+	expected = satisfied + len(t.expect)
+	return
+}
+
 func (t *objectTracker) kinds() []schema.GroupVersionKind {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
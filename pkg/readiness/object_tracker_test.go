@@ -78,6 +78,30 @@ func Test_ObjectTracker_Multiple_Expectations(t *testing.T) {
 	g.Expect(ot.Satisfied()).To(gomega.BeTrue(), "should be satisfied")
 }
 
+// Verify that progress reports satisfied vs. expected counts as
+// observations come in.
+func Test_ObjectTracker_Progress(t *testing.T) {
+	g := gomega.NewWithT(t)
+	ot := newObjTracker(schema.GroupVersionKind{}, nil)
+
+	const count = 4
+	ct := makeCTSlice("ct-", count)
+	for i := 0; i < len(ct); i++ {
+		ot.Expect(ct[i])
+	}
+	ot.ExpectationsDone()
+
+	satisfied, expected := ot.progress()
+	g.Expect(satisfied).To(gomega.Equal(0), "no observations yet")
+	g.Expect(expected).To(gomega.Equal(count))
+
+	ot.Observe(ct[0])
+	g.Expect(ot.Satisfied()).NotTo(gomega.BeTrue(), "should not be satisfied yet")
+	satisfied, expected = ot.progress()
+	g.Expect(satisfied).To(gomega.Equal(1), "one observation resolved")
+	g.Expect(expected).To(gomega.Equal(count))
+}
+
 // Verify that observations can precede expectations.
 func Test_ObjectTracker_Seen_Before_Expect(t *testing.T) {
 	g := gomega.NewWithT(t)
@@ -67,10 +67,21 @@ type Tracker struct {
 	constraintTrackers *syncutil.SingleRunner
 	statsEnabled       syncutil.SyncBool
 	mutationEnabled    bool
+
+	skipped       map[category]bool // categories not waited on, per --readiness-skip
+	startedAt     time.Time         // when Run began; anchors the category timeouts. Guarded by mu.
+	timeoutWarned map[category]bool // categories whose timeout expiry has been logged. Guarded by mu.
 }
 
 // NewTracker creates a new Tracker and initializes the internal trackers
 func NewTracker(lister Lister, mutationEnabled bool) *Tracker {
+	skipped, err := parseSkippedCategories()
+	if err != nil {
+		// SetupTracker validates the flag before getting here; direct
+		// callers get the same behavior minus the hard failure.
+		log.Error(err, "ignoring invalid --readiness-skip")
+		skipped = make(map[category]bool)
+	}
 	tracker := Tracker{
 		lister:             lister,
 		templates:          newObjTracker(v1beta1.SchemeGroupVersion.WithKind("ConstraintTemplate"), nil),
@@ -81,6 +92,9 @@ func NewTracker(lister Lister, mutationEnabled bool) *Tracker {
 		constraintTrackers: &syncutil.SingleRunner{},
 
 		mutationEnabled: mutationEnabled,
+
+		skipped:       skipped,
+		timeoutWarned: make(map[category]bool),
 	}
 	if mutationEnabled {
 		tracker.assignMetadata = newObjTracker(mutationv1alpha.GroupVersion.WithKind("AssignMetadata"), nil)
@@ -92,7 +106,11 @@ func NewTracker(lister Lister, mutationEnabled bool) *Tracker {
 // Returns nil if all expectations have been satisfied, otherwise returns an error.
 func (t *Tracker) CheckSatisfied(req *http.Request) error {
 	if !t.Satisfied(req.Context()) {
-		return errors.New("expectations not satisfied")
+		satisfied, expected := t.templates.progress()
+		if *readinessDiagnosticsAddr != "" {
+			return fmt.Errorf("expectations not satisfied: %d/%d constraint templates observed; see %s/debug/readiness for details", satisfied, expected, *readinessDiagnosticsAddr)
+		}
+		return fmt.Errorf("expectations not satisfied: %d/%d constraint templates observed", satisfied, expected)
 	}
 	return nil
 }
@@ -157,34 +175,38 @@ func (t *Tracker) Satisfied(ctx context.Context) bool {
 		return true
 	}
 
-	if t.mutationEnabled {
+	if t.mutationEnabled && !t.overridden(categoryMutation) {
 		if !t.assignMetadata.Satisfied() {
 			return false
 		}
 		log.V(1).Info("all expectations satisfied", "tracker", "assignMetadata")
 	}
 
-	if !t.templates.Satisfied() {
+	if !t.overridden(categoryTemplates) && !t.templates.Satisfied() {
 		return false
 	}
-	templateKinds := t.templates.kinds()
-	for _, gvk := range templateKinds {
-		if !t.constraints.Get(gvk).Satisfied() {
-			return false
+	if !t.overridden(categoryConstraints) {
+		templateKinds := t.templates.kinds()
+		for _, gvk := range templateKinds {
+			if !t.constraints.Get(gvk).Satisfied() {
+				return false
+			}
 		}
+		log.V(1).Info("all expectations satisfied", "tracker", "constraints")
 	}
-	log.V(1).Info("all expectations satisfied", "tracker", "constraints")
 
-	if !t.config.Satisfied() {
-		return false
-	}
-	configKinds := t.config.kinds()
-	for _, gvk := range configKinds {
-		if !t.data.Get(gvk).Satisfied() {
+	if !t.overridden(categoryData) {
+		if !t.config.Satisfied() {
 			return false
 		}
+		configKinds := t.config.kinds()
+		for _, gvk := range configKinds {
+			if !t.data.Get(gvk).Satisfied() {
+				return false
+			}
+		}
+		log.V(1).Info("all expectations satisfied", "tracker", "data")
 	}
-	log.V(1).Info("all expectations satisfied", "tracker", "data")
 
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -200,6 +222,9 @@ func (t *Tracker) Run(ctx context.Context) error {
 	// routines.
 	grp, gctx := errgroup.WithContext(ctx)
 	t.constraintTrackers = syncutil.RunnerWithContext(gctx)
+	t.mu.Lock()
+	t.startedAt = time.Now() // anchors the per-category readiness timeouts
+	t.mu.Unlock()
 	close(t.ready) // The constraintTrackers SingleRunner is ready.
 
 	if t.mutationEnabled {
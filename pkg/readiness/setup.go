@@ -26,6 +26,9 @@ import (
 // provided Manager object.
 // NOTE: Must be called _before_ the manager is started.
 func SetupTracker(mgr manager.Manager, mutationEnabled bool) (*Tracker, error) {
+	if _, err := parseSkippedCategories(); err != nil {
+		return nil, err
+	}
 	tracker := NewTracker(mgr.GetAPIReader(), mutationEnabled)
 
 	err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
@@ -39,5 +42,19 @@ func SetupTracker(mgr manager.Manager, mutationEnabled bool) (*Tracker, error) {
 		return nil, fmt.Errorf("registering readiness check: %w", err)
 	}
 
+	if *readinessSyncStatus {
+		writer := &syncStatusWriter{client: mgr.GetClient(), tracker: tracker}
+		if err := mgr.Add(writer); err != nil {
+			return nil, fmt.Errorf("adding sync status writer to manager: %w", err)
+		}
+	}
+
+	if *readinessDiagnosticsAddr != "" {
+		srv := &diagnosticsServer{tracker: tracker, addr: *readinessDiagnosticsAddr}
+		if err := mgr.Add(srv); err != nil {
+			return nil, fmt.Errorf("adding readiness diagnostics server to manager: %w", err)
+		}
+	}
+
 	return tracker, nil
 }
@@ -0,0 +1,97 @@
+package readiness
+
+import (
+	"context"
+	"flag"
+	"sort"
+	"time"
+
+	configv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/config/v1alpha1"
+	"github.com/open-policy-agent/gatekeeper/pkg/keys"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var readinessSyncStatus = flag.Bool("readiness-sync-status", false, "(alpha) surface per-GVK data sync readiness on the Config resource status while startup is in progress")
+
+const syncStatusInterval = 5 * time.Second
+
+// DataSyncStatus reports, per GVK under sync, whether the initial list
+// has been replicated. Until the Config has been ingested the result is
+// empty.
+func (t *Tracker) DataSyncStatus() []configv1alpha1.SyncGVKStatus {
+	kinds := t.config.kinds()
+	sort.Slice(kinds, func(i, j int) bool {
+		return kinds[i].String() < kinds[j].String()
+	})
+	var out []configv1alpha1.SyncGVKStatus
+	for _, gvk := range kinds {
+		out = append(out, configv1alpha1.SyncGVKStatus{
+			Group:   gvk.Group,
+			Version: gvk.Version,
+			Kind:    gvk.Kind,
+			Synced:  t.data.Get(gvk).Satisfied(),
+		})
+	}
+	return out
+}
+
+// ConstraintsSynced reports whether every constraint of every ingested
+// template kind has been loaded.
+func (t *Tracker) ConstraintsSynced() bool {
+	if !t.templates.Satisfied() {
+		return false
+	}
+	for _, gvk := range t.templates.kinds() {
+		if !t.constraints.Get(gvk).Satisfied() {
+			return false
+		}
+	}
+	return true
+}
+
+// syncStatusWriter mirrors the tracker's progress onto the Config
+// resource status, so the fail-open window during startup is observable
+// per GVK rather than only as a pod-level readiness bit. It stops after
+// the tracker is satisfied and that final state has been written.
+type syncStatusWriter struct {
+	client  client.Client
+	tracker *Tracker
+}
+
+// Start implements manager.Runnable.
+func (w *syncStatusWriter) Start(ctx context.Context) error {
+	ticker := time.NewTicker(syncStatusInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			satisfied := w.tracker.Satisfied(ctx)
+			if err := w.write(ctx, satisfied); err != nil {
+				log.Error(err, "could not write sync status to Config")
+				continue
+			}
+			if satisfied {
+				log.Info("readiness satisfied, sync status final")
+				return nil
+			}
+		}
+	}
+}
+
+func (w *syncStatusWriter) write(ctx context.Context, satisfied bool) error {
+	config := &configv1alpha1.Config{}
+	if err := w.client.Get(ctx, keys.Config, config); err != nil {
+		// without a Config there is nothing to report on
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	config.Status.Ready = satisfied
+	config.Status.ConstraintsSynced = w.tracker.ConstraintsSynced()
+	config.Status.SyncStatus = w.tracker.DataSyncStatus()
+	return w.client.Status().Update(ctx, config)
+}
@@ -0,0 +1,76 @@
+package selfprotect
+
+import (
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func makeRequest(group, kind, name, username string, groups ...string) *admissionv1.AdmissionRequest {
+	return &admissionv1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Group: group, Version: "v1", Kind: kind},
+		Name:      name,
+		Operation: admissionv1.Update,
+		UserInfo: authenticationv1.UserInfo{
+			Username: username,
+			Groups:   groups,
+		},
+	}
+}
+
+func setAdmins(t *testing.T, admins string) {
+	t.Helper()
+	old := *systemResourceAdmins
+	t.Cleanup(func() { *systemResourceAdmins = old })
+	*systemResourceAdmins = admins
+}
+
+func TestDeniedProtectedGroups(t *testing.T) {
+	setAdmins(t, "admin@example.com,platform-admins")
+	for _, group := range []string{
+		"templates.gatekeeper.sh",
+		"constraints.gatekeeper.sh",
+		"config.gatekeeper.sh",
+		"mutations.gatekeeper.sh",
+	} {
+		if _, denied := Denied(makeRequest(group, "Anything", "x", "intruder")); !denied {
+			t.Errorf("writes to %s by non-admins should be denied", group)
+		}
+	}
+}
+
+func TestDeniedWebhookConfigs(t *testing.T) {
+	setAdmins(t, "platform-admins")
+	req := makeRequest("admissionregistration.k8s.io", "ValidatingWebhookConfiguration",
+		"gatekeeper-validating-webhook-configuration", "intruder")
+	if _, denied := Denied(req); !denied {
+		t.Errorf("edits to Gatekeeper's webhook configuration should be denied")
+	}
+	other := makeRequest("admissionregistration.k8s.io", "ValidatingWebhookConfiguration",
+		"some-other-webhook", "intruder")
+	if _, denied := Denied(other); denied {
+		t.Errorf("unrelated webhook configurations should not be protected")
+	}
+}
+
+func TestAdminsAreAllowed(t *testing.T) {
+	setAdmins(t, "admin@example.com,platform-admins")
+	byName := makeRequest("templates.gatekeeper.sh", "ConstraintTemplate", "tmpl", "admin@example.com")
+	if msg, denied := Denied(byName); denied {
+		t.Errorf("admin user should be allowed, got %q", msg)
+	}
+	byGroup := makeRequest("templates.gatekeeper.sh", "ConstraintTemplate", "tmpl", "someone", "platform-admins")
+	if msg, denied := Denied(byGroup); denied {
+		t.Errorf("admin group member should be allowed, got %q", msg)
+	}
+}
+
+func TestUnprotectedResourcesPass(t *testing.T) {
+	setAdmins(t, "platform-admins")
+	req := makeRequest("apps", "Deployment", "nginx", "intruder")
+	if _, denied := Denied(req); denied {
+		t.Errorf("ordinary resources should not be affected by self-protection")
+	}
+}
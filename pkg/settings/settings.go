@@ -0,0 +1,180 @@
+// Package settings reconciles the GatekeeperConfig resource: a
+// cluster-scoped singleton that manages runtime knobs otherwise spread
+// across command-line flags (audit interval, violation limits, exempt
+// namespaces, deny logging). Safe knobs are applied live by updating the
+// corresponding flag values, which their owners re-read each cycle;
+// settings the running process cannot change, like the log level, are
+// reported on the status as requiring a restart.
+package settings
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	settingsv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/settings/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+var log = logf.Log.WithName("settings")
+
+// SingletonName is the only GatekeeperConfig name honored, mirroring the
+// Config resource's singleton convention.
+const SingletonName = "gatekeeper-config"
+
+// tickPeriod is how often the singleton is reconciled.
+const tickPeriod = 30 * time.Second
+
+var enableGatekeeperConfig = flag.Bool("enable-gatekeeper-config", false, "(alpha) reconcile runtime settings from the GatekeeperConfig resource, applying safe changes without a restart")
+
+// validLogLevels are the values accepted for spec.logLevel.
+var validLogLevels = map[string]bool{"DEBUG": true, "INFO": true, "WARNING": true, "ERROR": true}
+
+// Enabled returns true if GatekeeperConfig reconciliation was requested
+// via flags.
+func Enabled() bool {
+	return *enableGatekeeperConfig
+}
+
+// +kubebuilder:rbac:groups=settings.gatekeeper.sh,resources=gatekeeperconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=settings.gatekeeper.sh,resources=gatekeeperconfigs/status,verbs=get;update;patch
+
+// Reconciler applies the GatekeeperConfig singleton to the running
+// process. It implements manager.Runnable.
+type Reconciler struct {
+	client              client.Client
+	appliedGeneration   int64
+	appliedSuccessfully bool
+}
+
+// AddReconciler registers the reconciler with the manager.
+func AddReconciler(mgr manager.Manager) error {
+	return mgr.Add(&Reconciler{client: mgr.GetClient()})
+}
+
+// Start implements manager.Runnable.
+func (r *Reconciler) Start(ctx context.Context) error {
+	ticker := time.NewTicker(tickPeriod)
+	defer ticker.Stop()
+	for {
+		r.reconcile(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// reconcile fetches the singleton and applies it when its generation
+// changed or the last attempt failed.
+func (r *Reconciler) reconcile(ctx context.Context) {
+	cfg := &settingsv1alpha1.GatekeeperConfig{}
+	err := r.client.Get(ctx, types.NamespacedName{Name: SingletonName}, cfg)
+	switch {
+	case apierrors.IsNotFound(err) || meta.IsNoMatchError(err):
+		return
+	case err != nil:
+		log.Error(err, "unable to fetch GatekeeperConfig")
+		return
+	}
+	if cfg.GetGeneration() == r.appliedGeneration && r.appliedSuccessfully {
+		return
+	}
+	r.appliedGeneration = cfg.GetGeneration()
+
+	restartRequired, applyErr := Apply(&cfg.Spec)
+	r.appliedSuccessfully = applyErr == nil
+	if applyErr != nil {
+		log.Error(applyErr, "unable to apply GatekeeperConfig")
+		cfg.Status.LastError = applyErr.Error()
+	} else {
+		log.Info("applied GatekeeperConfig", "generation", cfg.GetGeneration(), "restartRequired", restartRequired)
+		cfg.Status.LastError = ""
+		cfg.Status.LastAppliedTime = metav1.Now()
+	}
+	cfg.Status.ObservedGeneration = cfg.GetGeneration()
+	cfg.Status.RestartRequired = restartRequired
+	if err := r.client.Status().Update(ctx, cfg); err != nil {
+		log.Error(err, "unable to update GatekeeperConfig status")
+	}
+}
+
+// flagUpdate is one flag the spec sets.
+type flagUpdate struct {
+	name  string
+	value string
+}
+
+// ValidateSpec rejects settings the flags would reject, so a bad value
+// surfaces on the status instead of half-applying.
+func ValidateSpec(spec *settingsv1alpha1.GatekeeperConfigSpec) error {
+	if spec.AuditIntervalSeconds != nil && *spec.AuditIntervalSeconds < 0 {
+		return fmt.Errorf("auditIntervalSeconds must not be negative")
+	}
+	if spec.ConstraintViolationsLimit != nil && *spec.ConstraintViolationsLimit < 1 {
+		return fmt.Errorf("constraintViolationsLimit must be at least 1")
+	}
+	for _, ns := range spec.ExemptNamespaces {
+		if ns == "" {
+			return fmt.Errorf("exemptNamespaces entries must be namespace names")
+		}
+	}
+	if spec.LogLevel != "" && !validLogLevels[spec.LogLevel] {
+		return fmt.Errorf("logLevel %q is not one of DEBUG, INFO, WARNING, ERROR", spec.LogLevel)
+	}
+	return nil
+}
+
+// FlagUpdates maps the spec's live-applicable settings onto the flags
+// that own them.
+func FlagUpdates(spec *settingsv1alpha1.GatekeeperConfigSpec) []flagUpdate {
+	var updates []flagUpdate
+	if spec.AuditIntervalSeconds != nil {
+		updates = append(updates, flagUpdate{"audit-interval", strconv.FormatInt(*spec.AuditIntervalSeconds, 10)})
+	}
+	if spec.ConstraintViolationsLimit != nil {
+		updates = append(updates, flagUpdate{"constraint-violations-limit", strconv.FormatInt(*spec.ConstraintViolationsLimit, 10)})
+	}
+	if spec.LogDenies != nil {
+		updates = append(updates, flagUpdate{"log-denies", strconv.FormatBool(*spec.LogDenies)})
+	}
+	if spec.EmitAdmissionEvents != nil {
+		updates = append(updates, flagUpdate{"emit-admission-events", strconv.FormatBool(*spec.EmitAdmissionEvents)})
+	}
+	// --exempt-namespace is a set flag: each Set call adds one namespace.
+	// Entries removed from the spec stay exempt until restart.
+	for _, ns := range spec.ExemptNamespaces {
+		updates = append(updates, flagUpdate{"exempt-namespace", ns})
+	}
+	return updates
+}
+
+// Apply validates the spec and pushes its settings into the owning
+// flags, returning the settings that only take effect after a restart.
+func Apply(spec *settingsv1alpha1.GatekeeperConfigSpec) ([]string, error) {
+	if err := ValidateSpec(spec); err != nil {
+		return nil, err
+	}
+	for _, update := range FlagUpdates(spec) {
+		if err := flag.Set(update.name, update.value); err != nil {
+			return nil, fmt.Errorf("setting --%s: %w", update.name, err)
+		}
+	}
+	var restartRequired []string
+	if spec.LogLevel != "" {
+		if f := flag.Lookup("log-level"); f != nil && f.Value.String() != spec.LogLevel {
+			// the logger is built once at startup
+			restartRequired = append(restartRequired, "logLevel")
+		}
+	}
+	return restartRequired, nil
+}
@@ -0,0 +1,91 @@
+package settings
+
+import (
+	"testing"
+
+	settingsv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/settings/v1alpha1"
+)
+
+func int64Ptr(v int64) *int64 { return &v }
+func boolPtr(v bool) *bool    { return &v }
+
+func TestValidateSpec(t *testing.T) {
+	tc := []struct {
+		name          string
+		spec          settingsv1alpha1.GatekeeperConfigSpec
+		errorExpected bool
+	}{
+		{
+			name: "empty spec is valid",
+			spec: settingsv1alpha1.GatekeeperConfigSpec{},
+		},
+		{
+			name: "zero audit interval disables audit",
+			spec: settingsv1alpha1.GatekeeperConfigSpec{AuditIntervalSeconds: int64Ptr(0)},
+		},
+		{
+			name:          "negative audit interval",
+			spec:          settingsv1alpha1.GatekeeperConfigSpec{AuditIntervalSeconds: int64Ptr(-1)},
+			errorExpected: true,
+		},
+		{
+			name:          "zero violations limit",
+			spec:          settingsv1alpha1.GatekeeperConfigSpec{ConstraintViolationsLimit: int64Ptr(0)},
+			errorExpected: true,
+		},
+		{
+			name:          "empty exempt namespace",
+			spec:          settingsv1alpha1.GatekeeperConfigSpec{ExemptNamespaces: []string{""}},
+			errorExpected: true,
+		},
+		{
+			name: "known log level",
+			spec: settingsv1alpha1.GatekeeperConfigSpec{LogLevel: "DEBUG"},
+		},
+		{
+			name:          "unknown log level",
+			spec:          settingsv1alpha1.GatekeeperConfigSpec{LogLevel: "TRACE"},
+			errorExpected: true,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSpec(&tt.spec)
+			if (err != nil) != tt.errorExpected {
+				t.Errorf("error expected = %v, got %v", tt.errorExpected, err)
+			}
+		})
+	}
+}
+
+func TestFlagUpdates(t *testing.T) {
+	spec := &settingsv1alpha1.GatekeeperConfigSpec{
+		AuditIntervalSeconds:      int64Ptr(120),
+		ConstraintViolationsLimit: int64Ptr(50),
+		LogDenies:                 boolPtr(true),
+		ExemptNamespaces:          []string{"kube-system", "gatekeeper-system"},
+	}
+	got := map[string][]string{}
+	for _, update := range FlagUpdates(spec) {
+		got[update.name] = append(got[update.name], update.value)
+	}
+	if len(got["audit-interval"]) != 1 || got["audit-interval"][0] != "120" {
+		t.Errorf("audit-interval should map from auditIntervalSeconds, got %v", got["audit-interval"])
+	}
+	if len(got["constraint-violations-limit"]) != 1 || got["constraint-violations-limit"][0] != "50" {
+		t.Errorf("constraint-violations-limit should map from constraintViolationsLimit, got %v", got["constraint-violations-limit"])
+	}
+	if len(got["log-denies"]) != 1 || got["log-denies"][0] != "true" {
+		t.Errorf("log-denies should map from logDenies, got %v", got["log-denies"])
+	}
+	if len(got["exempt-namespace"]) != 2 {
+		t.Errorf("each exempt namespace should map to one flag update, got %v", got["exempt-namespace"])
+	}
+	if _, found := got["emit-admission-events"]; found {
+		t.Errorf("unset fields must not touch their flags")
+	}
+
+	if updates := FlagUpdates(&settingsv1alpha1.GatekeeperConfigSpec{}); len(updates) != 0 {
+		t.Errorf("an empty spec should produce no updates, got %v", updates)
+	}
+}
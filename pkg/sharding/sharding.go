@@ -0,0 +1,229 @@
+// Package sharding spreads admission load across multiple webhook
+// deployments. Namespaces are assigned to shards by name hash via a
+// label, and each deployment maintains its own webhook configuration —
+// derived from the base one — whose namespaceSelector confines it to
+// the shard's namespaces. The base configuration keeps serving
+// namespaces that have no shard label yet, so namespaces created
+// between resyncs are never left without admission. Very large clusters
+// can then scale admission horizontally beyond what one deployment's
+// replicas allow.
+package sharding
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"time"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/logging"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("webhook-sharding").WithValues(logging.Process, "webhook_sharding")
+
+// ShardLabel carries a namespace's shard assignment. It is managed by
+// Gatekeeper; manual edits are reverted on the next resync.
+const ShardLabel = "admission.gatekeeper.sh/shard"
+
+const resyncPeriod = time.Minute
+
+var (
+	webhookShardCount = flag.Int("webhook-shard-count", 0, "(alpha) total number of webhook deployments sharing admission load; 0 disables sharding")
+	webhookShardIndex = flag.Int("webhook-shard-index", 0, "(alpha) which shard this deployment serves, in [0, webhook-shard-count)")
+)
+
+// Enabled returns true when webhook sharding was requested via flags.
+func Enabled() bool {
+	return *webhookShardCount > 1
+}
+
+// ShardFor returns the shard a namespace belongs to.
+func ShardFor(namespace string, count int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(namespace))
+	return int(h.Sum32() % uint32(count))
+}
+
+// Manager keeps namespace shard labels and this deployment's webhook
+// namespaceSelector in sync. It implements manager.Runnable.
+type Manager struct {
+	client  client.Client
+	vwhName string
+	count   int
+	index   int
+}
+
+// New returns a sharding Manager configured from the sharding flags.
+func New(c client.Client, vwhName string) (*Manager, error) {
+	if *webhookShardIndex < 0 || *webhookShardIndex >= *webhookShardCount {
+		return nil, fmt.Errorf("webhook-shard-index %d must be in [0, %d)", *webhookShardIndex, *webhookShardCount)
+	}
+	return &Manager{
+		client:  c,
+		vwhName: vwhName,
+		count:   *webhookShardCount,
+		index:   *webhookShardIndex,
+	}, nil
+}
+
+// Start implements manager.Runnable.
+func (m *Manager) Start(ctx context.Context) error {
+	ticker := time.NewTicker(resyncPeriod)
+	defer ticker.Stop()
+	for {
+		m.resync(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (m *Manager) resync(ctx context.Context) {
+	if err := m.assignShards(ctx); err != nil {
+		log.Error(err, "unable to assign namespace shards")
+	}
+	if err := m.ensureBaseSelector(ctx); err != nil {
+		log.Error(err, "unable to update base webhook namespaceSelector")
+	}
+	if err := m.ensureShardWebhook(ctx); err != nil {
+		log.Error(err, "unable to reconcile shard webhook configuration")
+	}
+}
+
+// assignShards labels every namespace with its shard by name hash.
+func (m *Manager) assignShards(ctx context.Context) error {
+	nsList := &corev1.NamespaceList{}
+	if err := m.client.List(ctx, nsList); err != nil {
+		return err
+	}
+	for i := range nsList.Items {
+		ns := &nsList.Items[i]
+		want := strconv.Itoa(ShardFor(ns.Name, m.count))
+		if ns.Labels[ShardLabel] == want {
+			continue
+		}
+		if ns.Labels == nil {
+			ns.Labels = make(map[string]string)
+		}
+		ns.Labels[ShardLabel] = want
+		if err := m.client.Update(ctx, ns); err != nil {
+			log.Error(err, "unable to label namespace with shard", logging.ResourceName, ns.Name, "shard", want)
+			continue
+		}
+		log.Info("assigned namespace to shard", logging.ResourceName, ns.Name, "shard", want)
+	}
+	return nil
+}
+
+// shardName appends a shard's suffix to a base object name. It is used
+// for both the shard's webhook configuration and its service.
+func shardName(base string, index int) string {
+	return fmt.Sprintf("%s-shard-%d", base, index)
+}
+
+// shardSelector confines a webhook to the namespaces of one shard.
+func shardSelector(index int) *metav1.LabelSelector {
+	return &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+		Key:      ShardLabel,
+		Operator: metav1.LabelSelectorOpIn,
+		Values:   []string{strconv.Itoa(index)},
+	}}}
+}
+
+// unlabeledSelector matches only namespaces that carry no shard label.
+func unlabeledSelector() *metav1.LabelSelector {
+	return &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+		Key:      ShardLabel,
+		Operator: metav1.LabelSelectorOpDoesNotExist,
+	}}}
+}
+
+// ensureBaseSelector restricts the base webhook configuration to
+// namespaces that have not been assigned a shard yet. It stays
+// registered as the catch-all for namespaces created between resyncs,
+// so none of them is ever left without admission. Every shard writes
+// the same selector, so concurrent managers converge instead of
+// fighting over it.
+func (m *Manager) ensureBaseSelector(ctx context.Context) error {
+	vwh := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+	if err := m.client.Get(ctx, types.NamespacedName{Name: m.vwhName}, vwh); err != nil {
+		return err
+	}
+	selector := unlabeledSelector()
+	changed := false
+	for i := range vwh.Webhooks {
+		if equalSelector(vwh.Webhooks[i].NamespaceSelector, selector) {
+			continue
+		}
+		vwh.Webhooks[i].NamespaceSelector = selector.DeepCopy()
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	log.Info("restricting base webhook configuration to unlabeled namespaces")
+	return m.client.Update(ctx, vwh)
+}
+
+// ensureShardWebhook maintains this shard's own webhook configuration
+// as a copy of the base one, confined to the shard's namespaces and
+// pointing at the shard's service. Re-deriving it on every resync also
+// propagates certificate rotation of the base configuration.
+func (m *Manager) ensureShardWebhook(ctx context.Context) error {
+	base := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+	if err := m.client.Get(ctx, types.NamespacedName{Name: m.vwhName}, base); err != nil {
+		return err
+	}
+	desired := shardWebhooks(base, m.index)
+	name := shardName(m.vwhName, m.index)
+	shard := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+	err := m.client.Get(ctx, types.NamespacedName{Name: name}, shard)
+	if apierrors.IsNotFound(err) {
+		shard = &admissionregistrationv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: base.Labels},
+			Webhooks:   desired,
+		}
+		log.Info("creating shard webhook configuration", logging.ResourceName, name, "shard", m.index)
+		return m.client.Create(ctx, shard)
+	}
+	if err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(shard.Webhooks, desired) {
+		return nil
+	}
+	shard.Webhooks = desired
+	log.Info("updating shard webhook configuration", logging.ResourceName, name, "shard", m.index)
+	return m.client.Update(ctx, shard)
+}
+
+// shardWebhooks derives one shard's webhooks from the base
+// configuration: same rules and CA bundle, the shard's service, and the
+// shard's namespaceSelector.
+func shardWebhooks(base *admissionregistrationv1.ValidatingWebhookConfiguration, index int) []admissionregistrationv1.ValidatingWebhook {
+	var out []admissionregistrationv1.ValidatingWebhook
+	for i := range base.Webhooks {
+		w := base.Webhooks[i].DeepCopy()
+		w.NamespaceSelector = shardSelector(index)
+		if svc := w.ClientConfig.Service; svc != nil {
+			svc.Name = shardName(svc.Name, index)
+		}
+		out = append(out, *w)
+	}
+	return out
+}
+
+func equalSelector(got, want *metav1.LabelSelector) bool {
+	return got != nil && equality.Semantic.DeepEqual(got, want)
+}
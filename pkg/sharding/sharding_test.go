@@ -0,0 +1,91 @@
+package sharding
+
+import (
+	"testing"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestShardForDeterministic(t *testing.T) {
+	if ShardFor("team-a", 4) != ShardFor("team-a", 4) {
+		t.Errorf("shard assignment must be deterministic")
+	}
+}
+
+func TestShardForInRange(t *testing.T) {
+	namespaces := []string{"default", "kube-system", "team-a", "team-b", "prod", "staging"}
+	for _, ns := range namespaces {
+		shard := ShardFor(ns, 3)
+		if shard < 0 || shard >= 3 {
+			t.Errorf("shard %d for namespace %q out of range [0, 3)", shard, ns)
+		}
+	}
+}
+
+func TestShardForSpread(t *testing.T) {
+	seen := make(map[int]bool)
+	for i := 0; i < 100; i++ {
+		seen[ShardFor("namespace-"+string(rune('a'+i%26))+string(rune('a'+i/26)), 4)] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("expected namespaces to spread over multiple shards, got %d", len(seen))
+	}
+}
+
+func TestEqualSelector(t *testing.T) {
+	want := shardSelector(1)
+	if equalSelector(nil, want) {
+		t.Errorf("nil selector should not be equal")
+	}
+	if !equalSelector(shardSelector(1), want) {
+		t.Errorf("identical selectors should be equal")
+	}
+	if equalSelector(shardSelector(2), want) {
+		t.Errorf("different shard selectors should not be equal")
+	}
+	if equalSelector(unlabeledSelector(), want) {
+		t.Errorf("the unlabeled selector should not equal a shard selector")
+	}
+}
+
+func TestSelectorsPartitionNamespaces(t *testing.T) {
+	// a namespace must match exactly one of: the base configuration's
+	// unlabeled selector or a single shard's selector
+	unlabeled := unlabeledSelector().MatchExpressions[0]
+	if unlabeled.Operator != metav1.LabelSelectorOpDoesNotExist || unlabeled.Key != ShardLabel {
+		t.Errorf("base selector must match namespaces without a shard label, got %v", unlabeled)
+	}
+	shard := shardSelector(2).MatchExpressions[0]
+	if shard.Operator != metav1.LabelSelectorOpIn || len(shard.Values) != 1 || shard.Values[0] != "2" {
+		t.Errorf("shard selector must match exactly its own shard, got %v", shard)
+	}
+}
+
+func TestShardWebhooks(t *testing.T) {
+	base := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{{
+			Name: "validation.gatekeeper.sh",
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{
+				Service:  &admissionregistrationv1.ServiceReference{Name: "gatekeeper-webhook-service"},
+				CABundle: []byte("ca"),
+			},
+		}},
+	}
+	got := shardWebhooks(base, 1)
+	if len(got) != 1 {
+		t.Fatalf("got %d webhooks, want 1", len(got))
+	}
+	if got[0].ClientConfig.Service.Name != "gatekeeper-webhook-service-shard-1" {
+		t.Errorf("service = %q, want the shard's service", got[0].ClientConfig.Service.Name)
+	}
+	if string(got[0].ClientConfig.CABundle) != "ca" {
+		t.Errorf("the base CA bundle must carry over")
+	}
+	if !equalSelector(got[0].NamespaceSelector, shardSelector(1)) {
+		t.Errorf("namespaceSelector = %v, want the shard's selector", got[0].NamespaceSelector)
+	}
+	if base.Webhooks[0].NamespaceSelector != nil || base.Webhooks[0].ClientConfig.Service.Name != "gatekeeper-webhook-service" {
+		t.Errorf("the base configuration must not be mutated")
+	}
+}
@@ -0,0 +1,114 @@
+package simulation
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/httpguard"
+	"github.com/open-policy-agent/gatekeeper/pkg/logging"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("simulation").WithValues(logging.Process, "simulation")
+
+var (
+	enableSimulation = flag.Bool("enable-simulation-api", false, "(alpha) enable the policy simulation endpoint")
+	simulationAddr   = flag.String("simulation-addr", ":8894", "The address the simulation endpoint binds to.")
+	simulationToken  = flag.String("simulation-token", "", "if set, simulation requests must present this value as a bearer token")
+)
+
+// Enabled returns true when the simulation endpoint was requested via flag.
+func Enabled() bool {
+	return *enableSimulation
+}
+
+// Server serves simulation requests. It implements manager.Runnable so
+// it can be registered with the controller manager like the other
+// optional servers.
+type Server struct {
+	simulator *Simulator
+	addr      string
+	token     string
+}
+
+// NewServer returns a Server configured from the simulation flags.
+func NewServer(simulator *Simulator) *Server {
+	return &Server{
+		simulator: simulator,
+		addr:      *simulationAddr,
+		token:     *simulationToken,
+	}
+}
+
+// Start implements manager.Runnable.
+func (s *Server) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.Handle("/v1/simulate", httpguard.Wrap(http.HandlerFunc(s.handleSimulate)))
+	srv := &http.Server{Addr: s.addr, Handler: mux}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+// handleSimulate accepts an object on POST and responds with the
+// violations and would-be mutations real admission would produce.
+func (s *Server) handleSimulate(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.authorized(req) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	simReq := &Request{}
+	if err := json.NewDecoder(req.Body).Decode(simReq); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if simReq.Object == nil {
+		http.Error(w, "simulation request must include an object", http.StatusBadRequest)
+		return
+	}
+
+	result, err := s.simulator.Simulate(req.Context(), simReq)
+	if err != nil {
+		log.Error(err, "unable to simulate object",
+			logging.ResourceKind, simReq.Object.GetKind(),
+			logging.ResourceNamespace, simReq.Object.GetNamespace(),
+			logging.ResourceName, simReq.Object.GetName(),
+		)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		log.Error(err, "unable to encode simulation result")
+	}
+}
+
+func (s *Server) authorized(req *http.Request) bool {
+	if s.token == "" {
+		return true
+	}
+	presented := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(presented), []byte(s.token)) == 1
+}
@@ -0,0 +1,108 @@
+// Package simulation evaluates arbitrary objects against the currently
+// loaded policies without going through real admission. Internal tooling
+// can submit an object and learn the full set of violations and would-be
+// mutations before attempting a deploy.
+package simulation
+
+import (
+	"context"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/mutation"
+	"github.com/open-policy-agent/gatekeeper/pkg/opashard"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Request is a simulation request: the object under review plus an
+// optional namespace to evaluate it in. When the namespace is omitted
+// and the object is namespaced, the simulator looks the namespace up in
+// the cluster.
+type Request struct {
+	Object    *unstructured.Unstructured `json:"object"`
+	Namespace *corev1.Namespace          `json:"namespace,omitempty"`
+}
+
+// Violation is a single constraint violation found during simulation.
+type Violation struct {
+	Constraint        string `json:"constraint"`
+	ConstraintKind    string `json:"constraintKind"`
+	Message           string `json:"message"`
+	EnforcementAction string `json:"enforcementAction"`
+}
+
+// Result reports what admission would have done with the object.
+type Result struct {
+	Violations []Violation `json:"violations"`
+	// Denied is true when at least one violated constraint carries the
+	// deny enforcement action.
+	Denied bool `json:"denied"`
+	// Mutated is true when at least one mutator would have changed the
+	// object; MutatedObject then holds the object as it would be admitted.
+	Mutated       bool                       `json:"mutated"`
+	MutatedObject *unstructured.Unstructured `json:"mutatedObject,omitempty"`
+}
+
+// Simulator runs objects through mutation and constraint review using
+// the same machinery as the webhook, without persisting anything.
+type Simulator struct {
+	opa            opashard.Client
+	mutationSystem *mutation.System
+	reader         client.Reader
+}
+
+// New returns a Simulator backed by the given OPA client and mutation
+// system. The reader is used to resolve namespaces not supplied with the
+// request and may be nil.
+func New(opaClient opashard.Client, mutationSystem *mutation.System, reader client.Reader) *Simulator {
+	return &Simulator{
+		opa:            opaClient,
+		mutationSystem: mutationSystem,
+		reader:         reader,
+	}
+}
+
+// Simulate mutates a copy of the object, reviews the result against all
+// loaded constraints, and reports what admission would have done.
+func (s *Simulator) Simulate(ctx context.Context, req *Request) (*Result, error) {
+	obj := req.Object.DeepCopy()
+	ns := req.Namespace
+	if ns == nil && obj.GetNamespace() != "" && s.reader != nil {
+		fetched := &corev1.Namespace{}
+		if err := s.reader.Get(ctx, types.NamespacedName{Name: obj.GetNamespace()}, fetched); err != nil {
+			return nil, err
+		}
+		ns = fetched
+	}
+
+	result := &Result{Violations: []Violation{}}
+	if s.mutationSystem != nil {
+		mutated, err := s.mutationSystem.Mutate(obj, ns)
+		if err != nil {
+			return nil, err
+		}
+		if mutated {
+			result.Mutated = true
+			result.MutatedObject = obj
+		}
+	}
+
+	resp, err := s.opa.Review(ctx, target.AugmentedUnstructured{Object: *obj, Namespace: ns})
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range resp.Results() {
+		result.Violations = append(result.Violations, Violation{
+			Constraint:        r.Constraint.GetName(),
+			ConstraintKind:    r.Constraint.GetKind(),
+			Message:           r.Msg,
+			EnforcementAction: r.EnforcementAction,
+		})
+		if r.EnforcementAction == "deny" {
+			result.Denied = true
+		}
+	}
+	return result, nil
+}
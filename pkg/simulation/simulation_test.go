@@ -0,0 +1,98 @@
+package simulation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/client/drivers/local"
+	"github.com/open-policy-agent/gatekeeper/pkg/mutation"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func makeOpaClient() (*opa.Client, error) {
+	t := &target.K8sValidationTarget{}
+	driver := local.New(local.Tracing(false))
+	backend, err := opa.NewBackend(opa.Driver(driver))
+	if err != nil {
+		return nil, err
+	}
+	return backend.NewClient(opa.Targets(t))
+}
+
+func makePod(name, namespace string) *unstructured.Unstructured {
+	pod := &unstructured.Unstructured{}
+	pod.SetAPIVersion("v1")
+	pod.SetKind("Pod")
+	pod.SetName(name)
+	pod.SetNamespace(namespace)
+	return pod
+}
+
+func TestSimulateNoViolations(t *testing.T) {
+	opaClient, err := makeOpaClient()
+	if err != nil {
+		t.Fatalf("could not initialize OPA: %s", err)
+	}
+	simulator := New(opaClient, mutation.NewSystem(), nil)
+
+	result, err := simulator.Simulate(context.Background(), &Request{Object: makePod("busybox", "")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Denied {
+		t.Errorf("object with no constraints should not be denied")
+	}
+	if len(result.Violations) != 0 {
+		t.Errorf("expected no violations, got %d", len(result.Violations))
+	}
+	if result.Mutated || result.MutatedObject != nil {
+		t.Errorf("object with no mutators should not be mutated")
+	}
+}
+
+func TestHandleSimulateRejectsBadRequests(t *testing.T) {
+	opaClient, err := makeOpaClient()
+	if err != nil {
+		t.Fatalf("could not initialize OPA: %s", err)
+	}
+	server := &Server{simulator: New(opaClient, nil, nil)}
+
+	w := httptest.NewRecorder()
+	server.handleSimulate(w, httptest.NewRequest(http.MethodGet, "/v1/simulate", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected %d for GET, got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	server.handleSimulate(w, httptest.NewRequest(http.MethodPost, "/v1/simulate", strings.NewReader(`{}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected %d for request without object, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestHandleSimulateToken(t *testing.T) {
+	opaClient, err := makeOpaClient()
+	if err != nil {
+		t.Fatalf("could not initialize OPA: %s", err)
+	}
+	server := &Server{simulator: New(opaClient, nil, nil), token: "secret"}
+
+	w := httptest.NewRecorder()
+	server.handleSimulate(w, httptest.NewRequest(http.MethodPost, "/v1/simulate", strings.NewReader(`{}`)))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected %d without token, got %d", http.StatusUnauthorized, w.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/simulate", strings.NewReader(`{"object": {"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "busybox"}}}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	server.handleSimulate(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected %d with token, got %d", http.StatusOK, w.Code)
+	}
+}
@@ -0,0 +1,164 @@
+package spiffe
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testPKI struct {
+	ca    *x509.Certificate
+	caKey *rsa.PrivateKey
+	caPEM []byte
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating CA key: %s", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-spire-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("creating CA: %s", err)
+	}
+	ca, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parsing CA: %s", err)
+	}
+	return &testPKI{
+		ca:    ca,
+		caKey: key,
+		caPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+func (p *testPKI) issueSVID(t *testing.T, spiffeID string) (certPEM, keyPEM, certDER []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating SVID key: %s", err)
+	}
+	id, err := url.Parse(spiffeID)
+	if err != nil {
+		t.Fatalf("parsing SPIFFE ID: %s", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		URIs:         []*url.URL{id},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, p.ca, &key.PublicKey, p.caKey)
+	if err != nil {
+		t.Fatalf("creating SVID: %s", err)
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return certPEM, keyPEM, der
+}
+
+func writeSVIDDir(t *testing.T, pki *testPKI, spiffeID string) string {
+	t.Helper()
+	dir := t.TempDir()
+	certPEM, keyPEM, _ := pki.issueSVID(t, spiffeID)
+	for name, data := range map[string][]byte{
+		svidFile:   certPEM,
+		keyFile:    keyPEM,
+		bundleFile: pki.caPEM,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			t.Fatalf("writing %s: %s", name, err)
+		}
+	}
+	return dir
+}
+
+func setTrustDomain(t *testing.T, domain string) {
+	t.Helper()
+	old := *trustDomain
+	*trustDomain = domain
+	t.Cleanup(func() { *trustDomain = old })
+}
+
+func TestSourceLoadsSVID(t *testing.T) {
+	pki := newTestPKI(t)
+	s := &Source{dir: writeSVIDDir(t, pki, "spiffe://example.org/gatekeeper")}
+	id, err := s.SVID()
+	if err != nil {
+		t.Fatalf("loading SVID: %s", err)
+	}
+	if id != "spiffe://example.org/gatekeeper" {
+		t.Errorf("SVID = %q, want spiffe://example.org/gatekeeper", id)
+	}
+}
+
+func TestVerifyPeerAcceptsBundledIdentity(t *testing.T) {
+	setTrustDomain(t, "example.org")
+	pki := newTestPKI(t)
+	s := &Source{dir: writeSVIDDir(t, pki, "spiffe://example.org/gatekeeper")}
+	_, _, peerDER := pki.issueSVID(t, "spiffe://example.org/provider")
+	if err := s.verifyPeer([][]byte{peerDER}, nil); err != nil {
+		t.Errorf("peer in the trust domain should verify: %s", err)
+	}
+}
+
+func TestVerifyPeerRejectsForeignTrustDomain(t *testing.T) {
+	setTrustDomain(t, "example.org")
+	pki := newTestPKI(t)
+	s := &Source{dir: writeSVIDDir(t, pki, "spiffe://example.org/gatekeeper")}
+	_, _, peerDER := pki.issueSVID(t, "spiffe://evil.test/provider")
+	if err := s.verifyPeer([][]byte{peerDER}, nil); err == nil {
+		t.Errorf("peer outside the trust domain should be rejected")
+	}
+}
+
+func TestVerifyPeerRejectsUnchainedCert(t *testing.T) {
+	pki := newTestPKI(t)
+	other := newTestPKI(t)
+	s := &Source{dir: writeSVIDDir(t, pki, "spiffe://example.org/gatekeeper")}
+	_, _, peerDER := other.issueSVID(t, "spiffe://example.org/provider")
+	if err := s.verifyPeer([][]byte{peerDER}, nil); err == nil {
+		t.Errorf("peer signed by an unknown CA should be rejected")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	oldDir := *svidDir
+	t.Cleanup(func() { *svidDir = oldDir })
+
+	*svidDir = ""
+	setTrustDomain(t, "example.org")
+	if err := Validate(); err == nil {
+		t.Errorf("trust domain without an SVID dir should be rejected")
+	}
+
+	*svidDir = "/run/spire"
+	if err := Validate(); err != nil {
+		t.Errorf("valid flags should pass: %s", err)
+	}
+
+	setTrustDomain(t, "spiffe://example.org")
+	if err := Validate(); err == nil {
+		t.Errorf("a SPIFFE ID is not a valid trust domain")
+	}
+}
@@ -0,0 +1,203 @@
+// Package staticdeny evaluates a small, file-based list of deny rules
+// directly in the webhook, before and independently of the policy
+// engine. The rules are plain GVK and field matchers loaded once at
+// startup from a mounted file, so they keep working when templates fail
+// to compile, CRDs are unhealthy, or the engine itself misbehaves. They
+// are meant as a minimum security floor during control-plane incidents
+// (e.g. always block privileged pods), not as a policy language:
+// anything expressible here should also exist as a constraint. Static
+// denials are deliberately not subject to break-glass bypass.
+package staticdeny
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"sigs.k8s.io/yaml"
+)
+
+var rulesFile = flag.String("static-deny-rules-file", "", "(alpha) path to a YAML file of static deny rules evaluated in the webhook before the policy engine, as a security floor that survives engine or CRD outages")
+
+// Enabled returns true if a static deny rules file was configured.
+func Enabled() bool {
+	return *rulesFile != ""
+}
+
+// File returns the configured static deny rules file.
+func File() string {
+	return *rulesFile
+}
+
+// ruleFile is the on-disk format.
+type ruleFile struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Rule denies objects of one kind, optionally narrowed by field
+// matchers. An empty group or version matches any; all field matchers
+// must match for the rule to fire.
+type Rule struct {
+	// Name identifies the rule in denial messages.
+	Name string `json:"name"`
+	// Message is an optional operator-facing explanation.
+	Message string `json:"message,omitempty"`
+	// Group, Version, and Kind select the objects the rule applies to.
+	// Kind is required; empty Group or Version matches any.
+	Group   string `json:"group,omitempty"`
+	Version string `json:"version,omitempty"`
+	Kind    string `json:"kind"`
+	// Fields further narrow the rule to objects whose fields match.
+	Fields []FieldMatch `json:"fields,omitempty"`
+}
+
+// FieldMatch matches one field of the reviewed object. Path is
+// dot-separated; a "*" segment descends into every element of a list or
+// every value of a map.
+type FieldMatch struct {
+	Path string `json:"path"`
+	// Values are the string renderings the field may take for the
+	// matcher to fire; empty means the field merely has to be present.
+	Values []string `json:"values,omitempty"`
+}
+
+// Rules is a loaded, validated rule list.
+type Rules struct {
+	rules []Rule
+}
+
+// Load reads and validates the rule file at path.
+func Load(path string) (*Rules, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rf := &ruleFile{}
+	if err := yaml.UnmarshalStrict(raw, rf); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for i, rule := range rf.Rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("%s: rule %d is missing a name", path, i)
+		}
+		if rule.Kind == "" {
+			return nil, fmt.Errorf("%s: rule %q is missing a kind", path, rule.Name)
+		}
+		for _, field := range rule.Fields {
+			if field.Path == "" {
+				return nil, fmt.Errorf("%s: rule %q has a field matcher without a path", path, rule.Name)
+			}
+		}
+	}
+	return &Rules{rules: rf.Rules}, nil
+}
+
+// Count returns the number of loaded rules.
+func (r *Rules) Count() int {
+	return len(r.rules)
+}
+
+// Denied reports whether a static rule denies the request, with a
+// message suitable for the admission response. Unparseable objects are
+// not denied here; the regular review path surfaces those.
+func (r *Rules) Denied(req *admissionv1.AdmissionRequest) (string, bool) {
+	if req.Object.Raw == nil {
+		return "", false
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(req.Object.Raw, &obj); err != nil {
+		return "", false
+	}
+	for _, rule := range r.rules {
+		if !rule.matchesKind(req) {
+			continue
+		}
+		if !rule.matchesFields(obj) {
+			continue
+		}
+		msg := rule.Message
+		if msg == "" {
+			msg = fmt.Sprintf("%s is blocked by a static deny rule", req.Kind.Kind)
+		}
+		return fmt.Sprintf("[denied by static rule %s] %s", rule.Name, msg), true
+	}
+	return "", false
+}
+
+func (rule Rule) matchesKind(req *admissionv1.AdmissionRequest) bool {
+	if rule.Kind != req.Kind.Kind {
+		return false
+	}
+	if rule.Group != "" && rule.Group != req.Kind.Group {
+		return false
+	}
+	if rule.Version != "" && rule.Version != req.Kind.Version {
+		return false
+	}
+	return true
+}
+
+func (rule Rule) matchesFields(obj map[string]interface{}) bool {
+	for _, field := range rule.Fields {
+		if !field.matches(obj) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f FieldMatch) matches(obj map[string]interface{}) bool {
+	values := fieldValues(obj, strings.Split(f.Path, "."))
+	if len(values) == 0 {
+		return false
+	}
+	if len(f.Values) == 0 {
+		return true
+	}
+	for _, value := range values {
+		rendered := fmt.Sprintf("%v", value)
+		for _, want := range f.Values {
+			if rendered == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fieldValues walks the object along the path segments and returns
+// every value reached; "*" fans out over list elements and map values.
+func fieldValues(node interface{}, path []string) []interface{} {
+	if len(path) == 0 {
+		return []interface{}{node}
+	}
+	segment, rest := path[0], path[1:]
+	switch typed := node.(type) {
+	case map[string]interface{}:
+		if segment == "*" {
+			var out []interface{}
+			for _, child := range typed {
+				out = append(out, fieldValues(child, rest)...)
+			}
+			return out
+		}
+		child, ok := typed[segment]
+		if !ok {
+			return nil
+		}
+		return fieldValues(child, rest)
+	case []interface{}:
+		if segment != "*" {
+			return nil
+		}
+		var out []interface{}
+		for _, child := range typed {
+			out = append(out, fieldValues(child, rest)...)
+		}
+		return out
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package staticdeny
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const privilegedRules = `
+rules:
+  - name: no-privileged-pods
+    message: privileged containers are not allowed
+    kind: Pod
+    version: v1
+    fields:
+      - path: spec.containers.*.securityContext.privileged
+        values: ["true"]
+  - name: no-node-port
+    kind: Service
+    fields:
+      - path: spec.type
+        values: ["NodePort"]
+`
+
+func loadRules(t *testing.T, content string) *Rules {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rules.yaml")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := Load(path)
+	if err != nil {
+		t.Fatalf("load failed: %s", err)
+	}
+	return rules
+}
+
+func podRequest(raw string) *admissionv1.AdmissionRequest {
+	return &admissionv1.AdmissionRequest{
+		Kind:   metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+		Object: runtime.RawExtension{Raw: []byte(raw)},
+	}
+}
+
+func TestDeniedPrivilegedPod(t *testing.T) {
+	rules := loadRules(t, privilegedRules)
+	req := podRequest(`{"spec": {"containers": [{"name": "a"}, {"name": "b", "securityContext": {"privileged": true}}]}}`)
+	msg, denied := rules.Denied(req)
+	if !denied {
+		t.Fatal("expected the privileged pod to be denied")
+	}
+	if msg != "[denied by static rule no-privileged-pods] privileged containers are not allowed" {
+		t.Errorf("unexpected message: %s", msg)
+	}
+}
+
+func TestAllowedUnprivilegedPod(t *testing.T) {
+	rules := loadRules(t, privilegedRules)
+	req := podRequest(`{"spec": {"containers": [{"name": "a", "securityContext": {"privileged": false}}]}}`)
+	if msg, denied := rules.Denied(req); denied {
+		t.Fatalf("unprivileged pod should not be denied: %s", msg)
+	}
+}
+
+func TestKindMismatchIgnored(t *testing.T) {
+	rules := loadRules(t, privilegedRules)
+	req := &admissionv1.AdmissionRequest{
+		Kind:   metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+		Object: runtime.RawExtension{Raw: []byte(`{"spec": {"type": "NodePort"}}`)},
+	}
+	if msg, denied := rules.Denied(req); denied {
+		t.Fatalf("rule should only match its own kind: %s", msg)
+	}
+}
+
+func TestPresenceMatchAndDefaultMessage(t *testing.T) {
+	rules := loadRules(t, `
+rules:
+  - name: no-host-network
+    kind: Pod
+    fields:
+      - path: spec.hostNetwork
+`)
+	req := podRequest(`{"spec": {"hostNetwork": false}}`)
+	msg, denied := rules.Denied(req)
+	if !denied {
+		t.Fatal("expected a presence matcher to fire on a set field")
+	}
+	if msg != "[denied by static rule no-host-network] Pod is blocked by a static deny rule" {
+		t.Errorf("unexpected message: %s", msg)
+	}
+}
+
+func TestLoadRejectsInvalidRules(t *testing.T) {
+	tc := []struct {
+		name    string
+		content string
+	}{
+		{"missing name", "rules:\n  - kind: Pod"},
+		{"missing kind", "rules:\n  - name: a"},
+		{"field without path", "rules:\n  - name: a\n    kind: Pod\n    fields:\n      - values: [\"true\"]"},
+		{"unknown key", "rules:\n  - name: a\n    kind: Pod\n    matchers: []"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "rules.yaml")
+			if err := ioutil.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Load(path); err == nil {
+				t.Fatal("expected a load error")
+			}
+		})
+	}
+}
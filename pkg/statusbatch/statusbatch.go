@@ -0,0 +1,154 @@
+// Package statusbatch coalesces constraint status writes into periodic
+// batched updates. Audit reporting and status aggregation both rewrite
+// constraint status; on busy clusters the resulting stream of small
+// updates loads the API server and provokes conflicts. When batching is
+// enabled, writers enqueue the desired status and a single flush loop
+// applies the latest state per constraint at a fixed interval, retrying
+// on conflict against a fresh read.
+package statusbatch
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("status-batch")
+
+var (
+	batchStatusUpdates   = flag.Bool("batch-status-updates", false, "(alpha) coalesce constraint status writes into periodic batched updates")
+	statusUpdateInterval = flag.Duration("status-update-interval", 10*time.Second, "interval between batched constraint status flushes. Only used with --batch-status-updates")
+)
+
+// Enabled returns true when status batching was requested via flags.
+func Enabled() bool {
+	return *batchStatusUpdates
+}
+
+var (
+	defaultBatcher *Batcher
+	defaultMux     sync.Mutex
+)
+
+// Enqueue hands a constraint with its desired status to the default
+// batcher. It returns false when batching is disabled or no batcher is
+// running, in which case the caller should write the status directly.
+func Enqueue(obj *unstructured.Unstructured) bool {
+	if !Enabled() {
+		return false
+	}
+	defaultMux.Lock()
+	b := defaultBatcher
+	defaultMux.Unlock()
+	if b == nil {
+		return false
+	}
+	b.enqueue(obj)
+	return true
+}
+
+// Batcher accumulates desired constraint statuses and flushes them
+// periodically. It implements manager.Runnable.
+type Batcher struct {
+	client   client.Client
+	interval time.Duration
+
+	mux     sync.Mutex
+	pending map[string]*unstructured.Unstructured
+}
+
+// New returns a Batcher configured from the batching flags and installs
+// it as the default target for Enqueue.
+func New(c client.Client) *Batcher {
+	b := &Batcher{
+		client:   c,
+		interval: *statusUpdateInterval,
+		pending:  make(map[string]*unstructured.Unstructured),
+	}
+	defaultMux.Lock()
+	defaultBatcher = b
+	defaultMux.Unlock()
+	return b
+}
+
+func pendingKey(obj *unstructured.Unstructured) string {
+	gvk := obj.GroupVersionKind()
+	return fmt.Sprintf("%s/%s/%s", gvk.GroupVersion(), gvk.Kind, obj.GetName())
+}
+
+func (b *Batcher) enqueue(obj *unstructured.Unstructured) {
+	b.mux.Lock()
+	b.pending[pendingKey(obj)] = obj.DeepCopy()
+	b.mux.Unlock()
+}
+
+// Start implements manager.Runnable.
+func (b *Batcher) Start(ctx context.Context) error {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			b.flush(ctx)
+		case <-ctx.Done():
+			// flush what is left so shutdown does not drop status
+			b.flush(context.Background())
+			return nil
+		}
+	}
+}
+
+// flush writes every pending status, latest state winning per
+// constraint.
+func (b *Batcher) flush(ctx context.Context) {
+	b.mux.Lock()
+	batch := b.pending
+	b.pending = make(map[string]*unstructured.Unstructured)
+	b.mux.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+	log.Info("flushing batched constraint status updates", "count", len(batch))
+	for _, desired := range batch {
+		if err := b.writeStatus(ctx, desired); err != nil {
+			log.Error(err, "unable to write batched constraint status", "kind", desired.GetKind(), "name", desired.GetName())
+		}
+	}
+}
+
+// writeStatus applies the desired status, re-reading the constraint on
+// conflict.
+func (b *Batcher) writeStatus(ctx context.Context, desired *unstructured.Unstructured) error {
+	instance := desired
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		err := b.client.Status().Update(ctx, instance)
+		if err == nil {
+			return nil
+		}
+
+		latest := &unstructured.Unstructured{}
+		latest.SetGroupVersionKind(desired.GroupVersionKind())
+		getErr := b.client.Get(ctx, types.NamespacedName{Namespace: desired.GetNamespace(), Name: desired.GetName()}, latest)
+		if getErr != nil {
+			return err
+		}
+		if status, found, _ := unstructured.NestedMap(desired.Object, "status"); found {
+			if setErr := unstructured.SetNestedMap(latest.Object, status, "status"); setErr != nil {
+				return err
+			}
+		} else {
+			unstructured.RemoveNestedField(latest.Object, "status")
+		}
+		instance = latest
+		return err
+	})
+}
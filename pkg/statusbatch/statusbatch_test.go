@@ -0,0 +1,124 @@
+package statusbatch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	testclients "github.com/open-policy-agent/gatekeeper/test/clients"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8schema "k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// recordingClient records status updates and can fail the first n of
+// them with a conflict.
+type recordingClient struct {
+	testclients.NoopClient
+	updated   []*unstructured.Unstructured
+	conflicts int
+}
+
+func (c *recordingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		u.SetName(key.Name)
+		return nil
+	}
+	return k8serrors.NewNotFound(k8schema.GroupResource{Resource: "constraints"}, key.Name)
+}
+
+func (c *recordingClient) Status() client.StatusWriter {
+	return &recordingStatusWriter{client: c}
+}
+
+type recordingStatusWriter struct {
+	client *recordingClient
+}
+
+func (w *recordingStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if w.client.conflicts > 0 {
+		w.client.conflicts--
+		return k8serrors.NewConflict(k8schema.GroupResource{Resource: "constraints"}, obj.GetName(), nil)
+	}
+	w.client.updated = append(w.client.updated, obj.(*unstructured.Unstructured).DeepCopy())
+	return nil
+}
+
+func (w *recordingStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return nil
+}
+
+func makeBatcher(c client.Client) *Batcher {
+	return &Batcher{
+		client:   c,
+		interval: time.Second,
+		pending:  make(map[string]*unstructured.Unstructured),
+	}
+}
+
+func makeConstraint(name string, violations int64) *unstructured.Unstructured {
+	cstr := &unstructured.Unstructured{}
+	cstr.SetAPIVersion("constraints.gatekeeper.sh/v1beta1")
+	cstr.SetKind("DenyAll")
+	cstr.SetName(name)
+	if err := unstructured.SetNestedField(cstr.Object, violations, "status", "totalViolations"); err != nil {
+		panic(err)
+	}
+	return cstr
+}
+
+func TestEnqueueRequiresEnabledFlag(t *testing.T) {
+	if Enqueue(makeConstraint("a", 1)) {
+		t.Errorf("enqueue should report false while batching is disabled")
+	}
+}
+
+func TestFlushCoalescesPerConstraint(t *testing.T) {
+	rc := &recordingClient{}
+	b := makeBatcher(rc)
+
+	b.enqueue(makeConstraint("a", 1))
+	b.enqueue(makeConstraint("a", 2))
+	b.flush(context.Background())
+
+	if len(rc.updated) != 1 {
+		t.Fatalf("expected 1 coalesced update, got %d", len(rc.updated))
+	}
+	got, _, _ := unstructured.NestedInt64(rc.updated[0].Object, "status", "totalViolations")
+	if got != 2 {
+		t.Errorf("latest enqueued status should win, got totalViolations %d", got)
+	}
+	if len(b.pending) != 0 {
+		t.Errorf("flush should drain the pending queue")
+	}
+}
+
+func TestFlushWritesEveryConstraint(t *testing.T) {
+	rc := &recordingClient{}
+	b := makeBatcher(rc)
+
+	b.enqueue(makeConstraint("a", 1))
+	b.enqueue(makeConstraint("b", 1))
+	b.flush(context.Background())
+
+	if len(rc.updated) != 2 {
+		t.Errorf("expected 2 updates, got %d", len(rc.updated))
+	}
+}
+
+func TestWriteStatusRetriesOnConflict(t *testing.T) {
+	rc := &recordingClient{conflicts: 1}
+	b := makeBatcher(rc)
+
+	if err := b.writeStatus(context.Background(), makeConstraint("a", 3)); err != nil {
+		t.Fatalf("write should succeed after conflict retry: %s", err)
+	}
+	if len(rc.updated) != 1 {
+		t.Fatalf("expected 1 successful update after retry, got %d", len(rc.updated))
+	}
+	got, _, _ := unstructured.NestedInt64(rc.updated[0].Object, "status", "totalViolations")
+	if got != 3 {
+		t.Errorf("desired status should be re-applied on retry, got totalViolations %d", got)
+	}
+}
@@ -0,0 +1,50 @@
+// Package statusshard splits status aggregation across multiple pods
+// running the status operation. Constraints and templates are assigned
+// to workers by name hash, so each status pod only merges the per-pod
+// status objects for its own share and bookkeeping for thousands of
+// constraints no longer rides on a single aggregator.
+package statusshard
+
+import (
+	"flag"
+	"fmt"
+	"hash/fnv"
+)
+
+var (
+	statusShardCount = flag.Int("status-shard-count", 0, "(alpha) total number of status pods sharing status aggregation; 0 disables sharding")
+	statusShardIndex = flag.Int("status-shard-index", 0, "(alpha) which shard this status pod serves, in [0, status-shard-count)")
+)
+
+// Enabled returns true when status sharding was requested via flags.
+func Enabled() bool {
+	return *statusShardCount > 1
+}
+
+// Validate checks the shard flags for consistency.
+func Validate() error {
+	if !Enabled() {
+		return nil
+	}
+	if *statusShardIndex < 0 || *statusShardIndex >= *statusShardCount {
+		return fmt.Errorf("status-shard-index %d must be in [0, %d)", *statusShardIndex, *statusShardCount)
+	}
+	return nil
+}
+
+// ShardFor returns the shard an object belongs to by name hash.
+func ShardFor(name string, count int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	return int(h.Sum32() % uint32(count))
+}
+
+// Mine returns true when this pod is responsible for aggregating status
+// for the named object. With sharding disabled every pod is responsible
+// for everything.
+func Mine(name string) bool {
+	if !Enabled() {
+		return true
+	}
+	return ShardFor(name, *statusShardCount) == *statusShardIndex
+}
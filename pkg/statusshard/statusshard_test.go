@@ -0,0 +1,60 @@
+package statusshard
+
+import "testing"
+
+func TestShardForDeterministic(t *testing.T) {
+	if ShardFor("constraint-a", 4) != ShardFor("constraint-a", 4) {
+		t.Errorf("shard assignment must be deterministic")
+	}
+}
+
+func TestShardForInRange(t *testing.T) {
+	names := []string{"a", "b", "require-labels", "deny-privileged", "x-y-z"}
+	for _, name := range names {
+		shard := ShardFor(name, 3)
+		if shard < 0 || shard >= 3 {
+			t.Errorf("shard %d for %q out of range [0, 3)", shard, name)
+		}
+	}
+}
+
+func TestMineDisabledAcceptsEverything(t *testing.T) {
+	if !Mine("anything") {
+		t.Errorf("with sharding disabled every pod owns every object")
+	}
+}
+
+func TestMineEnabled(t *testing.T) {
+	oldCount, oldIndex := *statusShardCount, *statusShardIndex
+	defer func() { *statusShardCount, *statusShardIndex = oldCount, oldIndex }()
+	*statusShardCount = 2
+
+	name := "require-labels"
+	*statusShardIndex = ShardFor(name, 2)
+	if !Mine(name) {
+		t.Errorf("pod serving the object's shard should own it")
+	}
+	*statusShardIndex = 1 - ShardFor(name, 2)
+	if Mine(name) {
+		t.Errorf("pod serving a different shard should not own it")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	oldCount, oldIndex := *statusShardCount, *statusShardIndex
+	defer func() { *statusShardCount, *statusShardIndex = oldCount, oldIndex }()
+
+	*statusShardCount = 0
+	if err := Validate(); err != nil {
+		t.Errorf("disabled sharding should validate: %s", err)
+	}
+	*statusShardCount = 3
+	*statusShardIndex = 2
+	if err := Validate(); err != nil {
+		t.Errorf("in-range index should validate: %s", err)
+	}
+	*statusShardIndex = 3
+	if err := Validate(); err == nil {
+		t.Errorf("out-of-range index should not validate")
+	}
+}
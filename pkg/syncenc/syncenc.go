@@ -0,0 +1,183 @@
+// Package syncenc encrypts the data fields of Secrets (and other
+// configured sensitive kinds) before they enter the policy engine's sync
+// store, limiting what a heap dump or debug endpoint such as Dump can
+// expose. Values are sealed with AES-GCM under an ephemeral per-process
+// key, so metadata stays referenceable by policies while the payloads at
+// rest are ciphertext. Referential policies never see the plaintext:
+// templates whose Rego reads the data fields of inventoried objects are
+// rejected outright, so a policy that would otherwise silently evaluate
+// against ciphertext fails loudly when it is written.
+package syncenc
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/open-policy-agent/frameworks/constraint/pkg/core/templates"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/types"
+	"github.com/open-policy-agent/gatekeeper/pkg/opashard"
+	"github.com/open-policy-agent/opa/ast"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// sealedPrefix marks a sealed value so decryption and idempotence checks
+// can recognize one.
+const sealedPrefix = "gatekeeper-sealed:"
+
+// dataFields are the top-level fields whose values are sealed on
+// sensitive kinds.
+var dataFields = []string{"data", "stringData", "binaryData"}
+
+var (
+	encryptSyncedSecrets = flag.Bool("encrypt-synced-secrets", false, "(alpha) encrypt the data fields of sensitive synced resources at rest in the policy engine's sync store; policies can still match on metadata, but the payload values are ciphertext")
+	encryptedSyncKinds   = flag.String("encrypted-sync-kinds", "Secret", "comma-separated list of kinds whose data fields are encrypted in the sync store; only used with --encrypt-synced-secrets")
+)
+
+// Enabled returns true if sync store encryption was requested via flags.
+func Enabled() bool {
+	return *encryptSyncedSecrets
+}
+
+// Client wraps an OPA client and seals the data fields of sensitive
+// kinds on their way into the sync store. It implements opashard.Client.
+type Client struct {
+	opashard.Client
+
+	aead  cipher.AEAD
+	kinds map[string]bool
+}
+
+var _ opashard.Client = &Client{}
+
+// New wraps inner with sync store encryption under a fresh ephemeral
+// key; sealed values do not survive a restart, matching the lifetime of
+// the in-memory store they protect.
+func New(inner opashard.Client) (*Client, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generating sync store encryption key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	kinds := make(map[string]bool)
+	for _, k := range strings.Split(*encryptedSyncKinds, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			kinds[k] = true
+		}
+	}
+	return &Client{Client: inner, aead: aead, kinds: kinds}, nil
+}
+
+// AddTemplate rejects templates whose Rego reads the data fields of
+// inventoried objects. With encryption on, those fields hold ciphertext,
+// so such a policy cannot evaluate meaningfully; rejecting it at write
+// time beats silently comparing against sealed values.
+func (c *Client) AddTemplate(ctx context.Context, templ *templates.ConstraintTemplate) (*types.Responses, error) {
+	for _, t := range templ.Spec.Targets {
+		sources := append([]string{t.Rego}, t.Libs...)
+		for _, source := range sources {
+			field, err := sealedFieldRef(source)
+			if err != nil {
+				// leave unparseable Rego to the engine's own validation
+				continue
+			}
+			if field != "" {
+				return nil, fmt.Errorf("template %s references the %q field of synced objects, which is encrypted under --encrypt-synced-secrets; policies cannot evaluate sealed values", templ.GetName(), field)
+			}
+		}
+	}
+	return c.Client.AddTemplate(ctx, templ)
+}
+
+// sealedFieldRef reports which sealed data field, if any, the Rego
+// source reads from the sync store. A module is flagged when it
+// references data.inventory and some non-input reference selects one of
+// the sealed fields; the check is deliberately conservative, since an
+// inventoried object reached through an intermediate variable cannot be
+// traced to a kind statically.
+func sealedFieldRef(source string) (string, error) {
+	module, err := ast.ParseModule("", source)
+	if err != nil {
+		return "", err
+	}
+	inventory := false
+	field := ""
+	ast.WalkRefs(module, func(ref ast.Ref) bool {
+		if ref.HasPrefix(ast.MustParseRef("data.inventory")) {
+			inventory = true
+		}
+		if ref[0].Equal(ast.InputRootDocument) {
+			return false
+		}
+		for _, term := range ref[1:] {
+			s, ok := term.Value.(ast.String)
+			if !ok {
+				continue
+			}
+			for _, f := range dataFields {
+				if string(s) == f {
+					field = f
+				}
+			}
+		}
+		return false
+	})
+	if !inventory {
+		return "", nil
+	}
+	return field, nil
+}
+
+// AddData seals the data fields of sensitive kinds before handing the
+// object to the engine.
+func (c *Client) AddData(ctx context.Context, data interface{}) (*types.Responses, error) {
+	if u, ok := data.(*unstructured.Unstructured); ok && c.kinds[u.GetKind()] {
+		data = c.seal(u)
+	}
+	return c.Client.AddData(ctx, data)
+}
+
+// seal returns a copy of obj with the values of its data fields
+// encrypted.
+func (c *Client) seal(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	out := obj.DeepCopy()
+	for _, field := range dataFields {
+		values, found, err := unstructured.NestedMap(out.Object, field)
+		if err != nil || !found {
+			continue
+		}
+		for k, v := range values {
+			s, ok := v.(string)
+			if !ok || strings.HasPrefix(s, sealedPrefix) {
+				continue
+			}
+			values[k] = c.sealValue(s)
+		}
+		_ = unstructured.SetNestedMap(out.Object, values, field)
+	}
+	return out
+}
+
+// sealValue encrypts one value into the sealed wire form.
+func (c *Client) sealValue(plaintext string) string {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		// rand.Read only fails when the system entropy source is broken;
+		// there is no safe fallback for key material.
+		panic(fmt.Sprintf("reading nonce for sync store encryption: %v", err))
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed)
+}
@@ -0,0 +1,169 @@
+package syncenc
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/client/drivers/local"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/core/templates"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func makeOpaClient() (*opa.Client, error) {
+	t := &target.K8sValidationTarget{}
+	driver := local.New(local.Tracing(false))
+	backend, err := opa.NewBackend(opa.Driver(driver))
+	if err != nil {
+		return nil, err
+	}
+	return backend.NewClient(opa.Targets(t))
+}
+
+func makeClient(t *testing.T) *Client {
+	inner, err := makeOpaClient()
+	if err != nil {
+		t.Fatalf("could not initialize OPA: %s", err)
+	}
+	c, err := New(inner)
+	if err != nil {
+		t.Fatalf("could not initialize encrypting client: %s", err)
+	}
+	return c
+}
+
+func makeSecret(name string) *unstructured.Unstructured {
+	secret := &unstructured.Unstructured{}
+	secret.SetAPIVersion("v1")
+	secret.SetKind("Secret")
+	secret.SetName(name)
+	secret.SetNamespace("default")
+	secret.Object["data"] = map[string]interface{}{
+		"password": "aHVudGVyMg==",
+	}
+	return secret
+}
+
+func TestSealRoundTrip(t *testing.T) {
+	c := makeClient(t)
+	sealed := c.seal(makeSecret("credentials"))
+
+	got, _, err := unstructured.NestedString(sealed.Object, "data", "password")
+	if err != nil {
+		t.Fatalf("reading sealed value: %s", err)
+	}
+	if !strings.HasPrefix(got, sealedPrefix) {
+		t.Fatalf("value should be sealed, got %q", got)
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(got, sealedPrefix))
+	if err != nil {
+		t.Fatalf("decoding sealed value: %s", err)
+	}
+	plaintext, err := c.aead.Open(nil, raw[:c.aead.NonceSize()], raw[c.aead.NonceSize():], nil)
+	if err != nil {
+		t.Fatalf("opening sealed value: %s", err)
+	}
+	if string(plaintext) != "aHVudGVyMg==" {
+		t.Errorf("sealed value decrypted to %q, want the original value", plaintext)
+	}
+}
+
+func TestSealLeavesMetadataAndOriginal(t *testing.T) {
+	c := makeClient(t)
+	secret := makeSecret("credentials")
+	sealed := c.seal(secret)
+
+	if sealed.GetName() != "credentials" || sealed.GetNamespace() != "default" {
+		t.Errorf("metadata should remain readable")
+	}
+	original, _, _ := unstructured.NestedString(secret.Object, "data", "password")
+	if original != "aHVudGVyMg==" {
+		t.Errorf("sealing must not mutate the caller's object, got %q", original)
+	}
+}
+
+func TestSealIsIdempotent(t *testing.T) {
+	c := makeClient(t)
+	once := c.seal(makeSecret("credentials"))
+	twice := c.seal(once)
+	v1, _, _ := unstructured.NestedString(once.Object, "data", "password")
+	v2, _, _ := unstructured.NestedString(twice.Object, "data", "password")
+	if v1 != v2 {
+		t.Errorf("sealing a sealed object should not re-encrypt values")
+	}
+}
+
+func TestAddDataSealsOnlyConfiguredKinds(t *testing.T) {
+	c := makeClient(t)
+	if _, err := c.AddData(context.Background(), makeSecret("credentials")); err != nil {
+		t.Fatalf("adding secret: %s", err)
+	}
+
+	pod := &unstructured.Unstructured{}
+	pod.SetAPIVersion("v1")
+	pod.SetKind("Pod")
+	pod.SetName("busybox")
+	if _, err := c.AddData(context.Background(), pod); err != nil {
+		t.Fatalf("adding pod: %s", err)
+	}
+}
+
+func makeTemplate(t *testing.T, rego string) *templates.ConstraintTemplate {
+	t.Helper()
+	templ := &templates.ConstraintTemplate{}
+	templ.SetName("k8stesttemplate")
+	templ.Spec.CRD.Spec.Names.Kind = "K8sTestTemplate"
+	templ.Spec.Targets = []templates.Target{{
+		Target: "admission.k8s.gatekeeper.sh",
+		Rego:   rego,
+	}}
+	return templ
+}
+
+func TestAddTemplateRejectsSealedFieldReaders(t *testing.T) {
+	c := makeClient(t)
+	reader := makeTemplate(t, `
+package k8stesttemplate
+
+violation[{"msg": msg}] {
+	secret := data.inventory.namespace[ns]["v1"]["Secret"][name]
+	secret.data["password"] == "hunter2"
+	msg := "weak password"
+}
+`)
+	_, err := c.AddTemplate(context.Background(), reader)
+	if err == nil {
+		t.Fatalf("templates reading sealed data fields should be rejected")
+	}
+	if !strings.Contains(err.Error(), "--encrypt-synced-secrets") {
+		t.Errorf("the rejection should name the flag, got: %s", err)
+	}
+
+	metadataOnly := makeTemplate(t, `
+package k8stesttemplate
+
+violation[{"msg": msg}] {
+	secret := data.inventory.namespace[ns]["v1"]["Secret"][name]
+	secret.metadata.name == "forbidden"
+	msg := "forbidden secret"
+}
+`)
+	if _, err := c.AddTemplate(context.Background(), metadataOnly); err != nil {
+		t.Errorf("templates reading only metadata should be accepted, got: %s", err)
+	}
+
+	local := makeTemplate(t, `
+package k8stesttemplate
+
+violation[{"msg": msg}] {
+	input.review.object.data["password"]
+	msg := "secrets may not carry passwords"
+}
+`)
+	if _, err := c.AddTemplate(context.Background(), local); err != nil {
+		t.Errorf("templates reading only the reviewed object should be accepted, got: %s", err)
+	}
+}
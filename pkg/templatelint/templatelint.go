@@ -0,0 +1,200 @@
+// Package templatelint statically checks the Rego carried by
+// ConstraintTemplates at admission time, so broken or sloppy policies
+// are rejected with actionable errors instead of surfacing later as a
+// template status error. Beyond compilation it applies the strict
+// checks `opa check --strict` performs: deprecated builtin detection
+// and unused variable analysis.
+package templatelint
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/open-policy-agent/frameworks/constraint/pkg/core/templates"
+	"github.com/open-policy-agent/opa/ast"
+)
+
+var lintTemplates = flag.Bool("lint-templates", false, "(alpha) reject ConstraintTemplates whose Rego fails compilation or strict lint checks at admission time")
+
+// Enabled returns true if template linting was requested via flags.
+func Enabled() bool {
+	return *lintTemplates
+}
+
+// deprecatedBuiltins are builtins kept for backwards compatibility that
+// strict checking rejects, with their replacements.
+var deprecatedBuiltins = map[string]string{
+	"any":              "use `count` with a comprehension instead",
+	"all":              "use `count` with a comprehension instead",
+	"re_match":         "use `regex.match` instead",
+	"net.cidr_overlap": "use `net.cidr_contains` instead",
+	"set_diff":         "use the `-` operator instead",
+	"cast_array":       "use explicit conversion or type checks instead",
+	"cast_set":         "use explicit conversion or type checks instead",
+	"cast_string":      "use explicit conversion or type checks instead",
+	"cast_boolean":     "use explicit conversion or type checks instead",
+	"cast_null":        "use explicit conversion or type checks instead",
+	"cast_object":      "use explicit conversion or type checks instead",
+}
+
+// Issue is one finding against a template's Rego.
+type Issue struct {
+	// Code classifies the finding: rego_compile_error,
+	// rego_deprecated_builtin, or rego_unused_variable.
+	Code string
+	// Target is the target whose source produced the finding.
+	Target string
+	// Location is the file:row position within the target's source.
+	Location string
+	Message  string
+}
+
+func (i Issue) String() string {
+	if i.Location != "" {
+		return fmt.Sprintf("[%s] %s %s: %s", i.Code, i.Target, i.Location, i.Message)
+	}
+	return fmt.Sprintf("[%s] %s: %s", i.Code, i.Target, i.Message)
+}
+
+// LintTemplate checks the Rego of each target of the template and
+// returns all findings. An empty result means the template is clean.
+func LintTemplate(ct *templates.ConstraintTemplate) []Issue {
+	var issues []Issue
+	for _, target := range ct.Spec.Targets {
+		sources := map[string]string{target.Target: target.Rego}
+		for i, lib := range target.Libs {
+			sources[fmt.Sprintf("%s-lib-%d", target.Target, i)] = lib
+		}
+		issues = append(issues, lintSources(target.Target, sources)...)
+	}
+	return issues
+}
+
+// AsError folds the issues into one error, one finding per line, for
+// the admission response.
+func AsError(issues []Issue) error {
+	lines := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		lines = append(lines, issue.String())
+	}
+	return fmt.Errorf("template rego failed linting:\n%s", strings.Join(lines, "\n"))
+}
+
+func lintSources(targetName string, sources map[string]string) []Issue {
+	parsed := map[string]*ast.Module{}
+	var issues []Issue
+	for name, source := range sources {
+		module, err := ast.ParseModule(name, source)
+		if err != nil {
+			issues = append(issues, compileIssues(targetName, err)...)
+			continue
+		}
+		parsed[name] = module
+	}
+	if len(issues) > 0 {
+		return issues
+	}
+
+	compiler := ast.NewCompiler()
+	compiler.Compile(parsed)
+	if compiler.Failed() {
+		return append(issues, compileIssues(targetName, compiler.Errors)...)
+	}
+
+	for _, module := range parsed {
+		ast.WalkRules(module, func(rule *ast.Rule) bool {
+			issues = append(issues, checkDeprecatedBuiltins(targetName, rule)...)
+			issues = append(issues, checkUnusedVars(targetName, rule)...)
+			return false
+		})
+	}
+	return issues
+}
+
+func compileIssues(targetName string, err error) []Issue {
+	if errs, ok := err.(ast.Errors); ok {
+		out := make([]Issue, 0, len(errs))
+		for _, e := range errs {
+			issue := Issue{Code: "rego_compile_error", Target: targetName, Message: e.Message}
+			if e.Location != nil {
+				issue.Location = e.Location.String()
+			}
+			out = append(out, issue)
+		}
+		return out
+	}
+	return []Issue{{Code: "rego_compile_error", Target: targetName, Message: err.Error()}}
+}
+
+func checkDeprecatedBuiltins(targetName string, rule *ast.Rule) []Issue {
+	var issues []Issue
+	ast.WalkExprs(rule, func(expr *ast.Expr) bool {
+		if !expr.IsCall() {
+			return false
+		}
+		name := expr.Operator().String()
+		hint, deprecated := deprecatedBuiltins[name]
+		if !deprecated {
+			return false
+		}
+		issue := Issue{
+			Code:    "rego_deprecated_builtin",
+			Target:  targetName,
+			Message: fmt.Sprintf("deprecated builtin %q: %s", name, hint),
+		}
+		if expr.Location != nil {
+			issue.Location = expr.Location.String()
+		}
+		issues = append(issues, issue)
+		return false
+	})
+	return issues
+}
+
+// checkUnusedVars flags variables introduced with := that the rest of
+// the rule never reads, mirroring strict checking's unused variable
+// analysis. Wildcards are exempt by construction.
+func checkUnusedVars(targetName string, rule *ast.Rule) []Issue {
+	declared := map[ast.Var]*ast.Location{}
+	ast.WalkExprs(rule, func(expr *ast.Expr) bool {
+		if !expr.IsAssignment() {
+			return false
+		}
+		ast.WalkVars(expr.Operand(0), func(v ast.Var) bool {
+			if !v.IsWildcard() {
+				declared[v] = expr.Location
+			}
+			return false
+		})
+		return false
+	})
+	if len(declared) == 0 {
+		return nil
+	}
+
+	// a declaration contributes one occurrence, so a variable is used
+	// only if it appears more than once
+	counts := map[ast.Var]int{}
+	ast.WalkVars(rule, func(v ast.Var) bool {
+		counts[v]++
+		return false
+	})
+
+	var issues []Issue
+	for v, loc := range declared {
+		if counts[v] > 1 {
+			continue
+		}
+		issue := Issue{
+			Code:    "rego_unused_variable",
+			Target:  targetName,
+			Message: fmt.Sprintf("variable %q is declared but never used", v),
+		}
+		if loc != nil {
+			issue.Location = loc.String()
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
@@ -0,0 +1,114 @@
+package templatelint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/open-policy-agent/frameworks/constraint/pkg/core/templates"
+)
+
+func template(rego string, libs ...string) *templates.ConstraintTemplate {
+	ct := &templates.ConstraintTemplate{}
+	ct.Spec.Targets = []templates.Target{
+		{Target: "admission.k8s.gatekeeper.sh", Rego: rego, Libs: libs},
+	}
+	return ct
+}
+
+func TestLintTemplateClean(t *testing.T) {
+	ct := template(`
+package k8srequiredlabels
+
+violation[{"msg": msg}] {
+	required := {label | label := input.parameters.labels[_]}
+	count(required) > 0
+	msg := sprintf("missing: %v", [required])
+}
+`)
+	if issues := LintTemplate(ct); len(issues) != 0 {
+		t.Fatalf("expected no issues, got %v", issues)
+	}
+}
+
+func TestLintTemplateCompileError(t *testing.T) {
+	ct := template(`
+package broken
+
+violation[{"msg": msg}] {
+	msg := undefined_function(input)
+}
+`)
+	issues := LintTemplate(ct)
+	if len(issues) == 0 || issues[0].Code != "rego_compile_error" {
+		t.Fatalf("expected a compile error issue, got %v", issues)
+	}
+	if issues[0].Location == "" {
+		t.Errorf("compile error should carry a location, got %+v", issues[0])
+	}
+}
+
+func TestLintTemplateDeprecatedBuiltin(t *testing.T) {
+	ct := template(`
+package deprecated
+
+violation[{"msg": msg}] {
+	re_match("^x", input.review.object.metadata.name)
+	msg := "matched"
+}
+`)
+	issues := LintTemplate(ct)
+	if len(issues) != 1 || issues[0].Code != "rego_deprecated_builtin" {
+		t.Fatalf("expected one deprecated builtin issue, got %v", issues)
+	}
+	if !strings.Contains(issues[0].Message, "re_match") {
+		t.Errorf("message should name the builtin, got %q", issues[0].Message)
+	}
+}
+
+func TestLintTemplateUnusedVariable(t *testing.T) {
+	ct := template(`
+package unused
+
+violation[{"msg": msg}] {
+	dangling := input.parameters.labels
+	msg := "always"
+}
+`)
+	issues := LintTemplate(ct)
+	if len(issues) != 1 || issues[0].Code != "rego_unused_variable" {
+		t.Fatalf("expected one unused variable issue, got %v", issues)
+	}
+	if !strings.Contains(issues[0].Message, "dangling") {
+		t.Errorf("message should name the variable, got %q", issues[0].Message)
+	}
+}
+
+func TestLintTemplateLibs(t *testing.T) {
+	ct := template(`
+package uses_lib
+
+import data.lib.helpers
+
+violation[{"msg": msg}] {
+	msg := helpers.format(input.review.object.metadata.name)
+}
+`, `
+package lib.helpers
+
+format(name) = out {
+	out := sprintf("bad name: %v", [name])
+}
+`)
+	if issues := LintTemplate(ct); len(issues) != 0 {
+		t.Fatalf("expected no issues with libs compiled together, got %v", issues)
+	}
+}
+
+func TestAsError(t *testing.T) {
+	err := AsError([]Issue{
+		{Code: "rego_unused_variable", Target: "admission.k8s.gatekeeper.sh", Message: `variable "x" is declared but never used`},
+	})
+	if !strings.Contains(err.Error(), "rego_unused_variable") {
+		t.Errorf("error should carry issue codes, got %q", err.Error())
+	}
+}
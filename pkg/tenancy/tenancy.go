@@ -0,0 +1,259 @@
+// Package tenancy projects namespaced NamespacedConstraint wrappers
+// into cluster-scoped constraints whose match is confined to the
+// wrapper's own namespace. This lets tenant admins author constraints
+// for their namespaces with ordinary namespaced RBAC, while cluster
+// admins control which templates are tenant-delegable by annotating
+// them. Projections are labeled with their owner so revoking delegation
+// or deleting a wrapper prunes the projected constraint.
+package tenancy
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"time"
+
+	tenancyv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/tenancy/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+var log = logf.Log.WithName("tenancy")
+
+// DelegableAnnotation marks a ConstraintTemplate as tenant-delegable:
+// only annotated templates may be instantiated through
+// NamespacedConstraints. Cluster admins own templates, so they own the
+// annotation.
+const DelegableAnnotation = "tenancy.gatekeeper.sh/delegable"
+
+// OwnerNamespaceLabel and OwnerNameLabel record which
+// NamespacedConstraint a projected constraint came from.
+const (
+	OwnerNamespaceLabel = "tenancy.gatekeeper.sh/owner-namespace"
+	OwnerNameLabel      = "tenancy.gatekeeper.sh/owner-name"
+)
+
+// tickPeriod is how often wrappers are reconciled with their
+// projections.
+const tickPeriod = 30 * time.Second
+
+var enableNamespacedConstraints = flag.Bool("enable-namespaced-constraints", false, "(alpha) project NamespacedConstraint resources into cluster-scoped constraints confined to their own namespace")
+
+// Enabled returns true if namespaced constraint projection was requested
+// via flags.
+func Enabled() bool {
+	return *enableNamespacedConstraints
+}
+
+// +kubebuilder:rbac:groups=tenancy.gatekeeper.sh,resources=namespacedconstraints,verbs=get;list;watch
+// +kubebuilder:rbac:groups=tenancy.gatekeeper.sh,resources=namespacedconstraints/status,verbs=get;update;patch
+
+// Projector reconciles NamespacedConstraints with their projected
+// cluster-scoped constraints. It implements manager.Runnable.
+type Projector struct {
+	client client.Client
+}
+
+// AddProjector registers the projector with the manager.
+func AddProjector(mgr manager.Manager) error {
+	return mgr.Add(&Projector{client: mgr.GetClient()})
+}
+
+// Start implements manager.Runnable.
+func (p *Projector) Start(ctx context.Context) error {
+	ticker := time.NewTicker(tickPeriod)
+	defer ticker.Stop()
+	for {
+		p.projectAll(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// projectAll upserts a projection for every wrapper of a delegable
+// template and prunes projections whose wrapper or delegation is gone.
+func (p *Projector) projectAll(ctx context.Context) {
+	templates := &unstructured.UnstructuredList{}
+	templates.SetGroupVersionKind(schema.GroupVersionKind{Group: "templates.gatekeeper.sh", Version: "v1beta1", Kind: "ConstraintTemplateList"})
+	if err := p.client.List(ctx, templates); err != nil {
+		log.Error(err, "unable to list constraint templates")
+		return
+	}
+	delegable := delegableKinds(templates.Items)
+
+	wrappers := &tenancyv1alpha1.NamespacedConstraintList{}
+	if err := p.client.List(ctx, wrappers); err != nil {
+		log.Error(err, "unable to list namespaced constraints")
+		return
+	}
+
+	desired := map[string]bool{}
+	for i := range wrappers.Items {
+		wrapper := &wrappers.Items[i]
+		kind := wrapper.Spec.ConstraintKind
+		if !delegable[kind] {
+			p.updateStatus(ctx, wrapper, "", fmt.Errorf("template for kind %q is not tenant-delegable; a cluster admin must set the %s annotation on it", kind, DelegableAnnotation))
+			continue
+		}
+		projection, err := buildProjection(wrapper)
+		if err != nil {
+			p.updateStatus(ctx, wrapper, "", err)
+			continue
+		}
+		if err := p.apply(ctx, projection); err != nil {
+			p.updateStatus(ctx, wrapper, "", err)
+			continue
+		}
+		desired[projection.GetName()] = true
+		p.updateStatus(ctx, wrapper, projection.GetName(), nil)
+	}
+
+	// prune across every template kind, not just the delegable ones, so
+	// revoking delegation retires the projections it had allowed
+	for kind := range templateKinds(templates.Items) {
+		p.prune(ctx, kind, desired)
+	}
+}
+
+// templateKinds returns every constraint kind the templates declare.
+func templateKinds(templates []unstructured.Unstructured) map[string]bool {
+	out := map[string]bool{}
+	for i := range templates {
+		kind, found, err := unstructured.NestedString(templates[i].Object, "spec", "crd", "spec", "names", "kind")
+		if err != nil || !found {
+			continue
+		}
+		out[kind] = true
+	}
+	return out
+}
+
+// delegableKinds returns the constraint kinds whose templates carry the
+// delegable annotation.
+func delegableKinds(templates []unstructured.Unstructured) map[string]bool {
+	out := map[string]bool{}
+	for i := range templates {
+		kind, found, err := unstructured.NestedString(templates[i].Object, "spec", "crd", "spec", "names", "kind")
+		if err != nil || !found {
+			continue
+		}
+		if templates[i].GetAnnotations()[DelegableAnnotation] == "true" {
+			out[kind] = true
+		}
+	}
+	return out
+}
+
+// projectedName derives the cluster-scoped constraint name for a
+// wrapper. Prefixing with the namespace keeps wrappers of the same name
+// in different namespaces from colliding.
+func projectedName(wrapper *tenancyv1alpha1.NamespacedConstraint) string {
+	return fmt.Sprintf("tenant-%s-%s", wrapper.GetNamespace(), wrapper.GetName())
+}
+
+// buildProjection renders the cluster-scoped constraint a wrapper
+// projects to. The match is passed through except that its namespace
+// selection is forced to the wrapper's own namespace, so a tenant cannot
+// widen the constraint beyond what they administer.
+func buildProjection(wrapper *tenancyv1alpha1.NamespacedConstraint) (*unstructured.Unstructured, error) {
+	if wrapper.Spec.ConstraintKind == "" {
+		return nil, fmt.Errorf("namespaced constraint %s/%s does not set constraintKind", wrapper.GetNamespace(), wrapper.GetName())
+	}
+
+	match := map[string]interface{}{}
+	if len(wrapper.Spec.Match.Raw) > 0 {
+		if err := json.Unmarshal(wrapper.Spec.Match.Raw, &match); err != nil {
+			return nil, fmt.Errorf("parsing match of %s/%s: %w", wrapper.GetNamespace(), wrapper.GetName(), err)
+		}
+	}
+	match["namespaces"] = []interface{}{wrapper.GetNamespace()}
+	delete(match, "excludedNamespaces")
+
+	spec := map[string]interface{}{"match": match}
+	if wrapper.Spec.EnforcementAction != "" {
+		spec["enforcementAction"] = wrapper.Spec.EnforcementAction
+	}
+	if len(wrapper.Spec.Parameters.Raw) > 0 {
+		var parameters interface{}
+		if err := json.Unmarshal(wrapper.Spec.Parameters.Raw, &parameters); err != nil {
+			return nil, fmt.Errorf("parsing parameters of %s/%s: %w", wrapper.GetNamespace(), wrapper.GetName(), err)
+		}
+		spec["parameters"] = parameters
+	}
+
+	projection := &unstructured.Unstructured{}
+	projection.SetGroupVersionKind(schema.GroupVersionKind{Group: "constraints.gatekeeper.sh", Version: "v1beta1", Kind: wrapper.Spec.ConstraintKind})
+	projection.SetName(projectedName(wrapper))
+	projection.SetLabels(map[string]string{
+		OwnerNamespaceLabel: wrapper.GetNamespace(),
+		OwnerNameLabel:      wrapper.GetName(),
+	})
+	if err := unstructured.SetNestedMap(projection.Object, spec, "spec"); err != nil {
+		return nil, err
+	}
+	return projection, nil
+}
+
+// apply creates or updates one projected constraint.
+func (p *Projector) apply(ctx context.Context, projection *unstructured.Unstructured) error {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(projection.GroupVersionKind())
+	err := p.client.Get(ctx, client.ObjectKey{Name: projection.GetName()}, existing)
+	switch {
+	case apierrors.IsNotFound(err):
+		log.Info("projecting namespaced constraint", "kind", projection.GetKind(), "name", projection.GetName())
+		return p.client.Create(ctx, projection)
+	case err != nil:
+		return err
+	}
+	if existing.GetLabels()[OwnerNamespaceLabel] == "" {
+		return fmt.Errorf("constraint %s %s already exists and is not a tenant projection; refusing to overwrite",
+			projection.GetKind(), projection.GetName())
+	}
+	projection.SetResourceVersion(existing.GetResourceVersion())
+	return p.client.Update(ctx, projection)
+}
+
+// prune deletes projected constraints of one kind whose wrapper no
+// longer exists or is no longer delegable.
+func (p *Projector) prune(ctx context.Context, kind string, desired map[string]bool) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{Group: "constraints.gatekeeper.sh", Version: "v1beta1", Kind: kind + "List"})
+	if err := p.client.List(ctx, list, client.HasLabels{OwnerNamespaceLabel}); err != nil {
+		// the constraint CRD may not exist yet; nothing to prune then
+		return
+	}
+	for i := range list.Items {
+		if desired[list.Items[i].GetName()] {
+			continue
+		}
+		log.Info("pruning projected constraint without a wrapper", "kind", list.Items[i].GetKind(), "name", list.Items[i].GetName())
+		if err := p.client.Delete(ctx, &list.Items[i]); err != nil && !apierrors.IsNotFound(err) {
+			log.Error(err, "unable to prune projected constraint", "kind", list.Items[i].GetKind(), "name", list.Items[i].GetName())
+		}
+	}
+}
+
+// updateStatus records the projection outcome on the wrapper, avoiding
+// writes when nothing changed.
+func (p *Projector) updateStatus(ctx context.Context, wrapper *tenancyv1alpha1.NamespacedConstraint, projected string, projErr error) {
+	status := tenancyv1alpha1.NamespacedConstraintStatus{ProjectedName: projected}
+	if projErr != nil {
+		status.LastError = projErr.Error()
+	}
+	if wrapper.Status == status {
+		return
+	}
+	wrapper.Status = status
+	if err := p.client.Status().Update(ctx, wrapper); err != nil {
+		log.Error(err, "unable to update namespaced constraint status", "namespace", wrapper.GetNamespace(), "name", wrapper.GetName())
+	}
+}
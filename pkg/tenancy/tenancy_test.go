@@ -0,0 +1,98 @@
+package tenancy
+
+import (
+	"strings"
+	"testing"
+
+	tenancyv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/tenancy/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func wrapper(namespace, name, kind string) *tenancyv1alpha1.NamespacedConstraint {
+	nc := &tenancyv1alpha1.NamespacedConstraint{}
+	nc.SetNamespace(namespace)
+	nc.SetName(name)
+	nc.Spec.ConstraintKind = kind
+	return nc
+}
+
+func TestBuildProjectionConfinesNamespace(t *testing.T) {
+	nc := wrapper("team-a", "require-owner", "K8sRequiredLabels")
+	nc.Spec.EnforcementAction = "warn"
+	nc.Spec.Match = runtime.RawExtension{Raw: []byte(`{"kinds":[{"apiGroups":[""],"kinds":["Pod"]}],"namespaces":["kube-system"],"excludedNamespaces":["team-a"]}`)}
+	nc.Spec.Parameters = runtime.RawExtension{Raw: []byte(`{"labels":["owner"]}`)}
+
+	projection, err := buildProjection(nc)
+	if err != nil {
+		t.Fatalf("building projection: %s", err)
+	}
+	if projection.GetKind() != "K8sRequiredLabels" || projection.GetName() != "tenant-team-a-require-owner" {
+		t.Errorf("projection = %s %s", projection.GetKind(), projection.GetName())
+	}
+	namespaces, _, err := unstructured.NestedStringSlice(projection.Object, "spec", "match", "namespaces")
+	if err != nil {
+		t.Fatalf("reading namespaces: %s", err)
+	}
+	if len(namespaces) != 1 || namespaces[0] != "team-a" {
+		t.Errorf("namespaces = %v, want only the wrapper's own", namespaces)
+	}
+	if _, found, _ := unstructured.NestedSlice(projection.Object, "spec", "match", "excludedNamespaces"); found {
+		t.Errorf("excludedNamespaces should be dropped from the projection")
+	}
+	kinds, _, _ := unstructured.NestedSlice(projection.Object, "spec", "match", "kinds")
+	if len(kinds) != 1 {
+		t.Errorf("kinds matcher should pass through, got %v", kinds)
+	}
+	action, _, _ := unstructured.NestedString(projection.Object, "spec", "enforcementAction")
+	if action != "warn" {
+		t.Errorf("enforcementAction = %q", action)
+	}
+	labels, _, _ := unstructured.NestedStringSlice(projection.Object, "spec", "parameters", "labels")
+	if len(labels) != 1 || labels[0] != "owner" {
+		t.Errorf("parameters should pass through, got %v", labels)
+	}
+	if projection.GetLabels()[OwnerNamespaceLabel] != "team-a" || projection.GetLabels()[OwnerNameLabel] != "require-owner" {
+		t.Errorf("owner labels = %v", projection.GetLabels())
+	}
+}
+
+func TestBuildProjectionRequiresKind(t *testing.T) {
+	if _, err := buildProjection(wrapper("team-a", "no-kind", "")); err == nil {
+		t.Errorf("a wrapper without constraintKind should be rejected")
+	} else if !strings.Contains(err.Error(), "constraintKind") {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestDelegableKinds(t *testing.T) {
+	template := func(name, kind string, delegable bool) unstructured.Unstructured {
+		ct := unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"crd": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"names": map[string]interface{}{"kind": kind},
+					},
+				},
+			},
+		}}
+		ct.SetName(name)
+		if delegable {
+			ct.SetAnnotations(map[string]string{DelegableAnnotation: "true"})
+		}
+		return ct
+	}
+	templates := []unstructured.Unstructured{
+		template("k8srequiredlabels", "K8sRequiredLabels", true),
+		template("k8sallowedrepos", "K8sAllowedRepos", false),
+	}
+
+	delegable := delegableKinds(templates)
+	if !delegable["K8sRequiredLabels"] || delegable["K8sAllowedRepos"] {
+		t.Errorf("delegable = %v", delegable)
+	}
+	all := templateKinds(templates)
+	if len(all) != 2 {
+		t.Errorf("templateKinds = %v, want both kinds", all)
+	}
+}
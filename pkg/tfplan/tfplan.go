@@ -0,0 +1,193 @@
+// Package tfplan evaluates Terraform plans against the currently loaded
+// policies, so infrastructure pipelines get the same gate as kubectl. A
+// pipeline posts the output of `terraform show -json` to the endpoint;
+// the Kubernetes objects the plan would create or update are converted
+// into review objects and run through the same mutation and constraint
+// machinery as admission, and the response reports violations per
+// resource address.
+package tfplan
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/httpguard"
+	"github.com/open-policy-agent/gatekeeper/pkg/logging"
+	"github.com/open-policy-agent/gatekeeper/pkg/simulation"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("terraform-plan").WithValues(logging.Process, "terraform_plan")
+
+var (
+	enableTerraformPlanAPI = flag.Bool("enable-terraform-plan-api", false, "(alpha) enable the Terraform plan validation endpoint")
+	terraformPlanAddr      = flag.String("terraform-plan-addr", ":8890", "The address the Terraform plan validation endpoint binds to.")
+)
+
+// Enabled returns true when the Terraform plan endpoint was requested
+// via flag.
+func Enabled() bool {
+	return *enableTerraformPlanAPI
+}
+
+// plan is the subset of Terraform's JSON plan format the validator
+// reads.
+type plan struct {
+	ResourceChanges []resourceChange `json:"resource_changes"`
+}
+
+type resourceChange struct {
+	Address string `json:"address"`
+	Type    string `json:"type"`
+	Change  struct {
+		Actions []string        `json:"actions"`
+		After   json.RawMessage `json:"after"`
+	} `json:"change"`
+}
+
+// manifestChange is the `after` value of a kubernetes_manifest resource.
+type manifestChange struct {
+	Manifest map[string]interface{} `json:"manifest"`
+	Object   map[string]interface{} `json:"object"`
+}
+
+// PlannedObject is one Kubernetes object a plan would create or update.
+type PlannedObject struct {
+	// Address is the Terraform resource address, for reporting.
+	Address string
+	Object  *unstructured.Unstructured
+}
+
+// ObjectResult pairs a planned object with its simulation outcome.
+type ObjectResult struct {
+	Address string             `json:"address"`
+	Result  *simulation.Result `json:"result"`
+}
+
+// Response is the endpoint's reply for one plan.
+type Response struct {
+	Results []ObjectResult `json:"results"`
+	// Denied is true when any planned object violates a deny constraint.
+	Denied bool `json:"denied"`
+}
+
+// ExtractObjects returns the Kubernetes objects a plan would create or
+// update. Only kubernetes_manifest resources carry a full object; other
+// resource types are ignored, as are deletions.
+func ExtractObjects(raw []byte) ([]PlannedObject, error) {
+	p := &plan{}
+	if err := json.Unmarshal(raw, p); err != nil {
+		return nil, fmt.Errorf("parsing Terraform plan: %w", err)
+	}
+	var objects []PlannedObject
+	for _, rc := range p.ResourceChanges {
+		if rc.Type != "kubernetes_manifest" || !isWrite(rc.Change.Actions) || len(rc.Change.After) == 0 {
+			continue
+		}
+		after := &manifestChange{}
+		if err := json.Unmarshal(rc.Change.After, after); err != nil {
+			return nil, fmt.Errorf("parsing planned state of %s: %w", rc.Address, err)
+		}
+		manifest := after.Manifest
+		if manifest == nil {
+			manifest = after.Object
+		}
+		if manifest == nil {
+			continue
+		}
+		obj := &unstructured.Unstructured{Object: manifest}
+		if obj.GetKind() == "" || obj.GetAPIVersion() == "" {
+			return nil, fmt.Errorf("planned manifest %s is missing apiVersion or kind", rc.Address)
+		}
+		objects = append(objects, PlannedObject{Address: rc.Address, Object: obj})
+	}
+	return objects, nil
+}
+
+// isWrite reports whether the planned actions create or update the
+// resource.
+func isWrite(actions []string) bool {
+	for _, action := range actions {
+		if action == "create" || action == "update" {
+			return true
+		}
+	}
+	return false
+}
+
+// Server serves Terraform plan validation requests. It implements
+// manager.Runnable so it can be registered with the controller manager
+// like the other optional servers.
+type Server struct {
+	simulator *simulation.Simulator
+	addr      string
+}
+
+// NewServer returns a Server configured from the Terraform plan flags.
+func NewServer(simulator *simulation.Simulator) *Server {
+	return &Server{simulator: simulator, addr: *terraformPlanAddr}
+}
+
+// Start implements manager.Runnable.
+func (s *Server) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.Handle("/v1/terraform-plan", httpguard.Wrap(http.HandlerFunc(s.handlePlan)))
+	srv := &http.Server{Addr: s.addr, Handler: mux}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+// handlePlan accepts a Terraform plan JSON on POST and responds with the
+// violations admission would produce for each planned object.
+func (s *Server) handlePlan(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	raw := json.RawMessage{}
+	if err := json.NewDecoder(req.Body).Decode(&raw); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	objects, err := ExtractObjects(raw)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := &Response{Results: []ObjectResult{}}
+	for _, planned := range objects {
+		result, err := s.simulator.Simulate(req.Context(), &simulation.Request{Object: planned.Object})
+		if err != nil {
+			log.Error(err, "unable to evaluate planned object", "address", planned.Address)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		response.Results = append(response.Results, ObjectResult{Address: planned.Address, Result: result})
+		if result.Denied {
+			response.Denied = true
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error(err, "unable to encode plan validation result")
+	}
+}
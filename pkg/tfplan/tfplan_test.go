@@ -0,0 +1,112 @@
+package tfplan
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/client/drivers/local"
+	"github.com/open-policy-agent/gatekeeper/pkg/mutation"
+	"github.com/open-policy-agent/gatekeeper/pkg/simulation"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+)
+
+func makeOpaClient() (*opa.Client, error) {
+	t := &target.K8sValidationTarget{}
+	driver := local.New(local.Tracing(false))
+	backend, err := opa.NewBackend(opa.Driver(driver))
+	if err != nil {
+		return nil, err
+	}
+	return backend.NewClient(opa.Targets(t))
+}
+
+const samplePlan = `{
+  "resource_changes": [
+    {
+      "address": "kubernetes_manifest.deployment",
+      "type": "kubernetes_manifest",
+      "change": {
+        "actions": ["create"],
+        "after": {
+          "manifest": {
+            "apiVersion": "apps/v1",
+            "kind": "Deployment",
+            "metadata": {"name": "nginx", "namespace": "default"}
+          }
+        }
+      }
+    },
+    {
+      "address": "kubernetes_manifest.removed",
+      "type": "kubernetes_manifest",
+      "change": {"actions": ["delete"], "after": null}
+    },
+    {
+      "address": "aws_s3_bucket.logs",
+      "type": "aws_s3_bucket",
+      "change": {"actions": ["create"], "after": {"bucket": "logs"}}
+    }
+  ]
+}`
+
+func TestExtractObjects(t *testing.T) {
+	objects, err := ExtractObjects([]byte(samplePlan))
+	if err != nil {
+		t.Fatalf("extracting objects: %s", err)
+	}
+	if len(objects) != 1 {
+		t.Fatalf("got %d objects, want 1 (deletes and non-Kubernetes resources skipped)", len(objects))
+	}
+	if objects[0].Address != "kubernetes_manifest.deployment" {
+		t.Errorf("address = %q", objects[0].Address)
+	}
+	if objects[0].Object.GetKind() != "Deployment" || objects[0].Object.GetName() != "nginx" {
+		t.Errorf("object = %v", objects[0].Object)
+	}
+}
+
+func TestExtractObjectsRejectsIncompleteManifests(t *testing.T) {
+	raw := `{"resource_changes": [{"address": "kubernetes_manifest.bad", "type": "kubernetes_manifest",
+		"change": {"actions": ["create"], "after": {"manifest": {"metadata": {"name": "x"}}}}}]}`
+	if _, err := ExtractObjects([]byte(raw)); err == nil {
+		t.Errorf("manifests without apiVersion/kind should be rejected")
+	}
+}
+
+func TestHandlePlan(t *testing.T) {
+	opaClient, err := makeOpaClient()
+	if err != nil {
+		t.Fatalf("could not initialize OPA: %s", err)
+	}
+	server := &Server{simulator: simulation.New(opaClient, mutation.NewSystem(), nil)}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/terraform-plan", strings.NewReader(samplePlan))
+	rec := httptest.NewRecorder()
+	server.handlePlan(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	response := &Response{}
+	if err := json.Unmarshal(rec.Body.Bytes(), response); err != nil {
+		t.Fatalf("parsing response: %s", err)
+	}
+	if len(response.Results) != 1 || response.Denied {
+		t.Errorf("response = %+v, want one clean result", response)
+	}
+
+	rec = httptest.NewRecorder()
+	server.handlePlan(rec, httptest.NewRequest(http.MethodGet, "/v1/terraform-plan", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET should be rejected, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	server.handlePlan(rec, httptest.NewRequest(http.MethodPost, "/v1/terraform-plan", strings.NewReader("not json")))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("malformed plans should be rejected, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,81 @@
+package tlsopts
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+func setFlags(t *testing.T, minVersion, ciphers, curves string) {
+	t.Helper()
+	oldMin, oldCiphers, oldCurves := *tlsMinVersion, *tlsCipherSuites, *tlsCurvePreferences
+	*tlsMinVersion = minVersion
+	*tlsCipherSuites = ciphers
+	*tlsCurvePreferences = curves
+	t.Cleanup(func() {
+		*tlsMinVersion = oldMin
+		*tlsCipherSuites = oldCiphers
+		*tlsCurvePreferences = oldCurves
+	})
+}
+
+func TestEnabled(t *testing.T) {
+	setFlags(t, "", "", "")
+	if Enabled() {
+		t.Errorf("expected Enabled() to be false with no flags set")
+	}
+	setFlags(t, "1.2", "", "")
+	if !Enabled() {
+		t.Errorf("expected Enabled() to be true with --tls-min-version set")
+	}
+}
+
+func TestApplyTo(t *testing.T) {
+	setFlags(t, "1.3", "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384", "X25519,P256")
+	if err := Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %s", err)
+	}
+	cfg := &tls.Config{}
+	ApplyTo(cfg)
+	if cfg.MinVersion != tls.VersionTLS13 {
+		t.Errorf("MinVersion = %#x, want %#x", cfg.MinVersion, tls.VersionTLS13)
+	}
+	want := []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}
+	if len(cfg.CipherSuites) != len(want) || cfg.CipherSuites[0] != want[0] || cfg.CipherSuites[1] != want[1] {
+		t.Errorf("CipherSuites = %v, want %v", cfg.CipherSuites, want)
+	}
+	wantCurves := []tls.CurveID{tls.X25519, tls.CurveP256}
+	if len(cfg.CurvePreferences) != len(wantCurves) || cfg.CurvePreferences[0] != wantCurves[0] || cfg.CurvePreferences[1] != wantCurves[1] {
+		t.Errorf("CurvePreferences = %v, want %v", cfg.CurvePreferences, wantCurves)
+	}
+}
+
+func TestApplyToLeavesUnsetFields(t *testing.T) {
+	setFlags(t, "1.2", "", "")
+	cfg := &tls.Config{}
+	ApplyTo(cfg)
+	if cfg.MinVersion != tls.VersionTLS12 {
+		t.Errorf("MinVersion = %#x, want %#x", cfg.MinVersion, tls.VersionTLS12)
+	}
+	if cfg.CipherSuites != nil || cfg.CurvePreferences != nil {
+		t.Errorf("unset flags should not constrain ciphers or curves")
+	}
+}
+
+func TestValidateRejectsBadValues(t *testing.T) {
+	tests := []struct {
+		name                        string
+		minVersion, ciphers, curves string
+	}{
+		{name: "bad version", minVersion: "1.4"},
+		{name: "bad cipher", ciphers: "TLS_BOGUS_SUITE"},
+		{name: "bad curve", curves: "P999"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			setFlags(t, tc.minVersion, tc.ciphers, tc.curves)
+			if err := Validate(); err == nil {
+				t.Errorf("expected a validation error")
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/logging"
+	"go.opencensus.io/trace"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("tracing").WithValues(logging.Process, "tracing")
+
+const (
+	// flushInterval is how often batched spans are exported.
+	flushInterval = 5 * time.Second
+
+	// maxBufferedSpans bounds memory when the collector is unreachable;
+	// newer spans are dropped beyond it.
+	maxBufferedSpans = 2048
+)
+
+// The following structs are the OTLP/HTTP JSON trace encoding, reduced
+// to the fields Gatekeeper emits. Trace and span IDs are hex per the
+// OTLP JSON mapping.
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes,omitempty"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	StartTimeUnixNano int64           `json:"startTimeUnixNano,string"`
+	EndTimeUnixNano   int64           `json:"endTimeUnixNano,string"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            *otlpStatus     `json:"status,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code    int32  `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Exporter batches finished spans and ships them to an OTLP HTTP
+// endpoint. It implements trace.Exporter for span collection and
+// manager.Runnable for background flushing.
+type Exporter struct {
+	endpoint string
+	client   *http.Client
+
+	mux   sync.Mutex
+	spans []*trace.SpanData
+}
+
+func newExporter(endpoint string) *Exporter {
+	return &Exporter{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ExportSpan buffers one finished span for the next flush.
+func (e *Exporter) ExportSpan(sd *trace.SpanData) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	if len(e.spans) >= maxBufferedSpans {
+		return
+	}
+	e.spans = append(e.spans, sd)
+}
+
+// Start flushes buffered spans until the context is canceled, then
+// flushes once more so shutdown does not lose spans.
+func (e *Exporter) Start(ctx context.Context) error {
+	log.Info("starting OTLP trace exporter", "endpoint", e.endpoint)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := e.flush(ctx); err != nil {
+				log.Error(err, "failed to export spans")
+			}
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := e.flush(flushCtx); err != nil {
+				log.Error(err, "failed to export spans during shutdown")
+			}
+			return nil
+		}
+	}
+}
+
+func (e *Exporter) flush(ctx context.Context) error {
+	e.mux.Lock()
+	spans := e.spans
+	e.spans = nil
+	e.mux.Unlock()
+	if len(spans) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(encodeSpans(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("collector returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func encodeSpans(spans []*trace.SpanData) *otlpRequest {
+	out := make([]otlpSpan, 0, len(spans))
+	for _, sd := range spans {
+		span := otlpSpan{
+			TraceID:           hex.EncodeToString(sd.SpanContext.TraceID[:]),
+			SpanID:            hex.EncodeToString(sd.SpanContext.SpanID[:]),
+			Name:              sd.Name,
+			StartTimeUnixNano: sd.StartTime.UnixNano(),
+			EndTimeUnixNano:   sd.EndTime.UnixNano(),
+		}
+		var zeroParent trace.SpanID
+		if sd.ParentSpanID != zeroParent {
+			span.ParentSpanID = hex.EncodeToString(sd.ParentSpanID[:])
+		}
+		for key, value := range sd.Attributes {
+			span.Attributes = append(span.Attributes, otlpAttribute{
+				Key:   key,
+				Value: otlpValue{StringValue: fmt.Sprintf("%v", value)},
+			})
+		}
+		if sd.Status.Code != 0 || sd.Status.Message != "" {
+			span.Status = &otlpStatus{Code: sd.Status.Code, Message: sd.Status.Message}
+		}
+		out = append(out, span)
+	}
+	return &otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: []otlpAttribute{{
+					Key:   "service.name",
+					Value: otlpValue{StringValue: "gatekeeper"},
+				}},
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: "github.com/open-policy-agent/gatekeeper"},
+				Spans: out,
+			}},
+		}},
+	}
+}
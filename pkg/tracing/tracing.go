@@ -0,0 +1,71 @@
+// Package tracing emits spans for admission requests so operators can
+// see where webhook latency goes: policy evaluation, data sync lookups,
+// or external data provider calls. Spans are recorded through the
+// OpenCensus trace API already vendored for metrics and exported in
+// OTLP format over HTTP, so any OpenTelemetry collector can receive
+// them; no OpenTelemetry SDK is required.
+package tracing
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/url"
+
+	"go.opencensus.io/trace"
+)
+
+var (
+	enableTracing   = flag.Bool("enable-tracing", false, "(alpha) emit OTLP spans for admission requests, policy evaluation, and external data calls")
+	tracingEndpoint = flag.String("tracing-endpoint", "", "OTLP HTTP endpoint spans are exported to, e.g. http://otel-collector:4318/v1/traces")
+	tracingSample   = flag.Float64("tracing-sample-rate", 0.01, "fraction of admission requests to trace, between 0 and 1")
+)
+
+// Enabled returns true if tracing was requested via flags.
+func Enabled() bool {
+	return *enableTracing
+}
+
+// Validate checks the tracing flags for mistakes that would otherwise
+// only surface once spans fail to export.
+func Validate() error {
+	if !*enableTracing {
+		return nil
+	}
+	if *tracingEndpoint == "" {
+		return fmt.Errorf("--enable-tracing requires --tracing-endpoint")
+	}
+	u, err := url.Parse(*tracingEndpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("--tracing-endpoint must be an http or https URL, got %q", *tracingEndpoint)
+	}
+	if *tracingSample < 0 || *tracingSample > 1 {
+		return fmt.Errorf("--tracing-sample-rate must be between 0 and 1, got %v", *tracingSample)
+	}
+	return nil
+}
+
+// Setup applies the sampling rate and registers the OTLP exporter. The
+// returned exporter is a manager.Runnable that flushes batched spans in
+// the background.
+func Setup() *Exporter {
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.ProbabilitySampler(*tracingSample)})
+	exporter := newExporter(*tracingEndpoint)
+	trace.RegisterExporter(exporter)
+	return exporter
+}
+
+// StartSpan opens a span as a child of any span already on the context.
+// When tracing is not enabled, the span is unsampled and near-free.
+func StartSpan(ctx context.Context, name string, attrs ...trace.Attribute) (context.Context, *trace.Span) {
+	ctx, span := trace.StartSpan(ctx, name)
+	if len(attrs) > 0 {
+		span.AddAttributes(attrs...)
+	}
+	return ctx, span
+}
+
+// Attribute builds a string span attribute.
+func Attribute(key, value string) trace.Attribute {
+	return trace.StringAttribute(key, value)
+}
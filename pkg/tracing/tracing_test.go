@@ -0,0 +1,134 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opencensus.io/trace"
+)
+
+func sampleSpanData() *trace.SpanData {
+	sd := &trace.SpanData{
+		Name:      "admission.validate",
+		StartTime: time.Date(2021, 2, 3, 4, 5, 6, 0, time.UTC),
+		EndTime:   time.Date(2021, 2, 3, 4, 5, 6, int(20*time.Millisecond), time.UTC),
+		Attributes: map[string]interface{}{
+			"resource_kind": "Pod",
+		},
+	}
+	sd.SpanContext.TraceID = trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	sd.SpanContext.SpanID = trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8}
+	return sd
+}
+
+func TestValidate(t *testing.T) {
+	reset := func() {
+		*enableTracing = false
+		*tracingEndpoint = ""
+		*tracingSample = 0.01
+	}
+	defer reset()
+
+	reset()
+	if err := Validate(); err != nil {
+		t.Errorf("unexpected error with tracing disabled: %v", err)
+	}
+
+	*enableTracing = true
+	if err := Validate(); err == nil {
+		t.Error("expected an error when the endpoint is missing")
+	}
+	*tracingEndpoint = "not a url"
+	if err := Validate(); err == nil {
+		t.Error("expected an error for a malformed endpoint")
+	}
+	*tracingEndpoint = "http://otel-collector:4318/v1/traces"
+	if err := Validate(); err != nil {
+		t.Errorf("unexpected error for a valid endpoint: %v", err)
+	}
+	*tracingSample = 1.5
+	if err := Validate(); err == nil {
+		t.Error("expected an error for a sample rate above 1")
+	}
+}
+
+func TestExporterFlush(t *testing.T) {
+	var got otlpRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decoding payload: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	e := newExporter(server.URL)
+	e.ExportSpan(sampleSpanData())
+	if err := e.flush(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(got.ResourceSpans) != 1 || len(got.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("unexpected payload shape: %+v", got)
+	}
+	if got.ResourceSpans[0].Resource.Attributes[0].Value.StringValue != "gatekeeper" {
+		t.Errorf("unexpected service name: %+v", got.ResourceSpans[0].Resource)
+	}
+	spans := got.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 1 {
+		t.Fatalf("expected one span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name != "admission.validate" {
+		t.Errorf("span name = %q", span.Name)
+	}
+	if span.TraceID != "0102030405060708090a0b0c0d0e0f10" {
+		t.Errorf("traceId = %q", span.TraceID)
+	}
+	if span.SpanID != "0102030405060708" {
+		t.Errorf("spanId = %q", span.SpanID)
+	}
+	if span.EndTimeUnixNano <= span.StartTimeUnixNano {
+		t.Error("span end should be after its start")
+	}
+	if len(span.Attributes) != 1 || span.Attributes[0].Key != "resource_kind" || span.Attributes[0].Value.StringValue != "Pod" {
+		t.Errorf("unexpected attributes: %+v", span.Attributes)
+	}
+
+	// the buffer is drained after a flush
+	if err := e.flush(context.Background()); err != nil {
+		t.Fatalf("unexpected error on empty flush: %v", err)
+	}
+}
+
+func TestExporterCollectorError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	e := newExporter(server.URL)
+	e.ExportSpan(sampleSpanData())
+	if err := e.flush(context.Background()); err == nil {
+		t.Error("expected an error when the collector rejects the batch")
+	}
+}
+
+func TestExporterBufferBound(t *testing.T) {
+	e := newExporter("http://collector.invalid")
+	for i := 0; i < maxBufferedSpans+10; i++ {
+		e.ExportSpan(sampleSpanData())
+	}
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	if len(e.spans) != maxBufferedSpans {
+		t.Errorf("expected the buffer to cap at %d, got %d", maxBufferedSpans, len(e.spans))
+	}
+}
@@ -0,0 +1,85 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// per-constraint audit overrides are declared under spec.audit:
+// interval is a Go duration string bounding how often audit refreshes the
+// constraint's results, and match.namespaces/match.excludedNamespaces
+// narrow which namespaces audit reports on. This lets expensive
+// constraints (e.g. ones calling external data) run hourly while cheap
+// ones run at the global --audit-interval.
+
+// GetAuditInterval returns the audit interval declared on the
+// constraint, and whether one was declared.
+func GetAuditInterval(item map[string]interface{}) (time.Duration, bool, error) {
+	raw, found, err := unstructured.NestedString(item, "spec", "audit", "interval")
+	if err != nil {
+		return 0, false, err
+	}
+	if !found || raw == "" {
+		return 0, false, nil
+	}
+	interval, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, false, fmt.Errorf("audit interval %q is not a valid duration: %v", raw, err)
+	}
+	if interval <= 0 {
+		return 0, false, fmt.Errorf("audit interval %q must be positive", raw)
+	}
+	return interval, true, nil
+}
+
+// AuditMatchesNamespace returns true if audit should report the
+// constraint's violations in the given namespace. Namespace names match
+// exactly or by a trailing-* prefix wildcard, like
+// spec.scopedEnforcementActions; an absent match reports everywhere.
+func AuditMatchesNamespace(item map[string]interface{}, namespace string) (bool, error) {
+	excluded, _, err := unstructured.NestedStringSlice(item, "spec", "audit", "match", "excludedNamespaces")
+	if err != nil {
+		return false, err
+	}
+	if auditNamespaceInList(excluded, namespace) {
+		return false, nil
+	}
+	namespaces, found, err := unstructured.NestedStringSlice(item, "spec", "audit", "match", "namespaces")
+	if err != nil {
+		return false, err
+	}
+	if !found || len(namespaces) == 0 {
+		return true, nil
+	}
+	return auditNamespaceInList(namespaces, namespace), nil
+}
+
+func auditNamespaceInList(list []string, namespace string) bool {
+	for _, n := range list {
+		if n == "*" || n == namespace {
+			return true
+		}
+		if strings.HasSuffix(n, "*") && strings.HasPrefix(namespace, strings.TrimSuffix(n, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateAuditSettings validates the audit overrides declared on a
+// constraint.
+func ValidateAuditSettings(item map[string]interface{}) error {
+	if _, _, err := GetAuditInterval(item); err != nil {
+		return err
+	}
+	if _, _, err := unstructured.NestedStringSlice(item, "spec", "audit", "match", "namespaces"); err != nil {
+		return err
+	}
+	if _, _, err := unstructured.NestedStringSlice(item, "spec", "audit", "match", "excludedNamespaces"); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func makeAudited(audit map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"spec": map[string]interface{}{
+			"audit": audit,
+		},
+	}
+}
+
+func TestGetAuditInterval(t *testing.T) {
+	interval, found, err := GetAuditInterval(makeAudited(map[string]interface{}{"interval": "1h"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !found || interval != time.Hour {
+		t.Errorf("interval = %v, found = %v; want 1h", interval, found)
+	}
+
+	_, found, err = GetAuditInterval(map[string]interface{}{"spec": map[string]interface{}{}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if found {
+		t.Errorf("constraint without an override should not report one")
+	}
+
+	if _, _, err := GetAuditInterval(makeAudited(map[string]interface{}{"interval": "often"})); err == nil {
+		t.Errorf("unparseable intervals should be rejected")
+	}
+	if _, _, err := GetAuditInterval(makeAudited(map[string]interface{}{"interval": "-5m"})); err == nil {
+		t.Errorf("non-positive intervals should be rejected")
+	}
+}
+
+func TestAuditMatchesNamespace(t *testing.T) {
+	scoped := makeAudited(map[string]interface{}{
+		"match": map[string]interface{}{
+			"namespaces":         []interface{}{"prod-*"},
+			"excludedNamespaces": []interface{}{"prod-canary"},
+		},
+	})
+	for namespace, want := range map[string]bool{
+		"prod-payments": true,
+		"prod-canary":   false,
+		"dev-payments":  false,
+	} {
+		matched, err := AuditMatchesNamespace(scoped, namespace)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if matched != want {
+			t.Errorf("AuditMatchesNamespace(%q) = %v, want %v", namespace, matched, want)
+		}
+	}
+
+	matched, err := AuditMatchesNamespace(map[string]interface{}{"spec": map[string]interface{}{}}, "anything")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !matched {
+		t.Errorf("constraints without an audit match should report everywhere")
+	}
+}
+
+func TestValidateAuditSettings(t *testing.T) {
+	valid := makeAudited(map[string]interface{}{
+		"interval": "30m",
+		"match": map[string]interface{}{
+			"namespaces": []interface{}{"team-a"},
+		},
+	})
+	if err := ValidateAuditSettings(valid); err != nil {
+		t.Errorf("ValidateAuditSettings should not error on valid settings, %v", err)
+	}
+	if err := ValidateAuditSettings(makeAudited(map[string]interface{}{"interval": "often"})); err == nil {
+		t.Errorf("invalid intervals should be rejected")
+	}
+	if err := ValidateAuditSettings(makeAudited(map[string]interface{}{
+		"match": map[string]interface{}{"namespaces": []interface{}{42}},
+	})); err == nil {
+		t.Errorf("non-string namespace entries should be rejected")
+	}
+}
@@ -0,0 +1,52 @@
+package util
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// constraints may declare spec.clusterSelector.matchLabels, a set of
+// label values the cluster's identity labels must carry for the
+// constraint to activate. Clusters publish their identity labels via the
+// gatekeeper-cluster-identity ConfigMap. Constraints without a cluster
+// selector are active everywhere.
+
+// ConstraintMatchesCluster returns true if the constraint's cluster
+// selector matches the given cluster identity labels.
+func ConstraintMatchesCluster(constraint map[string]interface{}, clusterLabels map[string]string) (bool, error) {
+	matchLabels, found, err := unstructured.NestedStringMap(constraint, "spec", "clusterSelector", "matchLabels")
+	if err != nil {
+		return false, err
+	}
+	if !found {
+		return true, nil
+	}
+	for k, v := range matchLabels {
+		if clusterLabels[k] != v {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// ValidateClusterSelector validates the cluster selector declared on a
+// constraint.
+func ValidateClusterSelector(constraint map[string]interface{}) error {
+	selector, found, err := unstructured.NestedMap(constraint, "spec", "clusterSelector")
+	if err != nil {
+		return err
+	}
+	if !found {
+		return nil
+	}
+	for field := range selector {
+		if field != "matchLabels" {
+			return fmt.Errorf("clusterSelector field %q is not recognized, only matchLabels is supported", field)
+		}
+	}
+	if _, _, err := unstructured.NestedStringMap(constraint, "spec", "clusterSelector", "matchLabels"); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package util
+
+import "testing"
+
+func makeClusterSelector(matchLabels map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"spec": map[string]interface{}{
+			"clusterSelector": map[string]interface{}{
+				"matchLabels": matchLabels,
+			},
+		},
+	}
+}
+
+func TestConstraintMatchesCluster(t *testing.T) {
+	clusterLabels := map[string]string{"env": "prod", "region": "us-east-1"}
+
+	tc := []struct {
+		name       string
+		constraint map[string]interface{}
+		expected   bool
+	}{
+		{
+			name:       "no selector matches everywhere",
+			constraint: map[string]interface{}{"spec": map[string]interface{}{}},
+			expected:   true,
+		},
+		{
+			name:       "matching labels",
+			constraint: makeClusterSelector(map[string]interface{}{"env": "prod"}),
+			expected:   true,
+		},
+		{
+			name:       "all labels must match",
+			constraint: makeClusterSelector(map[string]interface{}{"env": "prod", "region": "eu-west-1"}),
+			expected:   false,
+		},
+		{
+			name:       "label not present on cluster",
+			constraint: makeClusterSelector(map[string]interface{}{"tier": "free"}),
+			expected:   false,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ConstraintMatchesCluster(tt.constraint, clusterLabels)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestConstraintMatchesClusterNoClusterLabels(t *testing.T) {
+	matched, err := ConstraintMatchesCluster(makeClusterSelector(map[string]interface{}{"env": "prod"}), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if matched {
+		t.Errorf("constraints with cluster selectors should not activate without cluster identity labels")
+	}
+}
+
+func TestValidateClusterSelector(t *testing.T) {
+	if err := ValidateClusterSelector(makeClusterSelector(map[string]interface{}{"env": "prod"})); err != nil {
+		t.Errorf("ValidateClusterSelector should not error on valid selector, %v", err)
+	}
+	if err := ValidateClusterSelector(map[string]interface{}{"spec": map[string]interface{}{}}); err != nil {
+		t.Errorf("ValidateClusterSelector should not error when no selector is declared, %v", err)
+	}
+	if err := ValidateClusterSelector(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"clusterSelector": map[string]interface{}{"matchExpressions": []interface{}{}},
+		},
+	}); err == nil {
+		t.Errorf("ValidateClusterSelector should error on unsupported selector fields")
+	}
+	if err := ValidateClusterSelector(makeClusterSelector(map[string]interface{}{"env": int64(1)})); err == nil {
+		t.Errorf("ValidateClusterSelector should error on non-string label values")
+	}
+}
@@ -2,6 +2,7 @@ package util
 
 import (
 	"fmt"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
@@ -12,11 +13,23 @@ const (
 	Deny         EnforcementAction = "deny"
 	Dryrun       EnforcementAction = "dryrun"
 	Unrecognized EnforcementAction = "unrecognized"
+	// Ignore is only valid as an operation-scoped action; it drops the
+	// violation entirely for that admission operation.
+	Ignore EnforcementAction = "ignore"
 )
 
 var supportedEnforcementActions = []EnforcementAction{Deny, Dryrun}
 var KnownEnforcementActions = []EnforcementAction{Deny, Dryrun, Unrecognized}
 
+// EnforcementPoint is a stage of the request lifecycle at which
+// constraints are evaluated and may scope their enforcement action.
+type EnforcementPoint string
+
+const (
+	WebhookEnforcementPoint = EnforcementPoint("webhook")
+	AuditEnforcementPoint   = EnforcementPoint("audit")
+)
+
 func ValidateEnforcementAction(input EnforcementAction) error {
 	for _, n := range supportedEnforcementActions {
 		if input == n {
@@ -26,6 +39,189 @@ func ValidateEnforcementAction(input EnforcementAction) error {
 	return fmt.Errorf("Could not find the provided enforcementAction value within the supported list %v", supportedEnforcementActions)
 }
 
+// admission operations recognized by operation-scoped enforcement actions
+var supportedOperations = []string{"CREATE", "UPDATE", "DELETE", "CONNECT"}
+
+// GetEnforcementActionForOperation returns the enforcement action a
+// constraint scopes to the given admission operation via
+// spec.operationEnforcementActions, and whether one was declared. This
+// lets a constraint deny CREATE while only warning on UPDATE, which is
+// essential when legacy objects cannot be fixed immediately but new
+// violations must be blocked.
+func GetEnforcementActionForOperation(item map[string]interface{}, operation string) (EnforcementAction, bool, error) {
+	action, found, err := unstructured.NestedString(item, "spec", "operationEnforcementActions", operation)
+	if err != nil {
+		return "", false, err
+	}
+	if !found || action == "" {
+		return "", false, nil
+	}
+	enforcementAction := EnforcementAction(action)
+	if enforcementAction != Ignore {
+		if err := ValidateEnforcementAction(enforcementAction); err != nil {
+			return Unrecognized, true, nil
+		}
+	}
+	return enforcementAction, true, nil
+}
+
+// ValidateOperationEnforcementActions validates every per-operation
+// action declared on a constraint.
+func ValidateOperationEnforcementActions(item map[string]interface{}) error {
+	actions, found, err := unstructured.NestedStringMap(item, "spec", "operationEnforcementActions")
+	if err != nil {
+		return err
+	}
+	if !found {
+		return nil
+	}
+	for operation, action := range actions {
+		supported := false
+		for _, op := range supportedOperations {
+			if operation == op {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return fmt.Errorf("operation %q is not recognized, supported operations are %v", operation, supportedOperations)
+		}
+		if EnforcementAction(action) == Ignore {
+			continue
+		}
+		if err := ValidateEnforcementAction(EnforcementAction(action)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetEnforcementActionForNamespace returns the enforcement action a
+// constraint scopes to the given namespace via
+// spec.scopedEnforcementActions, and whether one matched. Entries are
+// evaluated in order and the first whose namespaces match wins; names
+// match exactly or by a trailing-* prefix wildcard. This lets one
+// constraint deny in production namespaces while only reporting in
+// development ones, instead of duplicating the constraint per
+// environment.
+func GetEnforcementActionForNamespace(item map[string]interface{}, namespace string) (EnforcementAction, bool, error) {
+	scoped, found, err := unstructured.NestedSlice(item, "spec", "scopedEnforcementActions")
+	if err != nil {
+		return "", false, err
+	}
+	if !found {
+		return "", false, nil
+	}
+	for _, raw := range scoped {
+		entry, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if !scopeMatchesNamespace(entry, namespace) {
+			continue
+		}
+		action, _, err := unstructured.NestedString(entry, "action")
+		if err != nil {
+			return "", false, err
+		}
+		enforcementAction := EnforcementAction(action)
+		if err := ValidateEnforcementAction(enforcementAction); err != nil {
+			return Unrecognized, true, nil
+		}
+		return enforcementAction, true, nil
+	}
+	return "", false, nil
+}
+
+func scopeMatchesNamespace(entry map[string]interface{}, namespace string) bool {
+	namespaces, _, err := unstructured.NestedStringSlice(entry, "namespaces")
+	if err != nil {
+		return false
+	}
+	for _, n := range namespaces {
+		if n == "*" || n == namespace {
+			return true
+		}
+		if strings.HasSuffix(n, "*") && strings.HasPrefix(namespace, strings.TrimSuffix(n, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateScopedEnforcementActions validates every namespace-scoped
+// action declared on a constraint.
+func ValidateScopedEnforcementActions(item map[string]interface{}) error {
+	scoped, found, err := unstructured.NestedSlice(item, "spec", "scopedEnforcementActions")
+	if err != nil {
+		return err
+	}
+	if !found {
+		return nil
+	}
+	for i, raw := range scoped {
+		entry, ok := raw.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("scopedEnforcementActions[%d] must be an object with action and namespaces", i)
+		}
+		action, _, err := unstructured.NestedString(entry, "action")
+		if err != nil {
+			return err
+		}
+		if err := ValidateEnforcementAction(EnforcementAction(action)); err != nil {
+			return fmt.Errorf("scopedEnforcementActions[%d]: %v", i, err)
+		}
+		namespaces, _, err := unstructured.NestedStringSlice(entry, "namespaces")
+		if err != nil {
+			return err
+		}
+		if len(namespaces) == 0 {
+			return fmt.Errorf("scopedEnforcementActions[%d] must list at least one namespace", i)
+		}
+	}
+	return nil
+}
+
+// GetEnforcementActionForPoint returns the enforcement action a constraint
+// scopes to the given enforcement point via spec.enforcementActionOverrides,
+// falling back to the global spec.enforcementAction when no override is
+// declared for that point.
+func GetEnforcementActionForPoint(item map[string]interface{}, point EnforcementPoint) (EnforcementAction, error) {
+	override, found, err := unstructured.NestedString(item, "spec", "enforcementActionOverrides", string(point))
+	if err != nil {
+		return "", err
+	}
+	if found && override != "" {
+		enforcementAction := EnforcementAction(override)
+		if err := ValidateEnforcementAction(enforcementAction); err != nil {
+			return Unrecognized, nil
+		}
+		return enforcementAction, nil
+	}
+	return GetEnforcementAction(item)
+}
+
+// ValidateEnforcementActionOverrides validates every per-enforcement-point
+// override declared on a constraint.
+func ValidateEnforcementActionOverrides(item map[string]interface{}) error {
+	overrides, found, err := unstructured.NestedStringMap(item, "spec", "enforcementActionOverrides")
+	if err != nil {
+		return err
+	}
+	if !found {
+		return nil
+	}
+	for point, action := range overrides {
+		if EnforcementPoint(point) != WebhookEnforcementPoint && EnforcementPoint(point) != AuditEnforcementPoint {
+			return fmt.Errorf("enforcement point %q is not recognized, supported enforcement points are [%s, %s]", point, WebhookEnforcementPoint, AuditEnforcementPoint)
+		}
+		if err := ValidateEnforcementAction(EnforcementAction(action)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func GetEnforcementAction(item map[string]interface{}) (EnforcementAction, error) {
 	enforcementActionSpec, _, err := unstructured.NestedString(item, "spec", "enforcementAction")
 	if err != nil {
@@ -18,3 +18,255 @@ func TestValidateEnforcementAction(t *testing.T) {
 		t.Errorf("ValidateEnforcementAction should not error when enforcementAction is recognized, %v", err)
 	}
 }
+
+func TestGetEnforcementActionForPoint(t *testing.T) {
+	item := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"enforcementAction": "deny",
+			"enforcementActionOverrides": map[string]interface{}{
+				"audit": "dryrun",
+			},
+		},
+	}
+
+	action, err := GetEnforcementActionForPoint(item, AuditEnforcementPoint)
+	if err != nil {
+		t.Errorf("GetEnforcementActionForPoint should not error, %v", err)
+	}
+	if action != Dryrun {
+		t.Errorf("expected audit override dryrun, got %v", action)
+	}
+
+	action, err = GetEnforcementActionForPoint(item, WebhookEnforcementPoint)
+	if err != nil {
+		t.Errorf("GetEnforcementActionForPoint should not error, %v", err)
+	}
+	if action != Deny {
+		t.Errorf("expected fallback to global enforcementAction deny, got %v", action)
+	}
+}
+
+func TestGetEnforcementActionForOperation(t *testing.T) {
+	item := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"enforcementAction": "deny",
+			"operationEnforcementActions": map[string]interface{}{
+				"UPDATE": "dryrun",
+				"DELETE": "ignore",
+			},
+		},
+	}
+
+	action, found, err := GetEnforcementActionForOperation(item, "UPDATE")
+	if err != nil {
+		t.Errorf("GetEnforcementActionForOperation should not error, %v", err)
+	}
+	if !found || action != Dryrun {
+		t.Errorf("expected dryrun for UPDATE, got %v (found=%v)", action, found)
+	}
+
+	action, found, err = GetEnforcementActionForOperation(item, "DELETE")
+	if err != nil {
+		t.Errorf("GetEnforcementActionForOperation should not error, %v", err)
+	}
+	if !found || action != Ignore {
+		t.Errorf("expected ignore for DELETE, got %v (found=%v)", action, found)
+	}
+
+	_, found, err = GetEnforcementActionForOperation(item, "CREATE")
+	if err != nil {
+		t.Errorf("GetEnforcementActionForOperation should not error, %v", err)
+	}
+	if found {
+		t.Errorf("no action is declared for CREATE, found should be false")
+	}
+}
+
+func TestValidateOperationEnforcementActions(t *testing.T) {
+	valid := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"operationEnforcementActions": map[string]interface{}{
+				"CREATE": "deny",
+				"UPDATE": "dryrun",
+				"DELETE": "ignore",
+			},
+		},
+	}
+	if err := ValidateOperationEnforcementActions(valid); err != nil {
+		t.Errorf("ValidateOperationEnforcementActions should not error on valid actions, %v", err)
+	}
+
+	badOperation := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"operationEnforcementActions": map[string]interface{}{
+				"PATCH": "deny",
+			},
+		},
+	}
+	if err := ValidateOperationEnforcementActions(badOperation); err == nil {
+		t.Errorf("ValidateOperationEnforcementActions should error on unrecognized operation")
+	}
+
+	badAction := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"operationEnforcementActions": map[string]interface{}{
+				"CREATE": "notsupported",
+			},
+		},
+	}
+	if err := ValidateOperationEnforcementActions(badAction); err == nil {
+		t.Errorf("ValidateOperationEnforcementActions should error on unsupported action")
+	}
+}
+
+func TestValidateEnforcementActionOverrides(t *testing.T) {
+	valid := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"enforcementActionOverrides": map[string]interface{}{
+				"webhook": "deny",
+				"audit":   "dryrun",
+			},
+		},
+	}
+	if err := ValidateEnforcementActionOverrides(valid); err != nil {
+		t.Errorf("ValidateEnforcementActionOverrides should not error on valid overrides, %v", err)
+	}
+
+	badAction := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"enforcementActionOverrides": map[string]interface{}{
+				"webhook": "notsupported",
+			},
+		},
+	}
+	if err := ValidateEnforcementActionOverrides(badAction); err == nil {
+		t.Errorf("ValidateEnforcementActionOverrides should error on unsupported action")
+	}
+
+	badPoint := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"enforcementActionOverrides": map[string]interface{}{
+				"notapoint": "deny",
+			},
+		},
+	}
+	if err := ValidateEnforcementActionOverrides(badPoint); err == nil {
+		t.Errorf("ValidateEnforcementActionOverrides should error on unrecognized enforcement point")
+	}
+}
+
+func TestGetEnforcementActionForNamespace(t *testing.T) {
+	item := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"enforcementAction": "dryrun",
+			"scopedEnforcementActions": []interface{}{
+				map[string]interface{}{
+					"action":     "deny",
+					"namespaces": []interface{}{"prod-*"},
+				},
+				map[string]interface{}{
+					"action":     "dryrun",
+					"namespaces": []interface{}{"dev", "staging"},
+				},
+			},
+		},
+	}
+
+	action, found, err := GetEnforcementActionForNamespace(item, "prod-payments")
+	if err != nil {
+		t.Errorf("GetEnforcementActionForNamespace should not error, %v", err)
+	}
+	if !found || action != Deny {
+		t.Errorf("expected deny for prod-payments, got %v (found=%v)", action, found)
+	}
+
+	action, found, err = GetEnforcementActionForNamespace(item, "dev")
+	if err != nil {
+		t.Errorf("GetEnforcementActionForNamespace should not error, %v", err)
+	}
+	if !found || action != Dryrun {
+		t.Errorf("expected dryrun for dev, got %v (found=%v)", action, found)
+	}
+
+	_, found, err = GetEnforcementActionForNamespace(item, "sandbox")
+	if err != nil {
+		t.Errorf("GetEnforcementActionForNamespace should not error, %v", err)
+	}
+	if found {
+		t.Errorf("expected no scoped action for sandbox")
+	}
+
+	unscoped := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"enforcementAction": "deny",
+		},
+	}
+	_, found, err = GetEnforcementActionForNamespace(unscoped, "prod-payments")
+	if err != nil {
+		t.Errorf("GetEnforcementActionForNamespace should not error, %v", err)
+	}
+	if found {
+		t.Errorf("expected no scoped action when none are declared")
+	}
+
+	wildcard := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"scopedEnforcementActions": []interface{}{
+				map[string]interface{}{
+					"action":     "deny",
+					"namespaces": []interface{}{"*"},
+				},
+			},
+		},
+	}
+	action, found, err = GetEnforcementActionForNamespace(wildcard, "anything")
+	if err != nil {
+		t.Errorf("GetEnforcementActionForNamespace should not error, %v", err)
+	}
+	if !found || action != Deny {
+		t.Errorf("expected deny from wildcard entry, got %v (found=%v)", action, found)
+	}
+}
+
+func TestValidateScopedEnforcementActions(t *testing.T) {
+	valid := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"scopedEnforcementActions": []interface{}{
+				map[string]interface{}{
+					"action":     "deny",
+					"namespaces": []interface{}{"prod-*"},
+				},
+			},
+		},
+	}
+	if err := ValidateScopedEnforcementActions(valid); err != nil {
+		t.Errorf("ValidateScopedEnforcementActions should not error on valid entries, %v", err)
+	}
+
+	badAction := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"scopedEnforcementActions": []interface{}{
+				map[string]interface{}{
+					"action":     "notsupported",
+					"namespaces": []interface{}{"prod-*"},
+				},
+			},
+		},
+	}
+	if err := ValidateScopedEnforcementActions(badAction); err == nil {
+		t.Errorf("ValidateScopedEnforcementActions should error on unsupported action")
+	}
+
+	noNamespaces := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"scopedEnforcementActions": []interface{}{
+				map[string]interface{}{
+					"action": "deny",
+				},
+			},
+		},
+	}
+	if err := ValidateScopedEnforcementActions(noNamespaces); err == nil {
+		t.Errorf("ValidateScopedEnforcementActions should error when no namespaces are listed")
+	}
+}
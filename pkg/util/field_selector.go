@@ -0,0 +1,165 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// constraints may declare spec.match.fieldSelectors, a list of simple
+// field criteria evaluated against the reviewed object before results
+// are enforced. This scopes constraints more precisely (e.g. only pods
+// with spec.nodeName set, or objects carrying a given annotation)
+// without requiring Rego changes.
+
+// supported field selector operators
+const (
+	FieldOpExists    = "Exists"
+	FieldOpNotExists = "NotExists"
+	FieldOpEquals    = "Equals"
+	FieldOpNotEquals = "NotEquals"
+)
+
+var supportedFieldOperators = []string{FieldOpExists, FieldOpNotExists, FieldOpEquals, FieldOpNotEquals}
+
+// FieldSelectorsMatch evaluates every field selector declared on the
+// constraint against the object; all selectors must match. Constraints
+// without field selectors match every object.
+func FieldSelectorsMatch(constraint map[string]interface{}, obj map[string]interface{}) (bool, error) {
+	selectors, found, err := unstructured.NestedSlice(constraint, "spec", "match", "fieldSelectors")
+	if err != nil {
+		return false, err
+	}
+	if !found {
+		return true, nil
+	}
+	for _, s := range selectors {
+		selector, ok := s.(map[string]interface{})
+		if !ok {
+			return false, fmt.Errorf("field selector must be an object, got %T", s)
+		}
+		matched, err := fieldSelectorMatches(selector, obj)
+		if err != nil {
+			return false, err
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// ValidateFieldSelectors validates every field selector declared on a
+// constraint.
+func ValidateFieldSelectors(constraint map[string]interface{}) error {
+	selectors, found, err := unstructured.NestedSlice(constraint, "spec", "match", "fieldSelectors")
+	if err != nil {
+		return err
+	}
+	if !found {
+		return nil
+	}
+	for _, s := range selectors {
+		selector, ok := s.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("field selector must be an object, got %T", s)
+		}
+		field, _ := selector["field"].(string)
+		if field == "" {
+			return fmt.Errorf("field selector must declare a field path")
+		}
+		if _, err := parseFieldPath(field); err != nil {
+			return err
+		}
+		op, _ := selector["operator"].(string)
+		switch op {
+		case FieldOpExists, FieldOpNotExists:
+		case FieldOpEquals, FieldOpNotEquals:
+			if _, ok := selector["value"]; !ok {
+				return fmt.Errorf("field selector operator %s requires a value", op)
+			}
+		default:
+			return fmt.Errorf("field selector operator %q is not recognized, supported operators are %v", op, supportedFieldOperators)
+		}
+	}
+	return nil
+}
+
+func fieldSelectorMatches(selector, obj map[string]interface{}) (bool, error) {
+	field, _ := selector["field"].(string)
+	if field == "" {
+		return false, fmt.Errorf("field selector must declare a field path")
+	}
+	path, err := parseFieldPath(field)
+	if err != nil {
+		return false, err
+	}
+	value, exists := resolveFieldPath(obj, path)
+	op, _ := selector["operator"].(string)
+	switch op {
+	case FieldOpExists:
+		return exists, nil
+	case FieldOpNotExists:
+		return !exists, nil
+	case FieldOpEquals:
+		return exists && fmt.Sprintf("%v", value) == fmt.Sprintf("%v", selector["value"]), nil
+	case FieldOpNotEquals:
+		return !exists || fmt.Sprintf("%v", value) != fmt.Sprintf("%v", selector["value"]), nil
+	default:
+		return false, fmt.Errorf("field selector operator %q is not recognized, supported operators are %v", op, supportedFieldOperators)
+	}
+}
+
+// parseFieldPath splits a field path such as
+// `metadata.annotations['example.com/key']` into its segments. Dots
+// separate segments except inside a bracketed, quoted key.
+func parseFieldPath(field string) ([]string, error) {
+	var segments []string
+	rest := field
+	for rest != "" {
+		if strings.HasPrefix(rest, "['") {
+			end := strings.Index(rest, "']")
+			if end < 0 {
+				return nil, fmt.Errorf("field path %q has an unterminated bracketed key", field)
+			}
+			segments = append(segments, rest[2:end])
+			rest = strings.TrimPrefix(rest[end+2:], ".")
+			continue
+		}
+		next := len(rest)
+		if i := strings.IndexAny(rest, ".["); i >= 0 {
+			next = i
+		}
+		if next == 0 {
+			return nil, fmt.Errorf("field path %q has an empty segment", field)
+		}
+		segments = append(segments, rest[:next])
+		rest = rest[next:]
+		rest = strings.TrimPrefix(rest, ".")
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("field path must not be empty")
+	}
+	return segments, nil
+}
+
+func resolveFieldPath(obj map[string]interface{}, path []string) (interface{}, bool) {
+	var current interface{} = obj
+	for _, segment := range path {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[segment]
+		if !ok {
+			return nil, false
+		}
+	}
+	// an empty string is treated as unset, so `!= ""` style criteria can
+	// be expressed with Exists
+	if s, ok := current.(string); ok && s == "" {
+		return nil, false
+	}
+	return current, true
+}
@@ -0,0 +1,170 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func makeFieldSelectors(selectors ...map[string]interface{}) map[string]interface{} {
+	list := make([]interface{}, 0, len(selectors))
+	for _, s := range selectors {
+		list = append(list, interface{}(s))
+	}
+	return map[string]interface{}{
+		"spec": map[string]interface{}{
+			"match": map[string]interface{}{
+				"fieldSelectors": list,
+			},
+		},
+	}
+}
+
+func TestParseFieldPath(t *testing.T) {
+	tc := []struct {
+		field    string
+		expected []string
+		wantErr  bool
+	}{
+		{field: "spec.nodeName", expected: []string{"spec", "nodeName"}},
+		{field: "metadata.annotations['example.com/key']", expected: []string{"metadata", "annotations", "example.com/key"}},
+		{field: "metadata.labels['a'].x", expected: []string{"metadata", "labels", "a", "x"}},
+		{field: "", wantErr: true},
+		{field: "metadata.annotations['unterminated", wantErr: true},
+		{field: "a..b", wantErr: true},
+	}
+	for _, tt := range tc {
+		t.Run(tt.field, func(t *testing.T) {
+			got, err := parseFieldPath(tt.field)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error parsing %q", tt.field)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFieldSelectorsMatch(t *testing.T) {
+	pod := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name": "busybox",
+			"annotations": map[string]interface{}{
+				"example.com/team": "platform",
+			},
+		},
+		"spec": map[string]interface{}{
+			"nodeName": "node-1",
+		},
+	}
+
+	tc := []struct {
+		name     string
+		selector map[string]interface{}
+		expected bool
+	}{
+		{
+			name:     "exists on set field",
+			selector: map[string]interface{}{"field": "spec.nodeName", "operator": "Exists"},
+			expected: true,
+		},
+		{
+			name:     "exists on missing field",
+			selector: map[string]interface{}{"field": "spec.serviceAccountName", "operator": "Exists"},
+			expected: false,
+		},
+		{
+			name:     "notexists on missing field",
+			selector: map[string]interface{}{"field": "spec.serviceAccountName", "operator": "NotExists"},
+			expected: true,
+		},
+		{
+			name:     "equals",
+			selector: map[string]interface{}{"field": "spec.nodeName", "operator": "Equals", "value": "node-1"},
+			expected: true,
+		},
+		{
+			name:     "equals mismatch",
+			selector: map[string]interface{}{"field": "spec.nodeName", "operator": "Equals", "value": "node-2"},
+			expected: false,
+		},
+		{
+			name:     "notequals",
+			selector: map[string]interface{}{"field": "spec.nodeName", "operator": "NotEquals", "value": "node-2"},
+			expected: true,
+		},
+		{
+			name:     "bracketed annotation key",
+			selector: map[string]interface{}{"field": "metadata.annotations['example.com/team']", "operator": "Equals", "value": "platform"},
+			expected: true,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FieldSelectorsMatch(makeFieldSelectors(tt.selector), pod)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFieldSelectorsMatchNoSelectors(t *testing.T) {
+	matched, err := FieldSelectorsMatch(map[string]interface{}{"spec": map[string]interface{}{}}, map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !matched {
+		t.Errorf("constraints without field selectors should match every object")
+	}
+}
+
+func TestFieldSelectorsMatchEmptyStringTreatedAsUnset(t *testing.T) {
+	obj := map[string]interface{}{
+		"spec": map[string]interface{}{"nodeName": ""},
+	}
+	matched, err := FieldSelectorsMatch(makeFieldSelectors(
+		map[string]interface{}{"field": "spec.nodeName", "operator": "Exists"},
+	), obj)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if matched {
+		t.Errorf("empty string fields should be treated as unset")
+	}
+}
+
+func TestValidateFieldSelectors(t *testing.T) {
+	valid := makeFieldSelectors(
+		map[string]interface{}{"field": "spec.nodeName", "operator": "Exists"},
+		map[string]interface{}{"field": "spec.nodeName", "operator": "Equals", "value": "node-1"},
+	)
+	if err := ValidateFieldSelectors(valid); err != nil {
+		t.Errorf("ValidateFieldSelectors should not error on valid selectors, %v", err)
+	}
+
+	if err := ValidateFieldSelectors(makeFieldSelectors(
+		map[string]interface{}{"field": "spec.nodeName", "operator": "Matches"},
+	)); err == nil {
+		t.Errorf("ValidateFieldSelectors should error on unrecognized operator")
+	}
+	if err := ValidateFieldSelectors(makeFieldSelectors(
+		map[string]interface{}{"field": "spec.nodeName", "operator": "Equals"},
+	)); err == nil {
+		t.Errorf("ValidateFieldSelectors should error when Equals has no value")
+	}
+	if err := ValidateFieldSelectors(makeFieldSelectors(
+		map[string]interface{}{"operator": "Exists"},
+	)); err == nil {
+		t.Errorf("ValidateFieldSelectors should error when no field is declared")
+	}
+}
@@ -0,0 +1,90 @@
+package util
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// maintenance windows are declared on constraints under
+// spec.maintenanceWindows as a list of {start, end} RFC3339 timestamps.
+// While a window is active the constraint's enforcement action is relaxed
+// to dryrun so planned maintenance is not blocked, with the relaxation
+// logged by the enforcement points.
+
+// InMaintenanceWindow returns true if the given time falls inside any
+// maintenance window declared on the constraint.
+func InMaintenanceWindow(item map[string]interface{}, now time.Time) (bool, error) {
+	windows, found, err := unstructured.NestedSlice(item, "spec", "maintenanceWindows")
+	if err != nil {
+		return false, err
+	}
+	if !found {
+		return false, nil
+	}
+	for _, w := range windows {
+		window, ok := w.(map[string]interface{})
+		if !ok {
+			return false, fmt.Errorf("maintenance window must be an object, got %T", w)
+		}
+		start, end, err := parseWindow(window)
+		if err != nil {
+			return false, err
+		}
+		if !now.Before(start) && now.Before(end) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ValidateMaintenanceWindows validates every maintenance window declared
+// on a constraint.
+func ValidateMaintenanceWindows(item map[string]interface{}) error {
+	windows, found, err := unstructured.NestedSlice(item, "spec", "maintenanceWindows")
+	if err != nil {
+		return err
+	}
+	if !found {
+		return nil
+	}
+	for _, w := range windows {
+		window, ok := w.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("maintenance window must be an object, got %T", w)
+		}
+		start, end, err := parseWindow(window)
+		if err != nil {
+			return err
+		}
+		if !end.After(start) {
+			return fmt.Errorf("maintenance window end %v must be after start %v", end, start)
+		}
+	}
+	return nil
+}
+
+func parseWindow(window map[string]interface{}) (time.Time, time.Time, error) {
+	start, err := parseWindowTime(window, "start")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := parseWindowTime(window, "end")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, end, nil
+}
+
+func parseWindowTime(window map[string]interface{}, field string) (time.Time, error) {
+	raw, ok := window[field].(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("maintenance window is missing required field %q", field)
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("maintenance window %s %q is not a valid RFC3339 timestamp: %v", field, raw, err)
+	}
+	return t, nil
+}
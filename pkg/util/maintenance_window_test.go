@@ -0,0 +1,77 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func makeWindowed(windows ...map[string]interface{}) map[string]interface{} {
+	list := make([]interface{}, 0, len(windows))
+	for _, w := range windows {
+		list = append(list, interface{}(w))
+	}
+	return map[string]interface{}{
+		"spec": map[string]interface{}{
+			"maintenanceWindows": list,
+		},
+	}
+}
+
+func TestInMaintenanceWindow(t *testing.T) {
+	item := makeWindowed(map[string]interface{}{
+		"start": "2021-01-01T00:00:00Z",
+		"end":   "2021-01-01T04:00:00Z",
+	})
+
+	inside, err := InMaintenanceWindow(item, time.Date(2021, 1, 1, 2, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !inside {
+		t.Errorf("expected time inside window to match")
+	}
+
+	outside, err := InMaintenanceWindow(item, time.Date(2021, 1, 1, 5, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if outside {
+		t.Errorf("expected time outside window not to match")
+	}
+}
+
+func TestInMaintenanceWindowNoWindows(t *testing.T) {
+	inside, err := InMaintenanceWindow(map[string]interface{}{"spec": map[string]interface{}{}}, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if inside {
+		t.Errorf("constraint without windows should never be in maintenance")
+	}
+}
+
+func TestValidateMaintenanceWindows(t *testing.T) {
+	valid := makeWindowed(map[string]interface{}{
+		"start": "2021-01-01T00:00:00Z",
+		"end":   "2021-01-01T04:00:00Z",
+	})
+	if err := ValidateMaintenanceWindows(valid); err != nil {
+		t.Errorf("ValidateMaintenanceWindows should not error on valid windows, %v", err)
+	}
+
+	badTimestamp := makeWindowed(map[string]interface{}{
+		"start": "not-a-timestamp",
+		"end":   "2021-01-01T04:00:00Z",
+	})
+	if err := ValidateMaintenanceWindows(badTimestamp); err == nil {
+		t.Errorf("ValidateMaintenanceWindows should error on malformed timestamps")
+	}
+
+	inverted := makeWindowed(map[string]interface{}{
+		"start": "2021-01-01T04:00:00Z",
+		"end":   "2021-01-01T00:00:00Z",
+	})
+	if err := ValidateMaintenanceWindows(inverted); err == nil {
+		t.Errorf("ValidateMaintenanceWindows should error when end precedes start")
+	}
+}
@@ -0,0 +1,89 @@
+package util
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// constraints may declare spec.match.namespaceSubtrees and
+// spec.match.excludedNamespaceSubtrees, lists of namespace names
+// interpreted against the hierarchy labels maintained by the
+// Hierarchical Namespace Controller. A namespace is in the subtree of a
+// root when it is the root itself or carries the root's
+// <root>.tree.hnc.x-k8s.io/depth label, so policies and exemptions
+// written against a tenant's parent namespace cover every descendant
+// without per-child duplication.
+
+// hncDepthLabelSuffix is the suffix of the per-ancestor tree labels HNC
+// maintains on every namespace in a subtree.
+const hncDepthLabelSuffix = ".tree.hnc.x-k8s.io/depth"
+
+// InNamespaceSubtree returns true if the namespace is the subtree root
+// or one of its descendants.
+func InNamespaceSubtree(root, namespace string, namespaceLabels map[string]string) bool {
+	if namespace == root {
+		return true
+	}
+	_, found := namespaceLabels[root+hncDepthLabelSuffix]
+	return found
+}
+
+// MatchesNamespaceSubtrees returns true if the constraint's subtree
+// match criteria apply to the given namespace. Constraints without
+// subtree criteria match everywhere; cluster-scoped objects (an empty
+// namespace) never match a subtree and are never excluded by one.
+func MatchesNamespaceSubtrees(constraint map[string]interface{}, namespace string, namespaceLabels map[string]string) (bool, error) {
+	subtrees, found, err := unstructured.NestedStringSlice(constraint, "spec", "match", "namespaceSubtrees")
+	if err != nil {
+		return false, err
+	}
+	if found && len(subtrees) > 0 {
+		if namespace == "" {
+			return false, nil
+		}
+		matched := false
+		for _, root := range subtrees {
+			if InNamespaceSubtree(root, namespace, namespaceLabels) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+
+	excluded, found, err := unstructured.NestedStringSlice(constraint, "spec", "match", "excludedNamespaceSubtrees")
+	if err != nil {
+		return false, err
+	}
+	if found && namespace != "" {
+		for _, root := range excluded {
+			if InNamespaceSubtree(root, namespace, namespaceLabels) {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// ValidateNamespaceSubtrees validates the subtree match criteria
+// declared on a constraint.
+func ValidateNamespaceSubtrees(constraint map[string]interface{}) error {
+	for _, field := range []string{"namespaceSubtrees", "excludedNamespaceSubtrees"} {
+		subtrees, found, err := unstructured.NestedStringSlice(constraint, "spec", "match", field)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		for _, root := range subtrees {
+			if root == "" {
+				return fmt.Errorf("%s entries must be namespace names", field)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package util
+
+import "testing"
+
+func makeSubtreeMatch(field string, roots []interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"spec": map[string]interface{}{
+			"match": map[string]interface{}{
+				field: roots,
+			},
+		},
+	}
+}
+
+func TestInNamespaceSubtree(t *testing.T) {
+	childLabels := map[string]string{
+		"tenant-a.tree.hnc.x-k8s.io/depth":      "1",
+		"tenant-a-team.tree.hnc.x-k8s.io/depth": "0",
+	}
+	if !InNamespaceSubtree("tenant-a", "tenant-a", nil) {
+		t.Errorf("a subtree root should be in its own subtree")
+	}
+	if !InNamespaceSubtree("tenant-a", "tenant-a-team", childLabels) {
+		t.Errorf("a labeled descendant should be in the subtree")
+	}
+	if InNamespaceSubtree("tenant-b", "tenant-a-team", childLabels) {
+		t.Errorf("namespaces outside the subtree should not match")
+	}
+}
+
+func TestMatchesNamespaceSubtrees(t *testing.T) {
+	childLabels := map[string]string{"tenant-a.tree.hnc.x-k8s.io/depth": "1"}
+
+	tc := []struct {
+		name       string
+		constraint map[string]interface{}
+		namespace  string
+		nsLabels   map[string]string
+		expected   bool
+	}{
+		{
+			name:       "no subtree criteria matches everywhere",
+			constraint: map[string]interface{}{"spec": map[string]interface{}{}},
+			namespace:  "anything",
+			expected:   true,
+		},
+		{
+			name:       "descendant matches subtree",
+			constraint: makeSubtreeMatch("namespaceSubtrees", []interface{}{"tenant-a"}),
+			namespace:  "tenant-a-team",
+			nsLabels:   childLabels,
+			expected:   true,
+		},
+		{
+			name:       "namespace outside subtree does not match",
+			constraint: makeSubtreeMatch("namespaceSubtrees", []interface{}{"tenant-a"}),
+			namespace:  "tenant-b",
+			expected:   false,
+		},
+		{
+			name:       "cluster-scoped objects never match a subtree",
+			constraint: makeSubtreeMatch("namespaceSubtrees", []interface{}{"tenant-a"}),
+			namespace:  "",
+			expected:   false,
+		},
+		{
+			name:       "exclusion inherited by descendants",
+			constraint: makeSubtreeMatch("excludedNamespaceSubtrees", []interface{}{"tenant-a"}),
+			namespace:  "tenant-a-team",
+			nsLabels:   childLabels,
+			expected:   false,
+		},
+		{
+			name:       "exclusion does not reach other namespaces",
+			constraint: makeSubtreeMatch("excludedNamespaceSubtrees", []interface{}{"tenant-a"}),
+			namespace:  "tenant-b",
+			expected:   true,
+		},
+		{
+			name:       "exclusion does not affect cluster-scoped objects",
+			constraint: makeSubtreeMatch("excludedNamespaceSubtrees", []interface{}{"tenant-a"}),
+			namespace:  "",
+			expected:   true,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MatchesNamespaceSubtrees(tt.constraint, tt.namespace, tt.nsLabels)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestValidateNamespaceSubtrees(t *testing.T) {
+	if err := ValidateNamespaceSubtrees(makeSubtreeMatch("namespaceSubtrees", []interface{}{"tenant-a"})); err != nil {
+		t.Errorf("ValidateNamespaceSubtrees should not error on valid criteria, %v", err)
+	}
+	if err := ValidateNamespaceSubtrees(map[string]interface{}{"spec": map[string]interface{}{}}); err != nil {
+		t.Errorf("ValidateNamespaceSubtrees should not error when no criteria are declared, %v", err)
+	}
+	if err := ValidateNamespaceSubtrees(makeSubtreeMatch("namespaceSubtrees", []interface{}{""})); err == nil {
+		t.Errorf("ValidateNamespaceSubtrees should error on empty namespace names")
+	}
+	if err := ValidateNamespaceSubtrees(makeSubtreeMatch("excludedNamespaceSubtrees", []interface{}{int64(1)})); err == nil {
+		t.Errorf("ValidateNamespaceSubtrees should error on non-string entries")
+	}
+}
@@ -0,0 +1,26 @@
+package util
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// OwnerAnnotation names the team or individual responsible for a
+	// constraint and its findings.
+	OwnerAnnotation = "policies.gatekeeper.sh/owner"
+	// ContactAnnotation is a machine-routable contact for the owner of a
+	// constraint, e.g. an email address or chat channel.
+	ContactAnnotation = "policies.gatekeeper.sh/contact"
+)
+
+// GetConstraintOwner returns the owner and contact declared on a
+// constraint, if any. Constraints are arbitrary CRs whose spec schema is
+// owned by their template, so ownership is carried as annotations rather
+// than spec fields.
+func GetConstraintOwner(constraint *unstructured.Unstructured) (owner string, contact string) {
+	annotations := constraint.GetAnnotations()
+	if annotations == nil {
+		return "", ""
+	}
+	return annotations[OwnerAnnotation], annotations[ContactAnnotation]
+}
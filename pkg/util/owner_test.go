@@ -0,0 +1,51 @@
+package util
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestGetConstraintOwner(t *testing.T) {
+	tc := []struct {
+		name            string
+		annotations     map[string]interface{}
+		expectedOwner   string
+		expectedContact string
+	}{
+		{
+			name: "no annotations",
+		},
+		{
+			name: "owner only",
+			annotations: map[string]interface{}{
+				OwnerAnnotation: "platform-team",
+			},
+			expectedOwner: "platform-team",
+		},
+		{
+			name: "owner and contact",
+			annotations: map[string]interface{}{
+				OwnerAnnotation:   "platform-team",
+				ContactAnnotation: "platform@example.com",
+			},
+			expectedOwner:   "platform-team",
+			expectedContact: "platform@example.com",
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			constraint := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			if tt.annotations != nil {
+				constraint.Object["metadata"] = map[string]interface{}{"annotations": tt.annotations}
+			}
+			owner, contact := GetConstraintOwner(constraint)
+			if owner != tt.expectedOwner {
+				t.Errorf("owner: expected %q, got %q", tt.expectedOwner, owner)
+			}
+			if contact != tt.expectedContact {
+				t.Errorf("contact: expected %q, got %q", tt.expectedContact, contact)
+			}
+		})
+	}
+}
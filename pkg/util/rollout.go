@@ -0,0 +1,46 @@
+package util
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// constraints may declare spec.rolloutPercentage (0-100) to progressively
+// roll out deny enforcement. A deterministic hash of the reviewed object's
+// identity decides whether a given object falls inside the enforced
+// percentage; objects outside it are downgraded to dryrun so risky
+// policies can be introduced gradually on busy clusters.
+
+// RolloutEnforced returns true when the object identified by key falls
+// within the constraint's enforced rollout percentage. Constraints that
+// declare no rolloutPercentage enforce for every object.
+func RolloutEnforced(item map[string]interface{}, key string) (bool, error) {
+	percentage, found, err := unstructured.NestedInt64(item, "spec", "rolloutPercentage")
+	if err != nil {
+		return false, err
+	}
+	if !found || percentage >= 100 {
+		return true, nil
+	}
+	if percentage <= 0 {
+		return false, nil
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int64(h.Sum32()%100) < percentage, nil
+}
+
+// ValidateRolloutPercentage validates the rolloutPercentage declared on a
+// constraint, if any.
+func ValidateRolloutPercentage(item map[string]interface{}) error {
+	percentage, found, err := unstructured.NestedInt64(item, "spec", "rolloutPercentage")
+	if err != nil {
+		return err
+	}
+	if found && (percentage < 0 || percentage > 100) {
+		return fmt.Errorf("rolloutPercentage must be between 0 and 100, got %d", percentage)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package util
+
+import (
+	"fmt"
+	"testing"
+)
+
+func makeRollout(percentage int64) map[string]interface{} {
+	return map[string]interface{}{
+		"spec": map[string]interface{}{
+			"rolloutPercentage": percentage,
+		},
+	}
+}
+
+func TestRolloutEnforced(t *testing.T) {
+	noRollout := map[string]interface{}{"spec": map[string]interface{}{}}
+	enforced, err := RolloutEnforced(noRollout, "Pod/default/foo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !enforced {
+		t.Errorf("constraints without rolloutPercentage should always enforce")
+	}
+
+	enforced, err = RolloutEnforced(makeRollout(0), "Pod/default/foo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if enforced {
+		t.Errorf("0 percent rollout should never enforce")
+	}
+
+	enforced, err = RolloutEnforced(makeRollout(100), "Pod/default/foo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !enforced {
+		t.Errorf("100 percent rollout should always enforce")
+	}
+}
+
+func TestRolloutEnforcedDeterministic(t *testing.T) {
+	item := makeRollout(50)
+	first, err := RolloutEnforced(item, "Pod/default/foo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		got, err := RolloutEnforced(item, "Pod/default/foo")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != first {
+			t.Fatalf("rollout decision must be deterministic for the same object")
+		}
+	}
+}
+
+func TestRolloutEnforcedSplitsObjects(t *testing.T) {
+	item := makeRollout(50)
+	enforcedCount := 0
+	total := 1000
+	for i := 0; i < total; i++ {
+		enforced, err := RolloutEnforced(item, fmt.Sprintf("Pod/default/pod-%d", i))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if enforced {
+			enforcedCount++
+		}
+	}
+	// the hash should spread objects roughly evenly across the percentage
+	if enforcedCount < total/4 || enforcedCount > 3*total/4 {
+		t.Errorf("expected roughly half of objects enforced, got %d of %d", enforcedCount, total)
+	}
+}
+
+func TestValidateRolloutPercentage(t *testing.T) {
+	if err := ValidateRolloutPercentage(makeRollout(50)); err != nil {
+		t.Errorf("ValidateRolloutPercentage should not error on valid percentage, %v", err)
+	}
+	if err := ValidateRolloutPercentage(makeRollout(101)); err == nil {
+		t.Errorf("ValidateRolloutPercentage should error on percentage above 100")
+	}
+	if err := ValidateRolloutPercentage(makeRollout(-1)); err == nil {
+		t.Errorf("ValidateRolloutPercentage should error on negative percentage")
+	}
+}
@@ -0,0 +1,38 @@
+package util
+
+import (
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// constraints may declare spec.exemptUsers and spec.exemptGroups, lists
+// of requesting users and groups whose requests bypass the constraint.
+// This covers cases namespace exemptions cannot, e.g. a break-glass
+// group that must be able to operate across all namespaces.
+
+// UserExempt returns true when the requesting user or one of their
+// groups is listed in the constraint's exemption lists.
+func UserExempt(item map[string]interface{}, userInfo authenticationv1.UserInfo) (bool, error) {
+	users, _, err := unstructured.NestedStringSlice(item, "spec", "exemptUsers")
+	if err != nil {
+		return false, err
+	}
+	for _, u := range users {
+		if u == userInfo.Username {
+			return true, nil
+		}
+	}
+
+	groups, _, err := unstructured.NestedStringSlice(item, "spec", "exemptGroups")
+	if err != nil {
+		return false, err
+	}
+	for _, g := range groups {
+		for _, ug := range userInfo.Groups {
+			if g == ug {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,69 @@
+package util
+
+import (
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+func makeUserExemptions(users, groups []interface{}) map[string]interface{} {
+	spec := map[string]interface{}{}
+	if users != nil {
+		spec["exemptUsers"] = users
+	}
+	if groups != nil {
+		spec["exemptGroups"] = groups
+	}
+	return map[string]interface{}{"spec": spec}
+}
+
+func TestUserExempt(t *testing.T) {
+	tc := []struct {
+		name     string
+		item     map[string]interface{}
+		userInfo authenticationv1.UserInfo
+		expected bool
+	}{
+		{
+			name:     "no exemption lists",
+			item:     makeUserExemptions(nil, nil),
+			userInfo: authenticationv1.UserInfo{Username: "alice"},
+			expected: false,
+		},
+		{
+			name:     "exempt user",
+			item:     makeUserExemptions([]interface{}{"alice"}, nil),
+			userInfo: authenticationv1.UserInfo{Username: "alice"},
+			expected: true,
+		},
+		{
+			name:     "other user not exempt",
+			item:     makeUserExemptions([]interface{}{"alice"}, nil),
+			userInfo: authenticationv1.UserInfo{Username: "bob"},
+			expected: false,
+		},
+		{
+			name:     "exempt group",
+			item:     makeUserExemptions(nil, []interface{}{"system:cluster-admins"}),
+			userInfo: authenticationv1.UserInfo{Username: "bob", Groups: []string{"system:authenticated", "system:cluster-admins"}},
+			expected: true,
+		},
+		{
+			name:     "no group overlap",
+			item:     makeUserExemptions(nil, []interface{}{"system:cluster-admins"}),
+			userInfo: authenticationv1.UserInfo{Username: "bob", Groups: []string{"system:authenticated"}},
+			expected: false,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UserExempt(tt.item, tt.userInfo)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,305 @@
+// Package vapgen generates ValidatingAdmissionPolicy and
+// ValidatingAdmissionPolicyBinding resources from constraint templates
+// that declare CEL validations, so simple checks run in-process in the
+// API server while Gatekeeper retains audit and reporting for the same
+// constraints. A template opts in by carrying the
+// gatekeeper.sh/cel-validations annotation: a JSON list of
+// {expression, message} pairs equivalent to its Rego. One policy is
+// generated per such template and one binding per constraint, scoped to
+// the constraint's match kinds. The generated objects are labeled and
+// pruned when their template or constraint goes away. The
+// admissionregistration API is addressed unstructured because the
+// cluster may be newer than the compiled-in client types.
+package vapgen
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/open-policy-agent/frameworks/constraint/pkg/apis/templates/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+var log = logf.Log.WithName("vap-generator")
+
+// CELValidationsAnnotation is set on a constraint template to declare
+// CEL validations equivalent to its Rego, as a JSON list of
+// {expression, message} objects.
+const CELValidationsAnnotation = "gatekeeper.sh/cel-validations"
+
+// generatedLabel marks objects this generator owns, so pruning never
+// touches hand-written policies.
+const generatedLabel = "gatekeeper.sh/vap-generated"
+
+// syncPeriod is how often generated policies are reconciled.
+const syncPeriod = 30 * time.Second
+
+var (
+	policyGVK    = schema.GroupVersionKind{Group: "admissionregistration.k8s.io", Version: "v1alpha1", Kind: "ValidatingAdmissionPolicy"}
+	bindingGVK   = schema.GroupVersionKind{Group: "admissionregistration.k8s.io", Version: "v1alpha1", Kind: "ValidatingAdmissionPolicyBinding"}
+	constraintGV = schema.GroupVersion{Group: "constraints.gatekeeper.sh", Version: "v1beta1"}
+)
+
+var generateVAP = flag.Bool("generate-vap", false, "(alpha) generate ValidatingAdmissionPolicy and Binding resources for constraint templates annotated with "+CELValidationsAnnotation+", so their checks also run in-process in the API server")
+
+// Enabled returns true if VAP generation was requested via flags.
+func Enabled() bool {
+	return *generateVAP
+}
+
+// Generator reconciles generated policies with the current templates
+// and constraints. It implements manager.Runnable.
+type Generator struct {
+	client client.Client
+}
+
+// AddGenerator registers the generator with the manager.
+func AddGenerator(mgr manager.Manager) error {
+	return mgr.Add(&Generator{client: mgr.GetClient()})
+}
+
+// Validation is one CEL check declared on a template.
+type Validation struct {
+	Expression string `json:"expression"`
+	Message    string `json:"message,omitempty"`
+}
+
+// Start implements manager.Runnable.
+func (g *Generator) Start(ctx context.Context) error {
+	ticker := time.NewTicker(syncPeriod)
+	defer ticker.Stop()
+	for {
+		if err := g.sync(ctx); err != nil {
+			log.Error(err, "unable to reconcile generated admission policies")
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// sync regenerates policies and bindings and prunes stale ones.
+func (g *Generator) sync(ctx context.Context) error {
+	templates := &v1beta1.ConstraintTemplateList{}
+	if err := g.client.List(ctx, templates); err != nil {
+		return fmt.Errorf("listing constraint templates: %w", err)
+	}
+
+	want := map[string]*unstructured.Unstructured{}
+	for i := range templates.Items {
+		template := &templates.Items[i]
+		validations, err := ParseValidations(template.GetAnnotations()[CELValidationsAnnotation])
+		if err != nil {
+			log.Error(err, "skipping template with invalid CEL validations", "template", template.GetName())
+			continue
+		}
+		if len(validations) == 0 {
+			continue
+		}
+		kind := template.Spec.CRD.Spec.Names.Kind
+		policy := BuildPolicy(template.GetName(), validations)
+		want[policy.GetName()] = policy
+
+		constraints := &unstructured.UnstructuredList{}
+		constraints.SetGroupVersionKind(constraintGV.WithKind(kind + "List"))
+		if err := g.client.List(ctx, constraints); err != nil {
+			// The constraint CRD may not be established yet.
+			log.V(1).Info("unable to list constraints for generated policy", "kind", kind, "error", err.Error())
+			continue
+		}
+		for j := range constraints.Items {
+			binding := BuildBinding(policy.GetName(), &constraints.Items[j])
+			want[binding.GetName()] = binding
+		}
+	}
+
+	for _, obj := range want {
+		if err := g.apply(ctx, obj); err != nil {
+			return err
+		}
+	}
+	return g.prune(ctx, want)
+}
+
+// apply creates or updates one generated object.
+func (g *Generator) apply(ctx context.Context, obj *unstructured.Unstructured) error {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(obj.GroupVersionKind())
+	err := g.client.Get(ctx, client.ObjectKey{Name: obj.GetName()}, existing)
+	switch {
+	case apierrors.IsNotFound(err):
+		log.Info("creating generated admission policy object", "kind", obj.GetKind(), "name", obj.GetName())
+		return g.client.Create(ctx, obj)
+	case err != nil:
+		return err
+	}
+	if existing.GetLabels()[generatedLabel] != "true" {
+		return fmt.Errorf("%s %s exists but is not managed by the generator; refusing to overwrite", obj.GetKind(), obj.GetName())
+	}
+	existingSpec, _, _ := unstructured.NestedMap(existing.Object, "spec")
+	wantSpec, _, _ := unstructured.NestedMap(obj.Object, "spec")
+	if equalJSON(existingSpec, wantSpec) {
+		return nil
+	}
+	if err := unstructured.SetNestedMap(existing.Object, wantSpec, "spec"); err != nil {
+		return err
+	}
+	log.Info("updating generated admission policy object", "kind", obj.GetKind(), "name", obj.GetName())
+	return g.client.Update(ctx, existing)
+}
+
+// prune deletes generated objects whose template or constraint is gone.
+func (g *Generator) prune(ctx context.Context, want map[string]*unstructured.Unstructured) error {
+	for _, gvk := range []schema.GroupVersionKind{policyGVK, bindingGVK} {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(schema.GroupVersionKind{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind + "List"})
+		if err := g.client.List(ctx, list, client.MatchingLabels{generatedLabel: "true"}); err != nil {
+			// The VAP API may not exist on this cluster.
+			log.V(1).Info("unable to list generated objects", "kind", gvk.Kind, "error", err.Error())
+			return nil
+		}
+		for i := range list.Items {
+			if _, found := want[list.Items[i].GetName()]; found {
+				continue
+			}
+			log.Info("pruning stale generated admission policy object", "kind", gvk.Kind, "name", list.Items[i].GetName())
+			if err := g.client.Delete(ctx, &list.Items[i]); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ParseValidations decodes the annotation value; an absent annotation is
+// not an error.
+func ParseValidations(raw string) ([]Validation, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var validations []Validation
+	if err := json.Unmarshal([]byte(raw), &validations); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", CELValidationsAnnotation, err)
+	}
+	for _, v := range validations {
+		if strings.TrimSpace(v.Expression) == "" {
+			return nil, fmt.Errorf("parsing %s: validations must include an expression", CELValidationsAnnotation)
+		}
+	}
+	return validations, nil
+}
+
+// BuildPolicy returns the ValidatingAdmissionPolicy for one template.
+// Resource scoping lives on the bindings, so the policy matches
+// everything.
+func BuildPolicy(templateName string, validations []Validation) *unstructured.Unstructured {
+	rules := make([]interface{}, 0, len(validations))
+	for _, v := range validations {
+		rule := map[string]interface{}{"expression": v.Expression}
+		if v.Message != "" {
+			rule["message"] = v.Message
+		}
+		rules = append(rules, rule)
+	}
+	policy := &unstructured.Unstructured{}
+	policy.SetGroupVersionKind(policyGVK)
+	policy.SetName("gatekeeper-" + templateName)
+	policy.SetLabels(map[string]string{generatedLabel: "true"})
+	policy.Object["spec"] = map[string]interface{}{
+		"failurePolicy": "Ignore",
+		"matchConstraints": map[string]interface{}{
+			"resourceRules": []interface{}{
+				map[string]interface{}{
+					"apiGroups":   []interface{}{"*"},
+					"apiVersions": []interface{}{"*"},
+					"operations":  []interface{}{"CREATE", "UPDATE"},
+					"resources":   []interface{}{"*"},
+				},
+			},
+		},
+		"validations": rules,
+	}
+	return policy
+}
+
+// BuildBinding returns the binding tying a generated policy to one
+// constraint's match kinds.
+func BuildBinding(policyName string, constraint *unstructured.Unstructured) *unstructured.Unstructured {
+	binding := &unstructured.Unstructured{}
+	binding.SetGroupVersionKind(bindingGVK)
+	binding.SetName("gatekeeper-" + strings.ToLower(constraint.GetKind()) + "-" + constraint.GetName())
+	binding.SetLabels(map[string]string{generatedLabel: "true"})
+	spec := map[string]interface{}{
+		"policyName":        policyName,
+		"validationActions": []interface{}{"Deny"},
+	}
+	if rules := resourceRulesFor(constraint); len(rules) > 0 {
+		spec["matchResources"] = map[string]interface{}{"resourceRules": rules}
+	}
+	binding.Object["spec"] = spec
+	return binding
+}
+
+// resourceRulesFor translates a constraint's spec.match.kinds into
+// binding resource rules. Kinds are expressed as lowercase plural
+// resources, the closest mapping available without a REST mapper; the
+// API server ignores rules for resources that do not exist.
+func resourceRulesFor(constraint *unstructured.Unstructured) []interface{} {
+	kinds, found, err := unstructured.NestedSlice(constraint.Object, "spec", "match", "kinds")
+	if err != nil || !found {
+		return nil
+	}
+	var rules []interface{}
+	for _, entry := range kinds {
+		m, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		groups, _, _ := unstructured.NestedSlice(m, "apiGroups")
+		kindNames, _, _ := unstructured.NestedSlice(m, "kinds")
+		resources := make([]interface{}, 0, len(kindNames))
+		for _, kind := range kindNames {
+			s, ok := kind.(string)
+			if !ok {
+				continue
+			}
+			if s == "*" {
+				resources = append(resources, "*")
+			} else {
+				resources = append(resources, strings.ToLower(s)+"s")
+			}
+		}
+		if len(resources) == 0 {
+			continue
+		}
+		if len(groups) == 0 {
+			groups = []interface{}{"*"}
+		}
+		rules = append(rules, map[string]interface{}{
+			"apiGroups":   groups,
+			"apiVersions": []interface{}{"*"},
+			"operations":  []interface{}{"CREATE", "UPDATE"},
+			"resources":   resources,
+		})
+	}
+	return rules
+}
+
+// equalJSON compares two unstructured fragments by serialization, which
+// sidesteps type differences introduced by round-tripping.
+func equalJSON(a, b map[string]interface{}) bool {
+	aRaw, errA := json.Marshal(a)
+	bRaw, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(aRaw) == string(bRaw)
+}
@@ -0,0 +1,96 @@
+package vapgen
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestParseValidations(t *testing.T) {
+	validations, err := ParseValidations(`[{"expression": "object.spec.replicas <= 5", "message": "too many replicas"}]`)
+	if err != nil {
+		t.Fatalf("parsing validations: %s", err)
+	}
+	if len(validations) != 1 || validations[0].Expression != "object.spec.replicas <= 5" {
+		t.Errorf("validations = %+v", validations)
+	}
+	if v, err := ParseValidations(""); err != nil || v != nil {
+		t.Errorf("an absent annotation should parse to nothing, got %v, %v", v, err)
+	}
+	if _, err := ParseValidations("not json"); err == nil {
+		t.Errorf("malformed annotations should be rejected")
+	}
+	if _, err := ParseValidations(`[{"message": "no expression"}]`); err == nil {
+		t.Errorf("validations without an expression should be rejected")
+	}
+}
+
+func TestBuildPolicy(t *testing.T) {
+	policy := BuildPolicy("k8srequiredlabels", []Validation{
+		{Expression: "has(object.metadata.labels.owner)", Message: "missing owner label"},
+	})
+	if policy.GetName() != "gatekeeper-k8srequiredlabels" {
+		t.Errorf("policy name = %q", policy.GetName())
+	}
+	if policy.GetLabels()[generatedLabel] != "true" {
+		t.Errorf("generated policies must carry the ownership label")
+	}
+	validations, _, err := unstructured.NestedSlice(policy.Object, "spec", "validations")
+	if err != nil || len(validations) != 1 {
+		t.Fatalf("validations = %v, err = %v", validations, err)
+	}
+	rule := validations[0].(map[string]interface{})
+	if rule["expression"] != "has(object.metadata.labels.owner)" || rule["message"] != "missing owner label" {
+		t.Errorf("rule = %v", rule)
+	}
+}
+
+func TestBuildBinding(t *testing.T) {
+	constraint := &unstructured.Unstructured{}
+	constraint.SetAPIVersion("constraints.gatekeeper.sh/v1beta1")
+	constraint.SetKind("K8sRequiredLabels")
+	constraint.SetName("require-owner")
+	constraint.Object["spec"] = map[string]interface{}{
+		"match": map[string]interface{}{
+			"kinds": []interface{}{
+				map[string]interface{}{
+					"apiGroups": []interface{}{"apps"},
+					"kinds":     []interface{}{"Deployment"},
+				},
+			},
+		},
+	}
+
+	binding := BuildBinding("gatekeeper-k8srequiredlabels", constraint)
+	if binding.GetName() != "gatekeeper-k8srequiredlabels-require-owner" {
+		t.Errorf("binding name = %q", binding.GetName())
+	}
+	policyName, _, _ := unstructured.NestedString(binding.Object, "spec", "policyName")
+	if policyName != "gatekeeper-k8srequiredlabels" {
+		t.Errorf("policyName = %q", policyName)
+	}
+	rules, _, err := unstructured.NestedSlice(binding.Object, "spec", "matchResources", "resourceRules")
+	if err != nil || len(rules) != 1 {
+		t.Fatalf("resourceRules = %v, err = %v", rules, err)
+	}
+	rule := rules[0].(map[string]interface{})
+	if !reflect.DeepEqual(rule["resources"], []interface{}{"deployments"}) {
+		t.Errorf("resources = %v, want [deployments]", rule["resources"])
+	}
+	if !reflect.DeepEqual(rule["apiGroups"], []interface{}{"apps"}) {
+		t.Errorf("apiGroups = %v, want [apps]", rule["apiGroups"])
+	}
+}
+
+func TestBuildBindingWithoutMatch(t *testing.T) {
+	constraint := &unstructured.Unstructured{}
+	constraint.SetAPIVersion("constraints.gatekeeper.sh/v1beta1")
+	constraint.SetKind("K8sRequiredLabels")
+	constraint.SetName("require-owner")
+
+	binding := BuildBinding("gatekeeper-k8srequiredlabels", constraint)
+	if _, found, _ := unstructured.NestedMap(binding.Object, "spec", "matchResources"); found {
+		t.Errorf("constraints without match kinds should produce an unscoped binding")
+	}
+}
@@ -0,0 +1,170 @@
+// Package verdictcache caches admission verdicts keyed by the content of
+// the reviewed request and the revision of the policy snapshot it was
+// decided under. Controllers stuck in reconcile loops resubmit
+// byte-identical objects; while the policy snapshot is unchanged their
+// verdicts are identical too, so the webhook can skip evaluation. Any
+// change to templates, constraints, or synced data advances the policy
+// revision, so verdicts cached under the previous snapshot can no longer
+// match; entries additionally expire after a short TTL to bound
+// staleness from time-dependent features such as maintenance windows.
+package verdictcache
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"flag"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+const (
+	defaultMaxEntries = 1024
+	defaultTTL        = 30 * time.Second
+)
+
+var (
+	enableAdmissionResultCache = flag.Bool("enable-admission-result-cache", false, "(alpha) cache admission verdicts for identical requests while the policy snapshot is unchanged")
+	admissionResultCacheSize   = flag.Int("admission-result-cache-size", defaultMaxEntries, "maximum number of cached admission verdicts")
+	admissionResultCacheTTL    = flag.Duration("admission-result-cache-ttl", defaultTTL, "how long a cached admission verdict stays valid")
+)
+
+// Enabled returns true when the admission result cache was requested via
+// flag.
+func Enabled() bool {
+	return *enableAdmissionResultCache
+}
+
+// Verdict is a cached admission outcome: the deny messages and warnings
+// the webhook produced for a request.
+type Verdict struct {
+	Msgs     []string
+	Warnings []string
+}
+
+type entry struct {
+	verdict   Verdict
+	expiresAt time.Time
+}
+
+// Cache is a bounded, TTL-based verdict cache.
+type Cache struct {
+	mux        sync.Mutex
+	entries    map[string]entry
+	maxEntries int
+	ttl        time.Duration
+}
+
+var (
+	cache     *Cache
+	cacheOnce sync.Once
+)
+
+// Get returns the process-wide verdict cache, sized from flags on first
+// use.
+func Get() *Cache {
+	cacheOnce.Do(func() {
+		cache = New(*admissionResultCacheSize, *admissionResultCacheTTL)
+	})
+	return cache
+}
+
+// New returns a cache with the given bounds, for tests.
+func New(maxEntries int, ttl time.Duration) *Cache {
+	return &Cache{
+		entries:    make(map[string]entry),
+		maxEntries: maxEntries,
+		ttl:        ttl,
+	}
+}
+
+// policyRevision counts policy snapshot changes. It participates in
+// every cache key, so a template, constraint, or data change does not
+// need to flush the cache: entries stored under the previous revision
+// simply never match again and age out via the TTL and size bound.
+var policyRevision uint64
+
+// Revision returns the current policy snapshot revision.
+func Revision() uint64 {
+	return atomic.LoadUint64(&policyRevision)
+}
+
+// Key derives the cache key for an admission request. Everything a
+// verdict can depend on participates in the hash: object content,
+// identity of the object, operation, the requesting user, and the
+// revision of the policy snapshot in effect.
+func Key(req *admissionv1.AdmissionRequest) string {
+	h := sha256.New()
+	var rev [8]byte
+	binary.BigEndian.PutUint64(rev[:], Revision())
+	h.Write(rev[:])
+	h.Write([]byte{0})
+	h.Write(req.Object.Raw)
+	h.Write([]byte{0})
+	h.Write(req.OldObject.Raw)
+	for _, s := range []string{
+		req.Kind.Group, req.Kind.Version, req.Kind.Kind,
+		req.Namespace, req.Name,
+		string(req.Operation),
+		req.UserInfo.Username,
+	} {
+		h.Write([]byte{0})
+		h.Write([]byte(s))
+	}
+	groups := make([]string, len(req.UserInfo.Groups))
+	copy(groups, req.UserInfo.Groups)
+	sort.Strings(groups)
+	h.Write([]byte{0})
+	h.Write([]byte(strings.Join(groups, ",")))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Lookup returns the cached verdict for the key, if present and fresh.
+func (c *Cache) Lookup(key string) (Verdict, bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return Verdict{}, false
+	}
+	if time.Now().After(e.expiresAt) {
+		delete(c.entries, key)
+		return Verdict{}, false
+	}
+	return e.verdict, true
+}
+
+// Store records the verdict for the key. When the cache is full it is
+// cleared wholesale; entries are cheap to recompute and expire quickly
+// anyway.
+func (c *Cache) Store(key string, verdict Verdict) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		c.entries = make(map[string]entry)
+	}
+	c.entries[key] = entry{verdict: verdict, expiresAt: time.Now().Add(c.ttl)}
+}
+
+// InvalidateAll advances the policy revision, retiring every verdict
+// cached under the previous snapshot. Safe to call from controllers on
+// every policy snapshot change; advancing a counter is cheaper than
+// flushing under load, and requests that looked their key up before the
+// change store under the old revision, where nothing can find them.
+func InvalidateAll() {
+	atomic.AddUint64(&policyRevision, 1)
+}
+
+// Invalidate drops every cached verdict wholesale.
+func (c *Cache) Invalidate() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if len(c.entries) > 0 {
+		c.entries = make(map[string]entry)
+	}
+}
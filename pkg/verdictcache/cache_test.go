@@ -0,0 +1,101 @@
+package verdictcache
+
+import (
+	"testing"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func makeRequest(raw, namespace, username string) *admissionv1.AdmissionRequest {
+	return &admissionv1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+		Namespace: namespace,
+		Name:      "busybox",
+		Operation: admissionv1.Create,
+		Object:    runtime.RawExtension{Raw: []byte(raw)},
+		UserInfo:  authenticationv1.UserInfo{Username: username},
+	}
+}
+
+func TestKey(t *testing.T) {
+	base := makeRequest(`{"spec": {}}`, "default", "alice")
+	if Key(base) != Key(makeRequest(`{"spec": {}}`, "default", "alice")) {
+		t.Errorf("identical requests should produce identical keys")
+	}
+	if Key(base) == Key(makeRequest(`{"spec": {"x": 1}}`, "default", "alice")) {
+		t.Errorf("different object content should produce different keys")
+	}
+	if Key(base) == Key(makeRequest(`{"spec": {}}`, "other", "alice")) {
+		t.Errorf("different namespaces should produce different keys")
+	}
+	if Key(base) == Key(makeRequest(`{"spec": {}}`, "default", "bob")) {
+		t.Errorf("different users should produce different keys")
+	}
+}
+
+func TestLookupStoreInvalidate(t *testing.T) {
+	c := New(10, time.Minute)
+	key := Key(makeRequest(`{}`, "default", "alice"))
+
+	if _, ok := c.Lookup(key); ok {
+		t.Errorf("lookup on empty cache should miss")
+	}
+
+	c.Store(key, Verdict{Msgs: []string{"denied"}})
+	v, ok := c.Lookup(key)
+	if !ok {
+		t.Fatalf("expected cache hit after store")
+	}
+	if len(v.Msgs) != 1 || v.Msgs[0] != "denied" {
+		t.Errorf("unexpected verdict: %+v", v)
+	}
+
+	c.Invalidate()
+	if _, ok := c.Lookup(key); ok {
+		t.Errorf("lookup after invalidation should miss")
+	}
+}
+
+func TestPolicyChangeRetiresKeys(t *testing.T) {
+	req := makeRequest(`{}`, "default", "alice")
+	before := Key(req)
+	InvalidateAll()
+	after := Key(req)
+	if before == after {
+		t.Errorf("a policy snapshot change should produce a new key for the same request")
+	}
+
+	// verdicts stored under the old revision are unreachable through the
+	// new key
+	c := New(10, time.Minute)
+	c.Store(before, Verdict{Msgs: []string{"denied"}})
+	if _, ok := c.Lookup(after); ok {
+		t.Errorf("verdict cached under the previous revision should not be served")
+	}
+}
+
+func TestLookupExpiredEntry(t *testing.T) {
+	c := New(10, -time.Second)
+	key := Key(makeRequest(`{}`, "default", "alice"))
+	c.Store(key, Verdict{})
+	if _, ok := c.Lookup(key); ok {
+		t.Errorf("expired entries should not be served")
+	}
+}
+
+func TestStoreEvictsWhenFull(t *testing.T) {
+	c := New(2, time.Minute)
+	c.Store("a", Verdict{})
+	c.Store("b", Verdict{})
+	c.Store("c", Verdict{})
+	if _, ok := c.Lookup("a"); ok {
+		t.Errorf("cache should have been cleared when full")
+	}
+	if _, ok := c.Lookup("c"); !ok {
+		t.Errorf("latest entry should be retained")
+	}
+}
@@ -0,0 +1,1170 @@
+package wasmengine
+
+// A self-contained interpreter for the WebAssembly MVP integer subset,
+// mirroring how the CEL engine carries its own evaluator rather than
+// pulling a runtime dependency into the admission path.
+//
+// Policy modules follow a small ABI:
+//
+//	(memory (export "memory") ...)
+//	(func (export "malloc") (param i32) (result i32))
+//	(func (export "eval") (param i32 i32) (result i64))
+//
+// The host calls malloc with the input length, writes the input JSON at
+// the returned offset, and calls eval with that offset and length. eval
+// returns the violations JSON packed as (ptr << 32) | len. Modules must
+// be self-contained: imports, tables, and floating point are outside the
+// supported subset and are rejected. In particular, modules produced by
+// "opa build -t wasm" depend on all three and cannot run here; policies
+// must be compiled against this ABI directly.
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+)
+
+const (
+	wasmPageSize = 65536
+	// maxMemoryPages caps module memory at 64MiB so a misbehaving
+	// module cannot exhaust the webhook's heap.
+	maxMemoryPages = 1024
+	// evalFuel bounds the instructions one evaluation may execute so a
+	// non-terminating module cannot hang admission.
+	evalFuel = 20_000_000
+)
+
+type funcType struct {
+	params  []byte
+	results []byte
+}
+
+type function struct {
+	typeIdx uint32
+	locals  []byte
+	body    []byte
+	blocks  map[int]blockInfo
+}
+
+type blockInfo struct {
+	endPC  int
+	elsePC int // -1 when the if has no else
+}
+
+type globalDef struct {
+	mutable bool
+	init    uint64
+}
+
+type export struct {
+	kind  byte
+	index uint32
+}
+
+type dataSegment struct {
+	offset uint32
+	bytes  []byte
+}
+
+// Module is a parsed and validated policy module.
+type Module struct {
+	types    []funcType
+	funcs    []function
+	minPages uint32
+	globals  []globalDef
+	exports  map[string]export
+	data     []dataSegment
+}
+
+// Compile parses a wasm binary and validates that it fits the supported
+// subset and exports the policy ABI.
+func Compile(wasm []byte) (*Module, error) {
+	if len(wasm) < 8 || string(wasm[0:4]) != "\x00asm" || binary.LittleEndian.Uint32(wasm[4:8]) != 1 {
+		return nil, fmt.Errorf("not a wasm v1 binary")
+	}
+	m := &Module{exports: map[string]export{}}
+	var funcTypeIdxs []uint32
+	r := &reader{buf: wasm, pc: 8}
+	for r.pc < len(r.buf) {
+		id, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		size, err := r.varuint32()
+		if err != nil {
+			return nil, err
+		}
+		if r.pc+int(size) > len(r.buf) {
+			return nil, fmt.Errorf("section %d overruns the binary", id)
+		}
+		section := &reader{buf: r.buf[r.pc : r.pc+int(size)]}
+		r.pc += int(size)
+		switch id {
+		case 1: // types
+			if err := m.parseTypes(section); err != nil {
+				return nil, err
+			}
+		case 2: // imports
+			return nil, fmt.Errorf("imports are not supported; policy modules must be self-contained (modules built with \"opa build -t wasm\" require host imports and cannot run here)")
+		case 3: // function type indexes
+			count, err := section.varuint32()
+			if err != nil {
+				return nil, err
+			}
+			for i := uint32(0); i < count; i++ {
+				idx, err := section.varuint32()
+				if err != nil {
+					return nil, err
+				}
+				funcTypeIdxs = append(funcTypeIdxs, idx)
+			}
+		case 4: // tables
+			return nil, fmt.Errorf("tables and indirect calls are not supported")
+		case 5: // memories
+			if err := m.parseMemory(section); err != nil {
+				return nil, err
+			}
+		case 6: // globals
+			if err := m.parseGlobals(section); err != nil {
+				return nil, err
+			}
+		case 7: // exports
+			if err := m.parseExports(section); err != nil {
+				return nil, err
+			}
+		case 10: // code
+			if err := m.parseCode(section, funcTypeIdxs); err != nil {
+				return nil, err
+			}
+		case 11: // data
+			if err := m.parseData(section); err != nil {
+				return nil, err
+			}
+		default:
+			// custom, start, and element sections are ignored
+		}
+	}
+	if err := m.validateABI(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *Module) parseTypes(r *reader) error {
+	count, err := r.varuint32()
+	if err != nil {
+		return err
+	}
+	for i := uint32(0); i < count; i++ {
+		form, err := r.byte()
+		if err != nil {
+			return err
+		}
+		if form != 0x60 {
+			return fmt.Errorf("unsupported type form 0x%x", form)
+		}
+		t := funcType{}
+		if t.params, err = r.valtypes(); err != nil {
+			return err
+		}
+		if t.results, err = r.valtypes(); err != nil {
+			return err
+		}
+		if len(t.results) > 1 {
+			return fmt.Errorf("multi-value results are not supported")
+		}
+		m.types = append(m.types, t)
+	}
+	return nil
+}
+
+func (m *Module) parseMemory(r *reader) error {
+	count, err := r.varuint32()
+	if err != nil {
+		return err
+	}
+	if count != 1 {
+		return fmt.Errorf("exactly one memory is required, got %d", count)
+	}
+	flags, err := r.byte()
+	if err != nil {
+		return err
+	}
+	if m.minPages, err = r.varuint32(); err != nil {
+		return err
+	}
+	if flags&0x1 != 0 {
+		if _, err := r.varuint32(); err != nil { // declared max, superseded by ours
+			return err
+		}
+	}
+	if m.minPages > maxMemoryPages {
+		return fmt.Errorf("memory of %d pages exceeds the %d page limit", m.minPages, maxMemoryPages)
+	}
+	return nil
+}
+
+func (m *Module) parseGlobals(r *reader) error {
+	count, err := r.varuint32()
+	if err != nil {
+		return err
+	}
+	for i := uint32(0); i < count; i++ {
+		if _, err := r.byte(); err != nil { // valtype
+			return err
+		}
+		mutable, err := r.byte()
+		if err != nil {
+			return err
+		}
+		init, err := r.constExpr()
+		if err != nil {
+			return err
+		}
+		m.globals = append(m.globals, globalDef{mutable: mutable == 1, init: init})
+	}
+	return nil
+}
+
+func (m *Module) parseExports(r *reader) error {
+	count, err := r.varuint32()
+	if err != nil {
+		return err
+	}
+	for i := uint32(0); i < count; i++ {
+		name, err := r.name()
+		if err != nil {
+			return err
+		}
+		kind, err := r.byte()
+		if err != nil {
+			return err
+		}
+		index, err := r.varuint32()
+		if err != nil {
+			return err
+		}
+		m.exports[name] = export{kind: kind, index: index}
+	}
+	return nil
+}
+
+func (m *Module) parseCode(r *reader, funcTypeIdxs []uint32) error {
+	count, err := r.varuint32()
+	if err != nil {
+		return err
+	}
+	if int(count) != len(funcTypeIdxs) {
+		return fmt.Errorf("code section has %d bodies for %d functions", count, len(funcTypeIdxs))
+	}
+	for i := uint32(0); i < count; i++ {
+		size, err := r.varuint32()
+		if err != nil {
+			return err
+		}
+		if r.pc+int(size) > len(r.buf) {
+			return fmt.Errorf("function body overruns the section")
+		}
+		body := &reader{buf: r.buf[r.pc : r.pc+int(size)]}
+		r.pc += int(size)
+
+		fn := function{typeIdx: funcTypeIdxs[i]}
+		declCount, err := body.varuint32()
+		if err != nil {
+			return err
+		}
+		for j := uint32(0); j < declCount; j++ {
+			n, err := body.varuint32()
+			if err != nil {
+				return err
+			}
+			valtype, err := body.byte()
+			if err != nil {
+				return err
+			}
+			if n > 10000 {
+				return fmt.Errorf("function declares too many locals")
+			}
+			for k := uint32(0); k < n; k++ {
+				fn.locals = append(fn.locals, valtype)
+			}
+		}
+		fn.body = body.buf[body.pc:]
+		if fn.blocks, err = scanBlocks(fn.body); err != nil {
+			return err
+		}
+		m.funcs = append(m.funcs, fn)
+	}
+	return nil
+}
+
+func (m *Module) parseData(r *reader) error {
+	count, err := r.varuint32()
+	if err != nil {
+		return err
+	}
+	for i := uint32(0); i < count; i++ {
+		memIdx, err := r.varuint32()
+		if err != nil {
+			return err
+		}
+		if memIdx != 0 {
+			return fmt.Errorf("data segment targets unknown memory %d", memIdx)
+		}
+		offset, err := r.constExpr()
+		if err != nil {
+			return err
+		}
+		size, err := r.varuint32()
+		if err != nil {
+			return err
+		}
+		if r.pc+int(size) > len(r.buf) {
+			return fmt.Errorf("data segment overruns the section")
+		}
+		m.data = append(m.data, dataSegment{offset: uint32(offset), bytes: r.buf[r.pc : r.pc+int(size)]})
+		r.pc += int(size)
+	}
+	return nil
+}
+
+// validateABI checks the exports the host calls into.
+func (m *Module) validateABI() error {
+	mem, ok := m.exports["memory"]
+	if !ok || mem.kind != 0x02 {
+		return fmt.Errorf(`module must export its memory as "memory"`)
+	}
+	for name, wantParams := range map[string]int{"malloc": 1, "eval": 2} {
+		exp, ok := m.exports[name]
+		if !ok || exp.kind != 0x00 {
+			return fmt.Errorf("module must export a %q function", name)
+		}
+		if int(exp.index) >= len(m.funcs) {
+			return fmt.Errorf("export %q references unknown function %d", name, exp.index)
+		}
+		t := m.types[m.funcs[exp.index].typeIdx]
+		if len(t.params) != wantParams || len(t.results) != 1 {
+			return fmt.Errorf("export %q does not match the policy ABI", name)
+		}
+	}
+	return nil
+}
+
+// scanBlocks precomputes each structured control instruction's end (and
+// else) position so branches resolve in constant time.
+func scanBlocks(body []byte) (map[int]blockInfo, error) {
+	blocks := map[int]blockInfo{}
+	var stack []int
+	r := &reader{buf: body}
+	for r.pc < len(r.buf) {
+		pc := r.pc
+		op, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case 0x02, 0x03, 0x04: // block, loop, if
+			if _, err := r.byte(); err != nil { // blocktype
+				return nil, err
+			}
+			stack = append(stack, pc)
+			blocks[pc] = blockInfo{elsePC: -1}
+		case 0x05: // else
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("else outside a block")
+			}
+			open := stack[len(stack)-1]
+			info := blocks[open]
+			info.elsePC = pc
+			blocks[open] = info
+		case 0x0B: // end
+			if len(stack) == 0 {
+				// end of the function body
+				continue
+			}
+			open := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			info := blocks[open]
+			info.endPC = pc
+			blocks[open] = info
+		default:
+			if err := skipImmediates(r, op); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(stack) != 0 {
+		return nil, fmt.Errorf("unterminated block")
+	}
+	return blocks, nil
+}
+
+// skipImmediates advances past one instruction's immediates during the
+// block scan.
+func skipImmediates(r *reader, op byte) error {
+	switch {
+	case op == 0x0C || op == 0x0D: // br, br_if
+		_, err := r.varuint32()
+		return err
+	case op == 0x0E: // br_table
+		count, err := r.varuint32()
+		if err != nil {
+			return err
+		}
+		for i := uint32(0); i <= count; i++ {
+			if _, err := r.varuint32(); err != nil {
+				return err
+			}
+		}
+		return nil
+	case op == 0x10: // call
+		_, err := r.varuint32()
+		return err
+	case op >= 0x20 && op <= 0x24: // local.get/set/tee, global.get/set
+		_, err := r.varuint32()
+		return err
+	case op >= 0x28 && op <= 0x3E: // loads and stores: align, offset
+		if _, err := r.varuint32(); err != nil {
+			return err
+		}
+		_, err := r.varuint32()
+		return err
+	case op == 0x3F || op == 0x40: // memory.size, memory.grow
+		_, err := r.byte()
+		return err
+	case op == 0x41: // i32.const
+		_, err := r.varint64()
+		return err
+	case op == 0x42: // i64.const
+		_, err := r.varint64()
+		return err
+	case op == 0x00 || op == 0x01 || op == 0x0F || op == 0x1A || op == 0x1B:
+		return nil // unreachable, nop, return, drop, select
+	case op >= 0x45 && op <= 0xC4:
+		return nil // numeric ops carry no immediates
+	default:
+		return fmt.Errorf("unsupported opcode 0x%x", op)
+	}
+}
+
+// Instance is one instantiation of a module, with its own memory and
+// globals. Instances are not safe for concurrent use; callers
+// instantiate per evaluation.
+type Instance struct {
+	module  *Module
+	memory  []byte
+	globals []uint64
+	fuel    int
+}
+
+// Instantiate allocates the module's memory, applies its data segments,
+// and initializes globals.
+func (m *Module) Instantiate() (*Instance, error) {
+	inst := &Instance{
+		module: m,
+		memory: make([]byte, int(m.minPages)*wasmPageSize),
+		fuel:   evalFuel,
+	}
+	for _, g := range m.globals {
+		inst.globals = append(inst.globals, g.init)
+	}
+	for _, seg := range m.data {
+		if int(seg.offset)+len(seg.bytes) > len(inst.memory) {
+			return nil, fmt.Errorf("data segment at %d overruns memory", seg.offset)
+		}
+		copy(inst.memory[seg.offset:], seg.bytes)
+	}
+	return inst, nil
+}
+
+// EvalJSON passes the input JSON through the policy ABI and returns the
+// module's output JSON.
+func (inst *Instance) EvalJSON(input []byte) ([]byte, error) {
+	ptr, err := inst.call("malloc", uint64(len(input)))
+	if err != nil {
+		return nil, err
+	}
+	if ptr+uint64(len(input)) > uint64(len(inst.memory)) {
+		return nil, fmt.Errorf("malloc returned an out-of-bounds offset %d", ptr)
+	}
+	copy(inst.memory[ptr:], input)
+	packed, err := inst.call("eval", ptr, uint64(len(input)))
+	if err != nil {
+		return nil, err
+	}
+	outPtr := packed >> 32
+	outLen := packed & 0xFFFFFFFF
+	if outPtr+outLen > uint64(len(inst.memory)) {
+		return nil, fmt.Errorf("eval returned an out-of-bounds result at %d len %d", outPtr, outLen)
+	}
+	out := make([]byte, outLen)
+	copy(out, inst.memory[outPtr:outPtr+outLen])
+	return out, nil
+}
+
+// call invokes an exported function.
+func (inst *Instance) call(name string, args ...uint64) (uint64, error) {
+	exp := inst.module.exports[name]
+	results, err := inst.invoke(exp.index, args)
+	if err != nil {
+		return 0, fmt.Errorf("calling %s: %w", name, err)
+	}
+	if len(results) != 1 {
+		return 0, fmt.Errorf("calling %s: expected one result, got %d", name, len(results))
+	}
+	return results[0], nil
+}
+
+// frame is one open structured control instruction.
+type frame struct {
+	isLoop bool
+	// branchPC is where a branch to this label lands: after the loop
+	// header, or at the block's end instruction.
+	branchPC int
+	// height is the value stack depth at entry, restored on branch.
+	height int
+	// arity is how many values a branch to this label carries.
+	arity int
+}
+
+func (inst *Instance) invoke(funcIdx uint32, args []uint64) ([]uint64, error) {
+	if int(funcIdx) >= len(inst.module.funcs) {
+		return nil, fmt.Errorf("call to unknown function %d", funcIdx)
+	}
+	fn := inst.module.funcs[funcIdx]
+	t := inst.module.types[fn.typeIdx]
+	if len(args) != len(t.params) {
+		return nil, fmt.Errorf("function %d takes %d arguments, got %d", funcIdx, len(t.params), len(args))
+	}
+
+	locals := make([]uint64, len(t.params)+len(fn.locals))
+	copy(locals, args)
+
+	var stack []uint64
+	var frames []frame
+	push := func(v uint64) { stack = append(stack, v) }
+	pop := func() uint64 {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		return v
+	}
+
+	// branch unwinds to the label depth levels up and returns the new pc
+	branch := func(depth int, pc int) (int, error) {
+		if depth >= len(frames) {
+			return 0, fmt.Errorf("branch depth %d exceeds open blocks", depth)
+		}
+		target := frames[len(frames)-1-depth]
+		carried := stack[len(stack)-target.arity:]
+		saved := make([]uint64, len(carried))
+		copy(saved, carried)
+		stack = append(stack[:target.height], saved...)
+		if target.isLoop {
+			frames = frames[:len(frames)-depth]
+			return target.branchPC, nil
+		}
+		frames = frames[:len(frames)-1-depth]
+		return target.branchPC, nil
+	}
+
+	body := fn.body
+	pc := 0
+	for pc < len(body) {
+		if inst.fuel--; inst.fuel <= 0 {
+			return nil, fmt.Errorf("execution budget exhausted")
+		}
+		opPC := pc
+		op := body[pc]
+		pc++
+		switch op {
+		case 0x00: // unreachable
+			return nil, fmt.Errorf("module trapped: unreachable")
+		case 0x01: // nop
+		case 0x02, 0x03: // block, loop
+			blocktype := body[pc]
+			pc++
+			arity := 0
+			if op == 0x02 && blocktype != 0x40 {
+				arity = 1
+			}
+			info := fn.blocks[opPC]
+			branchPC := info.endPC
+			if op == 0x03 {
+				branchPC = pc
+			}
+			frames = append(frames, frame{isLoop: op == 0x03, branchPC: branchPC, height: len(stack), arity: arity})
+		case 0x04: // if
+			blocktype := body[pc]
+			pc++
+			arity := 0
+			if blocktype != 0x40 {
+				arity = 1
+			}
+			info := fn.blocks[opPC]
+			frames = append(frames, frame{branchPC: info.endPC, height: len(stack) - 1, arity: arity})
+			if pop() == 0 {
+				if info.elsePC >= 0 {
+					pc = info.elsePC + 1
+				} else {
+					pc = info.endPC
+				}
+			}
+		case 0x05: // else: the then-arm fell through, skip to end
+			pc = frames[len(frames)-1].branchPC
+		case 0x0B: // end
+			if len(frames) > 0 {
+				frames = frames[:len(frames)-1]
+			}
+		case 0x0C: // br
+			depth, n, err := varuint32At(body, pc)
+			if err != nil {
+				return nil, err
+			}
+			pc += n
+			if pc, err = branch(int(depth), pc); err != nil {
+				return nil, err
+			}
+		case 0x0D: // br_if
+			depth, n, err := varuint32At(body, pc)
+			if err != nil {
+				return nil, err
+			}
+			pc += n
+			if pop() != 0 {
+				if pc, err = branch(int(depth), pc); err != nil {
+					return nil, err
+				}
+			}
+		case 0x0E: // br_table
+			count, n, err := varuint32At(body, pc)
+			if err != nil {
+				return nil, err
+			}
+			pc += n
+			targets := make([]uint32, count+1)
+			for i := range targets {
+				t, n, err := varuint32At(body, pc)
+				if err != nil {
+					return nil, err
+				}
+				pc += n
+				targets[i] = t
+			}
+			idx := pop()
+			if idx >= uint64(count) {
+				idx = uint64(count)
+			}
+			if pc, err = branch(int(targets[idx]), pc); err != nil {
+				return nil, err
+			}
+		case 0x0F: // return
+			if len(t.results) == 1 {
+				return []uint64{pop()}, nil
+			}
+			return nil, nil
+		case 0x10: // call
+			callee, n, err := varuint32At(body, pc)
+			if err != nil {
+				return nil, err
+			}
+			pc += n
+			if int(callee) >= len(inst.module.funcs) {
+				return nil, fmt.Errorf("call to unknown function %d", callee)
+			}
+			calleeType := inst.module.types[inst.module.funcs[callee].typeIdx]
+			argCount := len(calleeType.params)
+			callArgs := make([]uint64, argCount)
+			copy(callArgs, stack[len(stack)-argCount:])
+			stack = stack[:len(stack)-argCount]
+			results, err := inst.invoke(callee, callArgs)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, results...)
+		case 0x1A: // drop
+			pop()
+		case 0x1B: // select
+			cond, v2, v1 := pop(), pop(), pop()
+			if cond != 0 {
+				push(v1)
+			} else {
+				push(v2)
+			}
+		case 0x20: // local.get
+			idx, n, err := varuint32At(body, pc)
+			if err != nil {
+				return nil, err
+			}
+			pc += n
+			push(locals[idx])
+		case 0x21: // local.set
+			idx, n, err := varuint32At(body, pc)
+			if err != nil {
+				return nil, err
+			}
+			pc += n
+			locals[idx] = pop()
+		case 0x22: // local.tee
+			idx, n, err := varuint32At(body, pc)
+			if err != nil {
+				return nil, err
+			}
+			pc += n
+			locals[idx] = stack[len(stack)-1]
+		case 0x23: // global.get
+			idx, n, err := varuint32At(body, pc)
+			if err != nil {
+				return nil, err
+			}
+			pc += n
+			push(inst.globals[idx])
+		case 0x24: // global.set
+			idx, n, err := varuint32At(body, pc)
+			if err != nil {
+				return nil, err
+			}
+			pc += n
+			inst.globals[idx] = pop()
+		case 0x3F: // memory.size
+			pc++ // reserved byte
+			push(uint64(len(inst.memory) / wasmPageSize))
+		case 0x40: // memory.grow
+			pc++ // reserved byte
+			delta := pop()
+			current := uint64(len(inst.memory) / wasmPageSize)
+			if current+delta > maxMemoryPages {
+				push(0xFFFFFFFF) // -1: growth refused
+				break
+			}
+			inst.memory = append(inst.memory, make([]byte, delta*wasmPageSize)...)
+			push(current)
+		case 0x41: // i32.const
+			v, n, err := varint64At(body, pc)
+			if err != nil {
+				return nil, err
+			}
+			pc += n
+			push(uint64(uint32(v)))
+		case 0x42: // i64.const
+			v, n, err := varint64At(body, pc)
+			if err != nil {
+				return nil, err
+			}
+			pc += n
+			push(uint64(v))
+		default:
+			if op >= 0x28 && op <= 0x3E {
+				var err error
+				if pc, err = inst.memoryOp(op, body, pc, &stack); err != nil {
+					return nil, err
+				}
+				break
+			}
+			if err := inst.numericOp(op, &stack); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(t.results) == 1 {
+		if len(stack) == 0 {
+			return nil, fmt.Errorf("function ended with an empty stack")
+		}
+		return []uint64{stack[len(stack)-1]}, nil
+	}
+	return nil, nil
+}
+
+// memoryOp executes one load or store and returns the advanced pc.
+func (inst *Instance) memoryOp(op byte, body []byte, pc int, stack *[]uint64) (int, error) {
+	_, n, err := varuint32At(body, pc) // alignment hint, ignored
+	if err != nil {
+		return 0, err
+	}
+	pc += n
+	offset, n, err := varuint32At(body, pc)
+	if err != nil {
+		return 0, err
+	}
+	pc += n
+
+	pop := func() uint64 {
+		s := *stack
+		v := s[len(s)-1]
+		*stack = s[:len(s)-1]
+		return v
+	}
+	push := func(v uint64) { *stack = append(*stack, v) }
+
+	var value uint64
+	isStore := op >= 0x36
+	if isStore {
+		value = pop()
+	}
+	addr := uint64(uint32(pop())) + uint64(offset)
+	width := map[byte]uint64{
+		0x28: 4, 0x29: 8, 0x2C: 1, 0x2D: 1, 0x2E: 2, 0x2F: 2,
+		0x30: 1, 0x31: 1, 0x32: 2, 0x33: 2, 0x34: 4, 0x35: 4,
+		0x36: 4, 0x37: 8, 0x3A: 1, 0x3B: 2, 0x3C: 1, 0x3D: 2, 0x3E: 4,
+	}[op]
+	if width == 0 {
+		return 0, fmt.Errorf("unsupported memory opcode 0x%x", op)
+	}
+	if addr+width > uint64(len(inst.memory)) {
+		return 0, fmt.Errorf("out-of-bounds memory access at %d", addr)
+	}
+	mem := inst.memory[addr:]
+
+	if isStore {
+		switch width {
+		case 1:
+			mem[0] = byte(value)
+		case 2:
+			binary.LittleEndian.PutUint16(mem, uint16(value))
+		case 4:
+			binary.LittleEndian.PutUint32(mem, uint32(value))
+		case 8:
+			binary.LittleEndian.PutUint64(mem, value)
+		}
+		return pc, nil
+	}
+
+	var raw uint64
+	switch width {
+	case 1:
+		raw = uint64(mem[0])
+	case 2:
+		raw = uint64(binary.LittleEndian.Uint16(mem))
+	case 4:
+		raw = uint64(binary.LittleEndian.Uint32(mem))
+	case 8:
+		raw = binary.LittleEndian.Uint64(mem)
+	}
+	switch op {
+	case 0x2C: // i32.load8_s
+		raw = uint64(uint32(int32(int8(raw))))
+	case 0x2E: // i32.load16_s
+		raw = uint64(uint32(int32(int16(raw))))
+	case 0x30: // i64.load8_s
+		raw = uint64(int64(int8(raw)))
+	case 0x32: // i64.load16_s
+		raw = uint64(int64(int16(raw)))
+	case 0x34: // i64.load32_s
+		raw = uint64(int64(int32(raw)))
+	}
+	push(raw)
+	return pc, nil
+}
+
+// numericOp executes one integer instruction. Floating point opcodes
+// land here and are reported as outside the subset.
+func (inst *Instance) numericOp(op byte, stack *[]uint64) error {
+	pop := func() uint64 {
+		s := *stack
+		v := s[len(s)-1]
+		*stack = s[:len(s)-1]
+		return v
+	}
+	push := func(v uint64) { *stack = append(*stack, v) }
+	pushBool := func(b bool) {
+		if b {
+			push(1)
+		} else {
+			push(0)
+		}
+	}
+
+	switch op {
+	case 0x45: // i32.eqz
+		pushBool(uint32(pop()) == 0)
+	case 0x46, 0x47, 0x48, 0x49, 0x4A, 0x4B, 0x4C, 0x4D, 0x4E, 0x4F: // i32 comparisons
+		b, a := uint32(pop()), uint32(pop())
+		switch op {
+		case 0x46:
+			pushBool(a == b)
+		case 0x47:
+			pushBool(a != b)
+		case 0x48:
+			pushBool(int32(a) < int32(b))
+		case 0x49:
+			pushBool(a < b)
+		case 0x4A:
+			pushBool(int32(a) > int32(b))
+		case 0x4B:
+			pushBool(a > b)
+		case 0x4C:
+			pushBool(int32(a) <= int32(b))
+		case 0x4D:
+			pushBool(a <= b)
+		case 0x4E:
+			pushBool(int32(a) >= int32(b))
+		case 0x4F:
+			pushBool(a >= b)
+		}
+	case 0x50: // i64.eqz
+		pushBool(pop() == 0)
+	case 0x51, 0x52, 0x53, 0x54, 0x55, 0x56, 0x57, 0x58, 0x59, 0x5A: // i64 comparisons
+		b, a := pop(), pop()
+		switch op {
+		case 0x51:
+			pushBool(a == b)
+		case 0x52:
+			pushBool(a != b)
+		case 0x53:
+			pushBool(int64(a) < int64(b))
+		case 0x54:
+			pushBool(a < b)
+		case 0x55:
+			pushBool(int64(a) > int64(b))
+		case 0x56:
+			pushBool(a > b)
+		case 0x57:
+			pushBool(int64(a) <= int64(b))
+		case 0x58:
+			pushBool(a <= b)
+		case 0x59:
+			pushBool(int64(a) >= int64(b))
+		case 0x5A:
+			pushBool(a >= b)
+		}
+	case 0x67: // i32.clz
+		push(uint64(bits.LeadingZeros32(uint32(pop()))))
+	case 0x68: // i32.ctz
+		push(uint64(bits.TrailingZeros32(uint32(pop()))))
+	case 0x69: // i32.popcnt
+		push(uint64(bits.OnesCount32(uint32(pop()))))
+	case 0x6A, 0x6B, 0x6C, 0x6D, 0x6E, 0x6F, 0x70, 0x71, 0x72, 0x73, 0x74, 0x75, 0x76, 0x77, 0x78: // i32 arithmetic
+		b, a := uint32(pop()), uint32(pop())
+		switch op {
+		case 0x6A:
+			push(uint64(a + b))
+		case 0x6B:
+			push(uint64(a - b))
+		case 0x6C:
+			push(uint64(a * b))
+		case 0x6D:
+			if b == 0 || (int32(a) == -1<<31 && int32(b) == -1) {
+				return fmt.Errorf("module trapped: i32.div_s")
+			}
+			push(uint64(uint32(int32(a) / int32(b))))
+		case 0x6E:
+			if b == 0 {
+				return fmt.Errorf("module trapped: i32.div_u by zero")
+			}
+			push(uint64(a / b))
+		case 0x6F:
+			if b == 0 {
+				return fmt.Errorf("module trapped: i32.rem_s by zero")
+			}
+			push(uint64(uint32(int32(a) % int32(b))))
+		case 0x70:
+			if b == 0 {
+				return fmt.Errorf("module trapped: i32.rem_u by zero")
+			}
+			push(uint64(a % b))
+		case 0x71:
+			push(uint64(a & b))
+		case 0x72:
+			push(uint64(a | b))
+		case 0x73:
+			push(uint64(a ^ b))
+		case 0x74:
+			push(uint64(a << (b % 32)))
+		case 0x75:
+			push(uint64(uint32(int32(a) >> (b % 32))))
+		case 0x76:
+			push(uint64(a >> (b % 32)))
+		case 0x77:
+			push(uint64(bits.RotateLeft32(a, int(b%32))))
+		case 0x78:
+			push(uint64(bits.RotateLeft32(a, -int(b%32))))
+		}
+	case 0x79: // i64.clz
+		push(uint64(bits.LeadingZeros64(pop())))
+	case 0x7A: // i64.ctz
+		push(uint64(bits.TrailingZeros64(pop())))
+	case 0x7B: // i64.popcnt
+		push(uint64(bits.OnesCount64(pop())))
+	case 0x7C, 0x7D, 0x7E, 0x7F, 0x80, 0x81, 0x82, 0x83, 0x84, 0x85, 0x86, 0x87, 0x88, 0x89, 0x8A: // i64 arithmetic
+		b, a := pop(), pop()
+		switch op {
+		case 0x7C:
+			push(a + b)
+		case 0x7D:
+			push(a - b)
+		case 0x7E:
+			push(a * b)
+		case 0x7F:
+			if b == 0 || (int64(a) == -1<<63 && int64(b) == -1) {
+				return fmt.Errorf("module trapped: i64.div_s")
+			}
+			push(uint64(int64(a) / int64(b)))
+		case 0x80:
+			if b == 0 {
+				return fmt.Errorf("module trapped: i64.div_u by zero")
+			}
+			push(a / b)
+		case 0x81:
+			if b == 0 {
+				return fmt.Errorf("module trapped: i64.rem_s by zero")
+			}
+			push(uint64(int64(a) % int64(b)))
+		case 0x82:
+			if b == 0 {
+				return fmt.Errorf("module trapped: i64.rem_u by zero")
+			}
+			push(a % b)
+		case 0x83:
+			push(a & b)
+		case 0x84:
+			push(a | b)
+		case 0x85:
+			push(a ^ b)
+		case 0x86:
+			push(a << (b % 64))
+		case 0x87:
+			push(uint64(int64(a) >> (b % 64)))
+		case 0x88:
+			push(a >> (b % 64))
+		case 0x89:
+			push(bits.RotateLeft64(a, int(b%64)))
+		case 0x8A:
+			push(bits.RotateLeft64(a, -int(b%64)))
+		}
+	case 0xA7: // i32.wrap_i64
+		push(uint64(uint32(pop())))
+	case 0xAC: // i64.extend_i32_s
+		push(uint64(int64(int32(pop()))))
+	case 0xAD: // i64.extend_i32_u
+		push(uint64(uint32(pop())))
+	default:
+		return fmt.Errorf("opcode 0x%x is outside the supported integer subset", op)
+	}
+	return nil
+}
+
+// reader decodes the wasm binary format.
+type reader struct {
+	buf []byte
+	pc  int
+}
+
+func (r *reader) byte() (byte, error) {
+	if r.pc >= len(r.buf) {
+		return 0, fmt.Errorf("unexpected end of binary")
+	}
+	b := r.buf[r.pc]
+	r.pc++
+	return b, nil
+}
+
+func (r *reader) varuint32() (uint32, error) {
+	v, n, err := varuint32At(r.buf, r.pc)
+	r.pc += n
+	return v, err
+}
+
+func (r *reader) varint64() (int64, error) {
+	v, n, err := varint64At(r.buf, r.pc)
+	r.pc += n
+	return v, err
+}
+
+func (r *reader) name() (string, error) {
+	size, err := r.varuint32()
+	if err != nil {
+		return "", err
+	}
+	if r.pc+int(size) > len(r.buf) {
+		return "", fmt.Errorf("name overruns the binary")
+	}
+	s := string(r.buf[r.pc : r.pc+int(size)])
+	r.pc += int(size)
+	return s, nil
+}
+
+func (r *reader) valtypes() ([]byte, error) {
+	count, err := r.varuint32()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, count)
+	for i := uint32(0); i < count; i++ {
+		t, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		if t != 0x7F && t != 0x7E { // i32, i64
+			return nil, fmt.Errorf("value type 0x%x is outside the supported integer subset", t)
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// constExpr evaluates the single-constant init expressions used by
+// globals and data segments.
+func (r *reader) constExpr() (uint64, error) {
+	op, err := r.byte()
+	if err != nil {
+		return 0, err
+	}
+	if op != 0x41 && op != 0x42 { // i32.const, i64.const
+		return 0, fmt.Errorf("init expressions must be integer constants, got opcode 0x%x", op)
+	}
+	v, err := r.varint64()
+	if err != nil {
+		return 0, err
+	}
+	end, err := r.byte()
+	if err != nil {
+		return 0, err
+	}
+	if end != 0x0B {
+		return 0, fmt.Errorf("unterminated init expression")
+	}
+	if op == 0x41 {
+		return uint64(uint32(v)), nil
+	}
+	return uint64(v), nil
+}
+
+// varuint32At decodes an unsigned LEB128 value, returning it and the
+// bytes consumed.
+func varuint32At(buf []byte, pc int) (uint32, int, error) {
+	var v uint32
+	var shift uint
+	for n := 0; n < 5; n++ {
+		if pc+n >= len(buf) {
+			return 0, n, fmt.Errorf("unexpected end of binary")
+		}
+		b := buf[pc+n]
+		v |= uint32(b&0x7F) << shift
+		if b&0x80 == 0 {
+			return v, n + 1, nil
+		}
+		shift += 7
+	}
+	return 0, 5, fmt.Errorf("varuint32 is too long")
+}
+
+// varint64At decodes a signed LEB128 value, returning it and the bytes
+// consumed.
+func varint64At(buf []byte, pc int) (int64, int, error) {
+	var v int64
+	var shift uint
+	for n := 0; n < 10; n++ {
+		if pc+n >= len(buf) {
+			return 0, n, fmt.Errorf("unexpected end of binary")
+		}
+		b := buf[pc+n]
+		v |= int64(b&0x7F) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			if shift < 64 && b&0x40 != 0 {
+				v |= -1 << shift
+			}
+			return v, n + 1, nil
+		}
+	}
+	return 0, 10, fmt.Errorf("varint64 is too long")
+}
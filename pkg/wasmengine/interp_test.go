@@ -0,0 +1,233 @@
+package wasmengine
+
+import (
+	"bytes"
+	"testing"
+)
+
+// The tests assemble minimal policy modules by hand; helpers below
+// encode the wasm binary format.
+
+func uleb(v uint64) []byte {
+	var out []byte
+	for {
+		b := byte(v & 0x7F)
+		v >>= 7
+		if v != 0 {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if v == 0 {
+			return out
+		}
+	}
+}
+
+func sleb(v int64) []byte {
+	var out []byte
+	for {
+		b := byte(v & 0x7F)
+		v >>= 7
+		done := (v == 0 && b&0x40 == 0) || (v == -1 && b&0x40 != 0)
+		if !done {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if done {
+			return out
+		}
+	}
+}
+
+func section(id byte, payload []byte) []byte {
+	return append(append([]byte{id}, uleb(uint64(len(payload)))...), payload...)
+}
+
+func vec(items ...[]byte) []byte {
+	out := uleb(uint64(len(items)))
+	for _, item := range items {
+		out = append(out, item...)
+	}
+	return out
+}
+
+func funcExport(name string, idx byte) []byte {
+	out := append(uleb(uint64(len(name))), name...)
+	return append(out, 0x00, idx)
+}
+
+func body(locals, instrs []byte) []byte {
+	b := append(locals, instrs...)
+	b = append(b, 0x0B) // end
+	return append(uleb(uint64(len(b))), b...)
+}
+
+func packed(ptr, length int) int64 {
+	return int64(ptr)<<32 | int64(length)
+}
+
+// module assembles a two-function policy module: malloc always returns
+// 4096, and eval runs the given instructions (with the given extra i32
+// locals) over the given data segment at offset 1024.
+func module(evalLocals int, evalInstrs, data []byte) []byte {
+	out := []byte("\x00asm\x01\x00\x00\x00")
+	out = append(out, section(1, vec(
+		[]byte{0x60, 0x01, 0x7F, 0x01, 0x7F},       // (i32) -> i32
+		[]byte{0x60, 0x02, 0x7F, 0x7F, 0x01, 0x7E}, // (i32, i32) -> i64
+	))...)
+	out = append(out, section(3, vec([]byte{0x00}, []byte{0x01}))...)
+	out = append(out, section(5, vec([]byte{0x00, 0x01}))...) // one page
+	out = append(out, section(7, vec(
+		append(uleb(6), append([]byte("memory"), 0x02, 0x00)...),
+		funcExport("malloc", 0),
+		funcExport("eval", 1),
+	))...)
+	locals := vec()
+	if evalLocals > 0 {
+		locals = vec(append(uleb(uint64(evalLocals)), 0x7F))
+	}
+	out = append(out, section(10, vec(
+		body(vec(), append([]byte{0x41}, sleb(4096)...)),
+		body(locals, evalInstrs),
+	))...)
+	if data != nil {
+		seg := append([]byte{0x00, 0x41}, sleb(1024)...)
+		seg = append(seg, 0x0B)
+		seg = append(seg, uleb(uint64(len(data)))...)
+		seg = append(seg, data...)
+		out = append(out, section(11, vec(seg))...)
+	}
+	return out
+}
+
+func i64const(v int64) []byte {
+	return append([]byte{0x42}, sleb(v)...)
+}
+
+func TestCompileRejectsNonWasm(t *testing.T) {
+	if _, err := Compile([]byte("not a module")); err == nil {
+		t.Errorf("non-wasm input should be rejected")
+	}
+}
+
+func TestCompileRequiresABI(t *testing.T) {
+	raw := module(0, i64const(0), nil)
+	// strip the export section so the ABI check fails
+	broken := bytes.Replace(raw, []byte("eval"), []byte("oval"), 1)
+	if _, err := Compile(broken); err == nil {
+		t.Errorf("a module without an eval export should be rejected")
+	}
+}
+
+func TestEvalStaticViolations(t *testing.T) {
+	data := []byte(`[{"msg":"denied by wasm"}]`)
+	m, err := Compile(module(0, i64const(packed(1024, len(data))), data))
+	if err != nil {
+		t.Fatalf("compiling module: %s", err)
+	}
+	inst, err := m.Instantiate()
+	if err != nil {
+		t.Fatalf("instantiating module: %s", err)
+	}
+	out, err := inst.EvalJSON([]byte(`{"review":{}}`))
+	if err != nil {
+		t.Fatalf("evaluating module: %s", err)
+	}
+	if string(out) != string(data) {
+		t.Errorf("output = %q, want %q", out, data)
+	}
+}
+
+func TestEvalBranching(t *testing.T) {
+	// eval returns the violations segment only for inputs longer than
+	// three bytes, exercising if/else
+	data := []byte(`[{"msg":"long input"}]`)
+	instrs := []byte{0x20, 0x01, 0x41, 0x03, 0x4B, 0x04, 0x7E} // local.get 1; 3; i32.gt_u; if (result i64)
+	instrs = append(instrs, i64const(packed(1024, len(data)))...)
+	instrs = append(instrs, 0x05) // else
+	instrs = append(instrs, i64const(packed(0, 0))...)
+	instrs = append(instrs, 0x0B) // end if
+
+	m, err := Compile(module(0, instrs, data))
+	if err != nil {
+		t.Fatalf("compiling module: %s", err)
+	}
+	for input, want := range map[string]string{
+		`{"a":1}`: string(data),
+		`{}`:      "",
+	} {
+		inst, err := m.Instantiate()
+		if err != nil {
+			t.Fatalf("instantiating module: %s", err)
+		}
+		out, err := inst.EvalJSON([]byte(input))
+		if err != nil {
+			t.Fatalf("evaluating module: %s", err)
+		}
+		if string(out) != want {
+			t.Errorf("EvalJSON(%q) = %q, want %q", input, out, want)
+		}
+	}
+}
+
+func TestEvalLoop(t *testing.T) {
+	// eval computes 5 * len via repeated addition, exercising loop,
+	// br_if, locals, and extension
+	instrs := []byte{
+		0x03, 0x40, // loop
+		0x20, 0x02, 0x20, 0x01, 0x6A, 0x21, 0x02, // acc += len
+		0x20, 0x03, 0x41, 0x01, 0x6A, 0x22, 0x03, // i++
+		0x41, 0x05, 0x49, 0x0D, 0x00, // i < 5? br_if loop
+		0x0B,       // end loop
+		0x20, 0x02, // acc
+		0xAD, // i64.extend_i32_u
+	}
+	m, err := Compile(module(2, instrs, nil))
+	if err != nil {
+		t.Fatalf("compiling module: %s", err)
+	}
+	inst, err := m.Instantiate()
+	if err != nil {
+		t.Fatalf("instantiating module: %s", err)
+	}
+	got, err := inst.call("eval", 0, 7)
+	if err != nil {
+		t.Fatalf("calling eval: %s", err)
+	}
+	if got != 35 {
+		t.Errorf("eval(0, 7) = %d, want 35", got)
+	}
+}
+
+func TestEvalBudget(t *testing.T) {
+	// an unconditional backwards branch must hit the fuel limit rather
+	// than hang
+	instrs := []byte{
+		0x03, 0x40, // loop
+		0x0C, 0x00, // br loop
+		0x0B,       // end loop
+		0x42, 0x00, // unreachable in practice
+	}
+	m, err := Compile(module(0, instrs, nil))
+	if err != nil {
+		t.Fatalf("compiling module: %s", err)
+	}
+	inst, err := m.Instantiate()
+	if err != nil {
+		t.Fatalf("instantiating module: %s", err)
+	}
+	if _, err := inst.call("eval", 0, 0); err == nil {
+		t.Errorf("a non-terminating module should exhaust its budget")
+	}
+}
+
+func TestCompileRejectsImports(t *testing.T) {
+	raw := []byte("\x00asm\x01\x00\x00\x00")
+	// import section declaring env.f
+	imports := vec(append(append(append(uleb(3), "env"...), uleb(1)...), 'f', 0x00, 0x00))
+	raw = append(raw, section(1, vec([]byte{0x60, 0x00, 0x00}))...)
+	raw = append(raw, section(2, imports)...)
+	if _, err := Compile(raw); err == nil {
+		t.Errorf("modules with imports should be rejected")
+	}
+}
@@ -0,0 +1,327 @@
+// Package wasmengine evaluates constraint templates that ship a
+// compiled WebAssembly module alongside the Rego engine. A template
+// opts in by declaring targets[].code entries with engine Wasm, whose
+// source.module holds the base64-encoded binary. Modules follow the
+// policy ABI documented in interp.go: the review and constraint
+// parameters go in as JSON, violations come back as JSON. The engine is
+// a self-contained interpreter for the WebAssembly MVP integer subset,
+// so policies compiled against that ABI evaluate without a Rego compile
+// on the hot path. Modules must be built for the ABI directly; the
+// output of "opa build -t wasm" is not supported, because it requires
+// imported host functions, indirect-call tables, and floating point,
+// all outside the interpreter's subset. It wraps the OPA client as a
+// decorator, so both the webhook and audit evaluate whichever engine a
+// template declares.
+package wasmengine
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"sort"
+	"sync"
+
+	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/core/templates"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/types"
+	"github.com/open-policy-agent/gatekeeper/pkg/enginereview"
+	"github.com/open-policy-agent/gatekeeper/pkg/opashard"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var enableWasm = flag.Bool("experimental-enable-wasm-policies", false, "(alpha) evaluate constraint template code targets declaring engine Wasm with the built-in WebAssembly interpreter")
+
+// Engine is the engine name a template's code entry declares to be
+// evaluated here.
+const Engine = "Wasm"
+
+// Enabled returns true if wasm template evaluation was requested via
+// flags.
+func Enabled() bool {
+	return *enableWasm
+}
+
+// Client wraps an OPA client and additionally evaluates wasm templates
+// on Review. Templates and constraints are read through the manager's
+// cached client, so no second watch pipeline is needed. It implements
+// opashard.Client.
+type Client struct {
+	opashard.Client
+	reader client.Reader
+
+	mux     sync.Mutex
+	modules map[string]*Module // compiled modules by base64 source
+	kinds   map[string]string  // module source by constraint kind
+}
+
+var _ = opashard.Client(&Client{})
+
+// New wraps inner with wasm template evaluation.
+func New(inner opashard.Client, reader client.Reader) *Client {
+	return &Client{Client: inner, reader: reader, modules: map[string]*Module{}, kinds: map[string]string{}}
+}
+
+// AddTemplate indexes the template's wasm module by its constraint kind,
+// so reviews evaluate against the index instead of listing templates.
+// Rego templates pass through to the wrapped engine; wasm-only templates
+// have no Rego for it to compile, so they are acknowledged here and
+// served entirely by this engine.
+func (c *Client) AddTemplate(ctx context.Context, templ *templates.ConstraintTemplate) (*types.Responses, error) {
+	kind := templ.Spec.CRD.Spec.Names.Kind
+	source := c.templateModule(ctx, templ.GetName())
+	c.mux.Lock()
+	if source != "" {
+		c.kinds[kind] = source
+	} else {
+		delete(c.kinds, kind)
+	}
+	c.mux.Unlock()
+	if source != "" && !hasRego(templ) {
+		return types.NewResponses(), nil
+	}
+	return c.Client.AddTemplate(ctx, templ)
+}
+
+// RemoveTemplate drops the template from the index and tolerates the
+// wrapped engine not knowing wasm-only templates it was never handed.
+func (c *Client) RemoveTemplate(ctx context.Context, templ *templates.ConstraintTemplate) (*types.Responses, error) {
+	c.mux.Lock()
+	delete(c.kinds, templ.Spec.CRD.Spec.Names.Kind)
+	c.mux.Unlock()
+	resp, err := c.Client.RemoveTemplate(ctx, templ)
+	if err != nil && !hasRego(templ) {
+		return types.NewResponses(), nil
+	}
+	return resp, err
+}
+
+func hasRego(templ *templates.ConstraintTemplate) bool {
+	for _, t := range templ.Spec.Targets {
+		if t.Rego != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// templateModule fetches the named cluster template and returns the
+// wasm module source it declares, if any.
+func (c *Client) templateModule(ctx context.Context, name string) string {
+	t := &unstructured.Unstructured{}
+	t.SetGroupVersionKind(schema.GroupVersionKind{Group: "templates.gatekeeper.sh", Version: "v1beta1", Kind: "ConstraintTemplate"})
+	if err := c.reader.Get(ctx, k8stypes.NamespacedName{Name: name}, t); err != nil {
+		return ""
+	}
+	return ModuleSource(t)
+}
+
+// Review evaluates the wrapped engine first, then every matching wasm
+// constraint, and merges the results.
+func (c *Client) Review(ctx context.Context, obj interface{}, opts ...opa.QueryOpt) (*types.Responses, error) {
+	resp, err := c.Client.Review(ctx, obj, opts...)
+	if err != nil {
+		return resp, err
+	}
+	review, ok := enginereview.Extract(obj)
+	if !ok {
+		return resp, nil
+	}
+	results, err := c.reviewWasm(ctx, review)
+	if err != nil {
+		return resp, err
+	}
+	if len(results) == 0 {
+		return resp, nil
+	}
+
+	targetName := (&target.K8sValidationTarget{}).GetName()
+	if resp.ByTarget == nil {
+		resp.ByTarget = map[string]*types.Response{}
+	}
+	if resp.ByTarget[targetName] == nil {
+		resp.ByTarget[targetName] = &types.Response{Target: targetName}
+	}
+	resp.ByTarget[targetName].Results = append(resp.ByTarget[targetName].Results, results...)
+	return resp, nil
+}
+
+// reviewWasm evaluates every wasm constraint matching the review. Wasm
+// kinds come from the index AddTemplate maintains, so only constraints
+// of kinds that actually declare a module are listed.
+func (c *Client) reviewWasm(ctx context.Context, rev *enginereview.Review) ([]*types.Result, error) {
+	c.mux.Lock()
+	kinds := make([]string, 0, len(c.kinds))
+	sources := make(map[string]string, len(c.kinds))
+	for kind, source := range c.kinds {
+		kinds = append(kinds, kind)
+		sources[kind] = source
+	}
+	c.mux.Unlock()
+	sort.Strings(kinds)
+
+	var results []*types.Result
+	for _, kind := range kinds {
+		constraints := &unstructured.UnstructuredList{}
+		constraints.SetGroupVersionKind(schema.GroupVersionKind{Group: "constraints.gatekeeper.sh", Version: "v1beta1", Kind: kind + "List"})
+		if err := c.reader.List(ctx, constraints); err != nil {
+			// the constraint CRD registers asynchronously after its template
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, fmt.Errorf("listing %s constraints: %w", kind, err)
+		}
+		for j := range constraints.Items {
+			constraint := &constraints.Items[j]
+			matched, err := enginereview.Matches(constraint, rev)
+			if err != nil {
+				// mirror the Rego matcher's autoreject: an unevaluable
+				// selector fails loudly instead of silently changing the
+				// constraint's scope
+				results = append(results, enginereview.MatchFailure(constraint, err))
+				continue
+			}
+			if !matched {
+				continue
+			}
+			results = append(results, c.evaluate(constraint, sources[kind], rev)...)
+		}
+	}
+	return results, nil
+}
+
+// violation is one entry of the JSON a module returns.
+type violation struct {
+	Msg string `json:"msg"`
+}
+
+// evaluate runs the module for one constraint. A module that fails to
+// compile, traps, or returns malformed output is reported as a
+// violation, so broken policies fail closed.
+func (c *Client) evaluate(constraint *unstructured.Unstructured, source string, rev *enginereview.Review) []*types.Result {
+	action := enginereview.EnforcementAction(constraint)
+	failure := func(err error) []*types.Result {
+		return []*types.Result{{
+			Msg:               fmt.Sprintf("constraint %s: %v", constraint.GetName(), err),
+			Constraint:        constraint,
+			EnforcementAction: action,
+		}}
+	}
+
+	params, _, _ := unstructured.NestedMap(constraint.Object, "spec", "parameters")
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	input, err := json.Marshal(map[string]interface{}{
+		"review": map[string]interface{}{
+			"object":    rev.Object,
+			"oldObject": rev.OldObject,
+			"request":   rev.Request,
+		},
+		"parameters": params,
+	})
+	if err != nil {
+		return failure(err)
+	}
+
+	module, err := c.module(source)
+	if err != nil {
+		return failure(err)
+	}
+	// instances are not safe for concurrent use; reviews run in parallel
+	instance, err := module.Instantiate()
+	if err != nil {
+		return failure(err)
+	}
+	out, err := instance.EvalJSON(input)
+	if err != nil {
+		return failure(err)
+	}
+	var violations []violation
+	if err := json.Unmarshal(out, &violations); err != nil {
+		return failure(fmt.Errorf("module returned malformed violations: %v", err))
+	}
+
+	var results []*types.Result
+	for _, v := range violations {
+		results = append(results, &types.Result{
+			Msg:               v.Msg,
+			Constraint:        constraint,
+			EnforcementAction: action,
+		})
+	}
+	return results
+}
+
+// module returns the compiled form of a base64 module source, compiling
+// and caching it on first use.
+func (c *Client) module(source string) (*Module, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if m, ok := c.modules[source]; ok {
+		return m, nil
+	}
+	wasm, err := base64.StdEncoding.DecodeString(source)
+	if err != nil {
+		return nil, fmt.Errorf("decoding wasm module: %v", err)
+	}
+	m, err := Compile(wasm)
+	if err != nil {
+		return nil, fmt.Errorf("compiling wasm module: %w", err)
+	}
+	c.modules[source] = m
+	return m, nil
+}
+
+// ModuleSource extracts the base64-encoded wasm module a template
+// declares for this engine, across all its targets. Only one module per
+// template is supported; the first is taken.
+func ModuleSource(t *unstructured.Unstructured) string {
+	targets, _, _ := unstructured.NestedSlice(t.Object, "spec", "targets")
+	for _, tgt := range targets {
+		targetMap, ok := tgt.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		code, _, _ := unstructured.NestedSlice(targetMap, "code")
+		for _, entry := range code {
+			entryMap, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			engine, _, _ := unstructured.NestedString(entryMap, "engine")
+			if engine != Engine {
+				continue
+			}
+			module, _, _ := unstructured.NestedString(entryMap, "source", "module")
+			if module != "" {
+				return module
+			}
+		}
+	}
+	return ""
+}
+
+// ValidateTemplate rejects templates whose wasm module does not decode,
+// does not fit the supported subset, or does not export the policy ABI,
+// so mistakes surface at write time instead of at review.
+func ValidateTemplate(t *unstructured.Unstructured) error {
+	source := ModuleSource(t)
+	if source == "" {
+		return nil
+	}
+	wasm, err := base64.StdEncoding.DecodeString(source)
+	if err != nil {
+		return fmt.Errorf("decoding wasm module: %v", err)
+	}
+	if _, err := Compile(wasm); err != nil {
+		return fmt.Errorf("compiling wasm module: %w", err)
+	}
+	return nil
+}
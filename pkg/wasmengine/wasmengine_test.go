@@ -0,0 +1,90 @@
+package wasmengine
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/enginereview"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func wasmTemplate(engine, source string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "templates.gatekeeper.sh/v1beta1",
+		"kind":       "ConstraintTemplate",
+		"metadata":   map[string]interface{}{"name": "k8srequiredlabels"},
+		"spec": map[string]interface{}{
+			"targets": []interface{}{
+				map[string]interface{}{
+					"target": "admission.k8s.gatekeeper.sh",
+					"code": []interface{}{
+						map[string]interface{}{
+							"engine": engine,
+							"source": map[string]interface{}{"module": source},
+						},
+					},
+				},
+			},
+		},
+	}}
+}
+
+func TestModuleSource(t *testing.T) {
+	if got := ModuleSource(wasmTemplate(Engine, "AGFzbQ==")); got != "AGFzbQ==" {
+		t.Errorf("ModuleSource() = %q, want %q", got, "AGFzbQ==")
+	}
+	if got := ModuleSource(wasmTemplate("K8sNativeValidation", "AGFzbQ==")); got != "" {
+		t.Errorf("ModuleSource() = %q for a non-wasm engine, want empty", got)
+	}
+	plain := &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{}}}
+	if got := ModuleSource(plain); got != "" {
+		t.Errorf("ModuleSource() = %q for a template without code, want empty", got)
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	data := []byte(`[]`)
+	good := base64.StdEncoding.EncodeToString(module(0, i64const(packed(1024, len(data))), data))
+	if err := ValidateTemplate(wasmTemplate(Engine, good)); err != nil {
+		t.Errorf("a valid module should be accepted, got: %s", err)
+	}
+	if err := ValidateTemplate(wasmTemplate(Engine, "!!not base64!!")); err == nil {
+		t.Errorf("undecodable module sources should be rejected")
+	}
+	if err := ValidateTemplate(wasmTemplate(Engine, base64.StdEncoding.EncodeToString([]byte("junk")))); err == nil {
+		t.Errorf("non-wasm module sources should be rejected")
+	}
+	if err := ValidateTemplate(wasmTemplate("K8sNativeValidation", "ignored")); err != nil {
+		t.Errorf("templates without a wasm module should be accepted, got: %s", err)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	data := []byte(`[{"msg":"denied by wasm"}]`)
+	source := base64.StdEncoding.EncodeToString(module(0, i64const(packed(1024, len(data))), data))
+	constraint := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "constraints.gatekeeper.sh/v1beta1",
+		"kind":       "K8sRequiredLabels",
+		"metadata":   map[string]interface{}{"name": "must-have-owner"},
+		"spec":       map[string]interface{}{"enforcementAction": "warn"},
+	}}
+	rev := &enginereview.Review{Object: map[string]interface{}{"kind": "Pod"}}
+
+	c := New(nil, nil)
+	results := c.evaluate(constraint, source, rev)
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	if results[0].Msg != "denied by wasm" {
+		t.Errorf("Msg = %q, want %q", results[0].Msg, "denied by wasm")
+	}
+	if results[0].EnforcementAction != "warn" {
+		t.Errorf("EnforcementAction = %q, want %q", results[0].EnforcementAction, "warn")
+	}
+
+	// a broken module fails closed
+	broken := c.evaluate(constraint, "AGFzbQ==", rev)
+	if len(broken) != 1 {
+		t.Fatalf("got %d results for a broken module, want 1", len(broken))
+	}
+}
@@ -0,0 +1,142 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/controller/config/process"
+	"github.com/open-policy-agent/gatekeeper/pkg/logging"
+	"github.com/open-policy-agent/gatekeeper/pkg/mutation"
+	"github.com/open-policy-agent/gatekeeper/pkg/opashard"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+var enableAuthorizationWebhook = flag.Bool("enable-authorization-webhook", false, "(alpha) serve a Kubernetes authorization webhook that evaluates SubjectAccessReview requests against constraints, extending policy from admitted objects to who may do what")
+
+func init() {
+	AddToManagerFuncs = append(AddToManagerFuncs, AddAuthorizationWebhook)
+}
+
+// sarGroupVersionKind is the review context constraints match on; a
+// constraint targeting SubjectAccessReview in the authorization.k8s.io
+// group applies to authorization requests.
+const (
+	sarAPIVersion = "authorization.k8s.io/v1"
+	sarKind       = "SubjectAccessReview"
+)
+
+// AddAuthorizationWebhook registers the authorization webhook with the
+// manager when enabled. Unlike admission, the authorization protocol is
+// not an AdmissionReview exchange, so the handler serves the raw
+// SubjectAccessReview wire format instead of going through
+// controller-runtime's admission plumbing.
+func AddAuthorizationWebhook(mgr manager.Manager, opa opashard.Client, _ *process.Excluder, _ *mutation.System) error {
+	if !*enableAuthorizationWebhook {
+		return nil
+	}
+	registerWebhook(mgr, "/v1/authorize", &authorizationHandler{opa: opa})
+	return nil
+}
+
+type authorizationHandler struct {
+	opa opashard.Client
+}
+
+// ServeHTTP implements the webhook authorizer protocol: a POSTed
+// SubjectAccessReview comes back with its status filled in. Gatekeeper
+// only ever denies — when no deny constraint matches, the response
+// carries no opinion so the remaining authorizers in the chain decide.
+func (h *authorizationHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	sar := &authorizationv1.SubjectAccessReview{}
+	if err := json.NewDecoder(req.Body).Decode(sar); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	msgs, err := h.reviewSubjectAccess(req.Context(), sar)
+	sar.APIVersion = sarAPIVersion
+	sar.Kind = sarKind
+	sar.Status = authorizationv1.SubjectAccessReviewStatus{}
+	switch {
+	case err != nil:
+		// evaluation errors must not lock users out; report the error and
+		// leave the decision to the rest of the authorizer chain
+		log.Error(err, "error evaluating subject access review", logging.RequestUsername, sar.Spec.User)
+		sar.Status.EvaluationError = err.Error()
+	case len(msgs) > 0:
+		sar.Status.Denied = true
+		sar.Status.Reason = strings.Join(msgs, "\n")
+		log.Info("denied authorization",
+			logging.Process, "authorization",
+			logging.EventType, "violation",
+			logging.RequestUsername, sar.Spec.User,
+			"denials", sar.Status.Reason,
+		)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(sar); err != nil {
+		log.Error(err, "unable to encode subject access review response")
+	}
+}
+
+// reviewSubjectAccess evaluates the request against loaded constraints
+// and returns the deny messages. The SubjectAccessReview itself is the
+// object under review, so templates inspect the requesting user and the
+// requested action under review.object.spec.
+func (h *authorizationHandler) reviewSubjectAccess(ctx context.Context, sar *authorizationv1.SubjectAccessReview) ([]string, error) {
+	obj, err := wrapSubjectAccessReview(sar)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := h.opa.Review(ctx, target.AugmentedUnstructured{Object: *obj})
+	if err != nil {
+		return nil, err
+	}
+	var msgs []string
+	for _, r := range resp.Results() {
+		// as with admission, only the deny enforcement action blocks the
+		// request; other actions are surfaced by audit
+		if r.EnforcementAction != "deny" {
+			continue
+		}
+		msgs = append(msgs, fmt.Sprintf("[denied by %s] %s", r.Constraint.GetName(), r.Msg))
+	}
+	return msgs, nil
+}
+
+// wrapSubjectAccessReview turns the review into an unstructured object
+// constraints can match on. SubjectAccessReviews are unnamed on the
+// wire, so a synthetic name identifies the request in violation
+// messages, and resource requests inherit the target namespace so
+// namespace-scoped match criteria apply.
+func wrapSubjectAccessReview(sar *authorizationv1.SubjectAccessReview) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(sar)
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{Object: content}
+	obj.SetAPIVersion(sarAPIVersion)
+	obj.SetKind(sarKind)
+	name := sar.Spec.User
+	if name == "" {
+		name = "anonymous"
+	}
+	obj.SetName(name)
+	if sar.Spec.ResourceAttributes != nil {
+		obj.SetNamespace(sar.Spec.ResourceAttributes.Namespace)
+	}
+	return obj, nil
+}
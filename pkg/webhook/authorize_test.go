@@ -0,0 +1,178 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	templv1beta1 "github.com/open-policy-agent/frameworks/constraint/pkg/apis/templates/v1beta1"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/core/templates"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	sarTemplate = `
+apiVersion: templates.gatekeeper.sh/v1beta1
+kind: ConstraintTemplate
+metadata:
+  name: k8sdenysecretreads
+spec:
+  crd:
+    spec:
+      names:
+        kind: K8sDenySecretReads
+  targets:
+    - target: admission.k8s.gatekeeper.sh
+      rego: |
+        package denysecretreads
+
+        violation[{"msg": msg}] {
+          input.review.object.spec.resourceAttributes.resource == "secrets"
+          msg := sprintf("user %v may not read secrets", [input.review.object.spec.user])
+        }
+`
+
+	sarConstraint = `
+apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sDenySecretReads
+metadata:
+  name: deny-secret-reads
+spec:
+  match:
+    kinds:
+      - apiGroups: ["authorization.k8s.io"]
+        kinds: ["SubjectAccessReview"]
+`
+)
+
+func makeAuthorizationHandler(t *testing.T) *authorizationHandler {
+	t.Helper()
+	opa, err := makeOpaClient()
+	if err != nil {
+		t.Fatalf("Could not initialize OPA: %s", err)
+	}
+	cstr := &templv1beta1.ConstraintTemplate{}
+	if err := yaml.Unmarshal([]byte(sarTemplate), cstr); err != nil {
+		t.Fatalf("Could not instantiate template: %s", err)
+	}
+	unversioned := &templates.ConstraintTemplate{}
+	if err := runtimeScheme.Convert(cstr, unversioned, nil); err != nil {
+		t.Fatalf("Could not convert to unversioned: %v", err)
+	}
+	if _, err := opa.AddTemplate(context.Background(), unversioned); err != nil {
+		t.Fatalf("Could not add template: %s", err)
+	}
+	b, err := yaml.YAMLToJSON([]byte(sarConstraint))
+	if err != nil {
+		t.Fatalf("Error parsing yaml: %s", err)
+	}
+	u := &unstructured.Unstructured{}
+	if err := u.UnmarshalJSON(b); err != nil {
+		t.Fatalf("Could not parse constraint: %s", err)
+	}
+	if _, err := opa.AddConstraint(context.Background(), u); err != nil {
+		t.Fatalf("Could not add constraint: %s", err)
+	}
+	return &authorizationHandler{opa: opa}
+}
+
+func postSubjectAccessReview(t *testing.T, handler *authorizationHandler, sar *authorizationv1.SubjectAccessReview) *authorizationv1.SubjectAccessReview {
+	t.Helper()
+	body, err := json.Marshal(sar)
+	if err != nil {
+		t.Fatalf("Could not marshal review: %s", err)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/authorize", strings.NewReader(string(body))))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	out := &authorizationv1.SubjectAccessReview{}
+	if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+		t.Fatalf("Could not parse response: %s", err)
+	}
+	return out
+}
+
+func TestAuthorizationDeny(t *testing.T) {
+	handler := makeAuthorizationHandler(t)
+	out := postSubjectAccessReview(t, handler, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User: "jane",
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Verb:      "get",
+				Resource:  "secrets",
+				Namespace: "prod",
+			},
+		},
+	})
+	if !out.Status.Denied {
+		t.Fatalf("expected denial, got status %+v", out.Status)
+	}
+	if !strings.Contains(out.Status.Reason, "jane may not read secrets") {
+		t.Errorf("reason should carry the violation message, got %q", out.Status.Reason)
+	}
+	if out.Status.Allowed {
+		t.Errorf("the webhook must never allow, got status %+v", out.Status)
+	}
+}
+
+func TestAuthorizationNoOpinion(t *testing.T) {
+	handler := makeAuthorizationHandler(t)
+	out := postSubjectAccessReview(t, handler, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User: "jane",
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Verb:     "list",
+				Resource: "pods",
+			},
+		},
+	})
+	if out.Status.Denied || out.Status.Allowed {
+		t.Errorf("non-matching requests should get no opinion, got status %+v", out.Status)
+	}
+}
+
+func TestAuthorizationRejectsBadRequests(t *testing.T) {
+	handler := makeAuthorizationHandler(t)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/authorize", strings.NewReader("not json")))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("malformed requests should be rejected, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/authorize", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET should be rejected, got %d", rec.Code)
+	}
+}
+
+func TestWrapSubjectAccessReview(t *testing.T) {
+	obj, err := wrapSubjectAccessReview(&authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:               "jane",
+			ResourceAttributes: &authorizationv1.ResourceAttributes{Namespace: "prod"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("wrapping review: %s", err)
+	}
+	if obj.GetAPIVersion() != sarAPIVersion || obj.GetKind() != sarKind {
+		t.Errorf("review object should carry the SubjectAccessReview GVK, got %s/%s", obj.GetAPIVersion(), obj.GetKind())
+	}
+	if obj.GetName() != "jane" || obj.GetNamespace() != "prod" {
+		t.Errorf("review object should be named for the user in the target namespace, got %s/%s", obj.GetNamespace(), obj.GetName())
+	}
+	obj, err = wrapSubjectAccessReview(&authorizationv1.SubjectAccessReview{})
+	if err != nil {
+		t.Fatalf("wrapping review: %s", err)
+	}
+	if obj.GetName() != "anonymous" {
+		t.Errorf("unnamed users should fall back to anonymous, got %q", obj.GetName())
+	}
+}
@@ -14,9 +14,11 @@ import (
 	"github.com/open-policy-agent/gatekeeper/pkg/util"
 	admissionv1 "k8s.io/api/admission/v1"
 	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	k8sruntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -126,7 +128,7 @@ func (h *webhookHandler) tracingLevel(ctx context.Context, req admission.Request
 	return traceEnabled, dump
 }
 
-func (h *webhookHandler) skipExcludedNamespace(req admissionv1.AdmissionRequest, excludedProcess process.Process) (bool, error) {
+func (h *webhookHandler) skipExcludedNamespace(ctx context.Context, req admissionv1.AdmissionRequest, excludedProcess process.Process) (bool, error) {
 	obj := &unstructured.Unstructured{}
 	if _, _, err := deserializer.Decode(req.Object.Raw, nil, obj); err != nil {
 		return false, err
@@ -136,6 +138,24 @@ func (h *webhookHandler) skipExcludedNamespace(req admissionv1.AdmissionRequest,
 	if err != nil {
 		return false, err
 	}
+	if isNamespaceExcluded {
+		return true, nil
+	}
+
+	// name-based exclusion did not apply; for namespaced objects, also
+	// evaluate any label-based exclusion against the owning namespace.
+	// IsNamespaceExcluded already checks labels on Namespace objects
+	// themselves.
+	if obj.GetKind() == namespaceKind && obj.GroupVersionKind().Group == "" {
+		return false, nil
+	}
+	if req.Namespace == "" || h.client == nil || !h.processExcluder.HasNamespaceSelectors(excludedProcess) {
+		return false, nil
+	}
+	ns := &corev1.Namespace{}
+	if err := h.client.Get(ctx, types.NamespacedName{Name: req.Namespace}, ns); err != nil {
+		return false, err
+	}
 
-	return isNamespaceExcluded, err
+	return h.processExcluder.IsNamespaceExcludedByLabels(excludedProcess, ns.Labels), nil
 }
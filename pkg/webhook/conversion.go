@@ -0,0 +1,28 @@
+package webhook
+
+import (
+	"github.com/open-policy-agent/gatekeeper/pkg/controller/config/process"
+	"github.com/open-policy-agent/gatekeeper/pkg/mutation"
+	"github.com/open-policy-agent/gatekeeper/pkg/opashard"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+)
+
+func init() {
+	AddToManagerFuncs = append(AddToManagerFuncs, AddConversionWebhook)
+}
+
+// AddConversionWebhook serves CRD version conversion for resources with
+// multiple served versions, currently the Config resource (v1alpha1 and
+// v1). The handler is generic: it converts any scheme type implementing
+// the Convertible interface through its hub version.
+func AddConversionWebhook(mgr manager.Manager, _ opashard.Client, _ *process.Excluder, _ *mutation.System) error {
+	wh := &conversion.Webhook{}
+	// the scheme is injected explicitly so conversion also works behind
+	// the hardened server, which does not inject fields on registration
+	if err := wh.InjectScheme(mgr.GetScheme()); err != nil {
+		return err
+	}
+	registerWebhook(mgr, "/convert", wh)
+	return nil
+}
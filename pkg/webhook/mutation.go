@@ -14,23 +14,24 @@ package webhook
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/open-policy-agent/cert-controller/pkg/rotator"
-	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
 	"github.com/open-policy-agent/gatekeeper/apis"
 	mutationsv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/mutations/v1alpha1"
 	"github.com/open-policy-agent/gatekeeper/pkg/controller/config/process"
 	"github.com/open-policy-agent/gatekeeper/pkg/mutation"
+	"github.com/open-policy-agent/gatekeeper/pkg/opashard"
+	"github.com/open-policy-agent/gatekeeper/pkg/tracing"
 	"github.com/open-policy-agent/gatekeeper/pkg/util"
 	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
@@ -54,7 +55,7 @@ func init() {
 // TODO enable this once mutation is beta +kubebuilder:rbac:groups=*,resources=*,verbs=get;list;watch;update
 
 // AddMutatingWebhook registers the mutating webhook server with the manager
-func AddMutatingWebhook(mgr manager.Manager, client *opa.Client, processExcluder *process.Excluder, mutationSystem *mutation.System) error {
+func AddMutatingWebhook(mgr manager.Manager, client opashard.Client, processExcluder *process.Excluder, mutationSystem *mutation.System) error {
 	if !*mutation.MutationEnabled {
 		return nil
 	}
@@ -90,7 +91,7 @@ func AddMutatingWebhook(mgr manager.Manager, client *opa.Client, processExcluder
 	if err := wh.InjectLogger(log); err != nil {
 		return err
 	}
-	mgr.GetWebhookServer().Register("/v1/mutate", wh)
+	registerWebhook(mgr, "/v1/mutate", wh)
 
 	return nil
 }
@@ -117,6 +118,12 @@ func (h *mutationHandler) Handle(ctx context.Context, req admission.Request) adm
 		return admission.ValidationResponse(true, "Mutating only on create or update")
 	}
 
+	ctx, span := tracing.StartSpan(ctx, "admission.mutate",
+		tracing.Attribute("resource_kind", req.AdmissionRequest.Kind.Kind),
+		tracing.Attribute("resource_namespace", req.AdmissionRequest.Namespace),
+	)
+	defer span.End()
+
 	if userErr, err := h.validateGatekeeperResources(ctx, req); err != nil {
 		vResp := admission.ValidationResponse(false, err.Error())
 		if vResp.Result == nil {
@@ -144,7 +151,7 @@ func (h *mutationHandler) Handle(ctx context.Context, req admission.Request) adm
 	}()
 
 	// namespace is excluded from webhook using config
-	isExcludedNamespace, err := h.skipExcludedNamespace(req.AdmissionRequest, process.Mutation)
+	isExcludedNamespace, err := h.skipExcludedNamespace(ctx, req.AdmissionRequest, process.Mutation)
 	if err != nil {
 		log.Error(err, "error while excluding namespace")
 	}
@@ -196,14 +203,15 @@ func (h *mutationHandler) mutateRequest(ctx context.Context, req admission.Reque
 	} else {
 		ns = nil
 	}
-	obj := unstructured.Unstructured{}
+	obj := getScratchObject()
+	defer putScratchObject(obj)
 	err := obj.UnmarshalJSON(req.Object.Raw)
 	if err != nil {
 		log.Error(err, "failed to unmarshal", "object", string(req.Object.Raw))
 		return admission.Errored(int32(http.StatusInternalServerError), err), nil
 	}
 
-	mutated, err := h.mutationSystem.Mutate(&obj, ns)
+	mutated, err := h.mutationSystem.Mutate(obj, ns)
 	if err != nil {
 		log.Error(err, "failed to mutate object", "object", string(req.Object.Raw))
 		return admission.Errored(int32(http.StatusInternalServerError), err), nil
@@ -212,12 +220,13 @@ func (h *mutationHandler) mutateRequest(ctx context.Context, req admission.Reque
 		return admission.ValidationResponse(true, "Resource was not mutated"), nil
 	}
 
-	newJSON, err := obj.MarshalJSON()
-	if err != nil {
+	buf := getEncodeBuffer()
+	defer putEncodeBuffer(buf)
+	if err := json.NewEncoder(buf).Encode(obj.Object); err != nil {
 		log.Error(err, "failed to marshal mutated object", "object", obj)
 		return admission.Errored(int32(http.StatusInternalServerError), err), nil
 	}
-	resp := admission.PatchResponseFromRaw(req.Object.Raw, newJSON)
+	resp := admission.PatchResponseFromRaw(req.Object.Raw, buf.Bytes())
 	return resp, nil
 }
 
@@ -240,6 +249,27 @@ func (h *mutationHandler) validateGatekeeperResources(ctx context.Context, req a
 	if req.AdmissionRequest.Kind.Group == mutationsGroup && req.AdmissionRequest.Kind.Kind == "Assign" {
 		return h.validateAssign(ctx, req)
 	}
+	if req.AdmissionRequest.Kind.Group == mutationsGroup && req.AdmissionRequest.Kind.Kind == "JSONPatch" {
+		return h.validateJSONPatch(ctx, req)
+	}
+	return false, nil
+}
+
+func (h *mutationHandler) validateJSONPatch(ctx context.Context, req admission.Request) (bool, error) {
+	obj, _, err := deserializer.Decode(req.AdmissionRequest.Object.Raw, nil, &mutationsv1alpha1.JSONPatch{})
+	if err != nil {
+		return false, err
+	}
+	jp, ok := obj.(*mutationsv1alpha1.JSONPatch)
+	if !ok {
+		return false, fmt.Errorf("Deserialized object is not of type JSONPatch")
+	}
+
+	err = mutation.IsValidJSONPatch(jp)
+	if err != nil {
+		return true, err
+	}
+
 	return false, nil
 }
 
@@ -7,12 +7,11 @@ import (
 	"fmt"
 	"net/http"
 
-	opa "github.com/open-policy-agent/frameworks/constraint/pkg/client"
 	"github.com/open-policy-agent/gatekeeper/pkg/controller/config/process"
 	"github.com/open-policy-agent/gatekeeper/pkg/mutation"
+	"github.com/open-policy-agent/gatekeeper/pkg/opashard"
 	"github.com/pkg/errors"
 	admissionv1 "k8s.io/api/admission/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
@@ -52,14 +51,14 @@ func (l nsSet) Set(s string) error {
 // +kubebuilder:webhook:verbs=CREATE;UPDATE,path=/v1/admitlabel,mutating=false,failurePolicy=fail,groups="",resources=namespaces,versions=*,name=check-ignore-label.gatekeeper.sh
 
 // AddLabelWebhook registers the label webhook server with the manager
-func AddLabelWebhook(mgr manager.Manager, _ *opa.Client, _ *process.Excluder, mutationCache *mutation.System) error {
+func AddLabelWebhook(mgr manager.Manager, _ opashard.Client, _ *process.Excluder, mutationCache *mutation.System) error {
 	wh := &admission.Webhook{Handler: &namespaceLabelHandler{}}
 	// TODO(https://github.com/open-policy-agent/gatekeeper/issues/661): remove log injection if the race condition in the cited bug is eliminated.
 	// Otherwise we risk having unstable logger names for the webhook.
 	if err := wh.InjectLogger(log); err != nil {
 		return err
 	}
-	mgr.GetWebhookServer().Register("/v1/admitlabel", wh)
+	registerWebhook(mgr, "/v1/admitlabel", wh)
 	return nil
 }
 
@@ -74,7 +73,8 @@ func (h *namespaceLabelHandler) Handle(ctx context.Context, req admission.Reques
 	if req.AdmissionRequest.Kind.Group != "" || req.AdmissionRequest.Kind.Kind != namespaceKind {
 		return admission.Allowed("Not a namespace")
 	}
-	obj := &unstructured.Unstructured{}
+	obj := getScratchObject()
+	defer putScratchObject(obj)
 	if err := json.Unmarshal(req.Object.Raw, obj); err != nil {
 		r := admission.Denied(errors.Wrap(err, "while deserializing resource").Error())
 		r.Result.Code = http.StatusInternalServerError
@@ -28,28 +28,56 @@ import (
 	"github.com/open-policy-agent/frameworks/constraint/pkg/core/templates"
 	rtypes "github.com/open-policy-agent/frameworks/constraint/pkg/types"
 	"github.com/open-policy-agent/gatekeeper/apis"
+	configv1 "github.com/open-policy-agent/gatekeeper/apis/config/v1"
+	configv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/config/v1alpha1"
+	exemptionsv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/exemptions/v1alpha1"
+	expansionv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/expansion/v1alpha1"
+	externaldatav1alpha1 "github.com/open-policy-agent/gatekeeper/apis/externaldata/v1alpha1"
+	overridesv1alpha1 "github.com/open-policy-agent/gatekeeper/apis/overrides/v1alpha1"
+	"github.com/open-policy-agent/gatekeeper/pkg/breakglass"
+	"github.com/open-policy-agent/gatekeeper/pkg/canary"
+	"github.com/open-policy-agent/gatekeeper/pkg/celengine"
+	"github.com/open-policy-agent/gatekeeper/pkg/changelog"
+	"github.com/open-policy-agent/gatekeeper/pkg/clusteridentity"
 	"github.com/open-policy-agent/gatekeeper/pkg/controller/config/process"
+	"github.com/open-policy-agent/gatekeeper/pkg/decisionlog"
+	"github.com/open-policy-agent/gatekeeper/pkg/degradation"
+	"github.com/open-policy-agent/gatekeeper/pkg/exemption"
+	"github.com/open-policy-agent/gatekeeper/pkg/expansion"
+	"github.com/open-policy-agent/gatekeeper/pkg/externaldata"
 	"github.com/open-policy-agent/gatekeeper/pkg/keys"
 	"github.com/open-policy-agent/gatekeeper/pkg/logging"
 	"github.com/open-policy-agent/gatekeeper/pkg/mutation"
+	"github.com/open-policy-agent/gatekeeper/pkg/opashard"
+	"github.com/open-policy-agent/gatekeeper/pkg/override"
+	"github.com/open-policy-agent/gatekeeper/pkg/selfprotect"
+	"github.com/open-policy-agent/gatekeeper/pkg/staticdeny"
 	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	"github.com/open-policy-agent/gatekeeper/pkg/templatelint"
+	"github.com/open-policy-agent/gatekeeper/pkg/tracing"
 	"github.com/open-policy-agent/gatekeeper/pkg/util"
+	"github.com/open-policy-agent/gatekeeper/pkg/verdictcache"
+	"github.com/open-policy-agent/gatekeeper/pkg/wasmengine"
 	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	clientcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 var (
 	maxServingThreads = flag.Int("max-serving-threads", -1, "(alpha) cap the number of threads handling non-trivial requests, -1 means an infinite number of threads")
+	auditOnlyMode     = flag.Bool("audit-only-mode", false, "treat every constraint as dryrun regardless of its declared enforcementAction; violations are still logged, reported, and surfaced by audit, but nothing is denied. Useful when first adopting Gatekeeper on an existing cluster")
 )
 
 func init() {
@@ -62,9 +90,12 @@ func init() {
 
 // +kubebuilder:webhook:verbs=create;update,path=/v1/admit,mutating=false,failurePolicy=ignore,groups=*,resources=*,versions=*,name=validation.gatekeeper.sh
 // +kubebuilder:rbac:groups=*,resources=*,verbs=get;list;watch
+// +kubebuilder:rbac:groups=exemptions.gatekeeper.sh,resources=exemptions,verbs=get;list;watch
+// +kubebuilder:rbac:groups=overrides.gatekeeper.sh,resources=constraintoverrides,verbs=get;list;watch
+// +kubebuilder:rbac:groups=expansion.gatekeeper.sh,resources=expansiontemplates,verbs=get;list;watch
 
 // AddPolicyWebhook registers the policy webhook server with the manager
-func AddPolicyWebhook(mgr manager.Manager, opa *opa.Client, processExcluder *process.Excluder, mutationCache *mutation.System) error {
+func AddPolicyWebhook(mgr manager.Manager, opa opashard.Client, processExcluder *process.Excluder, mutationCache *mutation.System) error {
 	reporter, err := newStatsReporter()
 	if err != nil {
 		return err
@@ -89,13 +120,41 @@ func AddPolicyWebhook(mgr manager.Manager, opa *opa.Client, processExcluder *pro
 	if *maxServingThreads > 0 {
 		handler.semaphore = make(chan struct{}, *maxServingThreads)
 	}
+	if breakglass.Enabled() {
+		handler.breakGlass = breakglass.NewChecker(mgr.GetClient(), util.GetNamespace())
+	}
+	if changelog.Enabled() {
+		changeLog, err := changelog.NewRecorder()
+		if err != nil {
+			return err
+		}
+		handler.changeLog = changeLog
+	}
+	if staticdeny.Enabled() {
+		staticRules, err := staticdeny.Load(staticdeny.File())
+		if err != nil {
+			return err
+		}
+		handler.staticRules = staticRules
+	}
+	if expansion.Enabled() {
+		handler.expansionDefaulter = expansion.NewNamespaceDefaulter(mgr.GetClient())
+	}
+	if degradation.Enabled() {
+		monitor := degradation.NewMonitor(mgr.GetAPIReader())
+		if err := mgr.Add(monitor); err != nil {
+			return err
+		}
+		handler.degradation = monitor
+	}
+	handler.clusterIdentity = clusteridentity.New(mgr.GetClient(), util.GetNamespace())
 	wh := &admission.Webhook{Handler: handler}
 	// TODO(https://github.com/open-policy-agent/gatekeeper/issues/661): remove log injection if the race condition in the cited bug is eliminated.
 	// Otherwise we risk having unstable logger names for the webhook.
 	if err := wh.InjectLogger(log); err != nil {
 		return err
 	}
-	mgr.GetWebhookServer().Register("/v1/admit", wh)
+	registerWebhook(mgr, "/v1/admit", wh)
 	return nil
 }
 
@@ -103,8 +162,14 @@ var _ admission.Handler = &validationHandler{}
 
 type validationHandler struct {
 	webhookHandler
-	opa       *opa.Client
-	semaphore chan struct{}
+	opa                opashard.Client
+	semaphore          chan struct{}
+	breakGlass         *breakglass.Checker
+	changeLog          *changelog.Recorder
+	clusterIdentity    *clusteridentity.Provider
+	staticRules        *staticdeny.Rules
+	degradation        *degradation.Monitor
+	expansionDefaulter *expansion.NamespaceDefaulter
 }
 
 // Handle the validation request
@@ -117,11 +182,27 @@ func (h *validationHandler) Handle(ctx context.Context, req admission.Request) a
 		return admission.ValidationResponse(true, "Gatekeeper does not self-manage")
 	}
 
+	ctx, span := tracing.StartSpan(ctx, "admission.validate",
+		tracing.Attribute("resource_kind", req.AdmissionRequest.Kind.Kind),
+		tracing.Attribute("resource_namespace", req.AdmissionRequest.Namespace),
+		tracing.Attribute("operation", string(req.AdmissionRequest.Operation)),
+	)
+	defer span.End()
+
+	// decision is nil unless decision logging is enabled; its methods are
+	// no-ops on nil so the call sites below need no guards
+	var decision *decisionlog.Record
+	if decisionlog.Enabled() {
+		decision = decisionlog.New(&req.AdmissionRequest)
+		defer func() { decision.Emit(time.Since(timeStart)) }()
+	}
+
 	if req.AdmissionRequest.Operation == admissionv1.Delete {
 		// oldObject is the existing object.
 		// It is null for DELETE operations in API servers prior to v1.15.0.
 		// https://github.com/kubernetes/website/pull/14671
 		if req.AdmissionRequest.OldObject.Raw == nil {
+			decision.Error("DELETE request carried no oldObject")
 			vResp := admission.ValidationResponse(false, "For admission webhooks registered for DELETE operations, please use Kubernetes v1.15.0+.")
 			vResp.Result.Code = http.StatusInternalServerError
 			return vResp
@@ -134,7 +215,54 @@ func (h *validationHandler) Handle(ctx context.Context, req admission.Request) a
 		req.AdmissionRequest.Object = req.AdmissionRequest.OldObject
 	}
 
+	if h.changeLog != nil && changelog.Tracked(&req.AdmissionRequest) {
+		h.changeLog.Record(&req.AdmissionRequest)
+	}
+
+	if selfprotect.Enabled() {
+		if msg, denied := selfprotect.Denied(&req.AdmissionRequest); denied {
+			log.Info("self-protection denied a write to a Gatekeeper resource",
+				logging.ResourceKind, req.AdmissionRequest.Kind.Kind,
+				logging.ResourceName, req.AdmissionRequest.Name,
+				logging.RequestUsername, req.AdmissionRequest.UserInfo.Username,
+			)
+			decision.Deny(msg)
+			vResp := admission.ValidationResponse(false, msg)
+			if vResp.Result == nil {
+				vResp.Result = &metav1.Status{}
+			}
+			vResp.Result.Code = http.StatusForbidden
+			return vResp
+		}
+	}
+
+	// static rules are the security floor: they run before anything that
+	// depends on cluster state or the policy engine, and break-glass does
+	// not bypass them
+	if h.staticRules != nil {
+		if msg, denied := h.staticRules.Denied(&req.AdmissionRequest); denied {
+			log.Info("static deny rule blocked a request",
+				logging.ResourceKind, req.AdmissionRequest.Kind.Kind,
+				logging.ResourceNamespace, req.AdmissionRequest.Namespace,
+				logging.ResourceName, req.AdmissionRequest.Name,
+				logging.RequestUsername, req.AdmissionRequest.UserInfo.Username,
+			)
+			decision.Deny(msg)
+			vResp := admission.ValidationResponse(false, msg)
+			if vResp.Result == nil {
+				vResp.Result = &metav1.Status{}
+			}
+			vResp.Result.Code = http.StatusForbidden
+			return vResp
+		}
+	}
+
 	if userErr, err := h.validateGatekeeperResources(ctx, req); err != nil {
+		if userErr {
+			decision.Deny(err.Error())
+		} else {
+			decision.Error(err.Error())
+		}
 		vResp := admission.ValidationResponse(false, err.Error())
 		if vResp.Result == nil {
 			vResp.Result = &metav1.Status{}
@@ -157,7 +285,7 @@ func (h *validationHandler) Handle(ctx context.Context, req admission.Request) a
 	}()
 
 	// namespace is excluded from webhook using config
-	isExcludedNamespace, err := h.skipExcludedNamespace(req.AdmissionRequest, process.Webhook)
+	isExcludedNamespace, err := h.skipExcludedNamespace(ctx, req.AdmissionRequest, process.Webhook)
 	if err != nil {
 		log.Error(err, "error while excluding namespace")
 	}
@@ -165,12 +293,40 @@ func (h *validationHandler) Handle(ctx context.Context, req admission.Request) a
 	if isExcludedNamespace {
 
 		requestResponse = allowResponse
+		decision.Allow()
 		return admission.ValidationResponse(true, "Namespace is set to be ignored by Gatekeeper config")
 	}
 
-	resp, err := h.reviewRequest(ctx, req)
+	// identical requests against an unchanged policy snapshot produce
+	// identical verdicts, so replay them without re-evaluating
+	var cacheKey string
+	if verdictcache.Enabled() {
+		cacheKey = verdictcache.Key(&req.AdmissionRequest)
+		if v, ok := verdictcache.Get().Lookup(cacheKey); ok {
+			if len(v.Msgs) == 0 {
+				requestResponse = allowResponse
+				decision.Allow()
+				vResp := admission.ValidationResponse(true, "")
+				vResp.Warnings = v.Warnings
+				return vResp
+			}
+			if h.breakGlass == nil || !h.breakGlass.Active(ctx) {
+				decision.Deny(v.Msgs...)
+				vResp := admission.ValidationResponse(false, strings.Join(v.Msgs, "\n"))
+				if vResp.Result == nil {
+					vResp.Result = &metav1.Status{}
+				}
+				vResp.Result.Code = http.StatusForbidden
+				requestResponse = denyResponse
+				return vResp
+			}
+		}
+	}
+
+	resp, reviewNamespace, err := h.reviewRequest(ctx, req)
 	if err != nil {
 		log.Error(err, "error executing query")
+		decision.Error(err.Error())
 		vResp := admission.ValidationResponse(false, err.Error())
 		if vResp.Result == nil {
 			vResp.Result = &metav1.Status{}
@@ -180,9 +336,49 @@ func (h *validationHandler) Handle(ctx context.Context, req admission.Request) a
 		return vResp
 	}
 
-	res := resp.Results()
-	msgs := h.getDenyMessages(res, req)
+	res := h.filterFieldSelectorMismatches(resp.Results(), req)
+	res = h.filterClusterMismatches(ctx, res)
+	res = h.filterSubtreeMismatches(res, req, reviewNamespace)
+	res = h.filterUserExemptResults(res, req)
+	res = h.filterExemptedResults(ctx, res, req)
+	res, warnings := h.applyConstraintOverrides(ctx, res, req)
+	if canary.Enabled() {
+		var canaryWarnings []string
+		res, canaryWarnings = canary.Apply(res, req.AdmissionRequest.Namespace)
+		warnings = append(warnings, canaryWarnings...)
+	}
+	decision.Evaluated(res)
+	msgs := h.getDenyMessages(res, req, reviewNamespace)
+	if expansion.Enabled() {
+		msgs = append(msgs, h.expansionDenyMessages(ctx, req, reviewNamespace)...)
+	}
+	if len(msgs) > 0 && h.breakGlass != nil && h.breakGlass.Active(ctx) {
+		log.Info("BREAK-GLASS BYPASS ACTIVE: denied request admitted",
+			logging.EventType, "break_glass_bypass",
+			logging.ResourceKind, req.AdmissionRequest.Kind.Kind,
+			logging.ResourceNamespace, req.AdmissionRequest.Namespace,
+			logging.ResourceName, req.AdmissionRequest.Name,
+			logging.RequestUsername, req.AdmissionRequest.UserInfo.Username,
+			"denials", strings.Join(msgs, "\n"),
+		)
+		breakglass.ReportBypass()
+		requestResponse = allowResponse
+		decision.Allow()
+		return admission.ValidationResponse(true, "break-glass bypass active, denials downgraded to dryrun")
+	}
+	if cacheKey != "" {
+		verdictcache.Get().Store(cacheKey, verdictcache.Verdict{Msgs: msgs, Warnings: warnings})
+	}
+	// appended after the cache store so the staleness warning reflects the
+	// current degradation state rather than the one cached with the verdict
+	if h.degradation != nil {
+		if since, degraded := h.degradation.Degraded(); degraded {
+			warnings = append(warnings, fmt.Sprintf(
+				"Gatekeeper is degraded: API server unreachable since %s; this decision is based on last-known-good policy and cached data", since.Format(time.RFC3339)))
+		}
+	}
 	if len(msgs) > 0 {
+		decision.Deny(msgs...)
 		vResp := admission.ValidationResponse(false, strings.Join(msgs, "\n"))
 		if vResp.Result == nil {
 			vResp.Result = &metav1.Status{}
@@ -193,10 +389,262 @@ func (h *validationHandler) Handle(ctx context.Context, req admission.Request) a
 	}
 
 	requestResponse = allowResponse
-	return admission.ValidationResponse(true, "")
+	decision.Allow()
+	vResp := admission.ValidationResponse(true, "")
+	vResp.Warnings = warnings
+	return vResp
+}
+
+// filterFieldSelectorMismatches removes results from constraints whose
+// field selectors do not match the reviewed object.
+func (h *validationHandler) filterFieldSelectorMismatches(res []*rtypes.Result, req admission.Request) []*rtypes.Result {
+	if len(res) == 0 || req.AdmissionRequest.Object.Raw == nil {
+		return res
+	}
+	obj := getScratchObject()
+	defer putScratchObject(obj)
+	if _, _, err := deserializer.Decode(req.AdmissionRequest.Object.Raw, nil, obj); err != nil {
+		return res
+	}
+	var out []*rtypes.Result
+	for _, r := range res {
+		matched, err := util.FieldSelectorsMatch(r.Constraint.Object, obj.Object)
+		if err != nil {
+			log.Error(err, "unable to evaluate field selectors", logging.ConstraintName, r.Constraint.GetName())
+			matched = true
+		}
+		if matched {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// filterClusterMismatches removes results from constraints whose cluster
+// selectors do not match this cluster's identity labels.
+func (h *validationHandler) filterClusterMismatches(ctx context.Context, res []*rtypes.Result) []*rtypes.Result {
+	if len(res) == 0 || h.clusterIdentity == nil {
+		return res
+	}
+	clusterLabels := h.clusterIdentity.Labels(ctx)
+	var out []*rtypes.Result
+	for _, r := range res {
+		matched, err := util.ConstraintMatchesCluster(r.Constraint.Object, clusterLabels)
+		if err != nil {
+			log.Error(err, "unable to evaluate cluster selector", logging.ConstraintName, r.Constraint.GetName())
+			matched = true
+		}
+		if matched {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// filterSubtreeMismatches removes results from constraints whose
+// hierarchical namespace subtree criteria do not cover the request's
+// namespace, so subtree-scoped policies and exemptions written against a
+// parent namespace apply to every descendant.
+func (h *validationHandler) filterSubtreeMismatches(res []*rtypes.Result, req admission.Request, reviewNamespace *corev1.Namespace) []*rtypes.Result {
+	if len(res) == 0 {
+		return res
+	}
+	var nsLabels map[string]string
+	if reviewNamespace != nil {
+		nsLabels = reviewNamespace.GetLabels()
+	}
+	var out []*rtypes.Result
+	for _, r := range res {
+		matched, err := util.MatchesNamespaceSubtrees(r.Constraint.Object, req.AdmissionRequest.Namespace, nsLabels)
+		if err != nil {
+			log.Error(err, "unable to evaluate namespace subtree criteria", logging.ConstraintName, r.Constraint.GetName())
+			matched = true
+		}
+		if matched {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// filterUserExemptResults removes results from constraints that exempt
+// the requesting user or one of their groups, logging every bypass.
+func (h *validationHandler) filterUserExemptResults(res []*rtypes.Result, req admission.Request) []*rtypes.Result {
+	if len(res) == 0 {
+		return res
+	}
+	var out []*rtypes.Result
+	for _, r := range res {
+		exempt, err := util.UserExempt(r.Constraint.Object, req.AdmissionRequest.UserInfo)
+		if err != nil {
+			log.Error(err, "unable to evaluate user exemptions", logging.ConstraintName, r.Constraint.GetName())
+		}
+		if exempt {
+			log.Info("violation bypassed by user exemption",
+				logging.RequestUsername, req.AdmissionRequest.UserInfo.Username,
+				logging.ConstraintName, r.Constraint.GetName(),
+				logging.ConstraintKind, r.Constraint.GetKind(),
+				logging.ResourceKind, req.AdmissionRequest.Kind.Kind,
+				logging.ResourceNamespace, req.AdmissionRequest.Namespace,
+				logging.ResourceName, req.AdmissionRequest.Name,
+			)
+			if err := exemption.ReportHonored(exemption.SourceUserInfo); err != nil {
+				log.Error(err, "failed to report honored exemption")
+			}
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// filterExemptedResults removes results covered by an active Exemption,
+// logging each bypass along with its approver metadata so exempted
+// violations remain attributable.
+func (h *validationHandler) filterExemptedResults(ctx context.Context, res []*rtypes.Result, req admission.Request) []*rtypes.Result {
+	if len(res) == 0 || h.client == nil {
+		return res
+	}
+	exemptionList := &exemptionsv1alpha1.ExemptionList{}
+	if err := h.client.List(ctx, exemptionList); err != nil {
+		// the Exemption CRD may not be installed; in that case nothing is exempt
+		if !meta.IsNoMatchError(err) {
+			log.Error(err, "unable to list exemptions")
+		}
+		return res
+	}
+	if len(exemptionList.Items) == 0 {
+		return res
+	}
+
+	// the reviewed object may request its own exemption via annotation;
+	// it is honored below only where an exemption policy authorizes it
+	var annotationExemption *exemption.AnnotationExemption
+	if req.AdmissionRequest.Object.Raw != nil {
+		obj := getScratchObject()
+		defer putScratchObject(obj)
+		if _, _, err := deserializer.Decode(req.AdmissionRequest.Object.Raw, nil, obj); err == nil {
+			parsed, err := exemption.ParseAnnotation(obj)
+			if err != nil {
+				log.Error(err, "ignoring exemption annotation",
+					logging.ResourceNamespace, req.AdmissionRequest.Namespace,
+					logging.ResourceName, req.AdmissionRequest.Name,
+				)
+			} else {
+				annotationExemption = parsed
+			}
+		}
+	}
+
+	now := metav1.Now()
+	var out []*rtypes.Result
+	for _, r := range res {
+		target := exemption.Target{
+			ConstraintKind: r.Constraint.GetKind(),
+			ConstraintName: r.Constraint.GetName(),
+			Kind:           req.AdmissionRequest.Kind.Kind,
+			Namespace:      req.AdmissionRequest.Namespace,
+			Name:           req.AdmissionRequest.Name,
+			Username:       req.AdmissionRequest.UserInfo.Username,
+			Groups:         req.AdmissionRequest.UserInfo.Groups,
+		}
+		if ex := exemption.FindExemption(exemptionList.Items, target, now); ex != nil {
+			log.Info("violation bypassed by exemption",
+				"exemption", ex.GetName(),
+				"approver", ex.Spec.Approver,
+				"reason", ex.Spec.Reason,
+				"expires_at", ex.Spec.ExpiresAt.String(),
+				logging.ConstraintName, r.Constraint.GetName(),
+				logging.ConstraintKind, r.Constraint.GetKind(),
+				logging.ResourceKind, req.AdmissionRequest.Kind.Kind,
+				logging.ResourceNamespace, req.AdmissionRequest.Namespace,
+				logging.ResourceName, req.AdmissionRequest.Name,
+			)
+			if err := exemption.ReportHonored(exemption.SourceCRD); err != nil {
+				log.Error(err, "failed to report honored exemption")
+			}
+			continue
+		}
+		if annotationExemption != nil && annotationExemption.Covers(r.Constraint.GetName(), now) {
+			if authorizer := exemption.FindAnnotationAuthorizer(exemptionList.Items, target, now); authorizer != nil {
+				log.Info("violation bypassed by exemption annotation",
+					"authorized_by", authorizer.GetName(),
+					"justification", annotationExemption.Justification,
+					"expiry", annotationExemption.Expiry,
+					logging.ConstraintName, r.Constraint.GetName(),
+					logging.ConstraintKind, r.Constraint.GetKind(),
+					logging.ResourceKind, req.AdmissionRequest.Kind.Kind,
+					logging.ResourceNamespace, req.AdmissionRequest.Namespace,
+					logging.ResourceName, req.AdmissionRequest.Name,
+				)
+				if err := exemption.ReportHonored(exemption.SourceAnnotation); err != nil {
+					log.Error(err, "failed to report honored exemption")
+				}
+				continue
+			}
+		}
+		out = append(out, r)
+	}
+	return out
 }
 
-func (h *validationHandler) getDenyMessages(res []*rtypes.Result, req admission.Request) []string {
+// applyConstraintOverrides removes results downgraded from deny to warn
+// by a ConstraintOverride in the request's namespace, returning the
+// corresponding warning messages. Overrides are only honored for
+// constraints whose authors allow-listed the namespace.
+func (h *validationHandler) applyConstraintOverrides(ctx context.Context, res []*rtypes.Result, req admission.Request) ([]*rtypes.Result, []string) {
+	if !override.Enabled() || len(res) == 0 || h.client == nil || req.AdmissionRequest.Namespace == "" {
+		return res, nil
+	}
+	overrideList := &overridesv1alpha1.ConstraintOverrideList{}
+	if err := h.client.List(ctx, overrideList, client.InNamespace(req.AdmissionRequest.Namespace)); err != nil {
+		// the ConstraintOverride CRD may not be installed; in that case
+		// nothing is overridden
+		if !meta.IsNoMatchError(err) {
+			log.Error(err, "unable to list constraint overrides")
+		}
+		return res, nil
+	}
+	if len(overrideList.Items) == 0 {
+		return res, nil
+	}
+
+	var out []*rtypes.Result
+	var warnings []string
+	for _, r := range res {
+		// only denying constraints are downgraded; dryrun results keep
+		// flowing through so they are still logged and reported
+		enforcementAction := r.EnforcementAction
+		if scoped, err := util.GetEnforcementActionForPoint(r.Constraint.Object, util.WebhookEnforcementPoint); err == nil {
+			enforcementAction = string(scoped)
+		}
+		if nsAction, found, err := util.GetEnforcementActionForNamespace(r.Constraint.Object, req.AdmissionRequest.Namespace); err == nil && found {
+			enforcementAction = string(nsAction)
+		}
+		if enforcementAction != "deny" {
+			out = append(out, r)
+			continue
+		}
+		o := override.FindOverride(overrideList.Items, r.Constraint.GetKind(), r.Constraint.GetName())
+		if o == nil || !override.ConstraintAllowsOverride(r.Constraint, req.AdmissionRequest.Namespace) {
+			out = append(out, r)
+			continue
+		}
+		log.Info("deny downgraded to warn by constraint override",
+			"override", o.GetName(),
+			"reason", o.Spec.Reason,
+			logging.ConstraintName, r.Constraint.GetName(),
+			logging.ConstraintKind, r.Constraint.GetKind(),
+			logging.ResourceKind, req.AdmissionRequest.Kind.Kind,
+			logging.ResourceNamespace, req.AdmissionRequest.Namespace,
+			logging.ResourceName, req.AdmissionRequest.Name,
+		)
+		warnings = append(warnings, fmt.Sprintf("[overridden by %s] [would be denied by %s] %s", o.GetName(), r.Constraint.GetName(), r.Msg))
+	}
+	return out, warnings
+}
+
+func (h *validationHandler) getDenyMessages(res []*rtypes.Result, req admission.Request, reviewNamespace *corev1.Namespace) []string {
 	var msgs []string
 	var resourceName string
 	if len(res) > 0 && (*logDenies || *emitAdmissionEvents) {
@@ -204,23 +652,81 @@ func (h *validationHandler) getDenyMessages(res []*rtypes.Result, req admission.
 		if len(resourceName) == 0 && req.AdmissionRequest.Object.Raw != nil {
 			// On a CREATE operation, the client may omit name and
 			// rely on the server to generate the name.
-			obj := &unstructured.Unstructured{}
+			obj := getScratchObject()
 			if _, _, err := deserializer.Decode(req.AdmissionRequest.Object.Raw, nil, obj); err == nil {
 				resourceName = obj.GetName()
 			}
+			putScratchObject(obj)
 		}
 	}
 	for _, r := range res {
-		if r.EnforcementAction == "deny" || r.EnforcementAction == "dryrun" {
+		owner, contact := util.GetConstraintOwner(r.Constraint)
+		// constraints may scope a different enforcement action to the
+		// webhook enforcement point
+		enforcementAction := r.EnforcementAction
+		if scoped, err := util.GetEnforcementActionForPoint(r.Constraint.Object, util.WebhookEnforcementPoint); err == nil {
+			enforcementAction = string(scoped)
+		}
+		// namespace-scoped actions win over the global action and the
+		// webhook-point override
+		nsScoped := false
+		if nsAction, found, err := util.GetEnforcementActionForNamespace(r.Constraint.Object, req.AdmissionRequest.Namespace); err == nil && found {
+			enforcementAction = string(nsAction)
+			nsScoped = true
+		}
+		// operation-scoped actions are the most specific and win over
+		// every other form
+		if opAction, found, err := util.GetEnforcementActionForOperation(r.Constraint.Object, string(req.AdmissionRequest.Operation)); err == nil && found {
+			if opAction == util.Ignore {
+				continue
+			}
+			enforcementAction = string(opAction)
+		}
+		// constraints that do not declare an action fall back to the
+		// default configured for the namespace's tier, if any
+		if !nsScoped && !constraintDeclaresAction(r.Constraint) {
+			if tierAction, ok := tierDefaultAction(reviewNamespace); ok {
+				enforcementAction = string(tierAction)
+			}
+		}
+		if enforcementAction == "deny" {
+			rolloutKey := fmt.Sprintf("%s/%s/%s", req.AdmissionRequest.Kind.Kind, req.AdmissionRequest.Namespace, req.AdmissionRequest.Name)
+			if enforced, err := util.RolloutEnforced(r.Constraint.Object, rolloutKey); err == nil && !enforced {
+				log.Info("deny downgraded to dryrun by rollout percentage",
+					logging.ConstraintName, r.Constraint.GetName(),
+					logging.ConstraintKind, r.Constraint.GetKind(),
+					logging.ResourceNamespace, req.AdmissionRequest.Namespace,
+					logging.ResourceName, req.AdmissionRequest.Name,
+				)
+				enforcementAction = string(util.Dryrun)
+			}
+		}
+		if enforcementAction == "deny" {
+			if relaxed, err := util.InMaintenanceWindow(r.Constraint.Object, time.Now()); err == nil && relaxed {
+				log.Info("enforcement relaxed by maintenance window",
+					logging.ConstraintName, r.Constraint.GetName(),
+					logging.ConstraintKind, r.Constraint.GetKind(),
+				)
+				enforcementAction = string(util.Dryrun)
+			}
+		}
+		// audit-only mode downgrades every deny cluster-wide, after all
+		// per-constraint resolution has run
+		if enforcementAction == "deny" && *auditOnlyMode {
+			enforcementAction = string(util.Dryrun)
+		}
+		if enforcementAction == "deny" || enforcementAction == "dryrun" {
 			if *logDenies {
 				log.WithValues(
 					logging.Process, "admission",
 					logging.EventType, "violation",
 					logging.ConstraintName, r.Constraint.GetName(),
+					logging.ConstraintOwner, owner,
+					logging.ConstraintContact, contact,
 					logging.ConstraintGroup, r.Constraint.GroupVersionKind().Group,
 					logging.ConstraintAPIVersion, r.Constraint.GroupVersionKind().Version,
 					logging.ConstraintKind, r.Constraint.GetKind(),
-					logging.ConstraintAction, r.EnforcementAction,
+					logging.ConstraintAction, enforcementAction,
 					logging.ResourceGroup, req.AdmissionRequest.Kind.Group,
 					logging.ResourceAPIVersion, req.AdmissionRequest.Kind.Version,
 					logging.ResourceKind, req.AdmissionRequest.Kind.Kind,
@@ -234,10 +740,12 @@ func (h *validationHandler) getDenyMessages(res []*rtypes.Result, req admission.
 					logging.Process:              "admission",
 					logging.EventType:            "violation",
 					logging.ConstraintName:       r.Constraint.GetName(),
+					logging.ConstraintOwner:      owner,
+					logging.ConstraintContact:    contact,
 					logging.ConstraintGroup:      r.Constraint.GroupVersionKind().Group,
 					logging.ConstraintAPIVersion: r.Constraint.GroupVersionKind().Version,
 					logging.ConstraintKind:       r.Constraint.GetKind(),
-					logging.ConstraintAction:     r.EnforcementAction,
+					logging.ConstraintAction:     enforcementAction,
 					logging.ResourceGroup:        req.AdmissionRequest.Kind.Group,
 					logging.ResourceAPIVersion:   req.AdmissionRequest.Kind.Version,
 					logging.ResourceKind:         req.AdmissionRequest.Kind.Kind,
@@ -247,7 +755,7 @@ func (h *validationHandler) getDenyMessages(res []*rtypes.Result, req admission.
 				}
 				eventMsg := "Admission webhook \"validation.gatekeeper.sh\" denied request"
 				reason := "FailedAdmission"
-				if r.EnforcementAction == "dryrun" {
+				if enforcementAction == "dryrun" {
 					eventMsg = "Dryrun violation"
 					reason = "DryrunViolation"
 				}
@@ -257,8 +765,17 @@ func (h *validationHandler) getDenyMessages(res []*rtypes.Result, req admission.
 
 		}
 		// only deny enforcementAction should prompt deny admission response
-		if r.EnforcementAction == "deny" {
-			msgs = append(msgs, fmt.Sprintf("[denied by %s] %s", r.Constraint.GetName(), r.Msg))
+		if enforcementAction == "deny" {
+			if h.reporter != nil {
+				if err := h.reporter.ReportDenial(r.Constraint.GetKind(), r.Constraint.GetName()); err != nil {
+					log.Error(err, "failed to report denial metric")
+				}
+			}
+			msg := fmt.Sprintf("[denied by %s] %s", r.Constraint.GetName(), r.Msg)
+			if owner != "" {
+				msg = fmt.Sprintf("%s (owner: %s)", msg, owner)
+			}
+			msgs = append(msgs, msg)
 		}
 	}
 	return msgs
@@ -278,6 +795,14 @@ func (h *validationHandler) validateGatekeeperResources(ctx context.Context, req
 		if err := h.validateConfigResource(ctx, req); err != nil {
 			return true, err
 		}
+	case gvk.Group == "expansion.gatekeeper.sh" && gvk.Kind == "ExpansionTemplate":
+		if err := h.validateExpansionTemplate(req); err != nil {
+			return true, err
+		}
+	case gvk.Group == "externaldata.gatekeeper.sh" && gvk.Kind == "Provider":
+		if err := h.validateProvider(req); err != nil {
+			return true, err
+		}
 	}
 
 	return false, nil
@@ -295,6 +820,27 @@ func (h *validationHandler) validateTemplate(ctx context.Context, req admission.
 	if _, err := h.opa.CreateCRD(ctx, unversioned); err != nil {
 		return true, err
 	}
+	if templatelint.Enabled() {
+		if issues := templatelint.LintTemplate(unversioned); len(issues) > 0 {
+			return true, templatelint.AsError(issues)
+		}
+	}
+	if celengine.Enabled() || wasmengine.Enabled() {
+		obj := &unstructured.Unstructured{}
+		if _, _, err := deserializer.Decode(req.AdmissionRequest.Object.Raw, nil, obj); err != nil {
+			return false, err
+		}
+		if celengine.Enabled() {
+			if err := celengine.ValidateTemplate(obj); err != nil {
+				return true, err
+			}
+		}
+		if wasmengine.Enabled() {
+			if err := wasmengine.ValidateTemplate(obj); err != nil {
+				return true, err
+			}
+		}
+	}
 	return false, nil
 }
 
@@ -312,6 +858,35 @@ func (h *validationHandler) validateConstraint(ctx context.Context, req admissio
 		return false, err
 	}
 	enforcementAction := util.EnforcementAction(enforcementActionString)
+	if !*disableEnforcementActionValidation {
+		if err := util.ValidateEnforcementActionOverrides(obj.Object); err != nil {
+			return false, err
+		}
+		if err := util.ValidateOperationEnforcementActions(obj.Object); err != nil {
+			return false, err
+		}
+		if err := util.ValidateScopedEnforcementActions(obj.Object); err != nil {
+			return false, err
+		}
+	}
+	if err := util.ValidateMaintenanceWindows(obj.Object); err != nil {
+		return true, err
+	}
+	if err := util.ValidateAuditSettings(obj.Object); err != nil {
+		return true, err
+	}
+	if err := util.ValidateFieldSelectors(obj.Object); err != nil {
+		return true, err
+	}
+	if err := util.ValidateClusterSelector(obj.Object); err != nil {
+		return true, err
+	}
+	if err := util.ValidateNamespaceSubtrees(obj.Object); err != nil {
+		return true, err
+	}
+	if err := util.ValidateRolloutPercentage(obj.Object); err != nil {
+		return true, err
+	}
 	if found && enforcementAction != "" {
 		if !*disableEnforcementActionValidation {
 			err = util.ValidateEnforcementAction(enforcementAction)
@@ -329,11 +904,124 @@ func (h *validationHandler) validateConfigResource(ctx context.Context, req admi
 	if req.Name != keys.Config.Name {
 		return fmt.Errorf("Config resource must have name 'config'")
 	}
+	if req.AdmissionRequest.Object.Raw == nil {
+		return nil
+	}
+	obj, _, err := deserializer.Decode(req.AdmissionRequest.Object.Raw, nil, nil)
+	if err != nil {
+		return err
+	}
+	switch cfg := obj.(type) {
+	case *configv1.Config:
+		return cfg.Validate()
+	case *configv1alpha1.Config:
+		// alpha objects are validated through their v1 form so both
+		// served versions reject the same misconfigurations
+		hub := &configv1.Config{}
+		if err := cfg.ConvertTo(hub); err != nil {
+			return err
+		}
+		return hub.Validate()
+	}
+	return nil
+}
+
+// validateExpansionTemplate rejects ExpansionTemplates whose mistakes
+// would otherwise only surface as failed expansions at admission time.
+func (h *validationHandler) validateExpansionTemplate(req admission.Request) error {
+	if req.AdmissionRequest.Object.Raw == nil {
+		return nil
+	}
+	obj, _, err := deserializer.Decode(req.AdmissionRequest.Object.Raw, nil, nil)
+	if err != nil {
+		return err
+	}
+	if t, ok := obj.(*expansionv1alpha1.ExpansionTemplate); ok {
+		return expansion.ValidateTemplate(t)
+	}
 	return nil
 }
 
+// validateProvider rejects Providers whose mistakes would otherwise
+// only surface as failed external data lookups at evaluation time.
+func (h *validationHandler) validateProvider(req admission.Request) error {
+	if req.AdmissionRequest.Object.Raw == nil {
+		return nil
+	}
+	obj, _, err := deserializer.Decode(req.AdmissionRequest.Object.Raw, nil, nil)
+	if err != nil {
+		return err
+	}
+	if p, ok := obj.(*externaldatav1alpha1.Provider); ok {
+		return externaldata.ValidateProvider(p)
+	}
+	return nil
+}
+
+// expansionDenyMessages reviews the resources a workload generator
+// implies, per the matching ExpansionTemplates, and returns the deny
+// messages those reviews produce.
+func (h *validationHandler) expansionDenyMessages(ctx context.Context, req admission.Request, reviewNamespace *corev1.Namespace) []string {
+	if req.AdmissionRequest.Object.Raw == nil {
+		return nil
+	}
+	templateList := &expansionv1alpha1.ExpansionTemplateList{}
+	if err := h.client.List(ctx, templateList); err != nil {
+		// the ExpansionTemplate CRD may not be installed; in that case
+		// nothing expands
+		if !meta.IsNoMatchError(err) {
+			log.Error(err, "listing expansion templates; skipping expansion")
+		}
+		return nil
+	}
+	gvk := schema.GroupVersionKind{Group: req.AdmissionRequest.Kind.Group, Version: req.AdmissionRequest.Kind.Version, Kind: req.AdmissionRequest.Kind.Kind}
+	matching := expansion.TemplatesFor(templateList.Items, gvk)
+	if len(matching) == 0 {
+		return nil
+	}
+
+	parent := &unstructured.Unstructured{}
+	if err := parent.UnmarshalJSON(req.AdmissionRequest.Object.Raw); err != nil {
+		log.Error(err, "decoding object for expansion; skipping expansion")
+		return nil
+	}
+
+	var msgs []string
+	for _, t := range matching {
+		resultants, err := expansion.ExpandAll(ctx, t, parent)
+		if err != nil {
+			log.Error(err, "expanding resource", "template", t.GetName())
+			continue
+		}
+		for _, resultant := range resultants {
+			if h.expansionDefaulter != nil {
+				// best-effort: an undefaulted pod is still reviewable
+				if err := h.expansionDefaulter.ApplyTo(ctx, resultant); err != nil {
+					log.Error(err, "defaulting expanded resource", "template", t.GetName())
+				}
+			}
+			resp, err := h.opa.Review(ctx, target.AugmentedUnstructured{Object: *resultant, Namespace: reviewNamespace})
+			if err != nil {
+				log.Error(err, "reviewing expanded resource", "template", t.GetName())
+				continue
+			}
+			for _, r := range resp.Results() {
+				action := r.EnforcementAction
+				if t.Spec.EnforcementAction != "" {
+					action = t.Spec.EnforcementAction
+				}
+				if action != "deny" {
+					continue
+				}
+				msgs = append(msgs, fmt.Sprintf("[implied by %s] [denied by %s] %s", t.GetName(), r.Constraint.GetName(), r.Msg))
+			}
+		}
+	}
+	return msgs
+}
+
 // traceSwitch returns true if a request should be traced
-func (h *validationHandler) reviewRequest(ctx context.Context, req admission.Request) (*rtypes.Responses, error) {
+func (h *validationHandler) reviewRequest(ctx context.Context, req admission.Request) (*rtypes.Responses, *corev1.Namespace, error) {
 	// if we have a maximum number of concurrent serving goroutines, try to acquire
 	// a lock and block until we succeed
 	if h.semaphore != nil {
@@ -343,7 +1031,7 @@ func (h *validationHandler) reviewRequest(ctx context.Context, req admission.Req
 				<-h.semaphore
 			}()
 		case <-ctx.Done():
-			return nil, errors.New("serving context canceled, aborting request")
+			return nil, nil, errors.New("serving context canceled, aborting request")
 		}
 	}
 	trace, dump := h.tracingLevel(ctx, req)
@@ -354,22 +1042,31 @@ func (h *validationHandler) reviewRequest(ctx context.Context, req admission.Req
 		req.Namespace = ""
 	}
 	review := &target.AugmentedReview{AdmissionRequest: &req.AdmissionRequest}
+	var reviewNamespace *corev1.Namespace
 	if req.AdmissionRequest.Namespace != "" {
 		ns := &corev1.Namespace{}
 		if err := h.client.Get(ctx, types.NamespacedName{Name: req.AdmissionRequest.Namespace}, ns); err != nil {
 			if !k8serrors.IsNotFound(err) {
-				return nil, err
+				return nil, nil, err
 			}
 			// bypass cached client and ask api-server directly
 			err = h.reader.Get(ctx, types.NamespacedName{Name: req.AdmissionRequest.Namespace}, ns)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 		}
 		review.Namespace = ns
+		reviewNamespace = ns
 	}
 
-	resp, err := h.opa.Review(ctx, review, opa.Tracing(trace))
+	reviewCtx, span := tracing.StartSpan(ctx, "constraint.review")
+	if externaldata.Enabled() {
+		// scope an external data batcher to this review so lookups from
+		// different templates coalesce into one provider request
+		reviewCtx = externaldata.WithBatcher(reviewCtx, externaldata.NewBatcher())
+	}
+	resp, err := h.opa.Review(reviewCtx, review, opa.Tracing(trace))
+	span.End()
 	if trace {
 		log.Info(resp.TraceDump())
 	}
@@ -381,7 +1078,7 @@ func (h *validationHandler) reviewRequest(ctx context.Context, req admission.Req
 			log.Info(dump)
 		}
 	}
-	return resp, err
+	return resp, reviewNamespace, err
 }
 
 func getViolationRef(gkNamespace, rkind, rname, rnamespace, ckind, cname, cnamespace string) *corev1.ObjectReference {
@@ -308,7 +308,7 @@ func TestReviewRequest(t *testing.T) {
 					Namespace: "ns1",
 				},
 			}
-			_, err = handler.reviewRequest(context.Background(), review)
+			_, _, err = handler.reviewRequest(context.Background(), review)
 			if err != nil && !tt.Error {
 				t.Errorf("err = %s; want nil", err)
 			}
@@ -525,7 +525,7 @@ func TestTracing(t *testing.T) {
 					},
 				},
 			}
-			resp, err := handler.reviewRequest(context.Background(), review)
+			resp, _, err := handler.reviewRequest(context.Background(), review)
 			if err != nil {
 				t.Errorf("Unexpected error: %s", err)
 			}
@@ -654,7 +654,7 @@ func TestGetDenyMessages(t *testing.T) {
 					},
 				},
 			}
-			msgs := handler.getDenyMessages(tt.Result, review)
+			msgs := handler.getDenyMessages(tt.Result, review, nil)
 			if len(msgs) != tt.ExpectedMsgCount {
 				t.Errorf("expected count = %d; actual count = %d", tt.ExpectedMsgCount, len(msgs))
 			}
@@ -666,10 +666,44 @@ func TestGetDenyMessages(t *testing.T) {
 	}
 }
 
+func TestGetDenyMessagesAuditOnlyMode(t *testing.T) {
+	*auditOnlyMode = true
+	defer func() { *auditOnlyMode = false }()
+
+	resDeny := &rtypes.Result{
+		Msg:               "test",
+		Constraint:        newConstraint("Foo", "ph", "deny", t),
+		EnforcementAction: "deny",
+	}
+
+	opa, err := makeOpaClient()
+	if err != nil {
+		t.Fatalf("Could not initialize OPA: %s", err)
+	}
+	handler := validationHandler{opa: opa, webhookHandler: webhookHandler{}}
+	review := atypes.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Kind: metav1.GroupVersionKind{
+				Group:   "",
+				Version: "v1",
+				Kind:    "Namespace",
+			},
+			Object: runtime.RawExtension{
+				Raw: []byte(`{"apiVersion": "v1", "kind": "Namespace"}`),
+			},
+		},
+	}
+	msgs := handler.getDenyMessages([]*rtypes.Result{resDeny}, review, nil)
+	if len(msgs) != 0 {
+		t.Errorf("audit-only mode should not produce deny messages, got %d", len(msgs))
+	}
+}
+
 func TestValidateConfigResource(t *testing.T) {
 	tc := []struct {
 		TestName string
 		Name     string
+		Raw      string
 		Err      bool
 	}{
 		{
@@ -681,6 +715,29 @@ func TestValidateConfigResource(t *testing.T) {
 			TestName: "Correct name",
 			Name:     "config",
 		},
+		{
+			TestName: "Valid v1 spec",
+			Name:     "config",
+			Raw:      `{"apiVersion": "config.gatekeeper.sh/v1", "kind": "Config", "metadata": {"name": "config"}, "spec": {"sync": {"syncOnly": [{"version": "v1", "kind": "Namespace"}]}}}`,
+		},
+		{
+			TestName: "v1 sync entry missing kind",
+			Name:     "config",
+			Raw:      `{"apiVersion": "config.gatekeeper.sh/v1", "kind": "Config", "metadata": {"name": "config"}, "spec": {"sync": {"syncOnly": [{"version": "v1"}]}}}`,
+			Err:      true,
+		},
+		{
+			TestName: "v1 unknown process",
+			Name:     "config",
+			Raw:      `{"apiVersion": "config.gatekeeper.sh/v1", "kind": "Config", "metadata": {"name": "config"}, "spec": {"match": [{"excludedNamespaces": ["kube-system"], "processes": ["adit"]}]}}`,
+			Err:      true,
+		},
+		{
+			TestName: "alpha objects validated through v1",
+			Name:     "config",
+			Raw:      `{"apiVersion": "config.gatekeeper.sh/v1alpha1", "kind": "Config", "metadata": {"name": "config"}, "spec": {"validation": {"traces": [{"user": "jane"}]}}}`,
+			Err:      true,
+		},
 	}
 
 	for _, tt := range tc {
@@ -691,6 +748,9 @@ func TestValidateConfigResource(t *testing.T) {
 					Name: tt.Name,
 				},
 			}
+			if tt.Raw != "" {
+				req.AdmissionRequest.Object = runtime.RawExtension{Raw: []byte(tt.Raw)}
+			}
 
 			err := handler.validateConfigResource(context.Background(), req)
 
@@ -0,0 +1,80 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// The admission hot path allocates scratch space for every request: the
+// reviewed object is decoded into a throwaway unstructured several times
+// and the mutation webhook re-encodes the object to compute its patch.
+// Under high admission QPS those short-lived allocations dominate GC
+// work, so the wrappers and encode buffers are pooled here.
+
+// maxPooledBufferSize caps the encode buffers kept in the pool so a
+// single oversized object does not pin its buffer forever.
+const maxPooledBufferSize = 1 << 20
+
+var scratchObjectPool = sync.Pool{
+	New: func() interface{} { return &unstructured.Unstructured{} },
+}
+
+// getScratchObject returns a pooled unstructured to decode a request
+// object into. Callers must release it with putScratchObject once they
+// are done reading from it and must not retain references to it.
+func getScratchObject() *unstructured.Unstructured {
+	obj, ok := scratchObjectPool.Get().(*unstructured.Unstructured)
+	if !ok {
+		return &unstructured.Unstructured{}
+	}
+	return obj
+}
+
+// putScratchObject hands obj back to the pool. The backing map is
+// dropped so pooled wrappers do not keep decoded objects reachable.
+func putScratchObject(obj *unstructured.Unstructured) {
+	obj.Object = nil
+	scratchObjectPool.Put(obj)
+}
+
+var encodeBufferPool = sync.Pool{
+	New: func() interface{} { return &bytes.Buffer{} },
+}
+
+// getEncodeBuffer returns an empty pooled buffer for serializing a
+// response payload. Release it with putEncodeBuffer after the encoded
+// bytes are no longer referenced.
+func getEncodeBuffer() *bytes.Buffer {
+	buf, ok := encodeBufferPool.Get().(*bytes.Buffer)
+	if !ok {
+		return &bytes.Buffer{}
+	}
+	buf.Reset()
+	return buf
+}
+
+// putEncodeBuffer returns buf to the pool unless it has grown past
+// maxPooledBufferSize.
+func putEncodeBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	encodeBufferPool.Put(buf)
+}
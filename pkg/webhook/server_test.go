@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+)
+
+func setClientCertFlags(t *testing.T, caName, allowedCNs string) {
+	t.Helper()
+	oldCA, oldCNs := *clientCAName, *clientCAAllowCNs
+	*clientCAName = caName
+	*clientCAAllowCNs = allowedCNs
+	t.Cleanup(func() {
+		*clientCAName = oldCA
+		*clientCAAllowCNs = oldCNs
+	})
+}
+
+func TestValidateClientCertFlags(t *testing.T) {
+	setClientCertFlags(t, "", "")
+	if err := ValidateClientCertFlags(); err != nil {
+		t.Errorf("unset flags should validate: %s", err)
+	}
+
+	setClientCertFlags(t, "ca.crt", "kube-apiserver")
+	if err := ValidateClientCertFlags(); err != nil {
+		t.Errorf("CA with allowed CNs should validate: %s", err)
+	}
+
+	setClientCertFlags(t, "", "kube-apiserver")
+	if err := ValidateClientCertFlags(); err == nil {
+		t.Errorf("allowed CNs without a client CA should be rejected")
+	}
+}
+
+func connectionStateWithCN(cn string) tls.ConnectionState {
+	return tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{
+			{Subject: pkix.Name{CommonName: cn}},
+		},
+	}
+}
+
+func TestRestrictClientCN(t *testing.T) {
+	cfg := &tls.Config{}
+	restrictClientCN(cfg, "kube-apiserver, aggregator")
+
+	if err := cfg.VerifyConnection(connectionStateWithCN("kube-apiserver")); err != nil {
+		t.Errorf("allowed CN should be accepted: %s", err)
+	}
+	if err := cfg.VerifyConnection(connectionStateWithCN("aggregator")); err != nil {
+		t.Errorf("allowed CN with surrounding whitespace in the flag should be accepted: %s", err)
+	}
+	if err := cfg.VerifyConnection(connectionStateWithCN("intruder")); err == nil {
+		t.Errorf("unlisted CN should be rejected")
+	}
+	if err := cfg.VerifyConnection(tls.ConnectionState{}); err == nil {
+		t.Errorf("missing client certificate should be rejected")
+	}
+}
+
+func TestRestrictClientCNChainsExistingVerify(t *testing.T) {
+	called := false
+	cfg := &tls.Config{
+		VerifyConnection: func(tls.ConnectionState) error {
+			called = true
+			return nil
+		},
+	}
+	restrictClientCN(cfg, "kube-apiserver")
+	if err := cfg.VerifyConnection(connectionStateWithCN("kube-apiserver")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !called {
+		t.Errorf("existing VerifyConnection should still run")
+	}
+}
@@ -19,6 +19,8 @@ const (
 
 	mutationRequestCountMetricName    = "mutation_request_count"
 	mutationRequestDurationMetricName = "mutation_request_duration_seconds"
+
+	denialsMetricName = "webhook_denials_total"
 )
 
 var (
@@ -37,8 +39,16 @@ var (
 		"The response time in seconds",
 		stats.UnitSeconds)
 
+	denialsM = stats.Int64(
+		denialsMetricName,
+		"The number of denials per constraint",
+		stats.UnitDimensionless)
+
 	admissionStatusKey = tag.MustNewKey("admission_status")
 	mutationStatusKey  = tag.MustNewKey("mutation_status")
+
+	constraintKindKey = tag.MustNewKey("constraint_kind")
+	constraintNameKey = tag.MustNewKey("constraint_name")
 )
 
 func init() {
@@ -51,6 +61,7 @@ func init() {
 type StatsReporter interface {
 	ReportValidationRequest(response requestResponse, d time.Duration) error
 	ReportMutationRequest(response requestResponse, d time.Duration) error
+	ReportDenial(constraintKind, constraintName string) error
 }
 
 // reporter implements StatsReporter interface
@@ -81,6 +92,21 @@ func (r *reporter) ReportMutationRequest(response requestResponse, d time.Durati
 	return r.reportRequest(response, mutationStatusKey, mutationResponseTimeInSecM.M(d.Seconds()))
 }
 
+// ReportDenial counts one webhook denial against the constraint that
+// produced it, so alerting can target specific noisy constraints.
+func (r *reporter) ReportDenial(constraintKind, constraintName string) error {
+	ctx, err := tag.New(
+		r.ctx,
+		tag.Insert(constraintKindKey, constraintKind),
+		tag.Insert(constraintNameKey, constraintName),
+	)
+	if err != nil {
+		return err
+	}
+
+	return r.report(ctx, denialsM.M(1))
+}
+
 // Captures req count metric, recording the count and the duration
 func (r *reporter) reportRequest(response requestResponse, statusKey tag.Key, m stats.Measurement) error {
 	//mutationResponseTimeInSecM.M(d.Seconds())
@@ -144,6 +170,13 @@ func register() error {
 			Aggregation: view.Distribution(0.001, 0.002, 0.003, 0.004, 0.005, 0.006, 0.007, 0.008, 0.009, 0.01, 0.02, 0.03, 0.04, 0.05),
 			TagKeys:     []tag.Key{mutationStatusKey},
 		},
+		{
+			Name:        denialsMetricName,
+			Description: denialsM.Description(),
+			Measure:     denialsM,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{constraintKindKey, constraintNameKey},
+		},
 	}
 	return view.Register(views...)
 }
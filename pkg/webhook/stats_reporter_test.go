@@ -107,3 +107,35 @@ func checkData(t *testing.T, name string, expectedRowLength int) *view.Row {
 	}
 	return row[0]
 }
+
+func TestReportDenial(t *testing.T) {
+	r, err := newStatsReporter()
+	if err != nil {
+		t.Errorf("newStatsReporter() error %v", err)
+	}
+	err = r.ReportDenial("K8sRequiredLabels", "must-have-owner")
+	if err != nil {
+		t.Errorf("ReportDenial error %v", err)
+	}
+	err = r.ReportDenial("K8sRequiredLabels", "must-have-owner")
+	if err != nil {
+		t.Errorf("ReportDenial error %v", err)
+	}
+	row := checkData(t, denialsMetricName, 1)
+	count, ok := row.Data.(*view.CountData)
+	if !ok {
+		t.Error("ReportDenial should have aggregation Count()")
+	}
+	if count.Value != 2 {
+		t.Errorf("Metric: %v - Expected 2, got %v", denialsMetricName, count.Value)
+	}
+	expectedTags := map[string]string{
+		"constraint_kind": "K8sRequiredLabels",
+		"constraint_name": "must-have-owner",
+	}
+	for _, tag := range row.Tags {
+		if tag.Value != expectedTags[tag.Key.Name()] {
+			t.Errorf("ReportDenial tags does not match for %v", tag.Key.Name())
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var (
+	nsTierLabel    = flag.String("namespace-tier-label", "tier", "namespace label whose value selects a default enforcement action from --namespace-tier-defaults")
+	nsTierDefaults = flag.String("namespace-tier-defaults", "", "(alpha) comma-separated mapping of namespace tier label values to default enforcement actions, e.g. 'prod=deny,staging=dryrun,dev=dryrun'. Constraints that do not explicitly declare an enforcement action use the default for the namespace's tier")
+)
+
+// tierDefaults is parsed lazily from the flag on first use.
+var tierDefaults map[string]util.EnforcementAction
+
+// parseTierDefaults parses a 'tier=action' mapping flag value.
+func parseTierDefaults(s string) (map[string]util.EnforcementAction, error) {
+	out := make(map[string]util.EnforcementAction)
+	if s == "" {
+		return out, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("invalid namespace tier default %q, expected 'tier=action'", pair)
+		}
+		action := util.EnforcementAction(kv[1])
+		if err := util.ValidateEnforcementAction(action); err != nil {
+			return nil, err
+		}
+		out[kv[0]] = action
+	}
+	return out, nil
+}
+
+// tierDefaultAction returns the default enforcement action configured for
+// the namespace's tier label, if any.
+func tierDefaultAction(ns *corev1.Namespace) (util.EnforcementAction, bool) {
+	if ns == nil {
+		return "", false
+	}
+	if tierDefaults == nil {
+		parsed, err := parseTierDefaults(*nsTierDefaults)
+		if err != nil {
+			log.Error(err, "invalid --namespace-tier-defaults, namespace tier defaults are disabled")
+			parsed = make(map[string]util.EnforcementAction)
+		}
+		tierDefaults = parsed
+	}
+	tier, ok := ns.Labels[*nsTierLabel]
+	if !ok {
+		return "", false
+	}
+	action, ok := tierDefaults[tier]
+	return action, ok
+}
+
+// constraintDeclaresAction returns true when the constraint explicitly
+// declares an enforcement action, either globally or scoped to the
+// webhook enforcement point. Such constraints are not subject to
+// namespace tier defaults.
+func constraintDeclaresAction(constraint *unstructured.Unstructured) bool {
+	if action, _, err := unstructured.NestedString(constraint.Object, "spec", "enforcementAction"); err == nil && action != "" {
+		return true
+	}
+	if action, _, err := unstructured.NestedString(constraint.Object, "spec", "enforcementActionOverrides", string(util.WebhookEnforcementPoint)); err == nil && action != "" {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestParseTierDefaults(t *testing.T) {
+	parsed, err := parseTierDefaults("prod=deny,dev=dryrun")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed["prod"] != util.Deny || parsed["dev"] != util.Dryrun {
+		t.Errorf("unexpected parse result: %v", parsed)
+	}
+
+	if _, err := parseTierDefaults("prod=notsupported"); err == nil {
+		t.Errorf("expected error on unsupported enforcement action")
+	}
+	if _, err := parseTierDefaults("malformed"); err == nil {
+		t.Errorf("expected error on malformed mapping")
+	}
+	parsed, err = parseTierDefaults("")
+	if err != nil || len(parsed) != 0 {
+		t.Errorf("empty flag should parse to an empty mapping, got %v, %v", parsed, err)
+	}
+}
+
+func TestTierDefaultAction(t *testing.T) {
+	defer func() { tierDefaults = nil }()
+	tierDefaults = map[string]util.EnforcementAction{
+		"prod": util.Deny,
+		"dev":  util.Dryrun,
+	}
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "team-a",
+		Labels: map[string]string{"tier": "dev"},
+	}}
+	action, ok := tierDefaultAction(ns)
+	if !ok || action != util.Dryrun {
+		t.Errorf("expected dryrun default for dev tier, got %v, %v", action, ok)
+	}
+
+	unlabeled := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}}
+	if _, ok := tierDefaultAction(unlabeled); ok {
+		t.Errorf("unlabeled namespace should have no tier default")
+	}
+
+	if _, ok := tierDefaultAction(nil); ok {
+		t.Errorf("nil namespace should have no tier default")
+	}
+}
+
+func TestConstraintDeclaresAction(t *testing.T) {
+	explicit := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"enforcementAction": "deny"},
+	}}
+	if !constraintDeclaresAction(explicit) {
+		t.Errorf("constraint with spec.enforcementAction should declare an action")
+	}
+
+	scoped := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"enforcementActionOverrides": map[string]interface{}{"webhook": "dryrun"},
+		},
+	}}
+	if !constraintDeclaresAction(scoped) {
+		t.Errorf("constraint with a webhook override should declare an action")
+	}
+
+	implicit := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{},
+	}}
+	if constraintDeclaresAction(implicit) {
+		t.Errorf("constraint without an action should not declare one")
+	}
+}
@@ -0,0 +1,253 @@
+// Package wgpolicy publishes audit results as wgpolicyk8s.io
+// PolicyReport resources, the policy-working-group format consumed by
+// Policy Reporter, Kyverno dashboards, and similar ecosystem tooling.
+// One report per namespace is maintained under a fixed name, with
+// results merged by constraint so a constraint with many violating
+// resources stays one entry; cluster-scoped violations go to a single
+// ClusterPolicyReport. Reports are rewritten every audit run, so a
+// namespace that comes clean gets its report emptied rather than left
+// stale.
+package wgpolicy
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/logging"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("wgpolicy").WithValues(logging.Process, "policy_report")
+
+var (
+	emitPolicyReports      = flag.Bool("emit-policy-reports", false, "(alpha) maintain wgpolicyk8s.io PolicyReport resources from audit results")
+	policyReportMaxResults = flag.Uint("policy-report-max-results", 100, "maximum number of merged results per PolicyReport; the summary still counts every violation")
+)
+
+// Enabled returns true if PolicyReport output was requested via flags.
+func Enabled() bool {
+	return *emitPolicyReports
+}
+
+const (
+	apiVersion = "wgpolicyk8s.io/v1alpha2"
+	// reportName is the fixed name of the report gatekeeper maintains
+	// in each namespace.
+	reportName = "gatekeeper-audit"
+	// managedByLabel marks the reports this package owns so it never
+	// touches reports written by other tools.
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedByValue = "gatekeeper"
+	sourceName     = "gatekeeper"
+)
+
+// Entry is one audit violation to report.
+type Entry struct {
+	ConstraintKind     string
+	ConstraintName     string
+	ResourceAPIVersion string
+	ResourceKind       string
+	ResourceNamespace  string
+	ResourceName       string
+	Message            string
+	EnforcementAction  string
+}
+
+// mergedResult is one constraint's entry in a report, carrying every
+// violating resource in that namespace.
+type mergedResult struct {
+	constraintKind string
+	constraintName string
+	message        string
+	result         string
+	// resources holds JSON-shaped objects so the report stays valid
+	// unstructured content
+	resources []interface{}
+}
+
+// resultFor maps an enforcement action to a PolicyReport result value.
+func resultFor(enforcementAction string) string {
+	if enforcementAction == "deny" {
+		return "fail"
+	}
+	return "warn"
+}
+
+// BuildReport renders the entries for one namespace into a PolicyReport.
+// An empty namespace renders a ClusterPolicyReport instead. Results are
+// merged by constraint and capped at the configured maximum; the
+// summary counts every entry regardless of the cap.
+func BuildReport(namespace string, entries []Entry, timestamp string) *unstructured.Unstructured {
+	return buildReport(namespace, entries, timestamp, *policyReportMaxResults)
+}
+
+func buildReport(namespace string, entries []Entry, timestamp string, maxResults uint) *unstructured.Unstructured {
+	merged := map[string]*mergedResult{}
+	var order []string
+	fail := 0
+	warn := 0
+	for _, e := range entries {
+		key := e.ConstraintKind + "/" + e.ConstraintName
+		m, ok := merged[key]
+		if !ok {
+			m = &mergedResult{
+				constraintKind: e.ConstraintKind,
+				constraintName: e.ConstraintName,
+				message:        e.Message,
+				result:         resultFor(e.EnforcementAction),
+			}
+			merged[key] = m
+			order = append(order, key)
+		}
+		resource := map[string]interface{}{
+			"apiVersion": e.ResourceAPIVersion,
+			"kind":       e.ResourceKind,
+			"name":       e.ResourceName,
+		}
+		if e.ResourceNamespace != "" {
+			resource["namespace"] = e.ResourceNamespace
+		}
+		m.resources = append(m.resources, resource)
+		if m.result == "fail" {
+			fail++
+		} else {
+			warn++
+		}
+	}
+	sort.Strings(order)
+
+	results := []interface{}{}
+	for _, key := range order {
+		if uint(len(results)) >= maxResults {
+			break
+		}
+		m := merged[key]
+		result := map[string]interface{}{
+			"source":    sourceName,
+			"policy":    m.constraintKind,
+			"rule":      m.constraintName,
+			"message":   m.message,
+			"result":    m.result,
+			"resources": m.resources,
+		}
+		// the result timestamp is a metav1.Timestamp, not a metav1.Time
+		if t, err := time.Parse(time.RFC3339, timestamp); err == nil {
+			result["timestamp"] = map[string]interface{}{
+				"seconds": t.Unix(),
+				"nanos":   int64(0),
+			}
+		}
+		results = append(results, result)
+	}
+
+	report := &unstructured.Unstructured{}
+	report.SetAPIVersion(apiVersion)
+	if namespace == "" {
+		report.SetKind("ClusterPolicyReport")
+	} else {
+		report.SetKind("PolicyReport")
+		report.SetNamespace(namespace)
+	}
+	report.SetName(reportName)
+	report.SetLabels(map[string]string{managedByLabel: managedByValue})
+	report.SetAnnotations(map[string]string{"gatekeeper.sh/audit-timestamp": timestamp})
+	report.Object["summary"] = map[string]interface{}{
+		"pass":  int64(0),
+		"fail":  int64(fail),
+		"warn":  int64(warn),
+		"error": int64(0),
+		"skip":  int64(0),
+	}
+	report.Object["results"] = results
+	return report
+}
+
+// groupByNamespace splits entries by the violating resource's
+// namespace; cluster-scoped resources group under the empty key.
+func groupByNamespace(entries []Entry) map[string][]Entry {
+	grouped := map[string][]Entry{}
+	for _, e := range entries {
+		grouped[e.ResourceNamespace] = append(grouped[e.ResourceNamespace], e)
+	}
+	return grouped
+}
+
+// Sync writes one report per namespace with violations and clears the
+// reports of namespaces that no longer have any.
+func Sync(ctx context.Context, c client.Client, entries []Entry, timestamp string) error {
+	grouped := groupByNamespace(entries)
+
+	// find the reports written previously so clean namespaces get
+	// cleared
+	existing := &unstructured.UnstructuredList{}
+	existing.SetAPIVersion(apiVersion)
+	existing.SetKind("PolicyReportList")
+	if err := c.List(ctx, existing, client.MatchingLabels{managedByLabel: managedByValue}); err != nil {
+		if meta.IsNoMatchError(err) {
+			// the PolicyReport CRD is not installed; nothing to do
+			log.Info("PolicyReport CRD not installed; skipping policy report output")
+			return nil
+		}
+		return fmt.Errorf("listing policy reports: %w", err)
+	}
+	for i := range existing.Items {
+		ns := existing.Items[i].GetNamespace()
+		if _, ok := grouped[ns]; !ok && existing.Items[i].GetName() == reportName {
+			// rewrite as empty rather than delete, so consumers see the
+			// namespace came clean instead of the report vanishing
+			grouped[ns] = nil
+		}
+	}
+	clusterReports := &unstructured.UnstructuredList{}
+	clusterReports.SetAPIVersion(apiVersion)
+	clusterReports.SetKind("ClusterPolicyReportList")
+	if err := c.List(ctx, clusterReports, client.MatchingLabels{managedByLabel: managedByValue}); err == nil {
+		for i := range clusterReports.Items {
+			if _, ok := grouped[""]; !ok && clusterReports.Items[i].GetName() == reportName {
+				grouped[""] = nil
+			}
+		}
+	}
+
+	var firstErr error
+	for ns, nsEntries := range grouped {
+		report := BuildReport(ns, nsEntries, timestamp)
+		if err := writeReport(ctx, c, report); err != nil {
+			log.Error(err, "failed to write policy report", "namespace", ns)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// writeReport creates the report or overwrites the existing one.
+func writeReport(ctx context.Context, c client.Client, report *unstructured.Unstructured) error {
+	err := c.Create(ctx, report.DeepCopy())
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		if meta.IsNoMatchError(err) {
+			return nil
+		}
+		return err
+	}
+	current := &unstructured.Unstructured{}
+	current.SetAPIVersion(report.GetAPIVersion())
+	current.SetKind(report.GetKind())
+	if err := c.Get(ctx, client.ObjectKey{Namespace: report.GetNamespace(), Name: report.GetName()}, current); err != nil {
+		return err
+	}
+	report = report.DeepCopy()
+	report.SetResourceVersion(current.GetResourceVersion())
+	return c.Update(ctx, report)
+}
@@ -0,0 +1,158 @@
+package wgpolicy
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func sampleEntries() []Entry {
+	return []Entry{
+		{
+			ConstraintKind:    "K8sRequiredLabels",
+			ConstraintName:    "must-have-owner",
+			ResourceKind:      "Pod",
+			ResourceNamespace: "shipping",
+			ResourceName:      "pod-a",
+			Message:           "missing label owner",
+			EnforcementAction: "deny",
+		},
+		{
+			ConstraintKind:    "K8sRequiredLabels",
+			ConstraintName:    "must-have-owner",
+			ResourceKind:      "Pod",
+			ResourceNamespace: "shipping",
+			ResourceName:      "pod-b",
+			Message:           "missing label owner",
+			EnforcementAction: "deny",
+		},
+		{
+			ConstraintKind:    "K8sAllowedRepos",
+			ConstraintName:    "repo-allowlist",
+			ResourceKind:      "Pod",
+			ResourceNamespace: "shipping",
+			ResourceName:      "pod-a",
+			Message:           "image not from allowed repo",
+			EnforcementAction: "dryrun",
+		},
+	}
+}
+
+func results(t *testing.T, report *unstructured.Unstructured) []interface{} {
+	t.Helper()
+	res, found, err := unstructured.NestedSlice(report.Object, "results")
+	if err != nil || !found {
+		t.Fatalf("report has no results: %v", err)
+	}
+	return res
+}
+
+func TestBuildReportMergesByConstraint(t *testing.T) {
+	report := buildReport("shipping", sampleEntries(), "2026-08-30T00:00:00Z", 100)
+
+	if report.GetKind() != "PolicyReport" {
+		t.Errorf("got kind %q, want PolicyReport", report.GetKind())
+	}
+	if report.GetNamespace() != "shipping" {
+		t.Errorf("got namespace %q, want shipping", report.GetNamespace())
+	}
+
+	res := results(t, report)
+	if len(res) != 2 {
+		t.Fatalf("got %d results, want 2 (merged by constraint)", len(res))
+	}
+	first, ok := res[0].(map[string]interface{})
+	if !ok {
+		t.Fatalf("result is not an object: %v", res[0])
+	}
+	// order is sorted by constraint kind/name
+	if first["policy"] != "K8sAllowedRepos" || first["rule"] != "repo-allowlist" {
+		t.Errorf("unexpected first result: %v", first)
+	}
+	if first["result"] != "warn" {
+		t.Errorf("dryrun should map to warn, got %v", first["result"])
+	}
+	second := res[1].(map[string]interface{})
+	resources, _ := second["resources"].([]interface{})
+	if len(resources) != 2 {
+		t.Errorf("merged result should carry both resources, got %v", second["resources"])
+	}
+
+	summary, found, err := unstructured.NestedMap(report.Object, "summary")
+	if err != nil || !found {
+		t.Fatalf("report has no summary: %v", err)
+	}
+	if summary["fail"] != int64(2) || summary["warn"] != int64(1) {
+		t.Errorf("unexpected summary: %v", summary)
+	}
+}
+
+func TestBuildReportCapsResults(t *testing.T) {
+	report := buildReport("shipping", sampleEntries(), "2026-08-30T00:00:00Z", 1)
+
+	res := results(t, report)
+	if len(res) != 1 {
+		t.Fatalf("got %d results, want cap of 1", len(res))
+	}
+	// the summary still counts everything
+	summary, _, err := unstructured.NestedMap(report.Object, "summary")
+	if err != nil {
+		t.Fatalf("reading summary: %v", err)
+	}
+	if summary["fail"] != int64(2) || summary["warn"] != int64(1) {
+		t.Errorf("summary should count past the cap, got %v", summary)
+	}
+}
+
+func TestBuildReportClusterScoped(t *testing.T) {
+	entries := []Entry{
+		{
+			ConstraintKind:    "K8sRequiredLabels",
+			ConstraintName:    "must-have-owner",
+			ResourceKind:      "Namespace",
+			ResourceName:      "shipping",
+			Message:           "missing label owner",
+			EnforcementAction: "deny",
+		},
+	}
+	report := buildReport("", entries, "2026-08-30T00:00:00Z", 100)
+	if report.GetKind() != "ClusterPolicyReport" {
+		t.Errorf("got kind %q, want ClusterPolicyReport", report.GetKind())
+	}
+	if report.GetNamespace() != "" {
+		t.Errorf("cluster report should not be namespaced, got %q", report.GetNamespace())
+	}
+}
+
+func TestBuildReportEmpty(t *testing.T) {
+	report := buildReport("shipping", nil, "2026-08-30T00:00:00Z", 100)
+	res, found, err := unstructured.NestedSlice(report.Object, "results")
+	if err != nil || !found {
+		t.Fatalf("empty report should still declare results: %v", err)
+	}
+	if len(res) != 0 {
+		t.Errorf("got %d results, want 0", len(res))
+	}
+}
+
+func TestGroupByNamespace(t *testing.T) {
+	entries := sampleEntries()
+	entries = append(entries, Entry{
+		ConstraintKind:    "K8sRequiredLabels",
+		ConstraintName:    "must-have-owner",
+		ResourceKind:      "Namespace",
+		ResourceName:      "billing",
+		Message:           "missing label owner",
+		EnforcementAction: "deny",
+	})
+	grouped := groupByNamespace(entries)
+	if len(grouped) != 2 {
+		t.Fatalf("got %d groups, want 2", len(grouped))
+	}
+	if len(grouped["shipping"]) != 3 {
+		t.Errorf("got %d shipping entries, want 3", len(grouped["shipping"]))
+	}
+	if len(grouped[""]) != 1 {
+		t.Errorf("got %d cluster-scoped entries, want 1", len(grouped[""]))
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Modified from the original source (available at
+// https://github.com/kubernetes-sigs/controller-runtime/tree/v0.7.0/pkg/webhook/internal/metrics)
+// to reuse the collectors already registered by the upstream webhook
+// server package instead of panicking on duplicate registration.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	// Force the upstream webhook server package to initialize first; its
+	// internal metrics package panics on registration conflicts, so it
+	// must claim the collector names before this package resolves them.
+	_ "sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+var (
+	// RequestLatency is a prometheus metric which is a histogram of the latency
+	// of processing admission requests.
+	RequestLatency = registerOrGet(prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "controller_runtime_webhook_latency_seconds",
+			Help: "Histogram of the latency of processing admission requests",
+		},
+		[]string{"webhook"},
+	)).(*prometheus.HistogramVec)
+
+	// RequestTotal is a prometheus metric which is a counter of the total processed admission requests.
+	RequestTotal = registerOrGet(prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "controller_runtime_webhook_requests_total",
+			Help: "Total number of admission requests by HTTP status code.",
+		},
+		[]string{"webhook", "code"},
+	)).(*prometheus.CounterVec)
+
+	// RequestInFlight is a prometheus metric which is a gauge of the in-flight admission requests.
+	RequestInFlight = registerOrGet(prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "controller_runtime_webhook_requests_in_flight",
+			Help: "Current number of admission requests being served.",
+		},
+		[]string{"webhook"},
+	)).(*prometheus.GaugeVec)
+)
+
+// registerOrGet registers the collector, returning the previously
+// registered collector when the upstream webhook server package already
+// claimed the name.
+func registerOrGet(c prometheus.Collector) prometheus.Collector {
+	if err := metrics.Registry.Register(c); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector
+		}
+		panic(err)
+	}
+	return c
+}